@@ -56,6 +56,103 @@ type JobSpec struct {
 	// `.Values`. `.Release.Name` and `.Release.Namespace` are
 	// also exported.
 	Template corev1.PodTemplateSpec `json:"template"`
+
+	// PreHooks run in order before the backup pod starts, e.g. to quiesce
+	// or flush a database. A failing hook fails the BackupJob.
+	// +optional
+	PreHooks []JobPreHook `json:"preHooks,omitempty"`
+
+	// PostHooks run in order once the backup pod has terminated, whether or
+	// not it succeeded, e.g. to resume a quiesced database. A failing hook
+	// fails the BackupJob.
+	// +optional
+	PostHooks []JobPostHook `json:"postHooks,omitempty"`
+
+	// RestoreTemplate holds a PodTemplateSpec run to completion to restore
+	// a Backup taken with this strategy into an application. Templates are
+	// rendered as the template, with the target application, and with the
+	// Backup under `.Backup` (`.Backup.Name`, `.Backup.Namespace`,
+	// `.Backup.URI`, `.Backup.TakenAt` and `.Backup.DriverMetadata`).
+	// Backups taken with a strategy without a restore template cannot be
+	// restored.
+	// +optional
+	RestoreTemplate *corev1.PodTemplateSpec `json:"restoreTemplate,omitempty"`
+
+	// PostRestoreHooks run in order once the restore pod succeeded, e.g. to
+	// migrate schemas, rotate credentials or warm caches. A failing hook
+	// fails the RestoreJob.
+	// +optional
+	PostRestoreHooks []JobRestoreHook `json:"postRestoreHooks,omitempty"`
+}
+
+// JobPreHook is a command run before the backup. Exactly one of Exec and
+// InitContainer must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.exec) != has(self.initContainer)",message="exactly one of exec or initContainer must be set"
+type JobPreHook struct {
+	// Name identifies the hook in events and messages.
+	Name string `json:"name"`
+
+	// Exec runs a command in the pods of the application.
+	// +optional
+	Exec *ExecHook `json:"exec,omitempty"`
+
+	// InitContainer is added to the init containers of the backup pod,
+	// ahead of the init containers of the template. Helm-like Go templates
+	// are supported as in the template.
+	// +optional
+	InitContainer *corev1.Container `json:"initContainer,omitempty"`
+}
+
+// JobPostHook is a command run after the backup.
+type JobPostHook struct {
+	// Name identifies the hook in events and messages.
+	Name string `json:"name"`
+
+	// Exec runs a command in the pods of the application.
+	Exec ExecHook `json:"exec"`
+}
+
+// JobRestoreHook is a command run after a restore. Exactly one of Exec and
+// Template must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.exec) != has(self.template)",message="exactly one of exec or template must be set"
+type JobRestoreHook struct {
+	// Name identifies the hook in events, messages and the conditions of
+	// the RestoreJob.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+
+	// Exec runs a command in the pods of the restored application.
+	// +optional
+	Exec *ExecHook `json:"exec,omitempty"`
+
+	// Template holds a PodTemplateSpec run to completion in the namespace
+	// of the RestoreJob. Helm-like Go templates are supported as in the
+	// restore template.
+	// +optional
+	Template *corev1.PodTemplateSpec `json:"template,omitempty"`
+}
+
+// ExecHook runs a command in every running pod selected by PodSelector in
+// the namespace of the BackupJob or RestoreJob.
+type ExecHook struct {
+	// PodSelector selects the pods the command runs in. Helm-like Go
+	// templates are supported in its values as in the template.
+	PodSelector metav1.LabelSelector `json:"podSelector"`
+
+	// Container is the container the command runs in. Defaults to the
+	// first container of the pod.
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// Command is the command to run, not wrapped in a shell.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+
+	// Timeout bounds the time the command may run in each pod. Defaults
+	// to 30s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 type JobStatus struct {
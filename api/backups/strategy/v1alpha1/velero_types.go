@@ -50,6 +50,13 @@ type VeleroList struct {
 // VeleroSpec specifies the desired strategy for backing up with Velero.
 type VeleroSpec struct {
 	Template VeleroTemplate `json:"template"`
+
+	// RestoreTemplate is the base of the restore.velero.io objects created to
+	// restore backups taken with this strategy, e.g. to set restorePVs or
+	// existingResourcePolicy. The backup name and the namespaces to restore
+	// are always set from the RestoreJob.
+	// +optional
+	RestoreTemplate *VeleroRestoreTemplate `json:"restoreTemplate,omitempty"`
 }
 
 // VeleroTemplate describes the data a backup.velero.io should have when
@@ -58,6 +65,12 @@ type VeleroTemplate struct {
 	Spec velerov1.BackupSpec `json:"spec"`
 }
 
+// VeleroRestoreTemplate describes the data a restore.velero.io should have
+// when restoring a backup taken with a Velero backup strategy.
+type VeleroRestoreTemplate struct {
+	Spec velerov1.RestoreSpec `json:"spec"`
+}
+
 type VeleroStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -29,6 +29,9 @@ const (
 	BackupPhasePending BackupPhase = "Pending"
 	BackupPhaseReady   BackupPhase = "Ready"
 	BackupPhaseFailed  BackupPhase = "Failed"
+	// BackupPhaseExpired is only used for copies: the copy was removed by the
+	// retention policy of its Storage.
+	BackupPhaseExpired BackupPhase = "Expired"
 )
 
 // BackupArtifact describes the stored backup object (tarball, snapshot, etc.).
@@ -87,11 +90,41 @@ type BackupStatus struct {
 	// +optional
 	Artifact *BackupArtifact `json:"artifact,omitempty"`
 
+	// Copies reports the copies of the backup in additional Storages, in the
+	// order of spec.copies of the BackupJob that produced it.
+	// +optional
+	Copies []BackupCopyStatus `json:"copies,omitempty"`
+
 	// Conditions represents the latest available observations of a Backup's state.
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// BackupCopyStatus represents the observed state of a copy of a Backup.
+type BackupCopyStatus struct {
+	// StorageRef refers to the Storage object the copy is stored in.
+	StorageRef corev1.TypedLocalObjectReference `json:"storageRef"`
+
+	// Phase is the state of the copy.
+	// Typical values are: Pending, Ready, Failed, Expired.
+	// +optional
+	Phase BackupPhase `json:"phase,omitempty"`
+
+	// Artifact describes the stored copy, if available.
+	// +optional
+	Artifact *BackupArtifact `json:"artifact,omitempty"`
+
+	// Message is a human-readable message indicating details about the
+	// phase of the copy, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// DriverMetadata holds driver-specific, opaque metadata associated with
+	// the copy, used by the driver to remove it when it expires.
+	// +optional
+	DriverMetadata map[string]string `json:"driverMetadata,omitempty"`
+}
+
 // The field indexing on applicationRef will be needed later to display per-app backup resources.
 
 // +kubebuilder:object:root=true
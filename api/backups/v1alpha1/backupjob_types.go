@@ -24,6 +24,9 @@ func init() {
 const (
 	OwningJobNameLabel      = thisGroup + "/owned-by.BackupJobName"
 	OwningJobNamespaceLabel = thisGroup + "/owned-by.BackupJobNamespace"
+	// CopyIndexLabel is set on driver objects that produce a copy of a backup
+	// to the index of the copy in BackupJob.spec.copies.
+	CopyIndexLabel = thisGroup + "/copy-index"
 )
 
 // BackupJobPhase represents the lifecycle phase of a BackupJob.
@@ -49,12 +52,26 @@ type BackupJobSpec struct {
 	ApplicationRef corev1.TypedLocalObjectReference `json:"applicationRef"`
 
 	// StorageRef holds a reference to the Storage object that describes where
-	// the backup will be stored.
-	StorageRef corev1.TypedLocalObjectReference `json:"storageRef"`
+	// the backup will be stored. If omitted, the default storage of the
+	// namespace or the cluster is used.
+	// +optional
+	StorageRef *corev1.TypedLocalObjectReference `json:"storageRef,omitempty"`
 
 	// StrategyRef holds a reference to the driver-specific BackupStrategy object
 	// that describes how the backup should be created.
 	StrategyRef corev1.TypedLocalObjectReference `json:"strategyRef"`
+
+	// Copies holds references to additional Storage objects the backup is
+	// copied to once it has been taken.
+	// +optional
+	Copies []corev1.TypedLocalObjectReference `json:"copies,omitempty"`
+
+	// TTLSecondsAfterFinished is the number of seconds the BackupJob is kept
+	// once it succeeded or failed. It is then deleted, while the Backup it
+	// created is kept. Defaults to the TTL configured on the backup controller.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 }
 
 // BackupJobStatus represents the observed state of a BackupJob.
@@ -64,6 +81,11 @@ type BackupJobStatus struct {
 	// +optional
 	Phase BackupJobPhase `json:"phase,omitempty"`
 
+	// StorageRef is the Storage the run is bound to: spec.storageRef, or the
+	// default storage if spec.storageRef is omitted.
+	// +optional
+	StorageRef *corev1.TypedLocalObjectReference `json:"storageRef,omitempty"`
+
 	// BackupRef refers to the Backup object created by this run, if any.
 	// +optional
 	BackupRef *corev1.LocalObjectReference `json:"backupRef,omitempty"`
@@ -77,21 +99,72 @@ type BackupJobStatus struct {
 	// +optional
 	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
 
+	// Duration is the time the run took from StartedAt to CompletedAt,
+	// set once the run completes.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
 	// Message is a human-readable message indicating details about why the
 	// backup run is in its current phase, if any.
 	// +optional
 	Message string `json:"message,omitempty"`
 
+	// Filters are the filters scoping the backup to the objects of the
+	// application, computed from its inventory. Unset when the backup is
+	// scoped by the strategy alone.
+	// +optional
+	Filters *BackupFilters `json:"filters,omitempty"`
+
+	// Progress is the progress of the run as reported by the driver, if it
+	// reports any.
+	// +optional
+	Progress *JobProgress `json:"progress,omitempty"`
+
 	// Conditions represents the latest available observations of a BackupJob's state.
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// BackupFilters scope a backup to a set of objects.
+type BackupFilters struct {
+	// IncludedNamespaces are the namespaces the backed up objects are taken from.
+	// +optional
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+
+	// LabelSelectors select the backed up objects: an object is backed up if it
+	// matches any of them.
+	// +optional
+	LabelSelectors []metav1.LabelSelector `json:"labelSelectors,omitempty"`
+}
+
+// JobProgress is the progress of a backup or restore run as reported by the
+// driver.
+type JobProgress struct {
+	// TotalItems is the number of items the run processes. It may grow while
+	// the run is in progress.
+	// +optional
+	TotalItems int `json:"totalItems,omitempty"`
+
+	// ItemsCompleted is the number of items processed so far.
+	// +optional
+	ItemsCompleted int `json:"itemsCompleted,omitempty"`
+
+	// Warnings is the number of warnings the driver encountered.
+	// +optional
+	Warnings int `json:"warnings,omitempty"`
+
+	// Errors is the number of errors the driver encountered.
+	// +optional
+	Errors int `json:"errors,omitempty"`
+}
+
 // The field indexing on applicationRef will be needed later to display per-app backup resources.
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",priority=0
+// +kubebuilder:printcolumn:name="Items",type="integer",JSONPath=".status.progress.itemsCompleted",priority=1
+// +kubebuilder:printcolumn:name="Duration",type="string",JSONPath=".status.duration",priority=1
 // +kubebuilder:selectablefield:JSONPath=`.spec.applicationRef.apiGroup`
 // +kubebuilder:selectablefield:JSONPath=`.spec.applicationRef.kind`
 // +kubebuilder:selectablefield:JSONPath=`.spec.applicationRef.name`
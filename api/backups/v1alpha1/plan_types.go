@@ -9,6 +9,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func init() {
@@ -28,11 +29,52 @@ const (
 	PlanScheduleTypeCron  PlanScheduleType = "cron"
 )
 
+// PlanConcurrencyPolicy describes how a scheduled backup is treated while a
+// BackupJob of the same Plan is still running.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type PlanConcurrencyPolicy string
+
+const (
+	PlanConcurrencyAllow   PlanConcurrencyPolicy = "Allow"
+	PlanConcurrencyForbid  PlanConcurrencyPolicy = "Forbid"
+	PlanConcurrencyReplace PlanConcurrencyPolicy = "Replace"
+)
+
+// PlanRecreationPolicy describes how a Plan treats its application being
+// deleted and recreated under the same name.
+// +kubebuilder:validation:Enum=Adopt;Confirm
+type PlanRecreationPolicy string
+
+const (
+	PlanRecreationAdopt   PlanRecreationPolicy = "Adopt"
+	PlanRecreationConfirm PlanRecreationPolicy = "Confirm"
+)
+
 // Condtions
 const (
-	PlanConditionError = "Error"
+	PlanConditionError             = "Error"
+	PlanConditionMaintenanceWindow = "MaintenanceWindow"
+	// PlanConditionTargetRecreated is set when the application of the Plan was
+	// recreated since the Plan was bound to it
+	PlanConditionTargetRecreated = "TargetRecreated"
 )
 
+// AnnotationMaintenanceWindow is set on an application to declare the time
+// windows in which backups are preferred. The value is a comma-separated list
+// of UTC ranges in the form "HH:MM-HH:MM", e.g. "01:00-03:00,22:30-23:30".
+// A range whose end is before its start wraps around midnight.
+const AnnotationMaintenanceWindow = "backups.cozystack.io/maintenance-window"
+
+// AnnotationDefaultStorage is set on a Namespace to select the Storage that
+// Plans and BackupJobs without spec.storageRef bind to. The value has the form
+// "[<apiGroup>/]<Kind>/<name>", where apiGroup defaults to apps.cozystack.io,
+// e.g. "Bucket/backups".
+const AnnotationDefaultStorage = "backups.cozystack.io/default-storage"
+
+// AnnotationConfirmApplicationUID is set on a Plan with the Confirm recreation
+// policy to the UID of its recreated application, to bind the Plan to it.
+const AnnotationConfirmApplicationUID = "backups.cozystack.io/confirm-application-uid"
+
 // The field indexing on applicationRef will be needed later to display per-app backup resources.
 
 // +kubebuilder:object:root=true
@@ -69,14 +111,109 @@ type PlanSpec struct {
 
 	// StorageRef holds a reference to the Storage object that
 	// describes the location where the backup will be stored.
-	StorageRef corev1.TypedLocalObjectReference `json:"storageRef"`
+	// If omitted, the default storage of the namespace or the cluster is used.
+	// +optional
+	StorageRef *corev1.TypedLocalObjectReference `json:"storageRef,omitempty"`
 
 	// StrategyRef holds a reference to the Strategy object that
 	// describes, how a backup copy is to be created.
-	StrategyRef corev1.TypedLocalObjectReference `json:"strategyRef"`
+	// If omitted, the default strategy of the application kind set by a
+	// StrategyPolicy is used.
+	// +optional
+	StrategyRef *corev1.TypedLocalObjectReference `json:"strategyRef,omitempty"`
 
 	// Schedule specifies when backup copies are created.
 	Schedule PlanSchedule `json:"schedule"`
+
+	// ConcurrencyPolicy specifies how to treat a scheduled backup while a
+	// BackupJob of the Plan is still running: Allow runs them concurrently,
+	// Forbid skips the new run, and Replace cancels the running BackupJob in
+	// favor of the new one.
+	// +kubebuilder:default=Allow
+	// +optional
+	ConcurrencyPolicy PlanConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// StartingDeadlineSeconds is the deadline in seconds for starting a
+	// scheduled backup that was missed, e.g. because the controller was down
+	// or, with the Forbid policy, because a BackupJob was still running.
+	// Missed backups past the deadline are skipped. Defaults to 300.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// Copies lists additional Storage objects every backup of this Plan
+	// is copied to, e.g. an offsite S3 bucket in addition to a local one.
+	// Each copy is reported separately in the status of the Backup and is
+	// subject to the retention of its own Storage.
+	// +optional
+	Copies []PlanCopy `json:"copies,omitempty"`
+
+	// Retention limits the Backups kept for this Plan. Expired Backups are
+	// deleted together with their artifacts and copies. If omitted, Backups
+	// are kept until deleted manually.
+	// +optional
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+
+	// OnApplicationRecreated specifies what happens when the application is
+	// deleted and recreated under the same name: Adopt binds the Plan to the
+	// new application and keeps scheduling backups, Confirm suspends the Plan
+	// with the TargetRecreated condition until the backups.cozystack.io/confirm-application-uid
+	// annotation is set to the UID of the new application.
+	// +kubebuilder:default=Adopt
+	// +optional
+	OnApplicationRecreated PlanRecreationPolicy `json:"onApplicationRecreated,omitempty"`
+}
+
+// PlanCopy describes an additional Storage backups are copied to.
+type PlanCopy struct {
+	// StorageRef holds a reference to the Storage object that describes
+	// the location the copy is uploaded to.
+	StorageRef corev1.TypedLocalObjectReference `json:"storageRef"`
+
+	// Retention limits how long copies are kept in this Storage. If
+	// omitted, a copy is kept as long as its Backup exists.
+	// +optional
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+}
+
+// RetentionPolicy limits the backups kept in a Storage. If any of MaxCount,
+// KeepDaily, KeepWeekly and KeepMonthly is set, a backup is kept only if at
+// least one of them selects it. Backups older than MaxAge are removed
+// regardless. Days, weeks and months are calendar periods in UTC.
+type RetentionPolicy struct {
+	// MaxCount is the number of most recent backups to keep.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+
+	// MaxAge is the age, measured from TakenAt, after which backups are removed.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// KeepDaily keeps the most recent backup of each of this many most
+	// recent days that have backups.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	KeepDaily *int32 `json:"keepDaily,omitempty"`
+
+	// KeepWeekly keeps the most recent backup of each of this many most
+	// recent ISO weeks that have backups.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	KeepWeekly *int32 `json:"keepWeekly,omitempty"`
+
+	// KeepMonthly keeps the most recent backup of each of this many most
+	// recent months that have backups.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	KeepMonthly *int32 `json:"keepMonthly,omitempty"`
+
+	// DryRun reports the backups the policy would remove instead of
+	// removing them, so that a new policy can be validated without losing
+	// data. The report is stored in the status of the Plan and emitted as
+	// Events on the Plan.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // PlanSchedule specifies when backup copies are created.
@@ -95,4 +232,60 @@ type PlanSchedule struct {
 
 type PlanStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// StorageRef is the Storage the Plan is bound to: spec.storageRef, or the
+	// default storage if spec.storageRef is omitted.
+	// +optional
+	StorageRef *corev1.TypedLocalObjectReference `json:"storageRef,omitempty"`
+
+	// StrategyRef is the strategy the Plan is bound to: spec.strategyRef, or
+	// the default strategy of the application kind if spec.strategyRef is omitted.
+	// +optional
+	StrategyRef *corev1.TypedLocalObjectReference `json:"strategyRef,omitempty"`
+
+	// ApplicationUID is the UID of the application the Plan is bound to. It
+	// changes when the application is recreated and the Plan adopts it.
+	// +optional
+	ApplicationUID types.UID `json:"applicationUID,omitempty"`
+
+	// LastScheduleTime is the time a BackupJob was last scheduled for.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the time the last successful BackupJob of the
+	// Plan completed.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// Retention reports the Backups exceeding spec.retention, generated
+	// before they are removed.
+	// +optional
+	Retention *RetentionReport `json:"retention,omitempty"`
+}
+
+// RetentionReport lists the Backups of a Plan exceeding its retention.
+type RetentionReport struct {
+	// DryRun is set if the retention is a dry run, in which case the
+	// listed Backups are kept.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Time is when the report was generated.
+	Time metav1.Time `json:"time"`
+
+	// Expired lists the Backups exceeding the retention, newest first.
+	// +optional
+	Expired []ExpiredBackup `json:"expired,omitempty"`
+}
+
+// ExpiredBackup is a Backup exceeding the retention of its Plan.
+type ExpiredBackup struct {
+	// Name is the name of the Backup.
+	Name string `json:"name"`
+
+	// TakenAt is the time the Backup was taken.
+	TakenAt metav1.Time `json:"takenAt"`
+
+	// Reason explains why the Backup exceeds the retention.
+	Reason string `json:"reason"`
 }
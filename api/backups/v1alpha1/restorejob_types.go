@@ -42,6 +42,26 @@ type RestoreJobSpec struct {
 	// application as referenced by backup.spec.applicationRef.
 	// +optional
 	TargetApplicationRef *corev1.TypedLocalObjectReference `json:"targetApplicationRef,omitempty"`
+
+	// BackupNamespace is the namespace of the referenced Backup. Defaults to
+	// the namespace of the RestoreJob. Restoring a Backup from another
+	// namespace requires NamespaceMapping to map BackupNamespace to the
+	// namespace of the RestoreJob.
+	// +optional
+	BackupNamespace string `json:"backupNamespace,omitempty"`
+
+	// NamespaceMapping maps namespaces of the backed up resources to the
+	// namespaces they are restored into. Drivers MUST restore only the mapped
+	// namespaces and rewrite namespaced references accordingly.
+	// +optional
+	NamespaceMapping map[string]string `json:"namespaceMapping,omitempty"`
+
+	// TTLSecondsAfterFinished is the number of seconds the RestoreJob is kept
+	// once it succeeded or failed. It is then deleted, while the restored
+	// Backup is kept. Defaults to the TTL configured on the backup controller.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 }
 
 // RestoreJobStatus represents the observed state of a RestoreJob.
@@ -65,6 +85,11 @@ type RestoreJobStatus struct {
 	// +optional
 	Message string `json:"message,omitempty"`
 
+	// Progress is the progress of the run as reported by the driver, if it
+	// reports any.
+	// +optional
+	Progress *JobProgress `json:"progress,omitempty"`
+
 	// Conditions represents the latest available observations of a RestoreJob's state.
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Package v1alpha1 defines backups.cozystack.io API types.
+//
+// Group: backups.cozystack.io
+// Version: v1alpha1
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion,
+			&S3Storage{},
+			&S3StorageList{},
+		)
+		return nil
+	})
+}
+
+const (
+	S3StorageKind = "S3Storage"
+
+	// S3StorageConditionReady reports whether the bucket of an S3Storage is
+	// reachable with its credentials. Plans and BackupJobs referring to an
+	// S3Storage wait for it to become ready before starting a backup.
+	S3StorageConditionReady = "Ready"
+
+	// S3StorageFinalizer keeps an S3Storage from being deleted while Plans
+	// or unfinished BackupJobs refer to it.
+	S3StorageFinalizer = thisGroup + "/s3storage-protection"
+
+	// Keys of the Secret referenced by spec.secretRef
+	S3StorageAccessKeyIDKey     = "accessKeyID"
+	S3StorageSecretAccessKeyKey = "secretAccessKey"
+)
+
+// S3EncryptionType is the server-side encryption applied to stored artifacts.
+// +kubebuilder:validation:Enum=AES256;"aws:kms"
+type S3EncryptionType string
+
+const (
+	S3EncryptionAES256 S3EncryptionType = "AES256"
+	S3EncryptionKMS    S3EncryptionType = "aws:kms"
+)
+
+// S3StorageSpec describes an S3-compatible bucket that backups are stored in.
+type S3StorageSpec struct {
+	// Endpoint is the URL of the S3 API, e.g. https://s3.example.com.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the name of the bucket.
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Prefix is the path within the bucket that artifacts are stored under.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region is the region of the bucket. Defaults to us-east-1.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// SecretRef refers to the Secret in the namespace of the S3Storage
+	// holding the credentials, in the accessKeyID and secretAccessKey keys.
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+
+	// Encryption configures the server-side encryption of stored artifacts.
+	// If omitted, the default encryption of the bucket applies.
+	// +optional
+	Encryption *S3Encryption `json:"encryption,omitempty"`
+}
+
+// S3Encryption describes the server-side encryption of stored artifacts.
+type S3Encryption struct {
+	// Type is the server-side encryption: AES256 or aws:kms.
+	Type S3EncryptionType `json:"type"`
+
+	// KMSKeyID is the KMS key used with the aws:kms encryption type.
+	// +optional
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+}
+
+// S3StorageStatus represents the observed state of an S3Storage.
+type S3StorageStatus struct {
+	// LastCheckedAt is the time the connectivity to the bucket was last checked.
+	// +optional
+	LastCheckedAt *metav1.Time `json:"lastCheckedAt,omitempty"`
+
+	// Conditions represents the latest available observations of the
+	// S3Storage's state.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".spec.endpoint",priority=0
+// +kubebuilder:printcolumn:name="Bucket",type="string",JSONPath=".spec.bucket",priority=0
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status",priority=0
+// +kubebuilder:printcolumn:name="Prefix",type="string",JSONPath=".spec.prefix",priority=1
+
+// S3Storage is a Storage backed by a bucket of an S3-compatible object store.
+// The controller periodically checks that the bucket is reachable with the
+// referenced credentials and reports the result in the Ready condition.
+type S3Storage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   S3StorageSpec   `json:"spec,omitempty"`
+	Status S3StorageStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// S3StorageList contains a list of S3Storages.
+type S3StorageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3Storage `json:"items"`
+}
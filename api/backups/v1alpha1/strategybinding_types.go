@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Package v1alpha1 defines backups.cozystack.io API types.
+//
+// Group: backups.cozystack.io
+// Version: v1alpha1
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion,
+			&StrategyBinding{},
+			&StrategyBindingList{},
+		)
+		return nil
+	})
+}
+
+const (
+	StrategyBindingKind = "StrategyBinding"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Strategy Kind",type=string,JSONPath=`.spec.strategyRef.kind`
+// +kubebuilder:printcolumn:name="Strategy",type=string,JSONPath=`.spec.strategyRef.name`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// StrategyBinding parameterizes a cluster-scoped backup strategy for use in
+// a namespace. Plans and BackupJobs reference a StrategyBinding in their
+// strategyRef instead of the strategy itself; the parameters are exposed to
+// the strategy templates as `.Parameters`.
+type StrategyBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec StrategyBindingSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StrategyBindingList contains a list of StrategyBindings.
+type StrategyBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StrategyBinding `json:"items"`
+}
+
+// StrategyBindingSpec references the bound strategy and the parameters
+// passed to it.
+type StrategyBindingSpec struct {
+	// StrategyRef holds a reference to the driver-specific strategy object,
+	// e.g. a Velero.strategy.backups.cozystack.io.
+	StrategyRef corev1.TypedLocalObjectReference `json:"strategyRef"`
+
+	// Parameters is a JSON object available to the strategy templates
+	// under `.Parameters`, e.g. paths or database names.
+	// +optional
+	Parameters *apiextensionsv1.JSON `json:"parameters,omitempty"`
+}
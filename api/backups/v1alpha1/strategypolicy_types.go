@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Package v1alpha1 defines backups.cozystack.io API types.
+//
+// Group: backups.cozystack.io
+// Version: v1alpha1
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion,
+			&StrategyPolicy{},
+			&StrategyPolicyList{},
+		)
+		return nil
+	})
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// StrategyPolicy maps application kinds to the strategies their Plans use by
+// default. Plans without spec.strategyRef are bound to the default strategy
+// of the kind of their application, so tenants can create Plans without
+// knowing which strategies the cluster provides.
+type StrategyPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec StrategyPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StrategyPolicyList contains a list of StrategyPolicies.
+type StrategyPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StrategyPolicy `json:"items"`
+}
+
+// StrategyPolicySpec lists the default strategies of application kinds.
+type StrategyPolicySpec struct {
+	// Defaults lists the default strategy of each application kind. An
+	// application kind must not have different defaults, neither in one
+	// StrategyPolicy nor across StrategyPolicies.
+	// +listType=map
+	// +listMapKey=apiGroup
+	// +listMapKey=kind
+	Defaults []DefaultStrategy `json:"defaults"`
+}
+
+// DefaultStrategy is the default strategy of an application kind.
+type DefaultStrategy struct {
+	// APIGroup is the API group of the application.
+	// +kubebuilder:default=apps.cozystack.io
+	APIGroup string `json:"apiGroup"`
+
+	// Kind is the kind of the application, e.g. Postgres.
+	Kind string `json:"kind"`
+
+	// StrategyRef holds a reference to the strategy Plans of this kind use
+	// when they omit spec.strategyRef. A StrategyBinding is looked up in the
+	// namespace of the Plan.
+	StrategyRef corev1.TypedLocalObjectReference `json:"strategyRef"`
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// Package v1alpha1 defines backups.cozystack.io API types.
+//
+// Group: backups.cozystack.io
+// Version: v1alpha1
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion,
+			&VerificationJob{},
+			&VerificationJobList{},
+		)
+		return nil
+	})
+}
+
+const (
+	// OwningVerificationJobNameLabel and OwningVerificationJobNamespaceLabel are
+	// set on the driver objects and scratch namespaces of a VerificationJob.
+	OwningVerificationJobNameLabel      = thisGroup + "/owned-by.VerificationJobName"
+	OwningVerificationJobNamespaceLabel = thisGroup + "/owned-by.VerificationJobNamespace"
+
+	// BackupConditionVerified is the condition set on a Backup with the result
+	// of the last VerificationJob run against it.
+	BackupConditionVerified = "Verified"
+)
+
+// VerificationJobPhase represents the lifecycle phase of a VerificationJob run.
+type VerificationJobPhase string
+
+const (
+	VerificationJobPhaseEmpty     VerificationJobPhase = ""
+	VerificationJobPhasePending   VerificationJobPhase = "Pending"
+	VerificationJobPhaseRunning   VerificationJobPhase = "Running"
+	VerificationJobPhaseSucceeded VerificationJobPhase = "Succeeded"
+	VerificationJobPhaseFailed    VerificationJobPhase = "Failed"
+)
+
+// VerificationJobSpec describes the verification of a Backup.
+type VerificationJobSpec struct {
+	// BackupRef refers to the Backup that should be verified.
+	BackupRef corev1.LocalObjectReference `json:"backupRef"`
+
+	// Schedule re-runs the verification periodically. If omitted, the
+	// verification runs once.
+	// +optional
+	Schedule *PlanSchedule `json:"schedule,omitempty"`
+
+	// ScratchRestore, if set, additionally restores the backup into a scratch
+	// namespace, which is deleted once the restore has completed.
+	// +optional
+	ScratchRestore *ScratchRestore `json:"scratchRestore,omitempty"`
+}
+
+// ScratchRestore describes the scratch restore of a verification.
+type ScratchRestore struct {
+	// Namespace is the scratch namespace the backup is restored into. It is
+	// created for every run and must not exist otherwise. Defaults to
+	// verify-<namespace>-<name> of the VerificationJob.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// VerificationJobStatus represents the observed state of a VerificationJob.
+type VerificationJobStatus struct {
+	// Phase is a high-level summary of the state of the current or last run.
+	// Typical values: Pending, Running, Succeeded, Failed.
+	// +optional
+	Phase VerificationJobPhase `json:"phase,omitempty"`
+
+	// StartedAt is the time at which the current or last run started.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is the time at which the last run completed (successfully
+	// or otherwise).
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// NextRunAt is the time of the next scheduled run, if any.
+	// +optional
+	NextRunAt *metav1.Time `json:"nextRunAt,omitempty"`
+
+	// Checksum is the checksum of the artifact downloaded by the last run,
+	// in the form sha256:<hex>.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// Message is a human-readable message indicating details about why the
+	// run is in its current phase, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Conditions report the result of each check of the current or last run:
+	// ArtifactVerified and, with a scratch restore, RestoreVerified.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupRef.name",priority=0
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",priority=0
+// +kubebuilder:printcolumn:name="Completed",type="date",JSONPath=".status.completedAt",priority=0
+// +kubebuilder:printcolumn:name="Next Run",type="date",JSONPath=".status.nextRunAt",priority=1
+
+// VerificationJob checks that a Backup can be restored, once or on a schedule.
+// Each run downloads the backup artifact and validates the checksum recorded
+// in the Backup, and optionally restores it into a scratch namespace. The
+// result is reported by the Verified condition of the Backup.
+type VerificationJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VerificationJobSpec   `json:"spec,omitempty"`
+	Status VerificationJobStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VerificationJobList contains a list of VerificationJobs.
+type VerificationJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerificationJob `json:"items"`
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ApplicationShadowKind = "ApplicationShadow"
+)
+
+// ApplicationShadowSpec identifies the application an ApplicationShadow stands for
+type ApplicationShadowSpec struct {
+	// ApplicationRef refers to the application in the namespace of the shadow,
+	// e.g. apps.cozystack.io Postgres/db
+	ApplicationRef corev1.TypedLocalObjectReference `json:"applicationRef"`
+
+	// HelmReleaseName is the name of the HelmRelease backing the application
+	HelmReleaseName string `json:"helmReleaseName"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.applicationRef.kind"
+// +kubebuilder:printcolumn:name="Application",type="string",JSONPath=".spec.applicationRef.name"
+// +kubebuilder:printcolumn:name="HelmRelease",type="string",JSONPath=".spec.helmReleaseName"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ApplicationShadow is a persisted stand-in for an Application served by the
+// Cozystack API. It is owned by the HelmRelease of the application, so other
+// objects can put an ownerReference on it and be garbage collected together
+// with the application.
+type ApplicationShadow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ApplicationShadowSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApplicationShadowList contains a list of ApplicationShadow
+type ApplicationShadowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApplicationShadow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ApplicationShadow{}, &ApplicationShadowList{})
+}
+
+// ApplicationShadowName returns the name of the ApplicationShadow of the
+// application of the given kind and name
+func ApplicationShadowName(kind, name string) string {
+	return strings.ToLower(kind) + "-" + name
+}
@@ -20,15 +20,78 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Annotations of a CozystackResourceDefinition tuning how the apps API server
+// serves requests to applications of its kind
+const (
+	// RateLimitQPSAnnotation limits the requests per second to applications of
+	// the kind, per namespace
+	RateLimitQPSAnnotation = "apps.cozystack.io/rate-limit-qps"
+	// RateLimitBurstAnnotation is the number of requests per namespace allowed
+	// above the rate limit in bursts. Defaults to the rate limit.
+	RateLimitBurstAnnotation = "apps.cozystack.io/rate-limit-burst"
+	// PriorityLevelAnnotation names the PriorityLevelConfiguration requests to
+	// applications of the kind are served at. A FlowSchema is generated for it.
+	PriorityLevelAnnotation = "apps.cozystack.io/priority-level"
+	// FlowSchemaPrecedenceAnnotation is the matching precedence of the
+	// generated FlowSchema. Defaults to 1000.
+	FlowSchemaPrecedenceAnnotation = "apps.cozystack.io/flow-schema-precedence"
+)
+
+// MigrateReleasePrefixFromAnnotation opts a CozystackResourceDefinition into
+// moving the HelmReleases named with the given previous release prefix to the
+// current one. The Helm releases are kept, only the HelmReleases are renamed.
+const MigrateReleasePrefixFromAnnotation = "cozystack.io/migrate-release-prefix-from"
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
 
 // CozystackResourceDefinition is the Schema for the cozystackresourcedefinitions API
 type CozystackResourceDefinition struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec CozystackResourceDefinitionSpec `json:"spec,omitempty"`
+	Spec   CozystackResourceDefinitionSpec   `json:"spec,omitempty"`
+	Status CozystackResourceDefinitionStatus `json:"status,omitempty"`
+}
+
+// CozystackResourceDefinitionStatus is the observed state of a CozystackResourceDefinition
+type CozystackResourceDefinitionStatus struct {
+	// PrefixMigration reports the progress of moving HelmReleases to the
+	// current release prefix, requested with the
+	// cozystack.io/migrate-release-prefix-from annotation
+	// +optional
+	PrefixMigration *PrefixMigrationStatus `json:"prefixMigration,omitempty"`
+}
+
+// PrefixMigrationPhase is the state of a release prefix migration
+// +kubebuilder:validation:Enum=Running;Completed;Failed
+type PrefixMigrationPhase string
+
+const (
+	PrefixMigrationRunning   PrefixMigrationPhase = "Running"
+	PrefixMigrationCompleted PrefixMigrationPhase = "Completed"
+	PrefixMigrationFailed    PrefixMigrationPhase = "Failed"
+)
+
+// PrefixMigrationStatus reports the progress of a release prefix migration
+type PrefixMigrationStatus struct {
+	// From is the previous release prefix
+	From string `json:"from"`
+	// To is the current release prefix
+	To string `json:"to"`
+	// Phase is the state of the migration
+	Phase PrefixMigrationPhase `json:"phase"`
+	// Total is the number of HelmReleases to migrate
+	Total int `json:"total"`
+	// Migrated is the number of HelmReleases migrated
+	Migrated int `json:"migrated"`
+	// Failed lists the HelmReleases that cannot be migrated, as namespace/name: reason
+	// +optional
+	Failed []string `json:"failed,omitempty"`
+	// LastUpdateTime is the time the progress was last updated
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -59,6 +122,57 @@ type CozystackResourceDefinitionSpec struct {
 
 	// Dashboard configuration for this resource
 	Dashboard *CozystackResourceDefinitionDashboard `json:"dashboard,omitempty"`
+
+	// Backup configuration for this resource
+	// +optional
+	Backup *CozystackResourceDefinitionBackup `json:"backup,omitempty"`
+
+	// Remediation configuration for the releases of this resource
+	// +optional
+	Remediation *CozystackResourceDefinitionRemediation `json:"remediation,omitempty"`
+}
+
+// CozystackResourceDefinitionBackup holds backup defaults for applications of this kind.
+type CozystackResourceDefinitionBackup struct {
+	// MaintenanceWindows are the default UTC time ranges ("HH:MM-HH:MM") in which
+	// scheduled backups of this application kind are run. Applications can override
+	// them with the backups.cozystack.io/maintenance-window annotation.
+	// +optional
+	MaintenanceWindows []string `json:"maintenanceWindows,omitempty"`
+}
+
+// DriftDetectionMode enumerates how changes made to the objects of a release
+// outside of Helm are handled.
+// +kubebuilder:validation:Enum=enabled;warn;disabled
+type DriftDetectionMode string
+
+const (
+	// DriftDetectionEnabled detects drift and corrects it by reapplying the release
+	DriftDetectionEnabled DriftDetectionMode = "enabled"
+	// DriftDetectionWarn detects drift and reports it without correcting it
+	DriftDetectionWarn DriftDetectionMode = "warn"
+	// DriftDetectionDisabled does not detect drift
+	DriftDetectionDisabled DriftDetectionMode = "disabled"
+)
+
+// CozystackResourceDefinitionRemediation configures how failed and drifted
+// releases of applications of this kind are remediated.
+//
+// Example YAML:
+//
+//	remediation:
+//	  retries: 3
+//	  driftDetection: warn
+type CozystackResourceDefinitionRemediation struct {
+	// Retries is the number of times a failed install or upgrade is retried.
+	// A negative value retries indefinitely. Defaults to -1.
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	Retries *int `json:"retries,omitempty"`
+	// DriftDetection controls whether changes made to the objects of the
+	// release outside of Helm are detected and corrected. Defaults to disabled.
+	// +optional
+	DriftDetection DriftDetectionMode `json:"driftDetection,omitempty"`
 }
 
 type CozystackResourceDefinitionChart struct {
@@ -88,6 +202,120 @@ type CozystackResourceDefinitionApplication struct {
 	Plural string `json:"plural"`
 	// Singular name of the application, used for UI and API
 	Singular string `json:"singular"`
+	// PreserveUnknownFields controls whether spec fields not described by the OpenAPI schema
+	// are kept when the application is stored as HelmRelease values.
+	// Defaults to true. When set to false, unknown fields are pruned on create and update.
+	// +optional
+	PreserveUnknownFields *bool `json:"preserveUnknownFields,omitempty"`
+	// ReservedKeys lists spec values injected by the platform, for example from the
+	// cozystack-values Secret, that users must not set. Nested keys are written as
+	// dot-separated paths, e.g. "global.cozystack". Keys starting with "_" are always reserved.
+	// +optional
+	ReservedKeys []string `json:"reservedKeys,omitempty"`
+	// DeprecatedValues lists spec values that are scheduled for removal. Setting
+	// them still works, but returns a warning to the client.
+	// +optional
+	DeprecatedValues []CozystackResourceDefinitionDeprecatedValue `json:"deprecatedValues,omitempty"`
+	// Connection describes where the connection details of an application are found.
+	// The API server resolves them into status.connection when a single application
+	// is retrieved.
+	// +optional
+	Connection *CozystackResourceDefinitionConnection `json:"connection,omitempty"`
+	// Scale exposes the number of replicas of an application through the scale
+	// subresource, for kubectl scale and HorizontalPodAutoscalers.
+	// +optional
+	Scale *CozystackResourceDefinitionScale `json:"scale,omitempty"`
+}
+
+// CozystackResourceDefinitionScale maps the scale subresource of an application
+// to its values.
+//
+// LabelSelector supports the same Go template variables as resourceNames.
+//
+// Example YAML:
+//
+//	scale:
+//	  replicasPath: .replicas
+//	  labelSelector: app.kubernetes.io/instance={{ .kind }}-{{ .name }}
+type CozystackResourceDefinitionScale struct {
+	// ReplicasPath is the path of the number of replicas in the values, as
+	// dot-separated keys, e.g. ".replicas" or ".nodeGroups.md0.replicas".
+	// +kubebuilder:validation:Pattern="^(\\.[^.]+)+$"
+	ReplicasPath string `json:"replicasPath"`
+	// LabelSelector selects the pods of an application. It is reported in the
+	// status of the scale subresource, as required by HorizontalPodAutoscalers.
+	// +optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// CozystackResourceDefinitionConnection describes the connection details of an application
+type CozystackResourceDefinitionConnection struct {
+	// Host clients connect to
+	// +optional
+	Host *CozystackResourceDefinitionConnectionValue `json:"host,omitempty"`
+	// Port clients connect to
+	// +optional
+	Port *CozystackResourceDefinitionConnectionValue `json:"port,omitempty"`
+	// CredentialsSecret resolves the name of the Secret holding the credentials
+	// +optional
+	CredentialsSecret *CozystackResourceDefinitionConnectionValue `json:"credentialsSecret,omitempty"`
+}
+
+// ConnectionValueSource enumerates where a connection detail is read from.
+// +kubebuilder:validation:Enum=Values;Service;Secret
+type ConnectionValueSource string
+
+const (
+	// ConnectionValueSourceValues reads the values of the release
+	ConnectionValueSourceValues ConnectionValueSource = "Values"
+	// ConnectionValueSourceService reads a Service of the application
+	ConnectionValueSourceService ConnectionValueSource = "Service"
+	// ConnectionValueSourceSecret resolves the name of a Secret of the application
+	ConnectionValueSourceSecret ConnectionValueSource = "Secret"
+)
+
+// CozystackResourceDefinitionConnectionValue reads a connection detail from the
+// release values or from a Service or Secret in the namespace of the release.
+//
+// Name supports the same Go template variables as resourceNames.
+//
+// Example YAML:
+//
+//	connection:
+//	  host:
+//	    source: Service
+//	    name: "{{ .name }}-rw"
+//	    jsonPath: "{.metadata.name}.{.metadata.namespace}.svc"
+//	  port:
+//	    source: Service
+//	    name: "{{ .name }}-rw"
+//	    jsonPath: "{.spec.ports[0].port}"
+//	  credentialsSecret:
+//	    source: Secret
+//	    name: "{{ .name }}-credentials"
+type CozystackResourceDefinitionConnectionValue struct {
+	// Source the value is read from
+	Source ConnectionValueSource `json:"source"`
+	// Name of the Service or Secret
+	// +optional
+	Name string `json:"name,omitempty"`
+	// JSONPath template evaluated against the release values or the Service,
+	// e.g. "{.spec.ports[0].port}". It is ignored for Secrets: only the name of
+	// an existing Secret is resolved, its data is never exposed.
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+}
+
+// CozystackResourceDefinitionDeprecatedValue describes a deprecated spec value
+type CozystackResourceDefinitionDeprecatedValue struct {
+	// Path of the deprecated value as a dot-separated path, e.g. "resources.cpu"
+	Path string `json:"path"`
+	// Replacement is the dot-separated path of the value to use instead, if any
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
+	// Message is an additional hint shown to users, e.g. the release removing the value
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type CozystackResourceDefinitionRelease struct {
@@ -98,6 +326,22 @@ type CozystackResourceDefinitionRelease struct {
 	Labels map[string]string `json:"labels,omitempty"`
 	// Prefix for the release name
 	Prefix string `json:"prefix"`
+	// Interval at which the release is reconciled. Defaults to 5m.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Timeout of Helm actions such as install and upgrade. Defaults to the
+	// timeout of helm-controller.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// MaxHistory is the number of revisions kept in the release history.
+	// Zero keeps an unlimited history. Defaults to the default of helm-controller.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxHistory *int `json:"maxHistory,omitempty"`
 }
 
 // CozystackResourceDefinitionResourceSelector extends metav1.LabelSelector with resourceNames support.
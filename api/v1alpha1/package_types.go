@@ -21,10 +21,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// PackageConfirmIgnoreCoreDependenciesAnnotation lists, comma-separated, the
+	// core dependencies a Package deliberately ignores in spec.ignoreDependencies
+	PackageConfirmIgnoreCoreDependenciesAnnotation = "cozystack.io/confirm-ignore-core-dependencies"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster,shortName={pkg,pkgs}
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Variant",type="string",JSONPath=".spec.variant",description="Selected variant"
+// +kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".spec.suspend",description="Whether the Package is suspended"
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".spec.revision",description="Source revision the Package is pinned to",priority=1
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status",description="Ready status"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].message",description="Ready message"
 
@@ -59,6 +67,8 @@ type PackageSpec struct {
 
 	// IgnoreDependencies is a list of package source dependencies to ignore
 	// Dependencies listed here will not be installed even if they are specified in the PackageSource
+	// Each entry must be a dependency of the selected variant; ignoring a core
+	// dependency must be confirmed with the cozystack.io/confirm-ignore-core-dependencies annotation
 	// +optional
 	IgnoreDependencies []string `json:"ignoreDependencies,omitempty"`
 
@@ -66,6 +76,20 @@ type PackageSpec struct {
 	// Allows overriding values and enabling/disabling specific components from the PackageSource
 	// +optional
 	Components map[string]PackageComponent `json:"components,omitempty"`
+
+	// Suspend stops the reconciliation of the Package and suspends its HelmReleases
+	// Resuming the Package reconciles and resumes its HelmReleases
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Revision pins the Package to a revision of the sourceRef of its
+	// PackageSource, so that it stays at a known-good revision while the
+	// repository moves forward: a commit SHA for a GitRepository, or a
+	// manifest digest (sha256:<hex>) for an OCIRepository. The revisions
+	// recorded in the status, such as main@sha1:<commit>, are accepted too.
+	// Components built from the additional sourceRefs are not pinned.
+	// +optional
+	Revision string `json:"revision,omitempty"`
 }
 
 // PackageComponent defines overrides for a specific component
@@ -91,6 +115,11 @@ type PackageStatus struct {
 	// Key is the dependency package name, value indicates if the dependency is ready
 	// +optional
 	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+
+	// Components tracks the state of the HelmRelease generated for each component
+	// Key is the component name
+	// +optional
+	Components map[string]ComponentStatus `json:"components,omitempty"`
 }
 
 // DependencyStatus represents the readiness status of a dependency
@@ -98,3 +127,62 @@ type DependencyStatus struct {
 	// Ready indicates whether the dependency is ready
 	Ready bool `json:"ready"`
 }
+
+// ComponentStatus represents the observed state of the HelmRelease of a component
+type ComponentStatus struct {
+	// HelmRelease is the HelmRelease generated for the component, as namespace/name
+	HelmRelease string `json:"helmRelease"`
+
+	// Ready is the status of the Ready condition of the HelmRelease
+	// Unknown until the HelmRelease has been reconciled by helm-controller
+	Ready metav1.ConditionStatus `json:"ready"`
+
+	// Reason is the reason of the Ready condition of the HelmRelease
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is the message of the Ready condition of the HelmRelease
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ChartVersion is the version of the chart last deployed by the HelmRelease,
+	// or last attempted if none was deployed yet
+	// +optional
+	ChartVersion string `json:"chartVersion,omitempty"`
+
+	// LastReconcileTime is when the HelmRelease was last deployed or changed readiness
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// HealthChecks reports the state of the health check targets of the component
+	// +optional
+	HealthChecks []HealthCheckStatus `json:"healthChecks,omitempty"`
+
+	// SourceRevision is the revision of the source the chart artifact of the
+	// component was generated from, for example main@sha1:<commit>
+	// +optional
+	SourceRevision string `json:"sourceRevision,omitempty"`
+
+	// ArtifactDigest is the digest of the chart artifact of the component
+	// +optional
+	ArtifactDigest string `json:"artifactDigest,omitempty"`
+}
+
+// HealthCheckStatus represents the observed state of a health check target
+type HealthCheckStatus struct {
+	// Kind is the kind of the workload
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the workload
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the workload
+	Name string `json:"name"`
+
+	// Ready indicates whether the workload is rolled out and available
+	Ready bool `json:"ready"`
+
+	// Message describes why the workload is not ready
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PackageRevisionPackageLabel is set on PackageRevisions to the name of their Package
+	PackageRevisionPackageLabel = "cozystack.io/package"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName={pkgrev,pkgrevs}
+// +kubebuilder:printcolumn:name="Package",type="string",JSONPath=".spec.packageName",description="Package this revision belongs to"
+// +kubebuilder:printcolumn:name="Revision",type="integer",JSONPath=".spec.revision",description="Revision number"
+// +kubebuilder:printcolumn:name="Variant",type="string",JSONPath=".spec.packageSpec.variant",description="Selected variant"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// PackageRevision records a Package spec that was successfully reconciled,
+// together with the HelmReleases generated from it. The operator keeps a
+// limited history of revisions per Package, so a Package can be rolled back.
+type PackageRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PackageRevisionSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PackageRevisionList contains a list of PackageRevisions
+type PackageRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PackageRevision `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PackageRevision{}, &PackageRevisionList{})
+}
+
+// PackageRevisionSpec defines a recorded state of a Package
+type PackageRevisionSpec struct {
+	// PackageName is the name of the Package
+	PackageName string `json:"packageName"`
+
+	// Revision is the number of the revision, increasing per Package
+	// +kubebuilder:validation:Minimum=1
+	Revision int64 `json:"revision"`
+
+	// PackageSpec is the reconciled spec of the Package: variant, ignored
+	// dependencies and component overrides
+	PackageSpec PackageSpec `json:"packageSpec"`
+
+	// HelmReleases are the HelmReleases generated from the spec
+	// +optional
+	HelmReleases []PackageRevisionHelmRelease `json:"helmReleases,omitempty"`
+}
+
+// PackageRevisionHelmRelease identifies a HelmRelease generated for a revision
+type PackageRevisionHelmRelease struct {
+	// Name of the HelmRelease
+	Name string `json:"name"`
+	// Namespace of the HelmRelease
+	Namespace string `json:"namespace"`
+	// Component of the PackageSource variant the HelmRelease was generated for
+	Component string `json:"component"`
+	// Chart is the name of the chart artifact the HelmRelease installs
+	// +optional
+	Chart string `json:"chart,omitempty"`
+}
+
+// PackageRevisionName returns the name of revision of the Package packageName
+func PackageRevisionName(packageName string, revision int64) string {
+	return fmt.Sprintf("%s-%d", packageName, revision)
+}
@@ -17,13 +17,22 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// PackageSourceCoreLabel marks PackageSources the platform cannot run without.
+	// Packages ignoring them as a dependency must confirm it with
+	// PackageConfirmIgnoreCoreDependenciesAnnotation.
+	PackageSourceCoreLabel = "cozystack.io/core"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster,shortName={pks}
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Variants",type="string",JSONPath=".status.variants",description="Package variants (comma-separated)"
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.revision",description="Revision of the source the artifacts were generated from",priority=1
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status",description="Ready status"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].message",description="Ready message"
 
@@ -55,6 +64,12 @@ type PackageSourceSpec struct {
 	// +optional
 	SourceRef *PackageSourceRef `json:"sourceRef,omitempty"`
 
+	// SourceRefs are additional sources, such as an internal fork of a few
+	// charts, that components select with their alias instead of SourceRef,
+	// or copy overlays from. They require SourceRef to be set.
+	// +optional
+	SourceRefs []AliasedPackageSourceRef `json:"sourceRefs,omitempty"`
+
 	// Variants is a list of package source variants
 	// Each variant defines components, applications, dependencies, and libraries for a specific configuration
 	// +optional
@@ -82,14 +97,32 @@ type Variant struct {
 }
 
 // Library defines a Helm library chart
+// +kubebuilder:validation:XValidation:rule="has(self.path) || has(self.libraryRef)",message="either path or libraryRef must be set"
 type Library struct {
 	// Name is the optional name for library placed in charts
 	// +optional
 	Name string `json:"name,omitempty"`
 
 	// Path is the path to the library chart directory
+	// Required unless LibraryRef is set
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// LibraryRef references a library of another PackageSource instead of a path
+	// in this source, so that packages can share a single copy of a library chart
+	// +optional
+	LibraryRef *LibraryReference `json:"libraryRef,omitempty"`
+}
+
+// LibraryReference references a library declared by another PackageSource
+type LibraryReference struct {
+	// PackageSource is the name of the PackageSource declaring the library
 	// +required
-	Path string `json:"path"`
+	PackageSource string `json:"packageSource"`
+
+	// Name is the name of the library in the referenced PackageSource
+	// +required
+	Name string `json:"name"`
 }
 
 // PackageSourceRef defines the source reference for package source charts
@@ -114,6 +147,33 @@ type PackageSourceRef struct {
 	Path string `json:"path,omitempty"`
 }
 
+// AliasedPackageSourceRef is an additional source of a PackageSource
+type AliasedPackageSourceRef struct {
+	// Alias identifies the source in the components of the PackageSource
+	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	// +required
+	Alias string `json:"alias"`
+
+	PackageSourceRef `json:",inline"`
+}
+
+// ComponentOverlay copies files from a source over the chart of a component
+type ComponentOverlay struct {
+	// Source is the alias of the source in SourceRefs the files are copied
+	// from. Defaults to SourceRef.
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// Path is the path of the files in the source, relative to its base path
+	// +required
+	Path string `json:"path"`
+
+	// To is the directory in the chart the files are copied to, defaults to
+	// the root of the chart
+	// +optional
+	To string `json:"to,omitempty"`
+}
+
 // ComponentInstall defines installation parameters for a component
 type ComponentInstall struct {
 	// ReleaseName is the name of the HelmRelease resource that will be created
@@ -125,6 +185,12 @@ type ComponentInstall struct {
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 
+	// TargetNamespace is the namespace the chart resources are rendered into,
+	// when it differs from Namespace. The HelmRelease and the Helm release
+	// storage stay in Namespace.
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
 	// Privileged indicates whether this release requires privileged access
 	// +optional
 	Privileged bool `json:"privileged,omitempty"`
@@ -132,17 +198,61 @@ type ComponentInstall struct {
 	// DependsOn is a list of component names that must be installed before this component
 	// +optional
 	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// HealthChecks lists workloads that must be ready, in addition to a successful
+	// Helm release, for the component to be reported ready
+	// +optional
+	HealthChecks []ComponentHealthCheck `json:"healthChecks,omitempty"`
+
+	// Test enables Helm tests of the release after each install or upgrade
+	// +optional
+	Test bool `json:"test,omitempty"`
+}
+
+// ComponentHealthCheck references a workload installed by a component
+type ComponentHealthCheck struct {
+	// Kind is the kind of the workload
+	// +kubebuilder:validation:Enum=Deployment;DaemonSet;StatefulSet
+	// +required
+	Kind string `json:"kind"`
+
+	// Name is the name of the workload
+	// +required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the workload
+	// Defaults to the namespace the chart resources are rendered into
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // Component defines a single Helm release component within a package source
+// +kubebuilder:validation:XValidation:rule="has(self.path) != has(self.chartRef)",message="exactly one of path or chartRef must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.chartRef) || (!has(self.libraries) && !has(self.valuesFiles) && !has(self.source) && !has(self.overlays))",message="libraries, valuesFiles, source and overlays can only be used with path"
 type Component struct {
 	// Name is the unique identifier for this component within the package source
 	// +required
 	Name string `json:"name"`
 
 	// Path is the path to the Helm chart directory
-	// +required
-	Path string `json:"path"`
+	// Required unless ChartRef is set
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Source is the alias of the source in SourceRefs the chart at Path and
+	// the values files are taken from. Defaults to SourceRef.
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// Overlays are copied over the chart at Path, in order, before the
+	// libraries and the values files, e.g. to replace a few templates
+	// +optional
+	Overlays []ComponentOverlay `json:"overlays,omitempty"`
+
+	// ChartRef references a Helm chart published to an OCI registry instead of
+	// a path in the source of the package source
+	// +optional
+	ChartRef *ComponentChartRef `json:"chartRef,omitempty"`
 
 	// Install defines installation parameters for this component
 	// +optional
@@ -156,6 +266,49 @@ type Component struct {
 	// ValuesFiles is a list of values file names to use
 	// +optional
 	ValuesFiles []string `json:"valuesFiles,omitempty"`
+
+	// ValuesSchema is an OpenAPI v3 schema the values of this component set in
+	// Packages must satisfy. Packages with values that do not are rejected on
+	// admission and not reconciled.
+	// +optional
+	ValuesSchema *apiextensionsv1.JSON `json:"valuesSchema,omitempty"`
+
+	// Configurable offers to edit the values of this component when the
+	// package is installed interactively with cozypkg add --edit-values
+	// +optional
+	Configurable bool `json:"configurable,omitempty"`
+
+	// When is a CEL expression selecting whether the component is installed,
+	// so that a variant can hold optional components. Dependencies on a
+	// component that is not installed are dropped. The expression can use
+	// values (the values of the component set in the Package), pkg.name,
+	// pkg.variant, cluster.config (the data of the cozystack ConfigMap),
+	// cluster.talos and cluster.provider (the node provider, empty without a
+	// cloud provider), for example: cluster.provider == ""
+	// +optional
+	When string `json:"when,omitempty"`
+}
+
+// ComponentChartRef references a Helm chart published to an OCI registry
+// +kubebuilder:validation:XValidation:rule="has(self.tag) != has(self.digest)",message="exactly one of tag or digest must be set"
+type ComponentChartRef struct {
+	// URL is the address of the chart in the OCI registry,
+	// for example: oci://ghcr.io/cozystack/charts/cilium
+	// +kubebuilder:validation:Pattern="^oci://.+$"
+	// +required
+	URL string `json:"url"`
+
+	// Tag is the tag of the chart, usually its version
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest pins the chart to a manifest digest, for example: sha256:<hex>
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// SecretRef is the name of a Secret in cozy-system with credentials for the registry
+	// +optional
+	SecretRef string `json:"secretRef,omitempty"`
 }
 
 // PackageSourceStatus defines the observed state of PackageSource
@@ -168,4 +321,33 @@ type PackageSourceStatus struct {
 	// Conditions represents the latest available observations of a PackageSource's state
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Revision is the revision of the artifact of SourceRef the artifacts were
+	// last generated from, for example main@sha1:<commit>. While the Package of
+	// the same name is pinned with spec.revision, it is the pinned revision.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Artifacts records, for each component built from the sources, the
+	// ExternalArtifact generated for it and the source revision it was
+	// generated from
+	// +optional
+	Artifacts []ArtifactStatus `json:"artifacts,omitempty"`
+}
+
+// ArtifactStatus represents the ExternalArtifact generated for a component
+type ArtifactStatus struct {
+	// Name is the name of the ExternalArtifact in cozy-system
+	Name string `json:"name"`
+	// Variant is the variant of the component
+	Variant string `json:"variant"`
+	// Component is the name of the component
+	Component string `json:"component"`
+	// Revision is the revision of the source of the component the artifact
+	// was generated from, for example main@sha1:<commit>
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// Digest is the digest of the artifact
+	// +optional
+	Digest string `json:"digest,omitempty"`
 }
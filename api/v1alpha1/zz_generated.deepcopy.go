@@ -27,9 +27,124 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AliasedPackageSourceRef) DeepCopyInto(out *AliasedPackageSourceRef) {
+	*out = *in
+	out.PackageSourceRef = in.PackageSourceRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AliasedPackageSourceRef.
+func (in *AliasedPackageSourceRef) DeepCopy() *AliasedPackageSourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(AliasedPackageSourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationShadow) DeepCopyInto(out *ApplicationShadow) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationShadow.
+func (in *ApplicationShadow) DeepCopy() *ApplicationShadow {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationShadow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationShadow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationShadowList) DeepCopyInto(out *ApplicationShadowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplicationShadow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationShadowList.
+func (in *ApplicationShadowList) DeepCopy() *ApplicationShadowList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationShadowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationShadowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationShadowSpec) DeepCopyInto(out *ApplicationShadowSpec) {
+	*out = *in
+	in.ApplicationRef.DeepCopyInto(&out.ApplicationRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationShadowSpec.
+func (in *ApplicationShadowSpec) DeepCopy() *ApplicationShadowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationShadowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactStatus) DeepCopyInto(out *ArtifactStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactStatus.
+func (in *ArtifactStatus) DeepCopy() *ArtifactStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
+	if in.Overlays != nil {
+		in, out := &in.Overlays, &out.Overlays
+		*out = make([]ComponentOverlay, len(*in))
+		copy(*out, *in)
+	}
+	if in.ChartRef != nil {
+		in, out := &in.ChartRef, &out.ChartRef
+		*out = new(ComponentChartRef)
+		**out = **in
+	}
 	if in.Install != nil {
 		in, out := &in.Install, &out.Install
 		*out = new(ComponentInstall)
@@ -45,6 +160,11 @@ func (in *Component) DeepCopyInto(out *Component) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ValuesSchema != nil {
+		in, out := &in.ValuesSchema, &out.ValuesSchema
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Component.
@@ -57,6 +177,36 @@ func (in *Component) DeepCopy() *Component {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentChartRef) DeepCopyInto(out *ComponentChartRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentChartRef.
+func (in *ComponentChartRef) DeepCopy() *ComponentChartRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentChartRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentHealthCheck) DeepCopyInto(out *ComponentHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentHealthCheck.
+func (in *ComponentHealthCheck) DeepCopy() *ComponentHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentInstall) DeepCopyInto(out *ComponentInstall) {
 	*out = *in
@@ -65,6 +215,11 @@ func (in *ComponentInstall) DeepCopyInto(out *ComponentInstall) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.HealthChecks != nil {
+		in, out := &in.HealthChecks, &out.HealthChecks
+		*out = make([]ComponentHealthCheck, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentInstall.
@@ -77,12 +232,52 @@ func (in *ComponentInstall) DeepCopy() *ComponentInstall {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentOverlay) DeepCopyInto(out *ComponentOverlay) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentOverlay.
+func (in *ComponentOverlay) DeepCopy() *ComponentOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
+	*out = *in
+	if in.LastReconcileTime != nil {
+		in, out := &in.LastReconcileTime, &out.LastReconcileTime
+		*out = (*in).DeepCopy()
+	}
+	if in.HealthChecks != nil {
+		in, out := &in.HealthChecks, &out.HealthChecks
+		*out = make([]HealthCheckStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
+func (in *ComponentStatus) DeepCopy() *ComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CozystackResourceDefinition) DeepCopyInto(out *CozystackResourceDefinition) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinition.
@@ -106,6 +301,31 @@ func (in *CozystackResourceDefinition) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CozystackResourceDefinitionApplication) DeepCopyInto(out *CozystackResourceDefinitionApplication) {
 	*out = *in
+	if in.PreserveUnknownFields != nil {
+		in, out := &in.PreserveUnknownFields, &out.PreserveUnknownFields
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReservedKeys != nil {
+		in, out := &in.ReservedKeys, &out.ReservedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeprecatedValues != nil {
+		in, out := &in.DeprecatedValues, &out.DeprecatedValues
+		*out = make([]CozystackResourceDefinitionDeprecatedValue, len(*in))
+		copy(*out, *in)
+	}
+	if in.Connection != nil {
+		in, out := &in.Connection, &out.Connection
+		*out = new(CozystackResourceDefinitionConnection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scale != nil {
+		in, out := &in.Scale, &out.Scale
+		*out = new(CozystackResourceDefinitionScale)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionApplication.
@@ -118,6 +338,26 @@ func (in *CozystackResourceDefinitionApplication) DeepCopy() *CozystackResourceD
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CozystackResourceDefinitionBackup) DeepCopyInto(out *CozystackResourceDefinitionBackup) {
+	*out = *in
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionBackup.
+func (in *CozystackResourceDefinitionBackup) DeepCopy() *CozystackResourceDefinitionBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(CozystackResourceDefinitionBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CozystackResourceDefinitionChart) DeepCopyInto(out *CozystackResourceDefinitionChart) {
 	*out = *in
@@ -134,6 +374,51 @@ func (in *CozystackResourceDefinitionChart) DeepCopy() *CozystackResourceDefinit
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CozystackResourceDefinitionConnection) DeepCopyInto(out *CozystackResourceDefinitionConnection) {
+	*out = *in
+	if in.Host != nil {
+		in, out := &in.Host, &out.Host
+		*out = new(CozystackResourceDefinitionConnectionValue)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(CozystackResourceDefinitionConnectionValue)
+		**out = **in
+	}
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(CozystackResourceDefinitionConnectionValue)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionConnection.
+func (in *CozystackResourceDefinitionConnection) DeepCopy() *CozystackResourceDefinitionConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(CozystackResourceDefinitionConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CozystackResourceDefinitionConnectionValue) DeepCopyInto(out *CozystackResourceDefinitionConnectionValue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionConnectionValue.
+func (in *CozystackResourceDefinitionConnectionValue) DeepCopy() *CozystackResourceDefinitionConnectionValue {
+	if in == nil {
+		return nil
+	}
+	out := new(CozystackResourceDefinitionConnectionValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CozystackResourceDefinitionDashboard) DeepCopyInto(out *CozystackResourceDefinitionDashboard) {
 	*out = *in
@@ -170,6 +455,21 @@ func (in *CozystackResourceDefinitionDashboard) DeepCopy() *CozystackResourceDef
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CozystackResourceDefinitionDeprecatedValue) DeepCopyInto(out *CozystackResourceDefinitionDeprecatedValue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionDeprecatedValue.
+func (in *CozystackResourceDefinitionDeprecatedValue) DeepCopy() *CozystackResourceDefinitionDeprecatedValue {
+	if in == nil {
+		return nil
+	}
+	out := new(CozystackResourceDefinitionDeprecatedValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CozystackResourceDefinitionList) DeepCopyInto(out *CozystackResourceDefinitionList) {
 	*out = *in
@@ -213,6 +513,21 @@ func (in *CozystackResourceDefinitionRelease) DeepCopyInto(out *CozystackResourc
 			(*out)[key] = val
 		}
 	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxHistory != nil {
+		in, out := &in.MaxHistory, &out.MaxHistory
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionRelease.
@@ -225,6 +540,46 @@ func (in *CozystackResourceDefinitionRelease) DeepCopy() *CozystackResourceDefin
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CozystackResourceDefinitionRemediation) DeepCopyInto(out *CozystackResourceDefinitionRemediation) {
+	*out = *in
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionRemediation.
+func (in *CozystackResourceDefinitionRemediation) DeepCopy() *CozystackResourceDefinitionRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(CozystackResourceDefinitionRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CozystackResourceDefinitionStatus) DeepCopyInto(out *CozystackResourceDefinitionStatus) {
+	*out = *in
+	if in.PrefixMigration != nil {
+		in, out := &in.PrefixMigration, &out.PrefixMigration
+		*out = new(PrefixMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionStatus.
+func (in *CozystackResourceDefinitionStatus) DeepCopy() *CozystackResourceDefinitionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CozystackResourceDefinitionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CozystackResourceDefinitionResourceSelector) DeepCopyInto(out *CozystackResourceDefinitionResourceSelector) {
 	*out = *in
@@ -283,10 +638,25 @@ func (in *CozystackResourceDefinitionResources) DeepCopy() *CozystackResourceDef
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CozystackResourceDefinitionScale) DeepCopyInto(out *CozystackResourceDefinitionScale) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionScale.
+func (in *CozystackResourceDefinitionScale) DeepCopy() *CozystackResourceDefinitionScale {
+	if in == nil {
+		return nil
+	}
+	out := new(CozystackResourceDefinitionScale)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CozystackResourceDefinitionSpec) DeepCopyInto(out *CozystackResourceDefinitionSpec) {
 	*out = *in
-	out.Application = in.Application
+	in.Application.DeepCopyInto(&out.Application)
 	in.Release.DeepCopyInto(&out.Release)
 	in.Secrets.DeepCopyInto(&out.Secrets)
 	in.Services.DeepCopyInto(&out.Services)
@@ -296,6 +666,16 @@ func (in *CozystackResourceDefinitionSpec) DeepCopyInto(out *CozystackResourceDe
 		*out = new(CozystackResourceDefinitionDashboard)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(CozystackResourceDefinitionBackup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Remediation != nil {
+		in, out := &in.Remediation, &out.Remediation
+		*out = new(CozystackResourceDefinitionRemediation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CozystackResourceDefinitionSpec.
@@ -323,9 +703,29 @@ func (in *DependencyStatus) DeepCopy() *DependencyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckStatus) DeepCopyInto(out *HealthCheckStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckStatus.
+func (in *HealthCheckStatus) DeepCopy() *HealthCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Library) DeepCopyInto(out *Library) {
 	*out = *in
+	if in.LibraryRef != nil {
+		in, out := &in.LibraryRef, &out.LibraryRef
+		*out = new(LibraryReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Library.
@@ -338,6 +738,21 @@ func (in *Library) DeepCopy() *Library {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LibraryReference) DeepCopyInto(out *LibraryReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibraryReference.
+func (in *LibraryReference) DeepCopy() *LibraryReference {
+	if in == nil {
+		return nil
+	}
+	out := new(LibraryReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Package) DeepCopyInto(out *Package) {
 	*out = *in
@@ -422,6 +837,100 @@ func (in *PackageList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevision) DeepCopyInto(out *PackageRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevision.
+func (in *PackageRevision) DeepCopy() *PackageRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PackageRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionHelmRelease) DeepCopyInto(out *PackageRevisionHelmRelease) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionHelmRelease.
+func (in *PackageRevisionHelmRelease) DeepCopy() *PackageRevisionHelmRelease {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionHelmRelease)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionList) DeepCopyInto(out *PackageRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PackageRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionList.
+func (in *PackageRevisionList) DeepCopy() *PackageRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PackageRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageRevisionSpec) DeepCopyInto(out *PackageRevisionSpec) {
+	*out = *in
+	in.PackageSpec.DeepCopyInto(&out.PackageSpec)
+	if in.HelmReleases != nil {
+		in, out := &in.HelmReleases, &out.HelmReleases
+		*out = make([]PackageRevisionHelmRelease, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageRevisionSpec.
+func (in *PackageRevisionSpec) DeepCopy() *PackageRevisionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageRevisionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageSource) DeepCopyInto(out *PackageSource) {
 	*out = *in
@@ -504,6 +1013,11 @@ func (in *PackageSourceSpec) DeepCopyInto(out *PackageSourceSpec) {
 		*out = new(PackageSourceRef)
 		**out = **in
 	}
+	if in.SourceRefs != nil {
+		in, out := &in.SourceRefs, &out.SourceRefs
+		*out = make([]AliasedPackageSourceRef, len(*in))
+		copy(*out, *in)
+	}
 	if in.Variants != nil {
 		in, out := &in.Variants, &out.Variants
 		*out = make([]Variant, len(*in))
@@ -533,6 +1047,11 @@ func (in *PackageSourceStatus) DeepCopyInto(out *PackageSourceStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]ArtifactStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageSourceStatus.
@@ -589,6 +1108,13 @@ func (in *PackageStatus) DeepCopyInto(out *PackageStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[string]ComponentStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageStatus.
@@ -601,6 +1127,30 @@ func (in *PackageStatus) DeepCopy() *PackageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrefixMigrationStatus) DeepCopyInto(out *PrefixMigrationStatus) {
+	*out = *in
+	if in.Failed != nil {
+		in, out := &in.Failed, &out.Failed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixMigrationStatus.
+func (in *PrefixMigrationStatus) DeepCopy() *PrefixMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrefixMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Selector) DeepCopyInto(out *Selector) {
 	{
@@ -648,7 +1198,9 @@ func (in *Variant) DeepCopyInto(out *Variant) {
 	if in.Libraries != nil {
 		in, out := &in.Libraries, &out.Libraries
 		*out = make([]Library, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Components != nil {
 		in, out := &in.Components, &out.Components
@@ -20,6 +20,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -37,6 +38,7 @@ import (
 
 	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
 	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
 	"github.com/cozystack/cozystack/internal/backupcontroller"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	// +kubebuilder:scaffold:imports
@@ -52,6 +54,7 @@ func init() {
 
 	utilruntime.Must(backupsv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(strategyv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(velerov1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
@@ -59,9 +62,13 @@ func init() {
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var leaderElectionNamespace string
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var defaultStorage string
+	var planConcurrency, backupJobConcurrency, restoreJobConcurrency int
+	var jobTTL time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -69,10 +76,21 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "",
+		"The namespace of the leader election lease. Defaults to the namespace the controller runs in.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&defaultStorage, "default-storage", "",
+		"Storage used by Plans and BackupJobs without storageRef in namespaces without the "+
+			"backups.cozystack.io/default-storage annotation, in the form [<apiGroup>/]<Kind>/<name>.")
+	flag.IntVar(&planConcurrency, "plan-concurrency", 1, "The number of Plans reconciled in parallel.")
+	flag.IntVar(&backupJobConcurrency, "backupjob-concurrency", 1, "The number of BackupJobs reconciled in parallel.")
+	flag.IntVar(&restoreJobConcurrency, "restorejob-concurrency", 1, "The number of RestoreJobs reconciled in parallel.")
+	flag.DurationVar(&jobTTL, "job-ttl-after-finished", 7*24*time.Hour,
+		"How long BackupJobs and RestoreJobs without spec.ttlSecondsAfterFinished are kept once they finished. "+
+			"Use 0 to keep them.")
 	opts := zap.Options{
 		Development: false,
 	}
@@ -128,52 +146,114 @@ func main() {
 	config.Burst = 100 // Increased from default 10
 
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
-		Scheme:                 scheme,
-		Metrics:                metricsServerOptions,
-		WebhookServer:          webhookServer,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "core.backups.cozystack.io",
-		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
-		// when the Manager ends. This requires the binary to immediately end when the
-		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
-		// speeds up voluntary leader transitions as the new leader don't have to wait
-		// LeaseDuration time first.
-		//
-		// In the default scaffold provided, the program ends immediately after
-		// the manager stops, so would be fine to enable this option. However,
-		// if you are doing or is intended to do any operation such as perform cleanups
-		// after the manager stops then its usage might be unsafe.
-		// LeaderElectionReleaseOnCancel: true,
+		Scheme:                  scheme,
+		Metrics:                 metricsServerOptions,
+		WebhookServer:           webhookServer,
+		HealthProbeBindAddress:  probeAddr,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        "core.backups.cozystack.io",
+		LeaderElectionNamespace: leaderElectionNamespace,
+		// The program ends immediately after the manager stops, so the leader can
+		// step down voluntarily and a standby replica takes over without waiting
+		// for the lease to expire, e.g. during rolling upgrades.
+		LeaderElectionReleaseOnCancel: true,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	storageResolver := &backupcontroller.StorageResolver{
+		Reader:  mgr.GetClient(),
+		Default: defaultStorage,
+	}
+
 	if err = (&backupcontroller.PlanReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("backup-controller"),
+		StorageResolver:         storageResolver,
+		MaxConcurrentReconciles: planConcurrency,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Plan")
 		os.Exit(1)
 	}
 
-	if err = (&backupcontroller.BackupJobReconciler{
+	if err = (&backupcontroller.RetentionReconciler{
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("backup-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PlanRetention")
+		os.Exit(1)
+	}
+
+	if err = (&backupcontroller.JobTTLReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		DefaultTTL: jobTTL,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "JobTTL")
+		os.Exit(1)
+	}
+
+	if err = (&backupcontroller.BackupJobReconciler{
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("backup-controller"),
+		StorageResolver:         storageResolver,
+		MaxConcurrentReconciles: backupJobConcurrency,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BackupJob")
 		os.Exit(1)
 	}
 
+	if err = (&backupcontroller.RestoreJobReconciler{
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("backup-controller"),
+		MaxConcurrentReconciles: restoreJobConcurrency,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RestoreJob")
+		os.Exit(1)
+	}
+
+	if err = (&backupcontroller.VerificationJobReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("backup-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VerificationJob")
+		os.Exit(1)
+	}
+
+	if err = (&backupcontroller.S3StorageReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("backup-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "S3Storage")
+		os.Exit(1)
+	}
+
+	if err = (&backupcontroller.RestoreJobValidator{
+		Client: mgr.GetClient(),
+	}).SetupWithManagerAsWebhook(mgr); err != nil {
+		setupLog.Error(err, "unable to setup webhook", "webhook", "RestoreJob")
+		os.Exit(1)
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
+	// The RestoreJob webhook is served by every replica, not only the leader
+	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+		setupLog.Error(err, "unable to set up webhook ready check")
+		os.Exit(1)
+	}
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
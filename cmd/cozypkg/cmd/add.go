@@ -21,17 +21,18 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -41,8 +42,27 @@ import (
 )
 
 var addCmdFlags struct {
-	files      []string
-	kubeconfig string
+	files          []string
+	kubeconfig     string
+	dryRun         bool
+	diff           bool
+	fresh          bool
+	variant        string
+	set            []string
+	nonInteractive bool
+	yes            bool
+	editValues     bool
+}
+
+// variantSelections holds how planPackageInstall selects the variants of the
+// Packages it creates
+type variantSelections struct {
+	// explicit are the variants given on the command line, by package
+	explicit map[string]string
+	// saved are the variants saved by an interrupted install, by package
+	saved map[string]string
+	// nonInteractive fails instead of prompting when a package has several variants
+	nonInteractive bool
 }
 
 var addCmd = &cobra.Command{
@@ -51,15 +71,42 @@ var addCmd = &cobra.Command{
 	Long: `Install PackageSource and its dependencies interactively.
 
 You can specify packages as arguments or use -f flag to read from files.
-Multiple -f flags can be specified, and they can point to files or directories.`,
+Multiple -f flags can be specified, and they can point to YAML files of one or
+more documents, directories, kustomization directories, http(s) URLs, or - for
+stdin. Packages declared in them are created with their variant and component
+overrides as they are; PackageSources are installed interactively.
+
+With --dry-run, nothing is created: the Packages and the HelmReleases the operator
+would generate from them are printed instead. With --diff, they are compared with
+the cluster state and the differences are printed.
+
+The selected variants are saved until all Packages of an install are created.
+If creating a Package fails, rerunning add offers to resume with the saved
+selections instead of asking again; use --fresh to discard them.
+
+For use in scripts and CI, variants can be given with --variant for the
+requested packages and with --set <package>=<variant> for any package. With
+--non-interactive, add never prompts: packages with a single variant use it,
+and add fails if the variant of a package with several variants is not given.
+Saved selections are resumed without asking with --yes or --non-interactive.
+
+With --edit-values, add offers to edit the values of the components marked
+configurable in the PackageSource after the variants are selected. The editor
+($VISUAL or $EDITOR, vi by default) is seeded with the default values of the
+component, and the values changed from them are saved as component overrides
+in the Package.`,
+	Example: `  cozypkg add cozystack.postgres-operator
+  cozypkg add cozystack.cilium --variant kubeovn --non-interactive
+  cozypkg add cozystack.monitoring --set cozystack.networking=cilium --non-interactive
+  cozypkg add cozystack.postgres-operator --edit-values`,
 	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
 		// Collect package names from arguments and files
 		packageNames := make(map[string]bool)
-		packagesFromFiles := make(map[string]string) // packageName -> filePath
-		
+		packagesFromFiles := make(map[string]filePackage)
+
 		for _, arg := range args {
 			packageNames[arg] = true
 		}
@@ -71,21 +118,35 @@ Multiple -f flags can be specified, and they can point to files or directories.`
 				return fmt.Errorf("failed to read packages from %s: %w", filePath, err)
 			}
 			for _, pkg := range packages {
-				packageNames[pkg] = true
-				if oldPath, ok := packagesFromFiles[pkg]; ok {
-					fmt.Fprintf(os.Stderr, "warning: package %q is defined in both %s and %s, using the latter\n", pkg, oldPath, filePath)
+				packageNames[pkg.name] = true
+				if old, ok := packagesFromFiles[pkg.name]; ok {
+					fmt.Fprintf(os.Stderr, "warning: package %q is defined in both %s and %s, using the latter\n", pkg.name, old.source, pkg.source)
 				}
-				packagesFromFiles[pkg] = filePath
+				packagesFromFiles[pkg.name] = pkg
 			}
 		}
 
 		if len(packageNames) == 0 {
 			return fmt.Errorf("no packages specified")
 		}
+		if addCmdFlags.editValues && addCmdFlags.nonInteractive {
+			return fmt.Errorf("--edit-values cannot be used with --non-interactive")
+		}
+
+		explicit, err := parseVariantSelections(addCmdFlags.set)
+		if err != nil {
+			return err
+		}
+		if addCmdFlags.variant != "" {
+			for packageName := range packageNames {
+				if _, ok := explicit[packageName]; !ok {
+					explicit[packageName] = addCmdFlags.variant
+				}
+			}
+		}
 
 		// Create Kubernetes client config
 		var config *rest.Config
-		var err error
 
 		if addCmdFlags.kubeconfig != "" {
 			config, err = clientcmd.BuildConfigFromFlags("", addCmdFlags.kubeconfig)
@@ -102,136 +163,133 @@ Multiple -f flags can be specified, and they can point to files or directories.`
 		scheme := runtime.NewScheme()
 		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+		utilruntime.Must(sourcev1.AddToScheme(scheme))
 
 		k8sClient, err := client.New(config, client.Options{Scheme: scheme})
 		if err != nil {
 			return fmt.Errorf("failed to create k8s client: %w", err)
 		}
 
+		var editor *valuesEditor
+		if addCmdFlags.editValues {
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return fmt.Errorf("failed to create k8s clientset: %w", err)
+			}
+			editor = &valuesEditor{k8sClient: k8sClient, clientset: clientset}
+		}
+
+		dryRun := addCmdFlags.dryRun || addCmdFlags.diff
+		// Packages that would be created in dry-run mode, by name
+		planned := make(map[string]*cozyv1alpha1.Package)
+		var plannedOrder []string
+
 		// Process each package
 		for packageName := range packageNames {
-			// Check if package comes from a file
-			if filePath, fromFile := packagesFromFiles[packageName]; fromFile {
-				// Try to create Package directly from file
-				if err := createPackageFromFile(ctx, k8sClient, filePath, packageName); err == nil {
-					fmt.Fprintf(os.Stderr, "✓ Added Package %s\n", packageName)
-					continue
+			// Packages declared in a file are created as they are
+			if filePkg, fromFile := packagesFromFiles[packageName]; fromFile {
+				if pkg := filePkg.pkg; pkg != nil {
+					if dryRun {
+						planned[pkg.Name] = pkg
+						plannedOrder = append(plannedOrder, pkg.Name)
+						continue
+					}
+					if err := k8sClient.Create(ctx, pkg); err == nil {
+						fmt.Fprintf(os.Stderr, "✓ Added Package %s\n", packageName)
+						continue
+					}
 				}
 				// If failed, fall back to interactive installation
 			}
-			
-			// Interactive installation from PackageSource
-			if err := installPackage(ctx, k8sClient, packageName); err != nil {
-				return err
-			}
-		}
 
-		return nil
-	},
-}
-
-func readPackagesFromFile(filePath string) ([]string, error) {
-	info, err := os.Stat(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	var packages []string
+			// Selections saved by an interrupted install of this package
+			var saved map[string]string
+			var state *addState
+			stateKey := addStateKey(config.Host, packageName)
+			if !dryRun {
+				if state, err = loadAddState(); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠ Failed to load saved selections: %v\n", err)
+				} else if install, ok := state.Installs[stateKey]; ok && !addCmdFlags.fresh {
+					resume := addCmdFlags.yes || addCmdFlags.nonInteractive
+					if !resume {
+						if resume, err = confirmResume(packageName, install); err != nil {
+							return err
+						}
+					}
+					if resume {
+						saved = install.Variants
+					}
+				}
+			}
 
-	if info.IsDir() {
-		// Read all YAML files from directory
-		err := filepath.Walk(filePath, func(path string, info os.FileInfo, err error) error {
+			// Installation from PackageSource
+			pkgs, err := planPackageInstall(ctx, k8sClient, packageName, planned, variantSelections{
+				explicit:       explicit,
+				saved:          saved,
+				nonInteractive: addCmdFlags.nonInteractive,
+			})
 			if err != nil {
 				return err
 			}
-			if info.IsDir() || !strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml") {
-				return nil
+			if editor != nil {
+				for _, pkg := range pkgs {
+					if err := editor.editPackageValues(ctx, pkg); err != nil {
+						return fmt.Errorf("failed to edit values of %s: %w", pkg.Name, err)
+					}
+				}
 			}
-
-			pkgs, err := readPackagesFromYAMLFile(path)
-			if err != nil {
-				return fmt.Errorf("failed to read %s: %w", path, err)
+			if dryRun {
+				for _, pkg := range pkgs {
+					planned[pkg.Name] = pkg
+					plannedOrder = append(plannedOrder, pkg.Name)
+				}
+				continue
 			}
-			packages = append(packages, pkgs...)
-			return nil
-		})
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		packages, err = readPackagesFromYAMLFile(filePath)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	return packages, nil
-}
-
-func readPackagesFromYAMLFile(filePath string) ([]string, error) {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
-	}
 
-	var packages []string
-
-	// Split YAML documents (in case of multiple resources)
-	documents := strings.Split(string(data), "---")
-	
-	for _, doc := range documents {
-		doc = strings.TrimSpace(doc)
-		if doc == "" {
-			continue
-		}
-
-		// Parse using Kubernetes decoder
-		decoder := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
-		obj := &unstructured.Unstructured{}
-		_, _, err := decoder.Decode([]byte(doc), nil, obj)
-		if err != nil {
-			continue
-		}
-
-		// Check if it's a Package
-		if obj.GetKind() == "Package" {
-			name := obj.GetName()
-			if name != "" {
-				packages = append(packages, name)
+			// Save the selections until all Packages are created
+			if state != nil && len(pkgs) > 0 {
+				install := addInstallState{Variants: map[string]string{}, UpdatedAt: time.Now()}
+				for _, pkg := range pkgs {
+					install.Variants[pkg.Name] = pkg.Spec.Variant
+				}
+				state.Installs[stateKey] = install
+				if err := state.save(); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠ Failed to save selections: %v\n", err)
+				}
 			}
-			continue
-		}
-
-		// Check if it's a PackageSource
-		if obj.GetKind() == "PackageSource" {
-			name := obj.GetName()
-			if name != "" {
-				packages = append(packages, name)
+			for _, pkg := range pkgs {
+				if err := k8sClient.Create(ctx, pkg); err != nil {
+					// Created by an earlier attempt or concurrently
+					if apierrors.IsAlreadyExists(err) {
+						fmt.Fprintf(os.Stderr, "✓ Package %s already exists\n", pkg.Name)
+						continue
+					}
+					if state != nil {
+						fmt.Fprintf(os.Stderr, "⚠ Selections saved, run cozypkg add %s again to resume\n", packageName)
+					}
+					return fmt.Errorf("failed to create Package %s: %w", pkg.Name, err)
+				}
+				fmt.Fprintf(os.Stderr, "✓ Added Package %s\n", pkg.Name)
 			}
-			continue
-		}
-
-		// Try to parse as PackageList or PackageSourceList
-		if obj.GetKind() == "PackageList" || obj.GetKind() == "PackageSourceList" {
-			items, found, err := unstructured.NestedSlice(obj.Object, "items")
-			if err == nil && found {
-				for _, item := range items {
-					if itemMap, ok := item.(map[string]interface{}); ok {
-						if metadata, ok := itemMap["metadata"].(map[string]interface{}); ok {
-							if name, ok := metadata["name"].(string); ok && name != "" {
-								packages = append(packages, name)
-							}
-						}
+			if state != nil {
+				if _, ok := state.Installs[stateKey]; ok {
+					delete(state.Installs, stateKey)
+					if err := state.save(); err != nil {
+						fmt.Fprintf(os.Stderr, "⚠ Failed to save selections: %v\n", err)
 					}
 				}
 			}
-			continue
 		}
-	}
 
-	// Return empty list if no packages found - don't error out
-	// The check for whether any packages were specified at all is handled later in RunE
-	return packages, nil
+		if dryRun {
+			pkgs := make([]*cozyv1alpha1.Package, 0, len(plannedOrder))
+			for _, name := range plannedOrder {
+				pkgs = append(pkgs, planned[name])
+			}
+			return previewPackages(ctx, k8sClient, scheme, pkgs, addCmdFlags.diff)
+		}
+		return nil
+	},
 }
 
 // buildDependencyTree builds a dependency tree starting from the root PackageSource
@@ -345,73 +403,34 @@ func topologicalSort(tree map[string][]string) ([]string, error) {
 	return result, nil
 }
 
-// createPackageFromFile creates a Package resource directly from a YAML file
-func createPackageFromFile(ctx context.Context, k8sClient client.Client, filePath string, packageName string) error {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	// Split YAML documents
-	documents := strings.Split(string(data), "---")
-	
-	for _, doc := range documents {
-		doc = strings.TrimSpace(doc)
-		if doc == "" {
-			continue
-		}
-
-		// Parse using Kubernetes decoder
-		decoder := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
-		obj := &unstructured.Unstructured{}
-		_, _, err := decoder.Decode([]byte(doc), nil, obj)
-		if err != nil {
-			continue
-		}
-
-		// Check if it's a Package with matching name
-		if obj.GetKind() == "Package" && obj.GetName() == packageName {
-			// Convert to Package
-			var pkg cozyv1alpha1.Package
-			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pkg); err != nil {
-				return fmt.Errorf("failed to convert Package: %w", err)
-			}
-
-			// Create Package
-			if err := k8sClient.Create(ctx, &pkg); err != nil {
-				return fmt.Errorf("failed to create Package: %w", err)
-			}
-
-			return nil
-		}
-	}
-
-	return fmt.Errorf("Package %s not found in file", packageName)
-}
-
-func installPackage(ctx context.Context, k8sClient client.Client, packageSourceName string) error {
+// planPackageInstall resolves the dependencies of a PackageSource, selects variants
+// and returns the Packages to create, dependencies first. Packages that are installed
+// or in planned are not returned again. The variant of each Package is taken from
+// the explicit selections, then from the saved ones if the variant still exists, and
+// is asked for otherwise.
+func planPackageInstall(ctx context.Context, k8sClient client.Client, packageSourceName string, planned map[string]*cozyv1alpha1.Package, selections variantSelections) ([]*cozyv1alpha1.Package, error) {
 	// Get PackageSource
 	packageSource := &cozyv1alpha1.PackageSource{}
 	if err := k8sClient.Get(ctx, client.ObjectKey{Name: packageSourceName}, packageSource); err != nil {
-		return fmt.Errorf("failed to get PackageSource %s: %w", packageSourceName, err)
+		return nil, fmt.Errorf("failed to get PackageSource %s: %w", packageSourceName, err)
 	}
 
 	// Build dependency tree
 	dependencyTree, dependencyRequesters, err := buildDependencyTree(ctx, k8sClient, packageSourceName)
 	if err != nil {
-		return fmt.Errorf("failed to build dependency tree: %w", err)
+		return nil, fmt.Errorf("failed to build dependency tree: %w", err)
 	}
 
 	// Topological sort (install from root to leaves)
 	installOrder, err := topologicalSort(dependencyTree)
 	if err != nil {
-		return fmt.Errorf("failed to sort dependencies: %w", err)
+		return nil, fmt.Errorf("failed to sort dependencies: %w", err)
 	}
 
 	// Get all PackageSources for variant selection
 	var allPackageSources cozyv1alpha1.PackageSourceList
 	if err := k8sClient.List(ctx, &allPackageSources); err != nil {
-		return fmt.Errorf("failed to list PackageSources: %w", err)
+		return nil, fmt.Errorf("failed to list PackageSources: %w", err)
 	}
 
 	packageSourceMap := make(map[string]*cozyv1alpha1.PackageSource)
@@ -422,17 +441,22 @@ func installPackage(ctx context.Context, k8sClient client.Client, packageSourceN
 	// Get all installed Packages
 	var installedPackages cozyv1alpha1.PackageList
 	if err := k8sClient.List(ctx, &installedPackages); err != nil {
-		return fmt.Errorf("failed to list Packages: %w", err)
+		return nil, fmt.Errorf("failed to list Packages: %w", err)
 	}
 
 	installedMap := make(map[string]*cozyv1alpha1.Package)
 	for i := range installedPackages.Items {
 		installedMap[installedPackages.Items[i].Name] = &installedPackages.Items[i]
 	}
+	// Packages planned earlier in a dry run count as installed
+	for name, pkg := range planned {
+		installedMap[name] = pkg
+	}
 
 	// First, collect all variant selections
 	fmt.Fprintf(os.Stderr, "Installing %s and its dependencies...\n\n", packageSourceName)
 	packageVariants := make(map[string]string) // packageName -> variant
+	var ambiguous []string                     // packages whose variant is not given in non-interactive mode
 
 	for _, pkgName := range installOrder {
 		// Check if already installed
@@ -451,21 +475,56 @@ func installPackage(ctx context.Context, k8sClient client.Client, packageSourceN
 		if !exists {
 			requester := dependencyRequesters[pkgName]
 			if requester != "" {
-				return fmt.Errorf("PackageSource %s not found (required by %s)", pkgName, requester)
+				return nil, fmt.Errorf("PackageSource %s not found (required by %s)", pkgName, requester)
 			}
-			return fmt.Errorf("PackageSource %s not found", pkgName)
+			return nil, fmt.Errorf("PackageSource %s not found", pkgName)
+		}
+
+		// Use the variant given on the command line
+		if variant, ok := selections.explicit[pkgName]; ok {
+			if findVariant(ps, variant) == nil {
+				return nil, fmt.Errorf("variant %s not found in PackageSource %s (available variants: %s)", variant, pkgName, strings.Join(variantNames(ps), ", "))
+			}
+			fmt.Fprintf(os.Stderr, "✓ %s (variant: %s)\n", pkgName, variant)
+			packageVariants[pkgName] = variant
+			continue
+		}
+
+		// Reuse the selection of an interrupted install
+		if variant, ok := selections.saved[pkgName]; ok && findVariant(ps, variant) != nil {
+			fmt.Fprintf(os.Stderr, "✓ %s (resumed, variant: %s)\n", pkgName, variant)
+			packageVariants[pkgName] = variant
+			continue
+		}
+
+		if selections.nonInteractive {
+			switch len(ps.Spec.Variants) {
+			case 0:
+				return nil, fmt.Errorf("no variants available for PackageSource %s", ps.Name)
+			case 1:
+				variant := ps.Spec.Variants[0].Name
+				fmt.Fprintf(os.Stderr, "✓ %s (only variant: %s)\n", pkgName, variant)
+				packageVariants[pkgName] = variant
+			default:
+				ambiguous = append(ambiguous, fmt.Sprintf("%s (variants: %s)", pkgName, strings.Join(variantNames(ps), ", ")))
+			}
+			continue
 		}
 
 		// Select variant interactively
 		variant, err := selectVariantInteractive(ps)
 		if err != nil {
-			return fmt.Errorf("failed to select variant for %s: %w", pkgName, err)
+			return nil, fmt.Errorf("failed to select variant for %s: %w", pkgName, err)
 		}
 
 		packageVariants[pkgName] = variant
 	}
+	if len(ambiguous) > 0 {
+		return nil, fmt.Errorf("cannot select variants non-interactively, use --set <package>=<variant> for: %s", strings.Join(ambiguous, "; "))
+	}
 
-	// Now create all Package resources
+	// Now build all Package resources
+	var pkgs []*cozyv1alpha1.Package
 	for _, pkgName := range installOrder {
 		// Skip if already installed
 		if _, exists := installedMap[pkgName]; exists {
@@ -484,14 +543,27 @@ func installPackage(ctx context.Context, k8sClient client.Client, packageSourceN
 			},
 		}
 
-		if err := k8sClient.Create(ctx, pkg); err != nil {
-			return fmt.Errorf("failed to create Package %s: %w", pkgName, err)
-		}
-
-		fmt.Fprintf(os.Stderr, "✓ Added Package %s\n", pkgName)
+		pkgs = append(pkgs, pkg)
 	}
 
-	return nil
+	return pkgs, nil
+}
+
+// parseVariantSelections parses --set values of the form <package>=<variant>
+func parseVariantSelections(values []string) (map[string]string, error) {
+	selections := make(map[string]string, len(values))
+	for _, value := range values {
+		name, variant, ok := strings.Cut(value, "=")
+		name, variant = strings.TrimSpace(name), strings.TrimSpace(variant)
+		if !ok || name == "" || variant == "" {
+			return nil, fmt.Errorf("invalid --set %q, expected <package>=<variant>", value)
+		}
+		if previous, ok := selections[name]; ok && previous != variant {
+			return nil, fmt.Errorf("conflicting variants for %s: %s and %s", name, previous, variant)
+		}
+		selections[name] = variant
+	}
+	return selections, nil
 }
 
 // selectVariantInteractive prompts user to select a variant
@@ -518,7 +590,7 @@ func selectVariantInteractive(ps *cozyv1alpha1.PackageSource) (string, error) {
 	}
 
 	for {
-		fmt.Fprintf(os.Stderr, prompt)
+		fmt.Fprint(os.Stderr, prompt)
 		input, err := reader.ReadString('\n')
 		if err != nil {
 			return "", fmt.Errorf("failed to read input: %w", err)
@@ -543,7 +615,15 @@ func selectVariantInteractive(ps *cozyv1alpha1.PackageSource) (string, error) {
 
 func init() {
 	rootCmd.AddCommand(addCmd)
-	addCmd.Flags().StringArrayVarP(&addCmdFlags.files, "file", "f", []string{}, "Read packages from file or directory (can be specified multiple times)")
+	addCmd.Flags().StringArrayVarP(&addCmdFlags.files, "file", "f", []string{}, "Read packages from a file, directory, kustomization, http(s) URL or - for stdin (can be specified multiple times)")
 	addCmd.Flags().StringVar(&addCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+	addCmd.Flags().BoolVar(&addCmdFlags.dryRun, "dry-run", false, "Print the Packages and HelmReleases that would be created without creating them")
+	addCmd.Flags().BoolVar(&addCmdFlags.diff, "diff", false, "Print a diff of the Packages and HelmReleases that would be created against the cluster (implies --dry-run)")
+	addCmd.Flags().BoolVar(&addCmdFlags.fresh, "fresh", false, "Discard selections saved by an interrupted install and ask again")
+	addCmd.Flags().StringVar(&addCmdFlags.variant, "variant", "", "Variant of the requested packages instead of selecting it interactively")
+	addCmd.Flags().StringArrayVar(&addCmdFlags.set, "set", []string{}, "Variant of a package in the form <package>=<variant> (can be specified multiple times)")
+	addCmd.Flags().BoolVar(&addCmdFlags.nonInteractive, "non-interactive", false, "Never prompt; fail if the variant of a package with several variants is not given")
+	addCmd.Flags().BoolVarP(&addCmdFlags.yes, "yes", "y", false, "Resume selections saved by an interrupted install without asking")
+	addCmd.Flags().BoolVar(&addCmdFlags.editValues, "edit-values", false, "Offer to edit the values of configurable components in an editor")
 }
 
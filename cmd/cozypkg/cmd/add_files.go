@@ -0,0 +1,235 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// fileReadTimeout bounds downloading a file given with -f as a URL
+const fileReadTimeout = 30 * time.Second
+
+// filePackage is a package read with -f
+type filePackage struct {
+	// name is the name of the Package or PackageSource
+	name string
+	// source is where the package was read from
+	source string
+	// pkg is the Package as declared, with its variant and component
+	// overrides. It is nil when only a PackageSource is named.
+	pkg *cozyv1alpha1.Package
+}
+
+// readPackagesFromFile reads the packages declared at filePath: a YAML file of
+// one or more documents, a directory of them, a kustomization directory built
+// with kustomize, an http(s) URL, or - for stdin
+func readPackagesFromFile(filePath string) ([]filePackage, error) {
+	switch {
+	case filePath == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return parsePackages(data, "stdin")
+	case strings.HasPrefix(filePath, "https://") || strings.HasPrefix(filePath, "http://"):
+		data, err := downloadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return parsePackages(data, filePath)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return parsePackages(data, filePath)
+	}
+
+	var packages []filePackage
+	err = filepath.WalkDir(filePath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if !isKustomization(path) {
+				return nil
+			}
+			pkgs, err := buildKustomization(path)
+			if err != nil {
+				return fmt.Errorf("failed to build kustomization %s: %w", path, err)
+			}
+			packages = append(packages, pkgs...)
+			// The files of a kustomization are only read through it
+			return filepath.SkipDir
+		}
+		if !strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pkgs, err := parsePackages(data, path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		packages = append(packages, pkgs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// downloadFile returns the content at url
+func downloadFile(url string) ([]byte, error) {
+	client := &http.Client{Timeout: fileReadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// isKustomization reports whether dir holds a kustomization file
+func isKustomization(dir string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// buildKustomization returns the packages of the resources built from the
+// kustomization in dir
+func buildKustomization(dir string) ([]filePackage, error) {
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := resources.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+	return parsePackages(data, dir)
+}
+
+// parsePackages returns the Packages and PackageSources declared in the YAML or
+// JSON documents of data, including the items of lists. Other resources are
+// ignored.
+func parsePackages(data []byte, source string) ([]filePackage, error) {
+	var packages []filePackage
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return packages, nil
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		pkgs, err := packagesOf(obj, source)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, pkgs...)
+	}
+}
+
+// packagesOf returns the packages declared by obj
+func packagesOf(obj *unstructured.Unstructured, source string) ([]filePackage, error) {
+	switch obj.GetKind() {
+	case "Package":
+		if obj.GetName() == "" {
+			return nil, nil
+		}
+		pkg := &cozyv1alpha1.Package{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pkg); err != nil {
+			return nil, fmt.Errorf("failed to convert Package %s from %s: %w", obj.GetName(), source, err)
+		}
+		// Drop the server state of exported Packages, so they can be created
+		pkg.ObjectMeta = metav1.ObjectMeta{
+			Name:        pkg.Name,
+			Labels:      pkg.Labels,
+			Annotations: pkg.Annotations,
+		}
+		pkg.Status = cozyv1alpha1.PackageStatus{}
+		return []filePackage{{name: pkg.Name, source: source, pkg: pkg}}, nil
+	case "PackageSource":
+		if obj.GetName() == "" {
+			return nil, nil
+		}
+		return []filePackage{{name: obj.GetName(), source: source}}, nil
+	}
+
+	if !obj.IsList() {
+		return nil, nil
+	}
+	var packages []filePackage
+	err := obj.EachListItem(func(item runtime.Object) error {
+		u, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			return nil
+		}
+		// The items of typed lists may omit their kind
+		if u.GetKind() == "" {
+			u.SetKind(strings.TrimSuffix(obj.GetKind(), "List"))
+		}
+		pkgs, err := packagesOf(u, source)
+		if err != nil {
+			return err
+		}
+		packages = append(packages, pkgs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
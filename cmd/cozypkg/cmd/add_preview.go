@@ -0,0 +1,245 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/operator"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ANSI colors of diff lines
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+)
+
+// plannedPackagesClient serves Packages that would be created as if they
+// existed, so that dependencies between them can be resolved
+type plannedPackagesClient struct {
+	client.Client
+	planned map[string]*cozyv1alpha1.Package
+}
+
+func (c *plannedPackagesClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if pkg, ok := obj.(*cozyv1alpha1.Package); ok {
+		if planned, ok := c.planned[key.Name]; ok {
+			planned.DeepCopyInto(pkg)
+			return nil
+		}
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+// previewPackages prints the Packages that would be created together with the
+// HelmReleases the operator would generate from them, either as YAML manifests
+// or as a diff against the cluster state.
+func previewPackages(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, pkgs []*cozyv1alpha1.Package, diff bool) error {
+	planned := make(map[string]*cozyv1alpha1.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		planned[pkg.Name] = pkg
+	}
+	renderer := &operator.PackageReconciler{
+		Client: &plannedPackagesClient{Client: k8sClient, planned: planned},
+		Scheme: scheme,
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, pkg := range pkgs {
+		obj, err := toUnstructured(pkg, cozyv1alpha1.GroupVersion.String(), "Package")
+		if err != nil {
+			return err
+		}
+		objects = append(objects, obj)
+
+		packageSource := &cozyv1alpha1.PackageSource{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: pkg.Name}, packageSource); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Skipping HelmReleases of %s: failed to get PackageSource: %v\n", pkg.Name, err)
+			continue
+		}
+		releases, err := renderer.RenderHelmReleases(ctx, pkg, packageSource)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Skipping HelmReleases of %s: %v\n", pkg.Name, err)
+			continue
+		}
+		for _, hr := range releases {
+			obj, err := toUnstructured(hr, helmv2.GroupVersion.String(), helmv2.HelmReleaseKind)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, obj)
+		}
+	}
+
+	if !diff {
+		for _, obj := range objects {
+			data, err := yaml.Marshal(obj.Object)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			fmt.Fprintf(os.Stdout, "---\n%s", data)
+		}
+		return nil
+	}
+
+	color := isTerminal(os.Stdout)
+	changed := 0
+	for _, obj := range objects {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), live)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		var liveObj map[string]interface{}
+		if err == nil {
+			liveObj = projectFields(live.Object, obj.Object).(map[string]interface{})
+		}
+		ok, err := printObjectDiff(os.Stdout, objectDisplayName(obj), liveObj, obj.Object, color)
+		if err != nil {
+			return err
+		}
+		if ok {
+			changed++
+		}
+	}
+	if changed == 0 {
+		fmt.Fprintln(os.Stderr, "✓ No changes")
+	}
+	return nil
+}
+
+// toUnstructured converts obj to an unstructured object of the given type
+func toUnstructured(obj runtime.Object, apiVersion, kind string) (*unstructured.Unstructured, error) {
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", kind, err)
+	}
+	u := &unstructured.Unstructured{Object: data}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	unstructured.RemoveNestedField(u.Object, "status")
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	return u, nil
+}
+
+// projectFields returns the parts of live that are set in desired, so that the
+// diff only shows fields that cozypkg and the operator manage
+func projectFields(live, desired interface{}) interface{} {
+	liveMap, ok := live.(map[string]interface{})
+	if !ok {
+		return live
+	}
+	desiredMap, ok := desired.(map[string]interface{})
+	if !ok {
+		return live
+	}
+	out := make(map[string]interface{}, len(desiredMap))
+	for key, desiredValue := range desiredMap {
+		if liveValue, ok := liveMap[key]; ok {
+			out[key] = projectFields(liveValue, desiredValue)
+		}
+	}
+	return out
+}
+
+// objectDisplayName returns kind/[namespace/]name of obj
+func objectDisplayName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() != "" {
+		return fmt.Sprintf("%s/%s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+}
+
+// printObjectDiff prints a unified diff between the YAML of live and desired.
+// It reports whether there are differences.
+func printObjectDiff(w io.Writer, name string, live, desired map[string]interface{}, color bool) (bool, error) {
+	var liveYAML, desiredYAML []byte
+	var err error
+	if live != nil {
+		if liveYAML, err = yaml.Marshal(live); err != nil {
+			return false, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+	}
+	if desiredYAML, err = yaml.Marshal(desired); err != nil {
+		return false, fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	fromFile := "live/" + name
+	if live == nil {
+		fromFile = "/dev/null"
+	}
+	text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(liveYAML)),
+		B:        difflib.SplitLines(string(desiredYAML)),
+		FromFile: fromFile,
+		ToFile:   "planned/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to diff %s: %w", name, err)
+	}
+	if text == "" {
+		return false, nil
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		if !color {
+			fmt.Fprint(w, line)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Fprint(w, line)
+		case strings.HasPrefix(line, "+"):
+			fmt.Fprint(w, colorGreen+strings.TrimSuffix(line, "\n")+colorReset+"\n")
+		case strings.HasPrefix(line, "-"):
+			fmt.Fprint(w, colorRed+strings.TrimSuffix(line, "\n")+colorReset+"\n")
+		case strings.HasPrefix(line, "@@"):
+			fmt.Fprint(w, colorCyan+strings.TrimSuffix(line, "\n")+colorReset+"\n")
+		default:
+			fmt.Fprint(w, line)
+		}
+	}
+	return true, nil
+}
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProjectFields(t *testing.T) {
+	cases := []struct {
+		name    string
+		live    interface{}
+		desired interface{}
+		want    interface{}
+	}{
+		{
+			name: "fields not set in desired are dropped",
+			live: map[string]interface{}{
+				"spec":   map[string]interface{}{"variant": "default", "suspend": true},
+				"status": map[string]interface{}{"ready": true},
+			},
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{"variant": "ha"},
+			},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{"variant": "default"},
+			},
+		},
+		{
+			name:    "fields missing from live stay missing",
+			live:    map[string]interface{}{"spec": map[string]interface{}{}},
+			desired: map[string]interface{}{"spec": map[string]interface{}{"variant": "ha"}},
+			want:    map[string]interface{}{"spec": map[string]interface{}{}},
+		},
+		{
+			name:    "lists are kept whole",
+			live:    map[string]interface{}{"items": []interface{}{"a", "b"}},
+			desired: map[string]interface{}{"items": []interface{}{"a"}},
+			want:    map[string]interface{}{"items": []interface{}{"a", "b"}},
+		},
+		{
+			name:    "scalar replaced by a map",
+			live:    map[string]interface{}{"values": "x"},
+			desired: map[string]interface{}{"values": map[string]interface{}{"a": 1}},
+			want:    map[string]interface{}{"values": "x"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := projectFields(tc.live, tc.desired); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("projectFields = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrintObjectDiff(t *testing.T) {
+	cases := []struct {
+		name        string
+		live        map[string]interface{}
+		desired     map[string]interface{}
+		wantChanged bool
+		wantLines   []string
+	}{
+		{
+			name:        "unchanged",
+			live:        map[string]interface{}{"spec": map[string]interface{}{"variant": "default"}},
+			desired:     map[string]interface{}{"spec": map[string]interface{}{"variant": "default"}},
+			wantChanged: false,
+		},
+		{
+			name:        "changed",
+			live:        map[string]interface{}{"spec": map[string]interface{}{"variant": "default"}},
+			desired:     map[string]interface{}{"spec": map[string]interface{}{"variant": "ha"}},
+			wantChanged: true,
+			wantLines:   []string{"--- live/Package/cozystack.monitoring", "+++ planned/Package/cozystack.monitoring", "-  variant: default", "+  variant: ha"},
+		},
+		{
+			name:        "created",
+			desired:     map[string]interface{}{"spec": map[string]interface{}{"variant": "ha"}},
+			wantChanged: true,
+			wantLines:   []string{"--- /dev/null", "+  variant: ha"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			changed, err := printObjectDiff(&out, "Package/cozystack.monitoring", tc.live, tc.desired, false)
+			if err != nil {
+				t.Fatalf("printObjectDiff: %v", err)
+			}
+			if changed != tc.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tc.wantChanged)
+			}
+			if !tc.wantChanged && out.Len() != 0 {
+				t.Errorf("printed %q, want nothing", out.String())
+			}
+			lines := strings.Split(out.String(), "\n")
+			for _, want := range tc.wantLines {
+				found := false
+				for _, line := range lines {
+					if strings.TrimRight(line, " \t") == want {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("diff has no line %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// addState persists the variant selections of installs started by cozypkg add,
+// so that an install that failed midway can be resumed without asking again
+type addState struct {
+	// Installs are keyed by cluster and PackageSource, see addStateKey
+	Installs map[string]addInstallState `json:"installs"`
+}
+
+// addInstallState holds the selections of a single install
+type addInstallState struct {
+	// Variants maps Package names to the selected variant
+	Variants  map[string]string `json:"variants"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// addStateKey identifies the install of packageSource in the cluster at server
+func addStateKey(server, packageSource string) string {
+	return server + " " + packageSource
+}
+
+// addStatePath returns the location of the state file in the user cache directory
+func addStatePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cozypkg", "add-state.json"), nil
+}
+
+// loadAddState reads the state file, returning an empty state if there is none
+func loadAddState() (*addState, error) {
+	state := &addState{Installs: map[string]addInstallState{}}
+	path, err := addStatePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if state.Installs == nil {
+		state.Installs = map[string]addInstallState{}
+	}
+	return state, nil
+}
+
+// save writes the state file, removing it when no installs are left
+func (s *addState) save() error {
+	path, err := addStatePath()
+	if err != nil {
+		return err
+	}
+	if len(s.Installs) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// confirmResume shows the saved selections of an interrupted install and asks
+// whether to reuse them
+func confirmResume(packageSource string, saved addInstallState) (bool, error) {
+	names := make([]string, 0, len(saved.Variants))
+	for name := range saved.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(os.Stderr, "Found saved selections of an interrupted install of %s (%s):\n",
+		packageSource, saved.UpdatedAt.Local().Format(time.DateTime))
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s: %s\n", name, saved.Variants[name])
+	}
+	fmt.Fprintf(os.Stderr, "Resume with these selections? [Y/n]: ")
+
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "", "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
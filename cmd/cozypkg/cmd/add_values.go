@@ -0,0 +1,324 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/operator"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// artifactNamespace is the namespace of the ExternalArtifacts generated for PackageSources
+const artifactNamespace = "cozy-system"
+
+// valuesEditor edits the values of the configurable components of the
+// Packages created by cozypkg add
+type valuesEditor struct {
+	k8sClient client.Client
+	clientset kubernetes.Interface
+}
+
+// editPackageValues offers to edit the values of every configurable component
+// of the selected variant of pkg. The editor is seeded with the default values
+// of the component, and the values changed from them are stored in
+// pkg.Spec.Components as overrides.
+func (e *valuesEditor) editPackageValues(ctx context.Context, pkg *cozyv1alpha1.Package) error {
+	packageSource := &cozyv1alpha1.PackageSource{}
+	if err := e.k8sClient.Get(ctx, client.ObjectKey{Name: pkg.Name}, packageSource); err != nil {
+		return fmt.Errorf("failed to get PackageSource %s: %w", pkg.Name, err)
+	}
+	variantName := pkg.Spec.Variant
+	if variantName == "" {
+		variantName = "default"
+	}
+	variant := findVariant(packageSource, variantName)
+	if variant == nil {
+		return fmt.Errorf("variant %s not found in PackageSource %s", variantName, pkg.Name)
+	}
+
+	for i := range variant.Components {
+		component := &variant.Components[i]
+		if !component.Configurable {
+			continue
+		}
+		edit, err := confirmEditValues(pkg.Name, component.Name)
+		if err != nil {
+			return err
+		}
+		if !edit {
+			continue
+		}
+
+		defaults, err := e.defaultValues(ctx, packageSource.Name, variantName, component)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Failed to get the default values of %s: %v\n", component.Name, err)
+		}
+		current := defaults
+		if override, ok := pkg.Spec.Components[component.Name]; ok && override.Values != nil {
+			if current, err = mergeValues(defaults, override.Values.Raw); err != nil {
+				return fmt.Errorf("failed to read the values of %s: %w", component.Name, err)
+			}
+		}
+
+		edited, err := editValuesInEditor(pkg.Name, component.Name, current)
+		if err != nil {
+			return err
+		}
+		overrides := changedValues(defaults, edited)
+		if len(overrides) == 0 {
+			fmt.Fprintf(os.Stderr, "✓ %s/%s uses the default values\n", pkg.Name, component.Name)
+			continue
+		}
+		raw, err := json.Marshal(overrides)
+		if err != nil {
+			return fmt.Errorf("failed to encode the values of %s: %w", component.Name, err)
+		}
+		if pkg.Spec.Components == nil {
+			pkg.Spec.Components = map[string]cozyv1alpha1.PackageComponent{}
+		}
+		override := pkg.Spec.Components[component.Name]
+		override.Values = &apiextensionsv1.JSON{Raw: raw}
+		pkg.Spec.Components[component.Name] = override
+		fmt.Fprintf(os.Stderr, "✓ %s/%s values overridden\n", pkg.Name, component.Name)
+	}
+	return nil
+}
+
+// defaultValues returns the values.yaml of a component as built into its
+// ExternalArtifact, where the values files of the component are merged. The
+// artifact is downloaded through the API server proxy to the artifact server.
+func (e *valuesEditor) defaultValues(ctx context.Context, packageSource, variant string, component *cozyv1alpha1.Component) (map[string]interface{}, error) {
+	if component.ChartRef != nil {
+		return nil, fmt.Errorf("components with chartRef have no values files")
+	}
+
+	artifactName := operator.ComponentArtifactName(packageSource, variant, component.Name)
+	ea := &sourcev1.ExternalArtifact{}
+	if err := e.k8sClient.Get(ctx, client.ObjectKey{Namespace: artifactNamespace, Name: artifactName}, ea); err != nil {
+		return nil, fmt.Errorf("failed to get ExternalArtifact %s: %w", artifactName, err)
+	}
+	if ea.Status.Artifact == nil || ea.Status.Artifact.URL == "" {
+		return nil, fmt.Errorf("ExternalArtifact %s has no artifact yet", artifactName)
+	}
+
+	u, err := url.Parse(ea.Status.Artifact.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact URL %q: %w", ea.Status.Artifact.URL, err)
+	}
+	// Artifact URLs point to a Service: <service>.<namespace>.svc...
+	labels := strings.Split(u.Hostname(), ".")
+	if len(labels) < 2 {
+		return nil, fmt.Errorf("artifact URL %q does not point to a Service", ea.Status.Artifact.URL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+	}
+	data, err := e.clientset.CoreV1().Services(labels[1]).
+		ProxyGet(u.Scheme, labels[0], port, u.Path, nil).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact: %w", err)
+	}
+
+	chartDir := path.Base(component.Path)
+	raw, err := readTarGzFile(data, path.Join(chartDir, "values.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse values.yaml: %w", err)
+	}
+	return values, nil
+}
+
+// readTarGzFile returns the content of the file at name in a gzipped tarball
+func readTarGzFile(data []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s not found in artifact", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read artifact: %w", err)
+		}
+		if path.Clean(hdr.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// confirmEditValues asks whether to edit the values of a component
+func confirmEditValues(packageName, componentName string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "Edit values of %s/%s? [y/N]: ", packageName, componentName)
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// editValuesInEditor opens values in the editor of the user, taken from
+// $VISUAL or $EDITOR and defaulting to vi, and returns the saved values
+func editValuesInEditor(packageName, componentName string, values map[string]interface{}) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode values: %w", err)
+	}
+	if len(values) == 0 {
+		data = nil
+	}
+
+	f, err := os.CreateTemp("", "cozypkg-values-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create values file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	header := fmt.Sprintf("# Values of %s/%s. Values changed from the defaults are saved as\n"+
+		"# overrides in the Package. Keys removed here keep their default value.\n", packageName, componentName)
+	if _, err := f.WriteString(header + string(data)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write values file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write values file: %w", err)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may carry arguments, e.g. "code --wait"
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values file: %w", err)
+	}
+	result := map[string]interface{}{}
+	if err := yaml.Unmarshal(edited, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse values of %s/%s: %w", packageName, componentName, err)
+	}
+	return result, nil
+}
+
+// mergeValues returns defaults with the JSON values in raw merged over them
+func mergeValues(defaults map[string]interface{}, raw []byte) (map[string]interface{}, error) {
+	overrides := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &overrides); err != nil {
+		return nil, err
+	}
+	return mergeMaps(defaults, overrides), nil
+}
+
+// mergeMaps returns a copy of base with overrides merged over it recursively
+func mergeMaps(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		bm, bok := merged[k].(map[string]interface{})
+		om, ook := v.(map[string]interface{})
+		if bok && ook {
+			v = mergeMaps(bm, om)
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// changedValues returns the values of edited that differ from defaults.
+// Nested maps are compared key by key, so that only the changed keys are
+// overridden.
+func changedValues(defaults, edited map[string]interface{}) map[string]interface{} {
+	changed := map[string]interface{}{}
+	for k, v := range edited {
+		dv, ok := defaults[k]
+		if !ok {
+			changed[k] = v
+			continue
+		}
+		dm, dok := dv.(map[string]interface{})
+		em, eok := v.(map[string]interface{})
+		if dok && eok {
+			if nested := changedValues(dm, em); len(nested) > 0 {
+				changed[k] = nested
+			}
+			continue
+		}
+		if !reflect.DeepEqual(normalizeValue(dv), normalizeValue(v)) {
+			changed[k] = v
+		}
+	}
+	return changed
+}
+
+// normalizeValue round-trips v through JSON, so that values decoded from YAML
+// and JSON compare equal
+func normalizeValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
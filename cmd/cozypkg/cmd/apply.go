@@ -0,0 +1,320 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/spf13/cobra"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cozystack/cozystack/internal/operator"
+)
+
+// appliedLabel marks the Packages created or updated by cozypkg apply. Only
+// they are pruned.
+const appliedLabel = "cozypkg.cozystack.io/applied"
+
+var applyCmdFlags struct {
+	files      []string
+	kubeconfig string
+	prune      bool
+	dryRun     bool
+	diff       bool
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <file>...",
+	Short: "Make the Packages of the cluster match a set of files",
+	Long: `Make the Packages of the cluster match the Packages declared in a set of files.
+
+The files are read like with cozypkg add -f: YAML files, directories,
+kustomization directories, http(s) URLs, or - for stdin. Missing Packages are
+created and Packages whose variant, components or ignored dependencies differ
+from the files are updated. Other fields of existing Packages, such as
+spec.suspend, are left as they are.
+
+With --prune, Packages previously applied by cozypkg apply that are no longer
+declared in the files are deleted. Packages created otherwise are never pruned.
+
+With --dry-run, the changes are printed instead of applied. With --diff, the
+Packages and the HelmReleases the operator would generate from them are
+compared with the cluster state and the differences are printed.`,
+	Example: `  cozypkg apply -f packages/
+  cozypkg apply -f packages/ --prune
+  kustomize build overlays/prod | cozypkg apply -f - --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		if len(applyCmdFlags.files) == 0 {
+			return fmt.Errorf("no files specified, use -f")
+		}
+		desired := make(map[string]filePackage)
+		for _, filePath := range applyCmdFlags.files {
+			packages, err := readPackagesFromFile(filePath)
+			if err != nil {
+				return fmt.Errorf("failed to read packages from %s: %w", filePath, err)
+			}
+			for _, pkg := range packages {
+				if pkg.pkg == nil {
+					fmt.Fprintf(os.Stderr, "warning: ignoring PackageSource %s from %s, only Packages are applied\n", pkg.name, pkg.source)
+					continue
+				}
+				if old, ok := desired[pkg.name]; ok {
+					fmt.Fprintf(os.Stderr, "warning: package %q is defined in both %s and %s, using the latter\n", pkg.name, old.source, pkg.source)
+				}
+				desired[pkg.name] = pkg
+			}
+		}
+		if len(desired) == 0 && !applyCmdFlags.prune {
+			return fmt.Errorf("no Packages found in the files")
+		}
+
+		// Create Kubernetes client config
+		var config *rest.Config
+		var err error
+
+		if applyCmdFlags.kubeconfig != "" {
+			config, err = clientcmd.BuildConfigFromFlags("", applyCmdFlags.kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to load kubeconfig from %s: %w", applyCmdFlags.kubeconfig, err)
+			}
+		} else {
+			config, err = ctrl.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig: %w", err)
+			}
+		}
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+
+		k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("failed to create k8s client: %w", err)
+		}
+
+		plan, err := planApply(ctx, k8sClient, desired, applyCmdFlags.prune)
+		if err != nil {
+			return err
+		}
+		if applyCmdFlags.diff {
+			return previewPackages(ctx, k8sClient, scheme, append(plan.create, plan.update...), true)
+		}
+		return plan.apply(ctx, k8sClient, applyCmdFlags.dryRun)
+	},
+}
+
+// applyPlan holds the changes cozypkg apply makes to the Packages of the cluster
+type applyPlan struct {
+	create []*cozyv1alpha1.Package
+	// update holds the existing Packages with the desired spec
+	update    []*cozyv1alpha1.Package
+	unchanged []string
+	prune     []string
+}
+
+// planApply compares the desired Packages with the Packages of the cluster. The
+// Packages applied earlier but no longer desired are pruned if prune is set.
+func planApply(ctx context.Context, k8sClient client.Client, desired map[string]filePackage, prune bool) (*applyPlan, error) {
+	var installed cozyv1alpha1.PackageList
+	if err := k8sClient.List(ctx, &installed); err != nil {
+		return nil, fmt.Errorf("failed to list Packages: %w", err)
+	}
+	current := make(map[string]*cozyv1alpha1.Package, len(installed.Items))
+	for i := range installed.Items {
+		current[installed.Items[i].Name] = &installed.Items[i]
+	}
+
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	plan := &applyPlan{}
+	for _, name := range names {
+		want := desired[name].pkg.DeepCopy()
+		if want.Labels == nil {
+			want.Labels = map[string]string{}
+		}
+		want.Labels[appliedLabel] = "true"
+
+		existing, ok := current[name]
+		if !ok {
+			plan.create = append(plan.create, want)
+			continue
+		}
+		if packageSpecEqual(existing, want) && existing.Labels[appliedLabel] == "true" {
+			plan.unchanged = append(plan.unchanged, name)
+			continue
+		}
+		updated := existing.DeepCopy()
+		setDesiredSpec(updated, want)
+		plan.update = append(plan.update, updated)
+	}
+
+	if prune {
+		for _, pkg := range installed.Items {
+			if _, ok := desired[pkg.Name]; !ok && pkg.Labels[appliedLabel] == "true" {
+				plan.prune = append(plan.prune, pkg.Name)
+			}
+		}
+		sort.Strings(plan.prune)
+	}
+	return plan, nil
+}
+
+// apply makes the changes of the plan, or only prints them if dryRun is set
+func (p *applyPlan) apply(ctx context.Context, k8sClient client.Client, dryRun bool) error {
+	verb := func(done, planned string) string {
+		if dryRun {
+			return planned
+		}
+		return done
+	}
+
+	for _, pkg := range p.create {
+		if !dryRun {
+			if err := k8sClient.Create(ctx, pkg); err != nil {
+				return fmt.Errorf("failed to create Package %s: %w", pkg.Name, err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "✓ %s Package %s\n", verb("Created", "Would create"), pkg.Name)
+	}
+	for _, pkg := range p.update {
+		if !dryRun {
+			if err := updatePackageSpec(ctx, k8sClient, pkg); err != nil {
+				return fmt.Errorf("failed to update Package %s: %w", pkg.Name, err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "✓ %s Package %s\n", verb("Updated", "Would update"), pkg.Name)
+	}
+	for _, name := range p.unchanged {
+		fmt.Fprintf(os.Stderr, "✓ Package %s is unchanged\n", name)
+	}
+	for _, name := range p.prune {
+		if !dryRun {
+			pkg := &cozyv1alpha1.Package{}
+			pkg.Name = name
+			if err := k8sClient.Delete(ctx, pkg); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete Package %s: %w", name, err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "✓ %s Package %s\n", verb("Pruned", "Would prune"), name)
+	}
+	return nil
+}
+
+// updatePackageSpec applies the spec and labels of desired to the Package in
+// the cluster, retrying on conflicts with concurrent writes
+func updatePackageSpec(ctx context.Context, k8sClient client.Client, desired *cozyv1alpha1.Package) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pkg := &cozyv1alpha1.Package{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: desired.Name}, pkg); err != nil {
+			return err
+		}
+		setDesiredSpec(pkg, desired)
+		return k8sClient.Update(ctx, pkg)
+	})
+}
+
+// setDesiredSpec sets the fields of pkg managed by cozypkg apply from desired
+func setDesiredSpec(pkg, desired *cozyv1alpha1.Package) {
+	pkg.Spec.Variant = desired.Spec.Variant
+	pkg.Spec.Components = desired.Spec.Components
+	pkg.Spec.IgnoreDependencies = desired.Spec.IgnoreDependencies
+	if pkg.Labels == nil {
+		pkg.Labels = map[string]string{}
+	}
+	for k, v := range desired.Labels {
+		pkg.Labels[k] = v
+	}
+}
+
+// packageSpecEqual reports whether the variant, components and ignored
+// dependencies of a and b are the same. An empty variant is the default one,
+// as set by the operator webhook.
+func packageSpecEqual(a, b *cozyv1alpha1.Package) bool {
+	variant := func(pkg *cozyv1alpha1.Package) string {
+		if pkg.Spec.Variant == "" {
+			return operator.DefaultVariant
+		}
+		return pkg.Spec.Variant
+	}
+	if variant(a) != variant(b) {
+		return false
+	}
+	if len(a.Spec.IgnoreDependencies) != 0 || len(b.Spec.IgnoreDependencies) != 0 {
+		if !reflect.DeepEqual(a.Spec.IgnoreDependencies, b.Spec.IgnoreDependencies) {
+			return false
+		}
+	}
+	if len(a.Spec.Components) != len(b.Spec.Components) {
+		return false
+	}
+	for name, ca := range a.Spec.Components {
+		cb, ok := b.Spec.Components[name]
+		if !ok || !reflect.DeepEqual(ca.Enabled, cb.Enabled) || !jsonEqual(ca.Values, cb.Values) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual reports whether a and b hold the same JSON value, regardless of
+// formatting and key order
+func jsonEqual(a, b *apiextensionsv1.JSON) bool {
+	decode := func(v *apiextensionsv1.JSON) any {
+		if v == nil || len(v.Raw) == 0 {
+			return nil
+		}
+		var out any
+		if err := json.Unmarshal(v.Raw, &out); err != nil {
+			return string(v.Raw)
+		}
+		return out
+	}
+	return reflect.DeepEqual(decode(a), decode(b))
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringArrayVarP(&applyCmdFlags.files, "file", "f", []string{}, "Read packages from a file, directory, kustomization, http(s) URL or - for stdin (can be specified multiple times)")
+	applyCmd.Flags().StringVar(&applyCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+	applyCmd.Flags().BoolVar(&applyCmdFlags.prune, "prune", false, "Delete the Packages applied earlier that are no longer declared in the files")
+	applyCmd.Flags().BoolVar(&applyCmdFlags.dryRun, "dry-run", false, "Print the changes without applying them")
+	applyCmd.Flags().BoolVar(&applyCmdFlags.diff, "diff", false, "Print a diff of the Packages and HelmReleases that would be created or updated against the cluster (implies --dry-run)")
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testPackage(name string, spec cozyv1alpha1.PackageSpec, labels map[string]string) *cozyv1alpha1.Package {
+	return &cozyv1alpha1.Package{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       spec,
+	}
+}
+
+func TestPackageSpecEqual(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b cozyv1alpha1.PackageSpec
+		want bool
+	}{
+		{
+			name: "empty variant is the default one",
+			a:    cozyv1alpha1.PackageSpec{},
+			b:    cozyv1alpha1.PackageSpec{Variant: "default"},
+			want: true,
+		},
+		{
+			name: "different variant",
+			a:    cozyv1alpha1.PackageSpec{Variant: "kubeovn"},
+			b:    cozyv1alpha1.PackageSpec{Variant: "cilium"},
+			want: false,
+		},
+		{
+			name: "nil and empty ignored dependencies",
+			a:    cozyv1alpha1.PackageSpec{IgnoreDependencies: []string{}},
+			b:    cozyv1alpha1.PackageSpec{},
+			want: true,
+		},
+		{
+			name: "different ignored dependencies",
+			a:    cozyv1alpha1.PackageSpec{IgnoreDependencies: []string{"cozystack.networking"}},
+			b:    cozyv1alpha1.PackageSpec{},
+			want: false,
+		},
+		{
+			name: "values formatted differently",
+			a: cozyv1alpha1.PackageSpec{Components: map[string]cozyv1alpha1.PackageComponent{
+				"grafana": {Values: &apiextensionsv1.JSON{Raw: []byte(`{"a":1,"b":{"c":true}}`)}},
+			}},
+			b: cozyv1alpha1.PackageSpec{Components: map[string]cozyv1alpha1.PackageComponent{
+				"grafana": {Values: &apiextensionsv1.JSON{Raw: []byte(`{ "b": {"c": true}, "a": 1 }`)}},
+			}},
+			want: true,
+		},
+		{
+			name: "different values",
+			a: cozyv1alpha1.PackageSpec{Components: map[string]cozyv1alpha1.PackageComponent{
+				"grafana": {Values: &apiextensionsv1.JSON{Raw: []byte(`{"a":1}`)}},
+			}},
+			b: cozyv1alpha1.PackageSpec{Components: map[string]cozyv1alpha1.PackageComponent{
+				"grafana": {Values: &apiextensionsv1.JSON{Raw: []byte(`{"a":2}`)}},
+			}},
+			want: false,
+		},
+		{
+			name: "component disabled",
+			a: cozyv1alpha1.PackageSpec{Components: map[string]cozyv1alpha1.PackageComponent{
+				"grafana": {Enabled: ptr.To(false)},
+			}},
+			b: cozyv1alpha1.PackageSpec{Components: map[string]cozyv1alpha1.PackageComponent{
+				"grafana": {},
+			}},
+			want: false,
+		},
+		{
+			name: "component added",
+			a:    cozyv1alpha1.PackageSpec{},
+			b: cozyv1alpha1.PackageSpec{Components: map[string]cozyv1alpha1.PackageComponent{
+				"grafana": {Enabled: ptr.To(true)},
+			}},
+			want: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := testPackage("cozystack.monitoring", tc.a, nil)
+			b := testPackage("cozystack.monitoring", tc.b, nil)
+			if got := packageSpecEqual(a, b); got != tc.want {
+				t.Errorf("packageSpecEqual = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+
+	applied := map[string]string{appliedLabel: "true"}
+	installed := []*cozyv1alpha1.Package{
+		// Declared with another variant
+		testPackage("cozystack.networking", cozyv1alpha1.PackageSpec{Variant: "kubeovn", Suspend: true}, applied),
+		// Declared as it is
+		testPackage("cozystack.monitoring", cozyv1alpha1.PackageSpec{Variant: "default"}, applied),
+		// Declared as it is, but not applied by cozypkg apply before
+		testPackage("cozystack.storage", cozyv1alpha1.PackageSpec{}, nil),
+		// No longer declared
+		testPackage("cozystack.old", cozyv1alpha1.PackageSpec{}, applied),
+		// Never declared
+		testPackage("cozystack.manual", cozyv1alpha1.PackageSpec{}, nil),
+	}
+	desired := map[string]filePackage{}
+	for _, pkg := range []*cozyv1alpha1.Package{
+		testPackage("cozystack.networking", cozyv1alpha1.PackageSpec{Variant: "cilium"}, nil),
+		testPackage("cozystack.monitoring", cozyv1alpha1.PackageSpec{}, nil),
+		testPackage("cozystack.storage", cozyv1alpha1.PackageSpec{}, nil),
+		testPackage("cozystack.new", cozyv1alpha1.PackageSpec{}, nil),
+	} {
+		desired[pkg.Name] = filePackage{name: pkg.Name, source: "packages.yaml", pkg: pkg}
+	}
+
+	names := func(pkgs []*cozyv1alpha1.Package) []string {
+		var out []string
+		for _, pkg := range pkgs {
+			out = append(out, pkg.Name)
+		}
+		return out
+	}
+
+	cases := []struct {
+		name          string
+		prune         bool
+		wantCreate    []string
+		wantUpdate    []string
+		wantUnchanged []string
+		wantPrune     []string
+	}{
+		{
+			name:          "without prune",
+			wantCreate:    []string{"cozystack.new"},
+			wantUpdate:    []string{"cozystack.networking", "cozystack.storage"},
+			wantUnchanged: []string{"cozystack.monitoring"},
+		},
+		{
+			name:          "with prune",
+			prune:         true,
+			wantCreate:    []string{"cozystack.new"},
+			wantUpdate:    []string{"cozystack.networking", "cozystack.storage"},
+			wantUnchanged: []string{"cozystack.monitoring"},
+			wantPrune:     []string{"cozystack.old"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			for _, pkg := range installed {
+				builder = builder.WithObjects(pkg.DeepCopy())
+			}
+			plan, err := planApply(context.TODO(), builder.Build(), desired, tc.prune)
+			if err != nil {
+				t.Fatalf("planApply: %v", err)
+			}
+			if got := names(plan.create); !reflect.DeepEqual(got, tc.wantCreate) {
+				t.Errorf("create = %v, want %v", got, tc.wantCreate)
+			}
+			if got := names(plan.update); !reflect.DeepEqual(got, tc.wantUpdate) {
+				t.Errorf("update = %v, want %v", got, tc.wantUpdate)
+			}
+			if !reflect.DeepEqual(plan.unchanged, tc.wantUnchanged) {
+				t.Errorf("unchanged = %v, want %v", plan.unchanged, tc.wantUnchanged)
+			}
+			if !reflect.DeepEqual(plan.prune, tc.wantPrune) {
+				t.Errorf("prune = %v, want %v", plan.prune, tc.wantPrune)
+			}
+
+			for _, pkg := range plan.create {
+				if pkg.Labels[appliedLabel] != "true" {
+					t.Errorf("created Package %s is not labelled %s", pkg.Name, appliedLabel)
+				}
+			}
+			for _, pkg := range plan.update {
+				if pkg.Labels[appliedLabel] != "true" {
+					t.Errorf("updated Package %s is not labelled %s", pkg.Name, appliedLabel)
+				}
+				if pkg.Name == "cozystack.networking" && (pkg.Spec.Variant != "cilium" || !pkg.Spec.Suspend) {
+					t.Errorf("updated Package %s spec = %+v, want the declared variant and the suspend flag kept", pkg.Name, pkg.Spec)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,564 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/cozypkg/plugin"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// applicationAPIGroup is the API group of Cozystack applications
+	applicationAPIGroup = "apps.cozystack.io"
+
+	backupPollInterval = 2 * time.Second
+)
+
+var backupCmdFlags struct {
+	namespace  string
+	kubeconfig string
+}
+
+var backupCreateCmdFlags struct {
+	plan     string
+	storage  string
+	strategy string
+	wait     bool
+	timeout  time.Duration
+}
+
+var backupListCmdFlags struct {
+	allNamespaces bool
+	jobs          bool
+}
+
+var backupRestoreCmdFlags struct {
+	to      string
+	wait    bool
+	timeout time.Duration
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Create, list and restore application backups",
+	Long: `Create, list and restore backups of Cozystack applications.
+
+Applications are given as <kind>/<name>, where kind is the kind, singular or
+plural name of the application, e.g. postgres/db or Postgres/db.`,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create <kind>/<name>",
+	Short: "Back up an application now",
+	Long: `Create a BackupJob for an application.
+
+The strategy and storage are resolved in this order:
+  1. --strategy and --storage, if set;
+  2. the Plan given with --plan, or else the only Plan of the application;
+  3. the only StrategyBinding in the namespace, with the default storage
+     of the namespace.`,
+	Example: `  cozypkg backup create postgres/db -n tenant-foo
+  cozypkg backup create postgres/db -n tenant-foo --strategy Velero/postgres --wait`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		k8sClient, namespace, err := newBackupClient()
+		if err != nil {
+			return err
+		}
+		appRef, err := resolveApplicationRef(ctx, k8sClient, args[0])
+		if err != nil {
+			return err
+		}
+
+		strategyRef, storageRef, err := resolveBackupRefs(ctx, k8sClient, namespace, appRef)
+		if err != nil {
+			return err
+		}
+
+		job := &backupsv1alpha1.BackupJob{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: appRef.Name + "-",
+				Namespace:    namespace,
+			},
+			Spec: backupsv1alpha1.BackupJobSpec{
+				ApplicationRef: appRef,
+				StorageRef:     storageRef,
+				StrategyRef:    strategyRef,
+			},
+		}
+		if err := k8sClient.Create(ctx, job); err != nil {
+			return fmt.Errorf("failed to create BackupJob: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Created BackupJob %s/%s (strategy %s/%s)\n", namespace, job.Name, strategyRef.Kind, strategyRef.Name)
+
+		if !backupCreateCmdFlags.wait {
+			return nil
+		}
+		return waitForBackupJob(ctx, k8sClient, job, backupCreateCmdFlags.timeout)
+	},
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list [<kind>/<name>]",
+	Short: "List backups",
+	Long: `List the Backups in a namespace, optionally only those of one application.
+
+Use --jobs to list the BackupJobs instead.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		k8sClient, namespace, err := newBackupClient()
+		if err != nil {
+			return err
+		}
+		var opts []client.ListOption
+		if !backupListCmdFlags.allNamespaces {
+			opts = append(opts, client.InNamespace(namespace))
+		}
+		var appRef *corev1.TypedLocalObjectReference
+		if len(args) == 1 {
+			ref, err := resolveApplicationRef(ctx, k8sClient, args[0])
+			if err != nil {
+				return err
+			}
+			appRef = &ref
+		}
+
+		if backupListCmdFlags.jobs {
+			return listBackupJobs(ctx, k8sClient, appRef, opts...)
+		}
+		return listBackups(ctx, k8sClient, appRef, opts...)
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <backup> | <kind>/<name>",
+	Short: "Restore an application from a backup",
+	Long: `Create a RestoreJob for a Backup.
+
+The argument is either the name of a Backup, or an application given as
+<kind>/<name>, in which case its latest ready Backup is restored. By default
+the backup is restored into the application it was taken from; use --to to
+restore it into another application of the same kind.`,
+	Example: `  cozypkg backup restore db-x7k2p -n tenant-foo
+  cozypkg backup restore postgres/db -n tenant-foo --to postgres/db-copy --wait`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		k8sClient, namespace, err := newBackupClient()
+		if err != nil {
+			return err
+		}
+
+		backupName := args[0]
+		if strings.Contains(backupName, "/") {
+			appRef, err := resolveApplicationRef(ctx, k8sClient, backupName)
+			if err != nil {
+				return err
+			}
+			backup, err := latestBackup(ctx, k8sClient, namespace, appRef)
+			if err != nil {
+				return err
+			}
+			backupName = backup.Name
+		}
+
+		job := &backupsv1alpha1.RestoreJob{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: backupName + "-restore-",
+				Namespace:    namespace,
+			},
+			Spec: backupsv1alpha1.RestoreJobSpec{
+				BackupRef: corev1.LocalObjectReference{Name: backupName},
+			},
+		}
+		if backupRestoreCmdFlags.to != "" {
+			targetRef, err := resolveApplicationRef(ctx, k8sClient, backupRestoreCmdFlags.to)
+			if err != nil {
+				return err
+			}
+			job.Spec.TargetApplicationRef = &targetRef
+		}
+		if err := k8sClient.Create(ctx, job); err != nil {
+			return fmt.Errorf("failed to create RestoreJob: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Created RestoreJob %s/%s from Backup %s\n", namespace, job.Name, backupName)
+
+		if !backupRestoreCmdFlags.wait {
+			return nil
+		}
+		return waitForRestoreJob(ctx, k8sClient, job, backupRestoreCmdFlags.timeout)
+	},
+}
+
+// newBackupClient returns a client for the backups API and the namespace to work in
+func newBackupClient() (client.Client, string, error) {
+	opts := plugin.Options{Kubeconfig: backupCmdFlags.kubeconfig, Namespace: backupCmdFlags.namespace}
+	namespace, err := opts.CurrentNamespace()
+	if err != nil {
+		return nil, "", err
+	}
+	k8sClient, err := opts.Client()
+	if err != nil {
+		return nil, "", err
+	}
+	return k8sClient, namespace, nil
+}
+
+// resolveApplicationRef resolves an application given as <kind>/<name> to a
+// reference, matching kind against the CozystackResourceDefinitions
+func resolveApplicationRef(ctx context.Context, k8sClient client.Client, value string) (corev1.TypedLocalObjectReference, error) {
+	kind, name, ok := strings.Cut(value, "/")
+	if !ok || kind == "" || name == "" {
+		return corev1.TypedLocalObjectReference{}, fmt.Errorf("application %q must have the form <kind>/<name>", value)
+	}
+
+	var crdList cozyv1alpha1.CozystackResourceDefinitionList
+	if err := k8sClient.List(ctx, &crdList); err != nil {
+		return corev1.TypedLocalObjectReference{}, fmt.Errorf("failed to list CozystackResourceDefinitions: %w", err)
+	}
+	for _, crd := range crdList.Items {
+		app := crd.Spec.Application
+		if strings.EqualFold(kind, app.Kind) || strings.EqualFold(kind, app.Singular) || strings.EqualFold(kind, app.Plural) {
+			group := applicationAPIGroup
+			return corev1.TypedLocalObjectReference{APIGroup: &group, Kind: app.Kind, Name: name}, nil
+		}
+	}
+	return corev1.TypedLocalObjectReference{}, fmt.Errorf("unknown application kind %q", kind)
+}
+
+// resolveBackupRefs returns the strategy and storage to back up appRef with.
+// A nil storage reference leaves it to the default storage of the namespace.
+func resolveBackupRefs(ctx context.Context, k8sClient client.Client, namespace string, appRef corev1.TypedLocalObjectReference) (corev1.TypedLocalObjectReference, *corev1.TypedLocalObjectReference, error) {
+	var strategyRef *corev1.TypedLocalObjectReference
+	var storageRef *corev1.TypedLocalObjectReference
+	var err error
+
+	if backupCreateCmdFlags.strategy != "" {
+		if strategyRef, err = parseStrategyRef(backupCreateCmdFlags.strategy); err != nil {
+			return corev1.TypedLocalObjectReference{}, nil, err
+		}
+	}
+	if backupCreateCmdFlags.storage != "" {
+		if storageRef, err = parseTypedRef(backupCreateCmdFlags.storage, applicationAPIGroup); err != nil {
+			return corev1.TypedLocalObjectReference{}, nil, fmt.Errorf("invalid --storage: %w", err)
+		}
+	}
+	if strategyRef != nil && (storageRef != nil || backupCreateCmdFlags.plan == "") {
+		return *strategyRef, storageRef, nil
+	}
+
+	plan, err := findPlan(ctx, k8sClient, namespace, appRef)
+	if err != nil {
+		return corev1.TypedLocalObjectReference{}, nil, err
+	}
+	if plan != nil {
+		if strategyRef == nil {
+			strategyRef = plan.Spec.StrategyRef.DeepCopy()
+			if strategyRef == nil {
+				strategyRef = plan.Status.StrategyRef.DeepCopy()
+			}
+		}
+		if strategyRef == nil {
+			return corev1.TypedLocalObjectReference{}, nil, fmt.Errorf("Plan %s has no strategy yet, use --strategy", plan.Name)
+		}
+		if storageRef == nil {
+			storageRef = plan.Spec.StorageRef.DeepCopy()
+			if storageRef == nil {
+				storageRef = plan.Status.StorageRef.DeepCopy()
+			}
+		}
+		return *strategyRef, storageRef, nil
+	}
+
+	var bindings backupsv1alpha1.StrategyBindingList
+	if err := k8sClient.List(ctx, &bindings, client.InNamespace(namespace)); err != nil {
+		return corev1.TypedLocalObjectReference{}, nil, fmt.Errorf("failed to list StrategyBindings: %w", err)
+	}
+	if len(bindings.Items) != 1 {
+		return corev1.TypedLocalObjectReference{}, nil, fmt.Errorf("cannot choose a strategy for %s/%s: found no Plan and %d StrategyBindings in namespace %s, use --strategy", appRef.Kind, appRef.Name, len(bindings.Items), namespace)
+	}
+	group := backupsv1alpha1.GroupVersion.Group
+	return corev1.TypedLocalObjectReference{
+		APIGroup: &group,
+		Kind:     backupsv1alpha1.StrategyBindingKind,
+		Name:     bindings.Items[0].Name,
+	}, storageRef, nil
+}
+
+// findPlan returns the Plan given with --plan, or else the only Plan of
+// appRef in namespace. It returns nil if the application has no Plan.
+func findPlan(ctx context.Context, k8sClient client.Client, namespace string, appRef corev1.TypedLocalObjectReference) (*backupsv1alpha1.Plan, error) {
+	if backupCreateCmdFlags.plan != "" {
+		plan := &backupsv1alpha1.Plan{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: backupCreateCmdFlags.plan}, plan); err != nil {
+			return nil, fmt.Errorf("failed to get Plan %s/%s: %w", namespace, backupCreateCmdFlags.plan, err)
+		}
+		return plan, nil
+	}
+
+	var plans backupsv1alpha1.PlanList
+	if err := k8sClient.List(ctx, &plans, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list Plans: %w", err)
+	}
+	var matching []*backupsv1alpha1.Plan
+	for i := range plans.Items {
+		if sameApplication(plans.Items[i].Spec.ApplicationRef, appRef) {
+			matching = append(matching, &plans.Items[i])
+		}
+	}
+	switch len(matching) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matching[0], nil
+	}
+	names := make([]string, 0, len(matching))
+	for _, plan := range matching {
+		names = append(names, plan.Name)
+	}
+	return nil, fmt.Errorf("%s/%s has several Plans (%s), use --plan to choose one", appRef.Kind, appRef.Name, strings.Join(names, ", "))
+}
+
+// parseStrategyRef parses a strategy reference in the form
+// "[<apiGroup>/]<Kind>/<name>". Without an API group, StrategyBindings refer
+// to backups.cozystack.io and other kinds to strategy.backups.cozystack.io.
+func parseStrategyRef(value string) (*corev1.TypedLocalObjectReference, error) {
+	group := strategyv1alpha1.GroupVersion.Group
+	if strings.HasPrefix(value, backupsv1alpha1.StrategyBindingKind+"/") {
+		group = backupsv1alpha1.GroupVersion.Group
+	}
+	ref, err := parseTypedRef(value, group)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --strategy: %w", err)
+	}
+	return ref, nil
+}
+
+// parseTypedRef parses a reference in the form "[<apiGroup>/]<Kind>/<name>"
+func parseTypedRef(value, defaultGroup string) (*corev1.TypedLocalObjectReference, error) {
+	parts := strings.Split(value, "/")
+	group := defaultGroup
+	switch len(parts) {
+	case 2:
+	case 3:
+		group, parts = parts[0], parts[1:]
+	default:
+		return nil, fmt.Errorf("reference %q must have the form [<apiGroup>/]<Kind>/<name>", value)
+	}
+	if parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("reference %q must have the form [<apiGroup>/]<Kind>/<name>", value)
+	}
+	return &corev1.TypedLocalObjectReference{APIGroup: &group, Kind: parts[0], Name: parts[1]}, nil
+}
+
+// sameApplication reports whether a and b refer to the same application
+func sameApplication(a, b corev1.TypedLocalObjectReference) bool {
+	groupA, groupB := applicationAPIGroup, applicationAPIGroup
+	if a.APIGroup != nil && *a.APIGroup != "" {
+		groupA = *a.APIGroup
+	}
+	if b.APIGroup != nil && *b.APIGroup != "" {
+		groupB = *b.APIGroup
+	}
+	return groupA == groupB && a.Kind == b.Kind && a.Name == b.Name
+}
+
+// latestBackup returns the most recent ready Backup of appRef in namespace
+func latestBackup(ctx context.Context, k8sClient client.Client, namespace string, appRef corev1.TypedLocalObjectReference) (*backupsv1alpha1.Backup, error) {
+	var backups backupsv1alpha1.BackupList
+	if err := k8sClient.List(ctx, &backups, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list Backups: %w", err)
+	}
+	var latest *backupsv1alpha1.Backup
+	for i := range backups.Items {
+		backup := &backups.Items[i]
+		if backup.Status.Phase != backupsv1alpha1.BackupPhaseReady || !sameApplication(backup.Spec.ApplicationRef, appRef) {
+			continue
+		}
+		if latest == nil || backup.Spec.TakenAt.After(latest.Spec.TakenAt.Time) {
+			latest = backup
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no ready Backup of %s/%s found in namespace %s", appRef.Kind, appRef.Name, namespace)
+	}
+	return latest, nil
+}
+
+func listBackups(ctx context.Context, k8sClient client.Client, appRef *corev1.TypedLocalObjectReference, opts ...client.ListOption) error {
+	var backups backupsv1alpha1.BackupList
+	if err := k8sClient.List(ctx, &backups, opts...); err != nil {
+		return fmt.Errorf("failed to list Backups: %w", err)
+	}
+	items := backups.Items[:0]
+	for _, backup := range backups.Items {
+		if appRef == nil || sameApplication(backup.Spec.ApplicationRef, *appRef) {
+			items = append(items, backup)
+		}
+	}
+	// Newest first
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Spec.TakenAt.After(items[j].Spec.TakenAt.Time)
+	})
+
+	// Use tabwriter for better column alignment
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tAPPLICATION\tSTRATEGY\tTAKEN AT\tPHASE")
+	for _, backup := range items {
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s/%s\t%s\t%s\n",
+			backup.Namespace, backup.Name,
+			backup.Spec.ApplicationRef.Kind, backup.Spec.ApplicationRef.Name,
+			backup.Spec.StrategyRef.Kind, backup.Spec.StrategyRef.Name,
+			formatTime(&backup.Spec.TakenAt), backup.Status.Phase)
+	}
+	return nil
+}
+
+func listBackupJobs(ctx context.Context, k8sClient client.Client, appRef *corev1.TypedLocalObjectReference, opts ...client.ListOption) error {
+	var jobs backupsv1alpha1.BackupJobList
+	if err := k8sClient.List(ctx, &jobs, opts...); err != nil {
+		return fmt.Errorf("failed to list BackupJobs: %w", err)
+	}
+	items := jobs.Items[:0]
+	for _, job := range jobs.Items {
+		if appRef == nil || sameApplication(job.Spec.ApplicationRef, *appRef) {
+			items = append(items, job)
+		}
+	}
+	// Newest first
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreationTimestamp.After(items[j].CreationTimestamp.Time)
+	})
+
+	// Use tabwriter for better column alignment
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tAPPLICATION\tPLAN\tSTARTED AT\tPHASE\tBACKUP")
+	for _, job := range items {
+		plan, backup := "", ""
+		if job.Spec.PlanRef != nil {
+			plan = job.Spec.PlanRef.Name
+		}
+		if job.Status.BackupRef != nil {
+			backup = job.Status.BackupRef.Name
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\t%s\t%s\t%s\n",
+			job.Namespace, job.Name,
+			job.Spec.ApplicationRef.Kind, job.Spec.ApplicationRef.Name,
+			plan, formatTime(job.Status.StartedAt), job.Status.Phase, backup)
+	}
+	return nil
+}
+
+// formatTime formats t for tables, or returns an empty string if it is not set
+func formatTime(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// waitForBackupJob polls job until it succeeds, fails or timeout expires
+func waitForBackupJob(ctx context.Context, k8sClient client.Client, job *backupsv1alpha1.BackupJob, timeout time.Duration) error {
+	fmt.Fprintf(os.Stderr, "Waiting for BackupJob %s/%s...\n", job.Namespace, job.Name)
+	err := wait.PollUntilContextTimeout(ctx, backupPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+			return false, err
+		}
+		switch job.Status.Phase {
+		case backupsv1alpha1.BackupJobPhaseSucceeded:
+			return true, nil
+		case backupsv1alpha1.BackupJobPhaseFailed:
+			return false, fmt.Errorf("BackupJob %s/%s failed: %s", job.Namespace, job.Name, job.Status.Message)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed waiting for BackupJob %s/%s: %w", job.Namespace, job.Name, err)
+	}
+	backup := ""
+	if job.Status.BackupRef != nil {
+		backup = job.Status.BackupRef.Name
+	}
+	fmt.Fprintf(os.Stderr, "✓ BackupJob %s/%s succeeded, created Backup %s\n", job.Namespace, job.Name, backup)
+	return nil
+}
+
+// waitForRestoreJob polls job until it succeeds, fails or timeout expires
+func waitForRestoreJob(ctx context.Context, k8sClient client.Client, job *backupsv1alpha1.RestoreJob, timeout time.Duration) error {
+	fmt.Fprintf(os.Stderr, "Waiting for RestoreJob %s/%s...\n", job.Namespace, job.Name)
+	err := wait.PollUntilContextTimeout(ctx, backupPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+			return false, err
+		}
+		switch job.Status.Phase {
+		case backupsv1alpha1.RestoreJobPhaseSucceeded:
+			return true, nil
+		case backupsv1alpha1.RestoreJobPhaseFailed:
+			return false, fmt.Errorf("RestoreJob %s/%s failed: %s", job.Namespace, job.Name, job.Status.Message)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed waiting for RestoreJob %s/%s: %w", job.Namespace, job.Name, err)
+	}
+	fmt.Fprintf(os.Stderr, "✓ RestoreJob %s/%s succeeded\n", job.Namespace, job.Name)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupCreateCmd, backupListCmd, backupRestoreCmd)
+	backupCmd.PersistentFlags().StringVarP(&backupCmdFlags.namespace, "namespace", "n", "", "Namespace of the application (defaults to the namespace of the current context)")
+	backupCmd.PersistentFlags().StringVar(&backupCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+
+	backupCreateCmd.Flags().StringVar(&backupCreateCmdFlags.plan, "plan", "", "Plan to take the strategy and storage from")
+	backupCreateCmd.Flags().StringVar(&backupCreateCmdFlags.storage, "storage", "", "Storage in the form [<apiGroup>/]<Kind>/<name>")
+	backupCreateCmd.Flags().StringVar(&backupCreateCmdFlags.strategy, "strategy", "", "Strategy in the form [<apiGroup>/]<Kind>/<name>, e.g. Velero/postgres or StrategyBinding/nightly")
+	backupCreateCmd.Flags().BoolVar(&backupCreateCmdFlags.wait, "wait", false, "Wait for the BackupJob to complete")
+	backupCreateCmd.Flags().DurationVar(&backupCreateCmdFlags.timeout, "timeout", 30*time.Minute, "How long to wait with --wait")
+
+	backupListCmd.Flags().BoolVarP(&backupListCmdFlags.allNamespaces, "all-namespaces", "A", false, "List backups in all namespaces")
+	backupListCmd.Flags().BoolVar(&backupListCmdFlags.jobs, "jobs", false, "List BackupJobs instead of Backups")
+
+	backupRestoreCmd.Flags().StringVar(&backupRestoreCmdFlags.to, "to", "", "Application to restore into, in the form <kind>/<name> (defaults to the backed up application)")
+	backupRestoreCmd.Flags().BoolVar(&backupRestoreCmdFlags.wait, "wait", false, "Wait for the RestoreJob to complete")
+	backupRestoreCmd.Flags().DurationVar(&backupRestoreCmdFlags.timeout, "timeout", 30*time.Minute, "How long to wait with --wait")
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/client-go/discovery"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const redacted = "<redacted>"
+
+var bugreportCmdFlags struct {
+	output     string
+	kubeconfig string
+}
+
+// bugreportResource is a resource collected into the bug report
+type bugreportResource struct {
+	file string
+	gvk  schema.GroupVersionKind
+	opts []client.ListOption
+	// sanitize removes sensitive fields from an object
+	sanitize func(obj map[string]interface{})
+}
+
+var bugreportResources = []bugreportResource{
+	{
+		file:     "packagesources.yaml",
+		gvk:      cozyv1alpha1.GroupVersion.WithKind("PackageSource"),
+		sanitize: func(map[string]interface{}) {},
+	},
+	{
+		file: "packages.yaml",
+		gvk:  cozyv1alpha1.GroupVersion.WithKind("Package"),
+		sanitize: func(obj map[string]interface{}) {
+			components, _, _ := unstructured.NestedMap(obj, "spec", "components")
+			for name, c := range components {
+				if component, ok := c.(map[string]interface{}); ok {
+					if _, ok := component["values"]; ok {
+						component["values"] = redacted
+					}
+					components[name] = component
+				}
+			}
+			if len(components) > 0 {
+				_ = unstructured.SetNestedMap(obj, components, "spec", "components")
+			}
+		},
+	},
+	{
+		file: "helmreleases.yaml",
+		gvk:  schema.GroupVersionKind{Group: "helm.toolkit.fluxcd.io", Version: "v2", Kind: "HelmRelease"},
+		opts: []client.ListOption{client.HasLabels{"cozystack.io/package"}},
+		sanitize: func(obj map[string]interface{}) {
+			if _, ok, _ := unstructured.NestedFieldNoCopy(obj, "spec", "values"); ok {
+				_ = unstructured.SetNestedField(obj, redacted, "spec", "values")
+			}
+		},
+	},
+}
+
+var bugreportCmd = &cobra.Command{
+	Use:   "bugreport",
+	Short: "Collect sanitized package state for a support ticket",
+	Long: `Collect Packages, PackageSources and the HelmReleases generated from them
+into a tar.gz archive that can be attached to a support ticket.
+
+The archive is sanitized: Helm values of HelmReleases and Package components,
+managed fields and last-applied-configuration annotations are removed.
+If a telemetry log is configured (--telemetry-log), it is included as well.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		// Create Kubernetes client config
+		var config *rest.Config
+		var err error
+
+		if bugreportCmdFlags.kubeconfig != "" {
+			config, err = clientcmd.BuildConfigFromFlags("", bugreportCmdFlags.kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to load kubeconfig from %s: %w", bugreportCmdFlags.kubeconfig, err)
+			}
+		} else {
+			config, err = ctrl.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig: %w", err)
+			}
+		}
+
+		k8sClient, err := client.New(config, client.Options{})
+		if err != nil {
+			return fmt.Errorf("failed to create k8s client: %w", err)
+		}
+
+		output := bugreportCmdFlags.output
+		if output == "" {
+			output = fmt.Sprintf("cozypkg-bugreport-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+		}
+
+		files := map[string][]byte{}
+		var collectErrors bytes.Buffer
+
+		if dc, err := discovery.NewDiscoveryClientForConfig(config); err != nil {
+			fmt.Fprintf(&collectErrors, "version: %v\n", err)
+		} else if v, err := dc.ServerVersion(); err != nil {
+			fmt.Fprintf(&collectErrors, "version: %v\n", err)
+		} else {
+			files["version.txt"] = []byte(fmt.Sprintf("Kubernetes %s (%s)\n", v.GitVersion, v.Platform))
+		}
+
+		for _, res := range bugreportResources {
+			data, err := collectBugreportResource(ctx, k8sClient, res)
+			if err != nil {
+				fmt.Fprintf(&collectErrors, "%s: %v\n", res.file, err)
+				continue
+			}
+			files[res.file] = data
+		}
+
+		if telemetryFlags.log != "" {
+			if data, err := os.ReadFile(telemetryFlags.log); err == nil {
+				files["telemetry.jsonl"] = data
+			} else if !os.IsNotExist(err) {
+				fmt.Fprintf(&collectErrors, "telemetry.jsonl: %v\n", err)
+			}
+		}
+
+		if collectErrors.Len() > 0 {
+			files["errors.txt"] = collectErrors.Bytes()
+			fmt.Fprintf(os.Stderr, "⚠ Some resources could not be collected:\n%s", collectErrors.String())
+		}
+
+		if err := writeBugreport(output, files); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ Bug report written to %s\n", output)
+		return nil
+	},
+}
+
+// collectBugreportResource lists the objects of res and renders them as sanitized multi-document YAML
+func collectBugreportResource(ctx context.Context, k8sClient client.Client, res bugreportResource) ([]byte, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(res.gvk.GroupVersion().WithKind(res.gvk.Kind + "List"))
+	if err := k8sClient.List(ctx, list, res.opts...); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", res.gvk.Kind, err)
+	}
+
+	serializer := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{Yaml: true})
+	var buf bytes.Buffer
+	for i := range list.Items {
+		obj := &list.Items[i]
+		obj.SetManagedFields(nil)
+		annotations := obj.GetAnnotations()
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		obj.SetAnnotations(annotations)
+		res.sanitize(obj.Object)
+
+		buf.WriteString("---\n")
+		if err := serializer.Encode(obj, &buf); err != nil {
+			return nil, fmt.Errorf("failed to encode %s %s: %w", res.gvk.Kind, obj.GetName(), err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBugreport writes files into a tar.gz archive at path
+func writeBugreport(path string, files map[string][]byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, name := range []string{"version.txt", "packagesources.yaml", "packages.yaml", "helmreleases.yaml", "telemetry.jsonl", "errors.txt"} {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		hdr := &tar.Header{Name: "cozypkg-bugreport/" + name, Mode: 0o600, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func init() {
+	rootCmd.AddCommand(bugreportCmd)
+	bugreportCmd.Flags().StringVarP(&bugreportCmdFlags.output, "output", "o", "", "Path of the archive to write (defaults to cozypkg-bugreport-<timestamp>.tar.gz)")
+	bugreportCmd.Flags().StringVar(&bugreportCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+}
@@ -24,8 +24,11 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
 	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -37,8 +40,11 @@ import (
 )
 
 var delCmdFlags struct {
-	files      []string
-	kubeconfig string
+	files          []string
+	kubeconfig     string
+	yes              bool
+	nonInteractive   bool
+	orphanComponents bool
 }
 
 var delCmd = &cobra.Command{
@@ -47,15 +53,25 @@ var delCmd = &cobra.Command{
 	Long: `Delete Package resources.
 
 You can specify packages as arguments or use -f flag to read from files.
-Multiple -f flags can be specified, and they can point to files or directories.`,
+Multiple -f flags can be specified, and they can point to files, directories,
+kustomization directories, http(s) URLs, or - for stdin.
+
+The packages to delete, including the packages depending on them, are listed
+before asking for confirmation. Use --yes to delete them without asking. With
+--non-interactive, del never prompts and fails unless --yes is given.
+
+With --orphan-components, the HelmReleases of the packages are detached from
+them and kept installed, for emergencies where the package manager must be
+removed without uninstalling the running workloads. Use cozypkg adopt to
+re-attach them once the packages are recreated.`,
 	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
 		// Collect package names from arguments and files
 		packageNames := make(map[string]bool)
-		packagesFromFiles := make(map[string]string) // packageName -> filePath
-		
+		packagesFromFiles := make(map[string]string) // packageName -> source
+
 		for _, arg := range args {
 			packageNames[arg] = true
 		}
@@ -67,17 +83,20 @@ Multiple -f flags can be specified, and they can point to files or directories.`
 				return fmt.Errorf("failed to read packages from %s: %w", filePath, err)
 			}
 			for _, pkg := range packages {
-				packageNames[pkg] = true
-				if oldPath, ok := packagesFromFiles[pkg]; ok {
-					fmt.Fprintf(os.Stderr, "warning: package %q is defined in both %s and %s, using the latter\n", pkg, oldPath, filePath)
+				packageNames[pkg.name] = true
+				if oldSource, ok := packagesFromFiles[pkg.name]; ok {
+					fmt.Fprintf(os.Stderr, "warning: package %q is defined in both %s and %s, using the latter\n", pkg.name, oldSource, pkg.source)
 				}
-				packagesFromFiles[pkg] = filePath
+				packagesFromFiles[pkg.name] = pkg.source
 			}
 		}
 
 		if len(packageNames) == 0 {
 			return fmt.Errorf("no packages specified")
 		}
+		if delCmdFlags.nonInteractive && !delCmdFlags.yes {
+			return fmt.Errorf("--non-interactive requires --yes to confirm the deletion")
+		}
 
 		// Create Kubernetes client config
 		var config *rest.Config
@@ -98,6 +117,8 @@ Multiple -f flags can be specified, and they can point to files or directories.`
 		scheme := runtime.NewScheme()
 		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+		utilruntime.Must(helmv2.AddToScheme(scheme))
+		utilruntime.Must(backupsv1alpha1.AddToScheme(scheme))
 
 		k8sClient, err := client.New(config, client.Options{Scheme: scheme})
 		if err != nil {
@@ -132,8 +153,15 @@ Multiple -f flags can be specified, and they can point to files or directories.`
 			return nil
 		}
 
+		// Compute which resources beyond the Packages themselves are affected
+		impact, err := analyzeDeletionImpact(ctx, k8sClient, packagesToDelete)
+		if err != nil {
+			return fmt.Errorf("failed to analyze deletion impact: %w", err)
+		}
+		impact.Orphaned = delCmdFlags.orphanComponents
+
 		// Show packages to be deleted and ask for confirmation
-		if err := confirmDeletion(packagesToDelete, packageNames); err != nil {
+		if err := confirmDeletion(packagesToDelete, packageNames, impact, delCmdFlags.yes); err != nil {
 			return err
 		}
 
@@ -145,9 +173,18 @@ Multiple -f flags can be specified, and they can point to files or directories.`
 
 		// Delete each package
 		for _, packageName := range deleteOrder {
+			var opts []client.DeleteOption
+			if delCmdFlags.orphanComponents {
+				orphaned, err := orphanPackageComponents(ctx, k8sClient, packageName)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "✓ Detached %d HelmRelease(s) from Package %s\n", len(orphaned), packageName)
+				opts = append(opts, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+			}
 			pkg := &cozyv1alpha1.Package{}
 			pkg.Name = packageName
-			if err := k8sClient.Delete(ctx, pkg); err != nil {
+			if err := k8sClient.Delete(ctx, pkg, opts...); err != nil {
 				if apierrors.IsNotFound(err) {
 					fmt.Fprintf(os.Stderr, "⚠ Package %s not found, skipping\n", packageName)
 					continue
@@ -233,8 +270,9 @@ func findPackagesToDelete(ctx context.Context, k8sClient client.Client, requeste
 	return packagesToDelete, nil
 }
 
-// confirmDeletion shows the list of packages to be deleted and asks for user confirmation
-func confirmDeletion(packagesToDelete map[string]bool, requestedPackages map[string]bool) error {
+// confirmDeletion shows the list of packages to be deleted along with the affected
+// resources and asks for user confirmation unless yes is set
+func confirmDeletion(packagesToDelete map[string]bool, requestedPackages map[string]bool, impact *deletionImpact, yes bool) error {
 	// Separate requested packages from dependents
 	var requested []string
 	var dependents []string
@@ -265,7 +303,16 @@ func confirmDeletion(packagesToDelete map[string]bool, requestedPackages map[str
 		fmt.Fprintf(os.Stderr, "\n")
 	}
 
-	fmt.Fprintf(os.Stderr, "Total: %d package(s)\n\n", len(packagesToDelete))
+	printDeletionImpact(os.Stderr, impact)
+
+	fmt.Fprintf(os.Stderr, "Total: %d package(s)", len(packagesToDelete))
+	if impact != nil && len(impact.HelmReleases) > 0 {
+		fmt.Fprintf(os.Stderr, ", %d HelmRelease(s) in %d namespace(s)", len(impact.HelmReleases), len(impact.Namespaces))
+	}
+	fmt.Fprintf(os.Stderr, "\n\n")
+	if yes {
+		return nil
+	}
 	fmt.Fprintf(os.Stderr, "Do you want to continue? [y/N]: ")
 
 	reader := bufio.NewReader(os.Stdin)
@@ -390,7 +437,10 @@ func getDeleteOrder(ctx context.Context, k8sClient client.Client, packagesToDele
 
 func init() {
 	rootCmd.AddCommand(delCmd)
-	delCmd.Flags().StringArrayVarP(&delCmdFlags.files, "file", "f", []string{}, "Read packages from file or directory (can be specified multiple times)")
+	delCmd.Flags().StringArrayVarP(&delCmdFlags.files, "file", "f", []string{}, "Read packages from a file, directory, kustomization, http(s) URL or - for stdin (can be specified multiple times)")
 	delCmd.Flags().StringVar(&delCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+	delCmd.Flags().BoolVarP(&delCmdFlags.yes, "yes", "y", false, "Delete without asking for confirmation")
+	delCmd.Flags().BoolVar(&delCmdFlags.nonInteractive, "non-interactive", false, "Never prompt; fail unless --yes is given")
+	delCmd.Flags().BoolVar(&delCmdFlags.orphanComponents, "orphan-components", false, "Keep the HelmReleases of the packages installed, detached from them")
 }
 
@@ -71,7 +71,7 @@ Specify packages as arguments or use -f flag to read from files.`,
 				return fmt.Errorf("failed to read packages from %s: %w", filePath, err)
 			}
 			for _, pkg := range packages {
-				packageNames[pkg] = true
+				packageNames[pkg.name] = true
 			}
 		}
 
@@ -111,7 +111,7 @@ func init() {
 	rootCmd.AddCommand(dotCmd)
 	dotCmd.Flags().BoolVarP(&dotCmdFlags.installed, "installed", "i", false, "show dependencies only for installed Package resources")
 	dotCmd.Flags().BoolVar(&dotCmdFlags.components, "components", false, "show component-level dependencies")
-	dotCmd.Flags().StringArrayVarP(&dotCmdFlags.files, "file", "f", []string{}, "Read packages from file or directory (can be specified multiple times)")
+	dotCmd.Flags().StringArrayVarP(&dotCmdFlags.files, "file", "f", []string{}, "Read packages from a file, directory, kustomization, http(s) URL or - for stdin (can be specified multiple times)")
 	dotCmd.Flags().StringVar(&dotCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
 }
 
@@ -0,0 +1,286 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const devRevertTimeout = 30 * time.Second
+
+var devCmdFlags struct {
+	source     string
+	registry   string
+	path       string
+	namespace  string
+	insecure   bool
+	flux       string
+	kubeconfig string
+}
+
+var devCmd = &cobra.Command{
+	Use:   "dev <packagesource>",
+	Short: "Temporarily install a PackageSource from a local directory",
+	Long: `Push a local directory of packages as an OCI artifact and point a
+PackageSource at it, for iterating on packages without publishing them.
+
+The directory is pushed to --registry with "flux push artifact", and an
+OCIRepository pinned to the pushed digest is created next to the original source.
+The PackageSource is then patched to use it. Press Enter to push the directory
+again and roll out the changes.
+
+On interrupt (Ctrl+C), the original sourceRef of the PackageSource is restored
+and the temporary OCIRepository is deleted.`,
+	Example: `  cozypkg dev cozystack.postgres-operator --source ./packages \
+    --registry oci://localhost:5000/cozypkg-dev --insecure`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if devCmdFlags.source == "" {
+			return fmt.Errorf("--source is required")
+		}
+		if devCmdFlags.registry == "" {
+			return fmt.Errorf("--registry is required")
+		}
+		if !strings.HasPrefix(devCmdFlags.registry, "oci://") {
+			return fmt.Errorf("--registry must start with oci://")
+		}
+		if info, err := os.Stat(devCmdFlags.source); err != nil {
+			return fmt.Errorf("failed to read %s: %w", devCmdFlags.source, err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", devCmdFlags.source)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// Create Kubernetes client config
+		var config *rest.Config
+		var err error
+
+		if devCmdFlags.kubeconfig != "" {
+			config, err = clientcmd.BuildConfigFromFlags("", devCmdFlags.kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to load kubeconfig from %s: %w", devCmdFlags.kubeconfig, err)
+			}
+		} else {
+			config, err = ctrl.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig: %w", err)
+			}
+		}
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+		utilruntime.Must(sourcev1.AddToScheme(scheme))
+
+		k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("failed to create k8s client: %w", err)
+		}
+
+		ps := &cozyv1alpha1.PackageSource{}
+		if err := k8sClient.Get(ctx, types.NamespacedName{Name: name}, ps); err != nil {
+			return fmt.Errorf("failed to get PackageSource %s: %w", name, err)
+		}
+		original := ps.Spec.SourceRef.DeepCopy()
+
+		namespace := devCmdFlags.namespace
+		if namespace == "" && original != nil {
+			namespace = original.Namespace
+		}
+		if namespace == "" {
+			namespace = "cozy-system"
+		}
+		repoName := devRepositoryName(name)
+		repoURL := strings.TrimSuffix(devCmdFlags.registry, "/") + "/" + repoName
+
+		digest, err := pushDevArtifact(ctx, repoURL)
+		if err != nil {
+			return err
+		}
+
+		repo := &sourcev1.OCIRepository{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      repoName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"cozystack.io/dev-packagesource": name,
+				},
+			},
+			Spec: sourcev1.OCIRepositorySpec{
+				URL:       repoURL,
+				Reference: &sourcev1.OCIRepositoryRef{Digest: digest},
+				Interval:  metav1.Duration{Duration: time.Minute},
+				Insecure:  devCmdFlags.insecure,
+			},
+		}
+		if err := k8sClient.Create(ctx, repo); err != nil {
+			return fmt.Errorf("failed to create OCIRepository %s/%s: %w", namespace, repoName, err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Created OCIRepository %s/%s (%s)\n", namespace, repoName, digest)
+
+		// From here on, always put the PackageSource back and clean up, even if
+		// the context has been cancelled by an interrupt
+		defer func() {
+			revertCtx, cancel := context.WithTimeout(context.Background(), devRevertTimeout)
+			defer cancel()
+			if err := setPackageSourceRef(revertCtx, k8sClient, name, original); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠ Failed to restore sourceRef of PackageSource %s: %v\n", name, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "✓ Restored sourceRef of PackageSource %s\n", name)
+			}
+			if err := k8sClient.Delete(revertCtx, repo); err != nil && !apierrors.IsNotFound(err) {
+				fmt.Fprintf(os.Stderr, "⚠ Failed to delete OCIRepository %s/%s: %v\n", namespace, repoName, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "✓ Deleted OCIRepository %s/%s\n", namespace, repoName)
+			}
+		}()
+
+		devRef := &cozyv1alpha1.PackageSourceRef{
+			Kind:      sourcev1.OCIRepositoryKind,
+			Name:      repoName,
+			Namespace: namespace,
+			Path:      devCmdFlags.path,
+		}
+		if err := setPackageSourceRef(ctx, k8sClient, name, devRef); err != nil {
+			return fmt.Errorf("failed to patch PackageSource %s: %w", name, err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ PackageSource %s now uses %s\n", name, devCmdFlags.source)
+		fmt.Fprintln(os.Stderr, "Press Enter to push changes, Ctrl+C to revert and exit")
+
+		lines := make(chan struct{})
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				lines <- struct{}{}
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				fmt.Fprintln(os.Stderr)
+				return nil
+			case <-lines:
+			}
+
+			digest, err := pushDevArtifact(ctx, repoURL)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠ %v\n", err)
+				continue
+			}
+			err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(repo), repo); err != nil {
+					return err
+				}
+				repo.Spec.Reference = &sourcev1.OCIRepositoryRef{Digest: digest}
+				return k8sClient.Update(ctx, repo)
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠ Failed to update OCIRepository %s/%s: %v\n", namespace, repoName, err)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "✓ Pushed %s\n", digest)
+		}
+	},
+}
+
+// devRepositoryName returns the name of the temporary OCIRepository for a PackageSource
+func devRepositoryName(packageSource string) string {
+	return strings.ReplaceAll(packageSource, ".", "-") + "-dev"
+}
+
+// pushDevArtifact pushes the --source directory to repoURL with the flux CLI
+// and returns the digest of the pushed artifact.
+func pushDevArtifact(ctx context.Context, repoURL string) (string, error) {
+	args := []string{
+		"push", "artifact", repoURL + ":latest",
+		"--path", devCmdFlags.source,
+		"--source", "cozypkg-dev",
+		"--revision", fmt.Sprintf("dev@%d", time.Now().Unix()),
+		"--output", "json",
+	}
+	if devCmdFlags.insecure {
+		args = append(args, "--insecure-registry")
+	}
+	var stdout, stderr bytes.Buffer
+	push := exec.CommandContext(ctx, devCmdFlags.flux, args...)
+	push.Stdout = &stdout
+	push.Stderr = &stderr
+	if err := push.Run(); err != nil {
+		return "", fmt.Errorf("failed to push %s to %s: %w: %s", devCmdFlags.source, repoURL, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result struct {
+		Digest string `json:"digest"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil || result.Digest == "" {
+		return "", fmt.Errorf("failed to read the digest pushed to %s from %q", repoURL, stdout.String())
+	}
+	return result.Digest, nil
+}
+
+// setPackageSourceRef replaces the sourceRef of the PackageSource name with ref
+func setPackageSourceRef(ctx context.Context, k8sClient client.Client, name string, ref *cozyv1alpha1.PackageSourceRef) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ps := &cozyv1alpha1.PackageSource{}
+		if err := k8sClient.Get(ctx, types.NamespacedName{Name: name}, ps); err != nil {
+			return err
+		}
+		ps.Spec.SourceRef = ref.DeepCopy()
+		return k8sClient.Update(ctx, ps)
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+	devCmd.Flags().StringVar(&devCmdFlags.source, "source", "", "Local directory with the packages to install (required)")
+	devCmd.Flags().StringVar(&devCmdFlags.registry, "registry", "", "OCI repository prefix to push the directory to, e.g. oci://localhost:5000/cozypkg-dev (required)")
+	devCmd.Flags().StringVar(&devCmdFlags.path, "path", "", "Base path of the packages inside the directory")
+	devCmd.Flags().StringVarP(&devCmdFlags.namespace, "namespace", "n", "", "Namespace of the temporary OCIRepository (defaults to the namespace of the current source)")
+	devCmd.Flags().BoolVar(&devCmdFlags.insecure, "insecure", false, "Allow pushing to and pulling from a plain HTTP registry")
+	devCmd.Flags().StringVar(&devCmdFlags.flux, "flux", "flux", "Path to the flux CLI used to push the artifact")
+	devCmd.Flags().StringVar(&devCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deletionImpact describes the resources affected by deleting a set of packages
+type deletionImpact struct {
+	// HelmReleases are "namespace/name" of HelmReleases that will be removed
+	HelmReleases []string
+	// Namespaces are namespaces that host removed HelmReleases
+	Namespaces []string
+	// Plans are "namespace/name" of backup Plans targeting removed applications
+	Plans []string
+	// Backups are "namespace/name" of Backups of removed applications
+	Backups []string
+	// Orphaned is set if the HelmReleases are kept, detached from the packages
+	Orphaned bool
+}
+
+// analyzeDeletionImpact computes which HelmReleases, namespaces and backup
+// resources are affected by deleting packagesToDelete. HelmReleases are matched
+// by the cozystack.io/package label or by an ownerReference to a Package.
+// Backup resources are matched by their applicationRef against the application
+// labels of the affected HelmReleases.
+func analyzeDeletionImpact(ctx context.Context, k8sClient client.Client, packagesToDelete map[string]bool) (*deletionImpact, error) {
+	impact := &deletionImpact{}
+
+	var hrList helmv2.HelmReleaseList
+	if err := k8sClient.List(ctx, &hrList); err != nil {
+		if meta.IsNoMatchError(err) {
+			return impact, nil
+		}
+		return nil, fmt.Errorf("failed to list HelmReleases: %w", err)
+	}
+
+	type appKey struct {
+		namespace string
+		kind      string
+		name      string
+	}
+	affectedApps := make(map[appKey]bool)
+	namespaces := make(map[string]bool)
+
+	for i := range hrList.Items {
+		hr := &hrList.Items[i]
+		if !isOwnedByPackages(hr, packagesToDelete) {
+			continue
+		}
+		impact.HelmReleases = append(impact.HelmReleases, hr.Namespace+"/"+hr.Name)
+		namespaces[hr.Namespace] = true
+		if kind, name := hr.Labels[appsv1alpha1.ApplicationKindLabel], hr.Labels[appsv1alpha1.ApplicationNameLabel]; kind != "" && name != "" {
+			affectedApps[appKey{namespace: hr.Namespace, kind: kind, name: name}] = true
+		}
+	}
+
+	for ns := range namespaces {
+		impact.Namespaces = append(impact.Namespaces, ns)
+	}
+
+	matchesApp := func(namespace string, ref corev1.TypedLocalObjectReference) bool {
+		return affectedApps[appKey{namespace: namespace, kind: ref.Kind, name: ref.Name}]
+	}
+
+	if len(affectedApps) > 0 {
+		var plans backupsv1alpha1.PlanList
+		if err := k8sClient.List(ctx, &plans); err != nil {
+			if !meta.IsNoMatchError(err) {
+				return nil, fmt.Errorf("failed to list backup Plans: %w", err)
+			}
+		}
+		for _, plan := range plans.Items {
+			if matchesApp(plan.Namespace, plan.Spec.ApplicationRef) {
+				impact.Plans = append(impact.Plans, plan.Namespace+"/"+plan.Name)
+			}
+		}
+
+		var backups backupsv1alpha1.BackupList
+		if err := k8sClient.List(ctx, &backups); err != nil {
+			if !meta.IsNoMatchError(err) {
+				return nil, fmt.Errorf("failed to list Backups: %w", err)
+			}
+		}
+		for _, backup := range backups.Items {
+			if matchesApp(backup.Namespace, backup.Spec.ApplicationRef) {
+				impact.Backups = append(impact.Backups, backup.Namespace+"/"+backup.Name)
+			}
+		}
+	}
+
+	sort.Strings(impact.HelmReleases)
+	sort.Strings(impact.Namespaces)
+	sort.Strings(impact.Plans)
+	sort.Strings(impact.Backups)
+
+	return impact, nil
+}
+
+// isOwnedByPackages reports whether a HelmRelease belongs to one of the packages
+func isOwnedByPackages(hr *helmv2.HelmRelease, packages map[string]bool) bool {
+	if packages[hr.Labels["cozystack.io/package"]] {
+		return true
+	}
+	for _, ref := range hr.OwnerReferences {
+		if ref.Kind == "Package" && ref.APIVersion == cozyv1alpha1.GroupVersion.String() && packages[ref.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// printDeletionImpact writes a human-readable impact report
+func printDeletionImpact(w io.Writer, impact *deletionImpact) {
+	if impact == nil {
+		return
+	}
+
+	printSection := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "%s:\n", title)
+		for _, item := range items {
+			fmt.Fprintf(w, "  - %s\n", item)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	if impact.Orphaned {
+		printSection("HelmReleases (will be kept, detached from their Package)", impact.HelmReleases)
+		return
+	}
+	printSection("HelmReleases (will be uninstalled)", impact.HelmReleases)
+	printSection("Namespaces (workloads will be removed, namespaces are kept)", impact.Namespaces)
+	printSection("Backup Plans (will stop working)", impact.Plans)
+	printSection("Backups (application will no longer exist)", impact.Backups)
+}
@@ -18,13 +18,16 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -32,6 +35,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
@@ -39,6 +43,7 @@ var listCmdFlags struct {
 	installed   bool
 	components  bool
 	kubeconfig  string
+	output      string
 }
 
 var listCmd = &cobra.Command{
@@ -47,11 +52,21 @@ var listCmd = &cobra.Command{
 	Long: `List PackageSource or Package resources in table format.
 
 By default, lists PackageSource resources. Use --installed flag to list installed Package resources.
-Use --components flag to show components on separate lines.`,
+Use --components flag to show components on separate lines.
+
+Use -o wide to show untruncated messages and additional columns, or -o json and
+-o yaml to print an array of the resources with their readiness and components
+for use in scripts.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
+		switch listCmdFlags.output {
+		case "", "wide", "json", "yaml":
+		default:
+			return fmt.Errorf("unsupported output format %q (supported: wide, json, yaml)", listCmdFlags.output)
+		}
+
 		// Create Kubernetes client config
 		var config *rest.Config
 		var err error
@@ -78,60 +93,106 @@ Use --components flag to show components on separate lines.`,
 		}
 
 		if listCmdFlags.installed {
-			return listPackages(ctx, k8sClient, listCmdFlags.components)
+			return listPackages(ctx, k8sClient, listCmdFlags.components, listCmdFlags.output)
 		}
-		return listPackageSources(ctx, k8sClient, listCmdFlags.components)
+		return listPackageSources(ctx, k8sClient, listCmdFlags.components, listCmdFlags.output)
 	},
 }
 
-func listPackageSources(ctx context.Context, k8sClient client.Client, showComponents bool) error {
+// packageSourceListItem is a PackageSource as printed by list -o json|yaml
+type packageSourceListItem struct {
+	Name     string                     `json:"name"`
+	Ready    string                     `json:"ready"`
+	Message  string                     `json:"message,omitempty"`
+	Source   string                     `json:"source"`
+	Variants []packageSourceListVariant `json:"variants"`
+}
+
+// packageSourceListVariant is a variant of a packageSourceListItem
+type packageSourceListVariant struct {
+	Name       string   `json:"name"`
+	Components []string `json:"components"`
+}
+
+// packageListItem is a Package as printed by list -o json|yaml
+type packageListItem struct {
+	Name       string                 `json:"name"`
+	Variant    string                 `json:"variant"`
+	Ready      string                 `json:"ready"`
+	Message    string                 `json:"message,omitempty"`
+	Components []packageListComponent `json:"components"`
+}
+
+// packageListComponent is a component of a packageListItem, with the
+// readiness of its HelmRelease as reported by the operator
+type packageListComponent struct {
+	Name        string `json:"name"`
+	HelmRelease string `json:"helmRelease,omitempty"`
+	Ready       string `json:"ready,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+func listPackageSources(ctx context.Context, k8sClient client.Client, showComponents bool, output string) error {
 	var psList cozyv1alpha1.PackageSourceList
 	if err := k8sClient.List(ctx, &psList); err != nil {
 		return fmt.Errorf("failed to list PackageSources: %w", err)
 	}
 
+	items := make([]packageSourceListItem, 0, len(psList.Items))
+	for _, ps := range psList.Items {
+		item := packageSourceListItem{
+			Name:     ps.Name,
+			Source:   describeSourceRef(ps.Spec.SourceRef),
+			Variants: []packageSourceListVariant{},
+		}
+		item.Ready, item.Message = conditionReadiness(ps.Status.Conditions)
+		for _, variant := range ps.Spec.Variants {
+			v := packageSourceListVariant{Name: variant.Name, Components: []string{}}
+			for _, component := range variant.Components {
+				v.Components = append(v.Components, component.Name)
+			}
+			item.Variants = append(item.Variants, v)
+		}
+		items = append(items, item)
+	}
+	if output == "json" || output == "yaml" {
+		return printListItems(os.Stdout, items, output)
+	}
+	wide := output == "wide"
+
 	// Use tabwriter for better column alignment
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
 	defer w.Flush()
 
 	// Print header
-	fmt.Fprintln(w, "NAME\tVARIANTS\tREADY\tSTATUS")
+	if wide {
+		fmt.Fprintln(w, "NAME\tVARIANTS\tREADY\tSOURCE\tSTATUS")
+	} else {
+		fmt.Fprintln(w, "NAME\tVARIANTS\tREADY\tSTATUS")
+	}
 
 	// Print rows
-	for _, ps := range psList.Items {
-		// Get variants
+	for _, item := range items {
 		var variants []string
-		for _, variant := range ps.Spec.Variants {
+		for _, variant := range item.Variants {
 			variants = append(variants, variant.Name)
 		}
 		variantsStr := strings.Join(variants, ",")
-		if len(variantsStr) > 28 {
-			variantsStr = variantsStr[:25] + "..."
-		}
-
-		// Get Ready condition
-		ready := "Unknown"
-		status := ""
-		for _, condition := range ps.Status.Conditions {
-			if condition.Type == "Ready" {
-				ready = string(condition.Status)
-				status = condition.Message
-				if len(status) > 48 {
-					status = status[:45] + "..."
-				}
-				break
-			}
+		if wide {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.Name, variantsStr, item.Ready, item.Source, item.Message)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Name, truncate(variantsStr, 28), item.Ready, truncate(item.Message, 48))
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ps.Name, variantsStr, ready, status)
-
 		// Show components if requested
 		if showComponents {
-			for _, variant := range ps.Spec.Variants {
+			for _, variant := range item.Variants {
 				for _, component := range variant.Components {
-					fmt.Fprintf(w, "  %s\t%s\t\t\n", 
-						fmt.Sprintf("%s.%s", ps.Name, component.Name), 
-						variant.Name)
+					fmt.Fprintf(w, "  %s.%s\t%s\t\t", item.Name, component, variant.Name)
+					if wide {
+						fmt.Fprint(w, "\t")
+					}
+					fmt.Fprintln(w)
 				}
 			}
 		}
@@ -140,15 +201,16 @@ func listPackageSources(ctx context.Context, k8sClient client.Client, showCompon
 	return nil
 }
 
-func listPackages(ctx context.Context, k8sClient client.Client, showComponents bool) error {
+func listPackages(ctx context.Context, k8sClient client.Client, showComponents bool, output string) error {
 	var pkgList cozyv1alpha1.PackageList
 	if err := k8sClient.List(ctx, &pkgList); err != nil {
 		return fmt.Errorf("failed to list Packages: %w", err)
 	}
 
-	// Fetch all PackageSource resources once if components are requested
+	// Components are looked up in the PackageSources when they are shown
+	structured := output == "json" || output == "yaml"
 	var psMap map[string]*cozyv1alpha1.PackageSource
-	if showComponents {
+	if showComponents || structured || output == "wide" {
 		var psList cozyv1alpha1.PackageSourceList
 		if err := k8sClient.List(ctx, &psList); err != nil {
 			return fmt.Errorf("failed to list PackageSources: %w", err)
@@ -159,50 +221,65 @@ func listPackages(ctx context.Context, k8sClient client.Client, showComponents b
 		}
 	}
 
+	items := make([]packageListItem, 0, len(pkgList.Items))
+	for _, pkg := range pkgList.Items {
+		item := packageListItem{Name: pkg.Name, Variant: pkg.Spec.Variant, Components: []packageListComponent{}}
+		if item.Variant == "" {
+			item.Variant = "default"
+		}
+		item.Ready, item.Message = conditionReadiness(pkg.Status.Conditions)
+		if ps, exists := psMap[pkg.Name]; exists {
+			if variant := findVariant(ps, item.Variant); variant != nil {
+				for _, component := range variant.Components {
+					c := packageListComponent{Name: component.Name}
+					if cs, ok := pkg.Status.Components[component.Name]; ok {
+						c.HelmRelease = cs.HelmRelease
+						c.Ready = string(cs.Ready)
+						c.Message = cs.Message
+					}
+					item.Components = append(item.Components, c)
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	if structured {
+		return printListItems(os.Stdout, items, output)
+	}
+	wide := output == "wide"
+
 	// Use tabwriter for better column alignment
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
 	defer w.Flush()
 
 	// Print header
-	fmt.Fprintln(w, "NAME\tVARIANT\tREADY\tSTATUS")
+	if wide {
+		fmt.Fprintln(w, "NAME\tVARIANT\tREADY\tCOMPONENTS\tSTATUS")
+	} else {
+		fmt.Fprintln(w, "NAME\tVARIANT\tREADY\tSTATUS")
+	}
 
 	// Print rows
-	for _, pkg := range pkgList.Items {
-		variant := pkg.Spec.Variant
-		if variant == "" {
-			variant = "default"
-		}
-
-		// Get Ready condition
-		ready := "Unknown"
-		status := ""
-		for _, condition := range pkg.Status.Conditions {
-			if condition.Type == "Ready" {
-				ready = string(condition.Status)
-				status = condition.Message
-				if len(status) > 48 {
-					status = status[:45] + "..."
+	for _, item := range items {
+		if wide {
+			ready := 0
+			for _, c := range item.Components {
+				if c.Ready == string(metav1.ConditionTrue) {
+					ready++
 				}
-				break
 			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%s\n", item.Name, item.Variant, item.Ready, ready, len(item.Components), item.Message)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Name, item.Variant, item.Ready, truncate(item.Message, 48))
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pkg.Name, variant, ready, status)
-
 		// Show components if requested
 		if showComponents {
-			// Look up PackageSource from map instead of making API call
-			if ps, exists := psMap[pkg.Name]; exists {
-				// Find the variant
-				for _, v := range ps.Spec.Variants {
-					if v.Name == variant {
-						for _, component := range v.Components {
-							fmt.Fprintf(w, "  %s\t%s\t\t\n", 
-								fmt.Sprintf("%s.%s", pkg.Name, component.Name), 
-								variant)
-						}
-						break
-					}
+			for _, c := range item.Components {
+				if wide {
+					fmt.Fprintf(w, "  %s.%s\t%s\t%s\t%s\t%s\n", item.Name, c.Name, item.Variant, c.Ready, c.HelmRelease, c.Message)
+				} else {
+					fmt.Fprintf(w, "  %s.%s\t%s\t%s\t%s\n", item.Name, c.Name, item.Variant, c.Ready, truncate(c.Message, 48))
 				}
 			}
 		}
@@ -211,10 +288,34 @@ func listPackages(ctx context.Context, k8sClient client.Client, showComponents b
 	return nil
 }
 
+// printListItems prints items as a JSON or YAML array
+func printListItems(w io.Writer, items interface{}, output string) error {
+	if output == "yaml" {
+		data, err := yaml.Marshal(items)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+// truncate shortens s to at most max characters for table output
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max-3] + "..."
+	}
+	return s
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().BoolVarP(&listCmdFlags.installed, "installed", "i", false, "list installed Package resources instead of PackageSource resources")
 	listCmd.Flags().BoolVar(&listCmdFlags.components, "components", false, "show components on separate lines")
 	listCmd.Flags().StringVar(&listCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+	listCmd.Flags().StringVarP(&listCmdFlags.output, "output", "o", "", "Output format: wide, json or yaml")
 }
 
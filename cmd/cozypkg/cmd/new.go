@@ -0,0 +1,226 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+var newPackageSourceCmdFlags struct {
+	fromDir         string
+	namespace       string
+	sourceKind      string
+	sourceName      string
+	sourceNamespace string
+	sourcePath      string
+	output          string
+}
+
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Scaffold new Cozystack resources",
+}
+
+var newPackageSourceCmd = &cobra.Command{
+	Use:   "packagesource <name>",
+	Short: "Scaffold a PackageSource from a directory of Helm charts",
+	Long: `Scan a directory of Helm charts and print a PackageSource with a single
+"default" variant.
+
+Every library chart (type: library in Chart.yaml) becomes a variant library.
+Every application chart becomes a component installed as a HelmRelease named
+after the chart into --namespace, and uses the libraries it lists in its
+Chart.yaml dependencies or links into its charts/ directory. Chart paths are relative to --from-dir, which should
+be the directory the source reference points to.
+
+The result is a starting point: review install namespaces, dependencies
+between components and other PackageSources before applying it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ps, err := scaffoldPackageSource(args[0], newPackageSourceCmdFlags.fromDir)
+		if err != nil {
+			return err
+		}
+
+		data, err := marshalScaffold(ps)
+		if err != nil {
+			return err
+		}
+
+		if newPackageSourceCmdFlags.output == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(newPackageSourceCmdFlags.output, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", newPackageSourceCmdFlags.output, err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ PackageSource %s written to %s\n", args[0], newPackageSourceCmdFlags.output)
+		return nil
+	},
+}
+
+// chartMetadata is the part of Chart.yaml used for scaffolding
+type chartMetadata struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Dependencies []struct {
+		Name string `json:"name"`
+	} `json:"dependencies"`
+}
+
+// scaffoldPackageSource builds a PackageSource from the Helm charts found under dir
+func scaffoldPackageSource(name, dir string) (*cozyv1alpha1.PackageSource, error) {
+	type chart struct {
+		path string
+		meta chartMetadata
+		// subcharts are the names of the charts in the charts/ directory of the chart
+		subcharts []string
+	}
+	var charts []chart
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "Chart.yaml" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var meta chartMetadata
+		if err := yaml.Unmarshal(data, &meta); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		chartDir := filepath.Dir(path)
+		rel, err := filepath.Rel(dir, chartDir)
+		if err != nil {
+			return err
+		}
+		if meta.Name == "" {
+			meta.Name = filepath.Base(chartDir)
+		}
+		var subcharts []string
+		if entries, err := os.ReadDir(filepath.Join(chartDir, "charts")); err == nil {
+			for _, e := range entries {
+				subcharts = append(subcharts, e.Name())
+			}
+		}
+		charts = append(charts, chart{path: filepath.ToSlash(rel), meta: meta, subcharts: subcharts})
+		// Charts vendored into a chart are not components of their own
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	if len(charts) == 0 {
+		return nil, fmt.Errorf("no Helm charts found in %s", dir)
+	}
+	sort.Slice(charts, func(i, j int) bool { return charts[i].path < charts[j].path })
+
+	variant := cozyv1alpha1.Variant{Name: "default"}
+	for _, c := range charts {
+		if c.meta.Type == "library" {
+			variant.Libraries = append(variant.Libraries, cozyv1alpha1.Library{Name: c.meta.Name, Path: c.path})
+		}
+	}
+	for _, c := range charts {
+		if c.meta.Type == "library" {
+			continue
+		}
+		component := cozyv1alpha1.Component{
+			Name: c.meta.Name,
+			Path: c.path,
+			Install: &cozyv1alpha1.ComponentInstall{
+				ReleaseName: c.meta.Name,
+				Namespace:   newPackageSourceCmdFlags.namespace,
+			},
+		}
+		// Libraries are referenced as Chart.yaml dependencies or placed in charts/
+		used := map[string]bool{}
+		for _, dep := range c.meta.Dependencies {
+			used[dep.Name] = true
+		}
+		for _, sub := range c.subcharts {
+			used[sub] = true
+		}
+		for _, lib := range variant.Libraries {
+			if used[lib.Name] {
+				component.Libraries = append(component.Libraries, lib.Name)
+			}
+		}
+		variant.Components = append(variant.Components, component)
+	}
+
+	return &cozyv1alpha1.PackageSource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: cozyv1alpha1.GroupVersion.String(),
+			Kind:       "PackageSource",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: cozyv1alpha1.PackageSourceSpec{
+			SourceRef: &cozyv1alpha1.PackageSourceRef{
+				Kind:      newPackageSourceCmdFlags.sourceKind,
+				Name:      newPackageSourceCmdFlags.sourceName,
+				Namespace: newPackageSourceCmdFlags.sourceNamespace,
+				Path:      newPackageSourceCmdFlags.sourcePath,
+			},
+			Variants: []cozyv1alpha1.Variant{variant},
+		},
+	}, nil
+}
+
+// marshalScaffold renders obj as a YAML document without status and server-set metadata
+func marshalScaffold(obj runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("---\n"), data...), nil
+}
+
+func init() {
+	rootCmd.AddCommand(newCmd)
+	newCmd.AddCommand(newPackageSourceCmd)
+	newPackageSourceCmd.Flags().StringVar(&newPackageSourceCmdFlags.fromDir, "from-dir", "", "Directory with the Helm charts to package (required)")
+	newPackageSourceCmd.Flags().StringVarP(&newPackageSourceCmdFlags.namespace, "namespace", "n", "cozy-system", "Namespace to install components into")
+	newPackageSourceCmd.Flags().StringVar(&newPackageSourceCmdFlags.sourceKind, "source-kind", "OCIRepository", "Kind of the source reference (GitRepository or OCIRepository)")
+	newPackageSourceCmd.Flags().StringVar(&newPackageSourceCmdFlags.sourceName, "source-name", "cozystack-packages", "Name of the source reference")
+	newPackageSourceCmd.Flags().StringVar(&newPackageSourceCmdFlags.sourceNamespace, "source-namespace", "cozy-system", "Namespace of the source reference")
+	newPackageSourceCmd.Flags().StringVar(&newPackageSourceCmdFlags.sourcePath, "source-path", "/", "Path of --from-dir within the source")
+	newPackageSourceCmd.Flags().StringVarP(&newPackageSourceCmdFlags.output, "output", "o", "", "File to write the PackageSource to (defaults to stdout)")
+	_ = newPackageSourceCmd.MarkFlagRequired("from-dir")
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// packageLabel is set by the operator on the HelmReleases of a Package
+	packageLabel = "cozystack.io/package"
+	// orphanedFromAnnotation records on a HelmRelease detached by
+	// del --orphan-components the Package it belonged to, for adopt
+	orphanedFromAnnotation = "cozystack.io/orphaned-from-package"
+)
+
+var adoptCmdFlags struct {
+	kubeconfig string
+}
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <package> [package...]",
+	Short: "Re-attach HelmReleases detached from their Package",
+	Long: `Re-attach the HelmReleases detached with cozypkg del --orphan-components to
+their Package.
+
+The Package must exist again, for example recreated with cozypkg add. The
+HelmReleases are labeled and owned by the Package, so that the operator manages
+them and uninstalls them once the Package is deleted.`,
+	Example: `  cozypkg del --orphan-components cozystack.cilium
+  cozypkg add cozystack.cilium
+  cozypkg adopt cozystack.cilium`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		// Create Kubernetes client config
+		var config *rest.Config
+		var err error
+
+		if adoptCmdFlags.kubeconfig != "" {
+			config, err = clientcmd.BuildConfigFromFlags("", adoptCmdFlags.kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to load kubeconfig from %s: %w", adoptCmdFlags.kubeconfig, err)
+			}
+		} else {
+			config, err = ctrl.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig: %w", err)
+			}
+		}
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+		utilruntime.Must(helmv2.AddToScheme(scheme))
+
+		k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("failed to create k8s client: %w", err)
+		}
+
+		for _, name := range args {
+			pkg := &cozyv1alpha1.Package{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, pkg); err != nil {
+				return fmt.Errorf("failed to get Package %s: %w", name, err)
+			}
+			adopted, err := adoptPackageComponents(ctx, k8sClient, pkg)
+			if err != nil {
+				return err
+			}
+			if len(adopted) == 0 {
+				fmt.Fprintf(os.Stderr, "⚠ No HelmReleases detached from Package %s\n", name)
+				continue
+			}
+			for _, hr := range adopted {
+				fmt.Fprintf(os.Stderr, "✓ Adopted HelmRelease %s by Package %s\n", hr, name)
+			}
+		}
+		return nil
+	},
+}
+
+// orphanPackageComponents detaches the HelmReleases of the Package name from
+// it, so that deleting the Package keeps them installed. It returns the
+// detached HelmReleases as "namespace/name".
+func orphanPackageComponents(ctx context.Context, k8sClient client.Client, name string) ([]string, error) {
+	var hrList helmv2.HelmReleaseList
+	if err := k8sClient.List(ctx, &hrList); err != nil {
+		return nil, fmt.Errorf("failed to list HelmReleases: %w", err)
+	}
+
+	owned := map[string]bool{name: true}
+	var orphaned []string
+	for i := range hrList.Items {
+		if !isOwnedByPackages(&hrList.Items[i], owned) {
+			continue
+		}
+		key := client.ObjectKeyFromObject(&hrList.Items[i])
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			hr := &helmv2.HelmRelease{}
+			if err := k8sClient.Get(ctx, key, hr); err != nil {
+				return err
+			}
+			delete(hr.Labels, packageLabel)
+			var refs []metav1.OwnerReference
+			for _, ref := range hr.OwnerReferences {
+				if ref.Kind == "Package" && ref.APIVersion == cozyv1alpha1.GroupVersion.String() && ref.Name == name {
+					continue
+				}
+				refs = append(refs, ref)
+			}
+			hr.OwnerReferences = refs
+			if hr.Annotations == nil {
+				hr.Annotations = map[string]string{}
+			}
+			hr.Annotations[orphanedFromAnnotation] = name
+			return k8sClient.Update(ctx, hr)
+		})
+		if err != nil {
+			return orphaned, fmt.Errorf("failed to detach HelmRelease %s from Package %s: %w", key, name, err)
+		}
+		orphaned = append(orphaned, key.String())
+	}
+	return orphaned, nil
+}
+
+// adoptPackageComponents re-attaches the HelmReleases detached from pkg by
+// orphanPackageComponents. It returns the adopted HelmReleases as
+// "namespace/name".
+func adoptPackageComponents(ctx context.Context, k8sClient client.Client, pkg *cozyv1alpha1.Package) ([]string, error) {
+	var hrList helmv2.HelmReleaseList
+	if err := k8sClient.List(ctx, &hrList); err != nil {
+		return nil, fmt.Errorf("failed to list HelmReleases: %w", err)
+	}
+
+	var adopted []string
+	for i := range hrList.Items {
+		if hrList.Items[i].Annotations[orphanedFromAnnotation] != pkg.Name {
+			continue
+		}
+		key := client.ObjectKeyFromObject(&hrList.Items[i])
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			hr := &helmv2.HelmRelease{}
+			if err := k8sClient.Get(ctx, key, hr); err != nil {
+				return err
+			}
+			if hr.Labels == nil {
+				hr.Labels = map[string]string{}
+			}
+			hr.Labels[packageLabel] = pkg.Name
+			controller := true
+			hr.OwnerReferences = append(hr.OwnerReferences, metav1.OwnerReference{
+				APIVersion: cozyv1alpha1.GroupVersion.String(),
+				Kind:       "Package",
+				Name:       pkg.Name,
+				UID:        pkg.UID,
+				Controller: &controller,
+			})
+			delete(hr.Annotations, orphanedFromAnnotation)
+			return k8sClient.Update(ctx, hr)
+		})
+		if err != nil {
+			return adopted, fmt.Errorf("failed to adopt HelmRelease %s by Package %s: %w", key, pkg.Name, err)
+		}
+		adopted = append(adopted, key.String())
+	}
+	return adopted, nil
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().StringVar(&adoptCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+}
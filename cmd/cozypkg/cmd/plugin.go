@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cozystack/cozystack/pkg/cozypkg/plugin"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Inspect cozypkg plugins",
+	Long: `Plugins extend cozypkg with commands it does not ship.
+
+A plugin is an executable named cozypkg-<name> on the PATH. cozypkg <name>
+runs it with the remaining arguments, unless <name> is a built-in command.
+Dashes in the executable name map to nested commands: cozypkg-report-usage
+is run for cozypkg report usage. Plugins written in Go can use the
+github.com/cozystack/cozystack/pkg/cozypkg/plugin package to connect to the
+cluster the same way cozypkg does.`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins found on the PATH",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plugins, warnings := listPlugins()
+		if len(plugins) == 0 {
+			return fmt.Errorf("no plugins found on the PATH")
+		}
+		for _, path := range plugins {
+			fmt.Println(path)
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+		return nil
+	},
+}
+
+// findPlugin returns the executable of the plugin implementing the command
+// in args and the arguments to run it with. The longest match wins, so
+// cozypkg-report-usage is preferred over cozypkg-report for
+// "report usage". Matching stops at the first flag.
+func findPlugin(args []string) (string, []string, bool) {
+	var parts []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		parts = append(parts, arg)
+	}
+	for n := len(parts); n > 0; n-- {
+		path, err := exec.LookPath(plugin.Prefix + strings.Join(parts[:n], "-"))
+		if err == nil {
+			return path, args[n:], true
+		}
+	}
+	return "", nil, false
+}
+
+// runPlugin runs the plugin at path with args, passing it the standard
+// streams and the environment of cozypkg.
+func runPlugin(path string, args []string) error {
+	c := exec.Command(path, args...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	c.Env = os.Environ()
+	if self, err := os.Executable(); err == nil {
+		c.Env = append(c.Env, plugin.EnvCozypkg+"="+self)
+	}
+	return c.Run()
+}
+
+// listPlugins returns the plugins on the PATH, and warnings about the ones
+// that cannot be run: shadowed by a built-in command or by a plugin of the
+// same name earlier on the PATH, or not executable.
+func listPlugins() ([]string, []string) {
+	var plugins, warnings []string
+	seen := map[string]string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasPrefix(name, plugin.Prefix) {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			plugins = append(plugins, path)
+
+			if info, err := os.Stat(path); err != nil || info.Mode()&0o111 == 0 {
+				warnings = append(warnings, fmt.Sprintf("%s is not executable", path))
+			}
+			if first, ok := seen[name]; ok {
+				warnings = append(warnings, fmt.Sprintf("%s is shadowed by %s", path, first))
+				continue
+			}
+			seen[name] = path
+			command := strings.Split(strings.TrimPrefix(name, plugin.Prefix), "-")
+			if found, _, err := rootCmd.Find(command); err == nil && found != rootCmd {
+				warnings = append(warnings, fmt.Sprintf("%s is shadowed by the built-in command %q", path, found.CommandPath()))
+			}
+		}
+	}
+	sort.Strings(warnings)
+	return plugins, warnings
+}
+
+// exitCode returns the exit status of a plugin that ran and failed, or 0
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 0
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/operator"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	sourcewatcherv1beta1 "github.com/fluxcd/source-watcher/api/v2/v1beta1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var renderCmdFlags struct {
+	files      []string
+	variant    string
+	kubeconfig string
+}
+
+var renderCmd = &cobra.Command{
+	Use:   "render <package> [--variant <variant>] [-f <path>...]",
+	Short: "Print the resources the operator would create for a package",
+	Long: `Print the resources the operator would create for a package.
+
+The Namespaces, the ArtifactGenerator and the HelmReleases generated from the
+PackageSource of the package are printed as YAML manifests, without changing
+the cluster.
+
+With -f (files or directories, searched recursively for .yaml and .yml files),
+PackageSources and Packages are read from the manifests and the cluster is not
+contacted. Packages missing from the manifests are rendered with the default
+variant, or with the first variant of PackageSources without a default one. Without -f, the PackageSource and the Package are read from the
+cluster.`,
+	Example: `  cozypkg render cozystack.cilium
+  cozypkg render cozystack.cilium --variant kubeovn -f packages/`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		packageName := args[0]
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+		utilruntime.Must(helmv2.AddToScheme(scheme))
+		utilruntime.Must(sourcewatcherv1beta1.AddToScheme(scheme))
+
+		var k8sClient client.Client
+		var err error
+		if len(renderCmdFlags.files) > 0 {
+			k8sClient, err = newRenderClientFromPaths(scheme, renderCmdFlags.files)
+			if err != nil {
+				return err
+			}
+		} else {
+			var config *rest.Config
+			if renderCmdFlags.kubeconfig != "" {
+				config, err = clientcmd.BuildConfigFromFlags("", renderCmdFlags.kubeconfig)
+				if err != nil {
+					return fmt.Errorf("failed to load kubeconfig from %s: %w", renderCmdFlags.kubeconfig, err)
+				}
+			} else {
+				config, err = ctrl.GetConfig()
+				if err != nil {
+					return fmt.Errorf("failed to get kubeconfig: %w", err)
+				}
+			}
+			k8sClient, err = client.New(config, client.Options{Scheme: scheme})
+			if err != nil {
+				return fmt.Errorf("failed to create k8s client: %w", err)
+			}
+		}
+
+		packageSource := &cozyv1alpha1.PackageSource{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: packageName}, packageSource); err != nil {
+			return fmt.Errorf("failed to get PackageSource %s: %w", packageName, err)
+		}
+
+		pkg := &cozyv1alpha1.Package{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: packageName}, pkg); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get Package %s: %w", packageName, err)
+			}
+			pkg = defaultPackage(packageName)
+		}
+		if renderCmdFlags.variant != "" {
+			pkg.Spec.Variant = renderCmdFlags.variant
+		}
+
+		objects, err := renderPackage(ctx, k8sClient, scheme, pkg, packageSource)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objects {
+			data, err := yaml.Marshal(obj.Object)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			fmt.Fprintf(os.Stdout, "---\n%s", data)
+		}
+		return nil
+	},
+}
+
+// renderPackage returns the Namespaces, the ArtifactGenerator and the
+// HelmReleases the operator would create for pkg from packageSource
+func renderPackage(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, pkg *cozyv1alpha1.Package, packageSource *cozyv1alpha1.PackageSource) ([]*unstructured.Unstructured, error) {
+	variantName := pkg.Spec.Variant
+	if variantName == "" {
+		variantName = "default"
+	}
+	var variant *cozyv1alpha1.Variant
+	for i := range packageSource.Spec.Variants {
+		if packageSource.Spec.Variants[i].Name == variantName {
+			variant = &packageSource.Spec.Variants[i]
+			break
+		}
+	}
+	if variant == nil {
+		var names []string
+		for _, v := range packageSource.Spec.Variants {
+			names = append(names, v.Name)
+		}
+		return nil, fmt.Errorf("variant %s not found in PackageSource %s (available variants: %s)", variantName, packageSource.Name, strings.Join(names, ", "))
+	}
+
+	variant, err := operator.SelectComponents(ctx, k8sClient, pkg, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*unstructured.Unstructured
+
+	namespaces, err := operator.RenderNamespaces(pkg, variant)
+	if err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaces {
+		obj, err := toUnstructured(ns, "v1", "Namespace")
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+
+	sourceRenderer := &operator.PackageSourceReconciler{Client: k8sClient, Scheme: scheme}
+	ag, err := sourceRenderer.RenderArtifactGenerator(ctx, packageSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render ArtifactGenerator: %w", err)
+	}
+	if ag != nil {
+		obj, err := toUnstructured(ag, sourcewatcherv1beta1.GroupVersion.String(), sourcewatcherv1beta1.ArtifactGeneratorKind)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+
+	packageRenderer := &operator.PackageReconciler{
+		Client: &plannedPackagesClient{Client: k8sClient, planned: map[string]*cozyv1alpha1.Package{pkg.Name: pkg}},
+		Scheme: scheme,
+	}
+	releases, err := packageRenderer.RenderHelmReleases(ctx, pkg, packageSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render HelmReleases: %w", err)
+	}
+	for _, hr := range releases {
+		obj, err := toUnstructured(hr, helmv2.GroupVersion.String(), helmv2.HelmReleaseKind)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// newRenderClientFromPaths returns a client serving the PackageSources and
+// Packages defined in files or directories. PackageSources without a Package
+// get one with the default variant, or their first variant if they have no
+// default one, so that dependencies can be resolved.
+func newRenderClientFromPaths(scheme *runtime.Scheme, paths []string) (client.Client, error) {
+	sources, err := readPackageSourcesFromPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	packages, err := readPackagesFromPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range sources {
+		builder = builder.WithObjects(&sources[i])
+		if _, ok := packages[sources[i].Name]; !ok {
+			pkg := defaultPackage(sources[i].Name)
+			pkg.Spec.Variant = defaultVariantName(&sources[i])
+			packages[sources[i].Name] = pkg
+		}
+	}
+	for _, pkg := range packages {
+		builder = builder.WithObjects(pkg)
+	}
+	return builder.Build(), nil
+}
+
+// readPackagesFromPaths reads the Packages defined in files or directories,
+// searched recursively for YAML files, by name
+func readPackagesFromPaths(paths []string) (map[string]*cozyv1alpha1.Package, error) {
+	packages := map[string]*cozyv1alpha1.Package{}
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml") {
+				return nil
+			}
+			items, err := readObjectsFromYAMLFile(path, "Package")
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			for _, item := range items {
+				pkg := &cozyv1alpha1.Package{}
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item, pkg); err != nil {
+					return fmt.Errorf("failed to convert Package in %s: %w", path, err)
+				}
+				packages[pkg.Name] = pkg
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return packages, nil
+}
+
+// defaultVariantName returns the variant of a PackageSource a Package is
+// assumed to use when none is given
+func defaultVariantName(ps *cozyv1alpha1.PackageSource) string {
+	for _, v := range ps.Spec.Variants {
+		if v.Name == "default" {
+			return ""
+		}
+	}
+	if len(ps.Spec.Variants) > 0 {
+		return ps.Spec.Variants[0].Name
+	}
+	return ""
+}
+
+// defaultPackage returns a Package named name with the default variant
+func defaultPackage(name string) *cozyv1alpha1.Package {
+	return &cozyv1alpha1.Package{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: cozyv1alpha1.GroupVersion.String(),
+			Kind:       "Package",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+	renderCmd.Flags().StringArrayVarP(&renderCmdFlags.files, "file", "f", []string{}, "File or directory with PackageSource and Package manifests (can be specified multiple times)")
+	renderCmd.Flags().StringVar(&renderCmdFlags.variant, "variant", "", "Variant to render (defaults to the variant of the Package, or default)")
+	renderCmd.Flags().StringVar(&renderCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	sourcewatcherv1beta1 "github.com/fluxcd/source-watcher/api/v2/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDefaultVariantName(t *testing.T) {
+	cases := []struct {
+		name     string
+		variants []string
+		want     string
+	}{
+		{name: "default variant", variants: []string{"ha", "default"}, want: ""},
+		{name: "first variant", variants: []string{"kubeovn", "cilium"}, want: "kubeovn"},
+		{name: "no variants", want: ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := &cozyv1alpha1.PackageSource{}
+			for _, name := range tc.variants {
+				ps.Spec.Variants = append(ps.Spec.Variants, cozyv1alpha1.Variant{Name: name})
+			}
+			if got := defaultVariantName(ps); got != tc.want {
+				t.Errorf("defaultVariantName = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+const renderTestManifests = `apiVersion: cozystack.io/v1alpha1
+kind: PackageSource
+metadata:
+  name: cozystack.networking
+spec:
+  sourceRef:
+    kind: OCIRepository
+    name: cozystack-packages
+    namespace: cozy-system
+  variants:
+  - name: kubeovn
+    components:
+    - name: kubeovn
+      path: system/kubeovn
+      install:
+        namespace: cozy-kubeovn
+        privileged: true
+  - name: cilium
+    components:
+    - name: cilium
+      path: system/cilium
+      install:
+        namespace: cozy-cilium
+---
+apiVersion: cozystack.io/v1alpha1
+kind: PackageSource
+metadata:
+  name: cozystack.monitoring
+spec:
+  sourceRef:
+    kind: OCIRepository
+    name: cozystack-packages
+    namespace: cozy-system
+  variants:
+  - name: default
+    components:
+    - name: grafana
+      path: system/grafana
+      install:
+        namespace: cozy-monitoring
+---
+apiVersion: cozystack.io/v1alpha1
+kind: Package
+metadata:
+  name: cozystack.monitoring
+spec:
+  components:
+    grafana:
+      enabled: false
+`
+
+func TestRenderPackageFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "packages.yaml"), []byte(renderTestManifests), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(helmv2.AddToScheme(scheme))
+	utilruntime.Must(sourcewatcherv1beta1.AddToScheme(scheme))
+
+	k8sClient, err := newRenderClientFromPaths(scheme, []string{dir})
+	if err != nil {
+		t.Fatalf("newRenderClientFromPaths: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		pkg     string
+		variant string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "package missing from the files uses the first variant",
+			pkg:  "cozystack.networking",
+			want: []string{"Namespace/cozy-kubeovn", "ArtifactGenerator/cozy-system/cozystack.networking", "HelmRelease/cozy-kubeovn/kubeovn"},
+		},
+		{
+			name:    "variant override",
+			pkg:     "cozystack.networking",
+			variant: "cilium",
+			want:    []string{"Namespace/cozy-cilium", "ArtifactGenerator/cozy-system/cozystack.networking", "HelmRelease/cozy-cilium/cilium"},
+		},
+		{
+			name: "disabled component",
+			pkg:  "cozystack.monitoring",
+			want: []string{"ArtifactGenerator/cozy-system/cozystack.monitoring"},
+		},
+		{
+			name:    "unknown variant",
+			pkg:     "cozystack.monitoring",
+			variant: "ha",
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.TODO()
+			packageSource := &cozyv1alpha1.PackageSource{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: tc.pkg}, packageSource); err != nil {
+				t.Fatalf("Get PackageSource: %v", err)
+			}
+			pkg := &cozyv1alpha1.Package{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: tc.pkg}, pkg); err != nil {
+				t.Fatalf("Get Package: %v", err)
+			}
+			if tc.variant != "" {
+				pkg.Spec.Variant = tc.variant
+			}
+
+			objects, err := renderPackage(ctx, k8sClient, scheme, pkg, packageSource)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("renderPackage succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderPackage: %v", err)
+			}
+			var got []string
+			for _, obj := range objects {
+				got = append(got, objectDisplayName(obj))
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("rendered %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
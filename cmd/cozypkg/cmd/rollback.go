@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var rollbackCmdFlags struct {
+	list       bool
+	kubeconfig string
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <package> [revision]",
+	Short: "Roll a Package back to a previous revision",
+	Long: `Restore the spec of a Package from its revision history.
+
+The operator records every successfully reconciled Package spec as a
+PackageRevision. Without a revision number, the Package is rolled back to the
+revision before the latest one. The operator then reconciles the HelmReleases
+of the restored spec, which is recorded as a new revision.
+
+Use --list to show the revision history of the Package.`,
+	Example: `  cozypkg rollback cozystack.cilium --list
+  cozypkg rollback cozystack.cilium
+  cozypkg rollback cozystack.cilium 3`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		name := args[0]
+
+		// Create Kubernetes client config
+		var config *rest.Config
+		var err error
+
+		if rollbackCmdFlags.kubeconfig != "" {
+			config, err = clientcmd.BuildConfigFromFlags("", rollbackCmdFlags.kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to load kubeconfig from %s: %w", rollbackCmdFlags.kubeconfig, err)
+			}
+		} else {
+			config, err = ctrl.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig: %w", err)
+			}
+		}
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+
+		k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("failed to create k8s client: %w", err)
+		}
+
+		var list cozyv1alpha1.PackageRevisionList
+		if err := k8sClient.List(ctx, &list, client.MatchingLabels{cozyv1alpha1.PackageRevisionPackageLabel: name}); err != nil {
+			return fmt.Errorf("failed to list PackageRevisions: %w", err)
+		}
+		revisions := list.Items
+		sort.Slice(revisions, func(i, j int) bool {
+			return revisions[i].Spec.Revision < revisions[j].Spec.Revision
+		})
+
+		if rollbackCmdFlags.list {
+			return listPackageRevisions(revisions)
+		}
+		if len(revisions) == 0 {
+			return fmt.Errorf("Package %s has no revision history", name)
+		}
+
+		var target *cozyv1alpha1.PackageRevision
+		if len(args) == 2 {
+			number, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid revision %q: %w", args[1], err)
+			}
+			for i := range revisions {
+				if revisions[i].Spec.Revision == number {
+					target = &revisions[i]
+				}
+			}
+			if target == nil {
+				return fmt.Errorf("revision %d of Package %s not found, use --list to show the history", number, name)
+			}
+		} else {
+			if len(revisions) < 2 {
+				return fmt.Errorf("Package %s has no revision before %d", name, revisions[0].Spec.Revision)
+			}
+			target = &revisions[len(revisions)-2]
+		}
+
+		unchanged := false
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			pkg := &cozyv1alpha1.Package{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, pkg); err != nil {
+				return err
+			}
+			// Rolling back does not resume a suspended Package
+			spec := *target.Spec.PackageSpec.DeepCopy()
+			spec.Suspend = pkg.Spec.Suspend
+			if equality.Semantic.DeepEqual(pkg.Spec, spec) {
+				unchanged = true
+				return nil
+			}
+			pkg.Spec = spec
+			return k8sClient.Update(ctx, pkg)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update Package %s: %w", name, err)
+		}
+		if unchanged {
+			fmt.Fprintf(os.Stderr, "✓ Package %s already matches revision %d\n", name, target.Spec.Revision)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "✓ Rolled back Package %s to revision %d\n", name, target.Spec.Revision)
+		return nil
+	},
+}
+
+func listPackageRevisions(revisions []cozyv1alpha1.PackageRevision) error {
+	// Use tabwriter for better column alignment
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "REVISION\tVARIANT\tHELMRELEASES\tCREATED")
+	for _, revision := range revisions {
+		variant := revision.Spec.PackageSpec.Variant
+		if variant == "" {
+			variant = "default"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n",
+			revision.Spec.Revision, variant, len(revision.Spec.HelmReleases),
+			formatTime(&revision.CreationTimestamp))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().BoolVar(&rollbackCmdFlags.list, "list", false, "List the revision history of the Package instead of rolling back")
+	rollbackCmd.Flags().StringVar(&rollbackCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+}
@@ -19,8 +19,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/cozystack/cozystack/pkg/cozypkg/plugin"
 )
 
 // rootCmd represents the base command when called without any subcommands.
@@ -36,7 +41,23 @@ var rootCmd = &cobra.Command{
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
-	if err := rootCmd.Execute(); err != nil {
+	started := time.Now()
+	if _, _, err := rootCmd.Find(os.Args[1:]); err != nil {
+		if path, args, ok := findPlugin(os.Args[1:]); ok {
+			err := runPlugin(path, args)
+			recordTelemetry("cozypkg "+strings.TrimPrefix(filepath.Base(path), plugin.Prefix), started, err)
+			if code := exitCode(err); code != 0 {
+				os.Exit(code)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+			}
+			return err
+		}
+	}
+	cmd, err := rootCmd.ExecuteC()
+	recordTelemetry(cmd.CommandPath(), started, err)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		return err
 	}
@@ -0,0 +1,566 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var sourceDiffCmdFlags struct {
+	files          []string
+	against        []string
+	againstCluster bool
+	exitCode       bool
+	kubeconfig     string
+}
+
+var sourceDiffCmd = &cobra.Command{
+	Use:   "source-diff -f <path>... (--against-cluster | --against <path>...)",
+	Short: "Show how PackageSource manifests differ from the installed ones",
+	Long: `Show how PackageSource manifests differ from the installed ones.
+
+PackageSources read with -f (files or directories, searched recursively for
+.yaml and .yml files) are compared with the PackageSources in the cluster
+(--against-cluster) or in other manifests (--against), e.g. a checkout of the
+target branch of a pull request.
+
+For every PackageSource the added and removed variants and components are
+reported, together with changed paths, charts, libraries, values files and
+install parameters. With --against-cluster, the Packages using a changed
+variant are listed as affected.`,
+	Example: `  cozypkg source-diff -f packages/ --against-cluster
+  cozypkg source-diff -f pr/packages/ --against main/packages/ --exit-code`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		if len(sourceDiffCmdFlags.files) == 0 {
+			return fmt.Errorf("at least one -f is required")
+		}
+		if sourceDiffCmdFlags.againstCluster == (len(sourceDiffCmdFlags.against) > 0) {
+			return fmt.Errorf("exactly one of --against-cluster and --against is required")
+		}
+
+		newSources, err := readPackageSourcesFromPaths(sourceDiffCmdFlags.files)
+		if err != nil {
+			return err
+		}
+
+		var oldSources []cozyv1alpha1.PackageSource
+		var packages []cozyv1alpha1.Package
+		if sourceDiffCmdFlags.againstCluster {
+			var config *rest.Config
+			if sourceDiffCmdFlags.kubeconfig != "" {
+				config, err = clientcmd.BuildConfigFromFlags("", sourceDiffCmdFlags.kubeconfig)
+				if err != nil {
+					return fmt.Errorf("failed to load kubeconfig from %s: %w", sourceDiffCmdFlags.kubeconfig, err)
+				}
+			} else {
+				config, err = ctrl.GetConfig()
+				if err != nil {
+					return fmt.Errorf("failed to get kubeconfig: %w", err)
+				}
+			}
+
+			scheme := runtime.NewScheme()
+			utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+			utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+
+			k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+			if err != nil {
+				return fmt.Errorf("failed to create k8s client: %w", err)
+			}
+
+			var sourceList cozyv1alpha1.PackageSourceList
+			if err := k8sClient.List(ctx, &sourceList); err != nil {
+				return fmt.Errorf("failed to list PackageSources: %w", err)
+			}
+			oldSources = sourceList.Items
+
+			var pkgList cozyv1alpha1.PackageList
+			if err := k8sClient.List(ctx, &pkgList); err != nil {
+				return fmt.Errorf("failed to list Packages: %w", err)
+			}
+			packages = pkgList.Items
+		} else {
+			oldSources, err = readPackageSourcesFromPaths(sourceDiffCmdFlags.against)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Only the PackageSources given with -f are compared, other installed
+		// PackageSources are not reported as removed
+		newNames := make(map[string]bool, len(newSources))
+		for _, ps := range newSources {
+			newNames[ps.Name] = true
+		}
+		if sourceDiffCmdFlags.againstCluster {
+			var compared []cozyv1alpha1.PackageSource
+			for _, ps := range oldSources {
+				if newNames[ps.Name] {
+					compared = append(compared, ps)
+				}
+			}
+			oldSources = compared
+		}
+
+		diffs := diffPackageSources(oldSources, newSources)
+		if len(diffs) == 0 {
+			fmt.Fprintln(os.Stderr, "✓ No changes to PackageSources")
+			return nil
+		}
+		printSourceDiffs(os.Stdout, diffs, packages)
+		if sourceDiffCmdFlags.exitCode {
+			return errors.New("PackageSources differ")
+		}
+		return nil
+	},
+}
+
+// sourceDiff describes the changes to a PackageSource
+type sourceDiff struct {
+	Name string
+	// Change is "added", "removed" or "changed"
+	Change string
+	// Changes lists changes outside of the variants
+	Changes []string
+	// Variants lists the changes of each variant, by variant name
+	Variants map[string][]string
+}
+
+// diffPackageSources compares PackageSources by name and returns the ones that
+// differ, sorted by name
+func diffPackageSources(oldSources, newSources []cozyv1alpha1.PackageSource) []sourceDiff {
+	oldByName := make(map[string]*cozyv1alpha1.PackageSource, len(oldSources))
+	for i := range oldSources {
+		oldByName[oldSources[i].Name] = &oldSources[i]
+	}
+	newByName := make(map[string]*cozyv1alpha1.PackageSource, len(newSources))
+	for i := range newSources {
+		newByName[newSources[i].Name] = &newSources[i]
+	}
+
+	var diffs []sourceDiff
+	for name, newPS := range newByName {
+		oldPS, ok := oldByName[name]
+		if !ok {
+			diffs = append(diffs, sourceDiff{Name: name, Change: "added", Variants: variantsAs(newPS, "added")})
+			continue
+		}
+		if d := diffPackageSource(oldPS, newPS); d != nil {
+			diffs = append(diffs, *d)
+		}
+	}
+	for name, oldPS := range oldByName {
+		if _, ok := newByName[name]; !ok {
+			diffs = append(diffs, sourceDiff{Name: name, Change: "removed", Variants: variantsAs(oldPS, "removed")})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
+
+// variantsAs reports every variant of ps with the same change
+func variantsAs(ps *cozyv1alpha1.PackageSource, change string) map[string][]string {
+	variants := make(map[string][]string, len(ps.Spec.Variants))
+	for _, v := range ps.Spec.Variants {
+		variants[v.Name] = []string{"variant " + change}
+	}
+	return variants
+}
+
+// diffPackageSource returns the changes between two versions of a PackageSource,
+// or nil if they are equivalent
+func diffPackageSource(oldPS, newPS *cozyv1alpha1.PackageSource) *sourceDiff {
+	d := &sourceDiff{Name: newPS.Name, Change: "changed", Variants: map[string][]string{}}
+	if !equality.Semantic.DeepEqual(oldPS.Spec.SourceRef, newPS.Spec.SourceRef) {
+		d.Changes = append(d.Changes, fmt.Sprintf("sourceRef: %s -> %s", describeSourceRef(oldPS.Spec.SourceRef), describeSourceRef(newPS.Spec.SourceRef)))
+	}
+	oldRefs := make(map[string]cozyv1alpha1.PackageSourceRef, len(oldPS.Spec.SourceRefs))
+	for _, ref := range oldPS.Spec.SourceRefs {
+		oldRefs[ref.Alias] = ref.PackageSourceRef
+	}
+	for _, ref := range newPS.Spec.SourceRefs {
+		old, ok := oldRefs[ref.Alias]
+		delete(oldRefs, ref.Alias)
+		if !ok || old != ref.PackageSourceRef {
+			var from *cozyv1alpha1.PackageSourceRef
+			if ok {
+				from = &old
+			}
+			d.Changes = append(d.Changes, fmt.Sprintf("sourceRefs %s: %s -> %s", ref.Alias, describeSourceRef(from), describeSourceRef(&ref.PackageSourceRef)))
+		}
+	}
+	for _, ref := range oldPS.Spec.SourceRefs {
+		if old, ok := oldRefs[ref.Alias]; ok {
+			d.Changes = append(d.Changes, fmt.Sprintf("sourceRefs %s: %s -> <none>", ref.Alias, describeSourceRef(&old)))
+		}
+	}
+
+	oldVariants := make(map[string]*cozyv1alpha1.Variant, len(oldPS.Spec.Variants))
+	for i := range oldPS.Spec.Variants {
+		oldVariants[oldPS.Spec.Variants[i].Name] = &oldPS.Spec.Variants[i]
+	}
+	newVariants := make(map[string]bool, len(newPS.Spec.Variants))
+	for i := range newPS.Spec.Variants {
+		v := &newPS.Spec.Variants[i]
+		newVariants[v.Name] = true
+		oldV, ok := oldVariants[v.Name]
+		if !ok {
+			d.Variants[v.Name] = []string{"variant added"}
+			continue
+		}
+		if changes := diffVariant(oldV, v); len(changes) > 0 {
+			d.Variants[v.Name] = changes
+		}
+	}
+	for name := range oldVariants {
+		if !newVariants[name] {
+			d.Variants[name] = []string{"variant removed"}
+		}
+	}
+
+	if len(d.Changes) == 0 && len(d.Variants) == 0 {
+		return nil
+	}
+	return d
+}
+
+// diffVariant returns the changes between two versions of a variant
+func diffVariant(oldV, newV *cozyv1alpha1.Variant) []string {
+	var changes []string
+	if !stringSetsEqual(oldV.DependsOn, newV.DependsOn) {
+		changes = append(changes, fmt.Sprintf("dependsOn: %s -> %s", formatList(oldV.DependsOn), formatList(newV.DependsOn)))
+	}
+
+	oldLibraries := make(map[string]cozyv1alpha1.Library, len(oldV.Libraries))
+	for _, l := range oldV.Libraries {
+		oldLibraries[l.Name] = l
+	}
+	newLibraries := make(map[string]bool, len(newV.Libraries))
+	for _, l := range newV.Libraries {
+		newLibraries[l.Name] = true
+		oldL, ok := oldLibraries[l.Name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("library %s added", l.Name))
+		case !equality.Semantic.DeepEqual(oldL, l):
+			changes = append(changes, fmt.Sprintf("library %s changed", l.Name))
+		}
+	}
+	for name := range oldLibraries {
+		if !newLibraries[name] {
+			changes = append(changes, fmt.Sprintf("library %s removed", name))
+		}
+	}
+
+	oldComponents := make(map[string]*cozyv1alpha1.Component, len(oldV.Components))
+	for i := range oldV.Components {
+		oldComponents[oldV.Components[i].Name] = &oldV.Components[i]
+	}
+	newComponents := make(map[string]bool, len(newV.Components))
+	for i := range newV.Components {
+		c := &newV.Components[i]
+		newComponents[c.Name] = true
+		oldC, ok := oldComponents[c.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("component %s added", c.Name))
+			continue
+		}
+		for _, change := range diffComponent(oldC, c) {
+			changes = append(changes, fmt.Sprintf("component %s: %s", c.Name, change))
+		}
+	}
+	for name := range oldComponents {
+		if !newComponents[name] {
+			changes = append(changes, fmt.Sprintf("component %s removed", name))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// diffComponent returns the changes between two versions of a component
+func diffComponent(oldC, newC *cozyv1alpha1.Component) []string {
+	var changes []string
+	if oldC.Path != newC.Path {
+		changes = append(changes, fmt.Sprintf("path %q -> %q", oldC.Path, newC.Path))
+	}
+	if !equality.Semantic.DeepEqual(oldC.ChartRef, newC.ChartRef) {
+		changes = append(changes, fmt.Sprintf("chartRef %s -> %s", describeChartRef(oldC.ChartRef), describeChartRef(newC.ChartRef)))
+	}
+	if !stringSetsEqual(oldC.Libraries, newC.Libraries) {
+		changes = append(changes, fmt.Sprintf("libraries %s -> %s", formatList(oldC.Libraries), formatList(newC.Libraries)))
+	}
+	// The order of values files matters, later files override earlier ones
+	if !equality.Semantic.DeepEqual(oldC.ValuesFiles, newC.ValuesFiles) {
+		changes = append(changes, fmt.Sprintf("valuesFiles %s -> %s", formatList(oldC.ValuesFiles), formatList(newC.ValuesFiles)))
+	}
+	switch {
+	case oldC.Install == nil && newC.Install != nil:
+		changes = append(changes, "now installed")
+	case oldC.Install != nil && newC.Install == nil:
+		changes = append(changes, "no longer installed")
+	case oldC.Install != nil && !equality.Semantic.DeepEqual(oldC.Install, newC.Install):
+		o, n := oldC.Install, newC.Install
+		if o.ReleaseName != n.ReleaseName {
+			changes = append(changes, fmt.Sprintf("install.releaseName %q -> %q", o.ReleaseName, n.ReleaseName))
+		}
+		if o.Namespace != n.Namespace {
+			changes = append(changes, fmt.Sprintf("install.namespace %q -> %q", o.Namespace, n.Namespace))
+		}
+		if o.TargetNamespace != n.TargetNamespace {
+			changes = append(changes, fmt.Sprintf("install.targetNamespace %q -> %q", o.TargetNamespace, n.TargetNamespace))
+		}
+		if o.Privileged != n.Privileged {
+			changes = append(changes, fmt.Sprintf("install.privileged %t -> %t", o.Privileged, n.Privileged))
+		}
+		if !stringSetsEqual(o.DependsOn, n.DependsOn) {
+			changes = append(changes, fmt.Sprintf("install.dependsOn %s -> %s", formatList(o.DependsOn), formatList(n.DependsOn)))
+		}
+		if !equality.Semantic.DeepEqual(o.HealthChecks, n.HealthChecks) {
+			changes = append(changes, "install.healthChecks changed")
+		}
+		if o.Test != n.Test {
+			changes = append(changes, fmt.Sprintf("install.test %t -> %t", o.Test, n.Test))
+		}
+	}
+	return changes
+}
+
+// printSourceDiffs writes a human-readable report of diffs. Packages using a
+// changed variant are listed as affected.
+func printSourceDiffs(w io.Writer, diffs []sourceDiff, packages []cozyv1alpha1.Package) {
+	packagesBySource := make(map[string]*cozyv1alpha1.Package, len(packages))
+	for i := range packages {
+		packagesBySource[packages[i].Name] = &packages[i]
+	}
+
+	var affected []string
+	for _, d := range diffs {
+		fmt.Fprintf(w, "PackageSource %s (%s)\n", d.Name, d.Change)
+		for _, change := range d.Changes {
+			fmt.Fprintf(w, "  %s\n", change)
+		}
+		variants := make([]string, 0, len(d.Variants))
+		for name := range d.Variants {
+			variants = append(variants, name)
+		}
+		sort.Strings(variants)
+		for _, name := range variants {
+			fmt.Fprintf(w, "  variant %s:\n", name)
+			for _, change := range d.Variants[name] {
+				fmt.Fprintf(w, "    - %s\n", change)
+			}
+		}
+		fmt.Fprintln(w)
+
+		pkg, ok := packagesBySource[d.Name]
+		if !ok {
+			continue
+		}
+		variant := pkg.Spec.Variant
+		if variant == "" {
+			variant = "default"
+		}
+		changes, variantChanged := d.Variants[variant]
+		switch {
+		case d.Change == "removed" || (len(changes) == 1 && changes[0] == "variant removed"):
+			affected = append(affected, fmt.Sprintf("%s (variant %s will no longer exist)", pkg.Name, variant))
+		case variantChanged || len(d.Changes) > 0:
+			affected = append(affected, fmt.Sprintf("%s (variant %s)", pkg.Name, variant))
+		}
+	}
+
+	if len(affected) > 0 {
+		fmt.Fprintln(w, "Affected Packages:")
+		for _, a := range affected {
+			fmt.Fprintf(w, "  - %s\n", a)
+		}
+	}
+}
+
+// readPackageSourcesFromPaths reads the PackageSources defined in files or
+// directories, searched recursively for YAML files
+func readPackageSourcesFromPaths(paths []string) ([]cozyv1alpha1.PackageSource, error) {
+	var sources []cozyv1alpha1.PackageSource
+	seen := map[string]string{}
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml") {
+				return nil
+			}
+			found, err := readPackageSourcesFromYAMLFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			for _, ps := range found {
+				if previous, ok := seen[ps.Name]; ok {
+					return fmt.Errorf("PackageSource %s is defined in both %s and %s", ps.Name, previous, path)
+				}
+				seen[ps.Name] = path
+				sources = append(sources, ps)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
+
+// readPackageSourcesFromYAMLFile reads the PackageSources, including items of
+// PackageSourceLists, of a multi-document YAML file
+func readPackageSourcesFromYAMLFile(filePath string) ([]cozyv1alpha1.PackageSource, error) {
+	items, err := readObjectsFromYAMLFile(filePath, "PackageSource")
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]cozyv1alpha1.PackageSource, 0, len(items))
+	for _, item := range items {
+		var ps cozyv1alpha1.PackageSource
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item, &ps); err != nil {
+			return nil, fmt.Errorf("failed to convert PackageSource: %w", err)
+		}
+		sources = append(sources, ps)
+	}
+	return sources, nil
+}
+
+// readObjectsFromYAMLFile reads the objects of kind, including items of lists
+// of kind, of a multi-document YAML file. Other documents are skipped.
+func readObjectsFromYAMLFile(filePath, kind string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]interface{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := utilyaml.Unmarshal(doc, &obj.Object); err != nil || obj.Object == nil {
+			// Not a Kubernetes object, e.g. a values file
+			continue
+		}
+
+		switch obj.GetKind() {
+		case kind:
+			items = append(items, obj.Object)
+		case kind + "List":
+			list, _, _ := unstructured.NestedSlice(obj.Object, "items")
+			for _, item := range list {
+				if m, ok := item.(map[string]interface{}); ok {
+					items = append(items, m)
+				}
+			}
+		}
+	}
+	return items, nil
+}
+
+// describeSourceRef formats a PackageSource source reference
+func describeSourceRef(ref *cozyv1alpha1.PackageSourceRef) string {
+	if ref == nil {
+		return "<none>"
+	}
+	s := fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+	if ref.Path != "" {
+		s += " path " + ref.Path
+	}
+	return s
+}
+
+// describeChartRef formats a component chart reference
+func describeChartRef(ref *cozyv1alpha1.ComponentChartRef) string {
+	if ref == nil {
+		return "<none>"
+	}
+	if ref.Digest != "" {
+		return ref.URL + "@" + ref.Digest
+	}
+	return ref.URL + ":" + ref.Tag
+}
+
+// stringSetsEqual reports whether a and b contain the same strings, ignoring order
+func stringSetsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := append([]string(nil), a...)
+	bs := append([]string(nil), b...)
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatList formats a list of strings for display
+func formatList(items []string) string {
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+func init() {
+	rootCmd.AddCommand(sourceDiffCmd)
+	sourceDiffCmd.Flags().StringArrayVarP(&sourceDiffCmdFlags.files, "file", "f", []string{}, "File or directory with the new PackageSource manifests (can be specified multiple times)")
+	sourceDiffCmd.Flags().StringArrayVar(&sourceDiffCmdFlags.against, "against", []string{}, "File or directory with the PackageSource manifests to compare with (can be specified multiple times)")
+	sourceDiffCmd.Flags().BoolVar(&sourceDiffCmdFlags.againstCluster, "against-cluster", false, "Compare with the PackageSources installed in the cluster")
+	sourceDiffCmd.Flags().BoolVar(&sourceDiffCmdFlags.exitCode, "exit-code", false, "Exit with a non-zero status if PackageSources differ")
+	sourceDiffCmd.Flags().StringVar(&sourceDiffCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPackageSource(name string, variants ...cozyv1alpha1.Variant) cozyv1alpha1.PackageSource {
+	return cozyv1alpha1.PackageSource{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: cozyv1alpha1.PackageSourceSpec{
+			SourceRef: &cozyv1alpha1.PackageSourceRef{Kind: "OCIRepository", Namespace: "cozy-system", Name: "cozystack-packages"},
+			Variants:  variants,
+		},
+	}
+}
+
+func TestDiffPackageSources(t *testing.T) {
+	grafana := cozyv1alpha1.Component{
+		Name: "grafana",
+		Path: "system/grafana",
+		Install: &cozyv1alpha1.ComponentInstall{
+			Namespace: "cozy-monitoring",
+		},
+	}
+	moved := grafana
+	moved.Install = &cozyv1alpha1.ComponentInstall{Namespace: "cozy-grafana", DependsOn: []string{"victoria-metrics"}}
+	uninstalled := grafana
+	uninstalled.Install = nil
+
+	cases := []struct {
+		name  string
+		oldPS []cozyv1alpha1.PackageSource
+		newPS []cozyv1alpha1.PackageSource
+		want  []sourceDiff
+	}{
+		{
+			name:  "unchanged",
+			oldPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{Name: "default", Components: []cozyv1alpha1.Component{grafana}})},
+			newPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{Name: "default", Components: []cozyv1alpha1.Component{grafana}})},
+		},
+		{
+			name:  "added and removed sources",
+			oldPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.old", cozyv1alpha1.Variant{Name: "default"})},
+			newPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.new", cozyv1alpha1.Variant{Name: "default"}, cozyv1alpha1.Variant{Name: "ha"})},
+			want: []sourceDiff{
+				{Name: "cozystack.new", Change: "added", Variants: map[string][]string{"default": {"variant added"}, "ha": {"variant added"}}},
+				{Name: "cozystack.old", Change: "removed", Variants: map[string][]string{"default": {"variant removed"}}},
+			},
+		},
+		{
+			name:  "variants added and removed",
+			oldPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{Name: "default"}, cozyv1alpha1.Variant{Name: "ha"})},
+			newPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{Name: "default"}, cozyv1alpha1.Variant{Name: "minimal"})},
+			want: []sourceDiff{
+				{Name: "cozystack.monitoring", Change: "changed", Variants: map[string][]string{"ha": {"variant removed"}, "minimal": {"variant added"}}},
+			},
+		},
+		{
+			name: "components changed",
+			oldPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{
+				Name:       "default",
+				DependsOn:  []string{"cozystack.networking"},
+				Components: []cozyv1alpha1.Component{grafana, {Name: "alerta"}},
+			})},
+			newPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{
+				Name:       "default",
+				DependsOn:  []string{"cozystack.networking", "cozystack.storage"},
+				Components: []cozyv1alpha1.Component{moved, {Name: "vmagent"}},
+			})},
+			want: []sourceDiff{
+				{Name: "cozystack.monitoring", Change: "changed", Variants: map[string][]string{"default": {
+					"component alerta removed",
+					`component grafana: install.dependsOn [] -> [victoria-metrics]`,
+					`component grafana: install.namespace "cozy-monitoring" -> "cozy-grafana"`,
+					"component vmagent added",
+					"dependsOn: [cozystack.networking] -> [cozystack.networking, cozystack.storage]",
+				}}},
+			},
+		},
+		{
+			name:  "component no longer installed",
+			oldPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{Name: "default", Components: []cozyv1alpha1.Component{grafana}})},
+			newPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{Name: "default", Components: []cozyv1alpha1.Component{uninstalled}})},
+			want: []sourceDiff{
+				{Name: "cozystack.monitoring", Change: "changed", Variants: map[string][]string{"default": {"component grafana: no longer installed"}}},
+			},
+		},
+		{
+			name:  "dependency order ignored",
+			oldPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{Name: "default", DependsOn: []string{"a", "b"}})},
+			newPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring", cozyv1alpha1.Variant{Name: "default", DependsOn: []string{"b", "a"}})},
+		},
+		{
+			name:  "source moved",
+			oldPS: []cozyv1alpha1.PackageSource{testPackageSource("cozystack.monitoring")},
+			newPS: func() []cozyv1alpha1.PackageSource {
+				ps := testPackageSource("cozystack.monitoring")
+				ps.Spec.SourceRef = &cozyv1alpha1.PackageSourceRef{Kind: "GitRepository", Namespace: "cozy-system", Name: "cozystack", Path: "packages"}
+				return []cozyv1alpha1.PackageSource{ps}
+			}(),
+			want: []sourceDiff{
+				{
+					Name:     "cozystack.monitoring",
+					Change:   "changed",
+					Changes:  []string{"sourceRef: OCIRepository cozy-system/cozystack-packages -> GitRepository cozy-system/cozystack path packages"},
+					Variants: map[string][]string{},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diffPackageSources(tc.oldPS, tc.newPS)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("diffPackageSources =\n%#v\nwant\n%#v", got, tc.want)
+			}
+		})
+	}
+}
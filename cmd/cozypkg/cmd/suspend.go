@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var suspendCmdFlags struct {
+	kubeconfig string
+}
+
+var suspendCmd = &cobra.Command{
+	Use:   "suspend <package> [package...]",
+	Short: "Suspend the reconciliation of Packages",
+	Long: `Suspend Packages for a maintenance window without deleting them.
+
+The operator stops reconciling a suspended Package and suspends its
+HelmReleases, so that neither the Package spec nor the PackageSource is applied
+until the Package is resumed with cozypkg resume.`,
+	Example: `  cozypkg suspend cozystack.cilium
+  cozypkg suspend cozystack.cilium cozystack.kubeovn`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPackagesSuspend(args, true)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume <package> [package...]",
+	Short: "Resume the reconciliation of suspended Packages",
+	Long: `Resume Packages suspended with cozypkg suspend.
+
+The operator reconciles the Package again and resumes its HelmReleases.`,
+	Example: `  cozypkg resume cozystack.cilium`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPackagesSuspend(args, false)
+	},
+}
+
+// setPackagesSuspend sets spec.suspend of the Packages names to suspend
+func setPackagesSuspend(names []string, suspend bool) error {
+	ctx := context.Background()
+
+	// Create Kubernetes client config
+	var config *rest.Config
+	var err error
+
+	if suspendCmdFlags.kubeconfig != "" {
+		config, err = clientcmd.BuildConfigFromFlags("", suspendCmdFlags.kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to load kubeconfig from %s: %w", suspendCmdFlags.kubeconfig, err)
+		}
+	} else {
+		config, err = ctrl.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get kubeconfig: %w", err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+
+	k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create k8s client: %w", err)
+	}
+
+	action, state := "Resumed", "active"
+	if suspend {
+		action, state = "Suspended", "suspended"
+	}
+	for _, name := range names {
+		unchanged := false
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			pkg := &cozyv1alpha1.Package{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, pkg); err != nil {
+				return err
+			}
+			if pkg.Spec.Suspend == suspend {
+				unchanged = true
+				return nil
+			}
+			pkg.Spec.Suspend = suspend
+			return k8sClient.Update(ctx, pkg)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update Package %s: %w", name, err)
+		}
+		if unchanged {
+			fmt.Fprintf(os.Stderr, "✓ Package %s is already %s\n", name, state)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "✓ %s Package %s\n", action, name)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(suspendCmd)
+	rootCmd.AddCommand(resumeCmd)
+	for _, cmd := range []*cobra.Command{suspendCmd, resumeCmd} {
+		cmd.Flags().StringVar(&suspendCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// envTelemetryLog is the default for --telemetry-log
+	envTelemetryLog = "COZYPKG_TELEMETRY_LOG"
+	// envTelemetryEndpoint is the default for --telemetry-endpoint
+	envTelemetryEndpoint = "COZYPKG_TELEMETRY_ENDPOINT"
+
+	telemetrySendTimeout = 5 * time.Second
+)
+
+// Failure categories recorded for failed commands
+const (
+	failureUsage        = "usage"
+	failureKubeconfig   = "kubeconfig"
+	failureNotFound     = "not-found"
+	failureForbidden    = "forbidden"
+	failureConflict     = "conflict"
+	failureInvalid      = "invalid"
+	failureConnectivity = "connectivity"
+	failureOther        = "other"
+)
+
+var telemetryFlags struct {
+	log      string
+	endpoint string
+}
+
+// telemetryRecord describes the outcome of a single cozypkg invocation.
+// It deliberately carries no arguments, flag values or error messages,
+// which may contain package names or cluster details.
+type telemetryRecord struct {
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command"`
+	DurationMS int64     `json:"durationMs"`
+	Outcome    string    `json:"outcome"`
+	Failure    string    `json:"failure,omitempty"`
+}
+
+// recordTelemetry appends the outcome of a command to the telemetry log and
+// sends it to the telemetry endpoint, if either is configured.
+// Telemetry errors never affect the command result.
+func recordTelemetry(command string, started time.Time, cmdErr error) {
+	if telemetryFlags.log == "" && telemetryFlags.endpoint == "" {
+		return
+	}
+
+	rec := telemetryRecord{
+		Time:       started.UTC(),
+		Command:    command,
+		DurationMS: time.Since(started).Milliseconds(),
+		Outcome:    "success",
+	}
+	if cmdErr != nil {
+		rec.Outcome = "failure"
+		rec.Failure = failureCategory(cmdErr)
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+
+	if telemetryFlags.log != "" {
+		if f, err := os.OpenFile(telemetryFlags.log, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600); err == nil {
+			_, _ = f.Write(append(data, '\n'))
+			_ = f.Close()
+		}
+	}
+
+	if telemetryFlags.endpoint != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetrySendTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, telemetryFlags.endpoint, bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			_ = resp.Body.Close()
+		}
+	}
+}
+
+// failureCategory maps a command error to a coarse category
+func failureCategory(err error) string {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	switch {
+	case strings.Contains(err.Error(), "kubeconfig"):
+		return failureKubeconfig
+	case apierrors.IsNotFound(err):
+		return failureNotFound
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return failureForbidden
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		return failureConflict
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return failureInvalid
+	case errors.As(err, &opErr), errors.As(err, &dnsErr), apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), apierrors.IsServiceUnavailable(err):
+		return failureConnectivity
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "unknown command"),
+		strings.HasPrefix(msg, "unknown flag"),
+		strings.HasPrefix(msg, "unknown shorthand flag"),
+		strings.HasPrefix(msg, "flag needs an argument"),
+		strings.HasPrefix(msg, "invalid argument"),
+		strings.HasPrefix(msg, "accepts "),
+		strings.HasPrefix(msg, "requires at least"):
+		return failureUsage
+	case strings.Contains(msg, "not found"):
+		return failureNotFound
+	}
+	return failureOther
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&telemetryFlags.log, "telemetry-log", os.Getenv(envTelemetryLog),
+		"Opt in to recording command outcome, duration and failure category to this file (env "+envTelemetryLog+")")
+	rootCmd.PersistentFlags().StringVar(&telemetryFlags.endpoint, "telemetry-endpoint", os.Getenv(envTelemetryEndpoint),
+		"Opt in to sending command outcome, duration and failure category to this URL (env "+envTelemetryEndpoint+")")
+}
@@ -0,0 +1,440 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/cozypkg/plugin"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxTreeDepth bounds the nesting of HelmReleases deploying HelmReleases
+const maxTreeDepth = 8
+
+var treeCmdOptions plugin.Options
+
+var treeCmdFlags struct {
+	output string
+}
+
+var treeCmd = &cobra.Command{
+	Use:   "tree (<package> | <kind>/<name>)",
+	Short: "Show the objects of a Package or an application and their readiness",
+	Long: `Show the objects of a Package or an application and their readiness.
+
+The tree starts at a Package, or at an application given as <kind>/<name>,
+e.g. postgres/db. It lists the HelmReleases of the Package or the application,
+the objects deployed by each HelmRelease, as recorded in its Helm release, and
+the pods of the deployed workloads. HelmReleases deployed by a HelmRelease are
+expanded in turn.
+
+Every node shows its readiness: True, False or Unknown, or nothing for objects
+without a notion of readiness, such as ConfigMaps. Use -o json to get the tree
+as a JSON document.`,
+	Example: `  cozypkg tree cozystack.cilium
+  cozypkg tree postgres/db -n tenant-demo -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		if treeCmdFlags.output != "" && treeCmdFlags.output != "json" {
+			return fmt.Errorf("unsupported output format %q (supported: json)", treeCmdFlags.output)
+		}
+
+		k8sClient, err := treeCmdOptions.Client()
+		if err != nil {
+			return err
+		}
+
+		var root *treeNode
+		if strings.Contains(args[0], "/") {
+			namespace, err := treeCmdOptions.CurrentNamespace()
+			if err != nil {
+				return err
+			}
+			appRef, err := resolveApplicationRef(ctx, k8sClient, args[0])
+			if err != nil {
+				return err
+			}
+			root, err = applicationTree(ctx, k8sClient, namespace, appRef.Kind, appRef.Name)
+			if err != nil {
+				return err
+			}
+		} else {
+			root, err = packageTree(ctx, k8sClient, args[0])
+			if err != nil {
+				return err
+			}
+		}
+
+		if treeCmdFlags.output == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(root)
+		}
+		printTree(os.Stdout, root, "", "")
+		return nil
+	},
+}
+
+// treeNode is an object in the tree of a Package or an application
+type treeNode struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Ready is True, False or Unknown, or empty if the object has no readiness
+	Ready    string      `json:"ready,omitempty"`
+	Message  string      `json:"message,omitempty"`
+	Children []*treeNode `json:"children,omitempty"`
+}
+
+// packageTree returns the tree of the Package named name
+func packageTree(ctx context.Context, k8sClient client.Client, name string) (*treeNode, error) {
+	pkg := &cozyv1alpha1.Package{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, pkg); err != nil {
+		return nil, fmt.Errorf("failed to get Package %s: %w", name, err)
+	}
+	root := &treeNode{APIVersion: cozyv1alpha1.GroupVersion.String(), Kind: "Package", Name: pkg.Name}
+	root.Ready, root.Message = conditionReadiness(pkg.Status.Conditions)
+
+	var hrList helmv2.HelmReleaseList
+	if err := k8sClient.List(ctx, &hrList, client.MatchingLabels{"cozystack.io/package": pkg.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list HelmReleases: %w", err)
+	}
+	for i := range hrList.Items {
+		root.Children = append(root.Children, helmReleaseTree(ctx, k8sClient, &hrList.Items[i], 1))
+	}
+	return root, nil
+}
+
+// applicationTree returns the tree of the application kind/name in namespace
+func applicationTree(ctx context.Context, k8sClient client.Client, namespace, kind, name string) (*treeNode, error) {
+	app := &unstructured.Unstructured{}
+	app.SetAPIVersion(appsv1alpha1.SchemeGroupVersion.String())
+	app.SetKind(kind)
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, app); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", kind, namespace, name, err)
+	}
+	root := objectNode(app)
+
+	var hrList helmv2.HelmReleaseList
+	if err := k8sClient.List(ctx, &hrList, client.InNamespace(namespace), client.MatchingLabels{
+		appsv1alpha1.ApplicationKindLabel: kind,
+		appsv1alpha1.ApplicationNameLabel: name,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list HelmReleases: %w", err)
+	}
+	for i := range hrList.Items {
+		root.Children = append(root.Children, helmReleaseTree(ctx, k8sClient, &hrList.Items[i], 1))
+	}
+	return root, nil
+}
+
+// helmReleaseTree returns the tree of hr: the objects of its latest Helm
+// release, the pods of its workloads and the trees of its HelmReleases.
+// Objects that cannot be read are reported in the message of their node.
+func helmReleaseTree(ctx context.Context, k8sClient client.Client, hr *helmv2.HelmRelease, depth int) *treeNode {
+	node := &treeNode{APIVersion: helmv2.GroupVersion.String(), Kind: helmv2.HelmReleaseKind, Namespace: hr.Namespace, Name: hr.Name}
+	node.Ready, node.Message = conditionReadiness(hr.Status.Conditions)
+	if depth >= maxTreeDepth {
+		return node
+	}
+
+	objects, err := helmReleaseObjects(ctx, k8sClient, hr)
+	if err != nil {
+		node.Message = joinMessages(node.Message, fmt.Sprintf("failed to read the Helm release: %v", err))
+		return node
+	}
+	for _, obj := range objects {
+		node.Children = append(node.Children, deployedObjectTree(ctx, k8sClient, obj, depth))
+	}
+	return node
+}
+
+// deployedObjectTree returns the tree of an object deployed by a HelmRelease
+func deployedObjectTree(ctx context.Context, k8sClient client.Client, desired *unstructured.Unstructured, depth int) *treeNode {
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(desired.GroupVersionKind())
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(desired), live); err != nil {
+		node := objectNode(desired)
+		node.Ready = string(metav1.ConditionFalse)
+		if apierrors.IsNotFound(err) {
+			node.Message = "not found"
+		} else {
+			node.Ready = string(metav1.ConditionUnknown)
+			node.Message = err.Error()
+		}
+		return node
+	}
+
+	if live.GroupVersionKind().GroupKind() == helmv2.GroupVersion.WithKind(helmv2.HelmReleaseKind).GroupKind() {
+		hr := &helmv2.HelmRelease{}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(live), hr); err == nil {
+			return helmReleaseTree(ctx, k8sClient, hr, depth+1)
+		}
+	}
+
+	node := objectNode(live)
+	pods, err := workloadPods(ctx, k8sClient, live)
+	if err != nil {
+		node.Message = joinMessages(node.Message, fmt.Sprintf("failed to list pods: %v", err))
+	}
+	for i := range pods {
+		podNode := &treeNode{APIVersion: "v1", Kind: "Pod", Namespace: pods[i].Namespace, Name: pods[i].Name}
+		podNode.Ready, podNode.Message = podReadiness(&pods[i])
+		node.Children = append(node.Children, podNode)
+	}
+	return node
+}
+
+// objectNode returns the node of obj, without children
+func objectNode(obj *unstructured.Unstructured) *treeNode {
+	node := &treeNode{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	node.Ready, node.Message = objectReadiness(obj)
+	return node
+}
+
+// objectReadiness returns the readiness of obj from its Ready or Available
+// condition, or from its replica counts for workloads without conditions
+func objectReadiness(obj *unstructured.Unstructured) (string, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, conditionType := range []string{"Ready", "Available", "Complete"} {
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["type"] != conditionType {
+				continue
+			}
+			status, _ := condition["status"].(string)
+			message, _ := condition["message"].(string)
+			return status, message
+		}
+	}
+
+	switch obj.GetKind() {
+	case "StatefulSet", "ReplicaSet":
+		desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			desired = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		return replicaReadiness(ready, desired)
+	case "DaemonSet":
+		desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+		return replicaReadiness(ready, desired)
+	}
+	return "", ""
+}
+
+// replicaReadiness returns the readiness of a workload with ready of desired replicas
+func replicaReadiness(ready, desired int64) (string, string) {
+	message := fmt.Sprintf("%d/%d replicas ready", ready, desired)
+	if ready >= desired {
+		return string(metav1.ConditionTrue), message
+	}
+	return string(metav1.ConditionFalse), message
+}
+
+// conditionReadiness returns the status and message of the Ready condition
+func conditionReadiness(conditions []metav1.Condition) (string, string) {
+	if c := meta.FindStatusCondition(conditions, "Ready"); c != nil {
+		return string(c.Status), c.Message
+	}
+	return string(metav1.ConditionUnknown), ""
+}
+
+// podReadiness returns the readiness of pod from its Ready condition
+func podReadiness(pod *corev1.Pod) (string, string) {
+	if pod.Status.Phase == corev1.PodSucceeded {
+		return string(metav1.ConditionTrue), string(pod.Status.Phase)
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			message := c.Message
+			if message == "" {
+				message = string(pod.Status.Phase)
+			}
+			return string(c.Status), message
+		}
+	}
+	return string(metav1.ConditionUnknown), string(pod.Status.Phase)
+}
+
+// workloadPods returns the pods selected by the workload obj, if it is one
+func workloadPods(ctx context.Context, k8sClient client.Client, obj *unstructured.Unstructured) ([]corev1.Pod, error) {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+	default:
+		return nil, nil
+	}
+	selectorMap, found, err := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if err != nil || !found {
+		return nil, err
+	}
+	labelSelector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, labelSelector); err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	var podList corev1.PodList
+	if err := k8sClient.List(ctx, &podList, client.InNamespace(obj.GetNamespace()), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
+}
+
+// helmReleaseObjects returns the objects of the latest Helm release of hr,
+// read from the Helm storage Secret
+func helmReleaseObjects(ctx context.Context, k8sClient client.Client, hr *helmv2.HelmRelease) ([]*unstructured.Unstructured, error) {
+	latest := hr.Status.History.Latest()
+	if latest == nil {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{
+		Namespace: hr.GetStorageNamespace(),
+		Name:      fmt.Sprintf("sh.helm.release.v1.%s.v%d", latest.Name, latest.Version),
+	}
+	if err := k8sClient.Get(ctx, key, secret); err != nil {
+		return nil, err
+	}
+	manifest, err := decodeHelmReleaseManifest(secret.Data["release"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Secret %s/%s: %w", key.Namespace, key.Name, err)
+	}
+
+	var objects []*unstructured.Unstructured
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		obj := &unstructured.Unstructured{}
+		if err := utilyaml.Unmarshal(doc, &obj.Object); err != nil || len(obj.Object) == 0 || obj.GetKind() == "" {
+			continue
+		}
+		if obj.GetNamespace() == "" {
+			namespaced, err := k8sClient.IsObjectNamespaced(obj)
+			if err != nil || namespaced {
+				obj.SetNamespace(latest.Namespace)
+			}
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// decodeHelmReleaseManifest returns the manifest of a Helm release stored in a
+// Secret: base64 encoded, optionally gzipped JSON
+func decodeHelmReleaseManifest(data []byte) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return "", err
+	}
+	if bytes.HasPrefix(decoded, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return "", err
+		}
+	}
+	var release struct {
+		Manifest string `json:"manifest"`
+	}
+	if err := json.Unmarshal(decoded, &release); err != nil {
+		return "", err
+	}
+	return release.Manifest, nil
+}
+
+// joinMessages joins the non-empty messages
+func joinMessages(messages ...string) string {
+	var nonEmpty []string
+	for _, m := range messages {
+		if m != "" {
+			nonEmpty = append(nonEmpty, m)
+		}
+	}
+	return strings.Join(nonEmpty, "; ")
+}
+
+// printTree prints node and its children as an indented tree
+func printTree(w io.Writer, node *treeNode, prefix, childPrefix string) {
+	name := node.Name
+	if node.Namespace != "" {
+		name = node.Namespace + "/" + name
+	}
+	line := fmt.Sprintf("%s%s/%s", prefix, node.Kind, name)
+	switch node.Ready {
+	case string(metav1.ConditionTrue):
+		line += " ✓"
+	case string(metav1.ConditionFalse):
+		line += " ✗"
+	case string(metav1.ConditionUnknown):
+		line += " ?"
+	}
+	if node.Message != "" && node.Ready != string(metav1.ConditionTrue) {
+		line += " " + node.Message
+	}
+	fmt.Fprintln(w, line)
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			printTree(w, child, childPrefix+"└─ ", childPrefix+"   ")
+		} else {
+			printTree(w, child, childPrefix+"├─ ", childPrefix+"│  ")
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+	treeCmdOptions.AddFlags(treeCmd.Flags())
+	treeCmd.Flags().StringVarP(&treeCmdFlags.output, "output", "o", "", "Output format, json to print the tree as JSON")
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestObjectReadiness(t *testing.T) {
+	cases := []struct {
+		name        string
+		object      map[string]interface{}
+		wantStatus  string
+		wantMessage string
+	}{
+		{
+			name: "ready condition",
+			object: map[string]interface{}{
+				"kind": "Certificate",
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Issuing", "status": "True"},
+					map[string]interface{}{"type": "Ready", "status": "False", "message": "pending"},
+				}},
+			},
+			wantStatus:  "False",
+			wantMessage: "pending",
+		},
+		{
+			name: "available condition",
+			object: map[string]interface{}{
+				"kind": "Deployment",
+				"status": map[string]interface{}{"conditions": []interface{}{
+					map[string]interface{}{"type": "Available", "status": "True"},
+				}},
+			},
+			wantStatus: "True",
+		},
+		{
+			name: "statefulset replicas",
+			object: map[string]interface{}{
+				"kind":   "StatefulSet",
+				"spec":   map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{"readyReplicas": int64(2)},
+			},
+			wantStatus:  "False",
+			wantMessage: "2/3 replicas ready",
+		},
+		{
+			name: "statefulset with default replicas",
+			object: map[string]interface{}{
+				"kind":   "StatefulSet",
+				"status": map[string]interface{}{"readyReplicas": int64(1)},
+			},
+			wantStatus:  "True",
+			wantMessage: "1/1 replicas ready",
+		},
+		{
+			name: "daemonset",
+			object: map[string]interface{}{
+				"kind":   "DaemonSet",
+				"status": map[string]interface{}{"desiredNumberScheduled": int64(4), "numberReady": int64(4)},
+			},
+			wantStatus:  "True",
+			wantMessage: "4/4 replicas ready",
+		},
+		{
+			name:   "no readiness",
+			object: map[string]interface{}{"kind": "ConfigMap"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, message := objectReadiness(&unstructured.Unstructured{Object: tc.object})
+			if status != tc.wantStatus || message != tc.wantMessage {
+				t.Errorf("objectReadiness = %q, %q, want %q, %q", status, message, tc.wantStatus, tc.wantMessage)
+			}
+		})
+	}
+}
+
+// helmReleaseData encodes manifest like the release stored by Helm in a Secret
+func helmReleaseData(t *testing.T, manifest string, compress bool) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{"manifest": manifest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compress {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = buf.Bytes()
+	}
+	return []byte(base64.StdEncoding.EncodeToString(data))
+}
+
+func TestDecodeHelmReleaseManifest(t *testing.T) {
+	const manifest = "apiVersion: v1\nkind: ConfigMap\n"
+	cases := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "gzipped", data: helmReleaseData(t, manifest, true), want: manifest},
+		{name: "plain", data: helmReleaseData(t, manifest, false), want: manifest},
+		{name: "not base64", data: []byte("%%%"), wantErr: true},
+		{name: "not json", data: []byte(base64.StdEncoding.EncodeToString([]byte("manifest"))), wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeHelmReleaseManifest(tc.data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("decodeHelmReleaseManifest error = %v, want error %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("decodeHelmReleaseManifest = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPackageTree(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(helmv2.AddToScheme(scheme))
+
+	const manifest = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: grafana
+spec:
+  selector:
+    matchLabels:
+      app: grafana
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: grafana-dashboards
+`
+	pkg := &cozyv1alpha1.Package{
+		ObjectMeta: metav1.ObjectMeta{Name: "cozystack.monitoring"},
+		Status: cozyv1alpha1.PackageStatus{Conditions: []metav1.Condition{
+			{Type: "Ready", Status: metav1.ConditionFalse, Message: "waiting for grafana"},
+		}},
+	}
+	hr := &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "grafana",
+			Namespace: "cozy-monitoring",
+			Labels:    map[string]string{"cozystack.io/package": pkg.Name},
+		},
+		Status: helmv2.HelmReleaseStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+			History:    helmv2.Snapshots{{Name: "grafana", Namespace: "cozy-monitoring", Version: 2}},
+		},
+	}
+	releaseSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.grafana.v2", Namespace: "cozy-monitoring"},
+		Data:       map[string][]byte{"release": helmReleaseData(t, manifest, true)},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "cozy-monitoring"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana"}},
+		},
+		Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+		}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-abc", Namespace: "cozy-monitoring", Labels: map[string]string{"app": "grafana"}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse, Message: "containers not ready"}},
+		},
+	}
+	otherPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "alerta-abc", Namespace: "cozy-monitoring", Labels: map[string]string{"app": "alerta"}},
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(pkg, hr, releaseSecret, deployment, pod, otherPod).
+		WithStatusSubresource(pkg, hr, deployment, pod).
+		Build()
+
+	root, err := packageTree(context.TODO(), k8sClient, pkg.Name)
+	if err != nil {
+		t.Fatalf("packageTree: %v", err)
+	}
+	var out bytes.Buffer
+	printTree(&out, root, "", "")
+
+	want := `Package/cozystack.monitoring ✗ waiting for grafana
+└─ HelmRelease/cozy-monitoring/grafana ✓
+   ├─ Deployment/cozy-monitoring/grafana ✓
+   │  └─ Pod/cozy-monitoring/grafana-abc ✗ containers not ready
+   └─ ConfigMap/cozy-monitoring/grafana-dashboards ✗ not found
+`
+	if out.String() != want {
+		t.Errorf("tree =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var upgradeCmdFlags struct {
+	toVariant  string
+	dryRun     bool
+	kubeconfig string
+}
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [package]",
+	Short: "Migrate Packages to another variant",
+	Long: `Migrate Packages whose variant no longer exists in their PackageSource.
+
+Without arguments, all Packages are checked and those with a missing variant
+are migrated. With a package name, only that Package is checked; together with
+--to-variant it can also be moved to another variant while its current one
+still exists.
+
+The new variant is taken from --to-variant or selected interactively. Only
+spec.variant is changed: component overrides and ignored dependencies of the
+Package are kept.`,
+	Example: `  cozypkg upgrade
+  cozypkg upgrade cozystack.cilium --to-variant kubeovn`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		// Create Kubernetes client config
+		var config *rest.Config
+		var err error
+
+		if upgradeCmdFlags.kubeconfig != "" {
+			config, err = clientcmd.BuildConfigFromFlags("", upgradeCmdFlags.kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to load kubeconfig from %s: %w", upgradeCmdFlags.kubeconfig, err)
+			}
+		} else {
+			config, err = ctrl.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig: %w", err)
+			}
+		}
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+
+		k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("failed to create k8s client: %w", err)
+		}
+
+		var pkgs []cozyv1alpha1.Package
+		if len(args) == 1 {
+			pkg := cozyv1alpha1.Package{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Name: args[0]}, &pkg); err != nil {
+				return fmt.Errorf("failed to get Package %s: %w", args[0], err)
+			}
+			pkgs = append(pkgs, pkg)
+		} else {
+			if upgradeCmdFlags.toVariant != "" {
+				return fmt.Errorf("--to-variant requires a package name")
+			}
+			var pkgList cozyv1alpha1.PackageList
+			if err := k8sClient.List(ctx, &pkgList); err != nil {
+				return fmt.Errorf("failed to list Packages: %w", err)
+			}
+			pkgs = pkgList.Items
+		}
+
+		upgraded := 0
+		for i := range pkgs {
+			ok, err := upgradePackage(ctx, k8sClient, &pkgs[i], len(args) == 1)
+			if err != nil {
+				return err
+			}
+			if ok {
+				upgraded++
+			}
+		}
+		if upgraded == 0 {
+			fmt.Fprintln(os.Stderr, "✓ All Packages use existing variants, nothing to upgrade")
+		}
+		return nil
+	},
+}
+
+// upgradePackage moves pkg to a new variant if its variant is missing from its
+// PackageSource, or if explicit is set and --to-variant selects another one.
+// It reports whether the Package was (or, with --dry-run, would be) changed.
+func upgradePackage(ctx context.Context, k8sClient client.Client, pkg *cozyv1alpha1.Package, explicit bool) (bool, error) {
+	ps := &cozyv1alpha1.PackageSource{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: pkg.Name}, ps); err != nil {
+		return false, fmt.Errorf("failed to get PackageSource %s: %w", pkg.Name, err)
+	}
+
+	current := pkg.Spec.Variant
+	if current == "" {
+		current = "default"
+	}
+	exists := findVariant(ps, current) != nil
+
+	target := upgradeCmdFlags.toVariant
+	switch {
+	case target != "":
+		if findVariant(ps, target) == nil {
+			return false, fmt.Errorf("variant %s not found in PackageSource %s (available: %s)", target, ps.Name, strings.Join(variantNames(ps), ", "))
+		}
+		if target == current {
+			fmt.Fprintf(os.Stderr, "✓ %s already uses variant %s\n", pkg.Name, current)
+			return false, nil
+		}
+	case exists:
+		if explicit {
+			fmt.Fprintf(os.Stderr, "✓ %s uses variant %s, which still exists; use --to-variant to change it\n", pkg.Name, current)
+		}
+		return false, nil
+	default:
+		fmt.Fprintf(os.Stderr, "⚠ Variant %s of %s no longer exists in its PackageSource\n", current, pkg.Name)
+		var err error
+		if target, err = selectVariantInteractive(ps); err != nil {
+			return false, fmt.Errorf("failed to select variant for %s: %w", pkg.Name, err)
+		}
+	}
+
+	// Overrides of components missing from the new variant are kept, so they
+	// apply again if the component comes back, but have no effect meanwhile
+	if orphaned := orphanedComponentOverrides(pkg, findVariant(ps, target)); len(orphaned) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠ %s: variant %s has no components %s, their overrides are kept but have no effect\n",
+			pkg.Name, target, strings.Join(orphaned, ", "))
+	}
+
+	if upgradeCmdFlags.dryRun {
+		fmt.Fprintf(os.Stderr, "✓ %s would be moved from variant %s to %s (dry run)\n", pkg.Name, current, target)
+		return true, nil
+	}
+
+	patch := client.MergeFrom(pkg.DeepCopy())
+	pkg.Spec.Variant = target
+	if err := k8sClient.Patch(ctx, pkg, patch); err != nil {
+		return false, fmt.Errorf("failed to patch Package %s: %w", pkg.Name, err)
+	}
+	fmt.Fprintf(os.Stderr, "✓ %s moved from variant %s to %s\n", pkg.Name, current, target)
+	return true, nil
+}
+
+// findVariant returns the variant of ps with the given name, or nil if there is none
+func findVariant(ps *cozyv1alpha1.PackageSource, name string) *cozyv1alpha1.Variant {
+	for i := range ps.Spec.Variants {
+		if ps.Spec.Variants[i].Name == name {
+			return &ps.Spec.Variants[i]
+		}
+	}
+	return nil
+}
+
+// variantNames returns the names of the variants of ps
+func variantNames(ps *cozyv1alpha1.PackageSource) []string {
+	names := make([]string, 0, len(ps.Spec.Variants))
+	for _, variant := range ps.Spec.Variants {
+		names = append(names, variant.Name)
+	}
+	return names
+}
+
+// orphanedComponentOverrides returns the sorted names of the components
+// overridden by pkg that variant does not have
+func orphanedComponentOverrides(pkg *cozyv1alpha1.Package, variant *cozyv1alpha1.Variant) []string {
+	components := make(map[string]bool, len(variant.Components))
+	for _, component := range variant.Components {
+		components[component.Name] = true
+	}
+	var orphaned []string
+	for name := range pkg.Spec.Components {
+		if !components[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().StringVar(&upgradeCmdFlags.toVariant, "to-variant", "", "Variant to move the Package to instead of selecting it interactively")
+	upgradeCmd.Flags().BoolVar(&upgradeCmdFlags.dryRun, "dry-run", false, "Only report which Packages would be moved to which variant")
+	upgradeCmd.Flags().StringVar(&upgradeCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/sourceverify"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var verifyCmdFlags struct {
+	policy     string
+	kubeconfig string
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <package>...",
+	Short: "Verify signatures of the artifacts packages are installed from",
+	Long: `Verify that the OCI artifact each package is installed from is signed
+according to a verification policy. The Package must exist, its artifact is
+the one referenced by the PackageSource of the same name.
+
+Signatures and attestations are checked by Flux source-controller using the
+spec.verify section of the OCIRepository. This command checks that the configured
+verification is at least as strict as the policy and that the current artifact
+passed it.
+
+Without --policy, any configured verification that passed is accepted. A policy
+file may require a provider, a Secret with trusted keys, and allowed keyless
+signing identities:
+
+  provider: cosign
+  secretRef: cosign-public-keys
+  identities:
+  - issuer: ^https://token.actions.githubusercontent.com$
+    subject: ^https://github.com/cozystack/cozystack/.*$`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		policy := &sourceverify.Policy{}
+		if verifyCmdFlags.policy != "" {
+			var err error
+			policy, err = sourceverify.LoadPolicy(verifyCmdFlags.policy)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Create Kubernetes client config
+		var config *rest.Config
+		var err error
+
+		if verifyCmdFlags.kubeconfig != "" {
+			config, err = clientcmd.BuildConfigFromFlags("", verifyCmdFlags.kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to load kubeconfig from %s: %w", verifyCmdFlags.kubeconfig, err)
+			}
+		} else {
+			config, err = ctrl.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to get kubeconfig: %w", err)
+			}
+		}
+
+		scheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+		utilruntime.Must(sourcev1.AddToScheme(scheme))
+
+		k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("failed to create k8s client: %w", err)
+		}
+
+		failed := 0
+		for _, name := range args {
+			ps, err := packageSourceOf(ctx, k8sClient, name)
+			if err != nil {
+				return err
+			}
+			result, err := policy.Check(ctx, k8sClient, ps)
+			if err != nil {
+				return fmt.Errorf("failed to verify PackageSource %s: %w", name, err)
+			}
+			if result.Verified() {
+				fmt.Fprintf(os.Stderr, "✓ %s: %s verified (revision %s)\n", name, result.Source, result.Revision)
+				continue
+			}
+			failed++
+			fmt.Fprintf(os.Stderr, "⚠ %s: %s does not satisfy the verification policy:\n", name, result.Source)
+			for _, v := range result.Violations {
+				fmt.Fprintf(os.Stderr, "  - %s\n", v)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d package(s) failed verification", failed, len(args))
+		}
+		return nil
+	},
+}
+
+// packageSourceOf returns the PackageSource the Package named name is
+// installed from
+func packageSourceOf(ctx context.Context, k8sClient client.Client, name string) (*cozyv1alpha1.PackageSource, error) {
+	pkg := &cozyv1alpha1.Package{}
+	if err := k8sClient.Get(ctx, types.NamespacedName{Name: name}, pkg); err != nil {
+		return nil, fmt.Errorf("failed to get Package %s: %w", name, err)
+	}
+	// The operator installs a Package from the PackageSource of the same name
+	ps := &cozyv1alpha1.PackageSource{}
+	if err := k8sClient.Get(ctx, types.NamespacedName{Name: pkg.Name}, ps); err != nil {
+		return nil, fmt.Errorf("failed to get PackageSource %s of Package %s: %w", pkg.Name, name, err)
+	}
+	return ps, nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringVar(&verifyCmdFlags.policy, "policy", "", "Path to a verification policy file (YAML or JSON)")
+	verifyCmd.Flags().StringVar(&verifyCmdFlags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPackageSourceOf(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&cozyv1alpha1.Package{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.monitoring"}},
+		&cozyv1alpha1.PackageSource{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.monitoring"}},
+		// A PackageSource without a Package is not installed
+		&cozyv1alpha1.PackageSource{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.networking"}},
+		// A Package without a PackageSource can not be verified
+		&cozyv1alpha1.Package{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.storage"}},
+	).Build()
+
+	cases := []struct {
+		name    string
+		pkg     string
+		wantErr bool
+	}{
+		{name: "installed package", pkg: "cozystack.monitoring"},
+		{name: "package source without a package", pkg: "cozystack.networking", wantErr: true},
+		{name: "package without a package source", pkg: "cozystack.storage", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ps, err := packageSourceOf(context.TODO(), k8sClient, tc.pkg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("packageSourceOf error = %v, want error %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && ps.Name != tc.pkg {
+				t.Errorf("PackageSource = %s, want %s", ps.Name, tc.pkg)
+			}
+		})
+	}
+}
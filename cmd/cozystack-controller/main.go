@@ -19,7 +19,9 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -70,6 +72,8 @@ func main() {
 	var telemetryInterval string
 	var cozystackVersion string
 	var reconcileDeployment bool
+	var applicationShadows bool
+	var dashboardCatalog string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -91,6 +95,10 @@ func main() {
 		"Version of Cozystack")
 	flag.BoolVar(&reconcileDeployment, "reconcile-deployment", false,
 		"If set, the Cozystack API server is assumed to run as a Deployment, else as a DaemonSet.")
+	flag.StringVar(&dashboardCatalog, "dashboard-catalog", "cozy-dashboard/dashboard-catalog",
+		"The <namespace>/<name> of the ConfigMap the dashboard resource catalog is rendered into. Empty disables it.")
+	flag.BoolVar(&applicationShadows, "application-shadows", false,
+		"If set, an ApplicationShadow is kept for every application, so that other objects can be owned by it.")
 	opts := zap.Options{
 		Development: false,
 	}
@@ -104,6 +112,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	catalogNamespace, catalogName, ok := strings.Cut(dashboardCatalog, "/")
+	if dashboardCatalog != "" && (!ok || catalogNamespace == "" || catalogName == "") {
+		setupLog.Error(fmt.Errorf("must have the form <namespace>/<name>"), "invalid dashboard catalog", "value", dashboardCatalog)
+		os.Exit(1)
+	}
+
 	// Configure telemetry
 	telemetryConfig := telemetry.Config{
 		Disabled:         disableTelemetry,
@@ -221,6 +235,40 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controller.CozystackResourceDefinitionFlowSchemaReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CozystackResourceDefinitionFlowSchemaReconciler")
+		os.Exit(1)
+	}
+
+	if err = (&controller.CozystackResourceDefinitionPrefixMigrationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CozystackResourceDefinitionPrefixMigrationReconciler")
+		os.Exit(1)
+	}
+
+	if err = (&controller.TenantQuotaReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TenantQuotaReconciler")
+		os.Exit(1)
+	}
+
+	if applicationShadows {
+		if err = (&controller.ApplicationShadowReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ApplicationShadowReconciler")
+			os.Exit(1)
+		}
+	}
+
 	dashboardManager := &dashboard.Manager{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -230,6 +278,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	if dashboardCatalog != "" {
+		if err = (&dashboard.CatalogReconciler{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+			Namespace: catalogNamespace,
+			Name:      catalogName,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "DashboardCatalogReconciler")
+			os.Exit(1)
+		}
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
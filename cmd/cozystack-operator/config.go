@@ -0,0 +1,369 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/cozystack/cozystack/internal/operator"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+)
+
+const (
+	controllerPackageSource    = "packagesource"
+	controllerPackage          = "package"
+	controllerValuesReplicator = "cozyvaluesreplicator"
+
+	// configReloadInterval is how often the configuration file is checked for changes
+	configReloadInterval = 10 * time.Second
+
+	// defaultLogVerbosity is the default of --log-verbosity
+	defaultLogVerbosity = 1
+
+	// envShards and envShardIndex set --shards and --shard-index when the
+	// flags are unset
+	envShards     = "COZYSTACK_OPERATOR_SHARDS"
+	envShardIndex = "COZYSTACK_OPERATOR_SHARD_INDEX"
+	// envPodName is the name of the operator pod, whose StatefulSet ordinal is
+	// the shard index when no other is set
+	envPodName = "POD_NAME"
+)
+
+// allControllers are the controllers enabled by default
+var allControllers = []string{controllerPackageSource, controllerPackage, controllerValuesReplicator}
+
+// controllerLogNames are the names the controllers are logged with by
+// controller-runtime
+var controllerLogNames = map[string]string{
+	controllerPackageSource:    "cozystack-packagesource",
+	controllerPackage:          "cozystack-package",
+	controllerValuesReplicator: "cozystack-values-replicator",
+}
+
+// Config is the configuration file of the operator, set with --config.
+// Every setting mirrors a command line flag, flags given on the command line
+// take precedence over the file. Only the Package tunables and the log
+// verbosity are reloaded when the file changes, the rest requires a restart.
+type Config struct {
+	Metrics struct {
+		BindAddress string `json:"bindAddress,omitempty"`
+		Secure      *bool  `json:"secure,omitempty"`
+	} `json:"metrics,omitempty"`
+	HealthProbeBindAddress string `json:"healthProbeBindAddress,omitempty"`
+	LeaderElect            *bool  `json:"leaderElect,omitempty"`
+	EnableHTTP2            *bool  `json:"enableHTTP2,omitempty"`
+	EnableWebhooks         *bool  `json:"enableWebhooks,omitempty"`
+	InstallFlux            *bool  `json:"installFlux,omitempty"`
+	// Shards is the number of operator replicas sharing the Packages, the
+	// shard of each replica is set with --shard-index or the environment
+	Shards *int `json:"shards,omitempty"`
+	// Controllers are the controllers to run, all of them if empty
+	Controllers []string `json:"controllers,omitempty"`
+	// WatchNamespaces restricts the namespaced objects watched by the operator,
+	// all namespaces are watched if empty
+	WatchNamespaces []string         `json:"watchNamespaces,omitempty"`
+	ResyncPeriod    *metav1.Duration `json:"resyncPeriod,omitempty"`
+	PlatformSource  struct {
+		URL  string `json:"url,omitempty"`
+		Name string `json:"name,omitempty"`
+		Ref  string `json:"ref,omitempty"`
+	} `json:"platformSource,omitempty"`
+	CozyValues struct {
+		SecretName        string `json:"secretName,omitempty"`
+		SecretNamespace   string `json:"secretNamespace,omitempty"`
+		NamespaceSelector string `json:"namespaceSelector,omitempty"`
+	} `json:"cozyValues,omitempty"`
+	VerificationPolicy string `json:"verificationPolicy,omitempty"`
+	// Package holds the tunables of the Package controller
+	Package struct {
+		MaxConcurrentHelmReleases   *int `json:"maxConcurrentHelmReleases,omitempty"`
+		MaxHelmReleasesPerReconcile *int `json:"maxHelmReleasesPerReconcile,omitempty"`
+		RevisionHistoryLimit        *int `json:"revisionHistoryLimit,omitempty"`
+	} `json:"package,omitempty"`
+	// Logging holds the verbosity of the logs
+	Logging struct {
+		Verbosity *int `json:"verbosity,omitempty"`
+		// Controllers overrides the verbosity of the controllers named as in
+		// Controllers
+		Controllers map[string]int `json:"controllers,omitempty"`
+	} `json:"logging,omitempty"`
+}
+
+// loadConfig reads a Config from a YAML or JSON file
+func loadConfig(path string) (*Config, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg := &Config{}
+	if err := k8syaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, data, nil
+}
+
+// flagValues returns the settings of cfg as values of the flags they mirror.
+// Unset settings are omitted.
+func (cfg *Config) flagValues() map[string]string {
+	values := map[string]string{}
+	setString := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	setBool := func(name string, value *bool) {
+		if value != nil {
+			values[name] = strconv.FormatBool(*value)
+		}
+	}
+	setInt := func(name string, value *int) {
+		if value != nil {
+			values[name] = strconv.Itoa(*value)
+		}
+	}
+
+	setString("metrics-bind-address", cfg.Metrics.BindAddress)
+	setBool("metrics-secure", cfg.Metrics.Secure)
+	setString("health-probe-bind-address", cfg.HealthProbeBindAddress)
+	setBool("leader-elect", cfg.LeaderElect)
+	setBool("enable-http2", cfg.EnableHTTP2)
+	setBool("enable-webhooks", cfg.EnableWebhooks)
+	setBool("install-flux", cfg.InstallFlux)
+	setInt("shards", cfg.Shards)
+	setString("controllers", strings.Join(cfg.Controllers, ","))
+	setString("watch-namespaces", strings.Join(cfg.WatchNamespaces, ","))
+	if cfg.ResyncPeriod != nil {
+		values["resync-period"] = cfg.ResyncPeriod.Duration.String()
+	}
+	setString("platform-source-url", cfg.PlatformSource.URL)
+	setString("platform-source-name", cfg.PlatformSource.Name)
+	setString("platform-source-ref", cfg.PlatformSource.Ref)
+	setString("cozy-values-secret-name", cfg.CozyValues.SecretName)
+	setString("cozy-values-secret-namespace", cfg.CozyValues.SecretNamespace)
+	setString("cozy-values-namespace-selector", cfg.CozyValues.NamespaceSelector)
+	setString("verification-policy", cfg.VerificationPolicy)
+	setInt("max-concurrent-helmreleases", cfg.Package.MaxConcurrentHelmReleases)
+	setInt("max-helmreleases-per-reconcile", cfg.Package.MaxHelmReleasesPerReconcile)
+	setInt("revision-history-limit", cfg.Package.RevisionHistoryLimit)
+	setInt("log-verbosity", cfg.Logging.Verbosity)
+	setString("controller-log-verbosity", logging.FormatControllerLevels(cfg.Logging.Controllers))
+	return values
+}
+
+// applyConfig sets the flags of fs that were not given on the command line
+// from cfg. It returns the names of the flags given on the command line.
+func applyConfig(fs *flag.FlagSet, cfg *Config) (map[string]bool, error) {
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	for name, value := range cfg.flagValues() {
+		if explicit[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid config value for %s: %w", name, err)
+		}
+	}
+	return explicit, nil
+}
+
+// splitList splits a comma separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// resolveShard returns the shard of this replica from the --shards and
+// --shard-index flags, 0 and -1 when unset. Unset flags are read from the
+// environment, and the shard index defaults to the ordinal of the StatefulSet
+// pod the operator runs in.
+func resolveShard(shards, index int) (operator.Shard, error) {
+	if shards == 0 {
+		shards = 1
+		if value := os.Getenv(envShards); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return operator.Shard{}, fmt.Errorf("invalid %s: %w", envShards, err)
+			}
+			shards = n
+		}
+	}
+	if shards < 1 {
+		return operator.Shard{}, fmt.Errorf("the number of shards must be at least 1, got %d", shards)
+	}
+	if shards == 1 {
+		return operator.Shard{}, nil
+	}
+
+	if index < 0 {
+		value, name := os.Getenv(envShardIndex), envShardIndex
+		if value == "" {
+			// StatefulSet pods are named <statefulset>-<ordinal>
+			if pod := os.Getenv(envPodName); pod != "" {
+				value, name = pod[strings.LastIndex(pod, "-")+1:], envPodName
+			}
+		}
+		if value == "" {
+			return operator.Shard{}, fmt.Errorf("the shard of this replica is not set, use --shard-index, %s or %s", envShardIndex, envPodName)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return operator.Shard{}, fmt.Errorf("invalid shard index from %s: %w", name, err)
+		}
+		index = n
+	}
+	if index < 0 || index >= shards {
+		return operator.Shard{}, fmt.Errorf("shard index %d is out of range for %d shards", index, shards)
+	}
+	return operator.Shard{Index: index, Count: shards}, nil
+}
+
+// enabledControllers parses the --controllers flag
+func enabledControllers(value string) (map[string]bool, error) {
+	names := splitList(value)
+	if len(names) == 0 {
+		names = allControllers
+	}
+	enabled := map[string]bool{}
+	for _, name := range names {
+		known := false
+		for _, c := range allControllers {
+			known = known || c == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown controller %q (valid controllers: %s)", name, strings.Join(allControllers, ", "))
+		}
+		enabled[name] = true
+	}
+	return enabled, nil
+}
+
+// controllerLogLevels parses the --controller-log-verbosity flag into the
+// verbosity of the controllers by the names they are logged with
+func controllerLogLevels(value string) (map[string]int, error) {
+	levels, err := logging.ParseControllerLevels(value)
+	if err != nil {
+		return nil, err
+	}
+	named := make(map[string]int, len(levels))
+	for name, level := range levels {
+		logName, ok := controllerLogNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown controller %q (valid controllers: %s)", name, strings.Join(allControllers, ", "))
+		}
+		named[logName] = level
+	}
+	return named, nil
+}
+
+// configReloader reloads the tunable settings of the configuration file when it
+// changes. Tunables given on the command line are never overridden.
+type configReloader struct {
+	path     string
+	explicit map[string]bool
+	defaults operator.Tunables
+	packages *operator.PackageReconciler
+	data     []byte
+
+	// logVerbosity and controllerLogVerbosity are the log settings used when
+	// the file leaves them unset
+	logVerbosity           int
+	controllerLogVerbosity string
+	logLevels              *logging.Levels
+}
+
+// Start polls the configuration file until ctx is done
+func (c *configReloader) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("config")
+	ticker := time.NewTicker(configReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		cfg, data, err := loadConfig(c.path)
+		if err != nil {
+			logger.Error(err, "failed to reload config, keeping the current settings")
+			continue
+		}
+		if bytes.Equal(data, c.data) {
+			continue
+		}
+
+		verbosity, controllerVerbosity := c.logVerbosity, c.controllerLogVerbosity
+		if v := cfg.Logging.Verbosity; v != nil && !c.explicit["log-verbosity"] {
+			verbosity = *v
+		}
+		if v := cfg.Logging.Controllers; v != nil && !c.explicit["controller-log-verbosity"] {
+			controllerVerbosity = logging.FormatControllerLevels(v)
+		}
+		controllerLevels, err := controllerLogLevels(controllerVerbosity)
+		if err != nil {
+			logger.Error(err, "invalid controller log verbosity, keeping the current settings")
+			continue
+		}
+		c.data = data
+
+		c.logLevels.Set(verbosity, controllerLevels)
+		tunables := c.tunables(cfg)
+		if c.packages != nil {
+			c.packages.SetTunables(tunables)
+		}
+		logger.Info("reloaded config", "path", c.path,
+			"maxConcurrentHelmReleases", tunables.MaxConcurrentHelmReleases,
+			"maxHelmReleasesPerReconcile", tunables.MaxHelmReleasesPerReconcile,
+			"revisionHistoryLimit", tunables.RevisionHistoryLimit,
+			"logVerbosity", verbosity,
+			"controllerLogVerbosity", controllerVerbosity)
+	}
+}
+
+// tunables returns the Package tunables of cfg, keeping the values given on the
+// command line and falling back to the flag defaults for unset settings
+func (c *configReloader) tunables(cfg *Config) operator.Tunables {
+	t := c.defaults
+	if v := cfg.Package.MaxConcurrentHelmReleases; v != nil && !c.explicit["max-concurrent-helmreleases"] {
+		t.MaxConcurrentHelmReleases = *v
+	}
+	if v := cfg.Package.MaxHelmReleasesPerReconcile; v != nil && !c.explicit["max-helmreleases-per-reconcile"] {
+		t.MaxHelmReleasesPerReconcile = *v
+	}
+	if v := cfg.Package.RevisionHistoryLimit; v != nil && !c.explicit["revision-history-limit"] {
+		t.RevisionHistoryLimit = *v
+	}
+	return t
+}
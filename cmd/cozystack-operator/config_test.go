@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cozystack/cozystack/internal/operator"
+)
+
+func TestResolveShard(t *testing.T) {
+	cases := []struct {
+		name    string
+		shards  int
+		index   int
+		env     map[string]string
+		want    operator.Shard
+		wantErr string
+	}{
+		{
+			name:  "not sharded",
+			index: -1,
+			want:  operator.Shard{},
+		},
+		{
+			name:   "single shard",
+			shards: 1,
+			index:  -1,
+			env:    map[string]string{envPodName: "cozystack-operator-0"},
+			want:   operator.Shard{},
+		},
+		{
+			name:   "flags",
+			shards: 3,
+			index:  2,
+			want:   operator.Shard{Index: 2, Count: 3},
+		},
+		{
+			name:  "environment",
+			index: -1,
+			env:   map[string]string{envShards: "3", envShardIndex: "1"},
+			want:  operator.Shard{Index: 1, Count: 3},
+		},
+		{
+			name:   "statefulset ordinal",
+			shards: 3,
+			index:  -1,
+			env:    map[string]string{envPodName: "cozystack-operator-2"},
+			want:   operator.Shard{Index: 2, Count: 3},
+		},
+		{
+			name:   "shard index over pod name",
+			shards: 3,
+			index:  -1,
+			env:    map[string]string{envShardIndex: "0", envPodName: "cozystack-operator-2"},
+			want:   operator.Shard{Index: 0, Count: 3},
+		},
+		{
+			name:    "invalid shards",
+			index:   -1,
+			env:     map[string]string{envShards: "three"},
+			wantErr: "invalid " + envShards,
+		},
+		{
+			name:    "negative shards",
+			shards:  -2,
+			index:   -1,
+			wantErr: "must be at least 1",
+		},
+		{
+			name:    "missing index",
+			shards:  3,
+			index:   -1,
+			wantErr: "not set",
+		},
+		{
+			name:    "pod name without ordinal",
+			shards:  3,
+			index:   -1,
+			env:     map[string]string{envPodName: "cozystack-operator-5d8f7b9c4-x2x7k"},
+			wantErr: "invalid shard index from " + envPodName,
+		},
+		{
+			name:    "ordinal out of range",
+			shards:  3,
+			index:   -1,
+			env:     map[string]string{envPodName: "cozystack-operator-3"},
+			wantErr: "out of range",
+		},
+		{
+			name:    "index out of range",
+			shards:  3,
+			index:   5,
+			wantErr: "out of range",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range []string{envShards, envShardIndex, envPodName} {
+				t.Setenv(name, tc.env[name])
+			}
+			got, err := resolveShard(tc.shards, tc.index)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("resolveShard(%d, %d) error = %v, want %q", tc.shards, tc.index, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveShard(%d, %d) error = %v", tc.shards, tc.index, err)
+			}
+			if got != tc.want {
+				t.Errorf("resolveShard(%d, %d) = %+v, want %+v", tc.shards, tc.index, got, tc.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"math"
 	"os"
 	"strings"
 	"time"
@@ -32,6 +33,7 @@ import (
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sourcewatcherv1beta1 "github.com/fluxcd/source-watcher/api/v2/v1beta1"
+	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,6 +41,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -52,6 +55,8 @@ import (
 	"github.com/cozystack/cozystack/internal/cozyvaluesreplicator"
 	"github.com/cozystack/cozystack/internal/fluxinstall"
 	"github.com/cozystack/cozystack/internal/operator"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+	"github.com/cozystack/cozystack/internal/sourceverify"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -76,6 +81,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableWebhooks bool
 	var installFlux bool
 	var cozystackVersion string
 	var cozyValuesSecretName string
@@ -84,6 +90,20 @@ func main() {
 	var platformSourceURL string
 	var platformSourceName string
 	var platformSourceRef string
+	var maxConcurrentHelmReleases int
+	var maxHelmReleasesPerReconcile int
+	var revisionHistoryLimit int
+	var verificationPolicyPath string
+	var resyncPeriod time.Duration
+	var configPath string
+	var controllers string
+	var watchNamespaces string
+	var shards int
+	var shardIndex int
+	var logVerbosity int
+	var controllerLogVerbosity string
+	var logSamplingInitial int
+	var logSamplingThereafter int
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -94,6 +114,8 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the admission webhooks for Packages and PackageSources. Requires a serving certificate in the webhook certificate directory.")
 	flag.BoolVar(&installFlux, "install-flux", false, "Install Flux components before starting reconcile loop")
 	flag.StringVar(&cozystackVersion, "cozystack-version", "unknown",
 		"Version of Cozystack")
@@ -103,19 +125,117 @@ func main() {
 	flag.StringVar(&cozyValuesSecretName, "cozy-values-secret-name", "cozystack-values", "The name of the secret containing cluster-wide configuration values.")
 	flag.StringVar(&cozyValuesSecretNamespace, "cozy-values-secret-namespace", "cozy-system", "The namespace of the secret containing cluster-wide configuration values.")
 	flag.StringVar(&cozyValuesNamespaceSelector, "cozy-values-namespace-selector", "cozystack.io/system=true", "The label selector for namespaces where the cluster-wide configuration values must be replicated.")
+	flag.StringVar(&verificationPolicyPath, "verification-policy", "", "Path to a source verification policy file. If set, Packages are only installed from PackageSources whose OCI artifact satisfies the policy.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Hour, "How often all Packages and PackageSources are re-enqueued to repair drift, such as managed HelmReleases or ArtifactGenerators deleted while events were missed. 0 disables periodic resync.")
+	flag.IntVar(&maxConcurrentHelmReleases, "max-concurrent-helmreleases", operator.DefaultMaxConcurrentHelmReleases, "The maximum number of HelmReleases of a Package created or updated in parallel.")
+	flag.IntVar(&maxHelmReleasesPerReconcile, "max-helmreleases-per-reconcile", operator.DefaultMaxHelmReleasesPerReconcile, "The maximum number of HelmReleases of a Package applied in one reconciliation, the others are applied after a requeue.")
+	flag.IntVar(&revisionHistoryLimit, "revision-history-limit", operator.DefaultRevisionHistoryLimit, "The number of PackageRevisions kept per Package for rollbacks.")
+	flag.StringVar(&configPath, "config", "", "Path to a YAML configuration file. Flags given on the command line take precedence over the file. The package and logging settings are reloaded when the file changes.")
+	flag.StringVar(&controllers, "controllers", strings.Join(allControllers, ","), "Comma separated list of the controllers to run.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma separated list of the namespaces whose objects are watched. All namespaces are watched if empty.")
+	flag.IntVar(&shards, "shards", 0, "The number of operator replicas sharing the Packages and PackageSources, each reconciling those whose name hashes to its shard. Read from "+envShards+" if unset, 1 disables sharding.")
+	flag.IntVar(&shardIndex, "shard-index", -1, "The shard of this replica, from 0 to --shards - 1. Read from "+envShardIndex+" if unset, or from the StatefulSet ordinal in "+envPodName+".")
+
+	flag.IntVar(&logVerbosity, "log-verbosity", defaultLogVerbosity, "The verbosity of the logs, messages logged at a higher level are dropped. Reloaded when the config file changes.")
+	flag.StringVar(&controllerLogVerbosity, "controller-log-verbosity", "", "Comma separated list of controller=verbosity pairs overriding --log-verbosity for the given controllers (e.g. 'package=2'). Reloaded when the config file changes.")
+	flag.IntVar(&logSamplingInitial, "log-sampling-initial", 100, "The number of messages with the same level and text written every second before the rest are sampled. 0 disables sampling.")
+	flag.IntVar(&logSamplingThereafter, "log-sampling-thereafter", 100, "Once sampling, only every n-th message with the same level and text is written within the second.")
 
 	opts := zap.Options{
 		Development: true,
+		// Verbosity is filtered per controller by logLevels, an explicit
+		// --zap-log-level still caps it
+		Level: zapcore.Level(-math.MaxInt8),
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	if logSamplingInitial > 0 {
+		opts.ZapOpts = append(opts.ZapOpts, logging.Sampling(logSamplingInitial, logSamplingThereafter))
+	}
+	logLevels := logging.NewLevels(logVerbosity, nil)
+	ctrl.SetLogger(logging.WithLevels(zap.New(zap.UseFlagOptions(&opts)), logLevels))
 
-	config := ctrl.GetConfigOrDie()
+	var reloader *configReloader
+	if configPath != "" {
+		cfg, data, err := loadConfig(configPath)
+		if err != nil {
+			setupLog.Error(err, "unable to load config")
+			os.Exit(1)
+		}
+		explicit, err := applyConfig(flag.CommandLine, cfg)
+		if err != nil {
+			setupLog.Error(err, "unable to apply config")
+			os.Exit(1)
+		}
+		reloader = &configReloader{
+			path:     configPath,
+			explicit: explicit,
+			defaults: operator.Tunables{
+				MaxConcurrentHelmReleases:   operator.DefaultMaxConcurrentHelmReleases,
+				MaxHelmReleasesPerReconcile: operator.DefaultMaxHelmReleasesPerReconcile,
+				RevisionHistoryLimit:        operator.DefaultRevisionHistoryLimit,
+			},
+			data:         data,
+			logVerbosity: defaultLogVerbosity,
+			logLevels:    logLevels,
+		}
+		if explicit["max-concurrent-helmreleases"] {
+			reloader.defaults.MaxConcurrentHelmReleases = maxConcurrentHelmReleases
+		}
+		if explicit["max-helmreleases-per-reconcile"] {
+			reloader.defaults.MaxHelmReleasesPerReconcile = maxHelmReleasesPerReconcile
+		}
+		if explicit["revision-history-limit"] {
+			reloader.defaults.RevisionHistoryLimit = revisionHistoryLimit
+		}
+		if explicit["log-verbosity"] {
+			reloader.logVerbosity = logVerbosity
+		}
+		if explicit["controller-log-verbosity"] {
+			reloader.controllerLogVerbosity = controllerLogVerbosity
+		}
+	}
+
+	controllerLevels, err := controllerLogLevels(controllerLogVerbosity)
+	if err != nil {
+		setupLog.Error(err, "invalid controller log verbosity")
+		os.Exit(1)
+	}
+	logLevels.Set(logVerbosity, controllerLevels)
+
+	enabled, err := enabledControllers(controllers)
+	if err != nil {
+		setupLog.Error(err, "invalid controllers")
+		os.Exit(1)
+	}
+
+	shard, err := resolveShard(shards, shardIndex)
+	if err != nil {
+		setupLog.Error(err, "invalid sharding")
+		os.Exit(1)
+	}
+	// Every shard elects its own leader, the work that is not sharded runs in
+	// the first shard only
+	leaderElectionID := "cozystack-operator.cozystack.io"
+	firstShard := shard.Index == 0
+	if shard.Count > 1 {
+		leaderElectionID = fmt.Sprintf("cozystack-operator-shard-%d.cozystack.io", shard.Index)
+		setupLog.Info("Sharding reconciliation", "shard", shard.Index, "shards", shard.Count)
+	}
+
+	var defaultNamespaces map[string]cache.Config
+	if namespaces := splitList(watchNamespaces); len(namespaces) > 0 {
+		defaultNamespaces = make(map[string]cache.Config, len(namespaces))
+		for _, ns := range namespaces {
+			defaultNamespaces[ns] = cache.Config{}
+		}
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
 
 	// Create a direct client (without cache) for pre-start operations
-	directClient, err := client.New(config, client.Options{Scheme: scheme})
+	directClient, err := client.New(restConfig, client.Options{Scheme: scheme})
 	if err != nil {
 		setupLog.Error(err, "unable to create direct client")
 		os.Exit(1)
@@ -129,9 +249,11 @@ func main() {
 
 	// Start the controller manager
 	setupLog.Info("Starting controller manager")
-	mgr, err := ctrl.NewManager(config, ctrl.Options{
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
 		Cache: cache.Options{
+			SyncPeriod:        &resyncPeriod,
+			DefaultNamespaces: defaultNamespaces,
 			ByObject: map[client.Object]cache.ByObject{
 				// Cache only Secrets named <secretName> (in any namespace)
 				&corev1.Secret{}: {
@@ -153,7 +275,7 @@ func main() {
 		}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "cozystack-operator.cozystack.io",
+		LeaderElectionID:       leaderElectionID,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, setting this significantly speeds up voluntary
@@ -171,7 +293,7 @@ func main() {
 	}
 
 	// Install Flux before starting reconcile loop
-	if installFlux {
+	if installFlux && firstShard {
 		setupLog.Info("Installing Flux components before starting reconcile loop")
 		installCtx, installCancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer installCancel()
@@ -185,7 +307,7 @@ func main() {
 	}
 
 	// Generate and install platform source resource if specified
-	if platformSourceURL != "" {
+	if platformSourceURL != "" && firstShard {
 		setupLog.Info("Generating platform source resource", "url", platformSourceURL, "name", platformSourceName, "ref", platformSourceRef)
 		installCtx, installCancel := context.WithTimeout(context.Background(), 2*time.Minute)
 		defer installCancel()
@@ -200,33 +322,84 @@ func main() {
 	}
 
 	// Setup PackageSource reconciler
-	if err := (&operator.PackageSourceReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "PackageSource")
-		os.Exit(1)
+	if enabled[controllerPackageSource] {
+		if err := (&operator.PackageSourceReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("cozystack-packagesource-controller"),
+			Shard:    shard,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PackageSource")
+			os.Exit(1)
+		}
+	}
+
+	// Load source verification policy
+	var verificationPolicy *sourceverify.Policy
+	if verificationPolicyPath != "" {
+		verificationPolicy, err = sourceverify.LoadPolicy(verificationPolicyPath)
+		if err != nil {
+			setupLog.Error(err, "unable to load verification policy")
+			os.Exit(1)
+		}
 	}
 
 	// Setup Package reconciler
-	if err := (&operator.PackageReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Package")
-		os.Exit(1)
+	if enabled[controllerPackage] {
+		packageReconciler := &operator.PackageReconciler{
+			Client:                      mgr.GetClient(),
+			Scheme:                      mgr.GetScheme(),
+			Recorder:                    mgr.GetEventRecorderFor("cozystack-package-controller"),
+			MaxConcurrentHelmReleases:   maxConcurrentHelmReleases,
+			MaxHelmReleasesPerReconcile: maxHelmReleasesPerReconcile,
+			VerificationPolicy:          verificationPolicy,
+			RevisionHistoryLimit:        revisionHistoryLimit,
+			OperatorVersion:             cozystackVersion,
+			APIReader:                   mgr.GetAPIReader(),
+			Shard:                       shard,
+			Discovery:                   discovery.NewDiscoveryClientForConfigOrDie(mgr.GetConfig()),
+		}
+		if err := packageReconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Package")
+			os.Exit(1)
+		}
+
+		// Reload the Package tunables when the config file changes
+		if reloader != nil {
+			reloader.packages = packageReconciler
+		}
 	}
 
 	// Setup CozyValuesReplicator reconciler
-	if err := (&cozyvaluesreplicator.SecretReplicatorReconciler{
-		Client:                  mgr.GetClient(),
-		Scheme:                  mgr.GetScheme(),
-		SourceNamespace:         cozyValuesSecretNamespace,
-		SecretName:              cozyValuesSecretName,
-		TargetNamespaceSelector: targetNSSelector,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "CozyValuesReplicator")
-		os.Exit(1)
+	if enabled[controllerValuesReplicator] && firstShard {
+		if err := (&cozyvaluesreplicator.SecretReplicatorReconciler{
+			Client:                  mgr.GetClient(),
+			Scheme:                  mgr.GetScheme(),
+			Recorder:                mgr.GetEventRecorderFor("cozystack-values-replicator"),
+			SourceNamespace:         cozyValuesSecretNamespace,
+			SecretName:              cozyValuesSecretName,
+			TargetNamespaceSelector: targetNSSelector,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CozyValuesReplicator")
+			os.Exit(1)
+		}
+	}
+
+	// Reload the tunables and the log verbosity when the config file changes
+	if reloader != nil {
+		if err := mgr.Add(reloader); err != nil {
+			setupLog.Error(err, "unable to set up config reloader")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		if err := (&operator.PackageWebhook{
+			Client: mgr.GetClient(),
+		}).SetupWithManagerAsWebhook(mgr); err != nil {
+			setupLog.Error(err, "unable to setup webhook", "webhook", "Package")
+			os.Exit(1)
+		}
 	}
 
 	// +kubebuilder:scaffold:builder
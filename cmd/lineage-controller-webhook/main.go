@@ -25,6 +25,7 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -58,6 +59,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var tenantNamespaceLabels string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -69,6 +71,8 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&tenantNamespaceLabels, "tenant-namespace-labels", "",
+		"Comma-separated key=value labels set on every tenant namespace, e.g. the labels selecting namespaces for secret replication")
 	opts := zap.Options{
 		Development: false,
 	}
@@ -160,6 +164,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	extraLabels, err := labels.ConvertSelectorToLabelsMap(tenantNamespaceLabels)
+	if err != nil {
+		setupLog.Error(err, "invalid --tenant-namespace-labels")
+		os.Exit(1)
+	}
+	tenantNamespaceLabeler := &lcw.TenantNamespaceLabeler{ExtraLabels: extraLabels}
+	if err := tenantNamespaceLabeler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to setup webhook", "webhook", "TenantNamespaceLabeler")
+		os.Exit(1)
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,43 @@
+package backupcontroller
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+)
+
+// ensureApplicationOwner adds an ownerReference from p to the ApplicationShadow
+// of its application, so that the Plan is garbage collected together with the
+// application. Nothing is done if application shadows are not enabled.
+func (r *PlanReconciler) ensureApplicationOwner(ctx context.Context, p *backupsv1alpha1.Plan) error {
+	ref := p.Spec.ApplicationRef
+	if ref.APIGroup == nil || *ref.APIGroup != "apps.cozystack.io" {
+		return nil
+	}
+
+	shadow := &cozyv1alpha1.ApplicationShadow{}
+	key := client.ObjectKey{Namespace: p.Namespace, Name: cozyv1alpha1.ApplicationShadowName(ref.Kind, ref.Name)}
+	if err := r.Get(ctx, key, shadow); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	for _, owner := range p.OwnerReferences {
+		if owner.UID == shadow.UID {
+			return nil
+		}
+	}
+
+	patch := client.MergeFrom(p.DeepCopy())
+	if err := controllerutil.SetOwnerReference(shadow, p, r.Scheme); err != nil {
+		return err
+	}
+	return r.Patch(ctx, p, patch)
+}
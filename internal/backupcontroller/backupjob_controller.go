@@ -2,8 +2,11 @@ package backupcontroller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -14,6 +17,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
@@ -28,6 +32,14 @@ type BackupJobReconciler struct {
 	meta.RESTMapper
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// StorageResolver binds BackupJobs without spec.storageRef to a default storage.
+	// Without it, such BackupJobs are left until another controller records status.storageRef.
+	StorageResolver *StorageResolver
+	// MaxConcurrentReconciles is the number of BackupJobs reconciled in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
+
+	// hooks run the exec hooks of Job strategies
+	hooks *hookExecutor
 }
 
 func (r *BackupJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -50,24 +62,86 @@ func (r *BackupJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	if *j.Spec.StrategyRef.APIGroup != strategyv1alpha1.GroupVersion.Group {
+	strategy, err := resolveStrategy(ctx, r.Client, j.Namespace, j.Spec.StrategyRef)
+	if err != nil {
+		if j.Status.Phase == backupsv1alpha1.BackupJobPhaseSucceeded ||
+			j.Status.Phase == backupsv1alpha1.BackupJobPhaseFailed {
+			return ctrl.Result{}, nil
+		}
+		var status apierrors.APIStatus
+		if errors.As(err, &status) && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return r.markBackupJobFailed(ctx, j, fmt.Sprintf("failed to resolve strategy: %v", err))
+	}
+
+	if strategy.Ref.APIGroup == nil || *strategy.Ref.APIGroup != strategyv1alpha1.GroupVersion.Group {
 		logger.V(1).Info("BackupJob StrategyRef.APIGroup doesn't match, skipping",
 			"backupjob", j.Name,
 			"expected", strategyv1alpha1.GroupVersion.Group,
-			"got", *j.Spec.StrategyRef.APIGroup)
+			"got", strategy.Ref.APIGroup)
 		return ctrl.Result{}, nil
 	}
 
-	logger.Info("processing BackupJob", "backupjob", j.Name, "strategyKind", j.Spec.StrategyRef.Kind)
-	switch j.Spec.StrategyRef.Kind {
+	if j.Status.StorageRef == nil &&
+		j.Status.Phase != backupsv1alpha1.BackupJobPhaseSucceeded &&
+		j.Status.Phase != backupsv1alpha1.BackupJobPhaseFailed {
+		if j.Spec.StorageRef == nil && r.StorageResolver == nil {
+			logger.V(1).Info("BackupJob storage not resolved yet, skipping", "backupjob", j.Name)
+			return ctrl.Result{}, nil
+		}
+		storageRef, err := r.StorageResolver.Resolve(ctx, j.Namespace, j.Spec.StorageRef)
+		if err != nil {
+			var status apierrors.APIStatus
+			if errors.As(err, &status) {
+				return ctrl.Result{}, err
+			}
+			return r.markBackupJobFailed(ctx, j, err.Error())
+		}
+		j.Status.StorageRef = storageRef
+		if err := r.Status().Update(ctx, j); err != nil {
+			logger.Error(err, "failed to record BackupJob storage")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Runs wait for their storage before they start
+	if j.Status.StartedAt == nil && j.Status.StorageRef != nil &&
+		j.Status.Phase != backupsv1alpha1.BackupJobPhaseSucceeded &&
+		j.Status.Phase != backupsv1alpha1.BackupJobPhaseFailed {
+		notReady, err := storageNotReady(ctx, r.Client, j.Namespace, append([]corev1.TypedLocalObjectReference{*j.Status.StorageRef}, j.Spec.Copies...)...)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if notReady != "" {
+			logger.V(1).Info("BackupJob storage not ready, waiting", "backupjob", j.Name, "reason", notReady)
+			if j.Status.Phase != backupsv1alpha1.BackupJobPhasePending || j.Status.Message != notReady {
+				j.Status.Phase = backupsv1alpha1.BackupJobPhasePending
+				j.Status.Message = notReady
+				if err := r.Status().Update(ctx, j); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			return ctrl.Result{RequeueAfter: minRequeueDelay}, nil
+		}
+		if j.Status.Message != "" && j.Status.Phase == backupsv1alpha1.BackupJobPhasePending {
+			j.Status.Message = ""
+			if err := r.Status().Update(ctx, j); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	logger.Info("processing BackupJob", "backupjob", j.Name, "strategyKind", strategy.Ref.Kind)
+	switch strategy.Ref.Kind {
 	case strategyv1alpha1.JobStrategyKind:
-		return r.reconcileJob(ctx, j)
+		return r.reconcileJob(ctx, j, strategy)
 	case strategyv1alpha1.VeleroStrategyKind:
-		return r.reconcileVelero(ctx, j)
+		return r.reconcileVelero(ctx, j, strategy)
 	default:
 		logger.V(1).Info("BackupJob StrategyRef.Kind not supported, skipping",
 			"backupjob", j.Name,
-			"kind", j.Spec.StrategyRef.Kind,
+			"kind", strategy.Ref.Kind,
 			"supported", []string{strategyv1alpha1.JobStrategyKind, strategyv1alpha1.VeleroStrategyKind})
 		return ctrl.Result{}, nil
 	}
@@ -80,6 +154,9 @@ func (r *BackupJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if r.Interface, err = dynamic.NewForConfig(cfg); err != nil {
 		return err
 	}
+	if r.hooks, err = newHookExecutor(r.Client, cfg); err != nil {
+		return err
+	}
 	var h *http.Client
 	if h, err = rest.HTTPClientFor(cfg); err != nil {
 		return err
@@ -89,5 +166,6 @@ func (r *BackupJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&backupsv1alpha1.BackupJob{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
@@ -0,0 +1,58 @@
+package backupcontroller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// backupJobDuration records how long completed BackupJobs ran, for backup SLOs
+var backupJobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "cozystack_backupjob_duration_seconds",
+	Help:    "Time from start to completion of BackupJobs.",
+	Buckets: []float64{30, 60, 120, 300, 600, 1200, 1800, 3600, 7200, 14400, 28800},
+}, []string{"strategy_kind", "application_kind", "phase"})
+
+func init() {
+	metrics.Registry.MustRegister(backupJobDuration)
+}
+
+// markBackupJobStarted sets StartedAt on the first reconcile of a run.
+// It reports whether the status changed.
+func markBackupJobStarted(j *backupsv1alpha1.BackupJob) bool {
+	if j.Status.StartedAt != nil {
+		return false
+	}
+	now := metav1.Now().Rfc3339Copy()
+	j.Status.StartedAt = &now
+	return true
+}
+
+// setBackupJobCompleted sets the final phase of a run along with CompletedAt and Duration.
+// Runs that fail before they start get StartedAt set to the completion time.
+func setBackupJobCompleted(j *backupsv1alpha1.BackupJob, phase backupsv1alpha1.BackupJobPhase) {
+	now := metav1.Now().Rfc3339Copy()
+	if j.Status.StartedAt == nil {
+		j.Status.StartedAt = &now
+	}
+	j.Status.Phase = phase
+	j.Status.CompletedAt = &now
+	j.Status.Duration = &metav1.Duration{Duration: now.Sub(j.Status.StartedAt.Time).Round(time.Second)}
+}
+
+// observeBackupJobCompleted records the duration of a completed run.
+// It must be called once the completed status has been persisted.
+func observeBackupJobCompleted(j *backupsv1alpha1.BackupJob) {
+	if j.Status.Duration == nil {
+		return
+	}
+	backupJobDuration.WithLabelValues(
+		j.Spec.StrategyRef.Kind,
+		j.Spec.ApplicationRef.Kind,
+		string(j.Status.Phase),
+	).Observe(j.Status.Duration.Seconds())
+}
@@ -0,0 +1,50 @@
+package backupcontroller
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// expiredCopy identifies a copy of a Backup removed by retention.
+type expiredCopy struct {
+	Backup *backupsv1alpha1.Backup
+	Index  int
+}
+
+// expiredCopies returns the Ready copies in storageRef that exceed retention at now.
+// Copies are matched by Storage rather than by index, so that retention keeps
+// applying to a Storage after the copies of a Plan are reordered.
+func expiredCopies(backups []backupsv1alpha1.Backup, storageRef corev1.TypedLocalObjectReference, retention *backupsv1alpha1.RetentionPolicy, now time.Time) []expiredCopy {
+	if retention == nil {
+		return nil
+	}
+	var copies []expiredCopy
+	for i := range backups {
+		for j, c := range backups[i].Status.Copies {
+			if c.Phase == backupsv1alpha1.BackupPhaseReady && equality.Semantic.DeepEqual(c.StorageRef, storageRef) {
+				copies = append(copies, expiredCopy{Backup: &backups[i], Index: j})
+			}
+		}
+	}
+	// Newest first
+	sort.SliceStable(copies, func(i, j int) bool {
+		return copies[j].Backup.Spec.TakenAt.Before(&copies[i].Backup.Spec.TakenAt)
+	})
+
+	takenAt := make([]time.Time, len(copies))
+	for i, c := range copies {
+		takenAt[i] = c.Backup.Spec.TakenAt.Time
+	}
+	var expired []expiredCopy
+	for i, isExpired := range expiredByRetention(takenAt, retention, now) {
+		if isExpired {
+			expired = append(expired, copies[i])
+		}
+	}
+	return expired
+}
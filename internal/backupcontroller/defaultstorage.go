@@ -0,0 +1,74 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// defaultStorageAPIGroup is used for default storage references without an API group
+const defaultStorageAPIGroup = "apps.cozystack.io"
+
+// StorageResolver binds Plans and BackupJobs without spec.storageRef to a
+// default Storage: the one selected by the backups.cozystack.io/default-storage
+// annotation of their Namespace, or else the cluster-wide Default.
+type StorageResolver struct {
+	client.Reader
+	// Default is the cluster-wide default storage in the form
+	// "[<apiGroup>/]<Kind>/<name>", referring to a Storage object of that
+	// name in the namespace of the Plan or BackupJob. Optional.
+	Default string
+}
+
+// Resolve returns ref if it is set, otherwise the default storage for namespace.
+func (s *StorageResolver) Resolve(ctx context.Context, namespace string, ref *corev1.TypedLocalObjectReference) (*corev1.TypedLocalObjectReference, error) {
+	if ref != nil {
+		return ref.DeepCopy(), nil
+	}
+	if s == nil {
+		return nil, fmt.Errorf("storageRef is not set and default storage is not resolved by this controller")
+	}
+
+	ns := &corev1.Namespace{}
+	if err := s.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+	if value, ok := ns.Annotations[backupsv1alpha1.AnnotationDefaultStorage]; ok {
+		storageRef, err := parseStorageRef(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation on namespace %s: %w", backupsv1alpha1.AnnotationDefaultStorage, namespace, err)
+		}
+		return storageRef, nil
+	}
+
+	if s.Default != "" {
+		return parseStorageRef(s.Default)
+	}
+	return nil, fmt.Errorf("storageRef is not set and no default storage is configured for namespace %s", namespace)
+}
+
+// parseStorageRef parses a storage reference in the form "[<apiGroup>/]<Kind>/<name>"
+func parseStorageRef(value string) (*corev1.TypedLocalObjectReference, error) {
+	parts := strings.Split(value, "/")
+	group := defaultStorageAPIGroup
+	switch len(parts) {
+	case 2:
+	case 3:
+		group, parts = parts[0], parts[1:]
+	default:
+		return nil, fmt.Errorf("storage reference %q must have the form [<apiGroup>/]<Kind>/<name>", value)
+	}
+	if parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("storage reference %q must have the form [<apiGroup>/]<Kind>/<name>", value)
+	}
+	return &corev1.TypedLocalObjectReference{
+		APIGroup: &group,
+		Kind:     parts[0],
+		Name:     parts[1],
+	}, nil
+}
@@ -0,0 +1,61 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// resolvePlanStrategy returns the strategy of p: its spec.strategyRef, or else
+// the default strategy of the kind of its application set by a StrategyPolicy.
+func resolvePlanStrategy(ctx context.Context, c client.Reader, p *backupsv1alpha1.Plan) (*corev1.TypedLocalObjectReference, error) {
+	if p.Spec.StrategyRef != nil {
+		return p.Spec.StrategyRef.DeepCopy(), nil
+	}
+
+	appGroup := ""
+	if p.Spec.ApplicationRef.APIGroup != nil {
+		appGroup = *p.Spec.ApplicationRef.APIGroup
+	}
+	var policies backupsv1alpha1.StrategyPolicyList
+	if err := c.List(ctx, &policies); err != nil {
+		return nil, fmt.Errorf("failed to list StrategyPolicies: %w", err)
+	}
+
+	var strategyRef *corev1.TypedLocalObjectReference
+	var sources []string
+	for _, policy := range policies.Items {
+		for _, d := range policy.Spec.Defaults {
+			if d.APIGroup != appGroup || d.Kind != p.Spec.ApplicationRef.Kind {
+				continue
+			}
+			if strategyRef != nil && !sameTypedRef(*strategyRef, d.StrategyRef) {
+				return nil, fmt.Errorf("conflicting default strategies for %s in StrategyPolicies %s and %s",
+					p.Spec.ApplicationRef.Kind, strings.Join(sources, ", "), policy.Name)
+			}
+			strategyRef = d.StrategyRef.DeepCopy()
+			sources = append(sources, policy.Name)
+		}
+	}
+	if strategyRef == nil {
+		return nil, fmt.Errorf("strategyRef is not set and no StrategyPolicy sets a default strategy for %s", p.Spec.ApplicationRef.Kind)
+	}
+	return strategyRef, nil
+}
+
+// sameTypedRef reports whether a and b refer to the same object
+func sameTypedRef(a, b corev1.TypedLocalObjectReference) bool {
+	groupA, groupB := "", ""
+	if a.APIGroup != nil {
+		groupA = *a.APIGroup
+	}
+	if b.APIGroup != nil {
+		groupB = *b.APIGroup
+	}
+	return groupA == groupB && a.Kind == b.Kind && a.Name == b.Name
+}
@@ -10,6 +10,20 @@ import (
 )
 
 func BackupJob(p *backupsv1alpha1.Plan, scheduledFor time.Time) *backupsv1alpha1.BackupJob {
+	// Bind the job to the storage the Plan resolved, which may be a default storage,
+	storageRef := p.Spec.StorageRef
+	if p.Status.StorageRef != nil {
+		storageRef = p.Status.StorageRef
+	}
+	// and strategy, which may be a default strategy
+	strategyRef := p.Spec.StrategyRef
+	if p.Status.StrategyRef != nil {
+		strategyRef = p.Status.StrategyRef
+	}
+	var copies []corev1.TypedLocalObjectReference
+	for _, c := range p.Spec.Copies {
+		copies = append(copies, *c.StorageRef.DeepCopy())
+	}
 	job := &backupsv1alpha1.BackupJob{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("%s-%d", p.Name, scheduledFor.Unix()/60),
@@ -20,8 +34,9 @@ func BackupJob(p *backupsv1alpha1.Plan, scheduledFor time.Time) *backupsv1alpha1
 				Name: p.Name,
 			},
 			ApplicationRef: *p.Spec.ApplicationRef.DeepCopy(),
-			StorageRef:     *p.Spec.StorageRef.DeepCopy(),
-			StrategyRef:    *p.Spec.StrategyRef.DeepCopy(),
+			StorageRef:     storageRef.DeepCopy(),
+			StrategyRef:    *strategyRef.DeepCopy(),
+			Copies:         copies,
 		},
 	}
 	return job
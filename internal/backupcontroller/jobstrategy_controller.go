@@ -2,14 +2,312 @@ package backupcontroller
 
 import (
 	"context"
+	"fmt"
 
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
 	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	"github.com/cozystack/cozystack/internal/template"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
 )
 
-func (r *BackupJobReconciler) reconcileJob(ctx context.Context, j *backupsv1alpha1.BackupJob) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+// Conditions of a BackupJob run with the Job strategy
+const (
+	backupJobConditionPreHooks  = "PreHooksCompleted"
+	backupJobConditionPostHooks = "PostHooksCompleted"
+)
+
+// reconcileJob runs a BackupJob with the Job strategy: it runs the pre-hooks,
+// then the backup pod, then the post-hooks, and records the Backup once all
+// of them succeeded. Post-hooks run even if the backup pod failed, so that
+// what the pre-hooks quiesced is resumed.
+func (r *BackupJobReconciler) reconcileJob(ctx context.Context, j *backupsv1alpha1.BackupJob, strategy *resolvedStrategy) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+
+	if j.Status.Phase == backupsv1alpha1.BackupJobPhaseSucceeded ||
+		j.Status.Phase == backupsv1alpha1.BackupJobPhaseFailed {
+		return ctrl.Result{}, nil
+	}
+	if markBackupJobStarted(j) || j.Status.Phase != backupsv1alpha1.BackupJobPhaseRunning {
+		j.Status.Phase = backupsv1alpha1.BackupJobPhaseRunning
+		if err := r.Status().Update(ctx, j); err != nil {
+			logger.Error(err, "failed to update BackupJob status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	jobStrategy := &strategyv1alpha1.Job{}
+	if err := r.Get(ctx, client.ObjectKey{Name: strategy.Ref.Name}, jobStrategy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.markBackupJobFailed(ctx, j, fmt.Sprintf("Job strategy not found: %s", strategy.Ref.Name))
+		}
+		return ctrl.Result{}, err
+	}
+	templateContext, err := r.jobTemplateContext(ctx, j, strategy.Parameters)
+	if err != nil {
+		return r.markBackupJobFailed(ctx, j, fmt.Sprintf("failed to get application: %v", err))
+	}
+
+	// Step 1: pre-hooks, run once
+	if !meta.IsStatusConditionTrue(j.Status.Conditions, backupJobConditionPreHooks) {
+		for _, hook := range jobStrategy.Spec.PreHooks {
+			if hook.Exec == nil {
+				continue
+			}
+			if err := r.hooks.runExecHook(ctx, j.Namespace, *hook.Exec, templateContext); err != nil {
+				r.Recorder.Event(j, corev1.EventTypeWarning, "PreHookFailed", fmt.Sprintf("Pre-hook %s failed: %v", hook.Name, err))
+				return r.markBackupJobFailed(ctx, j, fmt.Sprintf("pre-hook %s failed: %v", hook.Name, err))
+			}
+			logger.Debug("ran pre-hook", "hook", hook.Name)
+		}
+		meta.SetStatusCondition(&j.Status.Conditions, metav1.Condition{
+			Type:    backupJobConditionPreHooks,
+			Status:  metav1.ConditionTrue,
+			Reason:  "HooksSucceeded",
+			Message: fmt.Sprintf("%d pre-hooks succeeded", len(jobStrategy.Spec.PreHooks)),
+		})
+		if err := r.Status().Update(ctx, j); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Step 2: the backup pod
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: j.Namespace, Name: backupPodJobName(j)}, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if job, err = r.backupPodJob(j, jobStrategy, templateContext); err != nil {
+			return r.markBackupJobFailed(ctx, j, fmt.Sprintf("failed to render the Job strategy template: %v", err))
+		}
+		if err := r.Create(ctx, job); err != nil {
+			return r.markBackupJobFailed(ctx, j, fmt.Sprintf("failed to create Job: %v", err))
+		}
+		r.Recorder.Event(j, corev1.EventTypeNormal, "JobCreated", fmt.Sprintf("Created Job %s", job.Name))
+		return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+	}
+	var jobFailure string
+	switch {
+	case jobConditionTrue(job, batchv1.JobComplete):
+	case jobConditionTrue(job, batchv1.JobFailed):
+		jobFailure = fmt.Sprintf("Job %s failed", job.Name)
+	default:
+		return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+	}
+
+	// Step 3: post-hooks, run once the pod has terminated
+	if !meta.IsStatusConditionTrue(j.Status.Conditions, backupJobConditionPostHooks) {
+		for _, hook := range jobStrategy.Spec.PostHooks {
+			if err := r.hooks.runExecHook(ctx, j.Namespace, hook.Exec, templateContext); err != nil {
+				r.Recorder.Event(j, corev1.EventTypeWarning, "PostHookFailed", fmt.Sprintf("Post-hook %s failed: %v", hook.Name, err))
+				message := fmt.Sprintf("post-hook %s failed: %v", hook.Name, err)
+				if jobFailure != "" {
+					message = jobFailure + "; " + message
+				}
+				return r.markBackupJobFailed(ctx, j, message)
+			}
+			logger.Debug("ran post-hook", "hook", hook.Name)
+		}
+		meta.SetStatusCondition(&j.Status.Conditions, metav1.Condition{
+			Type:    backupJobConditionPostHooks,
+			Status:  metav1.ConditionTrue,
+			Reason:  "HooksSucceeded",
+			Message: fmt.Sprintf("%d post-hooks succeeded", len(jobStrategy.Spec.PostHooks)),
+		})
+		if err := r.Status().Update(ctx, j); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if jobFailure != "" {
+		return r.markBackupJobFailed(ctx, j, jobFailure)
+	}
+
+	// Step 4: record the Backup
+	if j.Status.BackupRef == nil {
+		backup, err := r.createJobBackupResource(ctx, j, job, strategy.Ref)
+		if err != nil {
+			return r.markBackupJobFailed(ctx, j, fmt.Sprintf("failed to create Backup resource: %v", err))
+		}
+		j.Status.BackupRef = &corev1.LocalObjectReference{Name: backup.Name}
+	}
+	setBackupJobCompleted(j, backupsv1alpha1.BackupJobPhaseSucceeded)
+	if err := r.Status().Update(ctx, j); err != nil {
+		logger.Error(err, "failed to update BackupJob status")
+		return ctrl.Result{}, err
+	}
+	observeBackupJobCompleted(j)
+	logger.Debug("BackupJob succeeded", "backup", j.Status.BackupRef.Name)
 	return ctrl.Result{}, nil
 }
+
+// backupPodJobName is the name of the Job running the backup pod of j
+func backupPodJobName(j *backupsv1alpha1.BackupJob) string {
+	return "backup-" + j.Name
+}
+
+// backupPodJob renders the Job running the backup pod of j from the template
+// of the strategy, with the init container pre-hooks ahead of its own.
+func (r *BackupJobReconciler) backupPodJob(j *backupsv1alpha1.BackupJob, strategy *strategyv1alpha1.Job, templateContext map[string]any) (*batchv1.Job, error) {
+	podTemplate, err := template.Template(strategy.Spec.Template.DeepCopy(), templateContext)
+	if err != nil {
+		return nil, err
+	}
+	var initContainers []corev1.Container
+	for _, hook := range strategy.Spec.PreHooks {
+		if hook.InitContainer == nil {
+			continue
+		}
+		container, err := template.Template(hook.InitContainer.DeepCopy(), templateContext)
+		if err != nil {
+			return nil, fmt.Errorf("pre-hook %s: %w", hook.Name, err)
+		}
+		initContainers = append(initContainers, *container)
+	}
+	podTemplate.Spec.InitContainers = append(initContainers, podTemplate.Spec.InitContainers...)
+	if podTemplate.Spec.RestartPolicy == "" {
+		podTemplate.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupPodJobName(j),
+			Namespace: j.Namespace,
+			Labels: map[string]string{
+				backupsv1alpha1.OwningJobNameLabel:      j.Name,
+				backupsv1alpha1.OwningJobNamespaceLabel: j.Namespace,
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: backupsv1alpha1.GroupVersion.String(),
+				Kind:       "BackupJob",
+				Name:       j.Name,
+				UID:        j.UID,
+				Controller: boolPtr(true),
+			}},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template:     *podTemplate,
+		},
+	}, nil
+}
+
+// jobTemplateContext returns the context the templates of a Job strategy are
+// rendered with for j, see applicationTemplateContext
+func (r *BackupJobReconciler) jobTemplateContext(ctx context.Context, j *backupsv1alpha1.BackupJob, parameters map[string]any) (map[string]any, error) {
+	return applicationTemplateContext(ctx, r.Interface, r.RESTMapper, j.Namespace, j.Spec.ApplicationRef, parameters)
+}
+
+// applicationTemplateContext returns the context the templates of a Job
+// strategy are rendered with: the application ref in namespace as for other
+// strategies, with its values under .Values and its release under .Release.
+func applicationTemplateContext(ctx context.Context, dc dynamic.Interface, mapper meta.RESTMapper, namespace string, ref corev1.TypedLocalObjectReference, parameters map[string]any) (map[string]any, error) {
+	if ref.APIGroup == nil {
+		return nil, fmt.Errorf("application %s %s has no apiGroup", ref.Kind, ref.Name)
+	}
+	mapping, err := mapper.RESTMapping(schema.GroupKind{Group: *ref.APIGroup, Kind: ref.Kind})
+	if err != nil {
+		return nil, err
+	}
+	ns := namespace
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		ns = ""
+	}
+	app, err := dc.Resource(mapping.Resource).Namespace(ns).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	releaseName := app.GetName()
+	releases, err := dc.Resource(helmReleaseGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			appsv1alpha1.ApplicationKindLabel: app.GetKind(),
+			appsv1alpha1.ApplicationNameLabel: app.GetName(),
+		}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list HelmReleases of the application: %w", err)
+	}
+	if len(releases.Items) > 0 {
+		releaseName = releases.Items[0].GetName()
+	}
+
+	templateContext := strategyTemplateContext(app.Object, parameters)
+	values, _, _ := unstructured.NestedMap(app.Object, "spec")
+	templateContext["Values"] = values
+	templateContext["Release"] = map[string]any{
+		"Name":      releaseName,
+		"Namespace": namespace,
+	}
+	return templateContext, nil
+}
+
+// createJobBackupResource records the Backup produced by job for j
+func (r *BackupJobReconciler) createJobBackupResource(ctx context.Context, j *backupsv1alpha1.BackupJob, job *batchv1.Job, strategyRef corev1.TypedLocalObjectReference) (*backupsv1alpha1.Backup, error) {
+	takenAt := job.CreationTimestamp
+	if job.Status.StartTime != nil {
+		takenAt = *job.Status.StartTime
+	}
+	var storageRef corev1.TypedLocalObjectReference
+	if j.Status.StorageRef != nil {
+		storageRef = *j.Status.StorageRef
+	} else if j.Spec.StorageRef != nil {
+		storageRef = *j.Spec.StorageRef
+	}
+
+	backup := &backupsv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      j.Name,
+			Namespace: j.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: backupsv1alpha1.GroupVersion.String(),
+				Kind:       "BackupJob",
+				Name:       j.Name,
+				UID:        j.UID,
+				Controller: boolPtr(true),
+			}},
+		},
+		Spec: backupsv1alpha1.BackupSpec{
+			ApplicationRef: j.Spec.ApplicationRef,
+			PlanRef:        j.Spec.PlanRef,
+			StorageRef:     storageRef,
+			StrategyRef:    strategyRef,
+			TakenAt:        takenAt,
+			DriverMetadata: map[string]string{
+				"batch/job-name":      job.Name,
+				"batch/job-namespace": job.Namespace,
+			},
+		},
+		Status: backupsv1alpha1.BackupStatus{
+			Phase: backupsv1alpha1.BackupPhaseReady,
+			Artifact: &backupsv1alpha1.BackupArtifact{
+				URI: fmt.Sprintf("job://%s/%s", job.Namespace, job.Name),
+			},
+		},
+	}
+	if err := r.Create(ctx, backup); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// jobConditionTrue reports whether job has the condition conditionType set to True
+func jobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package backupcontroller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	"github.com/cozystack/cozystack/internal/template"
+)
+
+// defaultExecHookTimeout bounds exec hooks without a timeout
+const defaultExecHookTimeout = 30 * time.Second
+
+// hookExecutor runs the exec hooks of Job strategies
+type hookExecutor struct {
+	client.Client
+	restConfig *rest.Config
+	clientset  kubernetes.Interface
+}
+
+// newHookExecutor returns a hookExecutor listing pods with c and running
+// commands in them with cfg
+func newHookExecutor(c client.Client, cfg *rest.Config) (*hookExecutor, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &hookExecutor{Client: c, restConfig: cfg, clientset: clientset}, nil
+}
+
+// runExecHook runs the command of hook in every running pod it selects in
+// namespace. It fails if no pod is selected or the command fails in any pod.
+func (r *hookExecutor) runExecHook(ctx context.Context, namespace string, hook strategyv1alpha1.ExecHook, templateContext map[string]any) error {
+	rendered, err := template.Template(&hook, templateContext)
+	if err != nil {
+		return fmt.Errorf("failed to render hook: %w", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&rendered.PodSelector)
+	if err != nil {
+		return fmt.Errorf("invalid podSelector: %w", err)
+	}
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+	timeout := defaultExecHookTimeout
+	if rendered.Timeout != nil {
+		timeout = rendered.Timeout.Duration
+	}
+
+	ran := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		container := rendered.Container
+		if container == "" {
+			container = pod.Spec.Containers[0].Name
+		}
+		if err := r.execInPod(ctx, pod, container, rendered.Command, timeout); err != nil {
+			return fmt.Errorf("pod %s: %w", pod.Name, err)
+		}
+		ran++
+	}
+	if ran == 0 {
+		return fmt.Errorf("no running pods match %s", selector)
+	}
+	return nil
+}
+
+// execInPod runs command in container of pod, failing if it does not exit
+// with status 0 within timeout.
+func (r *hookExecutor) execInPod(ctx context.Context, pod *corev1.Pod, container string, command []string, timeout time.Duration) error {
+	req := r.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(r.restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	getLogger(ctx).Debug("ran hook command", "pod", pod.Name, "container", container, "output", stdout.String())
+	return nil
+}
@@ -0,0 +1,264 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	"github.com/cozystack/cozystack/internal/template"
+)
+
+// Conditions of a RestoreJob run with the Job strategy
+const (
+	restoreJobConditionRestored         = "Restored"
+	restoreJobConditionPostRestoreHooks = "PostRestoreHooksCompleted"
+	// restoreJobConditionPostRestoreHook is the prefix of the condition
+	// recording the outcome of each post-restore hook
+	restoreJobConditionPostRestoreHook = "PostRestoreHook."
+)
+
+// reconcileJobRestore runs a RestoreJob for a Backup taken with the Job
+// strategy: it runs the restore pod, then the post-restore hooks in order.
+// The outcome of each hook is recorded in a condition of the RestoreJob, so
+// that hooks which already ran, such as credential rotations, are not run
+// again while later hooks are in progress.
+func (r *RestoreJobReconciler) reconcileJobRestore(ctx context.Context, j *backupsv1alpha1.RestoreJob, backup *backupsv1alpha1.Backup) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+	logger.Debug("reconciling Job restore", "restorejob", j.Name, "phase", j.Status.Phase)
+
+	if j.Status.StartedAt == nil || j.Status.Phase != backupsv1alpha1.RestoreJobPhaseRunning {
+		if j.Status.StartedAt == nil {
+			now := metav1.Now()
+			j.Status.StartedAt = &now
+		}
+		j.Status.Phase = backupsv1alpha1.RestoreJobPhaseRunning
+		if err := r.Status().Update(ctx, j); err != nil {
+			logger.Error(err, "failed to update RestoreJob status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	jobStrategy := &strategyv1alpha1.Job{}
+	if err := r.Get(ctx, client.ObjectKey{Name: backup.Spec.StrategyRef.Name}, jobStrategy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.markRestoreJobFailed(ctx, j, fmt.Sprintf("Job strategy not found: %s", backup.Spec.StrategyRef.Name))
+		}
+		return ctrl.Result{}, err
+	}
+	if jobStrategy.Spec.RestoreTemplate == nil {
+		return r.markRestoreJobFailed(ctx, j, fmt.Sprintf("Job strategy %s has no restoreTemplate", jobStrategy.Name))
+	}
+	templateContext, err := r.restoreTemplateContext(ctx, j, backup)
+	if err != nil {
+		return r.markRestoreJobFailed(ctx, j, fmt.Sprintf("failed to get target application: %v", err))
+	}
+
+	// Step 1: the restore pod
+	if !meta.IsStatusConditionTrue(j.Status.Conditions, restoreJobConditionRestored) {
+		done, failure, err := r.runRestorePod(ctx, j, restorePodJobName(j), jobStrategy.Spec.RestoreTemplate, templateContext)
+		if err != nil {
+			return r.markRestoreJobFailed(ctx, j, fmt.Sprintf("failed to run the restore pod: %v", err))
+		}
+		if failure != "" {
+			return r.markRestoreJobFailed(ctx, j, failure)
+		}
+		if !done {
+			return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+		}
+		meta.SetStatusCondition(&j.Status.Conditions, metav1.Condition{
+			Type:    restoreJobConditionRestored,
+			Status:  metav1.ConditionTrue,
+			Reason:  "RestoreSucceeded",
+			Message: fmt.Sprintf("Job %s completed", restorePodJobName(j)),
+		})
+		if err := r.Status().Update(ctx, j); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Step 2: post-restore hooks, in order
+	for i := range jobStrategy.Spec.PostRestoreHooks {
+		hook := &jobStrategy.Spec.PostRestoreHooks[i]
+		conditionType := restoreJobConditionPostRestoreHook + hook.Name
+		if meta.IsStatusConditionTrue(j.Status.Conditions, conditionType) {
+			continue
+		}
+
+		var failure string
+		switch {
+		case hook.Exec != nil:
+			if err := r.hooks.runExecHook(ctx, j.Namespace, *hook.Exec, templateContext); err != nil {
+				failure = err.Error()
+			}
+		case hook.Template != nil:
+			done, podFailure, err := r.runRestorePod(ctx, j, postRestoreHookJobName(j, hook.Name), hook.Template, templateContext)
+			switch {
+			case err != nil:
+				failure = err.Error()
+			case podFailure != "":
+				failure = podFailure
+			case !done:
+				return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+			}
+		}
+		if failure != "" {
+			r.Recorder.Event(j, corev1.EventTypeWarning, "PostRestoreHookFailed", fmt.Sprintf("Post-restore hook %s failed: %s", hook.Name, failure))
+			meta.SetStatusCondition(&j.Status.Conditions, metav1.Condition{
+				Type:    conditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  "HookFailed",
+				Message: failure,
+			})
+			meta.SetStatusCondition(&j.Status.Conditions, metav1.Condition{
+				Type:    restoreJobConditionPostRestoreHooks,
+				Status:  metav1.ConditionFalse,
+				Reason:  "HookFailed",
+				Message: fmt.Sprintf("post-restore hook %s failed", hook.Name),
+			})
+			return r.markRestoreJobFailed(ctx, j, fmt.Sprintf("post-restore hook %s failed: %s", hook.Name, failure))
+		}
+
+		logger.Debug("ran post-restore hook", "hook", hook.Name)
+		meta.SetStatusCondition(&j.Status.Conditions, metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "HookSucceeded",
+			Message: fmt.Sprintf("Post-restore hook %s succeeded", hook.Name),
+		})
+		if err := r.Status().Update(ctx, j); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if len(jobStrategy.Spec.PostRestoreHooks) > 0 {
+		meta.SetStatusCondition(&j.Status.Conditions, metav1.Condition{
+			Type:    restoreJobConditionPostRestoreHooks,
+			Status:  metav1.ConditionTrue,
+			Reason:  "HooksSucceeded",
+			Message: fmt.Sprintf("%d post-restore hooks succeeded", len(jobStrategy.Spec.PostRestoreHooks)),
+		})
+	}
+
+	now := metav1.Now()
+	j.Status.CompletedAt = &now
+	j.Status.Phase = backupsv1alpha1.RestoreJobPhaseSucceeded
+	if err := r.Status().Update(ctx, j); err != nil {
+		logger.Error(err, "failed to update RestoreJob status")
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Event(j, corev1.EventTypeNormal, "RestoreSucceeded",
+		fmt.Sprintf("Restored Backup %s/%s", backup.Namespace, backup.Name))
+	return ctrl.Result{}, nil
+}
+
+// restorePodJobName is the name of the Job running the restore pod of j
+func restorePodJobName(j *backupsv1alpha1.RestoreJob) string {
+	return "restore-" + j.Name
+}
+
+// postRestoreHookJobName is the name of the Job running the pod of the
+// post-restore hook hookName of j
+func postRestoreHookJobName(j *backupsv1alpha1.RestoreJob, hookName string) string {
+	return restorePodJobName(j) + "-" + hookName
+}
+
+// runRestorePod runs the pod template of a Job strategy in a Job named name
+// owned by j, creating the Job if it does not exist. It reports whether the
+// pod succeeded, or why it failed.
+func (r *RestoreJobReconciler) runRestorePod(ctx context.Context, j *backupsv1alpha1.RestoreJob, name string, podTemplate *corev1.PodTemplateSpec, templateContext map[string]any) (bool, string, error) {
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: j.Namespace, Name: name}, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, "", err
+		}
+		if job, err = restorePodJob(j, name, podTemplate, templateContext); err != nil {
+			return false, "", fmt.Errorf("failed to render the template: %w", err)
+		}
+		if err := r.Create(ctx, job); err != nil {
+			return false, "", fmt.Errorf("failed to create Job: %w", err)
+		}
+		r.Recorder.Event(j, corev1.EventTypeNormal, "JobCreated", fmt.Sprintf("Created Job %s", job.Name))
+		return false, "", nil
+	}
+	switch {
+	case jobConditionTrue(job, batchv1.JobComplete):
+		return true, "", nil
+	case jobConditionTrue(job, batchv1.JobFailed):
+		return false, fmt.Sprintf("Job %s failed", job.Name), nil
+	}
+	return false, "", nil
+}
+
+// restorePodJob renders a Job named name running podTemplate for j
+func restorePodJob(j *backupsv1alpha1.RestoreJob, name string, podTemplate *corev1.PodTemplateSpec, templateContext map[string]any) (*batchv1.Job, error) {
+	rendered, err := template.Template(podTemplate.DeepCopy(), templateContext)
+	if err != nil {
+		return nil, err
+	}
+	if rendered.Spec.RestartPolicy == "" {
+		rendered.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: j.Namespace,
+			Labels: map[string]string{
+				backupsv1alpha1.OwningJobNameLabel:      j.Name,
+				backupsv1alpha1.OwningJobNamespaceLabel: j.Namespace,
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: backupsv1alpha1.GroupVersion.String(),
+				Kind:       "RestoreJob",
+				Name:       j.Name,
+				UID:        j.UID,
+				Controller: boolPtr(true),
+			}},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template:     *rendered,
+		},
+	}, nil
+}
+
+// restoreTemplateContext returns the context the restore templates of a Job
+// strategy are rendered with: the context of the target application as for
+// backups, with backup under .Backup.
+func (r *RestoreJobReconciler) restoreTemplateContext(ctx context.Context, j *backupsv1alpha1.RestoreJob, backup *backupsv1alpha1.Backup) (map[string]any, error) {
+	target := backup.Spec.ApplicationRef
+	if j.Spec.TargetApplicationRef != nil {
+		target = *j.Spec.TargetApplicationRef
+	}
+	templateContext, err := applicationTemplateContext(ctx, r.dynamic, r.mapper, j.Namespace, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var uri string
+	if backup.Status.Artifact != nil {
+		uri = backup.Status.Artifact.URI
+	}
+	driverMetadata := make(map[string]any, len(backup.Spec.DriverMetadata))
+	for k, v := range backup.Spec.DriverMetadata {
+		driverMetadata[k] = v
+	}
+	templateContext["Backup"] = map[string]any{
+		"Name":           backup.Name,
+		"Namespace":      backup.Namespace,
+		"URI":            uri,
+		"TakenAt":        backup.Spec.TakenAt.UTC().Format(time.RFC3339),
+		"DriverMetadata": driverMetadata,
+	}
+	return templateContext, nil
+}
@@ -0,0 +1,149 @@
+package backupcontroller
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// JobTTLReconciler deletes BackupJobs and RestoreJobs once they finished for
+// longer than spec.ttlSecondsAfterFinished. The Backups created by a BackupJob
+// are released first, so that they are not garbage collected with it.
+type JobTTLReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// DefaultTTL applies to jobs without spec.ttlSecondsAfterFinished.
+	// Zero keeps them.
+	DefaultTTL time.Duration
+}
+
+// ttlExpiry returns the time a job finished at finishedAt expires, and false
+// if it does not expire
+func (r *JobTTLReconciler) ttlExpiry(finishedAt *metav1.Time, ttlSeconds *int32) (time.Time, bool) {
+	if finishedAt == nil {
+		return time.Time{}, false
+	}
+	ttl := r.DefaultTTL
+	if ttlSeconds != nil {
+		ttl = time.Duration(*ttlSeconds) * time.Second
+	} else if ttl <= 0 {
+		return time.Time{}, false
+	}
+	return finishedAt.Add(ttl), true
+}
+
+func (r *JobTTLReconciler) reconcileBackupJob(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	j := &backupsv1alpha1.BackupJob{}
+	if err := r.Get(ctx, req.NamespacedName, j); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if j.DeletionTimestamp != nil ||
+		(j.Status.Phase != backupsv1alpha1.BackupJobPhaseSucceeded && j.Status.Phase != backupsv1alpha1.BackupJobPhaseFailed) {
+		return ctrl.Result{}, nil
+	}
+	expiry, ok := r.ttlExpiry(j.Status.CompletedAt, j.Spec.TTLSecondsAfterFinished)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+	if wait := time.Until(expiry); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	if err := r.releaseBackups(ctx, j); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.deleteExpired(ctx, j); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.FromContext(ctx).Info("deleted finished BackupJob after its TTL", "backupjob", j.Name, "phase", j.Status.Phase)
+	return ctrl.Result{}, nil
+}
+
+func (r *JobTTLReconciler) reconcileRestoreJob(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	j := &backupsv1alpha1.RestoreJob{}
+	if err := r.Get(ctx, req.NamespacedName, j); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if j.DeletionTimestamp != nil ||
+		(j.Status.Phase != backupsv1alpha1.RestoreJobPhaseSucceeded && j.Status.Phase != backupsv1alpha1.RestoreJobPhaseFailed) {
+		return ctrl.Result{}, nil
+	}
+	expiry, ok := r.ttlExpiry(j.Status.CompletedAt, j.Spec.TTLSecondsAfterFinished)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+	if wait := time.Until(expiry); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	if err := r.deleteExpired(ctx, j); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.FromContext(ctx).Info("deleted finished RestoreJob after its TTL", "restorejob", j.Name, "phase", j.Status.Phase)
+	return ctrl.Result{}, nil
+}
+
+// releaseBackups removes the owner reference to j from the Backups it created
+func (r *JobTTLReconciler) releaseBackups(ctx context.Context, j *backupsv1alpha1.BackupJob) error {
+	var list backupsv1alpha1.BackupList
+	if err := r.List(ctx, &list, client.InNamespace(j.Namespace)); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		b := &list.Items[i]
+		refs := withoutOwner(b.OwnerReferences, j.UID)
+		if len(refs) == len(b.OwnerReferences) {
+			continue
+		}
+		patch := client.MergeFrom(b.DeepCopy())
+		b.OwnerReferences = refs
+		if err := r.Patch(ctx, b, patch); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// withoutOwner returns refs without the references to the owner uid
+func withoutOwner(refs []metav1.OwnerReference, uid types.UID) []metav1.OwnerReference {
+	var out []metav1.OwnerReference
+	for _, ref := range refs {
+		if ref.UID != uid {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+// deleteExpired deletes the expired job obj together with the objects it owns
+func (r *JobTTLReconciler) deleteExpired(ctx context.Context, obj client.Object) error {
+	uid := obj.GetUID()
+	err := r.Delete(ctx, obj,
+		client.PropagationPolicy(metav1.DeletePropagationBackground),
+		client.Preconditions{UID: &uid})
+	return client.IgnoreNotFound(err)
+}
+
+// SetupWithManager registers our controllers with the Manager and sets up watches.
+func (r *JobTTLReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("backupjob-ttl").
+		For(&backupsv1alpha1.BackupJob{}).
+		Complete(reconcile.Func(r.reconcileBackupJob)); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("restorejob-ttl").
+		For(&backupsv1alpha1.RestoreJob{}).
+		Complete(reconcile.Func(r.reconcileRestoreJob))
+}
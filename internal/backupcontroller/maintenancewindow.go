@@ -0,0 +1,163 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+)
+
+const day = 24 * time.Hour
+
+// maintenanceWindow is a daily UTC time range, stored as offsets from midnight.
+// A window with end before start wraps around midnight.
+type maintenanceWindow struct {
+	start time.Duration
+	end   time.Duration
+}
+
+func (w maintenanceWindow) String() string {
+	format := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return format(w.start) + "-" + format(w.end)
+}
+
+// contains reports whether t falls into the window
+func (w maintenanceWindow) contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	if w.start <= w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// nextStart returns the first start of the window at or after t
+func (w maintenanceWindow) nextStart(t time.Time) time.Time {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	start := midnight.Add(w.start)
+	if start.Before(t) {
+		start = start.Add(day)
+	}
+	return start
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	t = t.UTC()
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// parseMaintenanceWindows parses a list of "HH:MM-HH:MM" ranges. Each element
+// may itself hold several comma-separated ranges.
+func parseMaintenanceWindows(values ...string) ([]maintenanceWindow, error) {
+	var windows []maintenanceWindow
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			from, to, ok := strings.Cut(part, "-")
+			if !ok {
+				return nil, fmt.Errorf("invalid maintenance window %q: expected HH:MM-HH:MM", part)
+			}
+			start, err := parseTimeOfDay(from)
+			if err != nil {
+				return nil, fmt.Errorf("invalid maintenance window %q: %w", part, err)
+			}
+			end, err := parseTimeOfDay(to)
+			if err != nil {
+				return nil, fmt.Errorf("invalid maintenance window %q: %w", part, err)
+			}
+			if start == end {
+				return nil, fmt.Errorf("invalid maintenance window %q: start equals end", part)
+			}
+			windows = append(windows, maintenanceWindow{start: start, end: end})
+		}
+	}
+	return windows, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// adjustToMaintenanceWindows moves t to the earliest time at or after t that lies
+// in one of the windows. It returns the window used and whether t was moved.
+func adjustToMaintenanceWindows(t time.Time, windows []maintenanceWindow) (time.Time, *maintenanceWindow, bool) {
+	if len(windows) == 0 {
+		return t, nil, false
+	}
+	var (
+		best   time.Time
+		chosen *maintenanceWindow
+	)
+	for i := range windows {
+		if windows[i].contains(t) {
+			return t, &windows[i], false
+		}
+		if start := windows[i].nextStart(t); chosen == nil || start.Before(best) {
+			best, chosen = start, &windows[i]
+		}
+	}
+	return best.In(t.Location()), chosen, true
+}
+
+// maintenanceWindowsFor returns the maintenance windows that apply to the
+// application referenced by the Plan. The application annotation takes
+// precedence over the default from its CozystackResourceDefinition.
+func (r *PlanReconciler) maintenanceWindowsFor(ctx context.Context, p *backupsv1alpha1.Plan) ([]maintenanceWindow, error) {
+	ref := p.Spec.ApplicationRef
+	if ref.APIGroup == nil || *ref.APIGroup == "" {
+		return nil, nil
+	}
+
+	mapping, err := r.RESTMapper().RESTMapping(schema.GroupKind{Group: *ref.APIGroup, Kind: ref.Kind})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	app := &unstructured.Unstructured{}
+	app.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: p.Namespace, Name: ref.Name}, app); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	} else if value, ok := app.GetAnnotations()[backupsv1alpha1.AnnotationMaintenanceWindow]; ok {
+		return parseMaintenanceWindows(value)
+	}
+
+	if *ref.APIGroup != "apps.cozystack.io" {
+		return nil, nil
+	}
+	crds := &cozyv1alpha1.CozystackResourceDefinitionList{}
+	if err := r.List(ctx, crds); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, crd := range crds.Items {
+		if crd.Spec.Application.Kind == ref.Kind && crd.Spec.Backup != nil {
+			return parseMaintenanceWindows(crd.Spec.Backup.MaintenanceWindows...)
+		}
+	}
+	return nil, nil
+}
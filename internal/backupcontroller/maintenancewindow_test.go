@@ -0,0 +1,235 @@
+package backupcontroller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+)
+
+func TestParseMaintenanceWindows(t *testing.T) {
+	cases := []struct {
+		name    string
+		values  []string
+		want    []maintenanceWindow
+		wantErr bool
+	}{
+		{
+			name:   "single window",
+			values: []string{"01:00-03:30"},
+			want:   []maintenanceWindow{{start: time.Hour, end: 3*time.Hour + 30*time.Minute}},
+		},
+		{
+			name:   "comma-separated and repeated values",
+			values: []string{" 01:00-02:00 , 22:00-23:00", "", "12:15-12:45"},
+			want: []maintenanceWindow{
+				{start: time.Hour, end: 2 * time.Hour},
+				{start: 22 * time.Hour, end: 23 * time.Hour},
+				{start: 12*time.Hour + 15*time.Minute, end: 12*time.Hour + 45*time.Minute},
+			},
+		},
+		{
+			name:   "past midnight",
+			values: []string{"23:00-02:00"},
+			want:   []maintenanceWindow{{start: 23 * time.Hour, end: 2 * time.Hour}},
+		},
+		{name: "missing end", values: []string{"01:00"}, wantErr: true},
+		{name: "invalid start", values: []string{"1am-03:00"}, wantErr: true},
+		{name: "invalid end", values: []string{"01:00-24:00"}, wantErr: true},
+		{name: "empty window", values: []string{"01:00-01:00"}, wantErr: true},
+		{name: "one invalid range", values: []string{"01:00-02:00,03:00"}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseMaintenanceWindows(tc.values...)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseMaintenanceWindows error = %v, want error %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseMaintenanceWindows = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAdjustToMaintenanceWindows(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.March, day, hour, minute, 0, 0, time.UTC)
+	}
+	windows, err := parseMaintenanceWindows("02:00-04:00", "23:00-01:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		t          time.Time
+		windows    []maintenanceWindow
+		want       time.Time
+		wantWindow string
+		wantMoved  bool
+	}{
+		{
+			name: "no windows",
+			t:    at(10, 12, 0),
+			want: at(10, 12, 0),
+		},
+		{
+			name:       "inside a window",
+			t:          at(10, 3, 0),
+			windows:    windows,
+			want:       at(10, 3, 0),
+			wantWindow: "02:00-04:00",
+		},
+		{
+			name:       "at the start of a window",
+			t:          at(10, 2, 0),
+			windows:    windows,
+			want:       at(10, 2, 0),
+			wantWindow: "02:00-04:00",
+		},
+		{
+			name:       "at the end of a window",
+			t:          at(10, 4, 0),
+			windows:    windows,
+			want:       at(10, 23, 0),
+			wantWindow: "23:00-01:00",
+			wantMoved:  true,
+		},
+		{
+			name:       "inside a window past midnight",
+			t:          at(10, 0, 30),
+			windows:    windows,
+			want:       at(10, 0, 30),
+			wantWindow: "23:00-01:00",
+		},
+		{
+			name:       "between windows",
+			t:          at(10, 1, 30),
+			windows:    windows,
+			want:       at(10, 2, 0),
+			wantWindow: "02:00-04:00",
+			wantMoved:  true,
+		},
+		{
+			name:       "pushed to the next day",
+			t:          at(10, 5, 0),
+			windows:    windows[:1],
+			want:       at(11, 2, 0),
+			wantWindow: "02:00-04:00",
+			wantMoved:  true,
+		},
+		{
+			name:       "local time",
+			t:          at(10, 12, 0).In(time.FixedZone("UTC+3", 3*60*60)),
+			windows:    windows,
+			want:       at(10, 23, 0).In(time.FixedZone("UTC+3", 3*60*60)),
+			wantWindow: "23:00-01:00",
+			wantMoved:  true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, window, moved := adjustToMaintenanceWindows(tc.t, tc.windows)
+			if !got.Equal(tc.want) || got.Location().String() != tc.t.Location().String() {
+				t.Errorf("adjusted to %v, want %v", got, tc.want)
+			}
+			var windowName string
+			if window != nil {
+				windowName = window.String()
+			}
+			if windowName != tc.wantWindow {
+				t.Errorf("window = %q, want %q", windowName, tc.wantWindow)
+			}
+			if moved != tc.wantMoved {
+				t.Errorf("moved = %v, want %v", moved, tc.wantMoved)
+			}
+		})
+	}
+}
+
+func TestNextInMaintenanceWindows(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.March, day, hour, minute, 0, 0, time.UTC)
+	}
+	hourly, err := cron.ParseStandard("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily, err := cron.ParseStandard("0 12 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	windows, err := parseMaintenanceWindows("22:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		schedule  cron.Schedule
+		tCheck    time.Time
+		wantSlot  time.Time
+		wantFire  time.Time
+		wantMoved bool
+	}{
+		{
+			name:     "slot inside the window",
+			schedule: hourly,
+			tCheck:   at(10, 22, 30),
+			wantSlot: at(10, 23, 0),
+			wantFire: at(10, 23, 0),
+		},
+		{
+			name:     "slot inside the window past midnight",
+			schedule: hourly,
+			tCheck:   at(10, 23, 30),
+			wantSlot: at(11, 0, 0),
+			wantFire: at(11, 0, 0),
+		},
+		{
+			name:      "slot pushed to the next window",
+			schedule:  daily,
+			tCheck:    at(10, 13, 0),
+			wantSlot:  at(10, 12, 0),
+			wantFire:  at(10, 22, 0),
+			wantMoved: true,
+		},
+		{
+			// The slots from 02:00 to 21:00 all fire at 22:00, the earliest is returned
+			name:      "slots outside the window share the next start",
+			schedule:  hourly,
+			tCheck:    at(10, 3, 0),
+			wantSlot:  at(10, 2, 0),
+			wantFire:  at(10, 22, 0),
+			wantMoved: true,
+		},
+		{
+			// The slot of the 10th fired at 22:00, the next one is the slot of the 11th
+			name:      "slot after a moved one fired",
+			schedule:  daily,
+			tCheck:    at(10, 22, 1),
+			wantSlot:  at(11, 12, 0),
+			wantFire:  at(11, 22, 0),
+			wantMoved: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			slot, fire, window, moved := nextInMaintenanceWindows(tc.schedule, windows, tc.tCheck)
+			if !slot.Equal(tc.wantSlot) {
+				t.Errorf("slot = %v, want %v", slot, tc.wantSlot)
+			}
+			if !fire.Equal(tc.wantFire) {
+				t.Errorf("fire = %v, want %v", fire, tc.wantFire)
+			}
+			if window == nil || window.String() != "22:00-02:00" {
+				t.Errorf("window = %v, want 22:00-02:00", window)
+			}
+			if moved != tc.wantMoved {
+				t.Errorf("moved = %v, want %v", moved, tc.wantMoved)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// applicationUID returns the UID of the application referenced by p, or an
+// empty UID if it does not exist or its kind is not served
+func (r *PlanReconciler) applicationUID(ctx context.Context, p *backupsv1alpha1.Plan) (types.UID, error) {
+	ref := p.Spec.ApplicationRef
+	if ref.APIGroup == nil || *ref.APIGroup == "" {
+		return "", nil
+	}
+	mapping, err := r.RESTMapper().RESTMapping(schema.GroupKind{Group: *ref.APIGroup, Kind: ref.Kind})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	app := &unstructured.Unstructured{}
+	app.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: p.Namespace, Name: ref.Name}, app); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return app.GetUID(), nil
+}
+
+// bindApplication records the UID of the application of p in its status and
+// detects the application being recreated under the same name. A recreated
+// application is adopted according to spec.onApplicationRecreated. It reports
+// whether p is suspended until the recreation is confirmed.
+func (r *PlanReconciler) bindApplication(ctx context.Context, p *backupsv1alpha1.Plan) (bool, error) {
+	uid, err := r.applicationUID(ctx, p)
+	if err != nil || uid == "" {
+		// Without an application there is nothing to bind to, BackupJobs report it
+		return false, err
+	}
+
+	switch {
+	case p.Status.ApplicationUID == "":
+		p.Status.ApplicationUID = uid
+		return false, r.Status().Update(ctx, p)
+	case p.Status.ApplicationUID == uid:
+		return false, nil
+	}
+
+	previous := p.Status.ApplicationUID
+	if p.Spec.OnApplicationRecreated == backupsv1alpha1.PlanRecreationConfirm &&
+		p.Annotations[backupsv1alpha1.AnnotationConfirmApplicationUID] != string(uid) {
+		if meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+			Type:   backupsv1alpha1.PlanConditionTargetRecreated,
+			Status: metav1.ConditionTrue,
+			Reason: "ConfirmationRequired",
+			Message: fmt.Sprintf("%s %s was recreated (UID %s, was %s), set the %s annotation to %s to resume backups",
+				p.Spec.ApplicationRef.Kind, p.Spec.ApplicationRef.Name, uid, previous,
+				backupsv1alpha1.AnnotationConfirmApplicationUID, uid),
+		}) {
+			if r.Recorder != nil {
+				r.Recorder.Eventf(p, corev1.EventTypeWarning, "TargetRecreated",
+					"%s %s was recreated, backups are suspended until the Plan is confirmed",
+					p.Spec.ApplicationRef.Kind, p.Spec.ApplicationRef.Name)
+			}
+			if err := r.Status().Update(ctx, p); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+
+	p.Status.ApplicationUID = uid
+	meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+		Type:   backupsv1alpha1.PlanConditionTargetRecreated,
+		Status: metav1.ConditionFalse,
+		Reason: "Adopted",
+		Message: fmt.Sprintf("Adopted %s %s recreated with UID %s (was %s)",
+			p.Spec.ApplicationRef.Kind, p.Spec.ApplicationRef.Name, uid, previous),
+	})
+	if r.Recorder != nil {
+		r.Recorder.Eventf(p, corev1.EventTypeNormal, "TargetAdopted",
+			"Bound to %s %s recreated with UID %s", p.Spec.ApplicationRef.Kind, p.Spec.ApplicationRef.Name, uid)
+	}
+	return false, r.Status().Update(ctx, p)
+}
@@ -6,28 +6,39 @@ import (
 	"time"
 
 	cron "github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
 	"github.com/cozystack/cozystack/internal/backupcontroller/factory"
 )
 
 const (
-	minRequeueDelay         = 30 * time.Second
+	minRequeueDelay = 30 * time.Second
+	// startingDeadlineSeconds is the default of spec.startingDeadlineSeconds
 	startingDeadlineSeconds = 300 * time.Second
 )
 
 // PlanReconciler reconciles a Plan object
 type PlanReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme          *runtime.Scheme
+	Recorder        record.EventRecorder
+	StorageResolver *StorageResolver
+	// MaxConcurrentReconciles is the number of Plans reconciled in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
 }
 
 func (r *PlanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -45,7 +56,19 @@ func (r *PlanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, err
 	}
 
-	tCheck := time.Now().Add(-startingDeadlineSeconds)
+	if err := r.ensureApplicationOwner(ctx, p); err != nil {
+		log.Error(err, "could not add ownerReference to the application shadow")
+	}
+
+	suspended, err := r.bindApplication(ctx, p)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if suspended {
+		log.V(1).Info("application was recreated, waiting for confirmation")
+		return ctrl.Result{}, nil
+	}
+
 	sch, err := cron.ParseStandard(p.Spec.Schedule.Cron)
 	if err != nil {
 		errWrapped := fmt.Errorf("could not parse cron %s: %w", p.Spec.Schedule.Cron, err)
@@ -62,6 +85,74 @@ func (r *PlanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, nil
 	}
 
+	storageRef, err := r.StorageResolver.Resolve(ctx, p.Namespace, p.Spec.StorageRef)
+	if err != nil {
+		log.Error(err, "could not resolve storage")
+		meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+			Type:    backupsv1alpha1.PlanConditionError,
+			Status:  metav1.ConditionTrue,
+			Reason:  "StorageNotResolved",
+			Message: err.Error(),
+		})
+		if err := r.Status().Update(ctx, p); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Namespaces are not watched, check the default storage again later
+		return ctrl.Result{RequeueAfter: minRequeueDelay}, nil
+	}
+	if !equality.Semantic.DeepEqual(p.Status.StorageRef, storageRef) {
+		p.Status.StorageRef = storageRef
+		if err := r.Status().Update(ctx, p); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	strategyRef, err := resolvePlanStrategy(ctx, r.Client, p)
+	if err != nil {
+		log.Error(err, "could not resolve strategy")
+		meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+			Type:    backupsv1alpha1.PlanConditionError,
+			Status:  metav1.ConditionTrue,
+			Reason:  "StrategyNotResolved",
+			Message: err.Error(),
+		})
+		if err := r.Status().Update(ctx, p); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Resolved again when a StrategyPolicy changes
+		return ctrl.Result{}, nil
+	}
+	if !equality.Semantic.DeepEqual(p.Status.StrategyRef, strategyRef) {
+		p.Status.StrategyRef = strategyRef
+		if err := r.Status().Update(ctx, p); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Do not start backups until the storage can be used
+	storageRefs := []corev1.TypedLocalObjectReference{*storageRef}
+	for _, c := range p.Spec.Copies {
+		storageRefs = append(storageRefs, c.StorageRef)
+	}
+	notReady, err := storageNotReady(ctx, r.Client, p.Namespace, storageRefs...)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if notReady != "" {
+		log.V(1).Info("storage not ready", "reason", notReady)
+		if meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+			Type:    backupsv1alpha1.PlanConditionError,
+			Status:  metav1.ConditionTrue,
+			Reason:  "StorageNotReady",
+			Message: notReady,
+		}) {
+			if err := r.Status().Update(ctx, p); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: minRequeueDelay}, nil
+	}
+
 	// Clear error condition if cron parsing succeeds
 	if condition := meta.FindStatusCondition(p.Status.Conditions, backupsv1alpha1.PlanConditionError); condition != nil && condition.Status == metav1.ConditionTrue {
 		meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
@@ -75,30 +166,204 @@ func (r *PlanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		}
 	}
 
-	tNext := sch.Next(tCheck)
+	windows, windowsErr := r.maintenanceWindowsFor(ctx, p)
+	if windowsErr != nil {
+		log.Error(windowsErr, "could not determine maintenance windows, scheduling without them")
+		windows = nil
+		if meta.SetStatusCondition(&p.Status.Conditions, metav1.Condition{
+			Type:    backupsv1alpha1.PlanConditionMaintenanceWindow,
+			Status:  metav1.ConditionFalse,
+			Reason:  "MaintenanceWindowUnavailable",
+			Message: windowsErr.Error(),
+		}) {
+			if err := r.Status().Update(ctx, p); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	jobs, err := r.planBackupJobs(ctx, p)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if lastSuccessful := lastSuccessfulTime(jobs); lastSuccessful != nil &&
+		(p.Status.LastSuccessfulTime == nil || p.Status.LastSuccessfulTime.Before(lastSuccessful)) {
+		p.Status.LastSuccessfulTime = lastSuccessful
+		if err := r.Status().Update(ctx, p); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	next := func(tCheck time.Time) (slot, fire time.Time, window *maintenanceWindow, moved bool) {
+		if len(windows) == 0 {
+			slot = sch.Next(tCheck)
+			return slot, slot, nil, false
+		}
+		return nextInMaintenanceWindows(sch, windows, tCheck)
+	}
+
+	// Backups scheduled for before the starting deadline are missed. Every
+	// time is scheduled at most once, so the search starts after the last one.
+	deadline := startingDeadlineSeconds
+	if p.Spec.StartingDeadlineSeconds != nil {
+		deadline = time.Duration(*p.Spec.StartingDeadlineSeconds) * time.Second
+	}
+	tCheck := time.Now().Add(-deadline)
+	if last := p.Status.LastScheduleTime; last != nil {
+		if tAfterLast := last.Add(time.Second); tAfterLast.After(tCheck) {
+			tCheck = tAfterLast
+		} else if _, tMissed, _, _ := next(tAfterLast); !tMissed.IsZero() && tMissed.Before(tCheck) && r.Recorder != nil {
+			r.Recorder.Eventf(p, corev1.EventTypeWarning, "MissedSchedule",
+				"Skipped backups scheduled since %s, they were not started within %s",
+				tMissed.UTC().Format(time.RFC3339), deadline)
+		}
+	}
+
+	tNext, tFire, window, moved := next(tCheck)
+	if tFire.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	if len(windows) > 0 {
+		condition := metav1.Condition{
+			Type:    backupsv1alpha1.PlanConditionMaintenanceWindow,
+			Status:  metav1.ConditionTrue,
+			Reason:  "InMaintenanceWindow",
+			Message: fmt.Sprintf("Backup scheduled for %s is within maintenance window %s UTC", tNext.UTC().Format(time.RFC3339), window),
+		}
+		if moved {
+			condition.Reason = "ScheduleAdjusted"
+			condition.Message = fmt.Sprintf("Backup scheduled for %s moved to %s to fit maintenance window %s UTC",
+				tNext.UTC().Format(time.RFC3339), tFire.UTC().Format(time.RFC3339), window)
+		}
+		if meta.SetStatusCondition(&p.Status.Conditions, condition) {
+			if err := r.Status().Update(ctx, p); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	} else if windowsErr == nil && meta.RemoveStatusCondition(&p.Status.Conditions, backupsv1alpha1.PlanConditionMaintenanceWindow) {
+		if err := r.Status().Update(ctx, p); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
-	if time.Now().Before(tNext) {
-		return ctrl.Result{RequeueAfter: tNext.Sub(time.Now())}, nil
+	if time.Now().Before(tFire) {
+		return ctrl.Result{RequeueAfter: tFire.Sub(time.Now())}, nil
 	}
 
-	job := factory.BackupJob(p, tNext)
+	job := factory.BackupJob(p, tFire)
 	if err := controllerutil.SetControllerReference(p, job, r.Scheme); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	if err := r.Create(ctx, job); err != nil {
-		if apierrors.IsAlreadyExists(err) {
-			return ctrl.Result{RequeueAfter: startingDeadlineSeconds}, nil
+	var active []*backupsv1alpha1.BackupJob
+	for i := range jobs {
+		if jobs[i].Name != job.Name && jobs[i].Status.Phase != backupsv1alpha1.BackupJobPhaseSucceeded &&
+			jobs[i].Status.Phase != backupsv1alpha1.BackupJobPhaseFailed {
+			active = append(active, &jobs[i])
+		}
+	}
+	if len(active) > 0 {
+		switch p.Spec.ConcurrencyPolicy {
+		case backupsv1alpha1.PlanConcurrencyForbid:
+			// Retried until the BackupJob completes or the starting deadline passes
+			log.V(1).Info("BackupJob still running, postponing", "backupjob", active[0].Name)
+			return ctrl.Result{RequeueAfter: minRequeueDelay}, nil
+		case backupsv1alpha1.PlanConcurrencyReplace:
+			for _, j := range active {
+				if err := r.Delete(ctx, j, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+					return ctrl.Result{}, err
+				}
+				if r.Recorder != nil {
+					r.Recorder.Eventf(p, corev1.EventTypeNormal, "BackupJobReplaced",
+						"Deleted running BackupJob %s in favor of %s", j.Name, job.Name)
+				}
+			}
 		}
+	}
+
+	if err := r.Create(ctx, job); err == nil {
+		if !tFire.Equal(tNext) && r.Recorder != nil {
+			r.Recorder.Eventf(p, corev1.EventTypeNormal, "ScheduleAdjusted",
+				"Created BackupJob %s at %s instead of %s to honor the maintenance window",
+				job.Name, tFire.UTC().Format(time.RFC3339), tNext.UTC().Format(time.RFC3339))
+		}
+	} else if !apierrors.IsAlreadyExists(err) {
 		return ctrl.Result{}, err
 	}
+	if p.Status.LastScheduleTime == nil || p.Status.LastScheduleTime.Time.Before(tFire) {
+		p.Status.LastScheduleTime = &metav1.Time{Time: tFire}
+		if err := r.Status().Update(ctx, p); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
 	return ctrl.Result{RequeueAfter: startingDeadlineSeconds}, nil
 }
 
+// planBackupJobs returns the BackupJobs created by p
+func (r *PlanReconciler) planBackupJobs(ctx context.Context, p *backupsv1alpha1.Plan) ([]backupsv1alpha1.BackupJob, error) {
+	var list backupsv1alpha1.BackupJobList
+	if err := r.List(ctx, &list, client.InNamespace(p.Namespace)); err != nil {
+		return nil, err
+	}
+	var jobs []backupsv1alpha1.BackupJob
+	for _, j := range list.Items {
+		if metav1.IsControlledBy(&j, p) {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs, nil
+}
+
+// lastSuccessfulTime returns the time the last of the succeeded jobs completed
+func lastSuccessfulTime(jobs []backupsv1alpha1.BackupJob) *metav1.Time {
+	var last *metav1.Time
+	for _, j := range jobs {
+		if j.Status.Phase == backupsv1alpha1.BackupJobPhaseSucceeded && j.Status.CompletedAt != nil &&
+			(last == nil || last.Before(j.Status.CompletedAt)) {
+			last = j.Status.CompletedAt.DeepCopy()
+		}
+	}
+	return last
+}
+
+// nextInMaintenanceWindows returns the earliest cron slot whose fire time,
+// adjusted into the maintenance windows, is not before tCheck. Adjustment never
+// delays a slot by more than a day, so slots are scanned starting one day back.
+// Several slots can be moved to the same fire time; they produce a single BackupJob.
+func nextInMaintenanceWindows(sch cron.Schedule, windows []maintenanceWindow, tCheck time.Time) (slot, fire time.Time, window *maintenanceWindow, moved bool) {
+	for slot = sch.Next(tCheck.Add(-day)); !slot.IsZero(); slot = sch.Next(slot) {
+		fire, window, moved = adjustToMaintenanceWindows(slot, windows)
+		if !fire.Before(tCheck) {
+			return slot, fire, window, moved
+		}
+	}
+	return time.Time{}, time.Time{}, nil, false
+}
+
+// plansWithDefaultStrategy returns requests for the Plans without
+// spec.strategyRef, whose strategy depends on the StrategyPolicies
+func (r *PlanReconciler) plansWithDefaultStrategy(ctx context.Context, _ client.Object) []reconcile.Request {
+	var plans backupsv1alpha1.PlanList
+	if err := r.List(ctx, &plans); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Plans")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, p := range plans.Items {
+		if p.Spec.StrategyRef == nil {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+		}
+	}
+	return requests
+}
+
 // SetupWithManager registers our controller with the Manager and sets up watches.
 func (r *PlanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&backupsv1alpha1.Plan{}).
+		Owns(&backupsv1alpha1.BackupJob{}).
+		Watches(&backupsv1alpha1.StrategyPolicy{}, handler.EnqueueRequestsFromMapFunc(r.plansWithDefaultStrategy)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
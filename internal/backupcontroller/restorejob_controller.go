@@ -0,0 +1,172 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// RestoreJobReconciler reconciles RestoreJob objects whose Backup was taken
+// with a strategy from strategy.backups.cozystack.io.
+type RestoreJobReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// MaxConcurrentReconciles is the number of RestoreJobs reconciled in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
+
+	// dynamic and mapper read the target applications of Job strategy
+	// restores, hooks run their exec hooks
+	dynamic dynamic.Interface
+	mapper  meta.RESTMapper
+	hooks   *hookExecutor
+}
+
+func (r *RestoreJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("reconciling RestoreJob", "namespace", req.Namespace, "name", req.Name)
+
+	j := &backupsv1alpha1.RestoreJob{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: req.Name}, j)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(1).Info("RestoreJob not found, skipping")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "failed to get RestoreJob")
+		return ctrl.Result{}, err
+	}
+
+	if j.Status.Phase == backupsv1alpha1.RestoreJobPhaseSucceeded ||
+		j.Status.Phase == backupsv1alpha1.RestoreJobPhaseFailed {
+		logger.V(1).Info("RestoreJob already completed, skipping", "phase", j.Status.Phase)
+		return ctrl.Result{}, nil
+	}
+
+	// The admission webhook authorizes cross-namespace restores; the spec is
+	// checked again here in case the webhook was bypassed.
+	if err := validateRestoreJobNamespaces(j); err != nil {
+		return r.markRestoreJobFailed(ctx, j, err.Error())
+	}
+
+	backup := &backupsv1alpha1.Backup{}
+	backupKey := types.NamespacedName{Namespace: restoreJobBackupNamespace(j), Name: j.Spec.BackupRef.Name}
+	if err := r.Get(ctx, backupKey, backup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.markRestoreJobFailed(ctx, j, fmt.Sprintf("Backup %s not found", backupKey))
+		}
+		logger.Error(err, "failed to get Backup", "backup", backupKey)
+		return ctrl.Result{}, err
+	}
+
+	strategyRef := backup.Spec.StrategyRef
+	if strategyRef.APIGroup == nil || *strategyRef.APIGroup != strategyv1alpha1.GroupVersion.Group {
+		logger.V(1).Info("Backup StrategyRef.APIGroup doesn't match, skipping",
+			"restorejob", j.Name,
+			"expected", strategyv1alpha1.GroupVersion.Group)
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("processing RestoreJob", "restorejob", j.Name, "strategyKind", strategyRef.Kind)
+	switch strategyRef.Kind {
+	case strategyv1alpha1.JobStrategyKind:
+		return r.reconcileJobRestore(ctx, j, backup)
+	case strategyv1alpha1.VeleroStrategyKind:
+		return r.reconcileVeleroRestore(ctx, j, backup)
+	default:
+		logger.V(1).Info("Backup StrategyRef.Kind not supported for restore, skipping",
+			"restorejob", j.Name,
+			"kind", strategyRef.Kind,
+			"supported", []string{strategyv1alpha1.JobStrategyKind, strategyv1alpha1.VeleroStrategyKind})
+		return ctrl.Result{}, nil
+	}
+}
+
+// restoreJobBackupNamespace returns the namespace of the Backup referenced by j.
+func restoreJobBackupNamespace(j *backupsv1alpha1.RestoreJob) string {
+	if j.Spec.BackupNamespace != "" {
+		return j.Spec.BackupNamespace
+	}
+	return j.Namespace
+}
+
+// validateRestoreJobNamespaces checks that a restore from another namespace
+// is explicitly mapped into the namespace of the RestoreJob.
+func validateRestoreJobNamespaces(j *backupsv1alpha1.RestoreJob) error {
+	for from, to := range j.Spec.NamespaceMapping {
+		if from == "" || to == "" {
+			return fmt.Errorf("spec.namespaceMapping must not contain empty namespaces")
+		}
+	}
+	source := restoreJobBackupNamespace(j)
+	if source == j.Namespace {
+		return nil
+	}
+	if target, ok := j.Spec.NamespaceMapping[source]; !ok || target != j.Namespace {
+		return fmt.Errorf("restoring a Backup from namespace %s requires spec.namespaceMapping to map %s to %s", source, source, j.Namespace)
+	}
+	return nil
+}
+
+func (r *RestoreJobReconciler) markRestoreJobFailed(ctx context.Context, restoreJob *backupsv1alpha1.RestoreJob, message string) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+	now := metav1.Now()
+	restoreJob.Status.CompletedAt = &now
+	restoreJob.Status.Phase = backupsv1alpha1.RestoreJobPhaseFailed
+	restoreJob.Status.Message = message
+
+	restoreJob.Status.Conditions = append(restoreJob.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionFalse,
+		Reason:             "RestoreFailed",
+		Message:            message,
+		LastTransitionTime: now,
+	})
+
+	if err := r.Status().Update(ctx, restoreJob); err != nil {
+		logger.Error(err, "failed to update RestoreJob status to Failed")
+		return ctrl.Result{}, err
+	}
+	logger.Debug("RestoreJob failed", "message", message)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager registers our controller with the Manager and sets up watches.
+func (r *RestoreJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	cfg := mgr.GetConfig()
+	var err error
+	if r.dynamic, err = dynamic.NewForConfig(cfg); err != nil {
+		return err
+	}
+	if r.hooks, err = newHookExecutor(r.Client, cfg); err != nil {
+		return err
+	}
+	var h *http.Client
+	if h, err = rest.HTTPClientFor(cfg); err != nil {
+		return err
+	}
+	if r.mapper, err = apiutil.NewDynamicRESTMapper(cfg, h); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupsv1alpha1.RestoreJob{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Complete(r)
+}
@@ -0,0 +1,136 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/validate-restorejob,mutating=false,failurePolicy=Fail,sideEffects=None,groups=backups.cozystack.io,resources=restorejobs,verbs=create;update,versions=v1alpha1,name=vrestorejob.backups.cozystack.io,admissionReviewVersions={v1}
+
+// RestoreJobValidator authorizes cross-namespace restores. The requesting
+// user must be able to read Backups in the source namespace and to create
+// RestoreJobs in every namespace the restore writes into.
+type RestoreJobValidator struct {
+	client.Client
+	decoder admission.Decoder
+}
+
+// SetupWithManagerAsWebhook registers the handler with the webhook server.
+func (v *RestoreJobValidator) SetupWithManagerAsWebhook(mgr ctrl.Manager) error {
+	v.decoder = admission.NewDecoder(mgr.GetScheme())
+	mgr.GetWebhookServer().Register("/validate-restorejob", &admission.Webhook{Handler: v})
+	return nil
+}
+
+// Handle is called for each AdmissionReview that matches the webhook config.
+func (v *RestoreJobValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("namespace", req.Namespace, "name", req.Name, "operation", req.Operation)
+
+	j := &backupsv1alpha1.RestoreJob{}
+	if err := v.decoder.Decode(req, j); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("decode object: %w", err))
+	}
+	if j.Namespace == "" {
+		j.Namespace = req.Namespace
+	}
+
+	if req.Operation == admissionv1.Update {
+		old := &backupsv1alpha1.RestoreJob{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, fmt.Errorf("decode old object: %w", err))
+		}
+		if restoreJobNamespacesEqual(old, j) {
+			return admission.Allowed("")
+		}
+	}
+
+	if err := validateRestoreJobNamespaces(j); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	checks := []authorizationv1.ResourceAttributes{{
+		Namespace: restoreJobBackupNamespace(j),
+		Verb:      "get",
+		Group:     backupsv1alpha1.GroupVersion.Group,
+		Resource:  "backups",
+		Name:      j.Spec.BackupRef.Name,
+	}}
+	targets := make([]string, 0, len(j.Spec.NamespaceMapping))
+	for _, to := range j.Spec.NamespaceMapping {
+		if to != j.Namespace {
+			targets = append(targets, to)
+		}
+	}
+	sort.Strings(targets)
+	for _, ns := range targets {
+		checks = append(checks, authorizationv1.ResourceAttributes{
+			Namespace: ns,
+			Verb:      "create",
+			Group:     backupsv1alpha1.GroupVersion.Group,
+			Resource:  "restorejobs",
+		})
+	}
+
+	for i := range checks {
+		allowed, reason, err := v.authorize(ctx, req, &checks[i])
+		if err != nil {
+			logger.Error(err, "SubjectAccessReview failed")
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if !allowed {
+			msg := fmt.Sprintf("user %q cannot %s %s.%s in namespace %s", req.UserInfo.Username,
+				checks[i].Verb, checks[i].Resource, checks[i].Group, checks[i].Namespace)
+			if reason != "" {
+				msg = fmt.Sprintf("%s: %s", msg, reason)
+			}
+			return admission.Denied(msg)
+		}
+	}
+	return admission.Allowed("")
+}
+
+// authorize runs a SubjectAccessReview for the user that sent req.
+func (v *RestoreJobValidator) authorize(ctx context.Context, req admission.Request, attrs *authorizationv1.ResourceAttributes) (bool, string, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for k, val := range req.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(val)
+	}
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: attrs,
+			User:               req.UserInfo.Username,
+			UID:                req.UserInfo.UID,
+			Groups:             req.UserInfo.Groups,
+			Extra:              extra,
+		},
+	}
+	if err := v.Create(ctx, sar); err != nil {
+		return false, "", fmt.Errorf("create SubjectAccessReview: %w", err)
+	}
+	return sar.Status.Allowed, sar.Status.Reason, nil
+}
+
+func restoreJobNamespacesEqual(a, b *backupsv1alpha1.RestoreJob) bool {
+	if restoreJobBackupNamespace(a) != restoreJobBackupNamespace(b) ||
+		a.Spec.BackupRef.Name != b.Spec.BackupRef.Name ||
+		len(a.Spec.NamespaceMapping) != len(b.Spec.NamespaceMapping) {
+		return false
+	}
+	for from, to := range a.Spec.NamespaceMapping {
+		if b.Spec.NamespaceMapping[from] != to {
+			return false
+		}
+	}
+	return true
+}
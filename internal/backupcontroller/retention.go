@@ -0,0 +1,98 @@
+package backupcontroller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// expiredByRetention reports which of the backups taken at takenAt, sorted
+// newest first, exceed retention at now.
+func expiredByRetention(takenAt []time.Time, retention *backupsv1alpha1.RetentionPolicy, now time.Time) []bool {
+	expired := make([]bool, len(takenAt))
+	for i, reason := range retentionReasons(takenAt, retention, now) {
+		expired[i] = reason != ""
+	}
+	return expired
+}
+
+// retentionReasons returns why each of the backups taken at takenAt, sorted
+// newest first, exceeds retention at now, or an empty string for the backups
+// that are kept.
+func retentionReasons(takenAt []time.Time, retention *backupsv1alpha1.RetentionPolicy, now time.Time) []string {
+	keep := make([]bool, len(takenAt))
+	var selectors []string
+	if retention.MaxCount != nil {
+		selectors = append(selectors, "maxCount")
+		for i := 0; i < len(takenAt) && i < int(*retention.MaxCount); i++ {
+			keep[i] = true
+		}
+	}
+	periods := []struct {
+		name   string
+		count  *int32
+		period func(time.Time) string
+	}{
+		{"keepDaily", retention.KeepDaily, func(t time.Time) string { return t.UTC().Format("2006-01-02") }},
+		{"keepWeekly", retention.KeepWeekly, func(t time.Time) string {
+			year, week := t.UTC().ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{"keepMonthly", retention.KeepMonthly, func(t time.Time) string { return t.UTC().Format("2006-01") }},
+	}
+	for _, p := range periods {
+		if p.count == nil {
+			continue
+		}
+		selectors = append(selectors, p.name)
+		keepNewestPerPeriod(takenAt, int(*p.count), p.period, keep)
+	}
+
+	reasons := make([]string, len(takenAt))
+	for i, t := range takenAt {
+		switch {
+		case retention.MaxAge != nil && now.Sub(t) > retention.MaxAge.Duration:
+			reasons[i] = fmt.Sprintf("older than maxAge %s", retention.MaxAge.Duration)
+		case len(selectors) > 0 && !keep[i]:
+			reasons[i] = fmt.Sprintf("not kept by %s", strings.Join(selectors, ", "))
+		}
+	}
+	return reasons
+}
+
+// keepNewestPerPeriod marks in keep the newest backup of each of the count most
+// recent periods that have backups. takenAt is sorted newest first.
+func keepNewestPerPeriod(takenAt []time.Time, count int, period func(time.Time) string, keep []bool) {
+	seen := map[string]bool{}
+	for i, t := range takenAt {
+		if len(seen) >= count {
+			return
+		}
+		p := period(t)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		keep[i] = true
+	}
+}
+
+// nextRetentionExpiry returns when the oldest of the backups taken at takenAt
+// that are not yet expired exceeds MaxAge, or the zero time if none will.
+func nextRetentionExpiry(takenAt []time.Time, expired []bool, retention *backupsv1alpha1.RetentionPolicy) time.Time {
+	if retention.MaxAge == nil {
+		return time.Time{}
+	}
+	var next time.Time
+	for i, t := range takenAt {
+		if expired[i] {
+			continue
+		}
+		if at := t.Add(retention.MaxAge.Duration); next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+	return next
+}
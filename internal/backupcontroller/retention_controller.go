@@ -0,0 +1,198 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// RetentionReconciler applies spec.retention of Plans: Backups of a Plan that
+// exceed its retention are reported in the status of the Plan, then deleted
+// together with their artifacts unless the retention is a dry run.
+type RetentionReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+func (r *RetentionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	p := &backupsv1alpha1.Plan{}
+	if err := r.Get(ctx, req.NamespacedName, p); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if p.Spec.Retention == nil {
+		if p.Status.Retention != nil {
+			p.Status.Retention = nil
+			return ctrl.Result{}, r.Status().Update(ctx, p)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	var list backupsv1alpha1.BackupList
+	if err := r.List(ctx, &list, client.InNamespace(p.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	// Only Ready backups count towards the retention, so that a failed run
+	// does not push out a restorable backup
+	var backups []*backupsv1alpha1.Backup
+	for i := range list.Items {
+		b := &list.Items[i]
+		if b.Spec.PlanRef != nil && b.Spec.PlanRef.Name == p.Name &&
+			b.Status.Phase == backupsv1alpha1.BackupPhaseReady && b.DeletionTimestamp == nil {
+			backups = append(backups, b)
+		}
+	}
+	// Newest first
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[j].Spec.TakenAt.Before(&backups[i].Spec.TakenAt)
+	})
+	takenAt := make([]time.Time, len(backups))
+	for i, b := range backups {
+		takenAt[i] = b.Spec.TakenAt.Time
+	}
+
+	now := time.Now()
+	reasons := retentionReasons(takenAt, p.Spec.Retention, now)
+	expired := make([]bool, len(backups))
+	var report []backupsv1alpha1.ExpiredBackup
+	for i, b := range backups {
+		if reasons[i] == "" {
+			continue
+		}
+		expired[i] = true
+		report = append(report, backupsv1alpha1.ExpiredBackup{Name: b.Name, TakenAt: b.Spec.TakenAt, Reason: reasons[i]})
+	}
+	if err := r.updateReport(ctx, p, report); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for i, b := range backups {
+		if !expired[i] || p.Spec.Retention.DryRun {
+			continue
+		}
+		deleted, err := r.deleteBackup(ctx, b)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !deleted {
+			// Keep the Backup, so that its artifacts are not orphaned
+			r.Recorder.Eventf(p, corev1.EventTypeWarning, "RetentionUnsupported",
+				"Backup %s exceeds the retention, but strategy %s cannot delete its artifacts", b.Name, b.Spec.StrategyRef.Kind)
+			continue
+		}
+		logger.Info("deleted expired Backup", "backup", b.Name, "takenAt", b.Spec.TakenAt)
+		r.Recorder.Eventf(p, corev1.EventTypeNormal, "BackupExpired",
+			"Deleted Backup %s taken at %s", b.Name, b.Spec.TakenAt.UTC().Format(time.RFC3339))
+	}
+
+	// New backups trigger a reconcile through the watch, MaxAge has to be checked again
+	if next := nextRetentionExpiry(takenAt, expired, p.Spec.Retention); !next.IsZero() {
+		return ctrl.Result{RequeueAfter: max(next.Sub(now), minRequeueDelay)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// updateReport stores the Backups exceeding the retention of p in its status.
+// In a dry run, the Backups newly listed are also announced as Events.
+func (r *RetentionReconciler) updateReport(ctx context.Context, p *backupsv1alpha1.Plan, expired []backupsv1alpha1.ExpiredBackup) error {
+	dryRun := p.Spec.Retention.DryRun
+	previous := p.Status.Retention
+	if previous != nil && previous.DryRun == dryRun && equality.Semantic.DeepEqual(previous.Expired, expired) {
+		return nil
+	}
+
+	if dryRun {
+		reported := map[string]bool{}
+		if previous != nil && previous.DryRun {
+			for _, e := range previous.Expired {
+				reported[e.Name] = true
+			}
+		}
+		for _, e := range expired {
+			if !reported[e.Name] {
+				r.Recorder.Eventf(p, corev1.EventTypeNormal, "RetentionDryRun",
+					"Backup %s taken at %s would be deleted: %s", e.Name, e.TakenAt.UTC().Format(time.RFC3339), e.Reason)
+			}
+		}
+	}
+
+	p.Status.Retention = &backupsv1alpha1.RetentionReport{
+		DryRun:  dryRun,
+		Time:    metav1.Now(),
+		Expired: expired,
+	}
+	if err := r.Status().Update(ctx, p); err != nil {
+		return fmt.Errorf("failed to update retention report of Plan %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// deleteBackup deletes the artifacts of b through the driver of its strategy,
+// then b itself. It returns false if the driver does not support deleting
+// artifacts, in which case nothing is deleted.
+func (r *RetentionReconciler) deleteBackup(ctx context.Context, b *backupsv1alpha1.Backup) (bool, error) {
+	if b.Spec.StrategyRef.APIGroup == nil || *b.Spec.StrategyRef.APIGroup != strategyv1alpha1.GroupVersion.Group {
+		return false, nil
+	}
+	switch b.Spec.StrategyRef.Kind {
+	case strategyv1alpha1.VeleroStrategyKind:
+		if name := b.Spec.DriverMetadata["velero.io/backup-name"]; name != "" {
+			if err := requestVeleroBackupDeletion(ctx, r.Client, name); err != nil {
+				return false, err
+			}
+		}
+		for _, c := range b.Status.Copies {
+			if c.Phase == backupsv1alpha1.BackupPhaseExpired {
+				continue
+			}
+			if name := c.DriverMetadata["velero.io/backup-name"]; name != "" {
+				if err := requestVeleroBackupDeletion(ctx, r.Client, name); err != nil {
+					return false, err
+				}
+			}
+		}
+	default:
+		return false, nil
+	}
+
+	if err := r.Delete(ctx, b); err != nil && !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to delete Backup %s: %w", b.Name, err)
+	}
+	return true, nil
+}
+
+// SetupWithManager registers our controller with the Manager and sets up watches.
+func (r *RetentionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("plan-retention").
+		For(&backupsv1alpha1.Plan{}).
+		Watches(
+			&backupsv1alpha1.Backup{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				b, ok := obj.(*backupsv1alpha1.Backup)
+				if !ok || b.Spec.PlanRef == nil {
+					return nil
+				}
+				return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: b.Namespace, Name: b.Spec.PlanRef.Name}}}
+			}),
+		).
+		Complete(r)
+}
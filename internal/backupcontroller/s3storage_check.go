@@ -0,0 +1,92 @@
+package backupcontroller
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// checkS3Bucket checks that the bucket of creds is reachable with its
+// credentials by sending a HEAD Bucket request signed with AWS Signature
+// Version 4. The bucket is addressed path-style, as Velero does.
+func checkS3Bucket(ctx context.Context, httpClient *http.Client, creds *S3Credentials) error {
+	endpoint, err := url.Parse(creds.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %q: %w", creds.Endpoint, err)
+	}
+	region := creds.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/" + creds.BucketName
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	signS3Request(req, creds.AccessKeyID, creds.AccessSecretKey, region, time.Now())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", creds.Endpoint, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusForbidden:
+		return fmt.Errorf("access to bucket %s denied, check the credentials", creds.BucketName)
+	case http.StatusNotFound:
+		return fmt.Errorf("bucket %s does not exist", creds.BucketName)
+	case http.StatusMovedPermanently:
+		return fmt.Errorf("bucket %s is not in region %s (%s)", creds.BucketName, region, resp.Header.Get("X-Amz-Bucket-Region"))
+	}
+	return fmt.Errorf("unexpected response to HEAD bucket %s: %s", creds.BucketName, resp.Status)
+}
+
+// signS3Request signs req, which must not have a body, for the s3 service
+// with AWS Signature Version 4.
+func signS3Request(req *http.Request, accessKeyID, secretAccessKey, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + emptyPayloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + secretAccessKey)
+	for _, part := range []string{date, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
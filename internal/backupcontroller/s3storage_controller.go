@@ -0,0 +1,241 @@
+package backupcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// s3StorageCheckInterval is how often the connectivity of an S3Storage is checked
+const s3StorageCheckInterval = 5 * time.Minute
+
+// S3StorageReconciler reconciles S3Storage objects: it checks that the bucket
+// is reachable with the referenced credentials, reports the result in the
+// Ready condition, and keeps the S3Storage while it is in use.
+type S3StorageReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// HTTPClient sends the requests to the S3 API. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+func (r *S3StorageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+
+	s := &backupsv1alpha1.S3Storage{}
+	if err := r.Get(ctx, req.NamespacedName, s); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !s.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, s)
+	}
+	if controllerutil.AddFinalizer(s, backupsv1alpha1.S3StorageFinalizer) {
+		if err := r.Update(ctx, s); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               backupsv1alpha1.S3StorageConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "BucketReachable",
+		Message:            fmt.Sprintf("Bucket %s is reachable", s.Spec.Bucket),
+		ObservedGeneration: s.Generation,
+	}
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	creds, err := resolveS3Storage(ctx, r.Client, s)
+	if err != nil {
+		var status apierrors.APIStatus
+		if errors.As(err, &status) && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, "CredentialsUnavailable", err.Error()
+	} else if err := checkS3Bucket(ctx, httpClient, creds); err != nil {
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, "BucketUnreachable", err.Error()
+	}
+
+	wasReady := meta.IsStatusConditionTrue(s.Status.Conditions, backupsv1alpha1.S3StorageConditionReady)
+	now := metav1.Now()
+	s.Status.LastCheckedAt = &now
+	meta.SetStatusCondition(&s.Status.Conditions, condition)
+	if err := r.Status().Update(ctx, s); err != nil {
+		logger.Error(err, "failed to update S3Storage status")
+		return ctrl.Result{}, err
+	}
+	if isReady := condition.Status == metav1.ConditionTrue; isReady != wasReady {
+		eventType := corev1.EventTypeNormal
+		if !isReady {
+			eventType = corev1.EventTypeWarning
+		}
+		r.Recorder.Event(s, eventType, condition.Reason, condition.Message)
+	}
+	logger.Debug("checked S3Storage", "s3storage", s.Name, "ready", condition.Status, "reason", condition.Reason)
+	return ctrl.Result{RequeueAfter: s3StorageCheckInterval}, nil
+}
+
+// reconcileDelete removes the finalizer of s once no Plan or unfinished
+// BackupJob refers to it.
+func (r *S3StorageReconciler) reconcileDelete(ctx context.Context, s *backupsv1alpha1.S3Storage) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(s, backupsv1alpha1.S3StorageFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	users, err := r.s3StorageUsers(ctx, s)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(users) > 0 {
+		r.Recorder.Eventf(s, corev1.EventTypeWarning, "StorageInUse",
+			"S3Storage is still in use by %v and is deleted once they no longer refer to it", users)
+		return ctrl.Result{RequeueAfter: minRequeueDelay}, nil
+	}
+	controllerutil.RemoveFinalizer(s, backupsv1alpha1.S3StorageFinalizer)
+	return ctrl.Result{}, r.Update(ctx, s)
+}
+
+// s3StorageUsers returns the Plans and unfinished BackupJobs referring to s
+func (r *S3StorageReconciler) s3StorageUsers(ctx context.Context, s *backupsv1alpha1.S3Storage) ([]string, error) {
+	var users []string
+
+	var plans backupsv1alpha1.PlanList
+	if err := r.List(ctx, &plans, client.InNamespace(s.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, p := range plans.Items {
+		refs := []*corev1.TypedLocalObjectReference{p.Spec.StorageRef, p.Status.StorageRef}
+		for i := range p.Spec.Copies {
+			refs = append(refs, &p.Spec.Copies[i].StorageRef)
+		}
+		if refersToS3Storage(s, refs...) {
+			users = append(users, "Plan/"+p.Name)
+		}
+	}
+
+	var jobs backupsv1alpha1.BackupJobList
+	if err := r.List(ctx, &jobs, client.InNamespace(s.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, j := range jobs.Items {
+		if j.Status.Phase == backupsv1alpha1.BackupJobPhaseSucceeded || j.Status.Phase == backupsv1alpha1.BackupJobPhaseFailed {
+			continue
+		}
+		refs := []*corev1.TypedLocalObjectReference{j.Spec.StorageRef, j.Status.StorageRef}
+		for i := range j.Spec.Copies {
+			refs = append(refs, &j.Spec.Copies[i])
+		}
+		if refersToS3Storage(s, refs...) {
+			users = append(users, "BackupJob/"+j.Name)
+		}
+	}
+	return users, nil
+}
+
+// isS3StorageRef reports whether ref refers to an S3Storage
+func isS3StorageRef(ref corev1.TypedLocalObjectReference) bool {
+	return ref.APIGroup != nil && *ref.APIGroup == backupsv1alpha1.GroupVersion.Group &&
+		ref.Kind == backupsv1alpha1.S3StorageKind
+}
+
+func refersToS3Storage(s *backupsv1alpha1.S3Storage, refs ...*corev1.TypedLocalObjectReference) bool {
+	for _, ref := range refs {
+		if ref != nil && isS3StorageRef(*ref) && ref.Name == s.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveS3Storage returns the location and credentials of the bucket of s
+func resolveS3Storage(ctx context.Context, c client.Reader, s *backupsv1alpha1.S3Storage) (*S3Credentials, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: s.Namespace, Name: s.Spec.SecretRef.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", s.Spec.SecretRef.Name, err)
+	}
+	creds := &S3Credentials{
+		BucketName:      s.Spec.Bucket,
+		Endpoint:        s.Spec.Endpoint,
+		Region:          s.Spec.Region,
+		Prefix:          s.Spec.Prefix,
+		AccessKeyID:     string(secret.Data[backupsv1alpha1.S3StorageAccessKeyIDKey]),
+		AccessSecretKey: string(secret.Data[backupsv1alpha1.S3StorageSecretAccessKeyKey]),
+		Encryption:      s.Spec.Encryption,
+	}
+	if creds.AccessKeyID == "" || creds.AccessSecretKey == "" {
+		return nil, fmt.Errorf("secret %s must contain the %s and %s keys", secret.Name,
+			backupsv1alpha1.S3StorageAccessKeyIDKey, backupsv1alpha1.S3StorageSecretAccessKeyKey)
+	}
+	return creds, nil
+}
+
+// storageNotReady returns why a Storage in refs, taken from namespace, cannot
+// be used yet, or an empty string if all can. Only S3Storages report their
+// readiness, other kinds of Storage are opaque to the core and always
+// considered ready.
+func storageNotReady(ctx context.Context, c client.Reader, namespace string, refs ...corev1.TypedLocalObjectReference) (string, error) {
+	for _, ref := range refs {
+		if !isS3StorageRef(ref) {
+			continue
+		}
+		s := &backupsv1alpha1.S3Storage{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, s); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("S3Storage %s not found", ref.Name), nil
+			}
+			return "", err
+		}
+		if !s.DeletionTimestamp.IsZero() {
+			return fmt.Sprintf("S3Storage %s is being deleted", ref.Name), nil
+		}
+		condition := meta.FindStatusCondition(s.Status.Conditions, backupsv1alpha1.S3StorageConditionReady)
+		if condition == nil {
+			return fmt.Sprintf("S3Storage %s has not been checked yet", ref.Name), nil
+		}
+		if condition.Status != metav1.ConditionTrue {
+			return fmt.Sprintf("S3Storage %s is not ready: %s", ref.Name, condition.Message), nil
+		}
+	}
+	return "", nil
+}
+
+// SetupWithManager registers our controller with the Manager and sets up watches.
+func (r *S3StorageReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupsv1alpha1.S3Storage{}).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				var list backupsv1alpha1.S3StorageList
+				if err := mgr.GetClient().List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+					return nil
+				}
+				var requests []reconcile.Request
+				for _, s := range list.Items {
+					if s.Spec.SecretRef.Name == obj.GetName() {
+						requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&s)})
+					}
+				}
+				return requests
+			}),
+		).
+		Complete(r)
+}
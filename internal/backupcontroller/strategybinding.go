@@ -0,0 +1,66 @@
+package backupcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// resolvedStrategy is the strategy a BackupJob runs with: either its
+// spec.strategyRef, or the strategy bound by the StrategyBinding it references.
+type resolvedStrategy struct {
+	// Ref refers to the driver-specific strategy object
+	Ref corev1.TypedLocalObjectReference
+	// Parameters are the StrategyBinding parameters, exposed to templates as .Parameters
+	Parameters map[string]any
+}
+
+// isStrategyBindingRef reports whether ref refers to a StrategyBinding
+func isStrategyBindingRef(ref corev1.TypedLocalObjectReference) bool {
+	return ref.APIGroup != nil &&
+		*ref.APIGroup == backupsv1alpha1.GroupVersion.Group &&
+		ref.Kind == backupsv1alpha1.StrategyBindingKind
+}
+
+// resolveStrategy returns the strategy referenced by ref, following a
+// StrategyBinding in namespace if ref refers to one.
+func resolveStrategy(ctx context.Context, c client.Reader, namespace string, ref corev1.TypedLocalObjectReference) (*resolvedStrategy, error) {
+	if !isStrategyBindingRef(ref) {
+		return &resolvedStrategy{Ref: ref}, nil
+	}
+
+	binding := &backupsv1alpha1.StrategyBinding{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, binding); err != nil {
+		return nil, err
+	}
+	if isStrategyBindingRef(binding.Spec.StrategyRef) {
+		return nil, fmt.Errorf("StrategyBinding %s/%s must not reference another StrategyBinding", namespace, binding.Name)
+	}
+
+	strategy := &resolvedStrategy{Ref: binding.Spec.StrategyRef}
+	if binding.Spec.Parameters != nil && len(binding.Spec.Parameters.Raw) > 0 {
+		if err := json.Unmarshal(binding.Spec.Parameters.Raw, &strategy.Parameters); err != nil {
+			return nil, fmt.Errorf("StrategyBinding %s/%s parameters must be a JSON object: %w", namespace, binding.Name, err)
+		}
+	}
+	return strategy, nil
+}
+
+// strategyTemplateContext returns the context strategy templates are rendered
+// with: the application object, extended with the strategy parameters.
+func strategyTemplateContext(app map[string]any, parameters map[string]any) map[string]any {
+	templateContext := make(map[string]any, len(app)+1)
+	for k, v := range app {
+		templateContext[k] = v
+	}
+	if parameters == nil {
+		parameters = map[string]any{}
+	}
+	templateContext["Parameters"] = parameters
+	return templateContext
+}
@@ -40,13 +40,15 @@ func (l loggerWithDebug) Debug(msg string, keysAndValues ...interface{}) {
 	l.Logger.V(1).Info(msg, keysAndValues...)
 }
 
-// S3Credentials holds the discovered S3 credentials from a Bucket storageRef
+// S3Credentials holds the discovered S3 credentials from a Bucket or S3Storage storageRef
 type S3Credentials struct {
 	BucketName      string
 	Endpoint        string
 	Region          string
+	Prefix          string
 	AccessKeyID     string
 	AccessSecretKey string
+	Encryption      *backupsv1alpha1.S3Encryption
 }
 
 // bucketInfo represents the structure of BucketInfo stored in the secret
@@ -70,15 +72,15 @@ const (
 	virtualMachinePrefix = "virtual-machine-"
 )
 
-func storageS3SecretName(namespace, backupJobName string) string {
-	return fmt.Sprintf("backup-%s-%s-s3-credentials", namespace, backupJobName)
+func storageS3SecretName(namespace, storageName string) string {
+	return fmt.Sprintf("backup-%s-%s-s3-credentials", namespace, storageName)
 }
 
 func boolPtr(b bool) *bool {
 	return &b
 }
 
-func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1alpha1.BackupJob) (ctrl.Result, error) {
+func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1alpha1.BackupJob, strategy *resolvedStrategy) (ctrl.Result, error) {
 	logger := getLogger(ctx)
 	logger.Debug("reconciling Velero strategy", "backupjob", j.Name, "phase", j.Status.Phase)
 
@@ -91,10 +93,8 @@ func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1a
 
 	// Step 1: On first reconcile, set startedAt (but not phase yet - phase will be set after backup creation)
 	logger.Debug("checking BackupJob status", "startedAt", j.Status.StartedAt, "phase", j.Status.Phase)
-	if j.Status.StartedAt == nil {
+	if markBackupJobStarted(j) {
 		logger.Debug("setting BackupJob StartedAt")
-		now := metav1.Now()
-		j.Status.StartedAt = &now
 		// Don't set phase to Running yet - will be set after Velero backup is successfully created
 		if err := r.Status().Update(ctx, j); err != nil {
 			logger.Error(err, "failed to update BackupJob status")
@@ -106,12 +106,12 @@ func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1a
 	}
 
 	// Step 2: Resolve inputs - Read Strategy, Storage, Application, optionally Plan
-	logger.Debug("fetching Velero strategy", "strategyName", j.Spec.StrategyRef.Name)
+	logger.Debug("fetching Velero strategy", "strategyName", strategy.Ref.Name)
 	veleroStrategy := &strategyv1alpha1.Velero{}
-	if err := r.Get(ctx, client.ObjectKey{Name: j.Spec.StrategyRef.Name}, veleroStrategy); err != nil {
+	if err := r.Get(ctx, client.ObjectKey{Name: strategy.Ref.Name}, veleroStrategy); err != nil {
 		if errors.IsNotFound(err) {
-			logger.Error(err, "Velero strategy not found", "strategyName", j.Spec.StrategyRef.Name)
-			return r.markBackupJobFailed(ctx, j, fmt.Sprintf("Velero strategy not found: %s", j.Spec.StrategyRef.Name))
+			logger.Error(err, "Velero strategy not found", "strategyName", strategy.Ref.Name)
+			return r.markBackupJobFailed(ctx, j, fmt.Sprintf("Velero strategy not found: %s", strategy.Ref.Name))
 		}
 		logger.Error(err, "failed to get Velero strategy")
 		return ctrl.Result{}, err
@@ -140,15 +140,26 @@ func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1a
 		return ctrl.Result{}, err
 	}
 
-	if len(veleroBackupList.Items) == 0 {
+	// Velero Backups producing copies of the backup are handled separately
+	var primaryBackups []velerov1.Backup
+	copyBackups := map[string]*velerov1.Backup{}
+	for i := range veleroBackupList.Items {
+		if index, ok := veleroBackupList.Items[i].Labels[backupsv1alpha1.CopyIndexLabel]; ok {
+			copyBackups[index] = &veleroBackupList.Items[i]
+			continue
+		}
+		primaryBackups = append(primaryBackups, veleroBackupList.Items[i])
+	}
+
+	if len(primaryBackups) == 0 {
 		// Create Velero Backup
 		logger.Debug("Velero Backup not found, creating new one")
-		if err := r.createVeleroBackup(ctx, j, veleroStrategy); err != nil {
+		if err := r.createVeleroBackup(ctx, j, veleroStrategy, strategy.Parameters); err != nil {
 			logger.Error(err, "failed to create Velero Backup")
 			return r.markBackupJobFailed(ctx, j, fmt.Sprintf("failed to create Velero Backup: %v", err))
 		}
-		// After successful Velero backup creation, set phase to Running
-		if j.Status.Phase != backupsv1alpha1.BackupJobPhaseRunning {
+		// After successful Velero backup creation, set phase to Running and record the filters
+		if j.Status.Phase != backupsv1alpha1.BackupJobPhaseRunning || j.Status.Filters != nil {
 			logger.Debug("setting BackupJob phase to Running after successful Velero backup creation")
 			j.Status.Phase = backupsv1alpha1.BackupJobPhaseRunning
 			if err := r.Status().Update(ctx, j); err != nil {
@@ -161,23 +172,28 @@ func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1a
 		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
 	}
 
-	if len(veleroBackupList.Items) > 1 {
+	if len(primaryBackups) > 1 {
 		logger.Error(fmt.Errorf("too many Velero backups for BackupJob"), "found more than one Velero Backup referencing a single BackupJob as owner")
-		j.Status.Phase = backupsv1alpha1.BackupJobPhaseFailed
+		setBackupJobCompleted(j, backupsv1alpha1.BackupJobPhaseFailed)
 		if err := r.Status().Update(ctx, j); err != nil {
 			logger.Error(err, "failed to update BackupJob status")
+			return ctrl.Result{}, nil
 		}
+		observeBackupJobCompleted(j)
 		return ctrl.Result{}, nil
 	}
 
-	veleroBackup := veleroBackupList.Items[0].DeepCopy()
+	veleroBackup := primaryBackups[0].DeepCopy()
 	logger.Debug("found existing Velero Backup", "phase", veleroBackup.Status.Phase)
 
 	// If Velero backup exists but phase is not Running, set it to Running
-	// This handles the case where the backup was created but phase wasn't set yet
-	if j.Status.Phase != backupsv1alpha1.BackupJobPhaseRunning {
-		logger.Debug("setting BackupJob phase to Running (Velero backup already exists)")
+	// This handles the case where the backup was created but phase wasn't set yet.
+	// The progress of the Velero Backup is mirrored while it runs.
+	progress := veleroBackupProgress(veleroBackup)
+	if j.Status.Phase != backupsv1alpha1.BackupJobPhaseRunning || !reflect.DeepEqual(j.Status.Progress, progress) {
+		logger.Debug("updating BackupJob phase and progress", "phase", veleroBackup.Status.Phase, "progress", progress)
 		j.Status.Phase = backupsv1alpha1.BackupJobPhaseRunning
+		j.Status.Progress = progress
 		if err := r.Status().Update(ctx, j); err != nil {
 			logger.Error(err, "failed to update BackupJob phase to Running")
 			return ctrl.Result{}, err
@@ -195,20 +211,27 @@ func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1a
 	if phase == "Completed" {
 		// Check if we already created the Backup resource
 		if j.Status.BackupRef == nil {
-			backup, err := r.createBackupResource(ctx, j, veleroBackup)
+			backup, err := r.createBackupResource(ctx, j, veleroBackup, strategy.Ref)
 			if err != nil {
 				return r.markBackupJobFailed(ctx, j, fmt.Sprintf("failed to create Backup resource: %v", err))
 			}
 
-			now := metav1.Now()
 			j.Status.BackupRef = &corev1.LocalObjectReference{Name: backup.Name}
-			j.Status.CompletedAt = &now
-			j.Status.Phase = backupsv1alpha1.BackupJobPhaseSucceeded
+			// With copies, the run completes once all copies have completed
+			if len(j.Spec.Copies) == 0 {
+				setBackupJobCompleted(j, backupsv1alpha1.BackupJobPhaseSucceeded)
+			}
 			if err := r.Status().Update(ctx, j); err != nil {
 				logger.Error(err, "failed to update BackupJob status")
 				return ctrl.Result{}, err
 			}
-			logger.Debug("BackupJob succeeded", "backup", backup.Name)
+			if len(j.Spec.Copies) == 0 {
+				observeBackupJobCompleted(j)
+				logger.Debug("BackupJob succeeded", "backup", backup.Name)
+			}
+		}
+		if len(j.Spec.Copies) > 0 {
+			return r.reconcileVeleroCopies(ctx, j, veleroBackup, copyBackups)
 		}
 		return ctrl.Result{}, nil
 	}
@@ -218,6 +241,10 @@ func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1a
 		message := fmt.Sprintf("Velero Backup failed with phase: %s", phase)
 		if len(veleroBackup.Status.ValidationErrors) > 0 {
 			message = fmt.Sprintf("%s: %v", message, veleroBackup.Status.ValidationErrors)
+		} else if veleroBackup.Status.FailureReason != "" {
+			message = fmt.Sprintf("%s: %s", message, veleroBackup.Status.FailureReason)
+		} else if veleroBackup.Status.Errors > 0 {
+			message = fmt.Sprintf("%s: %d error(s), see the logs of Velero Backup %s/%s", message, veleroBackup.Status.Errors, veleroNamespace, veleroBackup.Name)
 		}
 		return r.markBackupJobFailed(ctx, j, message)
 	}
@@ -226,6 +253,16 @@ func (r *BackupJobReconciler) reconcileVelero(ctx context.Context, j *backupsv1a
 	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 }
 
+// veleroBackupProgress returns the progress reported in the status of b
+func veleroBackupProgress(b *velerov1.Backup) *backupsv1alpha1.JobProgress {
+	progress := &backupsv1alpha1.JobProgress{Warnings: b.Status.Warnings, Errors: b.Status.Errors}
+	if b.Status.Progress != nil {
+		progress.TotalItems = b.Status.Progress.TotalItems
+		progress.ItemsCompleted = b.Status.Progress.ItemsBackedUp
+	}
+	return progress
+}
+
 // resolveBucketStorageRef discovers S3 credentials from a Bucket storageRef
 // It follows this flow:
 // 1. Get the Bucket resource (apps.cozystack.io/v1alpha1)
@@ -317,9 +354,8 @@ func (r *BackupJobReconciler) resolveBucketStorageRef(ctx context.Context, stora
 
 // createS3CredsForVelero creates or updates a Kubernetes Secret containing
 // Velero S3 credentials in the format expected by Velero's cloud-credentials plugin.
-func (r *BackupJobReconciler) createS3CredsForVelero(ctx context.Context, backupJob *backupsv1alpha1.BackupJob, creds *S3Credentials) error {
+func (r *BackupJobReconciler) createS3CredsForVelero(ctx context.Context, backupJob *backupsv1alpha1.BackupJob, secretName string, creds *S3Credentials) error {
 	logger := getLogger(ctx)
-	secretName := storageS3SecretName(backupJob.Namespace, backupJob.Name)
 	secretNamespace := veleroNamespace
 
 	secret := &corev1.Secret{
@@ -490,9 +526,7 @@ func (r *BackupJobReconciler) createVolumeSnapshotLocation(ctx context.Context,
 
 func (r *BackupJobReconciler) markBackupJobFailed(ctx context.Context, backupJob *backupsv1alpha1.BackupJob, message string) (ctrl.Result, error) {
 	logger := getLogger(ctx)
-	now := metav1.Now()
-	backupJob.Status.CompletedAt = &now
-	backupJob.Status.Phase = backupsv1alpha1.BackupJobPhaseFailed
+	setBackupJobCompleted(backupJob, backupsv1alpha1.BackupJobPhaseFailed)
 	backupJob.Status.Message = message
 
 	// Add condition
@@ -501,18 +535,19 @@ func (r *BackupJobReconciler) markBackupJobFailed(ctx context.Context, backupJob
 		Status:             metav1.ConditionFalse,
 		Reason:             "BackupFailed",
 		Message:            message,
-		LastTransitionTime: now,
+		LastTransitionTime: *backupJob.Status.CompletedAt,
 	})
 
 	if err := r.Status().Update(ctx, backupJob); err != nil {
 		logger.Error(err, "failed to update BackupJob status to Failed")
 		return ctrl.Result{}, err
 	}
+	observeBackupJobCompleted(backupJob)
 	logger.Debug("BackupJob failed", "message", message)
 	return ctrl.Result{}, nil
 }
 
-func (r *BackupJobReconciler) createVeleroBackup(ctx context.Context, backupJob *backupsv1alpha1.BackupJob, strategy *strategyv1alpha1.Velero) error {
+func (r *BackupJobReconciler) createVeleroBackup(ctx context.Context, backupJob *backupsv1alpha1.BackupJob, strategy *strategyv1alpha1.Velero, parameters map[string]any) error {
 	logger := getLogger(ctx)
 	logger.Debug("createVeleroBackup called", "strategy", strategy.Name)
 
@@ -529,10 +564,19 @@ func (r *BackupJobReconciler) createVeleroBackup(ctx context.Context, backupJob
 		return err
 	}
 
-	veleroBackupSpec, err := template.Template(&strategy.Spec.Template.Spec, app.Object)
+	veleroBackupSpec, err := template.Template(&strategy.Spec.Template.Spec, strategyTemplateContext(app.Object, parameters))
+	if err != nil {
+		return err
+	}
+	// Back up the objects of the application rather than whole namespaces
+	filters, err := r.applicationBackupFilters(ctx, app)
 	if err != nil {
 		return err
 	}
+	if applyBackupFilters(veleroBackupSpec, filters, app.GetNamespace()) {
+		backupJob.Status.Filters = filters
+		logger.Debug("scoped Velero Backup to the application", "namespaces", filters.IncludedNamespaces, "selectors", len(filters.LabelSelectors))
+	}
 	veleroBackup := &velerov1.Backup{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: fmt.Sprintf("%s.%s-", backupJob.Namespace, backupJob.Name),
@@ -561,7 +605,7 @@ func (r *BackupJobReconciler) createVeleroBackup(ctx context.Context, backupJob
 	return nil
 }
 
-func (r *BackupJobReconciler) createBackupResource(ctx context.Context, backupJob *backupsv1alpha1.BackupJob, veleroBackup *velerov1.Backup) (*backupsv1alpha1.Backup, error) {
+func (r *BackupJobReconciler) createBackupResource(ctx context.Context, backupJob *backupsv1alpha1.BackupJob, veleroBackup *velerov1.Backup, strategyRef corev1.TypedLocalObjectReference) (*backupsv1alpha1.Backup, error) {
 	logger := getLogger(ctx)
 	// Extract artifact information from Velero Backup
 	// Create a basic artifact referencing the Velero backup
@@ -583,6 +627,14 @@ func (r *BackupJobReconciler) createBackupResource(ctx context.Context, backupJo
 		"velero.io/backup-namespace": veleroBackup.Namespace,
 	}
 
+	// The storage recorded in status includes a resolved default storage
+	var storageRef corev1.TypedLocalObjectReference
+	if backupJob.Status.StorageRef != nil {
+		storageRef = *backupJob.Status.StorageRef
+	} else if backupJob.Spec.StorageRef != nil {
+		storageRef = *backupJob.Spec.StorageRef
+	}
+
 	backup := &backupsv1alpha1.Backup{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("%s", backupJob.Name),
@@ -599,8 +651,10 @@ func (r *BackupJobReconciler) createBackupResource(ctx context.Context, backupJo
 		},
 		Spec: backupsv1alpha1.BackupSpec{
 			ApplicationRef: backupJob.Spec.ApplicationRef,
-			StorageRef:     backupJob.Spec.StorageRef,
-			StrategyRef:    backupJob.Spec.StrategyRef,
+			StorageRef:     storageRef,
+			// The bound strategy rather than a StrategyBinding, so that restores
+			// do not depend on the binding
+			StrategyRef:    strategyRef,
 			TakenAt:        takenAt,
 			DriverMetadata: driverMetadata,
 		},
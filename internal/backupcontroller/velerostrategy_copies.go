@@ -0,0 +1,281 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+// defaultS3Region is used for copy storage locations whose Bucket does not report a region
+const defaultS3Region = "us-east-1"
+
+// copyStorageName identifies a copy Storage in the names of the Velero objects created for it
+func copyStorageName(storageRef corev1.TypedLocalObjectReference) string {
+	return fmt.Sprintf("%s-%s", strings.ToLower(storageRef.Kind), storageRef.Name)
+}
+
+// reconcileVeleroCopies produces the copies of a completed Velero backup and
+// mirrors their state to the status of the Backup. Velero cannot copy a backup
+// between storage locations, so every copy is a Velero Backup of the same spec
+// taken into the storage location of the copy once the primary backup completed.
+// The BackupJob succeeds when all copies have completed, whether or not they succeeded.
+func (r *BackupJobReconciler) reconcileVeleroCopies(ctx context.Context, j *backupsv1alpha1.BackupJob, primary *velerov1.Backup, copyBackups map[string]*velerov1.Backup) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+
+	backup := &backupsv1alpha1.Backup{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: j.Namespace, Name: j.Status.BackupRef.Name}, backup); err != nil {
+		logger.Error(err, "failed to get Backup", "backup", j.Status.BackupRef.Name)
+		return ctrl.Result{}, err
+	}
+
+	statuses := make([]backupsv1alpha1.BackupCopyStatus, len(j.Spec.Copies))
+	copy(statuses, backup.Status.Copies)
+	done, failed := true, 0
+	for i, storageRef := range j.Spec.Copies {
+		status := &statuses[i]
+		status.StorageRef = storageRef
+		switch status.Phase {
+		case backupsv1alpha1.BackupPhaseFailed:
+			failed++
+			continue
+		case backupsv1alpha1.BackupPhaseReady, backupsv1alpha1.BackupPhaseExpired:
+			continue
+		}
+
+		veleroBackup, ok := copyBackups[strconv.Itoa(i)]
+		if !ok {
+			if err := r.createVeleroBackupCopy(ctx, j, primary, i, storageRef); err != nil {
+				logger.Error(err, "failed to create Velero Backup copy", "storage", storageRef.Name)
+				status.Phase = backupsv1alpha1.BackupPhaseFailed
+				status.Message = fmt.Sprintf("failed to create Velero Backup: %v", err)
+				failed++
+				continue
+			}
+			status.Phase = backupsv1alpha1.BackupPhasePending
+			done = false
+			continue
+		}
+
+		switch phase := string(veleroBackup.Status.Phase); phase {
+		case "Completed":
+			status.Phase = backupsv1alpha1.BackupPhaseReady
+			status.Message = ""
+			status.Artifact = &backupsv1alpha1.BackupArtifact{
+				URI: fmt.Sprintf("velero://%s/%s", j.Namespace, veleroBackup.Name),
+			}
+			status.DriverMetadata = map[string]string{
+				"velero.io/backup-name":      veleroBackup.Name,
+				"velero.io/backup-namespace": veleroBackup.Namespace,
+			}
+		case "Failed", "PartiallyFailed", "FailedValidation":
+			status.Phase = backupsv1alpha1.BackupPhaseFailed
+			status.Message = fmt.Sprintf("Velero Backup failed with phase: %s", phase)
+			if len(veleroBackup.Status.ValidationErrors) > 0 {
+				status.Message = fmt.Sprintf("%s: %v", status.Message, veleroBackup.Status.ValidationErrors)
+			}
+			failed++
+		default:
+			status.Phase = backupsv1alpha1.BackupPhasePending
+			done = false
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(backup.Status.Copies, statuses) {
+		backup.Status.Copies = statuses
+		if err := r.Update(ctx, backup); err != nil {
+			logger.Error(err, "failed to update Backup copies", "backup", backup.Name)
+			return ctrl.Result{}, err
+		}
+	}
+	if !done {
+		return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+	}
+
+	setBackupJobCompleted(j, backupsv1alpha1.BackupJobPhaseSucceeded)
+	if failed > 0 {
+		j.Status.Message = fmt.Sprintf("%d of %d copies failed, see the status of Backup %s", failed, len(statuses), backup.Name)
+	}
+	if err := r.Status().Update(ctx, j); err != nil {
+		logger.Error(err, "failed to update BackupJob status")
+		return ctrl.Result{}, err
+	}
+	observeBackupJobCompleted(j)
+	logger.Debug("BackupJob succeeded", "backup", backup.Name, "failedCopies", failed)
+
+	if err := r.pruneVeleroCopies(ctx, j); err != nil {
+		logger.Error(err, "failed to apply retention to backup copies")
+	}
+	return ctrl.Result{}, nil
+}
+
+// createVeleroBackupCopy creates the Velero Backup producing copy index of the
+// backup in storageRef, along with the storage location it is stored in.
+func (r *BackupJobReconciler) createVeleroBackupCopy(ctx context.Context, j *backupsv1alpha1.BackupJob, primary *velerov1.Backup, index int, storageRef corev1.TypedLocalObjectReference) error {
+	logger := getLogger(ctx)
+
+	if storageRef.APIGroup == nil {
+		return fmt.Errorf("storage %s/%s has no apiGroup", storageRef.Kind, storageRef.Name)
+	}
+	var (
+		creds *S3Credentials
+		err   error
+	)
+	if isS3StorageRef(storageRef) {
+		s := &backupsv1alpha1.S3Storage{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: j.Namespace, Name: storageRef.Name}, s); err != nil {
+			return fmt.Errorf("failed to get S3Storage %s: %w", storageRef.Name, err)
+		}
+		creds, err = resolveS3Storage(ctx, r.Client, s)
+	} else {
+		creds, err = r.resolveBucketStorageRef(ctx, storageRef, j.Namespace)
+	}
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s", j.Namespace, copyStorageName(storageRef))
+	secretName := storageS3SecretName(j.Namespace, copyStorageName(storageRef))
+	if err := r.createS3CredsForVelero(ctx, j, secretName, creds); err != nil {
+		return err
+	}
+	region := creds.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	bsl := &velerov1.BackupStorageLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: veleroNamespace,
+		},
+		Spec: velerov1.BackupStorageLocationSpec{
+			Provider: "aws",
+			Config: map[string]string{
+				"region":           region,
+				"s3Url":            creds.Endpoint,
+				"s3ForcePathStyle": "true",
+			},
+			Credential: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  "cloud",
+			},
+			StorageType: velerov1.StorageType{
+				ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: creds.BucketName, Prefix: creds.Prefix},
+			},
+		},
+	}
+	if creds.Encryption != nil {
+		bsl.Spec.Config["serverSideEncryption"] = string(creds.Encryption.Type)
+		if creds.Encryption.KMSKeyID != "" {
+			bsl.Spec.Config["kmsKeyId"] = creds.Encryption.KMSKeyID
+		}
+	}
+	if err := r.createBackupStorageLocation(ctx, bsl); err != nil {
+		return err
+	}
+
+	spec := primary.Spec.DeepCopy()
+	spec.StorageLocation = bsl.Name
+	veleroBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s.%s-copy-%d-", j.Namespace, j.Name, index),
+			Namespace:    veleroNamespace,
+			Labels: map[string]string{
+				backupsv1alpha1.OwningJobNameLabel:      j.Name,
+				backupsv1alpha1.OwningJobNamespaceLabel: j.Namespace,
+				backupsv1alpha1.CopyIndexLabel:          strconv.Itoa(index),
+			},
+		},
+		Spec: *spec,
+	}
+	if err := r.Create(ctx, veleroBackup); err != nil {
+		r.Recorder.Event(j, corev1.EventTypeWarning, "VeleroBackupCreationFailed",
+			fmt.Sprintf("Failed to create Velero Backup copy in %s: %v", bsl.Name, err))
+		return err
+	}
+	logger.Debug("created Velero Backup copy", "name", veleroBackup.Name, "storageLocation", bsl.Name)
+	r.Recorder.Event(j, corev1.EventTypeNormal, "VeleroBackupCreated",
+		fmt.Sprintf("Created Velero Backup copy %s/%s in %s", veleroNamespace, veleroBackup.Name, bsl.Name))
+	return nil
+}
+
+// pruneVeleroCopies applies the retention of each copy of the Plan of j to the
+// Velero copies in its Storage: expired copies are deleted through Velero and
+// marked Expired on their Backup.
+func (r *BackupJobReconciler) pruneVeleroCopies(ctx context.Context, j *backupsv1alpha1.BackupJob) error {
+	logger := getLogger(ctx)
+	if j.Spec.PlanRef == nil {
+		return nil
+	}
+	plan := &backupsv1alpha1.Plan{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: j.Namespace, Name: j.Spec.PlanRef.Name}, plan); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	var list backupsv1alpha1.BackupList
+	if err := r.List(ctx, &list, client.InNamespace(j.Namespace)); err != nil {
+		return err
+	}
+	var backups []backupsv1alpha1.Backup
+	for _, b := range list.Items {
+		if b.Spec.PlanRef != nil && b.Spec.PlanRef.Name == plan.Name &&
+			b.Spec.StrategyRef.Kind == strategyv1alpha1.VeleroStrategyKind {
+			backups = append(backups, b)
+		}
+	}
+
+	now := time.Now()
+	for _, c := range plan.Spec.Copies {
+		for _, expired := range expiredCopies(backups, c.StorageRef, c.Retention, now) {
+			if c.Retention.DryRun {
+				r.Recorder.Eventf(plan, corev1.EventTypeNormal, "CopyRetentionDryRun",
+					"Copy of Backup %s in storage %s would be deleted", expired.Backup.Name, c.StorageRef.Name)
+				continue
+			}
+			status := &expired.Backup.Status.Copies[expired.Index]
+			if name := status.DriverMetadata["velero.io/backup-name"]; name != "" {
+				if err := requestVeleroBackupDeletion(ctx, r.Client, name); err != nil {
+					return err
+				}
+			}
+			status.Phase = backupsv1alpha1.BackupPhaseExpired
+			status.Message = "Removed by the retention policy of the storage"
+			status.Artifact = nil
+			if err := r.Update(ctx, expired.Backup); err != nil {
+				return fmt.Errorf("failed to mark copy of Backup %s expired: %w", expired.Backup.Name, err)
+			}
+			logger.Debug("expired backup copy", "backup", expired.Backup.Name, "storage", c.StorageRef.Name)
+		}
+	}
+	return nil
+}
+
+// requestVeleroBackupDeletion asks Velero to delete the Backup name together
+// with its data in the object storage and its volume snapshots.
+func requestVeleroBackupDeletion(ctx context.Context, c client.Client, name string) error {
+	request := &velerov1.DeleteBackupRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: name + "-",
+			Namespace:    veleroNamespace,
+			Labels: map[string]string{
+				velerov1.BackupNameLabel: name,
+			},
+		},
+		Spec: velerov1.DeleteBackupRequestSpec{BackupName: name},
+	}
+	if err := c.Create(ctx, request); err != nil {
+		return fmt.Errorf("failed to request deletion of Velero Backup %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+// Labels set by helm-controller on every object of a release, identifying its HelmRelease
+const (
+	helmReleaseNameLabel      = "helm.toolkit.fluxcd.io/name"
+	helmReleaseNamespaceLabel = "helm.toolkit.fluxcd.io/namespace"
+)
+
+var helmReleaseGVR = schema.GroupVersionResource{Group: "helm.toolkit.fluxcd.io", Version: "v2", Resource: "helmreleases"}
+
+// applicationBackupFilters computes the filters covering the objects of app from
+// its inventory in status.resources: the objects of its HelmReleases, and the
+// objects labelled with the application by the lineage webhook, such as pods and
+// volume claims. It returns nil if app has no inventory.
+func (r *BackupJobReconciler) applicationBackupFilters(ctx context.Context, app *unstructured.Unstructured) (*backupsv1alpha1.BackupFilters, error) {
+	resources, found, err := unstructured.NestedSlice(app.Object, "status", "resources")
+	if err != nil || !found || len(resources) == 0 {
+		return nil, err
+	}
+
+	namespaces := map[string]bool{}
+	if app.GetNamespace() != "" {
+		namespaces[app.GetNamespace()] = true
+	}
+	for _, resource := range resources {
+		if obj, ok := resource.(map[string]any); ok {
+			if ns, ok := obj["namespace"].(string); ok && ns != "" {
+				namespaces[ns] = true
+			}
+		}
+	}
+	filters := &backupsv1alpha1.BackupFilters{}
+	for ns := range namespaces {
+		filters.IncludedNamespaces = append(filters.IncludedNamespaces, ns)
+	}
+	sort.Strings(filters.IncludedNamespaces)
+
+	gv, err := schema.ParseGroupVersion(app.GetAPIVersion())
+	if err != nil {
+		return nil, err
+	}
+	appLabels := map[string]string{
+		appsv1alpha1.ApplicationGroupLabel: gv.Group,
+		appsv1alpha1.ApplicationKindLabel:  app.GetKind(),
+		appsv1alpha1.ApplicationNameLabel:  app.GetName(),
+	}
+	filters.LabelSelectors = append(filters.LabelSelectors, metav1.LabelSelector{MatchLabels: appLabels})
+
+	releases, err := r.Resource(helmReleaseGVR).Namespace(app.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			appsv1alpha1.ApplicationKindLabel: app.GetKind(),
+			appsv1alpha1.ApplicationNameLabel: app.GetName(),
+		}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list HelmReleases of the application: %w", err)
+	}
+	for _, hr := range releases.Items {
+		filters.LabelSelectors = append(filters.LabelSelectors, metav1.LabelSelector{MatchLabels: map[string]string{
+			helmReleaseNameLabel:      hr.GetName(),
+			helmReleaseNamespaceLabel: hr.GetNamespace(),
+		}})
+	}
+	return filters, nil
+}
+
+// applyBackupFilters scopes spec to filters, unless the strategy template
+// already selects objects by label or includes namespaces other than the
+// namespace of the application. It reports whether spec was changed.
+func applyBackupFilters(spec *velerov1.BackupSpec, filters *backupsv1alpha1.BackupFilters, appNamespace string) bool {
+	if filters == nil || spec.LabelSelector != nil || len(spec.OrLabelSelectors) > 0 {
+		return false
+	}
+	for _, ns := range spec.IncludedNamespaces {
+		if ns != appNamespace {
+			return false
+		}
+	}
+	spec.IncludedNamespaces = filters.IncludedNamespaces
+	spec.OrLabelSelectors = make([]*metav1.LabelSelector, len(filters.LabelSelectors))
+	for i := range filters.LabelSelectors {
+		spec.OrLabelSelectors[i] = filters.LabelSelectors[i].DeepCopy()
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+func (r *RestoreJobReconciler) reconcileVeleroRestore(ctx context.Context, j *backupsv1alpha1.RestoreJob, backup *backupsv1alpha1.Backup) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+	logger.Debug("reconciling Velero restore", "restorejob", j.Name, "phase", j.Status.Phase)
+
+	if j.Status.StartedAt == nil {
+		now := metav1.Now()
+		j.Status.StartedAt = &now
+		if err := r.Status().Update(ctx, j); err != nil {
+			logger.Error(err, "failed to update RestoreJob status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	veleroRestoreList := &velerov1.RestoreList{}
+	opts := []client.ListOption{
+		client.InNamespace(veleroNamespace),
+		client.MatchingLabels{
+			backupsv1alpha1.OwningJobNamespaceLabel: j.Namespace,
+			backupsv1alpha1.OwningJobNameLabel:      j.Name,
+		},
+	}
+	if err := r.List(ctx, veleroRestoreList, opts...); err != nil {
+		logger.Error(err, "failed to list Velero Restores")
+		return ctrl.Result{}, err
+	}
+
+	if len(veleroRestoreList.Items) == 0 {
+		if err := r.createVeleroRestore(ctx, j, backup); err != nil {
+			return r.markRestoreJobFailed(ctx, j, fmt.Sprintf("failed to create Velero Restore: %v", err))
+		}
+		if j.Status.Phase != backupsv1alpha1.RestoreJobPhaseRunning {
+			j.Status.Phase = backupsv1alpha1.RestoreJobPhaseRunning
+			if err := r.Status().Update(ctx, j); err != nil {
+				logger.Error(err, "failed to update RestoreJob phase to Running")
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	if len(veleroRestoreList.Items) > 1 {
+		return r.markRestoreJobFailed(ctx, j, "found more than one Velero Restore referencing this RestoreJob")
+	}
+
+	veleroRestore := &veleroRestoreList.Items[0]
+	logger.Debug("found existing Velero Restore", "phase", veleroRestore.Status.Phase)
+
+	// The progress of the Velero Restore is mirrored while it runs
+	progress := veleroRestoreProgress(veleroRestore)
+	if j.Status.Phase != backupsv1alpha1.RestoreJobPhaseRunning || !reflect.DeepEqual(j.Status.Progress, progress) {
+		j.Status.Phase = backupsv1alpha1.RestoreJobPhaseRunning
+		j.Status.Progress = progress
+		if err := r.Status().Update(ctx, j); err != nil {
+			logger.Error(err, "failed to update RestoreJob phase to Running")
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch veleroRestore.Status.Phase {
+	case velerov1.RestorePhaseCompleted:
+		now := metav1.Now()
+		j.Status.CompletedAt = &now
+		j.Status.Phase = backupsv1alpha1.RestoreJobPhaseSucceeded
+		if err := r.Status().Update(ctx, j); err != nil {
+			logger.Error(err, "failed to update RestoreJob status")
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Event(j, corev1.EventTypeNormal, "RestoreSucceeded",
+			fmt.Sprintf("Velero Restore %s/%s completed", veleroNamespace, veleroRestore.Name))
+		return ctrl.Result{}, nil
+	case velerov1.RestorePhaseFailed, velerov1.RestorePhasePartiallyFailed, velerov1.RestorePhaseFailedValidation:
+		message := fmt.Sprintf("Velero Restore failed with phase: %s", veleroRestore.Status.Phase)
+		if len(veleroRestore.Status.ValidationErrors) > 0 {
+			message = fmt.Sprintf("%s: %v", message, veleroRestore.Status.ValidationErrors)
+		} else if veleroRestore.Status.FailureReason != "" {
+			message = fmt.Sprintf("%s: %s", message, veleroRestore.Status.FailureReason)
+		} else if veleroRestore.Status.Errors > 0 {
+			message = fmt.Sprintf("%s: %d error(s), see the logs of Velero Restore %s/%s", message, veleroRestore.Status.Errors, veleroNamespace, veleroRestore.Name)
+		}
+		return r.markRestoreJobFailed(ctx, j, message)
+	}
+
+	// Still in progress (New, InProgress, Finalizing, etc.)
+	return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+}
+
+// createVeleroRestore creates a Velero Restore for the Velero Backup recorded
+// in the driver metadata of backup. Velero rewrites the namespaces of restored
+// objects and the namespaced references it knows about (PersistentVolume claim
+// references, RoleBinding subjects, etc.) according to spec.namespaceMapping.
+func (r *RestoreJobReconciler) createVeleroRestore(ctx context.Context, restoreJob *backupsv1alpha1.RestoreJob, backup *backupsv1alpha1.Backup) error {
+	logger := getLogger(ctx)
+
+	veleroBackupName := backup.Spec.DriverMetadata["velero.io/backup-name"]
+	if veleroBackupName == "" {
+		return fmt.Errorf("Backup %s/%s has no velero.io/backup-name in driver metadata", backup.Namespace, backup.Name)
+	}
+
+	spec, err := r.veleroRestoreTemplate(ctx, backup)
+	if err != nil {
+		return err
+	}
+	spec.BackupName = veleroBackupName
+	spec.NamespaceMapping = nil
+	spec.IncludedNamespaces = nil
+	if len(restoreJob.Spec.NamespaceMapping) > 0 {
+		// Restore only the mapped namespaces, so that a cross-namespace restore
+		// never writes into the namespaces the backup was taken from.
+		spec.NamespaceMapping = restoreJob.Spec.NamespaceMapping
+		for from := range restoreJob.Spec.NamespaceMapping {
+			spec.IncludedNamespaces = append(spec.IncludedNamespaces, from)
+		}
+		sort.Strings(spec.IncludedNamespaces)
+	}
+
+	veleroRestore := &velerov1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s.%s-", restoreJob.Namespace, restoreJob.Name),
+			Namespace:    veleroNamespace,
+			Labels: map[string]string{
+				backupsv1alpha1.OwningJobNameLabel:      restoreJob.Name,
+				backupsv1alpha1.OwningJobNamespaceLabel: restoreJob.Namespace,
+			},
+		},
+		Spec: *spec,
+	}
+	if err := r.Create(ctx, veleroRestore); err != nil {
+		logger.Error(err, "failed to create Velero Restore")
+		r.Recorder.Event(restoreJob, corev1.EventTypeWarning, "VeleroRestoreCreationFailed",
+			fmt.Sprintf("Failed to create Velero Restore for Backup %s/%s: %v", backup.Namespace, backup.Name, err))
+		return err
+	}
+
+	logger.Debug("created Velero Restore", "name", veleroRestore.Name, "namespace", veleroRestore.Namespace)
+	r.Recorder.Event(restoreJob, corev1.EventTypeNormal, "VeleroRestoreCreated",
+		fmt.Sprintf("Created Velero Restore %s/%s from Backup %s/%s", veleroNamespace, veleroRestore.Name, backup.Namespace, backup.Name))
+	return nil
+}
+
+// veleroRestoreTemplate returns a copy of the restore template of the Velero
+// strategy backup was taken with, or an empty spec if the strategy has none.
+// Backups outlive their strategies, so a deleted strategy is not an error.
+func (r *RestoreJobReconciler) veleroRestoreTemplate(ctx context.Context, backup *backupsv1alpha1.Backup) (*velerov1.RestoreSpec, error) {
+	strategy := &strategyv1alpha1.Velero{}
+	if err := r.Get(ctx, client.ObjectKey{Name: backup.Spec.StrategyRef.Name}, strategy); err != nil {
+		if apierrors.IsNotFound(err) {
+			getLogger(ctx).Debug("Velero strategy of Backup not found, restoring with defaults", "strategy", backup.Spec.StrategyRef.Name)
+			return &velerov1.RestoreSpec{}, nil
+		}
+		return nil, err
+	}
+	if strategy.Spec.RestoreTemplate == nil {
+		return &velerov1.RestoreSpec{}, nil
+	}
+	return strategy.Spec.RestoreTemplate.Spec.DeepCopy(), nil
+}
+
+// veleroRestoreProgress returns the progress reported in the status of restore
+func veleroRestoreProgress(restore *velerov1.Restore) *backupsv1alpha1.JobProgress {
+	progress := &backupsv1alpha1.JobProgress{Warnings: restore.Status.Warnings, Errors: restore.Status.Errors}
+	if restore.Status.Progress != nil {
+		progress.TotalItems = restore.Status.Progress.TotalItems
+		progress.ItemsCompleted = restore.Status.Progress.ItemsRestored
+	}
+	return progress
+}
@@ -0,0 +1,193 @@
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+// Conditions of a VerificationJob, one per check of a run
+const (
+	verificationConditionArtifact = "ArtifactVerified"
+	verificationConditionRestore  = "RestoreVerified"
+)
+
+// VerificationJobReconciler reconciles VerificationJob objects: it verifies
+// the referenced Backup once, or on every tick of spec.schedule, and reports
+// the result in the Verified condition of the Backup.
+type VerificationJobReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// HTTPClient downloads backup artifacts. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+func (r *VerificationJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+
+	j := &backupsv1alpha1.VerificationJob{}
+	if err := r.Get(ctx, req.NamespacedName, j); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if j.Status.Phase == backupsv1alpha1.VerificationJobPhaseSucceeded ||
+		j.Status.Phase == backupsv1alpha1.VerificationJobPhaseFailed {
+		return r.scheduleNextVerification(ctx, j)
+	}
+
+	backup := &backupsv1alpha1.Backup{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: j.Namespace, Name: j.Spec.BackupRef.Name}, backup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.finishVerification(ctx, j, nil, backupsv1alpha1.VerificationJobPhaseFailed,
+				fmt.Sprintf("Backup %s not found", j.Spec.BackupRef.Name))
+		}
+		return ctrl.Result{}, err
+	}
+	switch backup.Status.Phase {
+	case backupsv1alpha1.BackupPhaseReady:
+	case backupsv1alpha1.BackupPhaseFailed:
+		return r.finishVerification(ctx, j, backup, backupsv1alpha1.VerificationJobPhaseFailed,
+			fmt.Sprintf("Backup %s failed", backup.Name))
+	default:
+		logger.Debug("waiting for Backup to become ready", "backup", backup.Name, "phase", backup.Status.Phase)
+		if j.Status.Phase != backupsv1alpha1.VerificationJobPhasePending {
+			j.Status.Phase = backupsv1alpha1.VerificationJobPhasePending
+			if err := r.Status().Update(ctx, j); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: minRequeueDelay}, nil
+	}
+
+	if j.Status.Phase != backupsv1alpha1.VerificationJobPhaseRunning {
+		now := metav1.Now()
+		j.Status.Phase = backupsv1alpha1.VerificationJobPhaseRunning
+		j.Status.StartedAt = &now
+		if err := r.Status().Update(ctx, j); err != nil {
+			logger.Error(err, "failed to update VerificationJob status")
+			return ctrl.Result{}, err
+		}
+	}
+
+	strategyRef := backup.Spec.StrategyRef
+	if strategyRef.APIGroup != nil && *strategyRef.APIGroup == strategyv1alpha1.GroupVersion.Group &&
+		strategyRef.Kind == strategyv1alpha1.VeleroStrategyKind {
+		return r.reconcileVeleroVerification(ctx, j, backup)
+	}
+	return r.finishVerification(ctx, j, backup, backupsv1alpha1.VerificationJobPhaseFailed,
+		fmt.Sprintf("strategy %s does not support verification", strategyRef.Kind))
+}
+
+// scheduleNextVerification starts the next run of a completed VerificationJob
+// once it is due according to spec.schedule.
+func (r *VerificationJobReconciler) scheduleNextVerification(ctx context.Context, j *backupsv1alpha1.VerificationJob) (ctrl.Result, error) {
+	if j.Spec.Schedule == nil || j.Status.NextRunAt == nil {
+		return ctrl.Result{}, nil
+	}
+	if wait := time.Until(j.Status.NextRunAt.Time); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	getLogger(ctx).Debug("starting scheduled verification", "verificationjob", j.Name)
+	j.Status = backupsv1alpha1.VerificationJobStatus{Phase: backupsv1alpha1.VerificationJobPhasePending}
+	if err := r.Status().Update(ctx, j); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+}
+
+// setVerificationCondition records the result of a check of the current run
+func setVerificationCondition(j *backupsv1alpha1.VerificationJob, conditionType string, ok bool, reason, message string) {
+	status := metav1.ConditionFalse
+	if ok {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&j.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: j.Generation,
+	})
+}
+
+// finishVerification removes the objects created by the run, completes it
+// with phase and reports the result on backup, if it exists.
+func (r *VerificationJobReconciler) finishVerification(ctx context.Context, j *backupsv1alpha1.VerificationJob, backup *backupsv1alpha1.Backup, phase backupsv1alpha1.VerificationJobPhase, message string) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+
+	if err := r.cleanupVerification(ctx, j); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	j.Status.Phase = phase
+	j.Status.CompletedAt = &now
+	j.Status.Message = message
+	j.Status.NextRunAt = nil
+	if j.Spec.Schedule != nil {
+		sch, err := cron.ParseStandard(j.Spec.Schedule.Cron)
+		if err != nil {
+			message = fmt.Sprintf("%s; could not parse cron %s: %v", message, j.Spec.Schedule.Cron, err)
+			j.Status.Message = message
+		} else {
+			next := metav1.NewTime(sch.Next(now.Time))
+			j.Status.NextRunAt = &next
+		}
+	}
+	if err := r.Status().Update(ctx, j); err != nil {
+		logger.Error(err, "failed to update VerificationJob status")
+		return ctrl.Result{}, err
+	}
+
+	eventType, reason := corev1.EventTypeNormal, "VerificationSucceeded"
+	if phase != backupsv1alpha1.VerificationJobPhaseSucceeded {
+		eventType, reason = corev1.EventTypeWarning, "VerificationFailed"
+	}
+	r.Recorder.Event(j, eventType, reason, message)
+
+	if backup != nil {
+		condition := metav1.Condition{
+			Type:    backupsv1alpha1.BackupConditionVerified,
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: fmt.Sprintf("VerificationJob %s: %s", j.Name, message),
+		}
+		if phase != backupsv1alpha1.VerificationJobPhaseSucceeded {
+			condition.Status = metav1.ConditionFalse
+		}
+		meta.SetStatusCondition(&backup.Status.Conditions, condition)
+		if err := r.Update(ctx, backup); err != nil {
+			logger.Error(err, "failed to update Backup conditions", "backup", backup.Name)
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Event(backup, eventType, reason, condition.Message)
+	}
+
+	if j.Status.NextRunAt != nil {
+		return ctrl.Result{RequeueAfter: time.Until(j.Status.NextRunAt.Time)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager registers our controller with the Manager and sets up watches.
+func (r *VerificationJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupsv1alpha1.VerificationJob{}).
+		Complete(r)
+}
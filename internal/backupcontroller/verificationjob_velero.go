@@ -0,0 +1,287 @@
+package backupcontroller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+// reconcileVeleroVerification runs the checks of a VerificationJob against a
+// Backup taken with the Velero strategy: the contents of the Velero Backup are
+// downloaded and checked against the recorded checksum, then, if requested,
+// restored into a scratch namespace.
+func (r *VerificationJobReconciler) reconcileVeleroVerification(ctx context.Context, j *backupsv1alpha1.VerificationJob, backup *backupsv1alpha1.Backup) (ctrl.Result, error) {
+	veleroBackupName := backup.Spec.DriverMetadata["velero.io/backup-name"]
+	if veleroBackupName == "" {
+		return r.finishVerification(ctx, j, backup, backupsv1alpha1.VerificationJobPhaseFailed,
+			fmt.Sprintf("Backup %s has no velero.io/backup-name in driver metadata", backup.Name))
+	}
+
+	artifact := meta.FindStatusCondition(j.Status.Conditions, verificationConditionArtifact)
+	if artifact == nil {
+		return r.verifyVeleroArtifact(ctx, j, backup, veleroBackupName)
+	}
+	if artifact.Status != metav1.ConditionTrue {
+		return r.finishVerification(ctx, j, backup, backupsv1alpha1.VerificationJobPhaseFailed, artifact.Message)
+	}
+	message := artifact.Message
+
+	if j.Spec.ScratchRestore != nil {
+		restore := meta.FindStatusCondition(j.Status.Conditions, verificationConditionRestore)
+		if restore == nil {
+			return r.verifyVeleroRestore(ctx, j, backup, veleroBackupName)
+		}
+		if restore.Status != metav1.ConditionTrue {
+			return r.finishVerification(ctx, j, backup, backupsv1alpha1.VerificationJobPhaseFailed, restore.Message)
+		}
+		message = fmt.Sprintf("%s; %s", message, restore.Message)
+	}
+
+	return r.finishVerification(ctx, j, backup, backupsv1alpha1.VerificationJobPhaseSucceeded, message)
+}
+
+// verifyVeleroArtifact downloads the contents of the Velero Backup through a
+// Velero DownloadRequest and compares their checksum with the one recorded in
+// the artifact of backup.
+func (r *VerificationJobReconciler) verifyVeleroArtifact(ctx context.Context, j *backupsv1alpha1.VerificationJob, backup *backupsv1alpha1.Backup, veleroBackupName string) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+
+	list := &velerov1.DownloadRequestList{}
+	if err := r.List(ctx, list, client.InNamespace(veleroNamespace), client.MatchingLabels(verificationJobLabels(j))); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(list.Items) == 0 {
+		dr := &velerov1.DownloadRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: fmt.Sprintf("%s.%s-", j.Namespace, j.Name),
+				Namespace:    veleroNamespace,
+				Labels:       verificationJobLabels(j),
+			},
+			Spec: velerov1.DownloadRequestSpec{
+				Target: velerov1.DownloadTarget{
+					Kind: velerov1.DownloadTargetKindBackupContents,
+					Name: veleroBackupName,
+				},
+			},
+		}
+		if err := r.Create(ctx, dr); err != nil {
+			logger.Error(err, "failed to create Velero DownloadRequest")
+			return ctrl.Result{}, err
+		}
+		logger.Debug("created Velero DownloadRequest", "name", dr.Name)
+		return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+	}
+
+	dr := &list.Items[0]
+	if dr.Status.Phase != velerov1.DownloadRequestPhaseProcessed || dr.Status.DownloadURL == "" {
+		return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+	}
+
+	checksum, err := r.downloadChecksum(ctx, dr.Status.DownloadURL)
+	if err != nil {
+		setVerificationCondition(j, verificationConditionArtifact, false, "ArtifactUnavailable",
+			fmt.Sprintf("failed to download the contents of Velero Backup %s: %v", veleroBackupName, err))
+	} else {
+		j.Status.Checksum = checksum
+		ok, reason, message := compareChecksum(backup, checksum)
+		setVerificationCondition(j, verificationConditionArtifact, ok, reason, message)
+	}
+	if err := r.Status().Update(ctx, j); err != nil {
+		logger.Error(err, "failed to update VerificationJob status")
+		return ctrl.Result{}, err
+	}
+	if err := r.Delete(ctx, dr); err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "failed to delete Velero DownloadRequest", "name", dr.Name)
+	}
+	return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+}
+
+// downloadChecksum downloads url and returns the sha256 checksum of its content
+func (r *VerificationJobReconciler) downloadChecksum(ctx context.Context, url string) (string, error) {
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// compareChecksum compares the checksum of the downloaded artifact with the
+// one recorded in the artifact of backup. An artifact without a recorded
+// checksum passes, since it could be downloaded.
+func compareChecksum(backup *backupsv1alpha1.Backup, checksum string) (bool, string, string) {
+	var recorded string
+	if backup.Status.Artifact != nil {
+		recorded = backup.Status.Artifact.Checksum
+	}
+	switch {
+	case recorded == "":
+		return true, "NoChecksumRecorded", "artifact downloaded, the Backup records no checksum"
+	case !strings.HasPrefix(recorded, "sha256:"):
+		return false, "UnsupportedChecksum", fmt.Sprintf("unsupported checksum %s, only sha256 is supported", recorded)
+	case !strings.EqualFold(recorded, checksum):
+		return false, "ChecksumMismatch", fmt.Sprintf("artifact checksum %s does not match the recorded %s", checksum, recorded)
+	}
+	return true, "ChecksumMatched", "artifact checksum matches"
+}
+
+// verifyVeleroRestore restores the Velero Backup into the scratch namespace of
+// j, mapping the namespace of backup to it.
+func (r *VerificationJobReconciler) verifyVeleroRestore(ctx context.Context, j *backupsv1alpha1.VerificationJob, backup *backupsv1alpha1.Backup, veleroBackupName string) (ctrl.Result, error) {
+	logger := getLogger(ctx)
+	scratch := scratchNamespace(j)
+
+	list := &velerov1.RestoreList{}
+	if err := r.List(ctx, list, client.InNamespace(veleroNamespace), client.MatchingLabels(verificationJobLabels(j))); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(list.Items) == 0 {
+		ns := &corev1.Namespace{}
+		err := r.Get(ctx, client.ObjectKey{Name: scratch}, ns)
+		switch {
+		case apierrors.IsNotFound(err):
+			ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: scratch, Labels: verificationJobLabels(j)}}
+			if err := r.Create(ctx, ns); err != nil {
+				logger.Error(err, "failed to create scratch namespace", "namespace", scratch)
+				return ctrl.Result{}, err
+			}
+		case err != nil:
+			return ctrl.Result{}, err
+		case !ownedByVerificationJob(ns.Labels, j):
+			return r.finishVerification(ctx, j, backup, backupsv1alpha1.VerificationJobPhaseFailed,
+				fmt.Sprintf("scratch namespace %s already exists", scratch))
+		case ns.DeletionTimestamp != nil:
+			// Left over from the previous run
+			return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+		}
+
+		restore := &velerov1.Restore{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: fmt.Sprintf("%s.%s-", j.Namespace, j.Name),
+				Namespace:    veleroNamespace,
+				Labels:       verificationJobLabels(j),
+			},
+			Spec: velerov1.RestoreSpec{
+				BackupName:         veleroBackupName,
+				IncludedNamespaces: []string{backup.Namespace},
+				NamespaceMapping:   map[string]string{backup.Namespace: scratch},
+			},
+		}
+		if err := r.Create(ctx, restore); err != nil {
+			logger.Error(err, "failed to create Velero Restore")
+			return ctrl.Result{}, err
+		}
+		logger.Debug("created Velero Restore", "name", restore.Name, "scratchNamespace", scratch)
+		return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+	}
+
+	restore := &list.Items[0]
+	switch restore.Status.Phase {
+	case velerov1.RestorePhaseCompleted:
+		setVerificationCondition(j, verificationConditionRestore, true, "RestoreSucceeded",
+			fmt.Sprintf("restored into scratch namespace %s", scratch))
+	case velerov1.RestorePhaseFailed, velerov1.RestorePhasePartiallyFailed, velerov1.RestorePhaseFailedValidation:
+		message := fmt.Sprintf("scratch restore failed with phase: %s", restore.Status.Phase)
+		if len(restore.Status.ValidationErrors) > 0 {
+			message = fmt.Sprintf("%s: %v", message, restore.Status.ValidationErrors)
+		} else if restore.Status.FailureReason != "" {
+			message = fmt.Sprintf("%s: %s", message, restore.Status.FailureReason)
+		}
+		setVerificationCondition(j, verificationConditionRestore, false, "RestoreFailed", message)
+	default:
+		return ctrl.Result{RequeueAfter: defaultActiveJobPollingInterval}, nil
+	}
+	if err := r.Status().Update(ctx, j); err != nil {
+		logger.Error(err, "failed to update VerificationJob status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+}
+
+// cleanupVerification deletes the Velero objects and the scratch namespace
+// created by the run of j
+func (r *VerificationJobReconciler) cleanupVerification(ctx context.Context, j *backupsv1alpha1.VerificationJob) error {
+	labels := client.MatchingLabels(verificationJobLabels(j))
+	for _, list := range []client.ObjectList{&velerov1.DownloadRequestList{}, &velerov1.RestoreList{}} {
+		if err := r.List(ctx, list, client.InNamespace(veleroNamespace), labels); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		if err := meta.EachListItem(list, func(obj runtime.Object) error {
+			if err := r.Delete(ctx, obj.(client.Object)); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	if j.Spec.ScratchRestore == nil {
+		return nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: scratchNamespace(j)}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !ownedByVerificationJob(ns.Labels, j) || ns.DeletionTimestamp != nil {
+		return nil
+	}
+	if err := r.Delete(ctx, ns); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// scratchNamespace returns the namespace the scratch restore of j restores into
+func scratchNamespace(j *backupsv1alpha1.VerificationJob) string {
+	if j.Spec.ScratchRestore != nil && j.Spec.ScratchRestore.Namespace != "" {
+		return j.Spec.ScratchRestore.Namespace
+	}
+	return fmt.Sprintf("verify-%s-%s", j.Namespace, j.Name)
+}
+
+func verificationJobLabels(j *backupsv1alpha1.VerificationJob) map[string]string {
+	return map[string]string{
+		backupsv1alpha1.OwningVerificationJobNameLabel:      j.Name,
+		backupsv1alpha1.OwningVerificationJobNamespaceLabel: j.Namespace,
+	}
+}
+
+func ownedByVerificationJob(labels map[string]string, j *backupsv1alpha1.VerificationJob) bool {
+	return labels[backupsv1alpha1.OwningVerificationJobNameLabel] == j.Name &&
+		labels[backupsv1alpha1.OwningVerificationJobNamespaceLabel] == j.Namespace
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// ApplicationShadowReconciler keeps an ApplicationShadow for every HelmRelease
+// of an application. The shadow is owned by the HelmRelease, so it is garbage
+// collected together with the application.
+type ApplicationShadowReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=cozystack.io,resources=applicationshadows,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases,verbs=get;list;watch
+
+func (r *ApplicationShadowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	hr := &helmv2.HelmRelease{}
+	if err := r.Get(ctx, req.NamespacedName, hr); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The shadow is garbage collected through its ownerReference
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !hr.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	group := hr.Labels[appsv1alpha1.ApplicationGroupLabel]
+	kind := hr.Labels[appsv1alpha1.ApplicationKindLabel]
+	name := hr.Labels[appsv1alpha1.ApplicationNameLabel]
+	if group == "" || kind == "" || name == "" {
+		return ctrl.Result{}, nil
+	}
+
+	shadow := &cozyv1alpha1.ApplicationShadow{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cozyv1alpha1.ApplicationShadowName(kind, name),
+			Namespace: hr.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, shadow, func() error {
+		if shadow.Labels == nil {
+			shadow.Labels = map[string]string{}
+		}
+		shadow.Labels[appsv1alpha1.ApplicationGroupLabel] = group
+		shadow.Labels[appsv1alpha1.ApplicationKindLabel] = kind
+		shadow.Labels[appsv1alpha1.ApplicationNameLabel] = name
+		shadow.Spec = cozyv1alpha1.ApplicationShadowSpec{
+			ApplicationRef: corev1.TypedLocalObjectReference{
+				APIGroup: &group,
+				Kind:     kind,
+				Name:     name,
+			},
+			HelmReleaseName: hr.Name,
+		}
+		return controllerutil.SetControllerReference(hr, shadow, r.Scheme)
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("reconciled ApplicationShadow", "name", shadow.Name, "operation", op)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager registers our controller with the Manager and sets up watches.
+func (r *ApplicationShadowReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isApplication := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		_, ok := obj.GetLabels()[appsv1alpha1.ApplicationKindLabel]
+		return ok
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("applicationshadow").
+		For(&helmv2.HelmRelease{}, builder.WithPredicates(isApplication)).
+		Owns(&cozyv1alpha1.ApplicationShadow{}).
+		Complete(r)
+}
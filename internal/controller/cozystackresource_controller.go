@@ -10,11 +10,14 @@ import (
 	"time"
 
 	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/reconcilemetrics"
+	"github.com/cozystack/cozystack/pkg/config"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,7 +42,10 @@ type CozystackResourceDefinitionReconciler struct {
 func (r *CozystackResourceDefinitionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Only handle debounced restart logic
 	// HelmRelease reconciliation is handled by CozystackResourceDefinitionHelmReconciler
-	return r.debouncedRestart(ctx)
+	start := time.Now()
+	result, err := r.debouncedRestart(ctx)
+	reconcilemetrics.Observe("cozystackresource-controller", start, result, err)
+	return result, err
 }
 
 func (r *CozystackResourceDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -94,6 +100,18 @@ func (r *CozystackResourceDefinitionReconciler) computeConfigHash(ctx context.Co
 	return hex.EncodeToString(sum[:]), nil
 }
 
+// validateDefinitions checks that the current set of CozystackResourceDefinitions
+// can be served by cozystack-api and returns the problems found
+func (r *CozystackResourceDefinitionReconciler) validateDefinitions(ctx context.Context) ([]error, error) {
+	list := &cozyv1alpha1.CozystackResourceDefinitionList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(list.Items, sortCozyRDs)
+	_, errs := config.Validate(config.FromResourceDefinitions(list.Items))
+	return errs, nil
+}
+
 func (r *CozystackResourceDefinitionReconciler) debouncedRestart(ctx context.Context) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
@@ -116,6 +134,18 @@ func (r *CozystackResourceDefinitionReconciler) debouncedRestart(ctx context.Con
 		return ctrl.Result{}, nil
 	}
 
+	// Stage the new definitions: a set that does not validate is not rolled out,
+	// cozystack-api keeps serving its last validated snapshot instead
+	if errs, err := r.validateDefinitions(ctx); err != nil {
+		return ctrl.Result{}, err
+	} else if len(errs) > 0 {
+		r.mu.Lock()
+		r.lastHandled = le
+		r.mu.Unlock()
+		logger.Error(utilerrors.NewAggregate(errs), "CozystackResourceDefinitions failed validation; skipping restart")
+		return ctrl.Result{}, nil
+	}
+
 	newHash, err := r.computeConfigHash(ctx)
 	if err != nil {
 		return ctrl.Result{}, err
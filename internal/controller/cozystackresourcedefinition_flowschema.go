@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/reconcilemetrics"
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+const (
+	// FlowSchemaLabel marks the FlowSchemas generated for CozystackResourceDefinitions
+	FlowSchemaLabel = "internal.cozystack.io/flowschema"
+
+	flowSchemaPrefix            = "cozystack-apps-"
+	defaultFlowSchemaPrecedence = 1000
+)
+
+// +kubebuilder:rbac:groups=cozystack.io,resources=cozystackresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=flowcontrol.apiserver.k8s.io,resources=flowschemas,verbs=get;list;watch;create;update;patch;delete
+
+// CozystackResourceDefinitionFlowSchemaReconciler generates a FlowSchema for
+// every CozystackResourceDefinition annotated with a priority level, so that
+// API Priority and Fairness serves requests to applications of its kind at
+// that level, with fair queuing between namespaces.
+type CozystackResourceDefinitionFlowSchemaReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func (r *CozystackResourceDefinitionFlowSchemaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	reconcilemetrics.Observe("cozystackresourcedefinition-flowschema-reconciler", start, result, err)
+	return result, err
+}
+
+func (r *CozystackResourceDefinitionFlowSchemaReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	crd := &cozyv1alpha1.CozystackResourceDefinition{}
+	if err := r.Get(ctx, req.NamespacedName, crd); err != nil {
+		// The FlowSchema is garbage collected with its owner
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	fs := &flowcontrolv1.FlowSchema{ObjectMeta: metav1.ObjectMeta{Name: flowSchemaPrefix + crd.Name}}
+	priorityLevel := strings.TrimSpace(crd.Annotations[cozyv1alpha1.PriorityLevelAnnotation])
+	if priorityLevel == "" || crd.Spec.Application.Plural == "" || !crd.DeletionTimestamp.IsZero() {
+		if err := r.Delete(ctx, fs); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	precedence, err := flowSchemaPrecedence(crd.Annotations)
+	if err != nil {
+		logger.Error(err, "invalid annotation", "crd", crd.Name)
+		return ctrl.Result{}, nil
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, fs, func() error {
+		if fs.Labels == nil {
+			fs.Labels = map[string]string{}
+		}
+		fs.Labels[FlowSchemaLabel] = "true"
+		fs.Spec = flowSchemaSpec(crd.Spec.Application.Plural, priorityLevel, precedence)
+		return controllerutil.SetControllerReference(crd, fs, r.Scheme)
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info("reconciled FlowSchema", "crd", crd.Name, "flowSchema", fs.Name, "priorityLevel", priorityLevel, "operation", op)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *CozystackResourceDefinitionFlowSchemaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("cozystackresourcedefinition-flowschema-reconciler").
+		For(&cozyv1alpha1.CozystackResourceDefinition{}).
+		Owns(&flowcontrolv1.FlowSchema{}).
+		Complete(r)
+}
+
+// flowSchemaPrecedence returns the matching precedence set on a
+// CozystackResourceDefinition, or the default one
+func flowSchemaPrecedence(annotations map[string]string) (int32, error) {
+	raw, ok := annotations[cozyv1alpha1.FlowSchemaPrecedenceAnnotation]
+	if !ok {
+		return defaultFlowSchemaPrecedence, nil
+	}
+	precedence, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 32)
+	if err != nil || precedence < 1 || precedence > 10000 {
+		return 0, fmt.Errorf("%s must be an integer between 1 and 10000, got %q", cozyv1alpha1.FlowSchemaPrecedenceAnnotation, raw)
+	}
+	return int32(precedence), nil
+}
+
+// flowSchemaSpec matches the requests of all users to the applications of a
+// kind and its subresources, distinguishing flows by namespace
+func flowSchemaSpec(plural, priorityLevel string, precedence int32) flowcontrolv1.FlowSchemaSpec {
+	return flowcontrolv1.FlowSchemaSpec{
+		PriorityLevelConfiguration: flowcontrolv1.PriorityLevelConfigurationReference{Name: priorityLevel},
+		MatchingPrecedence:         precedence,
+		DistinguisherMethod: &flowcontrolv1.FlowDistinguisherMethod{
+			Type: flowcontrolv1.FlowDistinguisherMethodByNamespaceType,
+		},
+		Rules: []flowcontrolv1.PolicyRulesWithSubjects{{
+			Subjects: []flowcontrolv1.Subject{
+				{
+					Kind:  flowcontrolv1.SubjectKindGroup,
+					Group: &flowcontrolv1.GroupSubject{Name: user.AllAuthenticated},
+				},
+				{
+					Kind:  flowcontrolv1.SubjectKindGroup,
+					Group: &flowcontrolv1.GroupSubject{Name: user.AllUnauthenticated},
+				},
+			},
+			ResourceRules: []flowcontrolv1.ResourcePolicyRule{{
+				Verbs:      []string{flowcontrolv1.VerbAll},
+				APIGroups:  []string{appsv1alpha1.GroupName},
+				Resources:  []string{plural, plural + "/*"},
+				Namespaces: []string{flowcontrolv1.NamespaceEvery},
+			}},
+		}},
+	}
+}
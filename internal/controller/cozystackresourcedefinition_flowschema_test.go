@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	flowcontrolv1 "k8s.io/api/flowcontrol/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+)
+
+func TestFlowSchemaPrecedence(t *testing.T) {
+	if got, err := flowSchemaPrecedence(nil); err != nil || got != defaultFlowSchemaPrecedence {
+		t.Errorf("flowSchemaPrecedence(nil) = %d, %v, want %d", got, err, defaultFlowSchemaPrecedence)
+	}
+	if got, err := flowSchemaPrecedence(map[string]string{cozyv1alpha1.FlowSchemaPrecedenceAnnotation: "500"}); err != nil || got != 500 {
+		t.Errorf("flowSchemaPrecedence(500) = %d, %v, want 500", got, err)
+	}
+	for _, raw := range []string{"0", "10001", "high"} {
+		if _, err := flowSchemaPrecedence(map[string]string{cozyv1alpha1.FlowSchemaPrecedenceAnnotation: raw}); err == nil {
+			t.Errorf("flowSchemaPrecedence(%q) accepted an invalid precedence", raw)
+		}
+	}
+}
+
+func TestCozystackResourceDefinitionFlowSchemaReconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cozyv1alpha1.AddToScheme(scheme)
+	_ = flowcontrolv1.AddToScheme(scheme)
+
+	crd := &cozyv1alpha1.CozystackResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "postgres",
+			Annotations: map[string]string{cozyv1alpha1.PriorityLevelAnnotation: "workload-low"},
+		},
+		Spec: cozyv1alpha1.CozystackResourceDefinitionSpec{
+			Application: cozyv1alpha1.CozystackResourceDefinitionApplication{Kind: "Postgres", Plural: "postgreses"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(crd).Build()
+	r := &CozystackResourceDefinitionFlowSchemaReconciler{Client: c, Scheme: scheme}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "postgres"}}
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	fs := &flowcontrolv1.FlowSchema{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: flowSchemaPrefix + "postgres"}, fs); err != nil {
+		t.Fatalf("FlowSchema was not created: %v", err)
+	}
+	if fs.Spec.PriorityLevelConfiguration.Name != "workload-low" {
+		t.Errorf("priority level = %q, want workload-low", fs.Spec.PriorityLevelConfiguration.Name)
+	}
+	if fs.Spec.DistinguisherMethod == nil || fs.Spec.DistinguisherMethod.Type != flowcontrolv1.FlowDistinguisherMethodByNamespaceType {
+		t.Errorf("expected flows to be distinguished by namespace, got %+v", fs.Spec.DistinguisherMethod)
+	}
+	if got := fs.Spec.Rules[0].ResourceRules[0].Resources; len(got) != 2 || got[0] != "postgreses" {
+		t.Errorf("resources = %v, want postgreses and its subresources", got)
+	}
+
+	// Removing the annotation deletes the FlowSchema
+	crd.Annotations = nil
+	if err := c.Update(context.TODO(), crd); err != nil {
+		t.Fatalf("failed to update CozystackResourceDefinition: %v", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: flowSchemaPrefix + "postgres"}, fs); !apierrors.IsNotFound(err) {
+		t.Errorf("expected FlowSchema to be deleted, got err=%v", err)
+	}
+}
@@ -3,8 +3,10 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/reconcilemetrics"
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -27,6 +29,13 @@ type CozystackResourceDefinitionHelmReconciler struct {
 }
 
 func (r *CozystackResourceDefinitionHelmReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	reconcilemetrics.Observe("cozystackresourcedefinition-helm-reconciler", start, result, err)
+	return result, err
+}
+
+func (r *CozystackResourceDefinitionHelmReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
 	// Get the CozystackResourceDefinition that triggered this reconciliation
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/reconcilemetrics"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// MigratedFromAnnotation records the name of the HelmRelease a migrated
+	// HelmRelease was renamed from
+	MigratedFromAnnotation = "internal.cozystack.io/migrated-from"
+
+	// migrationSuspendAnnotation records whether a HelmRelease was suspended
+	// before the migration suspended it
+	migrationSuspendAnnotation = "internal.cozystack.io/migration-suspend"
+)
+
+// +kubebuilder:rbac:groups=cozystack.io,resources=cozystackresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cozystack.io,resources=cozystackresourcedefinitions/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases,verbs=get;list;watch;create;update;patch;delete
+
+// CozystackResourceDefinitionPrefixMigrationReconciler renames the
+// HelmReleases of a kind to its current release prefix when the
+// CozystackResourceDefinition is annotated with the previous one, so that
+// changing the prefix does not orphan existing applications.
+//
+// Every HelmRelease is suspended, copied under the new name with the same
+// Helm release name, and deleted. Flux keeps the Helm release of a suspended
+// HelmRelease on deletion, so the workloads are not reinstalled.
+type CozystackResourceDefinitionPrefixMigrationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func (r *CozystackResourceDefinitionPrefixMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	reconcilemetrics.Observe("cozystackresourcedefinition-prefixmigration-reconciler", start, result, err)
+	return result, err
+}
+
+func (r *CozystackResourceDefinitionPrefixMigrationReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	crd := &cozyv1alpha1.CozystackResourceDefinition{}
+	if err := r.Get(ctx, req.NamespacedName, crd); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	from, ok := crd.Annotations[cozyv1alpha1.MigrateReleasePrefixFromAnnotation]
+	if !ok || !crd.DeletionTimestamp.IsZero() || crd.Spec.Application.Kind == "" {
+		return ctrl.Result{}, nil
+	}
+	to := crd.Spec.Release.Prefix
+
+	status := &cozyv1alpha1.PrefixMigrationStatus{From: from, To: to}
+	// A prefix extending the other one cannot tell the HelmReleases apart
+	if strings.HasPrefix(to, from) || strings.HasPrefix(from, to) {
+		status.Phase = cozyv1alpha1.PrefixMigrationFailed
+		status.Failed = []string{fmt.Sprintf("prefixes %q and %q overlap", from, to)}
+		return ctrl.Result{}, r.updateStatus(ctx, crd, status)
+	}
+
+	hrList := &helmv2.HelmReleaseList{}
+	if err := r.List(ctx, hrList, client.MatchingLabels{
+		"apps.cozystack.io/application.kind":  crd.Spec.Application.Kind,
+		"apps.cozystack.io/application.group": "apps.cozystack.io",
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var migrateErr error
+	for i := range hrList.Items {
+		hr := &hrList.Items[i]
+		if strings.HasPrefix(hr.Annotations[MigratedFromAnnotation], from) {
+			status.Total++
+			status.Migrated++
+			continue
+		}
+		if !strings.HasPrefix(hr.Name, from) || !hr.DeletionTimestamp.IsZero() {
+			continue
+		}
+		status.Total++
+		reason, err := r.migrateHelmRelease(ctx, hr, from, to)
+		if err != nil {
+			logger.Error(err, "failed to migrate HelmRelease", "name", hr.Name, "namespace", hr.Namespace)
+			migrateErr = err
+			continue
+		}
+		if reason != "" {
+			status.Failed = append(status.Failed, fmt.Sprintf("%s/%s: %s", hr.Namespace, hr.Name, reason))
+			continue
+		}
+		logger.Info("migrated HelmRelease", "namespace", hr.Namespace, "from", hr.Name, "to", to+strings.TrimPrefix(hr.Name, from))
+		status.Migrated++
+	}
+
+	switch {
+	case migrateErr != nil:
+		status.Phase = cozyv1alpha1.PrefixMigrationRunning
+	case len(status.Failed) > 0:
+		status.Phase = cozyv1alpha1.PrefixMigrationFailed
+	default:
+		status.Phase = cozyv1alpha1.PrefixMigrationCompleted
+	}
+	if err := r.updateStatus(ctx, crd, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, migrateErr
+}
+
+// migrateHelmRelease renames hr from the from prefix to the to prefix. It
+// returns the reason when a safety check prevents the migration.
+func (r *CozystackResourceDefinitionPrefixMigrationReconciler) migrateHelmRelease(ctx context.Context, hr *helmv2.HelmRelease, from, to string) (string, error) {
+	name := to + strings.TrimPrefix(hr.Name, from)
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Sprintf("invalid name %s: %s", name, strings.Join(errs, ", ")), nil
+	}
+
+	target := &helmv2.HelmRelease{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: hr.Namespace, Name: name}, target)
+	switch {
+	case apierrors.IsNotFound(err):
+		target = nil
+	case err != nil:
+		return "", err
+	case target.Annotations[MigratedFromAnnotation] != hr.Name:
+		return fmt.Sprintf("HelmRelease %s already exists", name), nil
+	}
+
+	// Suspend the old HelmRelease, so that it does not reconcile the Helm
+	// release along with the new one and keeps it when deleted
+	if _, ok := hr.Annotations[migrationSuspendAnnotation]; !ok || !hr.Spec.Suspend {
+		if !ok {
+			if hr.Annotations == nil {
+				hr.Annotations = map[string]string{}
+			}
+			hr.Annotations[migrationSuspendAnnotation] = strconv.FormatBool(hr.Spec.Suspend)
+		}
+		hr.Spec.Suspend = true
+		if err := r.Update(ctx, hr); err != nil {
+			return "", err
+		}
+	}
+
+	if target == nil {
+		if err := r.Create(ctx, migratedHelmRelease(hr, name)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := r.Delete(ctx, hr); err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	return "", nil
+}
+
+// migratedHelmRelease returns a copy of the suspended hr named name, managing
+// the same Helm release and suspended as hr was before the migration
+func migratedHelmRelease(hr *helmv2.HelmRelease, name string) *helmv2.HelmRelease {
+	suspend, _ := strconv.ParseBool(hr.Annotations[migrationSuspendAnnotation])
+
+	annotations := map[string]string{}
+	for k, v := range hr.Annotations {
+		if k != migrationSuspendAnnotation {
+			annotations[k] = v
+		}
+	}
+	annotations[MigratedFromAnnotation] = hr.Name
+
+	labels := map[string]string{}
+	for k, v := range hr.Labels {
+		labels[k] = v
+	}
+
+	migrated := &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   hr.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: *hr.Spec.DeepCopy(),
+	}
+	migrated.Spec.ReleaseName = hr.GetReleaseName()
+	migrated.Spec.Suspend = suspend
+	return migrated
+}
+
+// updateStatus stores the progress of the migration if it changed
+func (r *CozystackResourceDefinitionPrefixMigrationReconciler) updateStatus(ctx context.Context, crd *cozyv1alpha1.CozystackResourceDefinition, status *cozyv1alpha1.PrefixMigrationStatus) error {
+	if current := crd.Status.PrefixMigration; current != nil {
+		status.LastUpdateTime = current.LastUpdateTime
+		if reflect.DeepEqual(current, status) {
+			return nil
+		}
+	}
+	now := metav1.Now()
+	status.LastUpdateTime = &now
+	crd.Status.PrefixMigration = status
+	return r.Status().Update(ctx, crd)
+}
+
+func (r *CozystackResourceDefinitionPrefixMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("cozystackresourcedefinition-prefixmigration-reconciler").
+		For(&cozyv1alpha1.CozystackResourceDefinition{}).
+		Complete(r)
+}
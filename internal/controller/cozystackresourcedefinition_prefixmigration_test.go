@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+)
+
+func TestCozystackResourceDefinitionPrefixMigrationReconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cozyv1alpha1.AddToScheme(scheme)
+	_ = helmv2.AddToScheme(scheme)
+
+	labels := map[string]string{
+		"apps.cozystack.io/application.kind":  "Postgres",
+		"apps.cozystack.io/application.group": "apps.cozystack.io",
+		"apps.cozystack.io/application.name":  "db",
+	}
+	release := func(name string, annotations map[string]string) *helmv2.HelmRelease {
+		return &helmv2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tenant-root", Labels: labels, Annotations: annotations},
+		}
+	}
+	crd := &cozyv1alpha1.CozystackResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "postgres",
+			Annotations: map[string]string{cozyv1alpha1.MigrateReleasePrefixFromAnnotation: "postgres-"},
+		},
+		Spec: cozyv1alpha1.CozystackResourceDefinitionSpec{
+			Application: cozyv1alpha1.CozystackResourceDefinitionApplication{Kind: "Postgres"},
+			Release:     cozyv1alpha1.CozystackResourceDefinitionRelease{Prefix: "pg-"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(crd).
+		WithObjects(crd, release("postgres-db", nil), release("postgres-other", nil), release("pg-other", nil)).
+		Build()
+	r := &CozystackResourceDefinitionPrefixMigrationReconciler{Client: c, Scheme: scheme}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "postgres"}}
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	migrated := &helmv2.HelmRelease{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "tenant-root", Name: "pg-db"}, migrated); err != nil {
+		t.Fatalf("HelmRelease was not migrated: %v", err)
+	}
+	if migrated.Spec.ReleaseName != "postgres-db" {
+		t.Errorf("release name = %q, want postgres-db", migrated.Spec.ReleaseName)
+	}
+	if migrated.Spec.Suspend {
+		t.Error("migrated HelmRelease is suspended")
+	}
+	if migrated.Annotations[MigratedFromAnnotation] != "postgres-db" {
+		t.Errorf("migrated-from annotation = %q, want postgres-db", migrated.Annotations[MigratedFromAnnotation])
+	}
+	if _, ok := migrated.Annotations[migrationSuspendAnnotation]; ok {
+		t.Error("migration suspend annotation was copied")
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "tenant-root", Name: "postgres-db"}, &helmv2.HelmRelease{}); !apierrors.IsNotFound(err) {
+		t.Errorf("old HelmRelease was not deleted: %v", err)
+	}
+
+	// pg-other already exists, so postgres-other is left untouched
+	conflicting := &helmv2.HelmRelease{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "tenant-root", Name: "postgres-other"}, conflicting); err != nil {
+		t.Fatalf("conflicting HelmRelease was deleted: %v", err)
+	}
+	if conflicting.Spec.Suspend {
+		t.Error("conflicting HelmRelease was suspended")
+	}
+
+	got := &cozyv1alpha1.CozystackResourceDefinition{}
+	if err := c.Get(context.TODO(), req.NamespacedName, got); err != nil {
+		t.Fatalf("failed to get CRD: %v", err)
+	}
+	status := got.Status.PrefixMigration
+	if status == nil {
+		t.Fatal("migration status was not reported")
+	}
+	if status.Phase != cozyv1alpha1.PrefixMigrationFailed || status.Total != 2 || status.Migrated != 1 || len(status.Failed) != 1 {
+		t.Errorf("status = %+v, want Failed with 1 of 2 migrated and 1 failure", status)
+	}
+}
+
+func TestPrefixMigrationRejectsOverlappingPrefixes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cozyv1alpha1.AddToScheme(scheme)
+	_ = helmv2.AddToScheme(scheme)
+
+	crd := &cozyv1alpha1.CozystackResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "postgres",
+			Annotations: map[string]string{cozyv1alpha1.MigrateReleasePrefixFromAnnotation: "pg-"},
+		},
+		Spec: cozyv1alpha1.CozystackResourceDefinitionSpec{
+			Application: cozyv1alpha1.CozystackResourceDefinitionApplication{Kind: "Postgres"},
+			Release:     cozyv1alpha1.CozystackResourceDefinitionRelease{Prefix: "pg-new-"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(crd).WithObjects(crd).Build()
+	r := &CozystackResourceDefinitionPrefixMigrationReconciler{Client: c, Scheme: scheme}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "postgres"}}
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	got := &cozyv1alpha1.CozystackResourceDefinition{}
+	if err := c.Get(context.TODO(), req.NamespacedName, got); err != nil {
+		t.Fatalf("failed to get CRD: %v", err)
+	}
+	if got.Status.PrefixMigration == nil || got.Status.PrefixMigration.Phase != cozyv1alpha1.PrefixMigrationFailed {
+		t.Errorf("status = %+v, want Failed", got.Status.PrefixMigration)
+	}
+}
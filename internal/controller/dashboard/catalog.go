@@ -0,0 +1,221 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// CatalogDataKey is the ConfigMap key holding the catalog JSON
+	CatalogDataKey = "catalog.json"
+	// CatalogVersionKey is the ConfigMap key holding the catalog version stamp
+	CatalogVersionKey = "version"
+	// AnnotationCatalogVersion mirrors the version stamp on the ConfigMap metadata
+	AnnotationCatalogVersion = "dashboard.cozystack.io/catalog-version"
+
+	ResourceTypeCatalog = "catalog"
+)
+
+// Catalog describes the resource kinds offered by the dashboard.
+type Catalog struct {
+	// Version is a stamp of the content, it changes whenever the catalog does
+	Version string `json:"version"`
+	// Categories are the names of the categories the kinds are grouped in, sorted
+	Categories []string `json:"categories"`
+	// Kinds are the resource kinds, ordered by category, weight and kind
+	Kinds []CatalogKind `json:"kinds"`
+}
+
+// CatalogKind describes a single resource kind in the Catalog.
+type CatalogKind struct {
+	Name             string          `json:"name"`
+	Group            string          `json:"group"`
+	Version          string          `json:"version"`
+	Kind             string          `json:"kind"`
+	Plural           string          `json:"plural"`
+	Singular         string          `json:"singular"`
+	DisplayName      string          `json:"displayName"`
+	DisplayPlural    string          `json:"displayPlural"`
+	Description      string          `json:"description,omitempty"`
+	Category         string          `json:"category"`
+	Tags             []string        `json:"tags,omitempty"`
+	Icon             string          `json:"icon,omitempty"`
+	Weight           int             `json:"weight,omitempty"`
+	Module           bool            `json:"module,omitempty"`
+	SingularResource bool            `json:"singularResource,omitempty"`
+	Schema           json.RawMessage `json:"schema,omitempty"`
+}
+
+// CatalogReconciler renders the CozystackResourceDefinitions with dashboard
+// configuration into a single catalog ConfigMap. The ConfigMap is replaced in
+// one update together with its version stamp, so the dashboard can pick up
+// new kinds without being restarted.
+type CatalogReconciler struct {
+	client.Client
+	// APIReader reads the ConfigMap, so that ConfigMaps are not cached cluster-wide
+	APIReader client.Reader
+	// Namespace and Name of the catalog ConfigMap
+	Namespace string
+	Name      string
+}
+
+func (r *CatalogReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("dashboard-catalog").
+		Watches(
+			&cozyv1alpha1.CozystackResourceDefinition{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{
+					NamespacedName: types.NamespacedName{Namespace: r.Namespace, Name: r.Name},
+				}}
+			}),
+		).
+		Complete(r)
+}
+
+func (r *CatalogReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+
+	var crdList cozyv1alpha1.CozystackResourceDefinitionList
+	if err := r.List(ctx, &crdList); err != nil {
+		return ctrl.Result{}, err
+	}
+	catalog, err := BuildCatalog(crdList.Items)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	data, err := json.Marshal(catalog)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	cm := &corev1.ConfigMap{}
+	err = r.APIReader.Get(ctx, req.NamespacedName, cm)
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{}
+		cm.Namespace = req.Namespace
+		cm.Name = req.Name
+		setCatalogData(cm, catalog.Version, data)
+		if err := r.Create(ctx, cm); err != nil {
+			return ctrl.Result{}, err
+		}
+		l.Info("Created dashboard catalog", "version", catalog.Version, "kinds", len(catalog.Kinds))
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if cm.Data[CatalogVersionKey] == catalog.Version && cm.Annotations[AnnotationCatalogVersion] == catalog.Version {
+		return ctrl.Result{}, nil
+	}
+	oldVersion := cm.Data[CatalogVersionKey]
+	setCatalogData(cm, catalog.Version, data)
+	// Update rather than patch: the resourceVersion guards against overwriting
+	// a concurrent change, and data and version are replaced together
+	if err := r.Update(ctx, cm); err != nil {
+		return ctrl.Result{}, err
+	}
+	l.Info("Updated dashboard catalog", "old", oldVersion, "new", catalog.Version, "kinds", len(catalog.Kinds))
+	return ctrl.Result{}, nil
+}
+
+// setCatalogData replaces the catalog in cm
+func setCatalogData(cm *corev1.ConfigMap, version string, data []byte) {
+	if cm.Labels == nil {
+		cm.Labels = map[string]string{}
+	}
+	cm.Labels[LabelManagedBy] = ManagedByValue
+	cm.Labels[LabelResourceType] = ResourceTypeCatalog
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Annotations[AnnotationCatalogVersion] = version
+	cm.Data = map[string]string{
+		CatalogDataKey:    string(data),
+		CatalogVersionKey: version,
+	}
+}
+
+// BuildCatalog renders the CozystackResourceDefinitions with dashboard
+// configuration into a Catalog. The result does not depend on the order of crds.
+func BuildCatalog(crds []cozyv1alpha1.CozystackResourceDefinition) (*Catalog, error) {
+	catalog := &Catalog{Categories: []string{}, Kinds: []CatalogKind{}}
+	categories := map[string]bool{}
+
+	for i := range crds {
+		crd := &crds[i]
+		dash := crd.Spec.Dashboard
+		if dash == nil {
+			continue
+		}
+		g, v, kind := pickGVK(crd)
+		item := CatalogKind{
+			Name:             crd.Name,
+			Group:            g,
+			Version:          v,
+			Kind:             kind,
+			Plural:           pickPlural(kind, crd),
+			Singular:         crd.Spec.Application.Singular,
+			DisplayName:      dash.Singular,
+			DisplayPlural:    dash.Plural,
+			Description:      dash.Description,
+			Category:         dash.Category,
+			Tags:             dash.Tags,
+			Icon:             dash.Icon,
+			Weight:           dash.Weight,
+			Module:           dash.Module,
+			SingularResource: dash.SingularResource,
+		}
+		// An invalid schema is left out rather than breaking the whole catalog;
+		// cozystack-api refuses to serve such a definition anyway
+		if s := crd.Spec.Application.OpenAPISchema; s != "" && json.Valid([]byte(s)) {
+			item.Schema = json.RawMessage(s)
+		}
+		catalog.Kinds = append(catalog.Kinds, item)
+		if dash.Category != "" {
+			categories[dash.Category] = true
+		}
+	}
+
+	sort.Slice(catalog.Kinds, func(i, j int) bool {
+		a, b := catalog.Kinds[i], catalog.Kinds[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Weight != b.Weight {
+			return a.Weight < b.Weight
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	for category := range categories {
+		catalog.Categories = append(catalog.Categories, category)
+	}
+	sort.Strings(catalog.Categories)
+
+	// The version is a hash of the content without the version itself
+	content, err := json.Marshal(catalog)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	catalog.Version = hex.EncodeToString(sum[:8])
+	return catalog, nil
+}
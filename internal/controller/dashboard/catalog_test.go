@@ -0,0 +1,82 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+)
+
+func catalogTestCRD(name, kind, category string, weight int) cozyv1alpha1.CozystackResourceDefinition {
+	crd := cozyv1alpha1.CozystackResourceDefinition{}
+	crd.Name = name
+	crd.Spec.Application.Kind = kind
+	crd.Spec.Application.OpenAPISchema = `{"type":"object"}`
+	crd.Spec.Dashboard = &cozyv1alpha1.CozystackResourceDefinitionDashboard{
+		Category: category,
+		Weight:   weight,
+	}
+	return crd
+}
+
+func TestBuildCatalog(t *testing.T) {
+	crds := []cozyv1alpha1.CozystackResourceDefinition{
+		catalogTestCRD("redis", "Redis", "PaaS", 20),
+		catalogTestCRD("bucket", "Bucket", "Storage", 0),
+		catalogTestCRD("postgres", "Postgres", "PaaS", 10),
+		{}, // no dashboard configuration
+	}
+	crds[0].Spec.Application.OpenAPISchema = "{not json"
+
+	catalog, err := BuildCatalog(crds)
+	if err != nil {
+		t.Fatalf("BuildCatalog: %v", err)
+	}
+
+	var kinds []string
+	for _, k := range catalog.Kinds {
+		kinds = append(kinds, k.Kind)
+	}
+	if got, want := kinds, []string{"Postgres", "Redis", "Bucket"}; !slices.Equal(got, want) {
+		t.Errorf("kinds = %v, want %v", got, want)
+	}
+	if got, want := catalog.Categories, []string{"PaaS", "Storage"}; !slices.Equal(got, want) {
+		t.Errorf("categories = %v, want %v", got, want)
+	}
+	if catalog.Kinds[1].Schema != nil {
+		t.Errorf("invalid schema should be left out, got %s", catalog.Kinds[1].Schema)
+	}
+	if string(catalog.Kinds[0].Schema) != `{"type":"object"}` {
+		t.Errorf("schema = %s", catalog.Kinds[0].Schema)
+	}
+	if _, err := json.Marshal(catalog); err != nil {
+		t.Errorf("catalog does not encode: %v", err)
+	}
+}
+
+func TestBuildCatalogVersion(t *testing.T) {
+	a := catalogTestCRD("postgres", "Postgres", "PaaS", 10)
+	b := catalogTestCRD("bucket", "Bucket", "Storage", 0)
+
+	first, err := BuildCatalog([]cozyv1alpha1.CozystackResourceDefinition{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reordered, err := BuildCatalog([]cozyv1alpha1.CozystackResourceDefinition{b, a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != reordered.Version {
+		t.Errorf("version depends on the order of definitions: %s != %s", first.Version, reordered.Version)
+	}
+
+	b.Spec.Dashboard.Description = "S3 compatible storage"
+	changed, err := BuildCatalog([]cozyv1alpha1.CozystackResourceDefinition{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Version == changed.Version {
+		t.Errorf("version did not change with the content: %s", first.Version)
+	}
+}
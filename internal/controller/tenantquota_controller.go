@@ -0,0 +1,376 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+const (
+	// TenantQuotaName is the name of the ResourceQuota of a tenant namespace
+	TenantQuotaName = "tenant-quota"
+	// TenantLimitRangeName is the name of the LimitRange of a tenant namespace
+	TenantLimitRangeName = "tenant-range-limits"
+	// TenantQuotaLabel marks the ResourceQuota and LimitRange managed by the TenantQuotaReconciler
+	TenantQuotaLabel = "internal.cozystack.io/tenantquota"
+
+	tenantKind          = "Tenant"
+	tenantPrefix        = "tenant-"
+	tenantRootNamespace = "tenant-root"
+	cozystackValues     = "cozystack-values"
+
+	defaultCPUAllocationRatio              = 10
+	defaultMemoryAllocationRatio           = 1
+	defaultEphemeralStorageAllocationRatio = 40
+)
+
+// rawQuotaKeys are the quota resources that are not split into requests and limits
+var rawQuotaKeys = map[string]bool{
+	"pods":                   true,
+	"services":               true,
+	"services.loadbalancers": true,
+	"services.nodeports":     true,
+	"services.clusterip":     true,
+	"configmaps":             true,
+	"secrets":                true,
+	"persistentvolumeclaims": true,
+	"replicationcontrollers": true,
+	"resourcequotas":         true,
+}
+
+// TenantQuotaReconciler materializes the resourceQuotas of a Tenant as a
+// ResourceQuota and a LimitRange in the tenant namespace. The namespace
+// belongs to another tenant than the HelmRelease, so the objects cannot be
+// owned by it and are deleted explicitly instead.
+type TenantQuotaReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// allocationRatios are the overcommit ratios of the cluster, dividing limits into requests
+type allocationRatios struct {
+	CPU              float64
+	Memory           float64
+	EphemeralStorage float64
+}
+
+// +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=resourcequotas;limitranges,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets;namespaces,verbs=get;list;watch
+
+func (r *TenantQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	namespace, ok := tenantNamespaceName(req.Namespace, req.Name)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	hr := &helmv2.HelmRelease{}
+	if err := r.Get(ctx, req.NamespacedName, hr); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		hr = nil
+	}
+	if hr == nil || !hr.DeletionTimestamp.IsZero() || hr.Labels[appsv1alpha1.ApplicationKindLabel] != tenantKind {
+		return ctrl.Result{}, r.cleanup(ctx, namespace)
+	}
+
+	quotas, err := tenantResourceQuotas(hr)
+	if err != nil {
+		log.Error(err, "invalid resourceQuotas", "helmrelease", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	if len(quotas) == 0 {
+		return ctrl.Result{}, r.cleanup(ctx, namespace)
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Reconciled again once the tenant chart creates the namespace
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !ns.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	ratios, err := r.allocationRatios(ctx, hr.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	hard, err := flattenQuotas(quotas, ratios)
+	if err != nil {
+		log.Error(err, "invalid resourceQuotas", "helmrelease", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	quota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: TenantQuotaName, Namespace: namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, quota, func() error {
+		setTenantQuotaLabel(quota)
+		quota.Spec.Hard = hard
+		return nil
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("reconciled ResourceQuota", "namespace", namespace, "operation", op)
+	}
+
+	limitRange := &corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: TenantLimitRangeName, Namespace: namespace}}
+	op, err = controllerutil.CreateOrUpdate(ctx, r.Client, limitRange, func() error {
+		setTenantQuotaLabel(limitRange)
+		limitRange.Spec.Limits = tenantLimits()
+		return nil
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Info("reconciled LimitRange", "namespace", namespace, "operation", op)
+	}
+	return ctrl.Result{}, nil
+}
+
+// cleanup deletes the ResourceQuota and LimitRange of the tenant namespace,
+// unless they were created by someone else
+func (r *TenantQuotaReconciler) cleanup(ctx context.Context, namespace string) error {
+	for _, obj := range []client.Object{
+		&corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: TenantQuotaName, Namespace: namespace}},
+		&corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: TenantLimitRangeName, Namespace: namespace}},
+	} {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if obj.GetLabels()[TenantQuotaLabel] != "true" {
+			continue
+		}
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		log.FromContext(ctx).Info("deleted tenant quota object", "namespace", namespace, "name", obj.GetName())
+	}
+	return nil
+}
+
+// allocationRatios reads the allocation ratios from the _cluster values of the
+// cozystack-values Secret in the namespace, falling back to the defaults
+func (r *TenantQuotaReconciler) allocationRatios(ctx context.Context, namespace string) (allocationRatios, error) {
+	ratios := allocationRatios{
+		CPU:              defaultCPUAllocationRatio,
+		Memory:           defaultMemoryAllocationRatio,
+		EphemeralStorage: defaultEphemeralStorageAllocationRatio,
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: cozystackValues}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ratios, nil
+		}
+		return ratios, err
+	}
+	var values struct {
+		Cluster map[string]interface{} `json:"_cluster"`
+	}
+	if err := yaml.Unmarshal(secret.Data["values.yaml"], &values); err != nil {
+		return ratios, fmt.Errorf("failed to parse %s/%s: %w", namespace, cozystackValues, err)
+	}
+	for key, ratio := range map[string]*float64{
+		"cpu-allocation-ratio":               &ratios.CPU,
+		"memory-allocation-ratio":            &ratios.Memory,
+		"ephemeral-storage-allocation-ratio": &ratios.EphemeralStorage,
+	} {
+		v, ok := values.Cluster[key]
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+		if err != nil || f <= 0 {
+			return ratios, fmt.Errorf("invalid %s %q in %s/%s", key, fmt.Sprint(v), namespace, cozystackValues)
+		}
+		*ratio = f
+	}
+	return ratios, nil
+}
+
+// tenantResourceQuotas returns the resourceQuotas values of the Tenant HelmRelease
+func tenantResourceQuotas(hr *helmv2.HelmRelease) (map[string]resource.Quantity, error) {
+	if hr.Spec.Values == nil || len(hr.Spec.Values.Raw) == 0 {
+		return nil, nil
+	}
+	var values struct {
+		ResourceQuotas map[string]resource.Quantity `json:"resourceQuotas"`
+	}
+	if err := json.Unmarshal(hr.Spec.Values.Raw, &values); err != nil {
+		return nil, err
+	}
+	return values.ResourceQuotas, nil
+}
+
+// flattenQuotas converts resourceQuotas into the hard limits of a ResourceQuota
+// the same way cozy-lib.resources.flatten does: every value is a limit, and
+// requests of cpu, memory and ephemeral-storage are divided by the allocation
+// ratios. Object counts are used as is and storage only has a request.
+func flattenQuotas(quotas map[string]resource.Quantity, ratios allocationRatios) (corev1.ResourceList, error) {
+	if _, ok := quotas["requests"]; ok {
+		return nil, fmt.Errorf("a flat map of resources expected, not nested requests or limits sections")
+	}
+	if _, ok := quotas["limits"]; ok {
+		return nil, fmt.Errorf("a flat map of resources expected, not nested requests or limits sections")
+	}
+
+	hard := corev1.ResourceList{}
+	for name, q := range quotas {
+		switch {
+		case rawQuotaKeys[name]:
+			hard[corev1.ResourceName(name)] = q
+		case name == string(corev1.ResourceStorage):
+			hard[corev1.ResourceRequestsStorage] = q
+		case name == string(corev1.ResourceCPU):
+			hard[corev1.ResourceLimitsCPU] = q
+			hard[corev1.ResourceRequestsCPU] = *resource.NewMilliQuantity(int64(float64(q.MilliValue())/ratios.CPU), resource.DecimalSI)
+		case name == string(corev1.ResourceMemory):
+			hard[corev1.ResourceLimitsMemory] = q
+			hard[corev1.ResourceRequestsMemory] = *resource.NewQuantity(int64(float64(q.Value())/ratios.Memory), resource.DecimalSI)
+		case name == string(corev1.ResourceEphemeralStorage):
+			hard[corev1.ResourceLimitsEphemeralStorage] = q
+			hard[corev1.ResourceRequestsEphemeralStorage] = *resource.NewQuantity(int64(float64(q.Value())/ratios.EphemeralStorage), resource.DecimalSI)
+		default:
+			hard[corev1.ResourceName("requests."+name)] = q
+			hard[corev1.ResourceName("limits."+name)] = q
+		}
+	}
+	return hard, nil
+}
+
+// tenantLimits are the container defaults of every tenant namespace with quotas
+func tenantLimits() []corev1.LimitRangeItem {
+	return []corev1.LimitRangeItem{{
+		Type: corev1.LimitTypeContainer,
+		Default: corev1.ResourceList{
+			corev1.ResourceCPU:              resource.MustParse("250m"),
+			corev1.ResourceMemory:           resource.MustParse("128Mi"),
+			corev1.ResourceEphemeralStorage: resource.MustParse("2Gi"),
+		},
+		DefaultRequest: corev1.ResourceList{
+			corev1.ResourceCPU:              resource.MustParse("25m"),
+			corev1.ResourceMemory:           resource.MustParse("128Mi"),
+			corev1.ResourceEphemeralStorage: resource.MustParse("50Mi"),
+		},
+	}}
+}
+
+func setTenantQuotaLabel(obj client.Object) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[TenantQuotaLabel] = "true"
+	obj.SetLabels(labels)
+}
+
+// tenantNamespaceName returns the namespace of the tenant released as name in
+// namespace, mirroring the tenant.name helper of the tenant chart: tenant foo
+// of the root tenant lives in tenant-foo, and tenant bar of tenant-foo lives
+// in tenant-foo-bar.
+func tenantNamespaceName(namespace, name string) (string, bool) {
+	tenant := strings.TrimPrefix(name, tenantPrefix)
+	if tenant == name || tenant == "" || strings.Contains(tenant, "-") || !strings.HasPrefix(namespace, tenantPrefix) {
+		return "", false
+	}
+	if namespace == tenantRootNamespace {
+		return tenantPrefix + tenant, true
+	}
+	return namespace + "-" + tenant, true
+}
+
+// tenantReleaseFor is the inverse of tenantNamespaceName, returning the
+// HelmRelease of the tenant living in namespace
+func tenantReleaseFor(namespace string) (types.NamespacedName, bool) {
+	tenant := strings.TrimPrefix(namespace, tenantPrefix)
+	if tenant == namespace || tenant == "" || namespace == tenantRootNamespace {
+		return types.NamespacedName{}, false
+	}
+	i := strings.LastIndex(tenant, "-")
+	if i < 0 {
+		return types.NamespacedName{Namespace: tenantRootNamespace, Name: namespace}, true
+	}
+	return types.NamespacedName{Namespace: tenantPrefix + tenant[:i], Name: tenantPrefix + tenant[i+1:]}, true
+}
+
+// SetupWithManager registers our controller with the Manager and sets up watches.
+func (r *TenantQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isTenant := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[appsv1alpha1.ApplicationKindLabel] == tenantKind
+	})
+	isQuota := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == TenantQuotaName || obj.GetName() == TenantLimitRangeName
+	})
+	isValues := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == cozystackValues
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("tenantquota").
+		For(&helmv2.HelmRelease{}, builder.WithPredicates(isTenant)).
+		Watches(&corev1.ResourceQuota{}, handler.EnqueueRequestsFromMapFunc(r.releaseOfNamespace), builder.WithPredicates(isQuota)).
+		Watches(&corev1.LimitRange{}, handler.EnqueueRequestsFromMapFunc(r.releaseOfNamespace), builder.WithPredicates(isQuota)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.releaseOfNamespace)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.tenantsInNamespace), builder.WithPredicates(isValues)).
+		Complete(r)
+}
+
+// releaseOfNamespace maps a tenant namespace, or an object in it, to the Tenant HelmRelease
+func (r *TenantQuotaReconciler) releaseOfNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	namespace := obj.GetNamespace()
+	if _, ok := obj.(*corev1.Namespace); ok {
+		namespace = obj.GetName()
+	}
+	key, ok := tenantReleaseFor(namespace)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: key}}
+}
+
+// tenantsInNamespace maps the cozystack-values Secret to the Tenant HelmReleases
+// in its namespace, which depend on its allocation ratios
+func (r *TenantQuotaReconciler) tenantsInNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	var hrList helmv2.HelmReleaseList
+	if err := r.List(ctx, &hrList, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{appsv1alpha1.ApplicationKindLabel: tenantKind}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list tenants", "namespace", obj.GetNamespace())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(hrList.Items))
+	for _, hr := range hrList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&hr)})
+	}
+	return requests
+}
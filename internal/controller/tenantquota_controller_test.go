@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+func TestTenantNamespaceName(t *testing.T) {
+	tests := []struct {
+		namespace, name, want string
+		ok                    bool
+	}{
+		{"tenant-root", "tenant-foo", "tenant-foo", true},
+		{"tenant-foo", "tenant-bar", "tenant-foo-bar", true},
+		{"tenant-root", "tenant-foo-bar", "", false},
+		{"tenant-root", "foo", "", false},
+		{"default", "tenant-foo", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tenantNamespaceName(tt.namespace, tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("tenantNamespaceName(%q, %q) = %q, %v, want %q, %v", tt.namespace, tt.name, got, ok, tt.want, tt.ok)
+		}
+		if !ok {
+			continue
+		}
+		key, ok := tenantReleaseFor(got)
+		if !ok || key.Namespace != tt.namespace || key.Name != tt.name {
+			t.Errorf("tenantReleaseFor(%q) = %v, %v, want %s/%s", got, key, ok, tt.namespace, tt.name)
+		}
+	}
+}
+
+func TestFlattenQuotas(t *testing.T) {
+	quotas := map[string]resource.Quantity{
+		"cpu":               resource.MustParse("2"),
+		"memory":            resource.MustParse("1Gi"),
+		"ephemeral-storage": resource.MustParse("40Gi"),
+		"storage":           resource.MustParse("100Gi"),
+		"services":          resource.MustParse("10"),
+		"nvidia.com/gpu":    resource.MustParse("1"),
+	}
+	ratios := allocationRatios{CPU: 10, Memory: 2, EphemeralStorage: 40}
+
+	hard, err := flattenQuotas(quotas, ratios)
+	if err != nil {
+		t.Fatalf("flattenQuotas returned error: %v", err)
+	}
+	want := map[corev1.ResourceName]string{
+		"limits.cpu":                 "2",
+		"requests.cpu":               "200m",
+		"limits.memory":              "1Gi",
+		"requests.memory":            "536870912",
+		"limits.ephemeral-storage":   "40Gi",
+		"requests.ephemeral-storage": "1073741824",
+		"requests.storage":           "100Gi",
+		"services":                   "10",
+		"requests.nvidia.com/gpu":    "1",
+		"limits.nvidia.com/gpu":      "1",
+	}
+	if len(hard) != len(want) {
+		t.Errorf("flattenQuotas returned %d resources, want %d: %v", len(hard), len(want), hard)
+	}
+	for name, value := range want {
+		got, ok := hard[name]
+		if !ok {
+			t.Errorf("flattenQuotas is missing %s", name)
+			continue
+		}
+		if got.String() != value {
+			t.Errorf("flattenQuotas()[%s] = %s, want %s", name, got.String(), value)
+		}
+	}
+
+	if _, err := flattenQuotas(map[string]resource.Quantity{"limits": resource.MustParse("1")}, ratios); err == nil {
+		t.Error("flattenQuotas accepted a nested limits section")
+	}
+}
+
+func TestTenantQuotaReconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = helmv2.AddToScheme(scheme)
+
+	hr := &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tenant-test",
+			Namespace: "tenant-root",
+			Labels:    map[string]string{appsv1alpha1.ApplicationKindLabel: tenantKind},
+		},
+		Spec: helmv2.HelmReleaseSpec{
+			Values: &apiextensionsv1.JSON{Raw: []byte(`{"resourceQuotas":{"cpu":"60","memory":"128Gi","storage":"100Gi"}}`)},
+		},
+	}
+	values := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: cozystackValues, Namespace: "tenant-root"},
+		Data:       map[string][]byte{"values.yaml": []byte("_cluster:\n  cpu-allocation-ratio: \"4\"\n")},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-test"}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(hr, values, ns).Build()
+	r := &TenantQuotaReconciler{Client: c, Scheme: scheme}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "tenant-test", Namespace: "tenant-root"}}
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	quota := &corev1.ResourceQuota{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: TenantQuotaName, Namespace: "tenant-test"}, quota); err != nil {
+		t.Fatalf("ResourceQuota was not created: %v", err)
+	}
+	if got := quota.Spec.Hard[corev1.ResourceRequestsCPU]; got.String() != "15" {
+		t.Errorf("requests.cpu = %s, want 15", got.String())
+	}
+	if got := quota.Spec.Hard[corev1.ResourceRequestsMemory]; got.String() != "137438953472" {
+		t.Errorf("requests.memory = %s, want 137438953472", got.String())
+	}
+	limitRange := &corev1.LimitRange{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: TenantLimitRangeName, Namespace: "tenant-test"}, limitRange); err != nil {
+		t.Fatalf("LimitRange was not created: %v", err)
+	}
+
+	// Removing the quotas from the Tenant deletes the objects
+	hr.Spec.Values = &apiextensionsv1.JSON{Raw: []byte(`{}`)}
+	if err := c.Update(context.TODO(), hr); err != nil {
+		t.Fatalf("failed to update HelmRelease: %v", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: TenantQuotaName, Namespace: "tenant-test"}, quota); !apierrors.IsNotFound(err) {
+		t.Errorf("expected ResourceQuota to be deleted, got err=%v", err)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: TenantLimitRangeName, Namespace: "tenant-test"}, limitRange); !apierrors.IsNotFound(err) {
+		t.Errorf("expected LimitRange to be deleted, got err=%v", err)
+	}
+}
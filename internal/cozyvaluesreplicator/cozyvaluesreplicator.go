@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,7 +40,8 @@ import (
 // SecretReplicatorReconciler replicates a source secret to namespaces matching a label selector.
 type SecretReplicatorReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 
 	// Source of truth:
 	SourceNamespace string
@@ -142,6 +144,7 @@ func (r *SecretReplicatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	return ctrl.NewControllerManagedBy(mgr).
 		// (b) Watch all Secrets with the chosen name; this also ensures Secret objects are cached.
+		Named("cozystack-values-replicator").
 		For(&corev1.Secret{}, builder.WithPredicates(secretNameOnly)).
 
 		// (c) Add a second watch on Secret, but only for the source secret, and fan-out to all namespaces.
@@ -234,7 +237,7 @@ func (r *SecretReplicatorReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		},
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, replicatedSecret, func() error {
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, replicatedSecret, func() error {
 		// Copy the secret data and type from the source
 		replicatedSecret.Data = make(map[string][]byte)
 		for k, v := range originalSecret.Data {
@@ -265,8 +268,14 @@ func (r *SecretReplicatorReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if err != nil {
 		logger.Error(err, "Failed to create or update replicated secret",
 			"namespace", req.Namespace, "secret", req.Name)
+		r.Recorder.Eventf(originalSecret, corev1.EventTypeWarning, "ReplicationFailed",
+			"Failed to replicate secret to namespace %s: %v", req.Namespace, err)
 		return ctrl.Result{}, err
 	}
+	if op != controllerutil.OperationResultNone {
+		r.Recorder.Eventf(originalSecret, corev1.EventTypeNormal, "Replicated",
+			"Secret %s in namespace %s", op, req.Namespace)
+	}
 
 	return ctrl.Result{}, nil
 }
@@ -0,0 +1,97 @@
+package lineagecontrollerwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// TenantNamespacePrefix is the name prefix of tenant namespaces
+	TenantNamespacePrefix = "tenant-"
+	// TenantRootNamespace is the namespace of the root tenant
+	TenantRootNamespace = "tenant-root"
+
+	// TenantLabelPrefix prefixes one label per tenant the namespace belongs to,
+	// the tenant itself and all of its parents, e.g. tenant.cozystack.io/tenant-root
+	TenantLabelPrefix = "tenant.cozystack.io/"
+	// TenantTierLabel holds the depth of the tenant below the root tenant, which is tier 0
+	TenantTierLabel = "tenant.cozystack.io/tier"
+)
+
+// TenantNamespaceLabeler is a mutating webhook stamping tenant namespaces with
+// labels derived from their name, so that label selectors such as the one of the
+// cozystack-values replicator match them from the moment they are created.
+type TenantNamespaceLabeler struct {
+	// ExtraLabels are set on every tenant namespace in addition to the tenant labels
+	ExtraLabels map[string]string
+
+	decoder admission.Decoder
+}
+
+// SetupWithManager registers the handler with the webhook server.
+func (h *TenantNamespaceLabeler) SetupWithManager(mgr ctrl.Manager) error {
+	h.decoder = admission.NewDecoder(mgr.GetScheme())
+	mgr.GetWebhookServer().Register("/mutate-tenant-namespace", &admission.Webhook{Handler: h})
+	return nil
+}
+
+// Handle is called for each AdmissionReview that matches the webhook config.
+func (h *TenantNamespaceLabeler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("name", req.Name, "operation", req.Operation)
+
+	ns := &corev1.Namespace{}
+	if err := h.decoder.Decode(req, ns); err != nil {
+		return admission.Errored(400, fmt.Errorf("decode namespace: %w", err))
+	}
+	labels := tenantNamespaceLabels(ns.Name)
+	if labels == nil {
+		return admission.Allowed("not a tenant namespace")
+	}
+	if ns.Labels == nil {
+		ns.Labels = make(map[string]string)
+	}
+	for k, v := range h.ExtraLabels {
+		ns.Labels[k] = v
+	}
+	for k, v := range labels {
+		ns.Labels[k] = v
+	}
+
+	mutated, err := json.Marshal(ns)
+	if err != nil {
+		return admission.Errored(500, fmt.Errorf("marshal mutated namespace: %w", err))
+	}
+	logger.V(1).Info("labeled tenant namespace", "tier", labels[TenantTierLabel])
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutated)
+}
+
+// tenantNamespaceLabels returns the tenant labels of the namespace name, or nil
+// if it is not a tenant namespace. Tenant names cannot contain dashes, so the
+// namespace of tenant bar nested in tenant foo is tenant-foo-bar, and its
+// parents are tenant-foo and tenant-root.
+func tenantNamespaceLabels(name string) map[string]string {
+	if !strings.HasPrefix(name, TenantNamespacePrefix) {
+		return nil
+	}
+	labels := map[string]string{
+		TenantLabelPrefix + TenantRootNamespace: "",
+	}
+	if name == TenantRootNamespace {
+		labels[TenantTierLabel] = "0"
+		return labels
+	}
+	parts := strings.Split(name, "-")
+	for i := 2; i <= len(parts); i++ {
+		labels[TenantLabelPrefix+strings.Join(parts[:i], "-")] = ""
+	}
+	labels[TenantTierLabel] = strconv.Itoa(len(parts) - 1)
+	return labels
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// helmChartLayerMediaType is the media type of the layer holding the chart in an OCI Helm chart artifact
+	helmChartLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+	// LabelPackageSource is set on objects generated for a PackageSource to its name
+	LabelPackageSource = "cozystack.io/packagesource"
+)
+
+// ComponentArtifactName returns the name of the chart source of a component:
+// <packagesource>-<variant>-<componentname>, with dots replaced by dashes to
+// comply with Kubernetes naming requirements
+func ComponentArtifactName(packageSource, variant, component string) string {
+	return fmt.Sprintf("%s-%s-%s",
+		strings.ReplaceAll(packageSource, ".", "-"),
+		strings.ReplaceAll(variant, ".", "-"),
+		strings.ReplaceAll(component, ".", "-"))
+}
+
+// reconcileOCIRepositories creates an OCIRepository for every component of the
+// package source that references a published chart with chartRef, and deletes
+// the OCIRepositories of components that no longer do. Such components are
+// not part of the ArtifactGenerator.
+func (r *PackageSourceReconciler) reconcileOCIRepositories(ctx context.Context, packageSource *cozyv1alpha1.PackageSource) error {
+	logger := log.FromContext(ctx)
+
+	gvk, err := apiutil.GVKForObject(packageSource, r.Scheme)
+	if err != nil {
+		return fmt.Errorf("failed to get GVK for PackageSource: %w", err)
+	}
+
+	desired := map[string]bool{}
+	for _, variant := range packageSource.Spec.Variants {
+		for _, component := range variant.Components {
+			if component.ChartRef == nil {
+				continue
+			}
+			repo := &sourcev1.OCIRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ComponentArtifactName(packageSource.Name, variant.Name, component.Name),
+					Namespace: "cozy-system",
+					Labels: map[string]string{
+						LabelPackageSource: packageSource.Name,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: gvk.GroupVersion().String(),
+							Kind:       gvk.Kind,
+							Name:       packageSource.Name,
+							UID:        packageSource.UID,
+							Controller: func() *bool { b := true; return &b }(),
+						},
+					},
+				},
+				Spec: sourcev1.OCIRepositorySpec{
+					URL: component.ChartRef.URL,
+					Reference: &sourcev1.OCIRepositoryRef{
+						Tag:    component.ChartRef.Tag,
+						Digest: component.ChartRef.Digest,
+					},
+					LayerSelector: &sourcev1.OCILayerSelector{
+						MediaType: helmChartLayerMediaType,
+						Operation: sourcev1.OCILayerCopy,
+					},
+					Interval: metav1.Duration{Duration: 5 * time.Minute},
+				},
+			}
+			if component.ChartRef.SecretRef != "" {
+				repo.Spec.SecretRef = &fluxmeta.LocalObjectReference{Name: component.ChartRef.SecretRef}
+			}
+			if err := r.createOrUpdate(ctx, repo); err != nil {
+				return fmt.Errorf("failed to reconcile OCIRepository %s: %w", repo.Name, err)
+			}
+			desired[repo.Name] = true
+			logger.V(1).Info("reconciled OCIRepository for component",
+				"variant", variant.Name, logging.KeyComponent, component.Name, "url", component.ChartRef.URL)
+		}
+	}
+
+	var repos sourcev1.OCIRepositoryList
+	if err := r.List(ctx, &repos, client.InNamespace("cozy-system"), client.MatchingLabels{LabelPackageSource: packageSource.Name}); err != nil {
+		return fmt.Errorf("failed to list OCIRepositories: %w", err)
+	}
+	for i := range repos.Items {
+		repo := &repos.Items[i]
+		// Pinned sources are reconciled by reconcilePinnedSource
+		if desired[repo.Name] || repo.Labels[LabelPinnedSource] != "" || !metav1.IsControlledBy(repo, packageSource) {
+			continue
+		}
+		if err := r.Delete(ctx, repo); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete OCIRepository %s: %w", repo.Name, err)
+		}
+		logger.Info("deleted OCIRepository of removed component", "name", repo.Name)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/google/cel-go/cel"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterConfigMap is the ConfigMap in cozy-system holding the platform
+// configuration exposed to component conditions as cluster.config
+const clusterConfigMap = "cozystack"
+
+// conditionEnv declares the variables of component conditions:
+//
+//	values  the values of the component set in the Package
+//	pkg     the name and variant of the Package
+//	cluster facts about the cluster: config (the cozystack ConfigMap), talos
+//	        (whether the nodes run Talos Linux) and provider (the scheme of the
+//	        node provider IDs, e.g. aws or gce, empty without a cloud provider)
+var conditionEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("values", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("pkg", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("cluster", cel.MapType(cel.StringType, cel.DynType)),
+	)
+})
+
+// conditionPrograms caches the compiled component conditions by expression
+var conditionPrograms sync.Map
+
+// compileCondition compiles a component condition, which must evaluate to a bool
+func compileCondition(expr string) (cel.Program, error) {
+	if prg, ok := conditionPrograms.Load(expr); ok {
+		return prg.(cel.Program), nil
+	}
+	env, err := conditionEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("condition must evaluate to a bool, not %s", ast.OutputType())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	conditionPrograms.Store(expr, prg)
+	return prg, nil
+}
+
+// ValidateCondition reports whether expr is a valid component condition
+func ValidateCondition(expr string) error {
+	_, err := compileCondition(expr)
+	return err
+}
+
+// ClusterFacts are the facts about the cluster component conditions can check
+type ClusterFacts struct {
+	// Config is the data of the cozystack ConfigMap in cozy-system
+	Config map[string]string
+	// Talos is set when the nodes run Talos Linux
+	Talos bool
+	// Provider is the scheme of the provider ID of the nodes, e.g. aws
+	Provider string
+}
+
+// LoadClusterFacts reads the facts about the cluster. The nodes are assumed to
+// be alike, only one of them is read.
+func LoadClusterFacts(ctx context.Context, reader client.Reader) (*ClusterFacts, error) {
+	facts := &ClusterFacts{Config: map[string]string{}}
+
+	cm := &corev1.ConfigMap{}
+	err := reader.Get(ctx, types.NamespacedName{Name: clusterConfigMap, Namespace: "cozy-system"}, cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get ConfigMap %s: %w", clusterConfigMap, err)
+	}
+	for k, v := range cm.Data {
+		facts.Config[k] = v
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := reader.List(ctx, nodes, client.Limit(1)); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if len(nodes.Items) > 0 {
+		node := nodes.Items[0]
+		facts.Talos = strings.HasPrefix(node.Status.NodeInfo.OSImage, "Talos")
+		if provider, _, ok := strings.Cut(node.Spec.ProviderID, "://"); ok {
+			facts.Provider = provider
+		}
+	}
+	return facts, nil
+}
+
+// ConditionError is the error of a component condition that failed to compile
+// or evaluate, as opposed to a failure to read the facts about the cluster
+type ConditionError struct {
+	// Component is the name of the component
+	Component string
+	Err       error
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("failed to evaluate the condition of component %s: %v", e.Component, e.Err)
+}
+
+func (e *ConditionError) Unwrap() error {
+	return e.Err
+}
+
+// SelectComponents returns variant without the components of pkg whose
+// condition is false. Dependencies on these components are dropped, as they
+// are optional. The facts about the cluster are only read if a component has a
+// condition. Invalid conditions are reported as a *ConditionError.
+func SelectComponents(ctx context.Context, reader client.Reader, pkg *cozyv1alpha1.Package, variant *cozyv1alpha1.Variant) (*cozyv1alpha1.Variant, error) {
+	if !slices.ContainsFunc(variant.Components, func(c cozyv1alpha1.Component) bool { return c.When != "" }) {
+		return variant, nil
+	}
+	facts, err := LoadClusterFacts(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+	cluster := map[string]any{
+		"config":   facts.Config,
+		"talos":    facts.Talos,
+		"provider": facts.Provider,
+	}
+
+	selected := variant.DeepCopy()
+	selected.Components = nil
+	skipped := map[string]bool{}
+	for _, component := range variant.Components {
+		if component.When != "" {
+			ok, err := evalCondition(component.When, pkg, component.Name, cluster)
+			if err != nil {
+				return nil, &ConditionError{Component: component.Name, Err: err}
+			}
+			if !ok {
+				skipped[component.Name] = true
+				continue
+			}
+		}
+		selected.Components = append(selected.Components, *component.DeepCopy())
+	}
+	for i := range selected.Components {
+		if install := selected.Components[i].Install; install != nil && len(install.DependsOn) > 0 {
+			install.DependsOn = slices.DeleteFunc(install.DependsOn, func(dep string) bool { return skipped[dep] })
+		}
+	}
+	return selected, nil
+}
+
+// evalCondition evaluates the condition expr of a component of pkg
+func evalCondition(expr string, pkg *cozyv1alpha1.Package, component string, cluster map[string]any) (bool, error) {
+	prg, err := compileCondition(expr)
+	if err != nil {
+		return false, err
+	}
+	values := map[string]any{}
+	if c, ok := pkg.Spec.Components[component]; ok && c.Values != nil && len(c.Values.Raw) > 0 {
+		if err := json.Unmarshal(c.Values.Raw, &values); err != nil {
+			return false, fmt.Errorf("failed to decode values: %w", err)
+		}
+	}
+	variant := pkg.Spec.Variant
+	if variant == "" {
+		variant = DefaultVariant
+	}
+	out, _, err := prg.Eval(map[string]any{
+		"values":  values,
+		"pkg":     map[string]string{"name": pkg.Name, "variant": variant},
+		"cluster": cluster,
+	})
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition evaluated to %v, not a bool", out.Value())
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestSelectComponentsErrors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	pkg := &cozyv1alpha1.Package{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.monitoring"}}
+	variant := func(when string) *cozyv1alpha1.Variant {
+		return &cozyv1alpha1.Variant{
+			Name:       DefaultVariant,
+			Components: []cozyv1alpha1.Component{{Name: "grafana", When: when}},
+		}
+	}
+	errList := errors.New("connection refused")
+
+	cases := []struct {
+		name          string
+		when          string
+		listErr       error
+		wantCondition bool
+	}{
+		{name: "invalid expression", when: "cluster.talos &&", wantCondition: true},
+		{name: "failed evaluation", when: "values.missing == 1", wantCondition: true},
+		{name: "failed to list nodes", when: "cluster.talos", listErr: errList},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tc.listErr != nil {
+				builder = builder.WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						return tc.listErr
+					},
+				})
+			}
+			_, err := SelectComponents(context.TODO(), builder.Build(), pkg, variant(tc.when))
+			if err == nil {
+				t.Fatal("SelectComponents succeeded, want an error")
+			}
+			var conditionErr *ConditionError
+			if got := errors.As(err, &conditionErr); got != tc.wantCondition {
+				t.Errorf("SelectComponents error %v is a *ConditionError: %v, want %v", err, got, tc.wantCondition)
+			}
+			if tc.listErr != nil && !errors.Is(err, tc.listErr) {
+				t.Errorf("SelectComponents error = %v, want it to wrap %v", err, tc.listErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// compileValuesSchema returns a validator for the values schema of a component
+func compileValuesSchema(schema *apiextensionsv1.JSON) (validation.SchemaValidator, error) {
+	var props apiextensionsv1.JSONSchemaProps
+	if err := json.Unmarshal(schema.Raw, &props); err != nil {
+		return nil, fmt.Errorf("failed to decode schema: %w", err)
+	}
+	var internal apiextensions.JSONSchemaProps
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&props, &internal, nil); err != nil {
+		return nil, fmt.Errorf("failed to convert schema: %w", err)
+	}
+	validator, _, err := validation.NewSchemaValidator(&internal)
+	if err != nil {
+		return nil, err
+	}
+	return validator, nil
+}
+
+// ValidateValuesSchema reports whether schema is a valid values schema
+func ValidateValuesSchema(schema *apiextensionsv1.JSON) error {
+	_, err := compileValuesSchema(schema)
+	return err
+}
+
+// ValidateComponentValues validates the values set in pkg for the components
+// of variant against their values schemas. The errors point at the invalid
+// fields under spec.components.<name>.values.
+func ValidateComponentValues(pkg *cozyv1alpha1.Package, variant *cozyv1alpha1.Variant) field.ErrorList {
+	var errs field.ErrorList
+	for _, component := range variant.Components {
+		if component.ValuesSchema == nil {
+			continue
+		}
+		override, ok := pkg.Spec.Components[component.Name]
+		if !ok || override.Values == nil || len(override.Values.Raw) == 0 {
+			continue
+		}
+		path := field.NewPath("spec", "components").Key(component.Name).Child("values")
+		validator, err := compileValuesSchema(component.ValuesSchema)
+		if err != nil {
+			errs = append(errs, field.InternalError(path, fmt.Errorf("invalid values schema of component %s: %w", component.Name, err)))
+			continue
+		}
+		var values any
+		if err := json.Unmarshal(override.Values.Raw, &values); err != nil {
+			errs = append(errs, field.Invalid(path, string(override.Values.Raw), err.Error()))
+			continue
+		}
+		errs = append(errs, validation.ValidateCustomResource(path, values, validator)...)
+	}
+	return errs
+}
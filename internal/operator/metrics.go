@@ -0,0 +1,52 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Names of the controllers in the reconcile metrics
+const (
+	packageControllerName       = "package"
+	packageSourceControllerName = "packagesource"
+)
+
+var (
+	// packageHelmReleases is the number of HelmReleases generated for each Package
+	packageHelmReleases = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cozystack_operator_package_helmreleases",
+		Help: "Number of HelmReleases generated for a Package by its last successful reconciliation.",
+	}, []string{"package"})
+
+	// packageDependenciesWaiting is the number of dependencies a Package waits for
+	packageDependenciesWaiting = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cozystack_operator_package_dependencies_waiting",
+		Help: "Number of dependencies of a Package that are not ready yet. HelmReleases are not generated while it is above zero.",
+	}, []string{"package"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(packageHelmReleases, packageDependenciesWaiting)
+}
+
+// forgetPackageMetrics drops the series of a deleted Package
+func forgetPackageMetrics(name string) {
+	packageHelmReleases.DeleteLabelValues(name)
+	packageDependenciesWaiting.DeleteLabelValues(name)
+}
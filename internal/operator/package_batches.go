@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// DefaultMaxHelmReleasesPerReconcile is the number of HelmReleases of a Package
+// applied in a single reconciliation when PackageReconciler.MaxHelmReleasesPerReconcile is unset
+const DefaultMaxHelmReleasesPerReconcile = 50
+
+// helmReleaseBatchInterval is how long a Package waits before the next batch of
+// its HelmReleases is applied
+const helmReleaseBatchInterval = 2 * time.Second
+
+// helmReleaseBatch is the progress of applying the HelmReleases of a Package
+// with more HelmReleases than applied in a single reconciliation
+type helmReleaseBatch struct {
+	// digest identifies the desired HelmReleases being applied. The batches
+	// start over when they change.
+	digest string
+	// next is the index of the first HelmRelease of the next batch
+	next int
+}
+
+// nextHelmReleaseBatch returns the range of releases to apply in this
+// reconciliation, at most MaxHelmReleasesPerReconcile of them starting after
+// the last batch applied, and the digest of releases to pass to
+// finishHelmReleaseBatch. The digest is taken before releases are applied, as
+// applying them sets their server state.
+func (r *PackageReconciler) nextHelmReleaseBatch(pkg *cozyv1alpha1.Package, releases []*helmv2.HelmRelease) (start, end int, digest string) {
+	limit := r.currentTunables().MaxHelmReleasesPerReconcile
+	if len(releases) <= limit {
+		r.batches.Delete(pkg.Name)
+		return 0, len(releases), ""
+	}
+	digest = helmReleasesDigest(releases)
+	if v, ok := r.batches.Load(pkg.Name); ok {
+		if batch := v.(helmReleaseBatch); batch.digest == digest && batch.next < len(releases) {
+			start = batch.next
+		}
+	}
+	return start, min(start+limit, len(releases)), digest
+}
+
+// finishHelmReleaseBatch records that the releases before end were applied. It
+// reports whether all releases are applied.
+func (r *PackageReconciler) finishHelmReleaseBatch(pkg *cozyv1alpha1.Package, releases []*helmv2.HelmRelease, end int, digest string) bool {
+	if end >= len(releases) {
+		r.batches.Delete(pkg.Name)
+		return true
+	}
+	r.batches.Store(pkg.Name, helmReleaseBatch{digest: digest, next: end})
+	return false
+}
+
+// forgetHelmReleaseBatch drops the progress recorded for the Package name
+func (r *PackageReconciler) forgetHelmReleaseBatch(name string) {
+	r.batches.Delete(name)
+}
+
+// helmReleasesDigest returns a digest of the desired state of releases
+func helmReleasesDigest(releases []*helmv2.HelmRelease) string {
+	// HelmReleases always marshal
+	data, _ := json.Marshal(releases)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
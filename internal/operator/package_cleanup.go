@@ -0,0 +1,188 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// PackageFinalizer keeps a deleted Package until its HelmReleases are
+	// uninstalled and the namespaces created for it are deleted
+	PackageFinalizer = "operator.cozystack.io/package-cleanup"
+
+	// AnnotationCreatedByPackage records the Package a namespace was created for.
+	// Such namespaces are deleted with the Package once they are empty, unless
+	// they are tenant namespaces.
+	AnnotationCreatedByPackage = "operator.cozystack.io/created-by-package"
+
+	// cleanupRetryInterval is how often the cleanup of a deleted Package or
+	// PackageSource is checked
+	cleanupRetryInterval = 10 * time.Second
+)
+
+// reconcileDelete cleans up after a deleted Package: its HelmReleases are
+// deleted, and once Flux has uninstalled them the empty namespaces created for
+// the Package are deleted and the finalizer is removed
+func (r *PackageReconciler) reconcileDelete(ctx context.Context, pkg *cozyv1alpha1.Package) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(pkg, PackageFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	hrList := &helmv2.HelmReleaseList{}
+	if err := r.List(ctx, hrList, client.MatchingLabels{
+		"cozystack.io/package": pkg.Name,
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(hrList.Items) > 0 {
+		for i := range hrList.Items {
+			hr := &hrList.Items[i]
+			if !hr.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if err := r.Delete(ctx, hr); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to delete HelmRelease %s/%s: %w", hr.Namespace, hr.Name, err)
+			}
+			logger.Info("deleted HelmRelease", "name", hr.Name, logging.KeyNamespace, hr.Namespace)
+		}
+		meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Deleting",
+			Message: fmt.Sprintf("Waiting for %d helmrelease(s) to be uninstalled", len(hrList.Items)),
+		})
+		if err := r.Status().Update(ctx, pkg); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		// Deleted HelmReleases enqueue the Package, check again in case they are missed
+		return ctrl.Result{RequeueAfter: cleanupRetryInterval}, nil
+	}
+
+	if err := r.deleteCreatedNamespaces(ctx, pkg); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	controllerutil.RemoveFinalizer(pkg, PackageFinalizer)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Update(ctx, pkg))
+}
+
+// deleteCreatedNamespaces deletes the namespaces created for pkg that are
+// empty. Tenant namespaces and namespaces holding anything else are kept.
+func (r *PackageReconciler) deleteCreatedNamespaces(ctx context.Context, pkg *cozyv1alpha1.Package) error {
+	logger := log.FromContext(ctx)
+
+	// The cache only holds system namespaces
+	nsList := &corev1.NamespaceList{}
+	if err := r.reader().List(ctx, nsList); err != nil {
+		return err
+	}
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		if ns.Annotations[AnnotationCreatedByPackage] != pkg.Name || !ns.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if strings.HasPrefix(ns.Name, "tenant-") {
+			continue
+		}
+		empty, err := r.namespaceEmpty(ctx, ns.Name)
+		if err != nil {
+			return fmt.Errorf("failed to check namespace %s: %w", ns.Name, err)
+		}
+		if !empty {
+			r.Recorder.Eventf(pkg, corev1.EventTypeNormal, "NamespaceKept",
+				"Namespace %s created for the Package is not empty and is kept", ns.Name)
+			continue
+		}
+		if err := r.Delete(ctx, ns); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete namespace %s: %w", ns.Name, err)
+		}
+		logger.Info("deleted namespace", "name", ns.Name)
+		r.Recorder.Eventf(pkg, corev1.EventTypeNormal, "NamespaceDeleted", "Deleted namespace %s", ns.Name)
+	}
+	return nil
+}
+
+// namespaceDefaults are the objects Kubernetes creates in every namespace,
+// by kind and name
+var namespaceDefaults = map[string]string{
+	"ConfigMap":      "kube-root-ca.crt",
+	"ServiceAccount": "default",
+}
+
+// namespaceEmpty reports whether the namespace holds nothing but objects being
+// deleted, events and the objects Kubernetes creates in every namespace. Every
+// namespaced resource served by the cluster is checked, a namespace is only
+// reported empty when all of them could be listed.
+func (r *PackageReconciler) namespaceEmpty(ctx context.Context, namespace string) (bool, error) {
+	if r.Discovery == nil {
+		return false, nil
+	}
+	resourceLists, err := discovery.ServerPreferredNamespacedResources(r.Discovery)
+	if discovery.IsGroupDiscoveryFailedError(err) {
+		// The resources of an unavailable API can not be checked
+		log.FromContext(ctx).Info("keeping namespace, some APIs are unavailable", "name", namespace, "error", err.Error())
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	resourceLists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list"}}, resourceLists)
+
+	reader := r.reader()
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return false, err
+		}
+		for _, resource := range resourceList.APIResources {
+			if resource.Name == "events" || strings.Contains(resource.Name, "/") {
+				continue
+			}
+			list := &metav1.PartialObjectMetadataList{}
+			list.SetGroupVersionKind(gv.WithKind(resource.Kind + "List"))
+			if err := reader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+				return false, fmt.Errorf("failed to list %s: %w", resource.Name, err)
+			}
+			for _, item := range list.Items {
+				if !item.DeletionTimestamp.IsZero() || namespaceDefaults[resource.Kind] == item.Name {
+					continue
+				}
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateOrUpdateNamespaceRecordsCreatedNamespacesOnly(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	existing := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-root"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PackageReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+	pkg := &cozyv1alpha1.Package{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.monitoring"}}
+	ctx := context.TODO()
+
+	// Reconciled twice, the namespace is only created by the first one
+	for range 2 {
+		for _, name := range []string{"cozy-monitoring", "tenant-root"} {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      map[string]string{"cozystack.io/system": "true"},
+				Annotations: map[string]string{"helm.sh/resource-policy": "keep"},
+			}}
+			if err := r.createOrUpdateNamespace(ctx, pkg, namespace); err != nil {
+				t.Fatalf("createOrUpdateNamespace(%s): %v", name, err)
+			}
+		}
+	}
+
+	want := map[string]string{"cozy-monitoring": pkg.Name, "tenant-root": ""}
+	for name, createdBy := range want {
+		got := &corev1.Namespace{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, got); err != nil {
+			t.Fatalf("Get(%s): %v", name, err)
+		}
+		if got.Annotations[AnnotationCreatedByPackage] != createdBy {
+			t.Errorf("namespace %s: %s = %q, want %q", name, AnnotationCreatedByPackage, got.Annotations[AnnotationCreatedByPackage], createdBy)
+		}
+		if got.Labels["cozystack.io/system"] != "true" {
+			t.Errorf("namespace %s: labels = %v, want the applied ones", name, got.Labels)
+		}
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("recorded %d events, want a single NamespaceCreated", len(recorder.Events))
+	}
+}
+
+func TestNamespaceEmpty(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	resources := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: metav1.Verbs{"list"}},
+				{Name: "events", Kind: "Event", Namespaced: true, Verbs: metav1.Verbs{"list"}},
+				{Name: "namespaces", Kind: "Namespace", Verbs: metav1.Verbs{"list"}},
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"list"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+				{Name: "serviceaccounts", Kind: "ServiceAccount", Namespaced: true, Verbs: metav1.Verbs{"list"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: metav1.Verbs{"list"}},
+			},
+		},
+	}
+	defaults := []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "cozy-monitoring"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "cozy-monitoring"}},
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "grafana.1", Namespace: "cozy-monitoring"}},
+	}
+	now := metav1.Now()
+
+	cases := []struct {
+		name      string
+		objects   []client.Object
+		discovery bool
+		want      bool
+	}{
+		{
+			name:      "objects created in every namespace",
+			objects:   defaults,
+			discovery: true,
+			want:      true,
+		},
+		{
+			name: "deployment",
+			objects: append([]client.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "cozy-monitoring"}},
+			}, defaults...),
+			discovery: true,
+			want:      false,
+		},
+		{
+			name: "configmap",
+			objects: []client.Object{
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "cozy-monitoring"}},
+			},
+			discovery: true,
+			want:      false,
+		},
+		{
+			name: "pod being deleted",
+			objects: []client.Object{
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name:              "grafana-0",
+					Namespace:         "cozy-monitoring",
+					DeletionTimestamp: &now,
+					Finalizers:        []string{"example.com/keep"},
+				}},
+			},
+			discovery: true,
+			want:      true,
+		},
+		{
+			name:    "without discovery",
+			objects: defaults,
+			want:    false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			r := &PackageReconciler{Client: c, Scheme: scheme}
+			if tc.discovery {
+				r.Discovery = &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+			}
+			got, err := r.namespaceEmpty(context.TODO(), "cozy-monitoring")
+			if err != nil {
+				t.Fatalf("namespaceEmpty: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("namespaceEmpty = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// componentStatuses reports the state of the HelmReleases generated for the
+// components of a Package, combined with the health checks of the components of
+// variant and the revisions their artifacts were generated from, as recorded by
+// packageSource. HelmReleases are owned by the Package, so changes to their
+// status trigger a reconcile that refreshes it.
+func (r *PackageReconciler) componentStatuses(ctx context.Context, packageSource *cozyv1alpha1.PackageSource, releases []*helmv2.HelmRelease, components []string, variant *cozyv1alpha1.Variant) (map[string]cozyv1alpha1.ComponentStatus, error) {
+	if len(releases) == 0 {
+		return nil, nil
+	}
+	statuses := make(map[string]cozyv1alpha1.ComponentStatus, len(releases))
+	for i, release := range releases {
+		status := cozyv1alpha1.ComponentStatus{
+			HelmRelease: fmt.Sprintf("%s/%s", release.Namespace, release.Name),
+			Ready:       metav1.ConditionUnknown,
+		}
+		hr := &helmv2.HelmRelease{}
+		err := r.Get(ctx, client.ObjectKeyFromObject(release), hr)
+		if apierrors.IsNotFound(err) {
+			// Just created and not in the cache yet
+			status.Reason = "Pending"
+			status.Message = "HelmRelease has not been observed yet"
+			setArtifactRevision(&status, packageSource, release)
+			statuses[components[i]] = status
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		status = helmReleaseComponentStatus(hr)
+		setArtifactRevision(&status, packageSource, release)
+		for j := range variant.Components {
+			if variant.Components[j].Name != components[i] {
+				continue
+			}
+			if err := r.checkComponentHealth(ctx, &status, &variant.Components[j]); err != nil {
+				return nil, err
+			}
+		}
+		statuses[components[i]] = status
+	}
+	return statuses, nil
+}
+
+// setArtifactRevision sets the source revision and digest of the artifact
+// release installs, if packageSource generated it
+func setArtifactRevision(status *cozyv1alpha1.ComponentStatus, packageSource *cozyv1alpha1.PackageSource, release *helmv2.HelmRelease) {
+	if release.Spec.ChartRef == nil || release.Spec.ChartRef.Kind != sourcev1.ExternalArtifactKind {
+		return
+	}
+	if artifact := artifactStatus(packageSource, release.Spec.ChartRef.Name); artifact != nil {
+		status.SourceRevision = artifact.Revision
+		status.ArtifactDigest = artifact.Digest
+	}
+}
+
+// helmReleaseComponentStatus summarizes the status of hr
+func helmReleaseComponentStatus(hr *helmv2.HelmRelease) cozyv1alpha1.ComponentStatus {
+	status := cozyv1alpha1.ComponentStatus{
+		HelmRelease:  fmt.Sprintf("%s/%s", hr.Namespace, hr.Name),
+		Ready:        metav1.ConditionUnknown,
+		ChartVersion: hr.Status.LastAttemptedRevision,
+	}
+	if ready := meta.FindStatusCondition(hr.Status.Conditions, "Ready"); ready != nil {
+		status.Ready = ready.Status
+		status.Reason = ready.Reason
+		status.Message = ready.Message
+		t := ready.LastTransitionTime
+		status.LastReconcileTime = &t
+	} else {
+		status.Reason = "Progressing"
+		status.Message = "HelmRelease has not been reconciled yet"
+	}
+	if latest := hr.Status.History.Latest(); latest != nil {
+		status.ChartVersion = latest.ChartVersion
+		if status.LastReconcileTime == nil || status.LastReconcileTime.Before(&latest.LastDeployed) {
+			t := latest.LastDeployed
+			status.LastReconcileTime = &t
+		}
+	}
+	return status
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// healthCheckRetryInterval is how often a Package with failing health checks is rechecked.
+// Health check targets are not watched.
+const healthCheckRetryInterval = 30 * time.Second
+
+// +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;statefulsets,verbs=get
+
+// checkComponentHealth evaluates the health checks of component and downgrades
+// status to not ready if any of them fails. Health checks are only evaluated
+// once the HelmRelease is ready.
+func (r *PackageReconciler) checkComponentHealth(ctx context.Context, status *cozyv1alpha1.ComponentStatus, component *cozyv1alpha1.Component) error {
+	if component.Install == nil || len(component.Install.HealthChecks) == 0 || status.Ready != metav1.ConditionTrue {
+		return nil
+	}
+	namespace := component.Install.Namespace
+	if component.Install.TargetNamespace != "" {
+		namespace = component.Install.TargetNamespace
+	}
+
+	var failed []string
+	for _, check := range component.Install.HealthChecks {
+		result := cozyv1alpha1.HealthCheckStatus{
+			Kind:      check.Kind,
+			Namespace: check.Namespace,
+			Name:      check.Name,
+		}
+		if result.Namespace == "" {
+			result.Namespace = namespace
+		}
+		message, err := r.workloadHealth(ctx, check.Kind, types.NamespacedName{Namespace: result.Namespace, Name: check.Name})
+		if err != nil {
+			return err
+		}
+		result.Ready = message == ""
+		result.Message = message
+		if !result.Ready {
+			failed = append(failed, fmt.Sprintf("%s %s/%s: %s", check.Kind, result.Namespace, check.Name, message))
+		}
+		status.HealthChecks = append(status.HealthChecks, result)
+	}
+
+	if len(failed) > 0 {
+		status.Ready = metav1.ConditionFalse
+		status.Reason = "HealthCheckFailed"
+		status.Message = strings.Join(failed, "; ")
+	}
+	return nil
+}
+
+// workloadHealth returns why the workload is not ready, or an empty string if it is.
+// Workloads are read through the API reader, so that the operator does not cache
+// every workload of the cluster.
+func (r *PackageReconciler) workloadHealth(ctx context.Context, kind string, key types.NamespacedName) (string, error) {
+	var obj client.Object
+	switch kind {
+	case "Deployment":
+		obj = &appsv1.Deployment{}
+	case "DaemonSet":
+		obj = &appsv1.DaemonSet{}
+	case "StatefulSet":
+		obj = &appsv1.StatefulSet{}
+	default:
+		return fmt.Sprintf("unsupported kind %s", kind), nil
+	}
+
+	reader := r.reader()
+	if err := reader.Get(ctx, key, obj); apierrors.IsNotFound(err) {
+		return "not found", nil
+	} else if err != nil {
+		return "", err
+	}
+	return workloadNotReadyReason(obj), nil
+}
+
+// workloadNotReadyReason reports why a Deployment, DaemonSet or StatefulSet is
+// not rolled out and available, or returns an empty string if it is
+func workloadNotReadyReason(obj client.Object) string {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		if w.Status.ObservedGeneration < w.Generation {
+			return "rollout in progress"
+		}
+		replicas := int32(1)
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+		if w.Status.UpdatedReplicas < replicas {
+			return fmt.Sprintf("%d of %d replicas updated", w.Status.UpdatedReplicas, replicas)
+		}
+		if w.Status.AvailableReplicas < replicas {
+			return fmt.Sprintf("%d of %d replicas available", w.Status.AvailableReplicas, replicas)
+		}
+	case *appsv1.DaemonSet:
+		if w.Status.ObservedGeneration < w.Generation {
+			return "rollout in progress"
+		}
+		desired := w.Status.DesiredNumberScheduled
+		if w.Status.UpdatedNumberScheduled < desired {
+			return fmt.Sprintf("%d of %d pods updated", w.Status.UpdatedNumberScheduled, desired)
+		}
+		if w.Status.NumberAvailable < desired {
+			return fmt.Sprintf("%d of %d pods available", w.Status.NumberAvailable, desired)
+		}
+	case *appsv1.StatefulSet:
+		if w.Status.ObservedGeneration < w.Generation {
+			return "rollout in progress"
+		}
+		replicas := int32(1)
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+		if w.Status.UpdatedReplicas < replicas {
+			return fmt.Sprintf("%d of %d replicas updated", w.Status.UpdatedReplicas, replicas)
+		}
+		if w.Status.ReadyReplicas < replicas {
+			return fmt.Sprintf("%d of %d replicas ready", w.Status.ReadyReplicas, replicas)
+		}
+	}
+	return ""
+}
+
+// healthChecksFailing reports whether a health check of any component failed
+func healthChecksFailing(components map[string]cozyv1alpha1.ComponentStatus) bool {
+	for _, status := range components {
+		for _, check := range status.HealthChecks {
+			if !check.Ready {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Provenance annotations record where the chart content of a generated
+// HelmRelease came from
+const (
+	// AnnotationSourceRevision is the revision of the source artifact the chart
+	// is taken from: the source of the PackageSource, or the OCIRepository of
+	// a component referencing a published chart
+	AnnotationSourceRevision = "operator.cozystack.io/source-revision"
+	// AnnotationVariant is the PackageSource variant the HelmRelease belongs to
+	AnnotationVariant = "operator.cozystack.io/variant"
+	// AnnotationComponentPath is the path of the chart in the source, or the
+	// OCI reference of a published chart
+	AnnotationComponentPath = "operator.cozystack.io/component-path"
+	// AnnotationOperatorVersion is the version of the operator that generated
+	// the HelmRelease
+	AnnotationOperatorVersion = "operator.cozystack.io/operator-version"
+)
+
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=gitrepositories,verbs=get;list;watch
+
+// annotateProvenance stamps hr, generated for component of variantName, with
+// the provenance annotations. The source revision is best-effort: it is left
+// out if the source or its artifact cannot be read.
+func (r *PackageReconciler) annotateProvenance(ctx context.Context, hr *helmv2.HelmRelease, packageSource *cozyv1alpha1.PackageSource, variantName string, component *cozyv1alpha1.Component) {
+	hr.Annotations[AnnotationVariant] = variantName
+	if r.OperatorVersion != "" {
+		hr.Annotations[AnnotationOperatorVersion] = r.OperatorVersion
+	}
+
+	var kind string
+	var key types.NamespacedName
+	if ref := component.ChartRef; ref != nil {
+		hr.Annotations[AnnotationComponentPath] = chartReference(ref)
+		kind, key = sourcev1.OCIRepositoryKind, types.NamespacedName{Namespace: hr.Spec.ChartRef.Namespace, Name: hr.Spec.ChartRef.Name}
+	} else {
+		hr.Annotations[AnnotationComponentPath] = component.Path
+		// The revision recorded for the artifact accounts for pinned sources
+		if hr.Spec.ChartRef != nil {
+			if artifact := artifactStatus(packageSource, hr.Spec.ChartRef.Name); artifact != nil && artifact.Revision != "" {
+				hr.Annotations[AnnotationSourceRevision] = artifact.Revision
+				return
+			}
+		}
+		if ref := sourceRefByAlias(packageSource, component.Source); ref != nil {
+			kind, key = ref.Kind, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+		}
+	}
+	if kind == "" {
+		return
+	}
+	revision, err := sourceRevision(ctx, r.Client, kind, key)
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("failed to get source revision", "kind", kind, "source", key, "error", err.Error())
+		return
+	}
+	if revision != "" {
+		hr.Annotations[AnnotationSourceRevision] = revision
+	}
+}
+
+// sourceRevision returns the revision of the artifact of the Flux source of
+// kind named key, or an empty string if it has no artifact yet
+func sourceRevision(ctx context.Context, reader client.Reader, kind string, key types.NamespacedName) (string, error) {
+	var source interface {
+		client.Object
+		GetArtifact() *fluxmeta.Artifact
+	}
+	switch kind {
+	case sourcev1.GitRepositoryKind:
+		source = &sourcev1.GitRepository{}
+	case sourcev1.OCIRepositoryKind:
+		source = &sourcev1.OCIRepository{}
+	default:
+		return "", nil
+	}
+	if err := reader.Get(ctx, key, source); err != nil {
+		return "", err
+	}
+	if artifact := source.GetArtifact(); artifact != nil {
+		return artifact.Revision, nil
+	}
+	return "", nil
+}
+
+// chartReference returns the OCI reference of a published chart
+func chartReference(ref *cozyv1alpha1.ComponentChartRef) string {
+	if ref.Digest != "" {
+		return ref.URL + "@" + ref.Digest
+	}
+	return ref.URL + ":" + ref.Tag
+}
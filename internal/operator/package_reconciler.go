@@ -18,20 +18,35 @@ package operator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+	"github.com/cozystack/cozystack/internal/shared/reconcilemetrics"
+	"github.com/cozystack/cozystack/internal/sourceverify"
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/csaupgrade"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -43,37 +58,138 @@ const (
 	AnnotationSkipCozystackValues = "operator.cozystack.io/skip-cozystack-values"
 	// SecretCozystackValues is the name of the secret containing cluster and namespace configuration
 	SecretCozystackValues = "cozystack-values"
+	// AnnotationForceNamespaceOwnership makes the operator take ownership of conflicting namespace
+	// fields when applying namespaces with server-side apply
+	// This annotation should be placed on Package
+	AnnotationForceNamespaceOwnership = "operator.cozystack.io/force-namespace-ownership"
+	// AnnotationPackageSourceUID records the UID of the PackageSource a HelmRelease was generated from
+	// It is used to detect HelmReleases left behind by a renamed (deleted and recreated) PackageSource
+	AnnotationPackageSourceUID = "operator.cozystack.io/package-source-uid"
+
+	// DefaultMaxConcurrentHelmReleases is the number of HelmReleases of a Package
+	// created or updated in parallel when PackageReconciler.MaxConcurrentHelmReleases is unset
+	DefaultMaxConcurrentHelmReleases = 8
+
+	// AnnotationSuspendedByPackage marks the HelmReleases suspended because their
+	// Package is suspended. They are resumed with the Package, while HelmReleases
+	// suspended otherwise are left suspended.
+	AnnotationSuspendedByPackage = "operator.cozystack.io/suspended-by-package"
+
+	// packageFieldOwner is the field manager used for server-side apply of
+	// namespaces and HelmReleases
+	packageFieldOwner = "cozystack-package-controller"
+
+	// packageSuspendFieldOwner is the field manager of the patches suspending
+	// and resuming the HelmReleases of a Package
+	packageSuspendFieldOwner = "cozystack-package-suspend"
+
+	// verificationRetryInterval is how often a Package whose source failed verification is rechecked
+	verificationRetryInterval = time.Minute
 )
 
+// legacyHelmReleaseFieldManagers are the field managers of the HelmRelease
+// updates of earlier operator versions, named after the operator binary
+var legacyHelmReleaseFieldManagers = sets.New("cozystack-operator")
+
 // PackageReconciler reconciles Package resources
 type PackageReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// MaxConcurrentHelmReleases limits how many HelmReleases of a single Package
+	// are created or updated in parallel
+	MaxConcurrentHelmReleases int
+	// MaxHelmReleasesPerReconcile limits how many HelmReleases of a single Package
+	// are applied in one reconciliation, the others are applied after a requeue
+	MaxHelmReleasesPerReconcile int
+	// VerificationPolicy, if set, must be satisfied by the source of a PackageSource
+	// before any of its Packages are installed
+	VerificationPolicy *sourceverify.Policy
+	// RevisionHistoryLimit is the number of PackageRevisions kept per Package
+	RevisionHistoryLimit int
+	// APIReader reads the workloads targeted by component health checks, the
+	// namespaces created and cleaned up for Packages and the cluster facts
+	// checked by component conditions. Falls back to the cached client if unset.
+	APIReader client.Reader
+	// OperatorVersion is recorded on the generated HelmReleases
+	OperatorVersion string
+	// Shard selects the Packages reconciled by this replica, all of them if unset
+	Shard Shard
+	// Discovery lists the resources checked before deleting a namespace created
+	// for a deleted Package. Such namespaces are kept if it is unset.
+	Discovery discovery.DiscoveryInterface
+
+	// tunables override MaxConcurrentHelmReleases, MaxHelmReleasesPerReconcile
+	// and RevisionHistoryLimit once set
+	tunables atomic.Pointer[Tunables]
+	// batches holds the helmReleaseBatch of the Packages being applied in batches
+	batches sync.Map
 }
 
 // +kubebuilder:rbac:groups=cozystack.io,resources=packages,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cozystack.io,resources=packages/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cozystack.io,resources=packagesources,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cozystack.io,resources=packagerevisions,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods;persistentvolumeclaims;secrets,verbs=list
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=list
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=ocirepositories,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
+	// Packages of other shards are enqueued through the watches of their
+	// dependencies and HelmReleases, they are reconciled by another replica
+	if !r.Shard.Owns(req.Name) {
+		return ctrl.Result{}, nil
+	}
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	reconcilemetrics.Observe(packageControllerName, start, result, err)
+	return result, err
+}
+
+func (r *PackageReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues(logging.KeyPackage, req.Name)
+	ctx = log.IntoContext(ctx, logger)
 
 	pkg := &cozyv1alpha1.Package{}
 	if err := r.Get(ctx, req.NamespacedName, pkg); err != nil {
 		if apierrors.IsNotFound(err) {
+			forgetPackageMetrics(req.Name)
+			r.forgetHelmReleaseBatch(req.Name)
 			// Resource not found, return (ownerReference will handle cleanup)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
+	// Deleted Packages are kept until their HelmReleases are uninstalled
+	if !pkg.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, pkg)
+	}
+	if controllerutil.AddFinalizer(pkg, PackageFinalizer) {
+		if err := r.Update(ctx, pkg); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Suspended Packages are left as they are until resumed
+	if pkg.Spec.Suspend {
+		return r.reconcileSuspended(ctx, pkg)
+	}
+	if wasSuspended(pkg) {
+		r.Recorder.Event(pkg, corev1.EventTypeNormal, "Resumed", "Resuming HelmReleases")
+	}
+
 	// Get PackageSource with the same name
 	packageSource := &cozyv1alpha1.PackageSource{}
 	if err := r.Get(ctx, types.NamespacedName{Name: pkg.Name}, packageSource); err != nil {
 		if apierrors.IsNotFound(err) {
+			// Dependencies are defined by the PackageSource, drop the ones left from it
+			pkg.Status.Dependencies = nil
 			meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
 				Type:    "Ready",
 				Status:  metav1.ConditionFalse,
@@ -88,6 +204,30 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
+	// Refuse to install from sources that do not satisfy the verification policy
+	if r.VerificationPolicy != nil {
+		result, err := r.VerificationPolicy.Check(ctx, r.Client, packageSource)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !result.Verified() {
+			message := fmt.Sprintf("%s does not satisfy the verification policy: %s", result.Source, strings.Join(result.Violations, "; "))
+			logger.Info("PackageSource failed verification", "violations", result.Violations)
+			r.Recorder.Event(pkg, corev1.EventTypeWarning, "VerificationFailed", message)
+			meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "VerificationFailed",
+				Message: message,
+			})
+			if err := r.Status().Update(ctx, pkg); err != nil {
+				return ctrl.Result{}, err
+			}
+			// Source verification status is not watched, check again later
+			return ctrl.Result{RequeueAfter: verificationRetryInterval}, nil
+		}
+	}
+
 	// Determine variant (default to "default" if not specified)
 	variantName := pkg.Spec.Variant
 	if variantName == "" {
@@ -116,9 +256,58 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
+	// Leave out the components whose condition is false
+	selected, err := SelectComponents(ctx, r.reader(), pkg, variant)
+	var conditionErr *ConditionError
+	if errors.As(err, &conditionErr) {
+		meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidCondition",
+			Message: err.Error(),
+		})
+		if err := r.Status().Update(ctx, pkg); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, err
+	} else if err != nil {
+		// Reading the facts about the cluster failed, retry
+		return ctrl.Result{}, err
+	}
+	variant = selected
+
+	// Invalid values would only fail later in helm-controller
+	if errs := ValidateComponentValues(pkg, variant); len(errs) > 0 {
+		message := errs.ToAggregate().Error()
+		if meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidValues",
+			Message: message,
+		}) {
+			r.Recorder.Event(pkg, corev1.EventTypeWarning, "InvalidValues", message)
+		}
+		if err := r.Status().Update(ctx, pkg); err != nil {
+			return ctrl.Result{}, err
+		}
+		// The Package or PackageSource has to change for the values to become valid
+		return ctrl.Result{}, nil
+	}
+
 	// Reconcile namespaces from components
 	if err := r.reconcileNamespaces(ctx, pkg, variant); err != nil {
 		logger.Error(err, "failed to reconcile namespaces")
+		if apierrors.IsConflict(err) {
+			meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "NamespaceConflict",
+				Message: err.Error(),
+			})
+			if err := r.Status().Update(ctx, pkg); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		return ctrl.Result{}, err
 	}
 
@@ -130,14 +319,29 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	// Validate variant dependencies before creating HelmReleases
 	// Check if all dependencies are ready based on status
-	if !r.areDependenciesReady(pkg, variant) {
-		logger.Info("variant dependencies not ready, skipping HelmRelease creation", "package", pkg.Name)
-		meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+	waiting := r.waitingDependencies(pkg, variant)
+	packageDependenciesWaiting.WithLabelValues(pkg.Name).Set(float64(len(waiting)))
+	if len(waiting) > 0 {
+		logger.Info("variant dependencies not ready, skipping HelmRelease creation")
+		// Dependencies in a cycle never become ready, say so instead of waiting
+		if cycle := meta.FindStatusCondition(packageSource.Status.Conditions, DependencyCycleCondition); cycle != nil && cycle.Status == metav1.ConditionTrue {
+			if meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "DependencyCycle",
+				Message: cycle.Message,
+			}) {
+				r.Recorder.Event(pkg, corev1.EventTypeWarning, "DependencyCycle", cycle.Message)
+			}
+		} else if meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
 			Type:    "Ready",
 			Status:  metav1.ConditionFalse,
 			Reason:  "DependenciesNotReady",
 			Message: "One or more dependencies are not ready",
-		})
+		}) {
+			r.Recorder.Eventf(pkg, corev1.EventTypeWarning, "DependenciesNotReady",
+				"Waiting for dependencies: %s", strings.Join(waiting, ", "))
+		}
 		if err := r.Status().Update(ctx, pkg); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -145,8 +349,9 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
-	// Create HelmReleases for components with Install section
-	helmReleaseCount := 0
+	// Build HelmReleases for components with Install section
+	var releases []*helmv2.HelmRelease
+	var releaseComponents []string
 	for _, component := range variant.Components {
 		// Skip components without Install section
 		if component.Install == nil {
@@ -156,17 +361,11 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		// Check if component is disabled via Package spec
 		if pkgComponent, ok := pkg.Spec.Components[component.Name]; ok {
 			if pkgComponent.Enabled != nil && !*pkgComponent.Enabled {
-				logger.V(1).Info("skipping disabled component", "package", pkg.Name, "component", component.Name)
+				logger.V(1).Info("skipping disabled component", logging.KeyComponent, component.Name)
 				continue
 			}
 		}
 
-		// Build artifact name: <packagesource>-<variant>-<componentname> (with dots replaced by dashes)
-		artifactName := fmt.Sprintf("%s-%s-%s",
-			strings.ReplaceAll(packageSource.Name, ".", "-"),
-			strings.ReplaceAll(variantName, ".", "-"),
-			strings.ReplaceAll(component.Name, ".", "-"))
-
 		// Namespace must be set
 		namespace := component.Install.Namespace
 		if namespace == "" {
@@ -183,55 +382,8 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{}, fmt.Errorf("component %s has empty namespace in Install section", component.Name)
 		}
 
-		// Determine release name (from Install or use component name)
-		releaseName := component.Install.ReleaseName
-		if releaseName == "" {
-			releaseName = component.Name
-		}
-
-		// Build labels
-		labels := make(map[string]string)
-		labels["cozystack.io/package"] = pkg.Name
-		if component.Install.Privileged {
-			labels["cozystack.io/privileged"] = "true"
-		}
-
-		// Create HelmRelease
-		hr := &helmv2.HelmRelease{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      releaseName,
-				Namespace: namespace,
-				Labels:    labels,
-			},
-			Spec: helmv2.HelmReleaseSpec{
-				Interval: metav1.Duration{Duration: 5 * 60 * 1000000000}, // 5m
-				ChartRef: &helmv2.CrossNamespaceSourceReference{
-					Kind:      "ExternalArtifact",
-					Name:      artifactName,
-					Namespace: "cozy-system",
-				},
-				Install: &helmv2.Install{
-					Remediation: &helmv2.InstallRemediation{
-						Retries: -1,
-					},
-				},
-				Upgrade: &helmv2.Upgrade{
-					Remediation: &helmv2.UpgradeRemediation{
-						Retries: -1,
-					},
-				},
-			},
-		}
-
-		// Add valuesFrom for cozystack-values secret unless disabled by annotation on PackageSource
-		if packageSource.GetAnnotations()[AnnotationSkipCozystackValues] != "true" {
-			hr.Spec.ValuesFrom = []helmv2.ValuesReference{
-				{
-					Kind: "Secret",
-					Name: SecretCozystackValues,
-				},
-			}
-		}
+		hr := r.newHelmRelease(pkg, packageSource, variantName, &component)
+		r.annotateProvenance(ctx, hr, packageSource, variantName, &component)
 
 		// Set ownerReference
 		gvk, err := apiutil.GVKForObject(pkg, r.Scheme)
@@ -258,15 +410,10 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			},
 		}
 
-		// Merge values from Package spec if provided
-		if pkgComponent, ok := pkg.Spec.Components[component.Name]; ok && pkgComponent.Values != nil {
-			hr.Spec.Values = pkgComponent.Values
-		}
-
 		// Build DependsOn from component Install and variant DependsOn
 		dependsOn, err := r.buildDependsOn(ctx, pkg, packageSource, variant, &component)
 		if err != nil {
-			logger.Error(err, "failed to build DependsOn", "component", component.Name)
+			logger.Error(err, "failed to build DependsOn", logging.KeyComponent, component.Name)
 			meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
 				Type:    "Ready",
 				Status:  metav1.ConditionFalse,
@@ -283,30 +430,86 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			hr.Spec.DependsOn = dependsOn
 		}
 
-		// Set valuesFiles annotation
-		if len(component.ValuesFiles) > 0 {
-			if hr.Annotations == nil {
-				hr.Annotations = make(map[string]string)
-			}
-			hr.Annotations["cozyhr.cozystack.io/values-files"] = strings.Join(component.ValuesFiles, ",")
+		releases = append(releases, hr)
+		releaseComponents = append(releaseComponents, component.Name)
+	}
+
+	// Take over HelmReleases left behind by a renamed PackageSource
+	renamedFrom, err := r.adoptRenamedHelmReleases(ctx, pkg, releases)
+	if err != nil {
+		logger.Error(err, "failed to adopt HelmReleases")
+		meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "HelmReleaseConflict",
+			Message: err.Error(),
+		})
+		if err := r.Status().Update(ctx, pkg); err != nil {
+			return ctrl.Result{}, err
 		}
+		return ctrl.Result{}, err
+	}
 
-		if err := r.createOrUpdateHelmRelease(ctx, hr); err != nil {
-			logger.Error(err, "failed to reconcile HelmRelease", "name", releaseName, "namespace", namespace)
-			meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
-				Type:    "Ready",
-				Status:  metav1.ConditionFalse,
-				Reason:  "HelmReleaseFailed",
-				Message: fmt.Sprintf("Failed to create HelmRelease %s: %v", releaseName, err),
-			})
-			if err := r.Status().Update(ctx, pkg); err != nil {
-				return ctrl.Result{}, err
+	// Create or update HelmReleases in parallel, in batches of at most
+	// MaxHelmReleasesPerReconcile. Namespaces are already reconciled above,
+	// and errors are reported in component order so the status is deterministic
+	batchStart, batchEnd, batchDigest := r.nextHelmReleaseBatch(pkg, releases)
+	errs := r.applyHelmReleases(ctx, pkg, releases[batchStart:batchEnd])
+	firstFailed, failed := -1, 0
+	for i, err := range errs {
+		hr := releases[batchStart+i]
+		if err != nil {
+			logger.Error(err, "failed to reconcile HelmRelease", "name", hr.Name, logging.KeyNamespace, hr.Namespace)
+			if firstFailed < 0 {
+				firstFailed = batchStart + i
 			}
+			failed++
+			continue
+		}
+		logger.Info("reconciled HelmRelease", logging.KeyComponent, releaseComponents[batchStart+i], "releaseName", hr.Name, logging.KeyNamespace, hr.Namespace)
+	}
+	if firstFailed >= 0 {
+		err := errs[firstFailed-batchStart]
+		message := fmt.Sprintf("Failed to create HelmRelease %s: %v", releases[firstFailed].Name, err)
+		if failed > 1 {
+			message = fmt.Sprintf("%s (and %d more)", message, failed-1)
+		}
+		meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "HelmReleaseFailed",
+			Message: message,
+		})
+		if err := r.Status().Update(ctx, pkg); err != nil {
 			return ctrl.Result{}, err
 		}
-
-		helmReleaseCount++
-		logger.Info("reconciled HelmRelease", "package", pkg.Name, "component", component.Name, "releaseName", releaseName, "namespace", namespace)
+		return ctrl.Result{}, err
+	}
+	// Apply the remaining HelmReleases after a requeue, before anything relies on all of them
+	if !r.finishHelmReleaseBatch(pkg, releases, batchEnd, batchDigest) {
+		logger.V(1).Info("applied a batch of HelmReleases", "applied", batchEnd, "total", len(releases))
+		meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Progressing",
+			Message: fmt.Sprintf("Applying %d HelmReleases in batches of %d", len(releases), batchEnd-batchStart),
+		})
+		if err := r.Status().Update(ctx, pkg); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: helmReleaseBatchInterval}, nil
+	}
+	helmReleaseCount := len(releases)
+
+	// HelmReleases of renamed Packages now belong to this Package, drop their
+	// stale entries from the status of dependent Packages
+	for _, oldName := range renamedFrom {
+		r.Recorder.Eventf(pkg, corev1.EventTypeNormal, "HelmReleasesMigrated",
+			"Migrated HelmReleases from Package %s of a renamed PackageSource", oldName)
+		if err := r.pruneDependencyStatus(ctx, oldName); err != nil {
+			logger.Error(err, "failed to prune dependency status", "dependency", oldName)
+			// Don't return error, continue with status update
+		}
 	}
 
 	// Cleanup orphaned HelmReleases
@@ -315,6 +518,19 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		// Don't return error, continue with status update
 	}
 
+	// Record the reconciled spec for rollbacks
+	if err := r.recordRevision(ctx, pkg, releases, releaseComponents); err != nil {
+		logger.Error(err, "failed to record PackageRevision")
+		// Don't return error, continue with status update
+	}
+
+	// Report the state of each component
+	components, err := r.componentStatuses(ctx, packageSource, releases, releaseComponents, variant)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	pkg.Status.Components = components
+
 	// Update status with success message
 	message := fmt.Sprintf("reconciliation succeeded, generated %d helmrelease(s)", helmReleaseCount)
 	meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
@@ -329,69 +545,343 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	logger.Info("reconciled Package", "name", pkg.Name, "helmReleaseCount", helmReleaseCount)
+	packageHelmReleases.WithLabelValues(pkg.Name).Set(float64(helmReleaseCount))
 
 	// Update dependencies status for Packages that depend on this Package
 	// This ensures they get re-enqueued when their dependency becomes ready
 	if err := r.updateDependentPackagesDependencies(ctx, pkg.Name); err != nil {
-		logger.V(1).Error(err, "failed to update dependent packages dependencies", "package", pkg.Name)
+		logger.V(1).Error(err, "failed to update dependent packages dependencies")
 		// Don't return error, this is best-effort
 	}
 
 	// Dependent Packages will be automatically enqueued by the watch handler
 	// when this Package's status is updated (see SetupWithManager watch handler)
 
+	// Health check targets are not watched, check again later
+	if healthChecksFailing(components) {
+		return ctrl.Result{RequeueAfter: healthCheckRetryInterval}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
-// createOrUpdateHelmRelease creates or updates a HelmRelease
-func (r *PackageReconciler) createOrUpdateHelmRelease(ctx context.Context, hr *helmv2.HelmRelease) error {
-	existing := &helmv2.HelmRelease{}
-	key := types.NamespacedName{
-		Name:      hr.Name,
-		Namespace: hr.Namespace,
+// newHelmRelease builds the HelmRelease of a component of pkg. Owner references
+// and dependencies are set by the caller.
+func (r *PackageReconciler) newHelmRelease(pkg *cozyv1alpha1.Package, packageSource *cozyv1alpha1.PackageSource, variantName string, component *cozyv1alpha1.Component) *helmv2.HelmRelease {
+	// Build artifact name: <packagesource>-<variant>-<componentname> (with dots replaced by dashes)
+	artifactName := ComponentArtifactName(packageSource.Name, variantName, component.Name)
+
+	// Components referencing a published chart install it from the OCIRepository
+	// generated by the PackageSource reconciler under the same name
+	chartKind := "ExternalArtifact"
+	if component.ChartRef != nil {
+		chartKind = sourcev1.OCIRepositoryKind
 	}
 
-	err := r.Get(ctx, key, existing)
-	if apierrors.IsNotFound(err) {
-		return r.Create(ctx, hr)
-	} else if err != nil {
-		return err
+	namespace := component.Install.Namespace
+
+	// Determine release name (from Install or use component name)
+	releaseName := component.Install.ReleaseName
+	if releaseName == "" {
+		releaseName = component.Name
+	}
+
+	// Build labels
+	labels := make(map[string]string)
+	labels["cozystack.io/package"] = pkg.Name
+	if component.Install.Privileged {
+		labels["cozystack.io/privileged"] = "true"
+	}
+
+	// Create HelmRelease
+	hr := &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      releaseName,
+			Namespace: namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				AnnotationPackageSourceUID: string(packageSource.UID),
+			},
+		},
+		Spec: helmv2.HelmReleaseSpec{
+			// Without it Flux names the release <targetNamespace>-<name> once a
+			// target namespace is set, installing a second copy of the component
+			ReleaseName: releaseName,
+			Interval:    metav1.Duration{Duration: 5 * 60 * 1000000000}, // 5m
+			ChartRef: &helmv2.CrossNamespaceSourceReference{
+				Kind:      chartKind,
+				Name:      artifactName,
+				Namespace: "cozy-system",
+			},
+			Install: &helmv2.Install{
+				Remediation: &helmv2.InstallRemediation{
+					Retries: -1,
+				},
+			},
+			Upgrade: &helmv2.Upgrade{
+				Remediation: &helmv2.UpgradeRemediation{
+					Retries: -1,
+				},
+			},
+		},
+	}
+
+	// Add valuesFrom for cozystack-values secret unless disabled by annotation on PackageSource
+	if packageSource.GetAnnotations()[AnnotationSkipCozystackValues] != "true" {
+		hr.Spec.ValuesFrom = []helmv2.ValuesReference{
+			{
+				Kind: "Secret",
+				Name: SecretCozystackValues,
+			},
+		}
+	}
+
+	// Render into the target namespace while keeping the Helm release storage next to the HelmRelease
+	if target := component.Install.TargetNamespace; target != "" && target != namespace {
+		hr.Spec.TargetNamespace = target
+		hr.Spec.StorageNamespace = namespace
+	}
+
+	// Run Helm tests after install and upgrade if requested
+	if component.Install.Test {
+		hr.Spec.Test = &helmv2.Test{Enable: true}
+	}
+
+	// Merge values from Package spec if provided
+	if pkgComponent, ok := pkg.Spec.Components[component.Name]; ok && pkgComponent.Values != nil {
+		hr.Spec.Values = pkgComponent.Values
+	}
+
+	// Set valuesFiles annotation
+	if len(component.ValuesFiles) > 0 {
+		hr.Annotations["cozyhr.cozystack.io/values-files"] = strings.Join(component.ValuesFiles, ",")
 	}
 
-	// Preserve resource version
-	hr.SetResourceVersion(existing.GetResourceVersion())
+	return hr
+}
 
-	// Merge labels
-	labels := hr.GetLabels()
-	if labels == nil {
-		labels = make(map[string]string)
+// RenderHelmReleases returns the HelmReleases the reconciler generates for pkg from
+// packageSource, without owner references. It does not modify the cluster, so it
+// can be used to preview the effect of a Package.
+func (r *PackageReconciler) RenderHelmReleases(ctx context.Context, pkg *cozyv1alpha1.Package, packageSource *cozyv1alpha1.PackageSource) ([]*helmv2.HelmRelease, error) {
+	variantName := pkg.Spec.Variant
+	if variantName == "" {
+		variantName = "default"
+	}
+	var variant *cozyv1alpha1.Variant
+	for i := range packageSource.Spec.Variants {
+		if packageSource.Spec.Variants[i].Name == variantName {
+			variant = &packageSource.Spec.Variants[i]
+			break
+		}
+	}
+	if variant == nil {
+		return nil, fmt.Errorf("variant %s not found in PackageSource %s", variantName, packageSource.Name)
 	}
-	for k, v := range existing.GetLabels() {
-		if _, ok := labels[k]; !ok {
-			labels[k] = v
+	variant, err := SelectComponents(ctx, r.reader(), pkg, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []*helmv2.HelmRelease
+	for i := range variant.Components {
+		component := &variant.Components[i]
+		if component.Install == nil {
+			continue
 		}
+		if pkgComponent, ok := pkg.Spec.Components[component.Name]; ok {
+			if pkgComponent.Enabled != nil && !*pkgComponent.Enabled {
+				continue
+			}
+		}
+		if component.Install.Namespace == "" {
+			return nil, fmt.Errorf("component %s has empty namespace in Install section", component.Name)
+		}
+
+		hr := r.newHelmRelease(pkg, packageSource, variantName, component)
+		r.annotateProvenance(ctx, hr, packageSource, variantName, component)
+		dependsOn, err := r.buildDependsOn(ctx, pkg, packageSource, variant, component)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build DependsOn for component %s: %w", component.Name, err)
+		}
+		if len(dependsOn) > 0 {
+			hr.Spec.DependsOn = dependsOn
+		}
+		releases = append(releases, hr)
 	}
-	hr.SetLabels(labels)
+	return releases, nil
+}
 
-	// Merge annotations
-	annotations := hr.GetAnnotations()
-	if annotations == nil {
-		annotations = make(map[string]string)
+// applyHelmReleases creates or updates HelmReleases using at most MaxConcurrentHelmReleases
+// workers. The returned errors are indexed like releases
+func (r *PackageReconciler) applyHelmReleases(ctx context.Context, pkg *cozyv1alpha1.Package, releases []*helmv2.HelmRelease) []error {
+	errs := make([]error, len(releases))
+	workers := r.currentTunables().MaxConcurrentHelmReleases
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, hr := range releases {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = r.createOrUpdateHelmRelease(ctx, pkg, hr)
+		}()
 	}
-	for k, v := range existing.GetAnnotations() {
-		if _, ok := annotations[k]; !ok {
-			annotations[k] = v
+	wg.Wait()
+
+	return errs
+}
+
+// adoptRenamedHelmReleases checks that the existing HelmReleases for releases are not
+// managed by another Package. HelmReleases of a Package whose PackageSource was renamed
+// (the PackageSource they were generated from no longer exists) are adopted: they are
+// relabeled and owned by pkg when applied. It returns the names of the renamed Packages
+func (r *PackageReconciler) adoptRenamedHelmReleases(ctx context.Context, pkg *cozyv1alpha1.Package, releases []*helmv2.HelmRelease) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	var sourceUIDs map[types.UID]bool
+	var renamedFrom []string
+	seen := make(map[string]bool)
+	for _, hr := range releases {
+		existing := &helmv2.HelmRelease{}
+		if err := r.Get(ctx, types.NamespacedName{Name: hr.Name, Namespace: hr.Namespace}, existing); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		owner := existing.GetLabels()["cozystack.io/package"]
+		if owner == "" || owner == pkg.Name {
+			continue
+		}
+
+		// Collect UIDs of existing PackageSources once, only when needed
+		if sourceUIDs == nil {
+			psList := &cozyv1alpha1.PackageSourceList{}
+			if err := r.List(ctx, psList); err != nil {
+				return nil, fmt.Errorf("failed to list PackageSources: %w", err)
+			}
+			sourceUIDs = make(map[types.UID]bool, len(psList.Items))
+			for i := range psList.Items {
+				sourceUIDs[psList.Items[i].UID] = true
+			}
+		}
+
+		var renamed bool
+		if uid := existing.GetAnnotations()[AnnotationPackageSourceUID]; uid != "" {
+			renamed = !sourceUIDs[types.UID(uid)]
+		} else {
+			// HelmReleases created before UID tracking: fall back to the PackageSource name
+			err := r.Get(ctx, types.NamespacedName{Name: owner}, &cozyv1alpha1.PackageSource{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			renamed = apierrors.IsNotFound(err)
+		}
+		if !renamed {
+			return nil, fmt.Errorf("HelmRelease %s/%s is managed by Package %s", existing.Namespace, existing.Name, owner)
+		}
+
+		logger.Info("adopting HelmRelease of renamed PackageSource", "name", hr.Name, logging.KeyNamespace, hr.Namespace, "previousPackage", owner)
+		if !seen[owner] {
+			seen[owner] = true
+			renamedFrom = append(renamedFrom, owner)
 		}
 	}
-	hr.SetAnnotations(annotations)
 
-	// Update Spec
-	existing.Spec = hr.Spec
-	existing.SetLabels(hr.GetLabels())
-	existing.SetAnnotations(hr.GetAnnotations())
-	existing.SetOwnerReferences(hr.GetOwnerReferences())
+	return renamedFrom, nil
+}
+
+// pruneDependencyStatus removes the status entry of dependency name from Packages
+// whose variant no longer depends on it
+func (r *PackageReconciler) pruneDependencyStatus(ctx context.Context, name string) error {
+	logger := log.FromContext(ctx)
 
-	return r.Update(ctx, existing)
+	packageList := &cozyv1alpha1.PackageList{}
+	if err := r.List(ctx, packageList); err != nil {
+		return fmt.Errorf("failed to list Packages: %w", err)
+	}
+
+	for i := range packageList.Items {
+		pkg := &packageList.Items[i]
+		if _, ok := pkg.Status.Dependencies[name]; !ok {
+			continue
+		}
+		if variant, err := r.getVariantForPackage(ctx, pkg, nil); err == nil && slices.Contains(variant.DependsOn, name) {
+			continue
+		}
+		delete(pkg.Status.Dependencies, name)
+		if err := r.Status().Update(ctx, pkg); err != nil {
+			return fmt.Errorf("failed to update status of Package %s: %w", pkg.Name, err)
+		}
+		logger.V(1).Info("removed stale dependency from status", "dependency", name)
+	}
+
+	return nil
+}
+
+// createOrUpdateHelmRelease applies a HelmRelease of pkg with server-side apply.
+// The operator only owns the fields it sets, so fields set by users or Flux,
+// such as spec.suspend or labels added by other tools, are kept.
+func (r *PackageReconciler) createOrUpdateHelmRelease(ctx context.Context, pkg *cozyv1alpha1.Package, hr *helmv2.HelmRelease) error {
+	// Apply a copy, hr is kept as desired for the revision history
+	applied := hr.DeepCopy()
+	// Ensure TypeMeta is set for server-side apply
+	applied.SetGroupVersionKind(helmv2.GroupVersion.WithKind(helmv2.HelmReleaseKind))
+
+	existing := &helmv2.HelmRelease{}
+	err := r.Get(ctx, types.NamespacedName{Name: hr.Name, Namespace: hr.Namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	created := apierrors.IsNotFound(err)
+
+	// Earlier operator versions updated the HelmReleases instead of applying
+	// them. The fields they set stay owned by their manager wherever the apply
+	// sets the same value, and would never be pruned once the operator stops
+	// setting them, so they are handed over to the apply manager first.
+	if !created {
+		patch, err := csaupgrade.UpgradeManagedFieldsPatch(existing, legacyHelmReleaseFieldManagers, packageFieldOwner)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade managed fields: %w", err)
+		}
+		if patch != nil {
+			if err := r.Patch(ctx, existing, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+				return fmt.Errorf("failed to upgrade managed fields: %w", err)
+			}
+		}
+	}
+
+	// The operator is authoritative for the fields it sets: force takes over
+	// the ones other managers set to different values
+	if err := r.Patch(ctx, applied, client.Apply, client.FieldOwner(packageFieldOwner), client.ForceOwnership); err != nil {
+		return err
+	}
+	if created {
+		r.Recorder.Eventf(pkg, corev1.EventTypeNormal, "HelmReleaseCreated",
+			"Created HelmRelease %s/%s", hr.Namespace, hr.Name)
+	}
+
+	// spec.suspend is not applied, resume the HelmReleases suspended with the Package
+	if applied.GetAnnotations()[AnnotationSuspendedByPackage] == "true" {
+		patch := client.MergeFrom(applied.DeepCopy())
+		applied.Spec.Suspend = false
+		delete(applied.Annotations, AnnotationSuspendedByPackage)
+		if err := r.Patch(ctx, applied, patch, client.FieldOwner(packageSuspendFieldOwner)); err != nil {
+			return fmt.Errorf("failed to resume HelmRelease: %w", err)
+		}
+	}
+	return nil
+}
+
+// reader returns APIReader, or the cached client if it is unset
+func (r *PackageReconciler) reader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
 }
 
 // getVariantForPackage retrieves the Variant for a given Package
@@ -480,7 +970,7 @@ func (r *PackageReconciler) buildDependsOn(ctx context.Context, pkg *cozyv1alpha
 				Name:      depComp.releaseName,
 				Namespace: depComp.namespace,
 			})
-			logger.V(1).Info("added component dependency", "component", component.Name, "dependsOn", depName, "releaseName", depComp.releaseName, "namespace", depComp.namespace)
+			logger.V(1).Info("added component dependency", logging.KeyComponent, component.Name, "dependsOn", depName, "releaseName", depComp.releaseName, logging.KeyNamespace, depComp.namespace)
 		}
 	}
 
@@ -496,7 +986,7 @@ func (r *PackageReconciler) buildDependsOn(ctx context.Context, pkg *cozyv1alpha
 				}
 			}
 			if ignore {
-				logger.V(1).Info("ignoring dependency", "package", pkg.Name, "dependency", depPackageName)
+				logger.V(1).Info("ignoring dependency", "dependency", depPackageName)
 				continue
 			}
 
@@ -514,6 +1004,10 @@ func (r *PackageReconciler) buildDependsOn(ctx context.Context, pkg *cozyv1alpha
 			if err != nil {
 				return nil, fmt.Errorf("failed to get variant for dependent Package %s: %w", depPackageName, err)
 			}
+			depVariant, err = SelectComponents(ctx, r.reader(), depPackage, depVariant)
+			if err != nil {
+				return nil, fmt.Errorf("failed to select components of dependent Package %s: %w", depPackageName, err)
+			}
 
 			// Add all components with Install from dependent variant
 			for _, depComp := range depVariant.Components {
@@ -541,7 +1035,7 @@ func (r *PackageReconciler) buildDependsOn(ctx context.Context, pkg *cozyv1alpha
 					Name:      depCompReleaseName,
 					Namespace: depCompNamespace,
 				})
-				logger.V(1).Info("added variant dependency", "package", pkg.Name, "dependency", depPackageName, "component", depComp.Name, "releaseName", depCompReleaseName, "namespace", depCompNamespace)
+				logger.V(1).Info("added variant dependency", "dependency", depPackageName, logging.KeyComponent, depComp.Name, "releaseName", depCompReleaseName, logging.KeyNamespace, depCompNamespace)
 			}
 		}
 	}
@@ -573,7 +1067,7 @@ func (r *PackageReconciler) updateDependenciesStatus(ctx context.Context, pkg *c
 				}
 			}
 			if ignore {
-				logger.V(1).Info("ignoring dependency", "package", pkg.Name, "dependency", depPackageName)
+				logger.V(1).Info("ignoring dependency", "dependency", depPackageName)
 				continue
 			}
 			currentDeps[depPackageName] = true
@@ -584,7 +1078,7 @@ func (r *PackageReconciler) updateDependenciesStatus(ctx context.Context, pkg *c
 	for depName := range pkg.Status.Dependencies {
 		if !currentDeps[depName] {
 			delete(pkg.Status.Dependencies, depName)
-			logger.V(1).Info("removed old dependency from status", "package", pkg.Name, "dependency", depName)
+			logger.V(1).Info("removed old dependency from status", "dependency", depName)
 		}
 	}
 
@@ -598,7 +1092,7 @@ func (r *PackageReconciler) updateDependenciesStatus(ctx context.Context, pkg *c
 				pkg.Status.Dependencies[depPackageName] = cozyv1alpha1.DependencyStatus{
 					Ready: false,
 				}
-				logger.V(1).Info("dependency not found, marking as not ready", "package", pkg.Name, "dependency", depPackageName)
+				logger.V(1).Info("dependency not found, marking as not ready", "dependency", depPackageName)
 				continue
 			}
 			// Error getting dependency, keep existing status or mark as not ready
@@ -607,7 +1101,7 @@ func (r *PackageReconciler) updateDependenciesStatus(ctx context.Context, pkg *c
 					Ready: false,
 				}
 			}
-			logger.V(1).Error(err, "failed to get dependency, keeping existing status", "package", pkg.Name, "dependency", depPackageName)
+			logger.V(1).Error(err, "failed to get dependency, keeping existing status", "dependency", depPackageName)
 			continue
 		}
 
@@ -619,18 +1113,16 @@ func (r *PackageReconciler) updateDependenciesStatus(ctx context.Context, pkg *c
 		pkg.Status.Dependencies[depPackageName] = cozyv1alpha1.DependencyStatus{
 			Ready: isReady,
 		}
-		logger.V(1).Info("updated dependency status", "package", pkg.Name, "dependency", depPackageName, "ready", isReady)
+		logger.V(1).Info("updated dependency status", "dependency", depPackageName, "ready", isReady)
 	}
 
 	return nil
 }
 
-// areDependenciesReady checks if all dependencies are ready based on status
-func (r *PackageReconciler) areDependenciesReady(pkg *cozyv1alpha1.Package, variant *cozyv1alpha1.Variant) bool {
-	if len(variant.DependsOn) == 0 {
-		return true
-	}
-
+// waitingDependencies returns the dependencies of variant that are not ready
+// based on status, leaving out ignored ones
+func (r *PackageReconciler) waitingDependencies(pkg *cozyv1alpha1.Package, variant *cozyv1alpha1.Variant) []string {
+	var waiting []string
 	for _, depPackageName := range variant.DependsOn {
 		// Check if dependency is in IgnoreDependencies
 		ignore := false
@@ -647,11 +1139,11 @@ func (r *PackageReconciler) areDependenciesReady(pkg *cozyv1alpha1.Package, vari
 		// Check dependency status
 		depStatus, exists := pkg.Status.Dependencies[depPackageName]
 		if !exists || !depStatus.Ready {
-			return false
+			waiting = append(waiting, depPackageName)
 		}
 	}
 
-	return true
+	return waiting
 }
 
 // updateDependentPackagesDependencies updates dependencies status for all Packages that depend on the given Package
@@ -689,7 +1181,7 @@ func (r *PackageReconciler) updateDependentPackagesDependencies(ctx context.Cont
 		variant, err := r.getVariantForPackage(ctx, &pkg, nil)
 		if err != nil {
 			// Continue if PackageSource or variant not found (best-effort operation)
-			logger.V(1).Info("skipping package, failed to get variant", "package", pkg.Name, "error", err)
+			logger.V(1).Info("skipping package, failed to get variant", "dependent", pkg.Name, "error", err)
 			continue
 		}
 
@@ -723,10 +1215,10 @@ func (r *PackageReconciler) updateDependentPackagesDependencies(ctx context.Cont
 				Ready: isReady,
 			}
 			if err := r.Status().Update(ctx, &pkg); err != nil {
-				logger.V(1).Error(err, "failed to update dependency status for dependent Package", "package", pkg.Name, "dependency", packageName)
+				logger.V(1).Error(err, "failed to update dependency status for dependent Package", "dependent", pkg.Name, "dependency", packageName)
 				continue
 			}
-			logger.V(1).Info("updated dependency status for dependent Package", "package", pkg.Name, "dependency", packageName, "ready", isReady)
+			logger.V(1).Info("updated dependency status for dependent Package", "dependent", pkg.Name, "dependency", packageName, "ready", isReady)
 		}
 	}
 
@@ -737,6 +1229,33 @@ func (r *PackageReconciler) updateDependentPackagesDependencies(ctx context.Cont
 func (r *PackageReconciler) reconcileNamespaces(ctx context.Context, pkg *cozyv1alpha1.Package, variant *cozyv1alpha1.Variant) error {
 	logger := log.FromContext(ctx)
 
+	namespaces, err := RenderNamespaces(pkg, variant)
+	if err != nil {
+		return err
+	}
+
+	// Create or update all namespaces
+	for _, namespace := range namespaces {
+		nsName := namespace.Name
+		privileged := namespace.Labels["pod-security.kubernetes.io/enforce"] == "privileged"
+		if err := r.createOrUpdateNamespace(ctx, pkg, namespace); err != nil {
+			logger.Error(err, "failed to reconcile namespace", "name", nsName, "privileged", privileged)
+			if apierrors.IsConflict(err) {
+				r.Recorder.Eventf(pkg, corev1.EventTypeWarning, "NamespaceConflict",
+					"Namespace %s has fields owned by another manager; set annotation %s=true to take ownership: %v",
+					nsName, AnnotationForceNamespaceOwnership, err)
+			}
+			return fmt.Errorf("failed to reconcile namespace %s: %w", nsName, err)
+		}
+		logger.Info("reconciled namespace", "name", nsName, "privileged", privileged)
+	}
+
+	return nil
+}
+
+// RenderNamespaces returns the namespaces the reconciler creates for the enabled
+// components of variant in pkg, sorted by name
+func RenderNamespaces(pkg *cozyv1alpha1.Package, variant *cozyv1alpha1.Variant) ([]*corev1.Namespace, error) {
 	// Collect namespaces from components
 	// Map: namespace -> {isPrivileged}
 	type namespaceInfo struct {
@@ -760,26 +1279,39 @@ func (r *PackageReconciler) reconcileNamespaces(ctx context.Context, pkg *cozyv1
 		// Namespace must be set
 		namespace := component.Install.Namespace
 		if namespace == "" {
-			return fmt.Errorf("component %s has empty namespace in Install section", component.Name)
+			return nil, fmt.Errorf("component %s has empty namespace in Install section", component.Name)
 		}
 
-		info, exists := namespacesMap[namespace]
-		if !exists {
-			info = namespaceInfo{
+		if _, exists := namespacesMap[namespace]; !exists {
+			namespacesMap[namespace] = namespaceInfo{
 				privileged: false,
 			}
 		}
 
+		// Workloads run in the target namespace, if set
+		workloadNamespace := namespace
+		if component.Install.TargetNamespace != "" {
+			workloadNamespace = component.Install.TargetNamespace
+		}
+		info := namespacesMap[workloadNamespace]
+
 		// If component is privileged, mark namespace as privileged
 		if component.Install.Privileged {
 			info.privileged = true
 		}
 
-		namespacesMap[namespace] = info
+		namespacesMap[workloadNamespace] = info
 	}
 
-	// Create or update all namespaces
-	for nsName, info := range namespacesMap {
+	names := make([]string, 0, len(namespacesMap))
+	for nsName := range namespacesMap {
+		names = append(names, nsName)
+	}
+	slices.Sort(names)
+
+	namespaces := make([]*corev1.Namespace, 0, len(names))
+	for _, nsName := range names {
+		info := namespacesMap[nsName]
 		namespace := &corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:   nsName,
@@ -800,26 +1332,45 @@ func (r *PackageReconciler) reconcileNamespaces(ctx context.Context, pkg *cozyv1
 			namespace.Labels["pod-security.kubernetes.io/enforce"] = "privileged"
 		}
 
-		if err := r.createOrUpdateNamespace(ctx, namespace); err != nil {
-			logger.Error(err, "failed to reconcile namespace", "name", nsName, "privileged", info.privileged)
-			return fmt.Errorf("failed to reconcile namespace %s: %w", nsName, err)
-		}
-		logger.Info("reconciled namespace", "name", nsName, "privileged", info.privileged)
+		namespaces = append(namespaces, namespace)
 	}
 
-	return nil
+	return namespaces, nil
 }
 
 // createOrUpdateNamespace creates or updates a namespace using server-side apply
-func (r *PackageReconciler) createOrUpdateNamespace(ctx context.Context, namespace *corev1.Namespace) error {
+// Ownership of conflicting fields is only forced when the Package carries the
+// AnnotationForceNamespaceOwnership annotation, otherwise a conflict error is returned
+func (r *PackageReconciler) createOrUpdateNamespace(ctx context.Context, pkg *cozyv1alpha1.Package, namespace *corev1.Namespace) error {
 	// Ensure TypeMeta is set for server-side apply
 	namespace.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Namespace"))
-	
+
+	opts := []client.PatchOption{client.FieldOwner(packageFieldOwner)}
+	if pkg.GetAnnotations()[AnnotationForceNamespaceOwnership] == "true" {
+		opts = append(opts, client.ForceOwnership)
+	}
+
+	// The namespace is created first, so that only a namespace created here is
+	// recorded as created for the Package. The annotation is set on creation
+	// rather than applied, so later applies keep it.
+	toCreate := namespace.DeepCopy()
+	if toCreate.Annotations == nil {
+		toCreate.Annotations = map[string]string{}
+	}
+	toCreate.Annotations[AnnotationCreatedByPackage] = pkg.Name
+	err := r.Create(ctx, toCreate, client.FieldOwner(packageFieldOwner))
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	if err == nil {
+		r.Recorder.Eventf(pkg, corev1.EventTypeNormal, "NamespaceCreated", "Created namespace %s", namespace.Name)
+	}
+
 	// Use server-side apply with field manager
 	// This is atomic and avoids race conditions from Get/Create/Update pattern
 	// Labels and annotations will be merged automatically by the server
 	// Each label/annotation key is treated as a separate field, so existing ones are preserved
-	return r.Patch(ctx, namespace, client.Apply, client.FieldOwner("cozystack-package-controller"))
+	return r.Patch(ctx, namespace, client.Apply, opts...)
 }
 
 // cleanupOrphanedHelmReleases removes HelmReleases that are no longer needed
@@ -872,10 +1423,15 @@ func (r *PackageReconciler) cleanupOrphanedHelmReleases(ctx context.Context, pkg
 			Namespace: hr.Namespace,
 		}
 		if !desiredReleases[key] {
-			logger.Info("deleting orphaned HelmRelease", "name", hr.Name, "namespace", hr.Namespace, "package", pkg.Name)
-			if err := r.Delete(ctx, &hr); err != nil && !apierrors.IsNotFound(err) {
-				logger.Error(err, "failed to delete orphaned HelmRelease", "name", hr.Name, "namespace", hr.Namespace)
+			logger.Info("deleting orphaned HelmRelease", "name", hr.Name, logging.KeyNamespace, hr.Namespace)
+			if err := r.Delete(ctx, &hr); err != nil {
+				if !apierrors.IsNotFound(err) {
+					logger.Error(err, "failed to delete orphaned HelmRelease", "name", hr.Name, logging.KeyNamespace, hr.Namespace)
+				}
+				continue
 			}
+			r.Recorder.Eventf(pkg, corev1.EventTypeNormal, "HelmReleaseDeleted",
+				"Deleted HelmRelease %s/%s of a removed or disabled component", hr.Namespace, hr.Name)
 		}
 	}
 
@@ -886,7 +1442,7 @@ func (r *PackageReconciler) cleanupOrphanedHelmReleases(ctx context.Context, pkg
 func (r *PackageReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("cozystack-package").
-		For(&cozyv1alpha1.Package{}).
+		For(&cozyv1alpha1.Package{}, builder.WithPredicates(r.Shard.Predicate())).
 		Owns(&helmv2.HelmRelease{}).
 		Watches(
 			&cozyv1alpha1.PackageSource{},
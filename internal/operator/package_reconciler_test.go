@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewHelmReleaseKeepsReleaseNameWithTargetNamespace(t *testing.T) {
+	pkg := &cozyv1alpha1.Package{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.monitoring"}}
+	packageSource := &cozyv1alpha1.PackageSource{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.monitoring"}}
+
+	cases := []struct {
+		name    string
+		install cozyv1alpha1.ComponentInstall
+		want    string
+	}{
+		{
+			name:    "component name",
+			install: cozyv1alpha1.ComponentInstall{Namespace: "cozy-monitoring"},
+			want:    "grafana",
+		},
+		{
+			name:    "component name with target namespace",
+			install: cozyv1alpha1.ComponentInstall{Namespace: "cozy-monitoring", TargetNamespace: "tenant-root"},
+			want:    "grafana",
+		},
+		{
+			name:    "release name with target namespace",
+			install: cozyv1alpha1.ComponentInstall{Namespace: "cozy-monitoring", TargetNamespace: "tenant-root", ReleaseName: "grafana-operator"},
+			want:    "grafana-operator",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &PackageReconciler{}
+			component := &cozyv1alpha1.Component{Name: "grafana", Install: &tc.install}
+			hr := r.newHelmRelease(pkg, packageSource, "default", component)
+			if hr.Name != tc.want {
+				t.Errorf("HelmRelease name = %q, want %q", hr.Name, tc.want)
+			}
+			if hr.Spec.ReleaseName != tc.want {
+				t.Errorf("spec.releaseName = %q, want %q", hr.Spec.ReleaseName, tc.want)
+			}
+			if hr.Spec.TargetNamespace != tc.install.TargetNamespace {
+				t.Errorf("spec.targetNamespace = %q, want %q", hr.Spec.TargetNamespace, tc.install.TargetNamespace)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateHelmReleasePrunesFieldsOfLegacyUpdates(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = cozyv1alpha1.AddToScheme(scheme)
+	_ = helmv2.AddToScheme(scheme)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithReturnManagedFields().Build()
+	ctx := context.TODO()
+
+	// A HelmRelease created and updated by an earlier operator version
+	legacy := &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "grafana",
+			Namespace: "cozy-monitoring",
+			Labels:    map[string]string{"cozystack.io/package": "cozystack.monitoring"},
+		},
+		Spec: helmv2.HelmReleaseSpec{
+			ReleaseName: "grafana",
+			ValuesFrom:  []helmv2.ValuesReference{{Kind: "Secret", Name: SecretCozystackValues}},
+			Test:        &helmv2.Test{Enable: true},
+		},
+	}
+	if err := c.Create(ctx, legacy, client.FieldOwner("cozystack-operator")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// The operator no longer sets spec.valuesFrom and spec.test
+	desired := &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "grafana",
+			Namespace: "cozy-monitoring",
+			Labels:    map[string]string{"cozystack.io/package": "cozystack.monitoring"},
+		},
+		Spec: helmv2.HelmReleaseSpec{
+			ReleaseName: "grafana",
+		},
+	}
+	r := &PackageReconciler{Client: c, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+	pkg := &cozyv1alpha1.Package{ObjectMeta: metav1.ObjectMeta{Name: "cozystack.monitoring"}}
+	if err := r.createOrUpdateHelmRelease(ctx, pkg, desired); err != nil {
+		t.Fatalf("createOrUpdateHelmRelease: %v", err)
+	}
+
+	got := &helmv2.HelmRelease{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(desired), got); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(got.Spec.ValuesFrom) != 0 {
+		t.Errorf("spec.valuesFrom = %v, want it pruned", got.Spec.ValuesFrom)
+	}
+	if got.Spec.Test != nil {
+		t.Errorf("spec.test = %v, want it pruned", got.Spec.Test)
+	}
+	if got.Spec.ReleaseName != "grafana" {
+		t.Errorf("spec.releaseName = %q, want %q", got.Spec.ReleaseName, "grafana")
+	}
+	for _, entry := range got.ManagedFields {
+		if entry.Manager == "cozystack-operator" {
+			t.Errorf("managed fields still list the legacy manager: %+v", entry)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"sort"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// DefaultRevisionHistoryLimit is the number of PackageRevisions kept per Package
+// when PackageReconciler.RevisionHistoryLimit is unset
+const DefaultRevisionHistoryLimit = 10
+
+// recordRevision records the reconciled spec of pkg and the HelmReleases generated
+// from it as a new PackageRevision, unless they equal the latest revision, and
+// deletes the revisions beyond RevisionHistoryLimit.
+func (r *PackageReconciler) recordRevision(ctx context.Context, pkg *cozyv1alpha1.Package, releases []*helmv2.HelmRelease, components []string) error {
+	revisions, err := r.listRevisions(ctx, pkg.Name)
+	if err != nil {
+		return err
+	}
+
+	helmReleases := make([]cozyv1alpha1.PackageRevisionHelmRelease, 0, len(releases))
+	for i, hr := range releases {
+		item := cozyv1alpha1.PackageRevisionHelmRelease{
+			Name:      hr.Name,
+			Namespace: hr.Namespace,
+			Component: components[i],
+		}
+		if hr.Spec.ChartRef != nil {
+			item.Chart = hr.Spec.ChartRef.Name
+		}
+		helmReleases = append(helmReleases, item)
+	}
+
+	var next int64 = 1
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		if equality.Semantic.DeepEqual(latest.Spec.PackageSpec, pkg.Spec) &&
+			equality.Semantic.DeepEqual(latest.Spec.HelmReleases, helmReleases) {
+			return nil
+		}
+		next = latest.Spec.Revision + 1
+	}
+
+	revision := &cozyv1alpha1.PackageRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: cozyv1alpha1.PackageRevisionName(pkg.Name, next),
+			Labels: map[string]string{
+				cozyv1alpha1.PackageRevisionPackageLabel: pkg.Name,
+			},
+		},
+		Spec: cozyv1alpha1.PackageRevisionSpec{
+			PackageName:  pkg.Name,
+			Revision:     next,
+			PackageSpec:  *pkg.Spec.DeepCopy(),
+			HelmReleases: helmReleases,
+		},
+	}
+	if err := controllerutil.SetControllerReference(pkg, revision, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, revision); err != nil {
+		return err
+	}
+	revisions = append(revisions, *revision)
+
+	limit := r.currentTunables().RevisionHistoryLimit
+	for i := 0; i < len(revisions)-limit; i++ {
+		if err := r.Delete(ctx, &revisions[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listRevisions returns the PackageRevisions of the Package name, oldest first
+func (r *PackageReconciler) listRevisions(ctx context.Context, name string) ([]cozyv1alpha1.PackageRevision, error) {
+	var list cozyv1alpha1.PackageRevisionList
+	if err := r.List(ctx, &list, client.MatchingLabels{cozyv1alpha1.PackageRevisionPackageLabel: name}); err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Spec.Revision < list.Items[j].Spec.Revision
+	})
+	return list.Items, nil
+}
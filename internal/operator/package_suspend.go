@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileSuspended suspends the HelmReleases of a suspended Package instead of
+// reconciling it. The HelmReleases are marked with AnnotationSuspendedByPackage,
+// the next reconciliation after the Package is resumed clears their spec.suspend.
+func (r *PackageReconciler) reconcileSuspended(ctx context.Context, pkg *cozyv1alpha1.Package) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	hrList := &helmv2.HelmReleaseList{}
+	if err := r.List(ctx, hrList, client.MatchingLabels{
+		"cozystack.io/package": pkg.Name,
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	suspended := 0
+	for i := range hrList.Items {
+		hr := &hrList.Items[i]
+		if hr.Spec.Suspend {
+			continue
+		}
+		patch := client.MergeFrom(hr.DeepCopy())
+		hr.Spec.Suspend = true
+		// Mark the HelmRelease to resume it with the Package
+		if hr.Annotations == nil {
+			hr.Annotations = map[string]string{}
+		}
+		hr.Annotations[AnnotationSuspendedByPackage] = "true"
+		if err := r.Patch(ctx, hr, patch, client.FieldOwner(packageSuspendFieldOwner)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to suspend HelmRelease %s/%s: %w", hr.Namespace, hr.Name, err)
+		}
+		suspended++
+		logger.Info("suspended HelmRelease", "name", hr.Name, logging.KeyNamespace, hr.Namespace)
+	}
+
+	// Keep the status of the Ready condition, so that dependent Packages are not
+	// blocked by the maintenance of their dependency
+	status := metav1.ConditionUnknown
+	if ready := meta.FindStatusCondition(pkg.Status.Conditions, "Ready"); ready != nil {
+		status = ready.Status
+	}
+	meta.SetStatusCondition(&pkg.Status.Conditions, metav1.Condition{
+		Type:    "Ready",
+		Status:  status,
+		Reason:  "Suspended",
+		Message: fmt.Sprintf("Package is suspended, %d helmrelease(s) suspended", len(hrList.Items)),
+	})
+	if err := r.Status().Update(ctx, pkg); err != nil {
+		return ctrl.Result{}, err
+	}
+	if suspended > 0 {
+		r.Recorder.Eventf(pkg, corev1.EventTypeNormal, "Suspended", "Suspended %d HelmRelease(s)", suspended)
+	}
+	return ctrl.Result{}, nil
+}
+
+// wasSuspended reports whether the last reconciliation of pkg found it suspended
+func wasSuspended(pkg *cozyv1alpha1.Package) bool {
+	ready := meta.FindStatusCondition(pkg.Status.Conditions, "Ready")
+	return ready != nil && ready.Reason == "Suspended"
+}
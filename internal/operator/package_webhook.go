@@ -0,0 +1,318 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultVariant is the variant of Packages that do not select one
+const DefaultVariant = "default"
+
+// +kubebuilder:webhook:path=/mutate-package,mutating=true,failurePolicy=Ignore,sideEffects=None,groups=cozystack.io,resources=packages,verbs=create;update,versions=v1alpha1,name=mpackage.cozystack.io,admissionReviewVersions={v1}
+// +kubebuilder:webhook:path=/validate-package,mutating=false,failurePolicy=Fail,sideEffects=None,groups=cozystack.io,resources=packages,verbs=create;update,versions=v1alpha1,name=vpackage.cozystack.io,admissionReviewVersions={v1}
+// +kubebuilder:webhook:path=/validate-packagesource,mutating=false,failurePolicy=Fail,sideEffects=None,groups=cozystack.io,resources=packagesources,verbs=create;update,versions=v1alpha1,name=vpackagesource.cozystack.io,admissionReviewVersions={v1}
+
+// PackageWebhook defaults and validates Packages and PackageSources, so that
+// misconfigurations are rejected on admission instead of surfacing as Ready=False
+// conditions after reconciliation.
+type PackageWebhook struct {
+	client.Client
+	decoder admission.Decoder
+}
+
+// SetupWithManagerAsWebhook registers the handlers with the webhook server.
+func (w *PackageWebhook) SetupWithManagerAsWebhook(mgr ctrl.Manager) error {
+	w.decoder = admission.NewDecoder(mgr.GetScheme())
+	server := mgr.GetWebhookServer()
+	server.Register("/mutate-package", &admission.Webhook{Handler: admission.HandlerFunc(w.defaultPackage)})
+	server.Register("/validate-package", &admission.Webhook{Handler: admission.HandlerFunc(w.validatePackage)})
+	server.Register("/validate-packagesource", &admission.Webhook{Handler: admission.HandlerFunc(w.validatePackageSource)})
+	return nil
+}
+
+// defaultPackage sets the variant of Packages that do not select one
+func (w *PackageWebhook) defaultPackage(ctx context.Context, req admission.Request) admission.Response {
+	pkg := &cozyv1alpha1.Package{}
+	if err := w.decoder.Decode(req, pkg); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("decode object: %w", err))
+	}
+	if pkg.Spec.Variant != "" {
+		return admission.Allowed("")
+	}
+	pkg.Spec.Variant = DefaultVariant
+	mutated, err := json.Marshal(pkg)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("marshal mutated object: %w", err))
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutated)
+}
+
+// validatePackage checks a Package against its PackageSource
+func (w *PackageWebhook) validatePackage(ctx context.Context, req admission.Request) admission.Response {
+	pkg := &cozyv1alpha1.Package{}
+	if err := w.decoder.Decode(req, pkg); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("decode object: %w", err))
+	}
+
+	packageSource := &cozyv1alpha1.PackageSource{}
+	if err := w.Get(ctx, types.NamespacedName{Name: pkg.Name}, packageSource); apierrors.IsNotFound(err) {
+		// The PackageSource may be applied after the Package
+		return admission.Allowed("").WithWarnings(fmt.Sprintf("PackageSource %s not found, the Package is not validated", pkg.Name))
+	} else if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	errs, warnings := validatePackageAgainstSource(pkg, packageSource)
+	if len(errs) == 0 {
+		ignoreErrs, ignoreWarnings, err := w.validateIgnoredDependencies(ctx, pkg)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		errs = append(errs, ignoreErrs...)
+		warnings = append(warnings, ignoreWarnings...)
+	}
+	if len(errs) > 0 {
+		return admission.Denied(errs.ToAggregate().Error()).WithWarnings(warnings...)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// validatePackageSource checks the consistency of a PackageSource
+func (w *PackageWebhook) validatePackageSource(ctx context.Context, req admission.Request) admission.Response {
+	packageSource := &cozyv1alpha1.PackageSource{}
+	if err := w.decoder.Decode(req, packageSource); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("decode object: %w", err))
+	}
+
+	errs := validatePackageSourceSpec(packageSource)
+	if len(errs) > 0 {
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+
+	// Dependencies may be applied later, so unresolved ones are only reported
+	var warnings []string
+	seen := map[string]bool{}
+	for _, variant := range packageSource.Spec.Variants {
+		for _, dep := range variant.DependsOn {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			err := w.Get(ctx, types.NamespacedName{Name: dep}, &cozyv1alpha1.PackageSource{})
+			if apierrors.IsNotFound(err) {
+				warnings = append(warnings, fmt.Sprintf("dependency %s: PackageSource not found", dep))
+			} else if err != nil {
+				return admission.Errored(http.StatusInternalServerError, err)
+			}
+		}
+	}
+
+	// Cycles are reported rather than denied, as they may be broken by
+	// PackageSources applied next
+	graph, err := loadDependencyGraph(ctx, w.Client)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	graph.sources[packageSource.Name] = packageSource
+	if cycle := graph.cycle(packageSource.Name); cycle != nil {
+		warnings = append(warnings, fmt.Sprintf("dependency cycle: %s, the Package will never become ready", formatCycle(cycle)))
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// validatePackageSourceSpec rejects duplicate source aliases, variant and component names,
+// components referencing undeclared sources, invalid component conditions and values schemas,
+// and component dependencies that do not resolve to an installed component of the same variant
+func validatePackageSourceSpec(packageSource *cozyv1alpha1.PackageSource) field.ErrorList {
+	var errs field.ErrorList
+	aliases := map[string]bool{}
+	if len(packageSource.Spec.SourceRefs) > 0 && packageSource.Spec.SourceRef == nil {
+		errs = append(errs, field.Required(field.NewPath("spec", "sourceRef"), "sourceRefs require a sourceRef"))
+	}
+	for i, ref := range packageSource.Spec.SourceRefs {
+		aliasPath := field.NewPath("spec", "sourceRefs").Index(i).Child("alias")
+		switch {
+		case ref.Alias == "":
+			errs = append(errs, field.Required(aliasPath, "sources must have an alias"))
+		case aliases[ref.Alias]:
+			errs = append(errs, field.Duplicate(aliasPath, ref.Alias))
+		}
+		aliases[ref.Alias] = true
+	}
+
+	variantNames := map[string]bool{}
+	for i, variant := range packageSource.Spec.Variants {
+		variantPath := field.NewPath("spec", "variants").Index(i)
+		if variantNames[variant.Name] {
+			errs = append(errs, field.Duplicate(variantPath.Child("name"), variant.Name))
+		}
+		variantNames[variant.Name] = true
+
+		componentNames := map[string]bool{}
+		installed := map[string]bool{}
+		for j, component := range variant.Components {
+			if componentNames[component.Name] {
+				errs = append(errs, field.Duplicate(variantPath.Child("components").Index(j).Child("name"), component.Name))
+			}
+			componentNames[component.Name] = true
+			if component.Source != "" && !aliases[component.Source] {
+				errs = append(errs, field.NotFound(variantPath.Child("components").Index(j).Child("source"), component.Source))
+			}
+			for k, overlay := range component.Overlays {
+				if overlay.Source != "" && !aliases[overlay.Source] {
+					errs = append(errs, field.NotFound(variantPath.Child("components").Index(j).Child("overlays").Index(k).Child("source"), overlay.Source))
+				}
+			}
+			if component.When != "" {
+				if err := ValidateCondition(component.When); err != nil {
+					errs = append(errs, field.Invalid(variantPath.Child("components").Index(j).Child("when"), component.When, err.Error()))
+				}
+			}
+			if component.ValuesSchema != nil {
+				if err := ValidateValuesSchema(component.ValuesSchema); err != nil {
+					errs = append(errs, field.Invalid(variantPath.Child("components").Index(j).Child("valuesSchema"), string(component.ValuesSchema.Raw), err.Error()))
+				}
+			}
+			if component.Install != nil {
+				installed[component.Name] = true
+			}
+		}
+
+		for j, component := range variant.Components {
+			if component.Install == nil {
+				continue
+			}
+			installPath := variantPath.Child("components").Index(j).Child("install")
+			if component.Install.Namespace == "" {
+				errs = append(errs, field.Required(installPath.Child("namespace"), "components with install must set a namespace"))
+			}
+			for k, dep := range component.Install.DependsOn {
+				depPath := installPath.Child("dependsOn").Index(k)
+				switch {
+				case dep == component.Name:
+					errs = append(errs, field.Invalid(depPath, dep, "a component cannot depend on itself"))
+				case !installed[dep]:
+					errs = append(errs, field.NotFound(depPath, dep))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validatePackageAgainstSource rejects Packages selecting a variant that does not
+// exist in packageSource, with component values that do not match their values
+// schema, ignoring dependencies the variant does not have, or pinned to a
+// revision the source of packageSource cannot check out, and warns about
+// overrides that have no effect
+func validatePackageAgainstSource(pkg *cozyv1alpha1.Package, packageSource *cozyv1alpha1.PackageSource) (field.ErrorList, []string) {
+	variantName := pkg.Spec.Variant
+	if variantName == "" {
+		variantName = DefaultVariant
+	}
+	var variant *cozyv1alpha1.Variant
+	names := make([]string, 0, len(packageSource.Spec.Variants))
+	for i := range packageSource.Spec.Variants {
+		names = append(names, packageSource.Spec.Variants[i].Name)
+		if packageSource.Spec.Variants[i].Name == variantName {
+			variant = &packageSource.Spec.Variants[i]
+		}
+	}
+	if variant == nil {
+		return field.ErrorList{field.NotSupported(field.NewPath("spec", "variant"), variantName, names)}, nil
+	}
+
+	var warnings []string
+	components := map[string]bool{}
+	for _, component := range variant.Components {
+		components[component.Name] = true
+	}
+	overrides := make([]string, 0, len(pkg.Spec.Components))
+	for name := range pkg.Spec.Components {
+		overrides = append(overrides, name)
+	}
+	sort.Strings(overrides)
+	for _, name := range overrides {
+		if !components[name] {
+			warnings = append(warnings, fmt.Sprintf("spec.components: component %s not found in variant %s", name, variantName))
+		}
+	}
+	errs := ValidateComponentValues(pkg, variant)
+	dependencies := map[string]bool{}
+	for _, dep := range variant.DependsOn {
+		dependencies[dep] = true
+	}
+	for i, dep := range pkg.Spec.IgnoreDependencies {
+		if !dependencies[dep] {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "ignoreDependencies").Index(i), dep,
+				fmt.Sprintf("not a dependency of variant %s", variantName)))
+		}
+	}
+	if pkg.Spec.Revision != "" {
+		revisionPath := field.NewPath("spec", "revision")
+		if packageSource.Spec.SourceRef == nil {
+			errs = append(errs, field.Invalid(revisionPath, pkg.Spec.Revision, "PackageSource has no sourceRef to pin"))
+		} else if _, err := parsePinnedRevision(packageSource.Spec.SourceRef.Kind, pkg.Spec.Revision); err != nil {
+			errs = append(errs, field.Invalid(revisionPath, pkg.Spec.Revision, err.Error()))
+		}
+	}
+	return errs, warnings
+}
+
+// validateIgnoredDependencies requires ignoring a core dependency to be confirmed
+// with an annotation, and warns about every other ignored dependency, which the
+// Package no longer waits for
+func (w *PackageWebhook) validateIgnoredDependencies(ctx context.Context, pkg *cozyv1alpha1.Package) (field.ErrorList, []string, error) {
+	confirmed := map[string]bool{}
+	for _, name := range strings.Split(pkg.Annotations[cozyv1alpha1.PackageConfirmIgnoreCoreDependenciesAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			confirmed[name] = true
+		}
+	}
+
+	var errs field.ErrorList
+	var warnings []string
+	for i, dep := range pkg.Spec.IgnoreDependencies {
+		depSource := &cozyv1alpha1.PackageSource{}
+		err := w.Get(ctx, types.NamespacedName{Name: dep}, depSource)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, nil, err
+		}
+		if err == nil && depSource.Labels[cozyv1alpha1.PackageSourceCoreLabel] == "true" && !confirmed[dep] {
+			errs = append(errs, field.Forbidden(field.NewPath("spec", "ignoreDependencies").Index(i),
+				fmt.Sprintf("%s is a core package, ignoring it requires listing it in the %s annotation",
+					dep, cozyv1alpha1.PackageConfirmIgnoreCoreDependenciesAnnotation)))
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("spec.ignoreDependencies: the Package does not wait for %s, which must be provided otherwise", dep))
+	}
+	return errs, warnings, nil
+}
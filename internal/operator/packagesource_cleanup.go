@@ -0,0 +1,89 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcewatcherv1beta1 "github.com/fluxcd/source-watcher/api/v2/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PackageSourceFinalizer keeps a deleted PackageSource until the
+// ArtifactGenerator and the OCIRepositories and pinned sources generated for it
+// are deleted, so that the artifacts they published are revoked
+const PackageSourceFinalizer = "operator.cozystack.io/packagesource-cleanup"
+
+// reconcileDelete deletes the objects generated for a deleted PackageSource
+// and removes the finalizer once they are gone
+func (r *PackageSourceReconciler) reconcileDelete(ctx context.Context, packageSource *cozyv1alpha1.PackageSource) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(packageSource, PackageSourceFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	remaining := 0
+	lists := []client.ObjectList{
+		&sourcewatcherv1beta1.ArtifactGeneratorList{},
+		&sourcev1.OCIRepositoryList{},
+		&sourcev1.GitRepositoryList{},
+	}
+	for _, list := range lists {
+		if err := r.List(ctx, list, client.MatchingLabels{LabelPackageSource: packageSource.Name}); err != nil {
+			return ctrl.Result{}, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+			remaining++
+			if !obj.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+			gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to delete %s %s/%s: %w", gvk.Kind, obj.GetNamespace(), obj.GetName(), err)
+			}
+			logger.Info("deleted generated object", "kind", gvk.Kind, "name", obj.GetName(), logging.KeyNamespace, obj.GetNamespace())
+		}
+	}
+	if remaining > 0 {
+		// Flux finalizes the deleted objects, check again once it is done
+		return ctrl.Result{RequeueAfter: cleanupRetryInterval}, nil
+	}
+
+	controllerutil.RemoveFinalizer(packageSource, PackageSourceFinalizer)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Update(ctx, packageSource))
+}
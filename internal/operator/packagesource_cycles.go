@@ -0,0 +1,212 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DependencyCycleCondition is the condition of PackageSources whose variants
+// depend on themselves through other PackageSources. Packages of such
+// PackageSources would wait for their dependencies forever.
+const DependencyCycleCondition = "DependencyCycle"
+
+// dependencyGraph holds the dependencies between packages, as declared by the
+// variants of their PackageSources
+type dependencyGraph struct {
+	sources  map[string]*cozyv1alpha1.PackageSource
+	packages map[string]*cozyv1alpha1.Package
+}
+
+// loadDependencyGraph reads the PackageSources and Packages of the cluster
+func loadDependencyGraph(ctx context.Context, c client.Client) (*dependencyGraph, error) {
+	var sources cozyv1alpha1.PackageSourceList
+	if err := c.List(ctx, &sources); err != nil {
+		return nil, fmt.Errorf("failed to list PackageSources: %w", err)
+	}
+	var packages cozyv1alpha1.PackageList
+	if err := c.List(ctx, &packages); err != nil {
+		return nil, fmt.Errorf("failed to list Packages: %w", err)
+	}
+	g := &dependencyGraph{
+		sources:  make(map[string]*cozyv1alpha1.PackageSource, len(sources.Items)),
+		packages: make(map[string]*cozyv1alpha1.Package, len(packages.Items)),
+	}
+	for i := range sources.Items {
+		g.sources[sources.Items[i].Name] = &sources.Items[i]
+	}
+	for i := range packages.Items {
+		g.packages[packages.Items[i].Name] = &packages.Items[i]
+	}
+	return g, nil
+}
+
+// dependencies returns the packages name depends on. Installed packages depend
+// on the dependencies of their variant that are not ignored, other packages on
+// the dependencies of all variants of their PackageSource, as any of them may
+// be selected.
+func (g *dependencyGraph) dependencies(name string) []string {
+	source, ok := g.sources[name]
+	if !ok {
+		return nil
+	}
+	if pkg, ok := g.packages[name]; ok {
+		variantName := pkg.Spec.Variant
+		if variantName == "" {
+			variantName = DefaultVariant
+		}
+		ignored := map[string]bool{}
+		for _, dep := range pkg.Spec.IgnoreDependencies {
+			ignored[dep] = true
+		}
+		for _, variant := range source.Spec.Variants {
+			if variant.Name != variantName {
+				continue
+			}
+			var deps []string
+			for _, dep := range variant.DependsOn {
+				if !ignored[dep] {
+					deps = append(deps, dep)
+				}
+			}
+			return deps
+		}
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var deps []string
+	for _, variant := range source.Spec.Variants {
+		for _, dep := range variant.DependsOn {
+			if !seen[dep] {
+				seen[dep] = true
+				deps = append(deps, dep)
+			}
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// cycle returns the shortest dependency cycle through the package name, as the
+// packages along it starting and ending with name, or nil if there is none
+func (g *dependencyGraph) cycle(name string) []string {
+	parents := map[string]string{}
+	visited := map[string]bool{name: true}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dep := range g.dependencies(current) {
+			if dep == name {
+				path := []string{name}
+				for n := current; n != name; n = parents[n] {
+					path = append(path, n)
+				}
+				path = append(path, name)
+				// The path was collected from the end of the cycle
+				for i, j := 1, len(path)-2; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if !visited[dep] {
+				visited[dep] = true
+				parents[dep] = current
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// dependents returns the packages depending on the package name, directly or
+// through other packages. Only the dependency cycles through them can be made
+// or broken by a change of the dependencies of name.
+func (g *dependencyGraph) dependents(name string) []string {
+	reverse := map[string][]string{}
+	for source := range g.sources {
+		for _, dep := range g.dependencies(source) {
+			reverse[dep] = append(reverse[dep], source)
+		}
+	}
+	visited := map[string]bool{name: true}
+	queue := []string{name}
+	var dependents []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range reverse[current] {
+			if !visited[dependent] {
+				visited[dependent] = true
+				dependents = append(dependents, dependent)
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// formatCycle formats a dependency cycle returned by dependencyGraph.cycle
+func formatCycle(cycle []string) string {
+	return strings.Join(cycle, " -> ")
+}
+
+// setDependencyCycleCondition sets the DependencyCycle condition of
+// packageSource, reporting the dependency cycle through it if there is one
+func (r *PackageSourceReconciler) setDependencyCycleCondition(ctx context.Context, packageSource *cozyv1alpha1.PackageSource) error {
+	graph, err := loadDependencyGraph(ctx, r.Client)
+	if err != nil {
+		return err
+	}
+	// Check the spec being reconciled rather than the cached one
+	graph.sources[packageSource.Name] = packageSource
+
+	cycle := graph.cycle(packageSource.Name)
+	if cycle == nil {
+		meta.SetStatusCondition(&packageSource.Status.Conditions, metav1.Condition{
+			Type:               DependencyCycleCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NoCycle",
+			Message:            "No dependency cycle",
+			ObservedGeneration: packageSource.Generation,
+		})
+		return nil
+	}
+	message := fmt.Sprintf("Dependency cycle: %s", formatCycle(cycle))
+	if !meta.IsStatusConditionTrue(packageSource.Status.Conditions, DependencyCycleCondition) {
+		r.Recorder.Event(packageSource, corev1.EventTypeWarning, "DependencyCycle", message)
+	}
+	meta.SetStatusCondition(&packageSource.Status.Conditions, metav1.Condition{
+		Type:               DependencyCycleCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "CycleDetected",
+		Message:            message,
+		ObservedGeneration: packageSource.Generation,
+	})
+	return nil
+}
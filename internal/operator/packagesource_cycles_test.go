@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"slices"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDependencyGraphDependents(t *testing.T) {
+	source := func(name string, dependsOn ...string) *cozyv1alpha1.PackageSource {
+		return &cozyv1alpha1.PackageSource{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: cozyv1alpha1.PackageSourceSpec{
+				Variants: []cozyv1alpha1.Variant{{Name: DefaultVariant, DependsOn: dependsOn}},
+			},
+		}
+	}
+	// a -> b -> c -> a, d -> a, e is independent
+	graph := &dependencyGraph{
+		sources: map[string]*cozyv1alpha1.PackageSource{
+			"a": source("a", "b"),
+			"b": source("b", "c"),
+			"c": source("c", "a"),
+			"d": source("d", "a"),
+			"e": source("e"),
+		},
+		packages: map[string]*cozyv1alpha1.Package{},
+	}
+
+	cases := []struct {
+		name string
+		want []string
+	}{
+		// Every package of the cycle is a dependent of the others, so breaking
+		// the cycle at c requeues a
+		{name: "c", want: []string{"a", "b", "d"}},
+		{name: "a", want: []string{"b", "c", "d"}},
+		{name: "d", want: nil},
+		{name: "e", want: nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := graph.dependents(tc.name); !slices.Equal(got, tc.want) {
+				t.Errorf("dependents(%q) = %v, want %v", tc.name, got, tc.want)
+			}
+		})
+	}
+}
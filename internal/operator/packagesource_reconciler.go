@@ -19,34 +19,62 @@ package operator
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+	"github.com/cozystack/cozystack/internal/shared/reconcilemetrics"
 	sourcewatcherv1beta1 "github.com/fluxcd/source-watcher/api/v2/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // PackageSourceReconciler reconciles PackageSource resources
 type PackageSourceReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Shard selects the PackageSources reconciled by this replica, all of them if unset
+	Shard Shard
 }
 
 // +kubebuilder:rbac:groups=cozystack.io,resources=packagesources,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cozystack.io,resources=packagesources/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cozystack.io,resources=packages,verbs=get;list;watch
 // +kubebuilder:rbac:groups=source.extensions.fluxcd.io,resources=artifactgenerators,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=ocirepositories,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *PackageSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
+	// PackageSources of other shards are reconciled by another replica
+	if !r.Shard.Owns(req.Name) {
+		return ctrl.Result{}, nil
+	}
+	start := time.Now()
+	result, err := r.reconcile(ctx, req)
+	reconcilemetrics.Observe(packageSourceControllerName, start, result, err)
+	return result, err
+}
+
+func (r *PackageSourceReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues(logging.KeyPackageSource, req.Name)
+	ctx = log.IntoContext(ctx, logger)
 
 	packageSource := &cozyv1alpha1.PackageSource{}
 	if err := r.Get(ctx, req.NamespacedName, packageSource); err != nil {
@@ -57,12 +85,35 @@ func (r *PackageSourceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	// Deleted PackageSources are kept until their generated objects are gone
+	if !packageSource.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, packageSource)
+	}
+	if controllerutil.AddFinalizer(packageSource, PackageSourceFinalizer) {
+		if err := r.Update(ctx, packageSource); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Generate OCIRepositories for components referencing published charts
+	if err := r.reconcileOCIRepositories(ctx, packageSource); err != nil {
+		logger.Error(err, "failed to reconcile OCIRepositories")
+		r.Recorder.Event(packageSource, corev1.EventTypeWarning, "OCIRepositoryFailed", err.Error())
+		return ctrl.Result{}, err
+	}
+
 	// Generate ArtifactGenerator for package source
 	if err := r.reconcileArtifactGenerators(ctx, packageSource); err != nil {
 		logger.Error(err, "failed to reconcile ArtifactGenerator")
+		r.Recorder.Event(packageSource, corev1.EventTypeWarning, "ArtifactGeneratorFailed", err.Error())
 		return ctrl.Result{}, err
 	}
 
+	// Report dependency cycles, which would leave the Packages waiting forever
+	if err := r.setDependencyCycleCondition(ctx, packageSource); err != nil {
+		logger.Error(err, "failed to check dependency cycles")
+	}
+
 	// Update PackageSource status (variants and conditions from ArtifactGenerator)
 	if err := r.updateStatus(ctx, packageSource); err != nil {
 		logger.Error(err, "failed to update status")
@@ -77,10 +128,59 @@ func (r *PackageSourceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 func (r *PackageSourceReconciler) reconcileArtifactGenerators(ctx context.Context, packageSource *cozyv1alpha1.PackageSource) error {
 	logger := log.FromContext(ctx)
 
+	// A pinned Package has its artifacts generated from a pinned copy of the source
+	sourceRef, err := r.reconcilePinnedSource(ctx, packageSource)
+	if err != nil {
+		return err
+	}
+	renderSource := packageSource
+	if sourceRef != packageSource.Spec.SourceRef {
+		renderSource = packageSource.DeepCopy()
+		renderSource.Spec.SourceRef = sourceRef
+	}
+
+	ag, err := r.RenderArtifactGenerator(ctx, renderSource)
+	if err != nil || ag == nil {
+		return err
+	}
+	agName, namespace, outputArtifacts := ag.Name, ag.Namespace, ag.Spec.OutputArtifacts
+
+	// Set ownerReference
+	gvk, err := apiutil.GVKForObject(packageSource, r.Scheme)
+	if err != nil {
+		return fmt.Errorf("failed to get GVK for PackageSource: %w", err)
+	}
+	ag.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Name:       packageSource.Name,
+			UID:        packageSource.UID,
+			Controller: func() *bool { b := true; return &b }(),
+		},
+	}
+
+	logger.Info("creating ArtifactGenerator for package source", "agName", agName, logging.KeyNamespace, namespace, "outputArtifactCount", len(outputArtifacts))
+
+	if err := r.createOrUpdate(ctx, ag); err != nil {
+		return fmt.Errorf("failed to reconcile ArtifactGenerator %s: %w", agName, err)
+	}
+
+	logger.Info("reconciled ArtifactGenerator for package source", "name", agName, logging.KeyNamespace, namespace, "outputArtifactCount", len(outputArtifacts))
+
+	return nil
+}
+
+// RenderArtifactGenerator returns the ArtifactGenerator the reconciler generates for
+// packageSource, without owner references, or nil if there are no artifacts to build.
+// It does not modify the cluster, so it can be used to preview a PackageSource.
+func (r *PackageSourceReconciler) RenderArtifactGenerator(ctx context.Context, packageSource *cozyv1alpha1.PackageSource) (*sourcewatcherv1beta1.ArtifactGenerator, error) {
+	logger := log.FromContext(ctx)
+
 	// Check if SourceRef is set
 	if packageSource.Spec.SourceRef == nil {
-		logger.Info("skipping ArtifactGenerator creation, SourceRef not set", "packageSource", packageSource.Name)
-		return nil
+		logger.Info("skipping ArtifactGenerator creation, SourceRef not set")
+		return nil, nil
 	}
 
 	// Namespace is always cozy-system
@@ -91,57 +191,110 @@ func (r *PackageSourceReconciler) reconcileArtifactGenerators(ctx context.Contex
 	// Collect all OutputArtifacts
 	outputArtifacts := []sourcewatcherv1beta1.OutputArtifact{}
 
+	// Sources of the ArtifactGenerator: the sources of this package source,
+	// plus the sources of package sources whose libraries are referenced
+	sources := newArtifactSources(packageSource.Spec.SourceRef)
+	// Referenced package sources, looked up once per reconcile
+	referencedSources := map[string]*cozyv1alpha1.PackageSource{}
+
 	// Process all variants and their components
 	for _, variant := range packageSource.Spec.Variants {
 		// Build library map for this variant
 		// Map key is the library name (from lib.Name or extracted from path)
 		// This allows components in this variant to reference libraries by name
 		// Libraries are scoped per variant to avoid conflicts between variants
-		libraryMap := make(map[string]cozyv1alpha1.Library)
+		libraryMap := make(map[string]libraryLocation)
 		for _, lib := range variant.Libraries {
 			libName := lib.Name
+			if libName == "" && lib.LibraryRef != nil {
+				libName = lib.LibraryRef.Name
+			}
 			if libName == "" {
 				// If library name is not set, extract from path
 				libName = r.getPackageNameFromPath(lib.Path)
 			}
-			if libName != "" {
+			if libName == "" {
+				continue
+			}
+			if lib.LibraryRef == nil {
 				// Store library with the resolved name
-				libraryMap[libName] = lib
+				libraryMap[libName] = libraryLocation{
+					source:   packageSource.Spec.SourceRef.Name,
+					basePath: r.getBasePath(packageSource.Spec.SourceRef),
+					path:     lib.Path,
+				}
+				continue
+			}
+			location, err := r.resolveLibraryRef(ctx, lib.LibraryRef, referencedSources, sources)
+			if err != nil {
+				return nil, err
 			}
+			if location == nil {
+				logger.Info("skipping unresolved library reference", "variant", variant.Name,
+					"library", libName, "referencedPackageSource", lib.LibraryRef.PackageSource, "referencedLibrary", lib.LibraryRef.Name)
+				continue
+			}
+			libraryMap[libName] = *location
 		}
 
 		for _, component := range variant.Components {
+			// Published charts are not built from the source, see reconcileOCIRepositories
+			if component.ChartRef != nil {
+				continue
+			}
+
 			// Skip components without path
 			if component.Path == "" {
-				logger.V(1).Info("skipping component without path", "packageSource", packageSource.Name, "variant", variant.Name, "component", component.Name)
+				logger.V(1).Info("skipping component without path", "variant", variant.Name, logging.KeyComponent, component.Name)
 				continue
 			}
 
-			logger.V(1).Info("processing component", "packageSource", packageSource.Name, "variant", variant.Name, "component", component.Name, "path", component.Path)
+			logger.V(1).Info("processing component", "variant", variant.Name, logging.KeyComponent, component.Name, "path", component.Path)
 
 			// Extract component name from path (last component)
 			componentPathName := r.getPackageNameFromPath(component.Path)
 			if componentPathName == "" {
-				logger.Info("skipping component with invalid path", "packageSource", packageSource.Name, "variant", variant.Name, "component", component.Name, "path", component.Path)
+				logger.Info("skipping component with invalid path", "variant", variant.Name, logging.KeyComponent, component.Name, "path", component.Path)
 				continue
 			}
 
-			// Get basePath with default values
-			basePath := r.getBasePath(packageSource)
+			// The chart and the values files are taken from the source of the component
+			sourceRef := sourceRefByAlias(packageSource, component.Source)
+			if sourceRef == nil {
+				return nil, fmt.Errorf("component %s of variant %s references unknown source %q", component.Name, variant.Name, component.Source)
+			}
+			source := sources.add(sourceRef)
+			basePath := r.getBasePath(sourceRef)
 
 			// Build copy operations
 			copyOps := []sourcewatcherv1beta1.CopyOperation{
 				{
-					From: r.buildSourcePath(packageSource.Spec.SourceRef.Name, basePath, component.Path),
+					From: r.buildSourcePath(source, basePath, component.Path),
 					To:   fmt.Sprintf("@artifact/%s/", componentPathName),
 				},
 			}
 
+			// Overlays are copied over the chart, before the libraries and the values files
+			for _, overlay := range component.Overlays {
+				overlayRef := sourceRefByAlias(packageSource, overlay.Source)
+				if overlayRef == nil {
+					return nil, fmt.Errorf("overlay of component %s of variant %s references unknown source %q", component.Name, variant.Name, overlay.Source)
+				}
+				to := fmt.Sprintf("@artifact/%s/", componentPathName)
+				if dir := strings.Trim(overlay.To, "/"); dir != "" {
+					to = fmt.Sprintf("@artifact/%s/%s/", componentPathName, dir)
+				}
+				copyOps = append(copyOps, sourcewatcherv1beta1.CopyOperation{
+					From: r.buildSourcePath(sources.add(overlayRef), r.getBasePath(overlayRef), overlay.Path),
+					To:   to,
+				})
+			}
+
 			// Add libraries if specified
 			for _, libName := range component.Libraries {
 				if lib, ok := libraryMap[libName]; ok {
 					copyOps = append(copyOps, sourcewatcherv1beta1.CopyOperation{
-						From: r.buildSourcePath(packageSource.Spec.SourceRef.Name, basePath, lib.Path),
+						From: r.buildSourcePath(lib.source, lib.basePath, lib.path),
 						To:   fmt.Sprintf("@artifact/%s/charts/%s/", componentPathName, libName),
 					})
 				}
@@ -154,37 +307,33 @@ func (r *PackageSourceReconciler) reconcileArtifactGenerators(ctx context.Contex
 					strategy = "Overwrite"
 				}
 				copyOps = append(copyOps, sourcewatcherv1beta1.CopyOperation{
-					From:     r.buildSourceFilePath(packageSource.Spec.SourceRef.Name, basePath, fmt.Sprintf("%s/%s", component.Path, valuesFile)),
+					From:     r.buildSourceFilePath(source, basePath, fmt.Sprintf("%s/%s", component.Path, valuesFile)),
 					To:       fmt.Sprintf("@artifact/%s/values.yaml", componentPathName),
 					Strategy: strategy,
 				})
 			}
 
 			// Artifact name: <packagesource>-<variant>-<componentname>
-			// Replace dots with dashes to comply with Kubernetes naming requirements
-			artifactName := fmt.Sprintf("%s-%s-%s",
-				strings.ReplaceAll(packageSource.Name, ".", "-"),
-				strings.ReplaceAll(variant.Name, ".", "-"),
-				strings.ReplaceAll(component.Name, ".", "-"))
+			artifactName := ComponentArtifactName(packageSource.Name, variant.Name, component.Name)
 
 			outputArtifacts = append(outputArtifacts, sourcewatcherv1beta1.OutputArtifact{
 				Name: artifactName,
 				Copy: copyOps,
 			})
 
-			logger.Info("added OutputArtifact for component", "packageSource", packageSource.Name, "variant", variant.Name, "component", component.Name, "artifactName", artifactName)
+			logger.Info("added OutputArtifact for component", "variant", variant.Name, logging.KeyComponent, component.Name, "artifactName", artifactName)
 		}
 	}
 
 	// If there are no OutputArtifacts, return (ownerReference will handle cleanup if needed)
 	if len(outputArtifacts) == 0 {
-		logger.Info("no OutputArtifacts to generate, skipping ArtifactGenerator creation", "packageSource", packageSource.Name)
-		return nil
+		logger.Info("no OutputArtifacts to generate, skipping ArtifactGenerator creation")
+		return nil, nil
 	}
 
 	// Build labels
 	labels := make(map[string]string)
-	labels["cozystack.io/packagesource"] = packageSource.Name
+	labels[LabelPackageSource] = packageSource.Name
 
 	// Create single ArtifactGenerator for the package source
 	ag := &sourcewatcherv1beta1.ArtifactGenerator{
@@ -194,42 +343,112 @@ func (r *PackageSourceReconciler) reconcileArtifactGenerators(ctx context.Contex
 			Labels:    labels,
 		},
 		Spec: sourcewatcherv1beta1.ArtifactGeneratorSpec{
-			Sources: []sourcewatcherv1beta1.SourceReference{
-				{
-					Alias:     packageSource.Spec.SourceRef.Name,
-					Kind:      packageSource.Spec.SourceRef.Kind,
-					Name:      packageSource.Spec.SourceRef.Name,
-					Namespace: packageSource.Spec.SourceRef.Namespace,
-				},
-			},
+			Sources:         sources.refs,
 			OutputArtifacts: outputArtifacts,
 		},
 	}
+	return ag, nil
+}
 
-	// Set ownerReference
-	gvk, err := apiutil.GVKForObject(packageSource, r.Scheme)
-	if err != nil {
-		return fmt.Errorf("failed to get GVK for PackageSource: %w", err)
+// libraryLocation is where the chart of a library is copied from
+type libraryLocation struct {
+	// source is the alias of the ArtifactGenerator source holding the library
+	source   string
+	basePath string
+	path     string
+}
+
+// artifactSources collects the sources of an ArtifactGenerator, adding each
+// source once so that libraries shared between package sources are fetched once
+type artifactSources struct {
+	refs    []sourcewatcherv1beta1.SourceReference
+	aliases map[cozyv1alpha1.PackageSourceRef]string
+}
+
+func newArtifactSources(primary *cozyv1alpha1.PackageSourceRef) *artifactSources {
+	s := &artifactSources{aliases: map[cozyv1alpha1.PackageSourceRef]string{}}
+	s.add(primary)
+	return s
+}
+
+// add adds ref to the sources unless it is already there and returns its alias
+func (s *artifactSources) add(ref *cozyv1alpha1.PackageSourceRef) string {
+	key := cozyv1alpha1.PackageSourceRef{Kind: ref.Kind, Name: ref.Name, Namespace: ref.Namespace}
+	if alias, ok := s.aliases[key]; ok {
+		return alias
 	}
-	ag.OwnerReferences = []metav1.OwnerReference{
-		{
-			APIVersion: gvk.GroupVersion().String(),
-			Kind:       gvk.Kind,
-			Name:       packageSource.Name,
-			UID:        packageSource.UID,
-			Controller: func() *bool { b := true; return &b }(),
-		},
+	// The primary source is aliased by its name; further sources get a unique alias
+	alias := ref.Name
+	if len(s.refs) > 0 {
+		alias = fmt.Sprintf("%s-%d", ref.Name, len(s.refs))
 	}
+	s.aliases[key] = alias
+	s.refs = append(s.refs, sourcewatcherv1beta1.SourceReference{
+		Alias:     alias,
+		Kind:      ref.Kind,
+		Name:      ref.Name,
+		Namespace: ref.Namespace,
+	})
+	return alias
+}
 
-	logger.Info("creating ArtifactGenerator for package source", "packageSource", packageSource.Name, "agName", agName, "namespace", namespace, "outputArtifactCount", len(outputArtifacts))
-
-	if err := r.createOrUpdate(ctx, ag); err != nil {
-		return fmt.Errorf("failed to reconcile ArtifactGenerator %s: %w", agName, err)
+// resolveLibraryRef returns the location of a library declared by another package
+// source, adding its source to sources. It returns nil if the package source or
+// the library doesn't exist; the reconciler is triggered again when it is created.
+func (r *PackageSourceReconciler) resolveLibraryRef(
+	ctx context.Context,
+	ref *cozyv1alpha1.LibraryReference,
+	referencedSources map[string]*cozyv1alpha1.PackageSource,
+	sources *artifactSources,
+) (*libraryLocation, error) {
+	ps, ok := referencedSources[ref.PackageSource]
+	if !ok {
+		ps = &cozyv1alpha1.PackageSource{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.PackageSource}, ps); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get PackageSource %s: %w", ref.PackageSource, err)
+			}
+			ps = nil
+		}
+		referencedSources[ref.PackageSource] = ps
+	}
+	if ps == nil || ps.Spec.SourceRef == nil {
+		return nil, nil
 	}
 
-	logger.Info("reconciled ArtifactGenerator for package source", "name", agName, "namespace", namespace, "outputArtifactCount", len(outputArtifacts))
+	for _, variant := range ps.Spec.Variants {
+		for _, lib := range variant.Libraries {
+			// Libraries are not resolved transitively
+			if lib.LibraryRef != nil || lib.Path == "" {
+				continue
+			}
+			libName := lib.Name
+			if libName == "" {
+				libName = r.getPackageNameFromPath(lib.Path)
+			}
+			if libName != ref.Name {
+				continue
+			}
+			return &libraryLocation{
+				source:   sources.add(ps.Spec.SourceRef),
+				basePath: r.getBasePath(ps.Spec.SourceRef),
+				path:     lib.Path,
+			}, nil
+		}
+	}
+	return nil, nil
+}
 
-	return nil
+// referencesLibrariesOf reports whether packageSource references libraries of the package source name
+func referencesLibrariesOf(packageSource *cozyv1alpha1.PackageSource, name string) bool {
+	for _, variant := range packageSource.Spec.Variants {
+		for _, lib := range variant.Libraries {
+			if lib.LibraryRef != nil && lib.LibraryRef.PackageSource == name {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Helper functions
@@ -241,11 +460,25 @@ func (r *PackageSourceReconciler) getPackageNameFromPath(path string) string {
 	return ""
 }
 
-// getBasePath returns the basePath with default values based on source kind
-func (r *PackageSourceReconciler) getBasePath(packageSource *cozyv1alpha1.PackageSource) string {
+// sourceRefByAlias returns the source of packageSource with alias, SourceRef
+// for an empty alias, or nil if there is no such source
+func sourceRefByAlias(packageSource *cozyv1alpha1.PackageSource, alias string) *cozyv1alpha1.PackageSourceRef {
+	if alias == "" {
+		return packageSource.Spec.SourceRef
+	}
+	for i := range packageSource.Spec.SourceRefs {
+		if packageSource.Spec.SourceRefs[i].Alias == alias {
+			return &packageSource.Spec.SourceRefs[i].PackageSourceRef
+		}
+	}
+	return nil
+}
+
+// getBasePath returns the basePath of ref with default values based on source kind
+func (r *PackageSourceReconciler) getBasePath(ref *cozyv1alpha1.PackageSourceRef) string {
 	// If path is explicitly set in SourceRef, use it (but normalize "/" to empty)
-	if packageSource.Spec.SourceRef.Path != "" {
-		path := strings.Trim(packageSource.Spec.SourceRef.Path, "/")
+	if ref.Path != "" {
+		path := strings.Trim(ref.Path, "/")
 		// If path is "/" or empty after trim, return empty string
 		if path == "" {
 			return ""
@@ -253,7 +486,7 @@ func (r *PackageSourceReconciler) getBasePath(packageSource *cozyv1alpha1.Packag
 		return path
 	}
 	// Default values based on kind
-	if packageSource.Spec.SourceRef.Kind == "OCIRepository" {
+	if ref.Kind == "OCIRepository" {
 		return "" // Root for OCI
 	}
 	// Default for GitRepository
@@ -384,6 +617,27 @@ func (r *PackageSourceReconciler) updateStatus(ctx context.Context, packageSourc
 		return r.Status().Update(ctx, packageSource)
 	}
 
+	// Report readiness transitions of the artifacts
+	if previous := meta.FindStatusCondition(packageSource.Status.Conditions, "Ready"); previous == nil || previous.Status != readyCondition.Status {
+		switch readyCondition.Status {
+		case metav1.ConditionTrue:
+			r.Recorder.Event(packageSource, corev1.EventTypeNormal, "ArtifactsReady", readyCondition.Message)
+		case metav1.ConditionFalse:
+			r.Recorder.Eventf(packageSource, corev1.EventTypeWarning, "ArtifactsNotReady", "%s: %s", readyCondition.Reason, readyCondition.Message)
+		}
+	}
+
+	// Record the revisions the artifacts were generated from
+	if readyCondition.Status == metav1.ConditionTrue {
+		artifacts, revision, err := r.artifactStatuses(ctx, packageSource, ag)
+		if err != nil {
+			logger.V(1).Info("failed to get artifact revisions", "error", err.Error())
+		} else {
+			packageSource.Status.Artifacts = artifacts
+			packageSource.Status.Revision = revision
+		}
+	}
+
 	// Copy Ready condition from ArtifactGenerator to PackageSource
 	meta.SetStatusCondition(&packageSource.Status.Conditions, metav1.Condition{
 		Type:               "Ready",
@@ -395,7 +649,6 @@ func (r *PackageSourceReconciler) updateStatus(ctx context.Context, packageSourc
 	})
 
 	logger.V(1).Info("updated PackageSource status from ArtifactGenerator",
-		"packageSource", packageSource.Name,
 		"status", readyCondition.Status,
 		"reason", readyCondition.Reason)
 
@@ -406,8 +659,47 @@ func (r *PackageSourceReconciler) updateStatus(ctx context.Context, packageSourc
 func (r *PackageSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("cozystack-packagesource").
-		For(&cozyv1alpha1.PackageSource{}).
+		For(&cozyv1alpha1.PackageSource{}, builder.WithPredicates(r.Shard.Predicate())).
 		Owns(&sourcewatcherv1beta1.ArtifactGenerator{}).
+		Watches(
+			&cozyv1alpha1.PackageSource{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				// Find all PackageSources referencing libraries of this PackageSource,
+				// or depending on it directly or not, as their dependency cycles may
+				// have changed
+				graph, err := loadDependencyGraph(ctx, mgr.GetClient())
+				if err != nil {
+					return nil
+				}
+				dependents := graph.dependents(obj.GetName())
+				var requests []reconcile.Request
+				for name, packageSource := range graph.sources {
+					if referencesLibrariesOf(packageSource, obj.GetName()) || slices.Contains(dependents, name) {
+						requests = append(requests, reconcile.Request{
+							NamespacedName: types.NamespacedName{Name: name},
+						})
+					}
+				}
+				return requests
+			}),
+		).
+		// The variant and ignored dependencies of a Package select the
+		// dependencies checked for cycles of its PackageSource and of the ones
+		// depending on it, and its revision pins the source
+		Watches(
+			&cozyv1alpha1.Package{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				requests := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetName()}}}
+				graph, err := loadDependencyGraph(ctx, mgr.GetClient())
+				if err != nil {
+					return requests
+				}
+				for _, name := range graph.dependents(obj.GetName()) {
+					requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+				}
+				return requests
+			}),
+		).
 		Complete(r)
 }
 
@@ -0,0 +1,295 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/cozystack/cozystack/internal/shared/logging"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcewatcherv1beta1 "github.com/fluxcd/source-watcher/api/v2/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// LabelPinnedSource is set to the name of the PackageSource on the copy of its
+// source pinned to the revision of its Package
+const LabelPinnedSource = "operator.cozystack.io/pinned-source"
+
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=gitrepositories,verbs=get;list;watch;create;update;patch;delete
+
+// pinnedSourceName returns the name of the pinned copy of the source of the
+// PackageSource name
+func pinnedSourceName(packageSource string) string {
+	return strings.ReplaceAll(packageSource, ".", "-") + "-pinned"
+}
+
+// parsePinnedRevision returns the reference a source of kind is pinned to for
+// the spec.revision of a Package: the commit SHA of a GitRepository, or the
+// digest of an OCIRepository. Revisions as reported by Flux, such as
+// main@sha1:<commit>, are accepted too.
+func parsePinnedRevision(kind, revision string) (string, error) {
+	if i := strings.LastIndex(revision, "@"); i >= 0 {
+		revision = revision[i+1:]
+	}
+	switch kind {
+	case sourcev1.GitRepositoryKind:
+		commit := strings.TrimPrefix(strings.TrimPrefix(revision, "sha1:"), "sha256:")
+		if !isHex(commit) || (len(commit) != 40 && len(commit) != 64) {
+			return "", fmt.Errorf("revision of a GitRepository must be a full commit SHA")
+		}
+		return commit, nil
+	case sourcev1.OCIRepositoryKind:
+		digest := strings.TrimPrefix(revision, "sha256:")
+		if !isHex(digest) || len(digest) != 64 {
+			return "", fmt.Errorf("revision of an OCIRepository must be a digest, sha256:<hex>")
+		}
+		return "sha256:" + digest, nil
+	default:
+		return "", fmt.Errorf("sources of kind %s cannot be pinned", kind)
+	}
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil && s == strings.ToLower(s)
+}
+
+// reconcilePinnedSource returns the source the artifacts of packageSource are
+// generated from: SourceRef, or a copy of it pinned to spec.revision of the
+// Package of the same name. Pinned copies that are no longer needed are deleted.
+func (r *PackageSourceReconciler) reconcilePinnedSource(ctx context.Context, packageSource *cozyv1alpha1.PackageSource) (*cozyv1alpha1.PackageSourceRef, error) {
+	logger := log.FromContext(ctx)
+	ref := packageSource.Spec.SourceRef
+
+	var revision string
+	pkg := &cozyv1alpha1.Package{}
+	if err := r.Get(ctx, types.NamespacedName{Name: packageSource.Name}, pkg); err == nil {
+		revision = pkg.Spec.Revision
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get Package %s: %w", packageSource.Name, err)
+	}
+
+	var pinned client.Object
+	if revision != "" && ref != nil {
+		pin, err := parsePinnedRevision(ref.Kind, revision)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revision of Package %s: %w", packageSource.Name, err)
+		}
+		pinned, err = r.pinnedSource(ctx, packageSource, ref, pin)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.createOrUpdate(ctx, pinned); err != nil {
+			return nil, fmt.Errorf("failed to reconcile pinned source %s/%s: %w", pinned.GetNamespace(), pinned.GetName(), err)
+		}
+		logger.V(1).Info("reconciled pinned source", "name", pinned.GetName(), logging.KeyNamespace, pinned.GetNamespace(), "revision", pin)
+	}
+
+	lists := []client.ObjectList{
+		&sourcev1.GitRepositoryList{},
+		&sourcev1.OCIRepositoryList{},
+	}
+	for _, list := range lists {
+		if err := r.List(ctx, list, client.MatchingLabels{LabelPinnedSource: packageSource.Name}); err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok || !metav1.IsControlledBy(obj, packageSource) {
+				continue
+			}
+			gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+			if err != nil {
+				return nil, err
+			}
+			if pinned != nil && gvk.Kind == ref.Kind && obj.GetNamespace() == pinned.GetNamespace() && obj.GetName() == pinned.GetName() {
+				continue
+			}
+			if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return nil, fmt.Errorf("failed to delete pinned source %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+			}
+			logger.Info("deleted pinned source", "name", obj.GetName(), logging.KeyNamespace, obj.GetNamespace())
+		}
+	}
+
+	if pinned == nil {
+		return ref, nil
+	}
+	return &cozyv1alpha1.PackageSourceRef{
+		Kind:      ref.Kind,
+		Name:      pinned.GetName(),
+		Namespace: pinned.GetNamespace(),
+		Path:      ref.Path,
+	}, nil
+}
+
+// pinnedSource returns a copy of the source ref of packageSource checking out pin
+func (r *PackageSourceReconciler) pinnedSource(ctx context.Context, packageSource *cozyv1alpha1.PackageSource, ref *cozyv1alpha1.PackageSourceRef, pin string) (client.Object, error) {
+	gvk, err := apiutil.GVKForObject(packageSource, r.Scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GVK for PackageSource: %w", err)
+	}
+	objectMeta := metav1.ObjectMeta{
+		Name:      pinnedSourceName(packageSource.Name),
+		Namespace: ref.Namespace,
+		Labels: map[string]string{
+			LabelPackageSource: packageSource.Name,
+			LabelPinnedSource:  packageSource.Name,
+		},
+		OwnerReferences: []metav1.OwnerReference{
+			{
+				APIVersion: gvk.GroupVersion().String(),
+				Kind:       gvk.Kind,
+				Name:       packageSource.Name,
+				UID:        packageSource.UID,
+				Controller: func() *bool { b := true; return &b }(),
+			},
+		},
+	}
+	key := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+
+	switch ref.Kind {
+	case sourcev1.GitRepositoryKind:
+		source := &sourcev1.GitRepository{}
+		if err := r.Get(ctx, key, source); err != nil {
+			return nil, fmt.Errorf("failed to get GitRepository %s: %w", key, err)
+		}
+		pinned := &sourcev1.GitRepository{ObjectMeta: objectMeta, Spec: *source.Spec.DeepCopy()}
+		// The commit is checked out from the tracked branch, if any
+		pinned.Spec.Reference = &sourcev1.GitRepositoryRef{Commit: pin}
+		if source.Spec.Reference != nil {
+			pinned.Spec.Reference.Branch = source.Spec.Reference.Branch
+		}
+		return pinned, nil
+	default:
+		source := &sourcev1.OCIRepository{}
+		if err := r.Get(ctx, key, source); err != nil {
+			return nil, fmt.Errorf("failed to get OCIRepository %s: %w", key, err)
+		}
+		pinned := &sourcev1.OCIRepository{ObjectMeta: objectMeta, Spec: *source.Spec.DeepCopy()}
+		pinned.Spec.Reference = &sourcev1.OCIRepositoryRef{Digest: pin}
+		return pinned, nil
+	}
+}
+
+// artifactStatuses returns the ExternalArtifacts ag generated for the
+// components of packageSource with the revisions of the sources they were
+// generated from, and the revision of its primary source. An artifact whose
+// digest did not change keeps its recorded revision, as the source may have
+// moved on before ag regenerated the artifact.
+func (r *PackageSourceReconciler) artifactStatuses(ctx context.Context, packageSource *cozyv1alpha1.PackageSource, ag *sourcewatcherv1beta1.ArtifactGenerator) ([]cozyv1alpha1.ArtifactStatus, string, error) {
+	recorded := make(map[string]cozyv1alpha1.ArtifactStatus, len(packageSource.Status.Artifacts))
+	for _, artifact := range packageSource.Status.Artifacts {
+		recorded[artifact.Name] = artifact
+	}
+	digests := make(map[string]string, len(ag.Status.Inventory))
+	for _, ref := range ag.Status.Inventory {
+		digests[ref.Name] = ref.Digest
+	}
+	// The chart of an artifact is copied first, from the source of its component
+	chartSources := make(map[string]string, len(ag.Spec.OutputArtifacts))
+	for _, artifact := range ag.Spec.OutputArtifacts {
+		if len(artifact.Copy) > 0 {
+			alias, _, _ := strings.Cut(strings.TrimPrefix(artifact.Copy[0].From, "@"), "/")
+			chartSources[artifact.Name] = alias
+		}
+	}
+
+	revisions := map[string]string{}
+	revisionOf := func(alias string) (string, error) {
+		if revision, ok := revisions[alias]; ok {
+			return revision, nil
+		}
+		for _, source := range ag.Spec.Sources {
+			if source.Alias != alias {
+				continue
+			}
+			namespace := source.Namespace
+			if namespace == "" {
+				namespace = ag.Namespace
+			}
+			revision, err := sourceRevision(ctx, r.Client, source.Kind, types.NamespacedName{Namespace: namespace, Name: source.Name})
+			if err != nil {
+				return "", err
+			}
+			revisions[alias] = revision
+			return revision, nil
+		}
+		return "", nil
+	}
+
+	var statuses []cozyv1alpha1.ArtifactStatus
+	for _, variant := range packageSource.Spec.Variants {
+		for _, component := range variant.Components {
+			name := ComponentArtifactName(packageSource.Name, variant.Name, component.Name)
+			digest, ok := digests[name]
+			if !ok {
+				continue
+			}
+			status := cozyv1alpha1.ArtifactStatus{
+				Name:      name,
+				Variant:   variant.Name,
+				Component: component.Name,
+				Digest:    digest,
+			}
+			if previous, ok := recorded[name]; ok && previous.Digest == digest && previous.Revision != "" {
+				status.Revision = previous.Revision
+			} else if alias, ok := chartSources[name]; ok {
+				revision, err := revisionOf(alias)
+				if err != nil {
+					return nil, "", err
+				}
+				status.Revision = revision
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	var revision string
+	if len(ag.Spec.Sources) > 0 {
+		var err error
+		if revision, err = revisionOf(ag.Spec.Sources[0].Alias); err != nil {
+			return nil, "", err
+		}
+	}
+	return statuses, revision, nil
+}
+
+// artifactStatus returns the status of the ExternalArtifact name of
+// packageSource, or nil if none is recorded
+func artifactStatus(packageSource *cozyv1alpha1.PackageSource, name string) *cozyv1alpha1.ArtifactStatus {
+	for i := range packageSource.Status.Artifacts {
+		if packageSource.Status.Artifacts[i].Name == name {
+			return &packageSource.Status.Artifacts[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import (
+	"hash/fnv"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Shard is the share of the Packages and PackageSources reconciled by one of
+// several operator replicas. They are assigned to a shard by the hash of their
+// name modulo the number of shards, so a Package and its PackageSource, which
+// share the name, are reconciled by the same replica. The zero Shard owns all
+// of them.
+type Shard struct {
+	// Index is the shard of this replica, from 0 to Count-1
+	Index int
+	// Count is the number of shards, sharding is disabled below 2
+	Count int
+}
+
+// Owns reports whether the Package or PackageSource name belongs to the shard
+func (s Shard) Owns(name string) bool {
+	if s.Count < 2 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return int(h.Sum32()%uint32(s.Count)) == s.Index
+}
+
+// Predicate filters the events of the objects not owned by the shard
+func (s Shard) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return s.Owns(obj.GetName())
+	})
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+import "testing"
+
+func TestShardOwns(t *testing.T) {
+	// The shards of fnv32a(name) % 3, they must not change between releases
+	// or Packages move between replicas on upgrade
+	cases := []struct {
+		name  string
+		shard int
+	}{
+		{name: "cozystack.cilium", shard: 0},
+		{name: "cozystack.kubeovn", shard: 1},
+		{name: "cozystack.linstor", shard: 2},
+		{name: "cozystack.metallb", shard: 2},
+		{name: "cozystack.cozystack-platform", shard: 1},
+		{name: "cozystack.monitoring", shard: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			owners := 0
+			for index := 0; index < 3; index++ {
+				owns := Shard{Index: index, Count: 3}.Owns(tc.name)
+				if owns {
+					owners++
+				}
+				if owns != (index == tc.shard) {
+					t.Errorf("Shard{Index: %d, Count: 3}.Owns(%q) = %v, want %v", index, tc.name, owns, index == tc.shard)
+				}
+			}
+			if owners != 1 {
+				t.Errorf("%q is owned by %d shards, want 1", tc.name, owners)
+			}
+		})
+	}
+}
+
+func TestShardOwnsAllWhenNotSharded(t *testing.T) {
+	for _, shard := range []Shard{{}, {Index: 0, Count: 1}} {
+		for _, name := range []string{"cozystack.cilium", "cozystack.kubeovn", "cozystack.linstor"} {
+			if !shard.Owns(name) {
+				t.Errorf("%+v.Owns(%q) = false, want true", shard, name)
+			}
+		}
+	}
+}
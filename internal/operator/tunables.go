@@ -0,0 +1,54 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operator
+
+// Tunables are the settings of a PackageReconciler that can be changed while
+// it is running, e.g. when the operator configuration file is reloaded
+type Tunables struct {
+	MaxConcurrentHelmReleases   int
+	MaxHelmReleasesPerReconcile int
+	RevisionHistoryLimit        int
+}
+
+// SetTunables replaces MaxConcurrentHelmReleases, MaxHelmReleasesPerReconcile
+// and RevisionHistoryLimit of a running reconciler. It is safe to call concurrently with Reconcile.
+func (r *PackageReconciler) SetTunables(t Tunables) {
+	r.tunables.Store(&t)
+}
+
+// currentTunables returns the tunables set with SetTunables, or the fields of r
+// if they were never changed, with defaults for unset values
+func (r *PackageReconciler) currentTunables() Tunables {
+	t := Tunables{
+		MaxConcurrentHelmReleases:   r.MaxConcurrentHelmReleases,
+		MaxHelmReleasesPerReconcile: r.MaxHelmReleasesPerReconcile,
+		RevisionHistoryLimit:        r.RevisionHistoryLimit,
+	}
+	if stored := r.tunables.Load(); stored != nil {
+		t = *stored
+	}
+	if t.MaxConcurrentHelmReleases <= 0 {
+		t.MaxConcurrentHelmReleases = DefaultMaxConcurrentHelmReleases
+	}
+	if t.MaxHelmReleasesPerReconcile <= 0 {
+		t.MaxHelmReleasesPerReconcile = DefaultMaxHelmReleasesPerReconcile
+	}
+	if t.RevisionHistoryLimit <= 0 {
+		t.RevisionHistoryLimit = DefaultRevisionHistoryLimit
+	}
+	return t
+}
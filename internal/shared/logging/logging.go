@@ -0,0 +1,174 @@
+// Package logging sets up the structured logs of the operator controllers: the
+// verbosity of each controller can be changed at runtime, repeated messages are
+// sampled, and the objects being reconciled are logged under standard keys.
+// controller-runtime already adds the controller and the namespace and name of
+// the reconciled object to the logger of every reconciliation.
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Standard keys of the values logged by the controllers
+const (
+	KeyPackage       = "package"
+	KeyPackageSource = "packageSource"
+	KeyComponent     = "component"
+	KeyNamespace     = "namespace"
+
+	// keyController is the key controller-runtime logs the controller name with
+	keyController = "controller"
+)
+
+// Levels holds the verbosity of the logs of each controller. Messages logged
+// with V(n) are written when n is at most the verbosity of their controller,
+// or the default verbosity for controllers without one and for messages logged
+// outside of controllers. Errors are always written. Levels is safe for
+// concurrent use.
+type Levels struct {
+	mu          sync.RWMutex
+	verbosity   int
+	controllers map[string]int
+}
+
+// NewLevels returns Levels with the default verbosity and the verbosity of
+// controllers
+func NewLevels(verbosity int, controllers map[string]int) *Levels {
+	l := &Levels{}
+	l.Set(verbosity, controllers)
+	return l
+}
+
+// Set replaces the default verbosity and the verbosity of controllers
+func (l *Levels) Set(verbosity int, controllers map[string]int) {
+	copied := make(map[string]int, len(controllers))
+	for name, v := range controllers {
+		copied[name] = v
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.verbosity = verbosity
+	l.controllers = copied
+}
+
+// Enabled reports whether messages of controller at level are written
+func (l *Levels) Enabled(controller string, level int) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if v, ok := l.controllers[controller]; ok {
+		return level <= v
+	}
+	return level <= l.verbosity
+}
+
+// ParseControllerLevels parses a comma separated list of controller=verbosity
+// pairs
+func ParseControllerLevels(value string) (map[string]int, error) {
+	levels := map[string]int{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid controller verbosity %q (expected controller=verbosity)", pair)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || level < 0 {
+			return nil, fmt.Errorf("invalid verbosity of controller %s: %q", name, v)
+		}
+		levels[strings.TrimSpace(name)] = level
+	}
+	return levels, nil
+}
+
+// FormatControllerLevels formats levels as parsed by ParseControllerLevels
+func FormatControllerLevels(levels map[string]int) string {
+	pairs := make([]string, 0, len(levels))
+	for name, level := range levels {
+		pairs = append(pairs, name+"="+strconv.Itoa(level))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// WithLevels returns logger writing only the messages enabled by levels
+func WithLevels(logger logr.Logger, levels *Levels) logr.Logger {
+	wrapped := logger.GetSink()
+	// Skip the frame of sink when reporting the caller
+	if cd, ok := wrapped.(logr.CallDepthLogSink); ok {
+		wrapped = cd.WithCallDepth(1)
+	}
+	return logr.New(&sink{LogSink: wrapped, levels: levels})
+}
+
+// Sampling returns a zap option sampling the messages of the same level and
+// text: the first initial ones of every second are written, then every
+// thereafter-th one. It keeps reconciliations of many objects from flooding
+// the logs.
+func Sampling(initial, thereafter int) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, time.Second, initial, thereafter)
+	})
+}
+
+// sink filters the messages of a LogSink by the verbosity of the controller
+// it logs for
+type sink struct {
+	logr.LogSink
+	levels     *Levels
+	controller string
+}
+
+var (
+	_ logr.LogSink          = &sink{}
+	_ logr.CallDepthLogSink = &sink{}
+)
+
+// Init does nothing, the wrapped LogSink is already initialized
+func (s *sink) Init(logr.RuntimeInfo) {}
+
+func (s *sink) Enabled(level int) bool {
+	return s.levels.Enabled(s.controller, level) && s.LogSink.Enabled(level)
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...any) {
+	s.LogSink.Info(level, msg, keysAndValues...)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...any) {
+	s.LogSink.Error(err, msg, keysAndValues...)
+}
+
+func (s *sink) WithValues(keysAndValues ...any) logr.LogSink {
+	controller := s.controller
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if keysAndValues[i] == keyController {
+			if name, ok := keysAndValues[i+1].(string); ok {
+				controller = name
+			}
+		}
+	}
+	return &sink{LogSink: s.LogSink.WithValues(keysAndValues...), levels: s.levels, controller: controller}
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	return &sink{LogSink: s.LogSink.WithName(name), levels: s.levels, controller: s.controller}
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	wrapped, ok := s.LogSink.(logr.CallDepthLogSink)
+	if !ok {
+		return s
+	}
+	return &sink{LogSink: wrapped.WithCallDepth(depth), levels: s.levels, controller: s.controller}
+}
@@ -0,0 +1,44 @@
+// Package reconcilemetrics records the duration and errors of reconciliations
+// on the metrics server of the controller-runtime manager. controller-runtime
+// reports the same per controller, these add the outcome of each run so that
+// requeues and errors can be told apart from successful reconciliations.
+package reconcilemetrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cozystack_reconcile_duration_seconds",
+		Help:    "Time taken by reconciliations, by controller and result.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"controller", "result"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cozystack_reconcile_errors_total",
+		Help: "Number of reconciliations that returned an error, by controller.",
+	}, []string{"controller"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, reconcileErrors)
+}
+
+// Observe records a reconciliation by controller that started at start and
+// returned result and err
+func Observe(controller string, start time.Time, result ctrl.Result, err error) {
+	outcome := "success"
+	switch {
+	case err != nil:
+		outcome = "error"
+		reconcileErrors.WithLabelValues(controller).Inc()
+	case result.RequeueAfter > 0:
+		outcome = "requeue"
+	}
+	reconcileDuration.WithLabelValues(controller, outcome).Observe(time.Since(start).Seconds())
+}
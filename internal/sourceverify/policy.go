@@ -0,0 +1,194 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sourceverify checks that the OCI artifact behind a PackageSource is
+// signed according to a verification policy.
+//
+// Signatures and attestations are verified by Flux source-controller, which
+// is configured through OCIRepository.spec.verify. This package checks that
+// the verification configured on the source is at least as strict as the
+// policy, and that the source-controller reported the current artifact as
+// verified.
+package sourceverify
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Policy describes how the OCI source of a PackageSource must be verified.
+// The zero Policy requires that verification is configured with any provider
+// and that the current artifact passed it.
+type Policy struct {
+	// Provider is the required signing technology (cosign or notation).
+	// Any provider is accepted when empty.
+	Provider string `json:"provider,omitempty"`
+	// SecretRef is the name of the Secret with trusted public keys that the
+	// source must verify against. Any keys are accepted when empty.
+	SecretRef string `json:"secretRef,omitempty"`
+	// Identities are the keyless signing identities the source may trust.
+	// A source using keyless verification must only match identities from this
+	// list. They are compared to the entries of matchOIDCIdentity by exact
+	// string equality, not matched as regular expressions: a source must copy
+	// them verbatim, as any other pattern could match more identities.
+	Identities []Identity `json:"identities,omitempty"`
+}
+
+// Identity is an OIDC issuer and subject pair, as in OCIRepository.spec.verify.matchOIDCIdentity.
+// Both are the regular expressions used by source-controller, e.g.
+// ^https://token.actions.githubusercontent.com$.
+type Identity struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+// Result is the outcome of checking a PackageSource against a Policy
+type Result struct {
+	// Source is the "Kind namespace/name" of the checked source
+	Source string
+	// Revision is the revision of the verified artifact, if any
+	Revision string
+	// Violations lists why the source does not satisfy the policy
+	Violations []string
+}
+
+// Verified reports whether the source satisfies the policy
+func (r *Result) Verified() bool {
+	return len(r.Violations) == 0
+}
+
+// LoadPolicy reads a Policy from a YAML or JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification policy: %w", err)
+	}
+	policy := &Policy{}
+	if err := k8syaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse verification policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Check verifies the sources of a PackageSource against the policy. The
+// additional sources in spec.sourceRefs must satisfy it as well, as components
+// are built from them; their violations name the alias of the source.
+// The client must have the source-controller API registered in its scheme.
+func (p *Policy) Check(ctx context.Context, c client.Client, ps *cozyv1alpha1.PackageSource) (*Result, error) {
+	ref := ps.Spec.SourceRef
+	if ref == nil {
+		return &Result{Violations: []string{"PackageSource has no sourceRef"}}, nil
+	}
+	result := &Result{Source: describeSource(ref)}
+	violations, revision, err := p.checkSource(ctx, c, ref)
+	if err != nil {
+		return nil, err
+	}
+	result.Revision = revision
+	result.Violations = violations
+
+	for i := range ps.Spec.SourceRefs {
+		extra := &ps.Spec.SourceRefs[i]
+		violations, _, err := p.checkSource(ctx, c, &extra.PackageSourceRef)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range violations {
+			result.Violations = append(result.Violations, fmt.Sprintf("source %s (%s): %s", extra.Alias, describeSource(&extra.PackageSourceRef), v))
+		}
+	}
+	return result, nil
+}
+
+// checkSource returns the violations of the source ref and the revision of its
+// verified artifact, if any
+func (p *Policy) checkSource(ctx context.Context, c client.Client, ref *cozyv1alpha1.PackageSourceRef) ([]string, string, error) {
+	if ref.Kind != sourcev1.OCIRepositoryKind {
+		return []string{fmt.Sprintf("source kind %s does not support signature verification, %s is required", ref.Kind, sourcev1.OCIRepositoryKind)}, "", nil
+	}
+
+	repo := &sourcev1.OCIRepository{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, repo); err != nil {
+		return nil, "", fmt.Errorf("failed to get %s: %w", describeSource(ref), err)
+	}
+	var revision string
+	if repo.Status.Artifact != nil {
+		revision = repo.Status.Artifact.Revision
+	}
+	return p.violations(repo), revision, nil
+}
+
+// describeSource formats ref as "Kind namespace/name"
+func describeSource(ref *cozyv1alpha1.PackageSourceRef) string {
+	return fmt.Sprintf("%s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+}
+
+// violations returns the reasons why repo does not satisfy the policy
+func (p *Policy) violations(repo *sourcev1.OCIRepository) []string {
+	verify := repo.Spec.Verify
+	if verify == nil {
+		return []string{"signature verification is not configured on the source (spec.verify)"}
+	}
+
+	var out []string
+	if p.Provider != "" && verify.Provider != p.Provider {
+		out = append(out, fmt.Sprintf("provider %q does not match required provider %q", verify.Provider, p.Provider))
+	}
+	if p.SecretRef != "" && (verify.SecretRef == nil || verify.SecretRef.Name != p.SecretRef) {
+		out = append(out, fmt.Sprintf("source does not verify against trusted keys from Secret %q", p.SecretRef))
+	}
+	if len(p.Identities) > 0 && verify.SecretRef == nil {
+		if len(verify.MatchOIDCIdentity) == 0 {
+			out = append(out, "keyless verification does not restrict signing identities")
+		}
+		for _, id := range verify.MatchOIDCIdentity {
+			if !p.allowsIdentity(id) {
+				out = append(out, fmt.Sprintf("signing identity issuer=%q subject=%q is not allowed by the policy", id.Issuer, id.Subject))
+			}
+		}
+	}
+
+	cond := meta.FindStatusCondition(repo.Status.Conditions, sourcev1.SourceVerifiedCondition)
+	switch {
+	case cond == nil:
+		out = append(out, "source-controller has not reported a verification result yet")
+	case repo.Status.ObservedGeneration != repo.Generation:
+		out = append(out, "verification result is outdated, source-controller has not observed the latest spec")
+	case cond.Status != metav1.ConditionTrue:
+		out = append(out, fmt.Sprintf("artifact failed verification: %s", cond.Message))
+	}
+	return out
+}
+
+// allowsIdentity reports whether id is one of the identities of the policy.
+// The regular expressions are compared as strings.
+func (p *Policy) allowsIdentity(id sourcev1.OIDCIdentityMatch) bool {
+	for _, allowed := range p.Identities {
+		if allowed.Issuer == id.Issuer && allowed.Subject == id.Subject {
+			return true
+		}
+	}
+	return false
+}
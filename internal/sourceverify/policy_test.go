@@ -0,0 +1,150 @@
+package sourceverify
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func verifiedRepo(verify *sourcev1.OCIRepositoryVerification) *sourcev1.OCIRepository {
+	return &sourcev1.OCIRepository{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       sourcev1.OCIRepositorySpec{Verify: verify},
+		Status: sourcev1.OCIRepositoryStatus{
+			ObservedGeneration: 2,
+			Conditions: []metav1.Condition{{
+				Type:   sourcev1.SourceVerifiedCondition,
+				Status: metav1.ConditionTrue,
+			}},
+		},
+	}
+}
+
+func TestPolicyAcceptsVerifiedSource(t *testing.T) {
+	policy := &Policy{
+		Provider:   "cosign",
+		Identities: []Identity{{Issuer: "^https://token.actions.githubusercontent.com$", Subject: "^https://github.com/cozystack/.*$"}},
+	}
+	repo := verifiedRepo(&sourcev1.OCIRepositoryVerification{
+		Provider: "cosign",
+		MatchOIDCIdentity: []sourcev1.OIDCIdentityMatch{
+			{Issuer: "^https://token.actions.githubusercontent.com$", Subject: "^https://github.com/cozystack/.*$"},
+		},
+	})
+	if v := policy.violations(repo); len(v) != 0 {
+		t.Fatalf("expected no violations, got %v", v)
+	}
+}
+
+func TestPolicyRejectsSources(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		repo   *sourcev1.OCIRepository
+		want   string
+	}{
+		{
+			name: "verification not configured",
+			repo: verifiedRepo(nil),
+			want: "not configured",
+		},
+		{
+			name:   "wrong provider",
+			policy: Policy{Provider: "cosign"},
+			repo:   verifiedRepo(&sourcev1.OCIRepositoryVerification{Provider: "notation"}),
+			want:   "does not match required provider",
+		},
+		{
+			name:   "wrong keys",
+			policy: Policy{SecretRef: "trusted-keys"},
+			repo:   verifiedRepo(&sourcev1.OCIRepositoryVerification{Provider: "cosign", SecretRef: &meta.LocalObjectReference{Name: "other"}}),
+			want:   "trusted keys",
+		},
+		{
+			name:   "unrestricted keyless identity",
+			policy: Policy{Identities: []Identity{{Issuer: "a", Subject: "b"}}},
+			repo:   verifiedRepo(&sourcev1.OCIRepositoryVerification{Provider: "cosign"}),
+			want:   "does not restrict signing identities",
+		},
+		{
+			name:   "foreign keyless identity",
+			policy: Policy{Identities: []Identity{{Issuer: "a", Subject: "b"}}},
+			repo: verifiedRepo(&sourcev1.OCIRepositoryVerification{
+				Provider:          "cosign",
+				MatchOIDCIdentity: []sourcev1.OIDCIdentityMatch{{Issuer: "a", Subject: ".*"}},
+			}),
+			want: "is not allowed",
+		},
+		{
+			// Identities are compared as strings, a pattern the policy would
+			// match as a regular expression may still match other identities
+			name:   "keyless identity matched by the policy pattern",
+			policy: Policy{Identities: []Identity{{Issuer: "a", Subject: "^https://github.com/cozystack/.*$"}}},
+			repo: verifiedRepo(&sourcev1.OCIRepositoryVerification{
+				Provider:          "cosign",
+				MatchOIDCIdentity: []sourcev1.OIDCIdentityMatch{{Issuer: "a", Subject: "^https://github.com/cozystack/x|.*$"}},
+			}),
+			want: "is not allowed",
+		},
+		{
+			name: "verification failed",
+			repo: func() *sourcev1.OCIRepository {
+				r := verifiedRepo(&sourcev1.OCIRepositoryVerification{Provider: "cosign"})
+				r.Status.Conditions[0].Status = metav1.ConditionFalse
+				r.Status.Conditions[0].Message = "no matching signatures"
+				return r
+			}(),
+			want: "no matching signatures",
+		},
+		{
+			name: "outdated result",
+			repo: func() *sourcev1.OCIRepository {
+				r := verifiedRepo(&sourcev1.OCIRepositoryVerification{Provider: "cosign"})
+				r.Generation = 3
+				return r
+			}(),
+			want: "outdated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.policy.violations(tt.repo)
+			if len(v) == 0 || !strings.Contains(strings.Join(v, "; "), tt.want) {
+				t.Fatalf("expected violation containing %q, got %v", tt.want, v)
+			}
+		})
+	}
+}
+
+func TestPolicyChecksAdditionalSources(t *testing.T) {
+	repo := verifiedRepo(&sourcev1.OCIRepositoryVerification{Provider: "cosign"})
+	repo.Name, repo.Namespace = "platform", "cozy-system"
+	scheme := runtime.NewScheme()
+	if err := sourcev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(repo).Build()
+
+	ps := &cozyv1alpha1.PackageSource{Spec: cozyv1alpha1.PackageSourceSpec{
+		SourceRef: &cozyv1alpha1.PackageSourceRef{Kind: sourcev1.OCIRepositoryKind, Name: "platform", Namespace: "cozy-system"},
+		SourceRefs: []cozyv1alpha1.AliasedPackageSourceRef{{
+			Alias:            "fork",
+			PackageSourceRef: cozyv1alpha1.PackageSourceRef{Kind: sourcev1.GitRepositoryKind, Name: "fork", Namespace: "cozy-system"},
+		}},
+	}}
+	result, err := (&Policy{}).Check(context.Background(), c, ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Violations) != 1 || !strings.HasPrefix(result.Violations[0], "source fork (GitRepository cozy-system/fork): ") {
+		t.Fatalf("expected a violation of the fork source, got %v", result.Violations)
+	}
+}
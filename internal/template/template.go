@@ -3,7 +3,13 @@ package template
 import (
 	"bytes"
 	"encoding/json"
+	"io"
+	"strings"
 	tmpl "text/template"
+	"text/template/parse"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 func Template[T any](obj *T, templateContext map[string]any) (*T, error) {
@@ -66,3 +72,135 @@ func template(in string, templateContext map[string]any) (string, error) {
 	}
 	return buf.String(), nil
 }
+
+// Unstructured templates every string of obj with templateContext and returns
+// the result, leaving obj unchanged. A string made of a single action, such as
+// "{{ .Values.replicas }}", takes the type of the value it evaluates to, so
+// that integers and booleans of the context are not turned into strings; any
+// other string stays a string. Unlike Template, missing keys and invalid
+// templates are errors, reported with the path of the offending field.
+func Unstructured(obj *unstructured.Unstructured, templateContext map[string]any) (*unstructured.Unstructured, error) {
+	out := obj.DeepCopy()
+	var errs field.ErrorList
+	out.Object = templateValue(out.Object, nil, templateContext, &errs).(map[string]any)
+	if len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	return out, nil
+}
+
+func templateValue(v any, path *field.Path, templateContext map[string]any, errs *field.ErrorList) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			x[k] = templateValue(val, childPath(path, k), templateContext, errs)
+		}
+		return x
+	case []any:
+		for i, val := range x {
+			x[i] = templateValue(val, path.Index(i), templateContext, errs)
+		}
+		return x
+	case string:
+		out, err := typedTemplate(x, templateContext)
+		if err != nil {
+			*errs = append(*errs, field.Invalid(path, x, err.Error()))
+			return x
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// childPath returns the path of the key k of the object at path. Keys that
+// are not identifiers, such as annotation names, are quoted.
+func childPath(path *field.Path, k string) *field.Path {
+	if path == nil {
+		return field.NewPath(k)
+	}
+	if strings.ContainsAny(k, "./") {
+		return path.Key(k)
+	}
+	return path.Child(k)
+}
+
+// typedTemplate executes the template in. If in is a single action, the value
+// the action evaluates to is returned when it is a number or a boolean.
+func typedTemplate(in string, templateContext map[string]any) (any, error) {
+	tpl, err := tmpl.New("this").Option("missingkey=error").Parse(in)
+	if err != nil {
+		return nil, err
+	}
+	if pipe := singleAction(tpl); pipe != nil {
+		var value any
+		capture := tmpl.FuncMap{"__capture": func(v any) string {
+			value = v
+			return ""
+		}}
+		typed, err := tmpl.New("this").Option("missingkey=error").Funcs(capture).Parse("{{ __capture (" + pipe.String() + ") }}")
+		if err == nil {
+			if err := typed.Execute(io.Discard, templateContext); err != nil {
+				return nil, err
+			}
+			if scalar, ok := typedScalar(value); ok {
+				return scalar, nil
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, templateContext); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+// singleAction returns the pipeline of tpl if it consists of a single action
+// without variable declarations
+func singleAction(tpl *tmpl.Template) *parse.PipeNode {
+	if tpl.Tree == nil || tpl.Tree.Root == nil || len(tpl.Tree.Root.Nodes) != 1 {
+		return nil
+	}
+	action, ok := tpl.Tree.Root.Nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Decl) > 0 {
+		return nil
+	}
+	return action.Pipe
+}
+
+// typedScalar converts numbers and booleans to the types used by unstructured
+// objects
+func typedScalar(v any) (any, bool) {
+	switch x := v.(type) {
+	case bool:
+		return x, true
+	case int:
+		return int64(x), true
+	case int8:
+		return int64(x), true
+	case int16:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	case uint8:
+		return int64(x), true
+	case uint16:
+		return int64(x), true
+	case uint32:
+		return int64(x), true
+	case float32:
+		return float64(x), true
+	case float64:
+		return x, true
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i, true
+		}
+		if f, err := x.Float64(); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
@@ -2,10 +2,12 @@ package template
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestTemplate_PodTemplateSpec(t *testing.T) {
@@ -66,3 +68,86 @@ func TestTemplate_PodTemplateSpec(t *testing.T) {
 		t.Fatalf("transformed value not equal to reference value, expected: %s, got: %s", string(b1), string(b2))
 	}
 }
+
+func TestUnstructured(t *testing.T) {
+	original := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name": "{{ .Release.Name }}",
+			"annotations": map[string]any{
+				"example.org/replicas": "replicas: {{ .Values.replicas }}",
+			},
+		},
+		"spec": map[string]any{
+			"replicas": "{{ .Values.replicas }}",
+			"paused":   "{{ .Values.paused }}",
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{
+							"name":  "app",
+							"image": "{{ .Values.image }}",
+						},
+					},
+				},
+			},
+		},
+	}}
+	templateContext := map[string]any{
+		"Release": map[string]any{"Name": "foo"},
+		"Values": map[string]any{
+			"replicas": 3,
+			"paused":   false,
+			"image":    "nginx:1.21",
+		},
+	}
+
+	got, err := Unstructured(original, templateContext)
+	if err != nil {
+		t.Fatalf("Unstructured returned error: %v", err)
+	}
+	if got.GetName() != "foo" {
+		t.Errorf("expected name foo, got %q", got.GetName())
+	}
+	if replicas, _, _ := unstructured.NestedFieldNoCopy(got.Object, "spec", "replicas"); replicas != int64(3) {
+		t.Errorf("expected replicas to be int64 3, got %#v", replicas)
+	}
+	if paused, _, _ := unstructured.NestedFieldNoCopy(got.Object, "spec", "paused"); paused != false {
+		t.Errorf("expected paused to be false, got %#v", paused)
+	}
+	if annotation := got.GetAnnotations()["example.org/replicas"]; annotation != "replicas: 3" {
+		t.Errorf("expected annotation to stay a string, got %q", annotation)
+	}
+	containers, _, _ := unstructured.NestedSlice(got.Object, "spec", "template", "spec", "containers")
+	if image := containers[0].(map[string]any)["image"]; image != "nginx:1.21" {
+		t.Errorf("expected image nginx:1.21, got %#v", image)
+	}
+	if replicas, _, _ := unstructured.NestedFieldNoCopy(original.Object, "spec", "replicas"); replicas != "{{ .Values.replicas }}" {
+		t.Errorf("expected the original object to be unchanged, got replicas %#v", replicas)
+	}
+}
+
+func TestUnstructured_Errors(t *testing.T) {
+	original := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				"example.org/broken": "{{ .Values.missing }}",
+			},
+		},
+		"spec": map[string]any{
+			"containers": []any{
+				map[string]any{"image": "{{ .Values.image"},
+			},
+		},
+	}}
+	_, err := Unstructured(original, map[string]any{"Values": map[string]any{}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, path := range []string{`metadata.annotations[example.org/broken]`, `spec.containers[0].image`} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("expected error to mention %s, got: %v", path, err)
+		}
+	}
+}
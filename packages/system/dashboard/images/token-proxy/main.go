@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -27,6 +28,8 @@ var (
 	cookieSecure                    bool
 	cookieRefresh                   time.Duration
 	tokenCheckURL                   string
+	loginTemplateDir, logoURL       string
+	legalBanner                     string
 )
 
 func init() {
@@ -39,10 +42,23 @@ func init() {
 	flag.BoolVar(&cookieSecure, "cookie-secure", false, "Set Secure flag on cookie")
 	flag.DurationVar(&cookieRefresh, "cookie-refresh", 0, "Cookie refresh interval (e.g. 1h)")
 	flag.StringVar(&tokenCheckURL, "token-check-url", "", "URL for external token validation")
+
+	flag.StringVar(&loginTemplateDir, "login-template-dir", "", "Directory with a custom login.html template; its files are also served under <proxy-prefix>/static/")
+	flag.StringVar(&logoURL, "logo-url", "", "URL of a logo shown on the sign-in page")
+	flag.StringVar(&legalBanner, "legal-banner", "", "Legal or compliance notice shown on the sign-in page")
 }
 
 /* ----------------------------- templates -------------------------------- */
 
+// loginData is passed to the login page template. Custom templates may use all of its fields.
+type loginData struct {
+	Action       string
+	Err          string
+	LogoURL      string
+	LegalBanner  string
+	StaticPrefix string
+}
+
 var loginTmpl = template.Must(template.New("login").Parse(`
 <!doctype html>
 <html lang="en">
@@ -103,22 +119,44 @@ var loginTmpl = template.Must(template.New("login").Parse(`
 			color: #e74c3c;
 			margin-bottom: 1rem;
 		}
+		.logo {
+			max-width: 200px;
+			max-height: 80px;
+			margin-bottom: 1rem;
+		}
+		.legal {
+			margin-top: 1.5rem;
+			color: #666;
+			font-size: 0.8rem;
+			text-align: left;
+			white-space: pre-line;
+		}
 	</style>
 </head>
 <body>
 	<div class="card">
+		{{if .LogoURL}}<img class="logo" src="{{.LogoURL}}" alt="Logo" />{{end}}
 		<h2>Kubernetes API Token</h2>
 		{{if .Err}}<p class="error">{{.Err}}</p>{{end}}
 		<form method="POST" action="{{.Action}}">
 			<input type="text" name="token" placeholder="Paste token here" autofocus />
 			<button type="submit">Login</button>
 		</form>
+		{{if .LegalBanner}}<p class="legal">{{.LegalBanner}}</p>{{end}}
 	</div>
 </body>
 </html>`))
 
 /* ----------------------------- helpers ---------------------------------- */
 
+// loadLoginTemplate returns the custom login.html from dir, or the built-in template if dir is empty.
+func loadLoginTemplate(dir string) (*template.Template, error) {
+	if dir == "" {
+		return loginTmpl, nil
+	}
+	return template.ParseFiles(filepath.Join(dir, "login.html"))
+}
+
 func decodeJWT(raw string) jwt.MapClaims {
 	if raw == "" {
 		return jwt.MapClaims{}
@@ -189,36 +227,50 @@ func main() {
 		log.Println("warning: no cookie-secret provided, cookies will be stored unsigned")
 	}
 
+	tmpl, err := loadLoginTemplate(loginTemplateDir)
+	if err != nil {
+		log.Fatalf("login-template-dir: %v", err)
+	}
+
 	// control paths
 	signIn := path.Join(proxyPrefix, "sign_in")
 	signOut := path.Join(proxyPrefix, "sign_out")
 	userInfo := path.Join(proxyPrefix, "userinfo")
+	static := path.Join(proxyPrefix, "static") + "/"
 
 	proxy := httputil.NewSingleHostReverseProxy(upURL)
 
+	renderLogin := func(w http.ResponseWriter, errMsg string) {
+		_ = tmpl.Execute(w, loginData{
+			Action:       signIn,
+			Err:          errMsg,
+			LogoURL:      logoURL,
+			LegalBanner:  legalBanner,
+			StaticPrefix: static,
+		})
+	}
+
+	/* ------------------------- /static ----------------------------------- */
+
+	// assets for custom login pages are served without authentication
+	if loginTemplateDir != "" {
+		http.Handle(static, http.StripPrefix(static, http.FileServer(http.Dir(loginTemplateDir))))
+	}
+
 	/* ------------------------- /sign_in ---------------------------------- */
 
 	http.HandleFunc(signIn, func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			_ = loginTmpl.Execute(w, struct {
-				Action string
-				Err    string
-			}{Action: signIn})
+			renderLogin(w, "")
 		case http.MethodPost:
 			token := strings.TrimSpace(r.FormValue("token"))
 			if token == "" {
-				_ = loginTmpl.Execute(w, struct {
-					Action string
-					Err    string
-				}{Action: signIn, Err: "Token required"})
+				renderLogin(w, "Token required")
 				return
 			}
 			if err := externalTokenCheck(token); err != nil {
-				_ = loginTmpl.Execute(w, struct {
-					Action string
-					Err    string
-				}{Action: signIn, Err: "Invalid token"})
+				renderLogin(w, "Invalid token")
 				return
 			}
 
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 The Cozystack Authors.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// Field selector labels supported by Applications in addition to
+// metadata.name and metadata.namespace
+const (
+	// StatusVersionField selects Applications by status.version
+	StatusVersionField = "status.version"
+	// StatusReadyField selects Applications by the status of their Ready
+	// condition: True, False or Unknown
+	StatusReadyField = "status.ready"
+)
+
+// ApplicationFieldLabelConversionFunc accepts the field labels Applications
+// can be selected by. It is registered for every dynamic Application kind.
+func ApplicationFieldLabelConversionFunc(label, value string) (string, string, error) {
+	switch label {
+	case "metadata.name", "metadata.namespace", StatusVersionField, StatusReadyField:
+		return label, value, nil
+	default:
+		return "", "", fmt.Errorf("field label not supported: %s", label)
+	}
+}
+
+// ApplicationToSelectableFields returns the fields Applications can be
+// selected by
+func ApplicationToSelectableFields(app *Application) fields.Set {
+	ready := string(metav1.ConditionUnknown)
+	if c := meta.FindStatusCondition(app.Status.Conditions, "Ready"); c != nil {
+		ready = string(c.Status)
+	}
+	return fields.Set{
+		"metadata.name":      app.Name,
+		"metadata.namespace": app.Namespace,
+		StatusVersionField:   app.Status.Version,
+		StatusReadyField:     ready,
+	}
+}
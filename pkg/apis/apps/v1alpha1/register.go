@@ -40,6 +40,15 @@ func init() {
 
 // addKnownTypes is called from init().
 func addKnownTypes(scheme *runtime.Scheme) error {
+	for _, gv := range []schema.GroupVersion{SchemeGroupVersion, {Group: GroupName, Version: runtime.APIVersionInternal}} {
+		scheme.AddKnownTypes(gv,
+			&RestorePointList{},
+			&ApplicationBackup{},
+			&ApplicationBackupList{},
+			&ApplicationRestore{},
+			&ApplicationRestoreList{},
+		)
+	}
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
 }
@@ -62,6 +71,9 @@ func RegisterDynamicTypes(scheme *runtime.Scheme, cfg *config.ResourceConfig) er
 		gvk := SchemeGroupVersion.WithKind(kind)
 		scheme.AddKnownTypeWithName(gvk, &Application{})
 		scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(kind+"List"), &ApplicationList{})
+		if err := scheme.AddFieldLabelConversionFunc(gvk, ApplicationFieldLabelConversionFunc); err != nil {
+			return err
+		}
 
 		gvkInternal := schema.GroupVersion{Group: GroupName, Version: runtime.APIVersionInternal}.WithKind(kind)
 		scheme.AddKnownTypeWithName(gvkInternal, &Application{})
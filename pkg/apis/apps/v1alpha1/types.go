@@ -47,6 +47,38 @@ type ApplicationStatus struct {
 	// Namespace holds the computed namespace for Tenant applications.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
+	// Resources lists the workloads and services created by the Application.
+	// It is only populated when a single Application is retrieved.
+	// +optional
+	Resources []ApplicationResource `json:"resources,omitempty"`
+	// Connection holds the details clients connect to the Application with.
+	// It is only populated when a single Application is retrieved.
+	// +optional
+	Connection *ApplicationConnection `json:"connection,omitempty"`
+}
+
+// ApplicationConnection holds the connection details of an Application.
+type ApplicationConnection struct {
+	// Host clients connect to.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Port clients connect to.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// CredentialsSecret is the name of the Secret holding the credentials.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// ApplicationResource is an object created by the release of an Application.
+type ApplicationResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Ready holds the ready and desired replicas of workloads, e.g. 2/3.
+	// +optional
+	Ready string `json:"ready,omitempty"`
 }
 
 // GetConditions returns the status conditions of the object.
@@ -72,3 +104,165 @@ type Application struct {
 	Spec   *apiextensionsv1.JSON `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
 	Status ApplicationStatus     `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
 }
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// RestorePointList lists the Backups an Application can be restored from,
+// newest first.
+type RestorePointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []RestorePoint `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// RestorePoint is a Backup of an Application.
+type RestorePoint struct {
+	// Backup is the name of the Backup.
+	Backup string `json:"backup"`
+	// TakenAt is the time at which the backup was taken.
+	TakenAt metav1.Time `json:"takenAt"`
+	// Plan is the name of the Plan that produced the backup, if any.
+	// +optional
+	Plan string `json:"plan,omitempty"`
+	// Storage is the Storage holding the backup, as Kind/name.
+	Storage string `json:"storage"`
+	// Strategy is the strategy the backup was taken with, as Kind/name.
+	Strategy string `json:"strategy"`
+	// SizeBytes is the size of the backup artifact, if known.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// Restorable reports whether a RestoreJob can be started from the backup.
+	Restorable bool `json:"restorable"`
+	// Message explains why the backup is not restorable.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Copies lists the copies of the backup in additional Storages.
+	// +optional
+	Copies []RestorePointCopy `json:"copies,omitempty"`
+}
+
+// RestorePointCopy is a copy of a backup in an additional Storage.
+type RestorePointCopy struct {
+	// Storage is the Storage holding the copy, as Kind/name.
+	Storage string `json:"storage"`
+	// Phase is the state of the copy.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// SizeBytes is the size of the copy, if known.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationBackupList lists the backup runs of an Application, newest first.
+type ApplicationBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []ApplicationBackup `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationBackup is a backup run of an Application. It is backed by a
+// BackupJob of the same name in the namespace of the Application.
+type ApplicationBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec   ApplicationBackupSpec   `json:"spec" protobuf:"bytes,2,opt,name=spec"`
+	Status ApplicationBackupStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// ApplicationBackupSpec selects how and where an Application is backed up.
+type ApplicationBackupSpec struct {
+	// StrategyRef refers to the strategy the backup is taken with: a
+	// StrategyBinding in the namespace or a cluster-scoped strategy.
+	StrategyRef BackupObjectReference `json:"strategyRef"`
+	// StorageRef refers to the Storage the backup is stored in. Defaults to
+	// the default storage of the namespace or the cluster.
+	// +optional
+	StorageRef *BackupObjectReference `json:"storageRef,omitempty"`
+	// Plan is the name of the Plan that requested the backup. It is empty for
+	// backups requested on demand and cannot be set.
+	// +optional
+	Plan string `json:"plan,omitempty"`
+}
+
+// BackupObjectReference refers to a backups.cozystack.io strategy or storage.
+type BackupObjectReference struct {
+	// APIGroup is the group of the referenced object.
+	// +optional
+	APIGroup string `json:"apiGroup,omitempty"`
+	// Kind is the kind of the referenced object.
+	Kind string `json:"kind"`
+	// Name is the name of the referenced object.
+	Name string `json:"name"`
+}
+
+// ApplicationBackupStatus is the state of a backup run.
+type ApplicationBackupStatus struct {
+	// Phase is the state of the run: Pending, Running, Succeeded or Failed.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// Backup is the name of the Backup taken by the run, once it succeeded.
+	// +optional
+	Backup string `json:"backup,omitempty"`
+	// StartedAt is the time at which the run started.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// CompletedAt is the time at which the run completed.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+	// Message explains the phase of the run.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationRestoreList lists the restore runs of an Application, newest first.
+type ApplicationRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []ApplicationRestore `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationRestore is a restore run of an Application from one of its
+// backups. It is backed by a RestoreJob of the same name in the namespace of
+// the Application.
+type ApplicationRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec   ApplicationRestoreSpec   `json:"spec" protobuf:"bytes,2,opt,name=spec"`
+	Status ApplicationRestoreStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// ApplicationRestoreSpec selects the backup an Application is restored from.
+type ApplicationRestoreSpec struct {
+	// Backup is the name of the Backup to restore, as listed by the
+	// restorepoints subresource.
+	Backup string `json:"backup"`
+}
+
+// ApplicationRestoreStatus is the state of a restore run.
+type ApplicationRestoreStatus struct {
+	// Phase is the state of the run: Pending, Running, Succeeded or Failed.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// StartedAt is the time at which the run started.
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// CompletedAt is the time at which the run completed.
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+	// Message explains the phase of the run.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
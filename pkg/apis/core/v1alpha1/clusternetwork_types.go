@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 The Cozystack Authors.
+
+// This file contains the cluster-scoped “ClusterNetwork” resource.
+// ClusterNetwork is a read-only singleton named "cluster" exposing the
+// networking facts of the cluster, read from the cozystack-values Secret.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterNetwork holds the networking facts of the cluster. There is a single
+// ClusterNetwork named "cluster".
+type ClusterNetwork struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// ClusterDomain is the DNS domain of the cluster, e.g. cozy.local
+	ClusterDomain string `json:"clusterDomain"`
+
+	// IngressDomain is the root domain applications are exposed under
+	IngressDomain string `json:"ingressDomain,omitempty"`
+
+	// ExposeIngress is the tenant whose ingress exposes the system services
+	ExposeIngress string `json:"exposeIngress,omitempty"`
+
+	// ExternalIPs are the addresses the ingress is exposed on
+	ExternalIPs []string `json:"externalIPs,omitempty"`
+
+	// LoadBalancerPools are the address pools LoadBalancer Services get
+	// their addresses from
+	LoadBalancerPools []LoadBalancerPool `json:"loadBalancerPools,omitempty"`
+
+	// PodCIDR is the IPv4 range of the pod network
+	PodCIDR string `json:"podCIDR,omitempty"`
+
+	// ServiceCIDR is the IPv4 range of the Service network
+	ServiceCIDR string `json:"serviceCIDR,omitempty"`
+
+	// JoinCIDR is the IPv4 range connecting the nodes to the pod network
+	JoinCIDR string `json:"joinCIDR,omitempty"`
+
+	// APIServerEndpoint is the endpoint of the Kubernetes API server
+	APIServerEndpoint string `json:"apiServerEndpoint,omitempty"`
+}
+
+// LoadBalancerPool is an address pool of LoadBalancer Services
+type LoadBalancerPool struct {
+	// Name is the name of the pool
+	Name string `json:"name"`
+
+	// Addresses are the ranges of the pool, as CIDRs or first-last ranges
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterNetworkList is the list variant for ClusterNetwork.
+type ClusterNetworkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNetwork `json:"items"`
+}
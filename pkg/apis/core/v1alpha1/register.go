@@ -55,13 +55,17 @@ func Resource(resource string) schema.GroupResource {
 // RegisterStaticTypes adds *compile-time* resources such as TenantNamespace.
 func RegisterStaticTypes(scheme *runtime.Scheme) {
 	scheme.AddKnownTypes(SchemeGroupVersion,
+		&ClusterNetwork{},
+		&ClusterNetworkList{},
 		&TenantNamespace{},
 		&TenantNamespaceList{},
 		&TenantSecret{},
 		&TenantSecretList{},
 		&TenantModule{},
 		&TenantModuleList{},
+		&WorkloadSummary{},
+		&WorkloadSummaryList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
-	klog.V(1).Info("Registered static kinds: TenantNamespace, TenantSecret, TenantModule")
+	klog.V(1).Info("Registered static kinds: ClusterNetwork, TenantNamespace, TenantSecret, TenantModule, WorkloadSummary")
 }
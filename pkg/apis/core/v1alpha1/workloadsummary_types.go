@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2025 The Cozystack Authors.
+
+// This file contains the cluster-scoped “WorkloadSummary” resource.
+// A WorkloadSummary is a read-only overview of a single Application of any
+// kind, derived from the HelmRelease that backs it.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WorkloadSummary summarizes an Application of any kind in any namespace.
+// Its name is "<namespace>.<kind>.<name>", with the kind in lower case.
+type WorkloadSummary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// ApplicationKind is the kind of the Application, e.g. Postgres
+	ApplicationKind string `json:"applicationKind"`
+
+	// ApplicationName is the name of the Application
+	ApplicationName string `json:"applicationName"`
+
+	// ApplicationNamespace is the namespace of the Application
+	ApplicationNamespace string `json:"applicationNamespace"`
+
+	// Ready is the status of the Ready condition: True, False or Unknown
+	Ready metav1.ConditionStatus `json:"ready"`
+
+	// Version is the last attempted revision of the Application chart
+	Version string `json:"version,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WorkloadSummaryList is the list variant for WorkloadSummary.
+type WorkloadSummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WorkloadSummary `json:"items"`
+}
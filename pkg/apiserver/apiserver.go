@@ -22,6 +22,8 @@ import (
 	"time"
 
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,6 +38,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
 	"github.com/cozystack/cozystack/pkg/apis/apps"
 	appsinstall "github.com/cozystack/cozystack/pkg/apis/apps/install"
 	"github.com/cozystack/cozystack/pkg/apis/core"
@@ -43,9 +46,11 @@ import (
 	"github.com/cozystack/cozystack/pkg/config"
 	cozyregistry "github.com/cozystack/cozystack/pkg/registry"
 	applicationstorage "github.com/cozystack/cozystack/pkg/registry/apps/application"
+	clusternetworkstorage "github.com/cozystack/cozystack/pkg/registry/core/clusternetwork"
 	tenantmodulestorage "github.com/cozystack/cozystack/pkg/registry/core/tenantmodule"
 	tenantnamespacestorage "github.com/cozystack/cozystack/pkg/registry/core/tenantnamespace"
 	tenantsecretstorage "github.com/cozystack/cozystack/pkg/registry/core/tenantsecret"
+	workloadsummarystorage "github.com/cozystack/cozystack/pkg/registry/core/workloadsummary"
 )
 
 var (
@@ -68,6 +73,11 @@ func init() {
 	appsinstall.Install(Scheme)
 	coreinstall.Install(Scheme)
 
+	// Register the Scale of the Application scale subresource. As for the
+	// Application kinds, the internal version is the versioned type.
+	Scheme.AddKnownTypes(autoscalingv1.SchemeGroupVersion, &autoscalingv1.Scale{})
+	Scheme.AddKnownTypes(schema.GroupVersion{Group: autoscalingv1.GroupName, Version: runtime.APIVersionInternal}, &autoscalingv1.Scale{})
+
 	// Register HelmRelease types.
 	if err := helmv2.AddToScheme(mgrScheme); err != nil {
 		panic(fmt.Errorf("Failed to add HelmRelease types to scheme: %w", err))
@@ -79,6 +89,12 @@ func init() {
 	if err := rbacv1.AddToScheme(mgrScheme); err != nil {
 		panic(fmt.Errorf("Failed to add RBAC types to scheme: %w", err))
 	}
+	if err := appsv1.AddToScheme(mgrScheme); err != nil {
+		panic(fmt.Errorf("Failed to add apps types to scheme: %w", err))
+	}
+	if err := backupsv1alpha1.AddToScheme(mgrScheme); err != nil {
+		panic(fmt.Errorf("Failed to add backup types to scheme: %w", err))
+	}
 	// Add unversioned types.
 	metav1.AddToGroupVersion(Scheme, schema.GroupVersion{Version: "v1"})
 
@@ -97,6 +113,9 @@ func init() {
 type Config struct {
 	GenericConfig  *genericapiserver.RecommendedConfig
 	ResourceConfig *config.ResourceConfig
+	// UpdateConflictRetries is how many times conflicting Application patches
+	// are retried. 0 disables retries.
+	UpdateConflictRetries int
 }
 
 // CozyServer holds the state for the Kubernetes master/api server.
@@ -105,8 +124,9 @@ type CozyServer struct {
 }
 
 type completedConfig struct {
-	GenericConfig  genericapiserver.CompletedConfig
-	ResourceConfig *config.ResourceConfig
+	GenericConfig         genericapiserver.CompletedConfig
+	ResourceConfig        *config.ResourceConfig
+	UpdateConflictRetries int
 }
 
 // CompletedConfig embeds a private pointer that cannot be created outside of this package.
@@ -119,6 +139,7 @@ func (cfg *Config) Complete() CompletedConfig {
 	c := completedConfig{
 		cfg.GenericConfig.Complete(),
 		cfg.ResourceConfig,
+		cfg.UpdateConflictRetries,
 	}
 
 	return CompletedConfig{&c}
@@ -151,12 +172,21 @@ func (c completedConfig) New() (*CozyServer, error) {
 
 	ctx := ctrl.SetupSignalHandler()
 
+	// Application lists look up their HelmReleases by kind in the cache
+	// instead of matching the labels of every HelmRelease of a namespace
+	if err = applicationstorage.IndexHelmReleases(ctx, mgr.GetFieldIndexer()); err != nil {
+		return nil, fmt.Errorf("failed to index HelmReleases: %w", err)
+	}
+
 	if err = mustGetInformers(ctx, mgr,
 		&helmv2.HelmRelease{},
 		&corev1.Secret{},
 		&corev1.Namespace{},
 		&corev1.Service{},
+		&corev1.ResourceQuota{},
 		&rbacv1.RoleBinding{},
+		&appsv1.Deployment{},
+		&appsv1.StatefulSet{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to get informers: %w", err)
 	}
@@ -187,6 +217,12 @@ func (c completedConfig) New() (*CozyServer, error) {
 	coreV1alpha1Storage["tenantmodules"] = cozyregistry.RESTInPeace(
 		tenantmodulestorage.NewREST(cli, watchCli),
 	)
+	coreV1alpha1Storage["workloadsummaries"] = cozyregistry.RESTInPeace(
+		workloadsummarystorage.NewREST(cli),
+	)
+	coreV1alpha1Storage["clusternetworks"] = cozyregistry.RESTInPeace(
+		clusternetworkstorage.NewREST(cli, watchCli),
+	)
 
 	coreApiGroupInfo := genericapiserver.NewDefaultAPIGroupInfo(core.GroupName, Scheme, metav1.ParameterCodec, Codecs)
 	coreApiGroupInfo.VersionedResourcesStorageMap["v1alpha1"] = coreV1alpha1Storage
@@ -195,10 +231,33 @@ func (c completedConfig) New() (*CozyServer, error) {
 	}
 
 	// --- dynamically-configured, per-tenant resources ---
+	// Application watches are closed with a bookmark on shutdown, so that clients
+	// resume against another replica instead of erroring out
+	drainer := applicationstorage.NewWatchDrainer()
+	gracePeriod := c.GenericConfig.ShutdownWatchTerminationGracePeriod
+	if err := s.GenericAPIServer.AddPreShutdownHook("drain-application-watches", func() error {
+		drainCtx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+		defer cancel()
+		return drainer.Drain(drainCtx)
+	}); err != nil {
+		return nil, err
+	}
+	// Updates racing with Flux status updates or other writers are retried
+	// against the latest version
+	var writes *applicationstorage.WriteCoordinator
+	if c.UpdateConflictRetries > 0 {
+		writes = applicationstorage.NewWriteCoordinator(c.UpdateConflictRetries)
+	}
 	appsV1alpha1Storage := map[string]rest.Storage{}
 	for _, resConfig := range c.ResourceConfig.Resources {
 		storage := applicationstorage.NewREST(cli, watchCli, &resConfig)
+		storage.SetWatchDrainer(drainer)
+		storage.SetWriteCoordinator(writes)
+		storage.SetIndexed(true)
 		appsV1alpha1Storage[resConfig.Application.Plural] = cozyregistry.RESTInPeace(storage)
+		for name, subresource := range storage.Subresources() {
+			appsV1alpha1Storage[resConfig.Application.Plural+"/"+name] = cozyregistry.RESTInPeace(subresource)
+		}
 	}
 	appsApiGroupInfo := genericapiserver.NewDefaultAPIGroupInfo(apps.GroupName, Scheme, metav1.ParameterCodec, Codecs)
 	appsApiGroupInfo.VersionedResourcesStorageMap["v1alpha1"] = appsV1alpha1Storage
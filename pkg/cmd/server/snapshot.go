@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cozystack/cozystack/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ResourceDefinitionSnapshotName is the name of the ConfigMap that holds
+	// the last validated resource configuration served by the API server
+	ResourceDefinitionSnapshotName = "cozystack-api-resource-definition-snapshot"
+	// ResourceDefinitionSnapshotNamespace is the namespace of the snapshot ConfigMap
+	ResourceDefinitionSnapshotNamespace = "cozy-system"
+
+	snapshotConfigKey = "config.json"
+)
+
+// loadResourceDefinitionSnapshot returns the last validated resource configuration,
+// or nil if no snapshot has been stored yet
+func loadResourceDefinitionSnapshot(ctx context.Context, c client.Client) (*config.ResourceConfig, error) {
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: ResourceDefinitionSnapshotNamespace, Name: ResourceDefinitionSnapshotName}
+	if err := c.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	raw, ok := cm.Data[snapshotConfigKey]
+	if !ok {
+		return nil, nil
+	}
+	cfg := &config.ResourceConfig{}
+	if err := json.Unmarshal([]byte(raw), cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return cfg, nil
+}
+
+// saveResourceDefinitionSnapshot stores a validated resource configuration
+func saveResourceDefinitionSnapshot(ctx context.Context, c client.Client, cfg *config.ResourceConfig) error {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: ResourceDefinitionSnapshotNamespace, Name: ResourceDefinitionSnapshotName}
+	err = c.Get(ctx, key, cm)
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ResourceDefinitionSnapshotName,
+				Namespace: ResourceDefinitionSnapshotNamespace,
+			},
+			Data: map[string]string{snapshotConfigKey: string(raw)},
+		}
+		return c.Create(ctx, cm)
+	} else if err != nil {
+		return err
+	}
+
+	if cm.Data[snapshotConfigKey] == string(raw) {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[snapshotConfigKey] = string(raw)
+	return c.Update(ctx, cm)
+}
+
+// activateResourceConfig decides which resource configuration the API server
+// serves. A candidate that passes validation is activated and stored as the new
+// snapshot. Otherwise the last validated snapshot is served, and if there is
+// none, only the valid subset of the candidate.
+func activateResourceConfig(ctx context.Context, c client.Client, candidate *config.ResourceConfig) *config.ResourceConfig {
+	valid, errs := config.Validate(candidate)
+	if len(errs) == 0 {
+		if err := saveResourceDefinitionSnapshot(ctx, c, candidate); err != nil {
+			fmt.Printf("Failed to store resource definition snapshot: %v\n", err)
+		}
+		return candidate
+	}
+
+	for _, err := range errs {
+		fmt.Printf("Invalid CozystackResourceDefinition: %v\n", err)
+	}
+
+	snapshot, err := loadResourceDefinitionSnapshot(ctx, c)
+	if err != nil {
+		fmt.Printf("Failed to load resource definition snapshot: %v\n", err)
+	}
+	if snapshot != nil {
+		fmt.Printf("Serving last validated resource definition snapshot with %d resource(s)\n", len(snapshot.Resources))
+		return snapshot
+	}
+
+	fmt.Printf("No resource definition snapshot found, serving %d of %d resource(s)\n", len(valid.Resources), len(candidate.Resources))
+	return valid
+}
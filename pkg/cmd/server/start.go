@@ -33,6 +33,7 @@ import (
 	"github.com/cozystack/cozystack/pkg/config"
 	sampleopenapi "github.com/cozystack/cozystack/pkg/generated/openapi"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/endpoints/openapi"
@@ -56,6 +57,14 @@ type CozyServerOptions struct {
 	AlternateDNS []string
 	Client       client.Client
 
+	// ShutdownWatchTerminationGracePeriod is how long active watches are given
+	// to drain on shutdown
+	ShutdownWatchTerminationGracePeriod time.Duration
+
+	// UpdateConflictRetries is how many times an Application patch that
+	// conflicts with a concurrent write is retried. 0 disables retries.
+	UpdateConflictRetries int
+
 	// Add a field to store the configuration
 	ResourceConfig *config.ResourceConfig
 }
@@ -72,6 +81,9 @@ func NewCozyServerOptions(out, errOut io.Writer) *CozyServerOptions {
 		),
 		StdOut: out,
 		StdErr: errOut,
+
+		ShutdownWatchTerminationGracePeriod: 10 * time.Second,
+		UpdateConflictRetries:               3,
 	}
 	o.RecommendedOptions.Etcd = nil
 	return o
@@ -100,6 +112,10 @@ func NewCommandStartCozyServer(ctx context.Context, defaults *CozyServerOptions)
 
 	flags := cmd.Flags()
 	o.RecommendedOptions.AddFlags(flags)
+	flags.DurationVar(&o.ShutdownWatchTerminationGracePeriod, "shutdown-watch-termination-grace-period", o.ShutdownWatchTerminationGracePeriod,
+		"How long active watches are given to close gracefully on shutdown before the server stops.")
+	flags.IntVar(&o.UpdateConflictRetries, "update-conflict-retries", o.UpdateConflictRetries,
+		"How many times an Application patch conflicting with a concurrent write is retried against the latest version. Updates (PUT) are not retried. 0 disables retries.")
 
 	// Note: KEP-4330 component versioning functionality (k8s.io/apiserver/pkg/util/version)
 	// is not available in Kubernetes v0.34.1. The component versioning code has been removed.
@@ -113,6 +129,9 @@ func (o *CozyServerOptions) Complete() error {
 	if err := v1alpha1.AddToScheme(scheme); err != nil {
 		return fmt.Errorf("failed to register types: %w", err)
 	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("failed to register types: %w", err)
+	}
 
 	cfg, err := k8sconfig.GetConfig()
 	if err != nil {
@@ -155,32 +174,9 @@ func (o *CozyServerOptions) Complete() error {
 		}
 	}
 
-	// Convert to ResourceConfig
-	o.ResourceConfig = &config.ResourceConfig{}
-	for _, crd := range crdList.Items {
-		resource := config.Resource{
-			Application: config.ApplicationConfig{
-				Kind:          crd.Spec.Application.Kind,
-				Singular:      crd.Spec.Application.Singular,
-				Plural:        crd.Spec.Application.Plural,
-				ShortNames:    []string{}, // TODO: implement shortnames
-				OpenAPISchema: crd.Spec.Application.OpenAPISchema,
-			},
-			Release: config.ReleaseConfig{
-				Prefix: crd.Spec.Release.Prefix,
-				Labels: crd.Spec.Release.Labels,
-				Chart: config.ChartConfig{
-					Name: crd.Spec.Release.Chart.Name,
-					SourceRef: config.SourceRefConfig{
-						Kind:      crd.Spec.Release.Chart.SourceRef.Kind,
-						Name:      crd.Spec.Release.Chart.SourceRef.Name,
-						Namespace: crd.Spec.Release.Chart.SourceRef.Namespace,
-					},
-				},
-			},
-		}
-		o.ResourceConfig.Resources = append(o.ResourceConfig.Resources, resource)
-	}
+	// Serve the candidate configuration only if it validates, otherwise fall
+	// back to the last validated snapshot
+	o.ResourceConfig = activateResourceConfig(context.Background(), o.Client, config.FromResourceDefinitions(crdList.Items))
 
 	return nil
 }
@@ -257,10 +253,12 @@ func (o *CozyServerOptions) Config() (*apiserver.Config, error) {
 	if err := o.RecommendedOptions.ApplyTo(serverConfig); err != nil {
 		return nil, err
 	}
+	serverConfig.ShutdownWatchTerminationGracePeriod = o.ShutdownWatchTerminationGracePeriod
 
 	config := &apiserver.Config{
-		GenericConfig:  serverConfig,
-		ResourceConfig: o.ResourceConfig,
+		GenericConfig:         serverConfig,
+		ResourceConfig:        o.ResourceConfig,
+		UpdateConflictRetries: o.UpdateConflictRetries,
 	}
 	return config, nil
 }
@@ -16,6 +16,8 @@ limitations under the License.
 
 package config
 
+import "time"
+
 // ResourceConfig represents the structure of the configuration file.
 type ResourceConfig struct {
 	Resources []Resource `yaml:"resources"`
@@ -34,6 +36,61 @@ type ApplicationConfig struct {
 	Plural        string   `yaml:"plural"`
 	ShortNames    []string `yaml:"shortNames"`
 	OpenAPISchema string   `yaml:"openAPISchema"`
+	// PreserveUnknownFields keeps spec fields that are not described by OpenAPISchema
+	PreserveUnknownFields bool `yaml:"preserveUnknownFields"`
+	// ReservedKeys are dot-separated spec paths that users must not set
+	ReservedKeys []string `yaml:"reservedKeys"`
+	// DeprecatedValues are spec values that produce a warning when set
+	DeprecatedValues []DeprecatedValue `yaml:"deprecatedValues"`
+	// Connection describes where the connection details of an application are found
+	Connection *ConnectionConfig `yaml:"connection"`
+	// RateLimit limits the requests to applications of the kind per namespace
+	RateLimit *RateLimitConfig `yaml:"rateLimit"`
+	// Scale maps the scale subresource to the values of an application
+	Scale *ScaleConfig `yaml:"scale"`
+}
+
+// ScaleConfig maps the scale subresource to the values of an application.
+type ScaleConfig struct {
+	// ReplicasPath is the path of the number of replicas in the values, e.g. ".replicas"
+	ReplicasPath string `yaml:"replicasPath"`
+	// LabelSelector is the template of the selector of the pods of an application
+	LabelSelector string `yaml:"labelSelector"`
+}
+
+// RateLimitConfig is a token bucket rate limit.
+type RateLimitConfig struct {
+	// QPS is the sustained number of requests per second
+	QPS float64 `yaml:"qps"`
+	// Burst is the number of requests allowed above QPS in bursts
+	Burst int `yaml:"burst"`
+}
+
+// ConnectionConfig describes the connection details of an application.
+type ConnectionConfig struct {
+	Host              *ConnectionValue `yaml:"host"`
+	Port              *ConnectionValue `yaml:"port"`
+	CredentialsSecret *ConnectionValue `yaml:"credentialsSecret"`
+}
+
+// ConnectionValue describes where a connection detail is read from.
+type ConnectionValue struct {
+	// Source is one of Values, Service or Secret
+	Source string `yaml:"source"`
+	// Name is the name template of the Service or Secret
+	Name string `yaml:"name"`
+	// JSONPath is evaluated against the release values or the Service
+	JSONPath string `yaml:"jsonPath"`
+}
+
+// DeprecatedValue describes a deprecated spec value.
+type DeprecatedValue struct {
+	// Path is the dot-separated spec path of the deprecated value
+	Path string `yaml:"path"`
+	// Replacement is the dot-separated spec path to use instead, if any
+	Replacement string `yaml:"replacement"`
+	// Message is an additional hint for users
+	Message string `yaml:"message"`
 }
 
 // ReleaseConfig contains the release settings.
@@ -41,6 +98,22 @@ type ReleaseConfig struct {
 	Prefix string            `yaml:"prefix"`
 	Labels map[string]string `yaml:"labels"`
 	Chart  ChartConfig       `yaml:"chart"`
+	// Interval is the reconcile interval of the release, 5m if zero
+	Interval time.Duration `yaml:"interval"`
+	// Timeout is the timeout of Helm actions, the helm-controller default if zero
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxHistory is the number of revisions kept, the helm-controller default if nil
+	MaxHistory *int `yaml:"maxHistory"`
+	// Remediation configures retries and drift detection of the release
+	Remediation *RemediationConfig `yaml:"remediation"`
+}
+
+// RemediationConfig contains the remediation settings of a release.
+type RemediationConfig struct {
+	// Retries is the number of install and upgrade retries, negative for unlimited
+	Retries *int `yaml:"retries"`
+	// DriftDetection is one of enabled, warn or disabled
+	DriftDetection string `yaml:"driftDetection"`
 }
 
 // ChartConfig contains the chart settings.
@@ -0,0 +1,333 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	v1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+	internalapiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// FromResourceDefinitions converts CozystackResourceDefinitions to a ResourceConfig.
+func FromResourceDefinitions(items []v1alpha1.CozystackResourceDefinition) *ResourceConfig {
+	cfg := &ResourceConfig{}
+	for _, crd := range items {
+		// Unknown fields are preserved unless explicitly disabled
+		preserveUnknownFields := true
+		if crd.Spec.Application.PreserveUnknownFields != nil {
+			preserveUnknownFields = *crd.Spec.Application.PreserveUnknownFields
+		}
+		var deprecatedValues []DeprecatedValue
+		for _, v := range crd.Spec.Application.DeprecatedValues {
+			deprecatedValues = append(deprecatedValues, DeprecatedValue{
+				Path:        v.Path,
+				Replacement: v.Replacement,
+				Message:     v.Message,
+			})
+		}
+		var connection *ConnectionConfig
+		if c := crd.Spec.Application.Connection; c != nil {
+			connection = &ConnectionConfig{
+				Host:              connectionValue(c.Host),
+				Port:              connectionValue(c.Port),
+				CredentialsSecret: connectionValue(c.CredentialsSecret),
+			}
+		}
+		var scale *ScaleConfig
+		if sc := crd.Spec.Application.Scale; sc != nil {
+			scale = &ScaleConfig{
+				ReplicasPath:  sc.ReplicasPath,
+				LabelSelector: sc.LabelSelector,
+			}
+		}
+		var remediation *RemediationConfig
+		if rm := crd.Spec.Remediation; rm != nil {
+			remediation = &RemediationConfig{
+				Retries:        rm.Retries,
+				DriftDetection: string(rm.DriftDetection),
+			}
+		}
+		cfg.Resources = append(cfg.Resources, Resource{
+			Application: ApplicationConfig{
+				Kind:                  crd.Spec.Application.Kind,
+				Singular:              crd.Spec.Application.Singular,
+				Plural:                crd.Spec.Application.Plural,
+				ShortNames:            []string{}, // TODO: implement shortnames
+				OpenAPISchema:         crd.Spec.Application.OpenAPISchema,
+				PreserveUnknownFields: preserveUnknownFields,
+				ReservedKeys:          crd.Spec.Application.ReservedKeys,
+				DeprecatedValues:      deprecatedValues,
+				Connection:            connection,
+				RateLimit:             rateLimit(crd.Annotations),
+				Scale:                 scale,
+			},
+			Release: ReleaseConfig{
+				Prefix: crd.Spec.Release.Prefix,
+				Labels: crd.Spec.Release.Labels,
+				Chart: ChartConfig{
+					Name: crd.Spec.Release.Chart.Name,
+					SourceRef: SourceRefConfig{
+						Kind:      crd.Spec.Release.Chart.SourceRef.Kind,
+						Name:      crd.Spec.Release.Chart.SourceRef.Name,
+						Namespace: crd.Spec.Release.Chart.SourceRef.Namespace,
+					},
+				},
+				Interval:    durationValue(crd.Spec.Release.Interval),
+				Timeout:     durationValue(crd.Spec.Release.Timeout),
+				MaxHistory:  crd.Spec.Release.MaxHistory,
+				Remediation: remediation,
+			},
+		})
+	}
+	return cfg
+}
+
+// rateLimit reads the rate limit of a kind from the annotations of its
+// CozystackResourceDefinition. Values that are not numbers are kept as -1 to
+// be reported by Validate.
+func rateLimit(annotations map[string]string) *RateLimitConfig {
+	rawQPS, ok := annotations[v1alpha1.RateLimitQPSAnnotation]
+	if !ok {
+		return nil
+	}
+	rl := &RateLimitConfig{QPS: -1, Burst: -1}
+	if qps, err := strconv.ParseFloat(strings.TrimSpace(rawQPS), 64); err == nil {
+		rl.QPS = qps
+	}
+	rawBurst, ok := annotations[v1alpha1.RateLimitBurstAnnotation]
+	if !ok {
+		rl.Burst = int(math.Ceil(rl.QPS))
+	} else if burst, err := strconv.Atoi(strings.TrimSpace(rawBurst)); err == nil {
+		rl.Burst = burst
+	}
+	return rl
+}
+
+// durationValue returns the duration d or zero if it is not set
+func durationValue(d *metav1.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.Duration
+}
+
+// connectionValue converts a connection value of a CozystackResourceDefinition
+func connectionValue(v *v1alpha1.CozystackResourceDefinitionConnectionValue) *ConnectionValue {
+	if v == nil {
+		return nil
+	}
+	return &ConnectionValue{
+		Source:   string(v.Source),
+		Name:     v.Name,
+		JSONPath: v.JSONPath,
+	}
+}
+
+// Validate checks that every resource can be served by the apps API group:
+// kind, singular and plural names are set and unique, and the OpenAPI schema
+// compiles into a structural schema. It returns a configuration holding only
+// the valid resources together with the problems found. When two resources
+// conflict, the first one wins.
+func Validate(cfg *ResourceConfig) (*ResourceConfig, []error) {
+	valid := &ResourceConfig{}
+	if cfg == nil {
+		return valid, nil
+	}
+
+	var errs []error
+	kinds := map[string]bool{}
+	names := map[string]string{} // lowercased singular/plural -> kind
+
+	for _, res := range cfg.Resources {
+		app := res.Application
+		if err := validateResource(res); err != nil {
+			errs = append(errs, fmt.Errorf("resource %q: %w", app.Kind, err))
+			continue
+		}
+		if kinds[app.Kind] {
+			errs = append(errs, fmt.Errorf("resource %q: kind is defined more than once", app.Kind))
+			continue
+		}
+		conflict := ""
+		for _, n := range []string{app.Plural, app.Singular} {
+			if owner, ok := names[strings.ToLower(n)]; ok {
+				conflict = fmt.Sprintf("name %q conflicts with kind %q", n, owner)
+				break
+			}
+		}
+		if conflict != "" {
+			errs = append(errs, fmt.Errorf("resource %q: %s", app.Kind, conflict))
+			continue
+		}
+
+		kinds[app.Kind] = true
+		names[strings.ToLower(app.Plural)] = app.Kind
+		names[strings.ToLower(app.Singular)] = app.Kind
+		valid.Resources = append(valid.Resources, res)
+	}
+
+	return valid, errs
+}
+
+// validateResource checks a single resource in isolation
+func validateResource(res Resource) error {
+	app := res.Application
+	switch {
+	case app.Kind == "":
+		return fmt.Errorf("application kind is empty")
+	case app.Plural == "":
+		return fmt.Errorf("application plural is empty")
+	case app.Singular == "":
+		return fmt.Errorf("application singular is empty")
+	}
+
+	if err := validateConnection(app.Connection); err != nil {
+		return err
+	}
+	if err := validateScale(app.Scale); err != nil {
+		return err
+	}
+	if err := validateRemediation(res.Release.Remediation); err != nil {
+		return err
+	}
+	if err := validateRelease(res.Release); err != nil {
+		return err
+	}
+	if rl := app.RateLimit; rl != nil && (rl.QPS <= 0 || rl.Burst < 1) {
+		return fmt.Errorf("rate limit: %s must be a positive number and %s a positive integer",
+			v1alpha1.RateLimitQPSAnnotation, v1alpha1.RateLimitBurstAnnotation)
+	}
+
+	raw := strings.TrimSpace(app.OpenAPISchema)
+	if raw == "" {
+		return nil
+	}
+	var v1js apiextv1.JSONSchemaProps
+	if err := json.Unmarshal([]byte(raw), &v1js); err != nil {
+		return fmt.Errorf("failed to unmarshal OpenAPI schema: %w", err)
+	}
+	scheme := runtime.NewScheme()
+	_ = internalapiext.AddToScheme(scheme)
+	_ = apiextv1.AddToScheme(scheme)
+	var ijs internalapiext.JSONSchemaProps
+	if err := scheme.Convert(&v1js, &ijs, nil); err != nil {
+		return fmt.Errorf("failed to convert OpenAPI schema: %w", err)
+	}
+	if _, err := structuralschema.NewStructural(&ijs); err != nil {
+		return fmt.Errorf("OpenAPI schema is not structural: %w", err)
+	}
+	return nil
+}
+
+// validateConnection checks the sources and JSONPaths of the connection details
+func validateConnection(c *ConnectionConfig) error {
+	if c == nil {
+		return nil
+	}
+	values := []struct {
+		field string
+		value *ConnectionValue
+	}{{"host", c.Host}, {"port", c.Port}, {"credentialsSecret", c.CredentialsSecret}}
+	for _, fv := range values {
+		field, v := fv.field, fv.value
+		if v == nil {
+			continue
+		}
+		switch v.Source {
+		case "Values":
+		case "Service", "Secret":
+			if v.Name == "" {
+				return fmt.Errorf("connection %s: name is required for source %s", field, v.Source)
+			}
+		default:
+			return fmt.Errorf("connection %s: unknown source %q", field, v.Source)
+		}
+		if v.JSONPath != "" && v.Source != "Secret" {
+			if err := jsonpath.New(field).Parse(v.JSONPath); err != nil {
+				return fmt.Errorf("connection %s: invalid JSONPath: %w", field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateScale checks the replicas path of the scale subresource
+func validateScale(sc *ScaleConfig) error {
+	if sc == nil {
+		return nil
+	}
+	if len(ScalePath(sc.ReplicasPath)) == 0 {
+		return fmt.Errorf("scale: replicasPath must be a dot-separated path like .replicas, got %q", sc.ReplicasPath)
+	}
+	return nil
+}
+
+// ScalePath splits the replicas path of the scale subresource into its keys.
+// It returns nil if the path is not of the form ".key1.key2".
+func ScalePath(path string) []string {
+	if !strings.HasPrefix(path, ".") {
+		return nil
+	}
+	keys := strings.Split(path[1:], ".")
+	for _, key := range keys {
+		if key == "" {
+			return nil
+		}
+	}
+	return keys
+}
+
+// validateRelease checks the interval, timeout and history of a release
+func validateRelease(rel ReleaseConfig) error {
+	if rel.Interval < 0 {
+		return fmt.Errorf("release interval must not be negative, got %s", rel.Interval)
+	}
+	if rel.Timeout < 0 {
+		return fmt.Errorf("release timeout must not be negative, got %s", rel.Timeout)
+	}
+	if rel.MaxHistory != nil && *rel.MaxHistory < 0 {
+		return fmt.Errorf("release maxHistory must not be negative, got %d", *rel.MaxHistory)
+	}
+	return nil
+}
+
+// validateRemediation checks the retries and drift detection mode of a release
+func validateRemediation(rm *RemediationConfig) error {
+	if rm == nil {
+		return nil
+	}
+	if rm.Retries != nil && *rm.Retries < -1 {
+		return fmt.Errorf("remediation retries must be -1 or greater, got %d", *rm.Retries)
+	}
+	switch rm.DriftDetection {
+	case "", "enabled", "warn", "disabled":
+	default:
+		return fmt.Errorf("remediation: unknown drift detection mode %q", rm.DriftDetection)
+	}
+	return nil
+}
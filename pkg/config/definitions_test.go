@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func testResource(kind, singular, plural, schema string) Resource {
+	return Resource{Application: ApplicationConfig{
+		Kind:          kind,
+		Singular:      singular,
+		Plural:        plural,
+		OpenAPISchema: schema,
+	}}
+}
+
+func TestValidateAcceptsValidConfig(t *testing.T) {
+	cfg := &ResourceConfig{Resources: []Resource{
+		testResource("Postgres", "postgres", "postgreses", `{"type":"object","properties":{"replicas":{"type":"integer"}}}`),
+		testResource("Redis", "redis", "redises", ""),
+	}}
+
+	valid, errs := Validate(cfg)
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	if len(valid.Resources) != 2 {
+		t.Fatalf("expected 2 valid resources, got %d", len(valid.Resources))
+	}
+}
+
+func TestValidateRejectsInvalidResources(t *testing.T) {
+	cfg := &ResourceConfig{Resources: []Resource{
+		testResource("Postgres", "postgres", "postgreses", ""),
+		testResource("Postgres", "postgres2", "postgreses2", ""),
+		testResource("PG", "pg", "postgreses", ""),
+		testResource("Broken", "broken", "brokens", `{"type":`),
+		testResource("", "empty", "empties", ""),
+	}}
+
+	valid, errs := Validate(cfg)
+	if len(valid.Resources) != 1 || valid.Resources[0].Application.Kind != "Postgres" {
+		t.Fatalf("expected only the first Postgres to be valid, got %+v", valid.Resources)
+	}
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestValidateRejectsInvalidConnection(t *testing.T) {
+	valid := testResource("Postgres", "postgres", "postgreses", "")
+	valid.Application.Connection = &ConnectionConfig{
+		Host:              &ConnectionValue{Source: "Service", Name: "{{ .name }}-rw", JSONPath: "{.metadata.name}.{.metadata.namespace}.svc"},
+		Port:              &ConnectionValue{Source: "Values", JSONPath: "{.port}"},
+		CredentialsSecret: &ConnectionValue{Source: "Secret", Name: "{{ .name }}-credentials"},
+	}
+	noName := testResource("Redis", "redis", "redises", "")
+	noName.Application.Connection = &ConnectionConfig{Host: &ConnectionValue{Source: "Service"}}
+	badPath := testResource("Kafka", "kafka", "kafkas", "")
+	badPath.Application.Connection = &ConnectionConfig{Port: &ConnectionValue{Source: "Values", JSONPath: "{.port"}}
+	badSource := testResource("NATS", "nats", "natses", "")
+	badSource.Application.Connection = &ConnectionConfig{Port: &ConnectionValue{Source: "ConfigMap"}}
+
+	cfg := &ResourceConfig{Resources: []Resource{valid, noName, badPath, badSource}}
+	got, errs := Validate(cfg)
+	if len(got.Resources) != 1 || got.Resources[0].Application.Kind != "Postgres" {
+		t.Fatalf("expected only Postgres to be valid, got %+v", got.Resources)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestValidateRejectsInvalidRemediation(t *testing.T) {
+	retries := func(n int) *int { return &n }
+	valid := testResource("Postgres", "postgres", "postgreses", "")
+	valid.Release.Remediation = &RemediationConfig{Retries: retries(3), DriftDetection: "warn"}
+	badRetries := testResource("Redis", "redis", "redises", "")
+	badRetries.Release.Remediation = &RemediationConfig{Retries: retries(-2)}
+	badMode := testResource("Kafka", "kafka", "kafkas", "")
+	badMode.Release.Remediation = &RemediationConfig{DriftDetection: "always"}
+
+	cfg := &ResourceConfig{Resources: []Resource{valid, badRetries, badMode}}
+	got, errs := Validate(cfg)
+	if len(got.Resources) != 1 || got.Resources[0].Application.Kind != "Postgres" {
+		t.Fatalf("expected only Postgres to be valid, got %+v", got.Resources)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestValidateRejectsInvalidScale(t *testing.T) {
+	valid := testResource("Postgres", "postgres", "postgreses", "")
+	valid.Application.Scale = &ScaleConfig{ReplicasPath: ".replicas", LabelSelector: "app={{ .name }}"}
+	noDot := testResource("Redis", "redis", "redises", "")
+	noDot.Application.Scale = &ScaleConfig{ReplicasPath: "replicas"}
+	emptyKey := testResource("Kafka", "kafka", "kafkas", "")
+	emptyKey.Application.Scale = &ScaleConfig{ReplicasPath: ".nodes..replicas"}
+
+	cfg := &ResourceConfig{Resources: []Resource{valid, noDot, emptyKey}}
+	got, errs := Validate(cfg)
+	if len(got.Resources) != 1 || got.Resources[0].Application.Kind != "Postgres" {
+		t.Fatalf("expected only Postgres to be valid, got %+v", got.Resources)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestValidateRejectsInvalidRelease(t *testing.T) {
+	maxHistory := -1
+	valid := testResource("Postgres", "postgres", "postgreses", "")
+	valid.Release.Interval = time.Minute
+	badInterval := testResource("Redis", "redis", "redises", "")
+	badInterval.Release.Interval = -time.Minute
+	badHistory := testResource("Kafka", "kafka", "kafkas", "")
+	badHistory.Release.MaxHistory = &maxHistory
+
+	cfg := &ResourceConfig{Resources: []Resource{valid, badInterval, badHistory}}
+	got, errs := Validate(cfg)
+	if len(got.Resources) != 1 || got.Resources[0].Application.Kind != "Postgres" {
+		t.Fatalf("expected only Postgres to be valid, got %+v", got.Resources)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestRateLimitFromAnnotations(t *testing.T) {
+	if rl := rateLimit(nil); rl != nil {
+		t.Fatalf("expected no rate limit, got %+v", rl)
+	}
+	rl := rateLimit(map[string]string{"apps.cozystack.io/rate-limit-qps": "2.5"})
+	if rl == nil || rl.QPS != 2.5 || rl.Burst != 3 {
+		t.Fatalf("expected qps 2.5 and burst 3, got %+v", rl)
+	}
+
+	bad := testResource("Postgres", "postgres", "postgreses", "")
+	bad.Application.RateLimit = rateLimit(map[string]string{"apps.cozystack.io/rate-limit-qps": "fast"})
+	if _, errs := Validate(&ResourceConfig{Resources: []Resource{bad}}); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin helps writing cozypkg plugins. A plugin is an executable
+// named cozypkg-<name> on the PATH, which cozypkg runs for `cozypkg <name>`
+// with the remaining arguments; dashes in the name allow nested commands,
+// e.g. cozypkg-report-usage for `cozypkg report usage`.
+//
+// A plugin typically binds the standard flags and builds its client with
+// the same scheme and kubeconfig handling as cozypkg itself:
+//
+//	var opts plugin.Options
+//	opts.AddFlags(cmd.Flags())
+//	...
+//	c, err := opts.Client()
+package plugin
+
+import (
+	"fmt"
+	"os"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	cozyv1alpha1 "github.com/cozystack/cozystack/api/v1alpha1"
+)
+
+const (
+	// Prefix is the prefix of the executable names of plugins
+	Prefix = "cozypkg-"
+
+	// EnvCozypkg is set for plugins to the path of the cozypkg executable
+	// that runs them, so they can call back into it.
+	EnvCozypkg = "COZYPKG_BIN"
+)
+
+// NewScheme returns a scheme with the types cozypkg works with: the
+// Kubernetes built-ins, Packages and PackageSources, backups and HelmReleases.
+func NewScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(cozyv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(backupsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(helmv2.AddToScheme(scheme))
+	return scheme
+}
+
+// Options are the connection options shared by cozypkg commands.
+type Options struct {
+	// Kubeconfig is the path to the kubeconfig file. Defaults to the
+	// KUBECONFIG environment variable or ~/.kube/config.
+	Kubeconfig string
+	// Namespace is the namespace to work in. Defaults to the namespace of
+	// the current kubeconfig context.
+	Namespace string
+}
+
+// AddFlags binds the --kubeconfig and --namespace flags to o.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config or KUBECONFIG env var)")
+	flags.StringVarP(&o.Namespace, "namespace", "n", "", "Namespace to work in (defaults to the namespace of the current context)")
+}
+
+// RESTConfig returns the configuration to connect to the cluster.
+func (o *Options) RESTConfig() (*rest.Config, error) {
+	if o.Kubeconfig != "" {
+		config, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig from %s: %w", o.Kubeconfig, err)
+		}
+		return config, nil
+	}
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+// CurrentNamespace returns o.Namespace, or the namespace of the current
+// kubeconfig context if it is empty.
+func (o *Options) CurrentNamespace() (string, error) {
+	if o.Namespace != "" {
+		return o.Namespace, nil
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if o.Kubeconfig != "" {
+		rules.ExplicitPath = o.Kubeconfig
+	}
+	namespace, _, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).Namespace()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the current namespace: %w", err)
+	}
+	return namespace, nil
+}
+
+// Client returns a client using the scheme of NewScheme.
+func (o *Options) Client() (client.Client, error) {
+	config, err := o.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	k8sClient, err := client.New(config, client.Options{Scheme: NewScheme()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s client: %w", err)
+	}
+	return k8sClient, nil
+}
+
+// Cozypkg returns the path of the cozypkg executable running the plugin, or
+// "cozypkg" if the plugin was not run by cozypkg.
+func Cozypkg() string {
+	if path := os.Getenv(EnvCozypkg); path != "" {
+		return path
+	}
+	return "cozypkg"
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package application
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -26,9 +27,9 @@ import (
 	"time"
 
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 	labels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -63,6 +65,12 @@ var (
 	_ rest.Patcher         = &REST{}
 )
 
+// generateNameAttempts is how many names are tried when creating an Application with metadata.generateName
+const generateNameAttempts = 8
+
+// defaultReleaseInterval is the reconcile interval of HelmReleases whose kind does not set one
+const defaultReleaseInterval = 5 * time.Minute
+
 // Define constants for label and annotation prefixes
 const (
 	LabelPrefix      = "apps.cozystack.io-"
@@ -86,6 +94,24 @@ type REST struct {
 	singularName  string
 	releaseConfig config.ReleaseConfig
 	specSchema    *structuralschema.Structural
+	// preserveUnknownFields keeps spec fields that are not described by specSchema
+	preserveUnknownFields bool
+	// reservedKeys are dot-separated spec paths injected by the platform
+	reservedKeys []string
+	// deprecatedValues are spec values that produce a warning when set
+	deprecatedValues []config.DeprecatedValue
+	// drainer, if set, closes watches gracefully on shutdown
+	drainer *WatchDrainer
+	// connection describes where the connection details are found
+	connection *config.ConnectionConfig
+	// scale, if set, maps the scale subresource to the values
+	scale *config.ScaleConfig
+	// writes, if set, retries conflicting updates
+	writes *WriteCoordinator
+	// limiter, if set, throttles requests per namespace
+	limiter *RequestLimiter
+	// indexed lists HelmReleases from the HelmReleaseApplicationIndex
+	indexed bool
 }
 
 // NewREST creates a new REST storage for Application with specific configuration
@@ -124,10 +150,16 @@ func NewREST(c client.Client, w client.WithWatch, config *config.Resource) *REST
 			Group:   appsv1alpha1.GroupName,
 			Version: "v1alpha1",
 		}.WithKind(config.Application.Kind),
-		kindName:      config.Application.Kind,
-		singularName:  config.Application.Singular,
-		releaseConfig: config.Release,
-		specSchema:    specSchema,
+		kindName:              config.Application.Kind,
+		singularName:          config.Application.Singular,
+		releaseConfig:         config.Release,
+		specSchema:            specSchema,
+		preserveUnknownFields: config.Application.PreserveUnknownFields,
+		reservedKeys:          config.Application.ReservedKeys,
+		deprecatedValues:      config.Application.DeprecatedValues,
+		connection:            config.Application.Connection,
+		scale:                 config.Application.Scale,
+		limiter:               NewRequestLimiter(config.Application.RateLimit),
 	}
 }
 
@@ -143,44 +175,100 @@ func (r *REST) GetSingularName() string {
 
 // Create handles the creation of a new Application by converting it to a HelmRelease
 func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	return r.create(ctx, obj, createValidation, options, r.limiter)
+}
+
+// create creates the Application obj, charging the request to limiter. An
+// Update creating the Application passes a nil limiter, as it was charged.
+func (r *REST) create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions, limiter *RequestLimiter) (runtime.Object, error) {
 	// Assert the object is of type Application
 	app, ok := obj.(*appsv1alpha1.Application)
 	if !ok {
 		return nil, fmt.Errorf("expected *appsv1alpha1.Application object, got %T", obj)
 	}
 
+	if err := r.authorizeTenantNamespace(ctx, app.Namespace); err != nil {
+		return nil, err
+	}
+	if err := limiter.accept(ctx, r.kindName); err != nil {
+		return nil, err
+	}
+
 	// Validate that values don't contain reserved keys (starting with "_")
 	if err := validateNoInternalKeys(app.Spec); err != nil {
 		return nil, apierrors.NewBadRequest(err.Error())
 	}
 
-	// Convert Application to HelmRelease
-	helmRelease, err := r.ConvertApplicationToHelmRelease(app)
-	if err != nil {
-		klog.Errorf("Conversion error: %v", err)
-		return nil, fmt.Errorf("conversion error: %v", err)
+	// Validate that values don't override platform-injected configuration
+	if err := r.validateReservedKeys(app); err != nil {
+		return nil, err
 	}
 
-	// Merge system labels (from config) directly
-	helmRelease.Labels = mergeMaps(r.releaseConfig.Labels, helmRelease.Labels)
-	// Merge user labels with prefix
-	helmRelease.Labels = mergeMaps(helmRelease.Labels, addPrefixedMap(app.Labels, LabelPrefix))
-	// Add application metadata labels
-	if helmRelease.Labels == nil {
-		helmRelease.Labels = make(map[string]string)
+	// Warn about values scheduled for removal
+	r.warnDeprecatedValues(ctx, app)
+
+	// Validate annotations requesting Flux actions
+	if err := validateReconcileHints(app.Annotations); err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
 	}
-	helmRelease.Labels[ApplicationKindLabel] = r.kindName
-	helmRelease.Labels[ApplicationGroupLabel] = r.gvk.Group
-	helmRelease.Labels[ApplicationNameLabel] = app.Name
-	// Note: Annotations from config are not handled as r.releaseConfig.Annotations is undefined
 
-	klog.V(6).Infof("Creating HelmRelease %s in namespace %s", helmRelease.Name, app.Namespace)
+	// Drop fields unknown to the schema unless they must be preserved
+	if err := r.pruneUnknownFields(app); err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
 
-	// Create HelmRelease in Kubernetes
-	err = r.c.Create(ctx, helmRelease, &client.CreateOptions{Raw: options})
-	if err != nil {
-		klog.Errorf("Failed to create HelmRelease %s: %v", helmRelease.Name, err)
-		return nil, fmt.Errorf("failed to create HelmRelease: %v", err)
+	// A name is generated from metadata.generateName when metadata.name is not set
+	generateName := app.Name == "" && app.GenerateName != ""
+	if app.Name == "" && !generateName {
+		return nil, apierrors.NewBadRequest("metadata.name or metadata.generateName is required")
+	}
+
+	var helmRelease *helmv2.HelmRelease
+	for attempt := 1; ; attempt++ {
+		if generateName {
+			app.Name = names.SimpleNameGenerator.GenerateName(app.GenerateName)
+		}
+
+		// Convert Application to HelmRelease
+		var err error
+		helmRelease, err = r.ConvertApplicationToHelmRelease(app)
+		if err != nil {
+			klog.Errorf("Conversion error: %v", err)
+			return nil, fmt.Errorf("conversion error: %v", err)
+		}
+
+		// Merge system labels (from config) directly
+		helmRelease.Labels = mergeMaps(r.releaseConfig.Labels, helmRelease.Labels)
+		// Merge user labels with prefix
+		helmRelease.Labels = mergeMaps(helmRelease.Labels, addPrefixedMap(app.Labels, LabelPrefix))
+		// Add application metadata labels
+		if helmRelease.Labels == nil {
+			helmRelease.Labels = make(map[string]string)
+		}
+		helmRelease.Labels[ApplicationKindLabel] = r.kindName
+		helmRelease.Labels[ApplicationGroupLabel] = r.gvk.Group
+		helmRelease.Labels[ApplicationNameLabel] = app.Name
+		// Note: Annotations from config are not handled as r.releaseConfig.Annotations is undefined
+
+		klog.V(6).Infof("Creating HelmRelease %s in namespace %s", helmRelease.Name, app.Namespace)
+
+		// Create HelmRelease in Kubernetes
+		err = r.c.Create(ctx, helmRelease, &client.CreateOptions{Raw: options})
+		if generateName && apierrors.IsAlreadyExists(err) && attempt < generateNameAttempts {
+			klog.V(4).Infof("Generated name %s is already taken, retrying", app.Name)
+			continue
+		}
+		if err != nil {
+			klog.Errorf("Failed to create HelmRelease %s: %v", helmRelease.Name, err)
+			if generateName && apierrors.IsAlreadyExists(err) {
+				return nil, apierrors.NewAlreadyExists(r.gvr.GroupResource(), app.Name)
+			}
+			if admissionErr := r.admissionError(err, helmRelease.Name, app.Name); admissionErr != nil {
+				return nil, admissionErr
+			}
+			return nil, fmt.Errorf("failed to create HelmRelease: %v", err)
+		}
+		break
 	}
 
 	// Convert the created HelmRelease back to Application
@@ -198,18 +286,29 @@ func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 
 // Get retrieves an Application by converting the corresponding HelmRelease
 func (r *REST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	if err := r.limiter.accept(ctx, r.kindName); err != nil {
+		return nil, err
+	}
+	return r.get(ctx, name, options, r.c)
+}
+
+// get retrieves the Application name reading its HelmRelease with reader
+func (r *REST) get(ctx context.Context, name string, options *metav1.GetOptions, reader client.Reader) (runtime.Object, error) {
 	namespace, err := r.getNamespace(ctx)
 	if err != nil {
 		klog.Errorf("Failed to get namespace: %v", err)
 		return nil, err
 	}
+	if err := r.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
 
 	klog.V(6).Infof("Attempting to retrieve resource %s of type %s in namespace %s", name, r.gvr.Resource, namespace)
 
 	// Get the corresponding HelmRelease using the new prefix
 	helmReleaseName := r.releaseConfig.Prefix + name
 	helmRelease := &helmv2.HelmRelease{}
-	err = r.c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: helmReleaseName}, helmRelease, &client.GetOptions{Raw: options})
+	err = reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: helmReleaseName}, helmRelease, &client.GetOptions{Raw: options})
 	if err != nil {
 		klog.Errorf("Error retrieving HelmRelease for resource %s: %v", name, err)
 
@@ -229,6 +328,7 @@ func (r *REST) Get(ctx context.Context, name string, options *metav1.GetOptions)
 		// Return a NotFound error for the Application resource
 		return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
 	}
+	r.writes.observe(helmRelease)
 
 	// Convert HelmRelease to Application
 	convertedApp, err := r.ConvertHelmReleaseToApplication(helmRelease)
@@ -237,6 +337,18 @@ func (r *REST) Get(ctx context.Context, name string, options *metav1.GetOptions)
 		return nil, fmt.Errorf("conversion error: %v", err)
 	}
 
+	// The child objects are best-effort, they must not make the Application unreadable
+	resources, err := r.applicationResources(ctx, helmRelease)
+	if err != nil {
+		klog.Warningf("Failed to resolve resources of %s %s/%s: %v", r.kindName, namespace, name, err)
+	}
+	convertedApp.Status.Resources = resources
+	connection, err := r.applicationConnection(ctx, helmRelease, name)
+	if err != nil {
+		klog.Warningf("Failed to resolve connection of %s %s/%s: %v", r.kindName, namespace, name, err)
+	}
+	convertedApp.Status.Connection = connection
+
 	klog.V(6).Infof("Successfully retrieved and converted resource %s of kind %s", name, r.gvr.Resource)
 	return &convertedApp, nil
 }
@@ -248,6 +360,13 @@ func (r *REST) List(ctx context.Context, options *metainternalversion.ListOption
 		klog.Errorf("Failed to get namespace: %v", err)
 		return nil, err
 	}
+	if err := r.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	if err := r.limiter.accept(ctx, r.kindName); err != nil {
+		return nil, err
+	}
 
 	klog.V(6).Infof("Attempting to list HelmReleases in namespace %s with options: %v", namespace, options)
 
@@ -268,6 +387,10 @@ func (r *REST) List(ctx context.Context, options *metainternalversion.ListOption
 		klog.Errorf("Error parsing field selector: %v", err)
 		return nil, err
 	}
+	statusFilter, err := newFieldSelectorFilter(options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
 
 	// If field selector specifies namespace different from context, return empty list
 	if fieldFilter.Namespace != "" && namespace != "" && namespace != fieldFilter.Namespace {
@@ -329,10 +452,10 @@ func (r *REST) List(ctx context.Context, options *metainternalversion.ListOption
 	// List HelmReleases with label selector only
 	// Field selectors are not supported by controller-runtime cache, so we filter manually below
 	hrList := &helmv2.HelmReleaseList{}
-	err = r.c.List(ctx, hrList, &client.ListOptions{
+	err = r.listHelmReleases(ctx, hrList, &client.ListOptions{
 		Namespace:     namespace,
 		LabelSelector: helmLabelSelector,
-	})
+	}, options, fieldFilter.Name)
 	if err != nil {
 		klog.Errorf("Error listing HelmReleases: %v", err)
 		return nil, err
@@ -363,6 +486,7 @@ func (r *REST) List(ctx context.Context, options *metainternalversion.ListOption
 			klog.Errorf("Error converting HelmRelease %s to Application: %v", hr.GetName(), err)
 			continue
 		}
+		r.writes.observe(hr)
 
 		// If resourceName is set, check for match
 		if resourceName != "" && app.Name != resourceName {
@@ -381,20 +505,9 @@ func (r *REST) List(ctx context.Context, options *metainternalversion.ListOption
 			}
 		}
 
-		// Apply field.selector by name and namespace (if specified)
-		if options.FieldSelector != nil {
-			fs, err := fields.ParseSelector(options.FieldSelector.String())
-			if err != nil {
-				klog.Errorf("Invalid field selector: %v", err)
-				continue
-			}
-			fieldsSet := fields.Set{
-				"metadata.name":      app.Name,
-				"metadata.namespace": app.Namespace,
-			}
-			if !fs.Matches(fieldsSet) {
-				continue
-			}
+		// Apply field.selector, including the status fields
+		if !statusFilter.matches(&app) {
+			continue
 		}
 
 		items = append(items, app)
@@ -403,6 +516,7 @@ func (r *REST) List(ctx context.Context, options *metainternalversion.ListOption
 	// Create ApplicationList with proper kind
 	appList := r.NewList().(*appsv1alpha1.ApplicationList)
 	appList.SetResourceVersion(hrList.GetResourceVersion())
+	appList.SetContinue(hrList.GetContinue())
 	appList.Items = items
 
 	sorting.ByNamespacedName[appsv1alpha1.Application, *appsv1alpha1.Application](appList.Items)
@@ -411,10 +525,34 @@ func (r *REST) List(ctx context.Context, options *metainternalversion.ListOption
 	return appList, nil
 }
 
-// Update updates an existing Application by converting it to a HelmRelease
+// Update updates an existing Application by converting it to a HelmRelease.
+// With a WriteCoordinator, conflicts of patches are retried against the
+// current HelmRelease, read bypassing the cache. A PUT is not retried: its
+// object carries the resource version the client read, so a retry would
+// conflict again.
 func (r *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	if err := r.limiter.accept(ctx, r.kindName); err != nil {
+		return nil, false, err
+	}
+	retries := 0
+	if info, ok := request.RequestInfoFrom(ctx); r.writes != nil && (!ok || info.Verb != "update") {
+		retries = r.writes.retries
+	}
+	var reader client.Reader = r.c
+	for attempt := 0; ; attempt++ {
+		obj, created, err := r.update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, reader)
+		if !apierrors.IsConflict(err) || attempt >= retries {
+			return obj, created, err
+		}
+		klog.V(4).Infof("Retrying update of %s %s after conflict (attempt %d): %v", r.kindName, name, attempt+1, err)
+		reader = r.w
+	}
+}
+
+// update updates the Application name once, reading the existing one with reader
+func (r *REST) update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, reader client.Reader) (runtime.Object, bool, error) {
 	// Retrieve the existing Application
-	oldObj, err := r.Get(ctx, name, &metav1.GetOptions{})
+	oldObj, err := r.get(ctx, name, &metav1.GetOptions{}, reader)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			if !forceAllowCreate {
@@ -426,7 +564,7 @@ func (r *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObje
 				klog.Errorf("Failed to get updated object: %v", err)
 				return nil, false, err
 			}
-			createdObj, err := r.Create(ctx, obj, createValidation, &metav1.CreateOptions{})
+			createdObj, err := r.create(ctx, obj, createValidation, &metav1.CreateOptions{}, nil)
 			if err != nil {
 				klog.Errorf("Failed to create new Application: %v", err)
 				return nil, false, err
@@ -464,6 +602,24 @@ func (r *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObje
 		return nil, false, apierrors.NewBadRequest(err.Error())
 	}
 
+	// Validate that values don't override platform-injected configuration
+	if err := r.validateReservedKeys(app); err != nil {
+		return nil, false, err
+	}
+
+	// Warn about values scheduled for removal
+	r.warnDeprecatedValues(ctx, app)
+
+	// Validate annotations requesting Flux actions
+	if err := validateReconcileHints(app.Annotations); err != nil {
+		return nil, false, apierrors.NewBadRequest(err.Error())
+	}
+
+	// Drop fields unknown to the schema unless they must be preserved
+	if err := r.pruneUnknownFields(app); err != nil {
+		return nil, false, apierrors.NewBadRequest(err.Error())
+	}
+
 	// Convert Application to HelmRelease
 	helmRelease, err := r.ConvertApplicationToHelmRelease(app)
 	if err != nil {
@@ -480,6 +636,13 @@ func (r *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObje
 		}
 		helmRelease.SetResourceVersion(cur.GetResourceVersion())
 	}
+	// An update based on a stale resource version whose content is unchanged
+	// is applied to the current one
+	if current := oldObj.(*appsv1alpha1.Application).ResourceVersion; helmRelease.ResourceVersion != current &&
+		r.writes.unchanged(helmRelease.Namespace, helmRelease.Name, helmRelease.ResourceVersion, current) {
+		klog.V(4).Infof("Mapping resource version %s of %s %s to %s", helmRelease.ResourceVersion, r.kindName, name, current)
+		helmRelease.SetResourceVersion(current)
+	}
 
 	// Merge system labels (from config) directly
 	helmRelease.Labels = mergeMaps(r.releaseConfig.Labels, helmRelease.Labels)
@@ -493,15 +656,27 @@ func (r *REST) Update(ctx context.Context, name string, objInfo rest.UpdatedObje
 	helmRelease.Labels[ApplicationGroupLabel] = r.gvk.Group
 	helmRelease.Labels[ApplicationNameLabel] = app.Name
 	// Note: Annotations from config are not handled as r.releaseConfig.Annotations is undefined
+	// Keep the conditions reported through the status subresource
+	if err := storeConditions(helmRelease, reportedConditions(oldObj.(*appsv1alpha1.Application).Status.Conditions)); err != nil {
+		return nil, false, err
+	}
 
 	klog.V(6).Infof("Updating HelmRelease %s in namespace %s", helmRelease.Name, helmRelease.Namespace)
 
 	// Update the HelmRelease in Kubernetes
 	err = r.c.Update(ctx, helmRelease, &client.UpdateOptions{Raw: &metav1.UpdateOptions{}})
-	if err != nil {
+	if apierrors.IsConflict(err) {
+		klog.V(4).Infof("Conflict updating HelmRelease %s: %v", helmRelease.Name, err)
+		return nil, false, apierrors.NewConflict(r.gvr.GroupResource(), name,
+			fmt.Errorf("the %s has been modified since it was read; get the latest version and apply your changes again", r.kindName))
+	} else if err != nil {
 		klog.Errorf("Failed to update HelmRelease %s: %v", helmRelease.Name, err)
+		if admissionErr := r.admissionError(err, helmRelease.Name, name); admissionErr != nil {
+			return nil, false, admissionErr
+		}
 		return nil, false, fmt.Errorf("failed to update HelmRelease: %v", err)
 	}
+	r.writes.observe(helmRelease)
 
 	// Convert the updated HelmRelease back to Application
 	convertedApp, err := r.ConvertHelmReleaseToApplication(helmRelease)
@@ -524,6 +699,13 @@ func (r *REST) Delete(ctx context.Context, name string, deleteValidation rest.Va
 		klog.Errorf("Failed to get namespace: %v", err)
 		return nil, false, err
 	}
+	if err := r.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, false, err
+	}
+
+	if err := r.limiter.accept(ctx, r.kindName); err != nil {
+		return nil, false, err
+	}
 
 	klog.V(6).Infof("Attempting to delete HelmRelease %s in namespace %s", name, namespace)
 
@@ -571,6 +753,13 @@ func (r *REST) Watch(ctx context.Context, options *metainternalversion.ListOptio
 		klog.Errorf("Failed to get namespace: %v", err)
 		return nil, err
 	}
+	if err := r.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	if err := r.limiter.accept(ctx, r.kindName); err != nil {
+		return nil, err
+	}
 
 	klog.V(6).Infof("Setting up watch for HelmReleases in namespace %s with options: %v", namespace, options)
 
@@ -591,6 +780,10 @@ func (r *REST) Watch(ctx context.Context, options *metainternalversion.ListOptio
 		klog.Errorf("Error parsing field selector: %v", err)
 		return nil, err
 	}
+	statusFilter, err := newFieldSelectorFilter(options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
 
 	// Convert Application name to HelmRelease name for manual filtering
 	var filterByName string
@@ -639,11 +832,44 @@ func (r *REST) Watch(ctx context.Context, options *metainternalversion.ListOptio
 	// Start watch on HelmRelease with label selector only
 	// Field selectors are not supported by controller-runtime cache
 	// See: https://github.com/kubernetes-sigs/controller-runtime/issues/612
-	hrList := &helmv2.HelmReleaseList{}
-	helmWatcher, err := r.w.Watch(ctx, hrList, &client.ListOptions{
-		Namespace:     namespace,
-		LabelSelector: helmLabelSelector,
-	})
+	// Bookmarks are always requested, so that a closed watch is resumed from
+	// a recent resource version
+	openWatch := func(resourceVersion string) (watch.Interface, error) {
+		return r.w.Watch(ctx, &helmv2.HelmReleaseList{}, &client.ListOptions{
+			Namespace:     namespace,
+			LabelSelector: helmLabelSelector,
+			Raw: &metav1.ListOptions{
+				ResourceVersion:     resourceVersion,
+				AllowWatchBookmarks: true,
+			},
+		})
+	}
+
+	// Resource version of the last event of the HelmRelease watch, which a
+	// closed watch is resumed from
+	watchResourceVersion := options.ResourceVersion
+	// A watch without a resource version starts with the current HelmReleases.
+	// They are listed here instead of being replayed by the HelmRelease watch,
+	// so that a watch closed before its first event or bookmark is resumed
+	// from the resource version of the list rather than replaying them again.
+	var initialEvents []watch.Event
+	listed := watchResourceVersion == "" || watchResourceVersion == "0"
+	if listed {
+		hrList := &helmv2.HelmReleaseList{}
+		if err := r.w.List(ctx, hrList, &client.ListOptions{
+			Namespace:     namespace,
+			LabelSelector: helmLabelSelector,
+			Raw:           &metav1.ListOptions{ResourceVersion: watchResourceVersion},
+		}); err != nil {
+			klog.Errorf("Error listing HelmReleases for watch: %v", err)
+			return nil, err
+		}
+		watchResourceVersion = hrList.ResourceVersion
+		for i := range hrList.Items {
+			initialEvents = append(initialEvents, watch.Event{Type: watch.Added, Object: &hrList.Items[i]})
+		}
+	}
+	helmWatcher, err := openWatch(watchResourceVersion)
 	if err != nil {
 		klog.Errorf("Error setting up watch for HelmReleases: %v", err)
 		return nil, err
@@ -653,85 +879,176 @@ func (r *REST) Watch(ctx context.Context, options *metainternalversion.ListOptio
 	customW := &customWatcher{
 		resultChan: make(chan watch.Event),
 		stopChan:   make(chan struct{}),
+		drainChan:  make(chan struct{}),
 		underlying: helmWatcher,
 	}
+	if r.drainer != nil {
+		if err := r.drainer.register(customW); err != nil {
+			helmWatcher.Stop()
+			return nil, err
+		}
+	}
 
-	go func() {
-		defer close(customW.resultChan)
-		defer customW.underlying.Stop()
-		for {
-			select {
-			case event, ok := <-customW.underlying.ResultChan():
-				if !ok {
-					// The watcher has been closed, attempt to re-establish the watch
-					klog.Warning("HelmRelease watcher closed, attempting to re-establish")
-					// Implement retry logic or exit based on your requirements
-					return
-				}
+	// Resource version of the last delivered event, reported in the bookmark
+	// sent when the watch is drained
+	lastResourceVersion := options.ResourceVersion
+	if lastResourceVersion == "0" {
+		lastResourceVersion = ""
+	}
 
-				// Check if the object is a *v1.Status
-				if status, ok := event.Object.(*metav1.Status); ok {
-					klog.V(4).Infof("Received Status object in HelmRelease watch: %v", status.Message)
-					continue // Skip processing this event
-				}
+	// replaying is set while the listed HelmReleases are sent, which are not
+	// ordered by resource version
+	replaying := listed
 
-				// Proceed with processing HelmRelease objects
-				hr, ok := event.Object.(*helmv2.HelmRelease)
-				if !ok {
-					klog.V(4).Infof("Expected HelmRelease object, got %T", event.Object)
-					continue
-				}
+	// deliver converts a HelmRelease event into an Application event and sends
+	// it to the client unless it is filtered out. It returns false once the
+	// watch ends.
+	deliver := func(event watch.Event) bool {
+		hr, ok := event.Object.(*helmv2.HelmRelease)
+		if !ok {
+			klog.V(4).Infof("Expected HelmRelease object, got %T", event.Object)
+			return true
+		}
 
-				// Apply manual field selector filtering (metadata.name and metadata.namespace)
-				// controller-runtime cache doesn't support field selectors
-				// See: https://github.com/kubernetes-sigs/controller-runtime/issues/612
-				if filterByName != "" && hr.Name != filterByName {
-					continue
-				}
-				if !fieldFilter.MatchesNamespace(hr.Namespace) {
-					continue
-				}
+		// Apply manual field selector filtering (metadata.name and metadata.namespace)
+		// controller-runtime cache doesn't support field selectors
+		// See: https://github.com/kubernetes-sigs/controller-runtime/issues/612
+		if filterByName != "" && hr.Name != filterByName {
+			return true
+		}
+		if !fieldFilter.MatchesNamespace(hr.Namespace) {
+			return true
+		}
 
-				// Note: All HelmReleases already match the required labels due to server-side label selector filtering
-				// Convert HelmRelease to Application
-				app, err := r.ConvertHelmReleaseToApplication(hr)
-				if err != nil {
-					klog.Errorf("Error converting HelmRelease to Application: %v", err)
-					continue
-				}
+		// Note: All HelmReleases already match the required labels due to server-side label selector filtering
+		// Convert HelmRelease to Application
+		app, err := r.ConvertHelmReleaseToApplication(hr)
+		if err != nil {
+			klog.Errorf("Error converting HelmRelease to Application: %v", err)
+			return true
+		}
+
+		// Apply field.selector by name if specified
+		if resourceName != "" && app.Name != resourceName {
+			return true
+		}
+
+		// Apply label.selector
+		if options.LabelSelector != nil {
+			sel, err := labels.Parse(options.LabelSelector.String())
+			if err != nil {
+				klog.Errorf("Invalid label selector: %v", err)
+				return true
+			}
+			if !sel.Matches(labels.Set(app.Labels)) {
+				return true
+			}
+		}
+
+		// Apply field.selector, including the status fields
+		eventType, ok := statusFilter.filterEvent(event.Type, &app)
+		if !ok {
+			return true
+		}
+
+		// Create watch event with Application object
+		appEvent := watch.Event{
+			Type:   eventType,
+			Object: &app,
+		}
+
+		// Send event to custom watcher
+		select {
+		case customW.resultChan <- appEvent:
+			if !replaying {
+				lastResourceVersion = app.ResourceVersion
+			}
+			return true
+		case <-customW.drainChan:
+			// The event is not delivered, the client gets it again
+			// when resuming from the bookmark. The listed HelmReleases
+			// have no resource version to resume from, a watch drained
+			// while they are sent is closed without a bookmark so that
+			// the client relists.
+			if !replaying {
+				customW.sendBookmark(ctx, r.gvk, options.AllowWatchBookmarks, lastResourceVersion)
+			}
+			return false
+		case <-customW.stopChan:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
 
-				// Apply field.selector by name if specified
-				if resourceName != "" && app.Name != resourceName {
+	go func() {
+		if r.drainer != nil {
+			defer r.drainer.release(customW)
+		}
+		defer close(customW.resultChan)
+		defer func() { customW.watcher().Stop() }()
+		for _, event := range initialEvents {
+			if !deliver(event) {
+				return
+			}
+		}
+		if replaying {
+			// The client has the state as of the list
+			replaying = false
+			lastResourceVersion = watchResourceVersion
+		}
+		for {
+			select {
+			case event, ok := <-customW.watcher().ResultChan():
+				if !ok {
+					// The watcher has been closed, re-establish the watch
+					// from the last event it delivered
+					klog.V(4).Infof("HelmRelease watcher closed, re-establishing at resource version %q", watchResourceVersion)
+					if !customW.resume(ctx, openWatch, watchResourceVersion) {
+						select {
+						case <-customW.drainChan:
+							customW.sendBookmark(ctx, r.gvk, options.AllowWatchBookmarks, lastResourceVersion)
+						default:
+						}
+						return
+					}
 					continue
 				}
 
-				// Apply label.selector
-				if options.LabelSelector != nil {
-					sel, err := labels.Parse(options.LabelSelector.String())
-					if err != nil {
-						klog.Errorf("Invalid label selector: %v", err)
-						continue
+				switch event.Type {
+				case watch.Error:
+					// An expired resource version ends the watch with 410 Gone,
+					// so that the client relists. Other errors close the
+					// HelmRelease watch, which is then resumed.
+					if err := apierrors.FromObject(event.Object); isExpired(err) {
+						customW.sendError(ctx, err)
+						return
 					}
-					if !sel.Matches(labels.Set(app.Labels)) {
-						continue
+					klog.V(4).Infof("Received error in HelmRelease watch: %v", apierrors.FromObject(event.Object))
+					continue
+				case watch.Bookmark:
+					// All events up to the bookmark have been delivered or
+					// filtered out
+					if obj, err := apimeta.Accessor(event.Object); err == nil && obj.GetResourceVersion() != "" {
+						watchResourceVersion = obj.GetResourceVersion()
+						lastResourceVersion = watchResourceVersion
+						if options.AllowWatchBookmarks && !customW.send(ctx, bookmarkEvent(r.gvk, lastResourceVersion)) {
+							return
+						}
 					}
+					continue
 				}
 
-				// Create watch event with Application object
-				appEvent := watch.Event{
-					Type:   event.Type,
-					Object: &app,
+				if hr, ok := event.Object.(*helmv2.HelmRelease); ok {
+					watchResourceVersion = hr.ResourceVersion
 				}
-
-				// Send event to custom watcher
-				select {
-				case customW.resultChan <- appEvent:
-				case <-customW.stopChan:
-					return
-				case <-ctx.Done():
+				if !deliver(event) {
 					return
 				}
 
+			case <-customW.drainChan:
+				customW.sendBookmark(ctx, r.gvk, options.AllowWatchBookmarks, lastResourceVersion)
+				return
 			case <-customW.stopChan:
 				return
 			case <-ctx.Done():
@@ -749,13 +1066,22 @@ type customWatcher struct {
 	resultChan chan watch.Event
 	stopChan   chan struct{}
 	stopOnce   sync.Once
+	drainChan  chan struct{}
+	drainOnce  sync.Once
+
+	// mu guards underlying, which is replaced when the watch is resumed
+	mu         sync.Mutex
 	underlying watch.Interface
+	stopped    bool
 }
 
 // Stop terminates the watch
 func (cw *customWatcher) Stop() {
 	cw.stopOnce.Do(func() {
 		close(cw.stopChan)
+		cw.mu.Lock()
+		defer cw.mu.Unlock()
+		cw.stopped = true
 		if cw.underlying != nil {
 			cw.underlying.Stop()
 		}
@@ -876,8 +1202,11 @@ func filterInternalKeys(values *apiextv1.JSON) *apiextv1.JSON {
 	if values == nil || len(values.Raw) == 0 {
 		return values
 	}
+	// Decode numbers as json.Number so that large integers survive re-encoding
 	var data map[string]interface{}
-	if err := json.Unmarshal(values.Raw, &data); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(values.Raw))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
 		return values
 	}
 	for key := range data {
@@ -921,6 +1250,7 @@ func (r *REST) convertHelmReleaseToApplication(hr *helmv2.HelmRelease) (appsv1al
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:              strings.TrimPrefix(hr.Name, r.releaseConfig.Prefix),
+			GenerateName:      strings.TrimPrefix(hr.GenerateName, r.releaseConfig.Prefix),
 			Namespace:         hr.Namespace,
 			UID:               hr.GetUID(),
 			ResourceVersion:   hr.GetResourceVersion(),
@@ -937,7 +1267,7 @@ func (r *REST) convertHelmReleaseToApplication(hr *helmv2.HelmRelease) (appsv1al
 
 	var conditions []metav1.Condition
 	for _, hrCondition := range hr.GetConditions() {
-		if hrCondition.Type == "Ready" || hrCondition.Type == "Released" {
+		if hrCondition.Type == "Ready" || hrCondition.Type == "Released" || hrCondition.Type == "Remediated" {
 			conditions = append(conditions, metav1.Condition{
 				LastTransitionTime: hrCondition.LastTransitionTime,
 				Reason:             hrCondition.Reason,
@@ -947,6 +1277,10 @@ func (r *REST) convertHelmReleaseToApplication(hr *helmv2.HelmRelease) (appsv1al
 			})
 		}
 	}
+	if drift, ok := driftCondition(hr); ok {
+		conditions = append(conditions, drift)
+	}
+	conditions = append(conditions, storedConditions(hr)...)
 	app.SetConditions(conditions)
 
 	// Add namespace field for Tenant applications
@@ -966,6 +1300,7 @@ func (r *REST) convertApplicationToHelmRelease(app *appsv1alpha1.Application) (*
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            r.releaseConfig.Prefix + app.Name,
+			GenerateName:    generateNameWithPrefix(r.releaseConfig.Prefix, app.GenerateName),
 			Namespace:       app.Namespace,
 			Labels:          addPrefixedMap(app.Labels, LabelPrefix),
 			Annotations:     addPrefixedMap(app.Annotations, AnnotationPrefix),
@@ -985,17 +1320,7 @@ func (r *REST) convertApplicationToHelmRelease(app *appsv1alpha1.Application) (*
 					},
 				},
 			},
-			Interval: metav1.Duration{Duration: 5 * time.Minute},
-			Install: &helmv2.Install{
-				Remediation: &helmv2.InstallRemediation{
-					Retries: -1,
-				},
-			},
-			Upgrade: &helmv2.Upgrade{
-				Remediation: &helmv2.UpgradeRemediation{
-					Retries: -1,
-				},
-			},
+			Interval: metav1.Duration{Duration: r.releaseInterval()},
 			ValuesFrom: []helmv2.ValuesReference{
 				{
 					Kind: "Secret",
@@ -1006,9 +1331,36 @@ func (r *REST) convertApplicationToHelmRelease(app *appsv1alpha1.Application) (*
 		},
 	}
 
+	if r.releaseConfig.Timeout > 0 {
+		helmRelease.Spec.Timeout = &metav1.Duration{Duration: r.releaseConfig.Timeout}
+	}
+	helmRelease.Spec.MaxHistory = r.releaseConfig.MaxHistory
+	applyRemediation(r.releaseConfig.Remediation, helmRelease)
+
+	// Translate Application annotations into Flux reconcile requests
+	if err := applyReconcileHints(app.Annotations, helmRelease); err != nil {
+		return nil, err
+	}
+
 	return helmRelease, nil
 }
 
+// releaseInterval returns the reconcile interval of the HelmReleases of the kind
+func (r *REST) releaseInterval() time.Duration {
+	if r.releaseConfig.Interval > 0 {
+		return r.releaseConfig.Interval
+	}
+	return defaultReleaseInterval
+}
+
+// generateNameWithPrefix maps an Application generateName to the HelmRelease generateName
+func generateNameWithPrefix(prefix, generateName string) string {
+	if generateName == "" {
+		return ""
+	}
+	return prefix + generateName
+}
+
 // ConvertToTable implements the TableConvertor interface for displaying resources in a table format
 func (r *REST) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
 	klog.V(6).Infof("ConvertToTable: received object of type %T", object)
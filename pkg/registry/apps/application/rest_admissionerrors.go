@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// admissionError translates the rejection of a HelmRelease write, by CRD
+// validation or an admission webhook, into an error about the Application
+// name. The causes are mapped back to the Application fields they were
+// generated from. Causes about platform-managed HelmRelease fields are not
+// actionable for the user, so an Invalid error with only such causes becomes
+// an internal error. It returns nil for any other error.
+func (r *REST) admissionError(err error, releaseName, name string) error {
+	var apiStatus apierrors.APIStatus
+	if !errors.As(err, &apiStatus) {
+		return nil
+	}
+	rejected := apiStatus.Status()
+	switch rejected.Reason {
+	case metav1.StatusReasonInvalid, metav1.StatusReasonBadRequest, metav1.StatusReasonForbidden:
+	default:
+		return nil
+	}
+
+	status := rejected
+	status.Message = strings.NewReplacer(
+		fmt.Sprintf("%s %q", helmv2.GroupVersion.WithKind("HelmRelease").GroupKind(), releaseName),
+		fmt.Sprintf("%s %q", r.gvk.GroupKind(), name),
+		fmt.Sprintf("%s %q", helmv2.GroupVersion.WithResource("helmreleases").GroupResource(), releaseName),
+		fmt.Sprintf("%s %q", r.gvr.GroupResource(), name),
+	).Replace(rejected.Message)
+	status.Details = &metav1.StatusDetails{Group: r.gvr.Group, Kind: r.gvr.Resource, Name: name}
+	if rejected.Reason == metav1.StatusReasonInvalid {
+		status.Details.Kind = r.kindName
+	}
+
+	if rejected.Details == nil {
+		return &apierrors.StatusError{ErrStatus: status}
+	}
+	mapped := 0
+	for _, cause := range rejected.Details.Causes {
+		if path, ok := applicationFieldPath(cause.Field); ok {
+			cause.Message = strings.ReplaceAll(cause.Message, cause.Field, path)
+			status.Message = strings.ReplaceAll(status.Message, cause.Field, path)
+			cause.Field = path
+			mapped++
+		} else if cause.Field != "" {
+			cause.Message = fmt.Sprintf("platform-managed HelmRelease field %s: %s", cause.Field, cause.Message)
+			cause.Field = ""
+		}
+		status.Details.Causes = append(status.Details.Causes, cause)
+	}
+	if rejected.Reason == metav1.StatusReasonInvalid && mapped == 0 {
+		return apierrors.NewInternalError(fmt.Errorf("the HelmRelease generated for %s %s was rejected: %s", r.kindName, name, rejected.Message))
+	}
+	return &apierrors.StatusError{ErrStatus: status}
+}
+
+// applicationFieldPath returns the Application field path a HelmRelease field
+// path is generated from, and false if the field is platform-managed
+func applicationFieldPath(releaseField string) (string, bool) {
+	switch {
+	case releaseField == "spec.values":
+		return "spec", true
+	case strings.HasPrefix(releaseField, "spec.values.") || strings.HasPrefix(releaseField, "spec.values["):
+		return "spec" + strings.TrimPrefix(releaseField, "spec.values"), true
+	case releaseField == "metadata.name" || releaseField == "metadata.generateName":
+		return releaseField, true
+	case strings.HasPrefix(releaseField, "metadata.labels"):
+		return strings.Replace(releaseField, "["+LabelPrefix, "[", 1), true
+	case strings.HasPrefix(releaseField, "metadata.annotations"):
+		return strings.Replace(releaseField, "["+AnnotationPrefix, "[", 1), true
+	}
+	return "", false
+}
@@ -0,0 +1,81 @@
+package application
+
+import (
+	"errors"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+var _ = Describe("admission errors", func() {
+	r := &REST{
+		gvr:      schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+		gvk:      schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+		kindName: "Test",
+	}
+	releaseKind := helmv2.GroupVersion.WithKind("HelmRelease").GroupKind()
+
+	It("maps values causes to Application spec fields", func() {
+		rejected := apierrors.NewInvalid(releaseKind, "test-db", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "values", "replicas"), "two", "spec.values.replicas in body must be of type integer"),
+		})
+		err := r.admissionError(rejected, "test-db", "db")
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(HavePrefix(`Test.apps.cozystack.io "db" is invalid`))
+		Expect(err.Error()).NotTo(ContainSubstring("spec.values"))
+
+		status := err.(apierrors.APIStatus).Status()
+		Expect(status.Details.Name).To(Equal("db"))
+		Expect(status.Details.Kind).To(Equal("Test"))
+		Expect(status.Details.Causes).To(HaveLen(1))
+		Expect(status.Details.Causes[0].Field).To(Equal("spec.replicas"))
+		Expect(status.Details.Causes[0].Message).To(ContainSubstring("spec.replicas in body must be of type integer"))
+	})
+
+	It("strips the label prefix from label keys", func() {
+		rejected := apierrors.NewInvalid(releaseKind, "test-db", field.ErrorList{
+			field.Invalid(field.NewPath("metadata", "labels").Key(LabelPrefix+"team"), "a b", "must be a valid label value"),
+		})
+		status := r.admissionError(rejected, "test-db", "db").(apierrors.APIStatus).Status()
+		Expect(status.Details.Causes[0].Field).To(Equal("metadata.labels[team]"))
+	})
+
+	It("turns rejections of platform-managed fields only into internal errors", func() {
+		rejected := apierrors.NewInvalid(releaseKind, "test-db", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "interval"), "x", "must be a duration"),
+		})
+		err := r.admissionError(rejected, "test-db", "db")
+		Expect(apierrors.IsInternalError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.interval"))
+	})
+
+	It("keeps platform-managed causes next to mapped ones", func() {
+		rejected := apierrors.NewInvalid(releaseKind, "test-db", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "values", "size"), "1", "too small"),
+			field.Invalid(field.NewPath("spec", "interval"), "x", "must be a duration"),
+		})
+		status := r.admissionError(rejected, "test-db", "db").(apierrors.APIStatus).Status()
+		Expect(status.Details.Causes).To(HaveLen(2))
+		Expect(status.Details.Causes[1].Field).To(BeEmpty())
+		Expect(status.Details.Causes[1].Message).To(ContainSubstring("platform-managed HelmRelease field spec.interval"))
+	})
+
+	It("reports webhook denials against the Application", func() {
+		rejected := apierrors.NewForbidden(helmv2.GroupVersion.WithResource("helmreleases").GroupResource(), "test-db",
+			errors.New(`admission webhook "policy.example.org" denied the request: values not allowed`))
+		err := r.admissionError(rejected, "test-db", "db")
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(HavePrefix(`tests.apps.cozystack.io "db" is forbidden: admission webhook`))
+	})
+
+	It("ignores other errors", func() {
+		Expect(r.admissionError(errors.New("connection refused"), "test-db", "db")).To(BeNil())
+		Expect(r.admissionError(apierrors.NewServiceUnavailable("down"), "test-db", "db")).To(BeNil())
+	})
+})
@@ -0,0 +1,403 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// Subresources exposing the backups.cozystack.io runs of an Application
+const (
+	// SubresourceBackups lists and starts the BackupJobs of an Application
+	SubresourceBackups = "backups"
+	// SubresourceRestores lists and starts the RestoreJobs of an Application
+	SubresourceRestores = "restores"
+)
+
+var (
+	_ rest.Getter       = &BackupsREST{}
+	_ rest.NamedCreater = &BackupsREST{}
+	_ rest.Getter       = &RestoresREST{}
+	_ rest.NamedCreater = &RestoresREST{}
+)
+
+// applicationRefFields returns the field selector matching the backups.cozystack.io
+// objects whose spec.applicationRef refers to the Application name
+func (r *REST) applicationRefFields(name string) client.MatchingFields {
+	return client.MatchingFields{
+		"spec.applicationRef.apiGroup": appsv1alpha1.GroupName,
+		"spec.applicationRef.kind":     r.kindName,
+		"spec.applicationRef.name":     name,
+	}
+}
+
+// applicationRef returns the reference of backups.cozystack.io objects to the
+// Application name
+func (r *REST) applicationRef(name string) corev1.TypedLocalObjectReference {
+	group := appsv1alpha1.GroupName
+	return corev1.TypedLocalObjectReference{APIGroup: &group, Kind: r.kindName, Name: name}
+}
+
+// getApplicationNamespace returns the namespace of the request once the user is
+// authorized for it and the Application name exists in it
+func (r *REST) getApplicationNamespace(ctx context.Context, name string) (string, error) {
+	namespace, err := r.getNamespace(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := r.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return "", err
+	}
+	if _, err := r.getHelmRelease(ctx, namespace, name); err != nil {
+		return "", err
+	}
+	return namespace, nil
+}
+
+// BackupsREST implements the backups subresource. A GET returns the backup runs
+// of the Application, newest first; a POST of an ApplicationBackup starts one.
+// The runs are BackupJobs, read directly so that the API server does not cache
+// them and keeps working when the backups API is not installed.
+type BackupsREST struct {
+	app *REST
+}
+
+// New creates a new instance of ApplicationBackup
+func (r *BackupsREST) New() runtime.Object {
+	return &appsv1alpha1.ApplicationBackup{}
+}
+
+// Destroy releases resources associated with BackupsREST
+func (r *BackupsREST) Destroy() {}
+
+// GroupVersionKind returns the GroupVersionKind of ApplicationBackup
+func (r *BackupsREST) GroupVersionKind(gv schema.GroupVersion) schema.GroupVersionKind {
+	return gv.WithKind("ApplicationBackup")
+}
+
+// Get lists the backup runs of the Application name
+func (r *BackupsREST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	namespace, err := r.app.getApplicationNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &appsv1alpha1.ApplicationBackupList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ApplicationBackupList",
+		},
+		Items: []appsv1alpha1.ApplicationBackup{},
+	}
+	var jobs backupsv1alpha1.BackupJobList
+	if err := r.app.w.List(ctx, &jobs, client.InNamespace(namespace), r.app.applicationRefFields(name)); meta.IsNoMatchError(err) {
+		return list, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list BackupJobs: %w", err)
+	}
+	list.ResourceVersion = jobs.ResourceVersion
+
+	for i := range jobs.Items {
+		list.Items = append(list.Items, applicationBackup(&jobs.Items[i]))
+	}
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return list.Items[j].CreationTimestamp.Before(&list.Items[i].CreationTimestamp)
+	})
+	return list, nil
+}
+
+// Create starts a backup run of the Application name
+func (r *BackupsREST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	backup, ok := obj.(*appsv1alpha1.ApplicationBackup)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected ApplicationBackup, got %T", obj))
+	}
+	if backup.Spec.StrategyRef.Kind == "" || backup.Spec.StrategyRef.Name == "" {
+		return nil, apierrors.NewBadRequest("spec.strategyRef must set kind and name")
+	}
+	if backup.Spec.Plan != "" {
+		return nil, apierrors.NewBadRequest("spec.plan cannot be set")
+	}
+	namespace, err := r.app.getApplicationNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.app.limiter.accept(ctx, r.app.kindName); err != nil {
+		return nil, err
+	}
+
+	job := &backupsv1alpha1.BackupJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    namespace,
+			Name:         backup.Name,
+			GenerateName: backup.GenerateName,
+			Labels:       backup.Labels,
+			Annotations:  backup.Annotations,
+		},
+		Spec: backupsv1alpha1.BackupJobSpec{
+			ApplicationRef: r.app.applicationRef(name),
+			StrategyRef:    typedLocalObjectReference(backup.Spec.StrategyRef),
+		},
+	}
+	if backup.Spec.StorageRef != nil {
+		storageRef := typedLocalObjectReference(*backup.Spec.StorageRef)
+		job.Spec.StorageRef = &storageRef
+	}
+	if job.Name == "" && job.GenerateName == "" {
+		job.GenerateName = name + "-"
+	}
+	if err := r.app.w.Create(ctx, job, &client.CreateOptions{Raw: &metav1.CreateOptions{DryRun: options.DryRun}}); err != nil {
+		klog.Errorf("Failed to create BackupJob for %s %s/%s: %v", r.app.kindName, namespace, name, err)
+		return nil, err
+	}
+	klog.V(4).Infof("Started backup %s of %s %s/%s", job.Name, r.app.kindName, namespace, name)
+
+	created := applicationBackup(job)
+	return &created, nil
+}
+
+// applicationBackup describes j as a backup run of its Application
+func applicationBackup(j *backupsv1alpha1.BackupJob) appsv1alpha1.ApplicationBackup {
+	b := appsv1alpha1.ApplicationBackup{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ApplicationBackup",
+		},
+		ObjectMeta: *j.ObjectMeta.DeepCopy(),
+		Spec: appsv1alpha1.ApplicationBackupSpec{
+			StrategyRef: backupObjectReference(j.Spec.StrategyRef),
+		},
+		Status: appsv1alpha1.ApplicationBackupStatus{
+			Phase:       string(j.Status.Phase),
+			StartedAt:   j.Status.StartedAt,
+			CompletedAt: j.Status.CompletedAt,
+			Message:     j.Status.Message,
+		},
+	}
+	b.ManagedFields = nil
+	if j.Spec.PlanRef != nil {
+		b.Spec.Plan = j.Spec.PlanRef.Name
+	}
+	storageRef := j.Spec.StorageRef
+	if storageRef == nil {
+		storageRef = j.Status.StorageRef
+	}
+	if storageRef != nil {
+		ref := backupObjectReference(*storageRef)
+		b.Spec.StorageRef = &ref
+	}
+	if j.Status.BackupRef != nil {
+		b.Status.Backup = j.Status.BackupRef.Name
+	}
+	return b
+}
+
+// backupObjectReference converts a reference of the backups API to the apps API
+func backupObjectReference(ref corev1.TypedLocalObjectReference) appsv1alpha1.BackupObjectReference {
+	out := appsv1alpha1.BackupObjectReference{Kind: ref.Kind, Name: ref.Name}
+	if ref.APIGroup != nil {
+		out.APIGroup = *ref.APIGroup
+	}
+	return out
+}
+
+// typedLocalObjectReference converts a reference of the apps API to the backups API
+func typedLocalObjectReference(ref appsv1alpha1.BackupObjectReference) corev1.TypedLocalObjectReference {
+	out := corev1.TypedLocalObjectReference{Kind: ref.Kind, Name: ref.Name}
+	if ref.APIGroup != "" {
+		group := ref.APIGroup
+		out.APIGroup = &group
+	}
+	return out
+}
+
+// RestoresREST implements the restores subresource. A GET returns the restore
+// runs of the Application, newest first: the RestoreJobs restoring one of its
+// Backups or targeting it. A POST of an ApplicationRestore restores one of the
+// Backups of the Application into it. Restores into other Applications or
+// namespaces are not offered here, as the RestoreJob is created by the API
+// server and the RestoreJob webhook would authorize the API server instead of
+// the user; they are created as RestoreJobs directly.
+type RestoresREST struct {
+	app *REST
+}
+
+// New creates a new instance of ApplicationRestore
+func (r *RestoresREST) New() runtime.Object {
+	return &appsv1alpha1.ApplicationRestore{}
+}
+
+// Destroy releases resources associated with RestoresREST
+func (r *RestoresREST) Destroy() {}
+
+// GroupVersionKind returns the GroupVersionKind of ApplicationRestore
+func (r *RestoresREST) GroupVersionKind(gv schema.GroupVersion) schema.GroupVersionKind {
+	return gv.WithKind("ApplicationRestore")
+}
+
+// Get lists the restore runs of the Application name
+func (r *RestoresREST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	namespace, err := r.app.getApplicationNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &appsv1alpha1.ApplicationRestoreList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ApplicationRestoreList",
+		},
+		Items: []appsv1alpha1.ApplicationRestore{},
+	}
+	var backups backupsv1alpha1.BackupList
+	if err := r.app.w.List(ctx, &backups, client.InNamespace(namespace), r.app.applicationRefFields(name)); meta.IsNoMatchError(err) {
+		return list, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list Backups: %w", err)
+	}
+	owned := make(map[string]bool, len(backups.Items))
+	for _, b := range backups.Items {
+		owned[b.Name] = true
+	}
+	var jobs backupsv1alpha1.RestoreJobList
+	if err := r.app.w.List(ctx, &jobs, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list RestoreJobs: %w", err)
+	}
+	list.ResourceVersion = jobs.ResourceVersion
+
+	target := r.app.applicationRef(name)
+	for i := range jobs.Items {
+		j := &jobs.Items[i]
+		if !restoresApplication(j, namespace, owned, target) {
+			continue
+		}
+		list.Items = append(list.Items, applicationRestore(j))
+	}
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return list.Items[j].CreationTimestamp.Before(&list.Items[i].CreationTimestamp)
+	})
+	return list, nil
+}
+
+// Create restores the Application name from one of its Backups
+func (r *RestoresREST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	restore, ok := obj.(*appsv1alpha1.ApplicationRestore)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected ApplicationRestore, got %T", obj))
+	}
+	if restore.Spec.Backup == "" {
+		return nil, apierrors.NewBadRequest("spec.backup must be set")
+	}
+	namespace, err := r.app.getApplicationNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.app.limiter.accept(ctx, r.app.kindName); err != nil {
+		return nil, err
+	}
+
+	backup := &backupsv1alpha1.Backup{}
+	err = r.app.w.Get(ctx, client.ObjectKey{Namespace: namespace, Name: restore.Spec.Backup}, backup)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("backup %q not found", restore.Spec.Backup))
+	} else if err != nil {
+		return nil, err
+	}
+	ref := backup.Spec.ApplicationRef
+	if ref.APIGroup == nil || *ref.APIGroup != appsv1alpha1.GroupName || ref.Kind != r.app.kindName || ref.Name != name {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("backup %q is not a backup of %s %s", backup.Name, r.app.kindName, name))
+	}
+	if reason := notRestorableReason(backup); reason != "" {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("backup %q cannot be restored: %s", backup.Name, reason))
+	}
+
+	target := r.app.applicationRef(name)
+	job := &backupsv1alpha1.RestoreJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    namespace,
+			Name:         restore.Name,
+			GenerateName: restore.GenerateName,
+			Labels:       restore.Labels,
+			Annotations:  restore.Annotations,
+		},
+		Spec: backupsv1alpha1.RestoreJobSpec{
+			BackupRef:            corev1.LocalObjectReference{Name: backup.Name},
+			TargetApplicationRef: &target,
+		},
+	}
+	if job.Name == "" && job.GenerateName == "" {
+		job.GenerateName = name + "-"
+	}
+	if err := r.app.w.Create(ctx, job, &client.CreateOptions{Raw: &metav1.CreateOptions{DryRun: options.DryRun}}); err != nil {
+		klog.Errorf("Failed to create RestoreJob for %s %s/%s: %v", r.app.kindName, namespace, name, err)
+		return nil, err
+	}
+	klog.V(4).Infof("Started restore %s of %s %s/%s from %s", job.Name, r.app.kindName, namespace, name, backup.Name)
+
+	created := applicationRestore(job)
+	return &created, nil
+}
+
+// restoresApplication reports whether j, in namespace, restores one of the
+// Backups in owned or restores into target
+func restoresApplication(j *backupsv1alpha1.RestoreJob, namespace string, owned map[string]bool, target corev1.TypedLocalObjectReference) bool {
+	if ref := j.Spec.TargetApplicationRef; ref != nil {
+		return ref.APIGroup != nil && target.APIGroup != nil && *ref.APIGroup == *target.APIGroup &&
+			ref.Kind == target.Kind && ref.Name == target.Name
+	}
+	if j.Spec.BackupNamespace != "" && j.Spec.BackupNamespace != namespace {
+		return false
+	}
+	return owned[j.Spec.BackupRef.Name]
+}
+
+// applicationRestore describes j as a restore run of its Application
+func applicationRestore(j *backupsv1alpha1.RestoreJob) appsv1alpha1.ApplicationRestore {
+	r := appsv1alpha1.ApplicationRestore{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ApplicationRestore",
+		},
+		ObjectMeta: *j.ObjectMeta.DeepCopy(),
+		Spec: appsv1alpha1.ApplicationRestoreSpec{
+			Backup: j.Spec.BackupRef.Name,
+		},
+		Status: appsv1alpha1.ApplicationRestoreStatus{
+			Phase:       string(j.Status.Phase),
+			StartedAt:   j.Status.StartedAt,
+			CompletedAt: j.Status.CompletedAt,
+			Message:     j.Status.Message,
+		},
+	}
+	r.ManagedFields = nil
+	return r
+}
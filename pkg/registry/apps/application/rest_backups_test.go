@@ -0,0 +1,99 @@
+package application
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+var _ = Describe("backups and restores subresources", func() {
+	strategyGroup := strategyv1alpha1.GroupVersion.Group
+	appsGroup := appsv1alpha1.GroupName
+
+	It("describes a BackupJob as an ApplicationBackup", func() {
+		started := metav1.Now()
+		b := applicationBackup(&backupsv1alpha1.BackupJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:          "db-nightly-1",
+				Namespace:     "tenant-foo",
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "backup-controller"}},
+			},
+			Spec: backupsv1alpha1.BackupJobSpec{
+				PlanRef:     &corev1.LocalObjectReference{Name: "nightly"},
+				StrategyRef: corev1.TypedLocalObjectReference{APIGroup: &strategyGroup, Kind: strategyv1alpha1.VeleroStrategyKind, Name: "postgres"},
+			},
+			Status: backupsv1alpha1.BackupJobStatus{
+				Phase:      backupsv1alpha1.BackupJobPhaseSucceeded,
+				StorageRef: &corev1.TypedLocalObjectReference{Kind: "Bucket", Name: "primary"},
+				BackupRef:  &corev1.LocalObjectReference{Name: "db-20250101"},
+				StartedAt:  &started,
+			},
+		})
+		Expect(b.Kind).To(Equal("ApplicationBackup"))
+		Expect(b.Name).To(Equal("db-nightly-1"))
+		Expect(b.ManagedFields).To(BeNil())
+		Expect(b.Spec.Plan).To(Equal("nightly"))
+		Expect(b.Spec.StrategyRef).To(Equal(appsv1alpha1.BackupObjectReference{APIGroup: strategyGroup, Kind: strategyv1alpha1.VeleroStrategyKind, Name: "postgres"}))
+		Expect(b.Spec.StorageRef).To(Equal(&appsv1alpha1.BackupObjectReference{Kind: "Bucket", Name: "primary"}))
+		Expect(b.Status.Phase).To(Equal("Succeeded"))
+		Expect(b.Status.Backup).To(Equal("db-20250101"))
+		Expect(b.Status.StartedAt).To(Equal(&started))
+	})
+
+	It("converts references between the apps and backups APIs", func() {
+		ref := appsv1alpha1.BackupObjectReference{APIGroup: strategyGroup, Kind: strategyv1alpha1.VeleroStrategyKind, Name: "postgres"}
+		Expect(backupObjectReference(typedLocalObjectReference(ref))).To(Equal(ref))
+
+		core := typedLocalObjectReference(appsv1alpha1.BackupObjectReference{Kind: "Bucket", Name: "primary"})
+		Expect(core.APIGroup).To(BeNil())
+	})
+
+	It("describes a RestoreJob as an ApplicationRestore", func() {
+		r := applicationRestore(&backupsv1alpha1.RestoreJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-restore", Namespace: "tenant-foo"},
+			Spec:       backupsv1alpha1.RestoreJobSpec{BackupRef: corev1.LocalObjectReference{Name: "db-20250101"}},
+			Status:     backupsv1alpha1.RestoreJobStatus{Phase: backupsv1alpha1.RestoreJobPhaseFailed, Message: "velero restore failed"},
+		})
+		Expect(r.Kind).To(Equal("ApplicationRestore"))
+		Expect(r.Spec.Backup).To(Equal("db-20250101"))
+		Expect(r.Status.Phase).To(Equal("Failed"))
+		Expect(r.Status.Message).To(Equal("velero restore failed"))
+	})
+
+	Describe("restoresApplication", func() {
+		target := corev1.TypedLocalObjectReference{APIGroup: &appsGroup, Kind: "Postgres", Name: "db"}
+		owned := map[string]bool{"db-20250101": true}
+		restoreJob := func(backup string) *backupsv1alpha1.RestoreJob {
+			return &backupsv1alpha1.RestoreJob{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-foo"},
+				Spec:       backupsv1alpha1.RestoreJobSpec{BackupRef: corev1.LocalObjectReference{Name: backup}},
+			}
+		}
+
+		It("matches restores of the backups of the Application", func() {
+			Expect(restoresApplication(restoreJob("db-20250101"), "tenant-foo", owned, target)).To(BeTrue())
+			Expect(restoresApplication(restoreJob("other-20250101"), "tenant-foo", owned, target)).To(BeFalse())
+		})
+
+		It("ignores backups of the same name in other namespaces", func() {
+			j := restoreJob("db-20250101")
+			j.Spec.BackupNamespace = "tenant-bar"
+			Expect(restoresApplication(j, "tenant-foo", owned, target)).To(BeFalse())
+		})
+
+		It("matches on the target Application when it is set", func() {
+			j := restoreJob("other-20250101")
+			j.Spec.TargetApplicationRef = &corev1.TypedLocalObjectReference{APIGroup: &appsGroup, Kind: "Postgres", Name: "db"}
+			Expect(restoresApplication(j, "tenant-foo", owned, target)).To(BeTrue())
+
+			j = restoreJob("db-20250101")
+			j.Spec.TargetApplicationRef = &corev1.TypedLocalObjectReference{APIGroup: &appsGroup, Kind: "Postgres", Name: "db-clone"}
+			Expect(restoresApplication(j, "tenant-foo", owned, target)).To(BeFalse())
+		})
+	})
+})
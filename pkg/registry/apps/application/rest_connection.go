@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cozystack/cozystack/internal/template"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+// applicationConnection resolves the connection details of the Application name
+// released by hr. Details that cannot be resolved are left empty; nil is returned
+// if none can.
+func (r *REST) applicationConnection(ctx context.Context, hr *helmv2.HelmRelease, name string) (*appsv1alpha1.ApplicationConnection, error) {
+	if r.connection == nil {
+		return nil, nil
+	}
+	connection, err := template.Template(r.connection, map[string]any{
+		"name":      name,
+		"kind":      strings.ToLower(r.kindName),
+		"namespace": hr.GetReleaseNamespace(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render connection templates: %w", err)
+	}
+
+	result := &appsv1alpha1.ApplicationConnection{}
+	if result.Host, err = r.connectionValue(ctx, hr, connection.Host); err != nil {
+		return nil, fmt.Errorf("host: %w", err)
+	}
+	port, err := r.connectionValue(ctx, hr, connection.Port)
+	if err != nil {
+		return nil, fmt.Errorf("port: %w", err)
+	}
+	if port != "" {
+		p, err := strconv.ParseInt(port, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("port: %q is not a port number", port)
+		}
+		result.Port = int32(p)
+	}
+	if result.CredentialsSecret, err = r.connectionValue(ctx, hr, connection.CredentialsSecret); err != nil {
+		return nil, fmt.Errorf("credentialsSecret: %w", err)
+	}
+
+	if *result == (appsv1alpha1.ApplicationConnection{}) {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// connectionValue resolves a single connection detail. Missing Services and
+// Secrets resolve to an empty string, as they may not be created yet.
+func (r *REST) connectionValue(ctx context.Context, hr *helmv2.HelmRelease, v *config.ConnectionValue) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	key := client.ObjectKey{Namespace: hr.GetReleaseNamespace(), Name: v.Name}
+	switch v.Source {
+	case "Values":
+		var values interface{}
+		if hr.Spec.Values != nil {
+			if err := json.Unmarshal(hr.Spec.Values.Raw, &values); err != nil {
+				return "", fmt.Errorf("failed to decode release values: %w", err)
+			}
+		}
+		return evaluateJSONPath(v.JSONPath, values)
+	case "Service":
+		svc := &corev1.Service{}
+		if err := r.c.Get(ctx, key, svc); apierrors.IsNotFound(err) {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		if v.JSONPath == "" {
+			return svc.Name, nil
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(svc)
+		if err != nil {
+			return "", err
+		}
+		return evaluateJSONPath(v.JSONPath, obj)
+	case "Secret":
+		// Only the existence of the Secret is checked, its data is never exposed
+		secret := &corev1.Secret{}
+		if err := r.c.Get(ctx, key, secret); apierrors.IsNotFound(err) {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		return secret.Name, nil
+	}
+	return "", fmt.Errorf("unknown source %q", v.Source)
+}
+
+// evaluateJSONPath evaluates the JSONPath template path against data. Missing
+// keys resolve to an empty string.
+func evaluateJSONPath(path string, data interface{}) (string, error) {
+	if path == "" || data == nil {
+		return "", nil
+	}
+	jp := jsonpath.New("connection").AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return "", fmt.Errorf("invalid JSONPath %q: %w", path, err)
+	}
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to evaluate JSONPath %q: %w", path, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,70 @@
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("application connection", func() {
+	hr := &helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres-db", Namespace: "tenant-root"},
+		Spec:       helmv2.HelmReleaseSpec{Values: &apiextv1.JSON{Raw: []byte(`{"external":{"port":"15432"}}`)}},
+	}
+
+	newREST := func(connection *config.ConnectionConfig, objs ...runtime.Object) *REST {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+		return &REST{c: c, kindName: "Postgres", connection: connection}
+	}
+
+	It("resolves values, services and secrets", func() {
+		r := newREST(&config.ConnectionConfig{
+			Host:              &config.ConnectionValue{Source: "Service", Name: "{{ .name }}-rw", JSONPath: "{.metadata.name}.{.metadata.namespace}.svc"},
+			Port:              &config.ConnectionValue{Source: "Values", JSONPath: "{.external.port}"},
+			CredentialsSecret: &config.ConnectionValue{Source: "Secret", Name: "{{ .kind }}-{{ .name }}-credentials"},
+		},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "db-rw", Namespace: "tenant-root"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "postgres-db-credentials", Namespace: "tenant-root"}},
+		)
+
+		connection, err := r.applicationConnection(context.Background(), hr, "db")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(connection).To(Equal(&appsv1alpha1.ApplicationConnection{
+			Host:              "db-rw.tenant-root.svc",
+			Port:              15432,
+			CredentialsSecret: "postgres-db-credentials",
+		}))
+	})
+
+	It("leaves out details of missing objects", func() {
+		r := newREST(&config.ConnectionConfig{
+			Host:              &config.ConnectionValue{Source: "Service", Name: "{{ .name }}-rw"},
+			CredentialsSecret: &config.ConnectionValue{Source: "Secret", Name: "{{ .name }}-credentials"},
+		})
+
+		connection, err := r.applicationConnection(context.Background(), hr, "db")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(connection).To(BeNil())
+	})
+
+	It("rejects ports that are not numbers", func() {
+		r := newREST(&config.ConnectionConfig{
+			Port: &config.ConnectionValue{Source: "Values", JSONPath: "{.external}"},
+		})
+
+		_, err := r.applicationConnection(context.Background(), hr, "db")
+		Expect(err).To(HaveOccurred())
+	})
+})
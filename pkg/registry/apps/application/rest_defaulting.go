@@ -17,6 +17,7 @@ limitations under the License.
 package application
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
@@ -32,7 +33,10 @@ func (r *REST) applySpecDefaults(app *appsv1alpha1.Application) error {
 	}
 	var m map[string]any
 	if app.Spec != nil && len(app.Spec.Raw) > 0 {
-		if err := json.Unmarshal(app.Spec.Raw, &m); err != nil {
+		// Decode numbers as json.Number so that large integers survive re-encoding
+		dec := json.NewDecoder(bytes.NewReader(app.Spec.Raw))
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil {
 			return err
 		}
 	}
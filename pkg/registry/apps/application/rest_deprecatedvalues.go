@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apiserver/pkg/warning"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// warnDeprecatedValues adds a warning to the response for every deprecated
+// value declared in the CozystackResourceDefinition that the Application sets.
+func (r *REST) warnDeprecatedValues(ctx context.Context, app *appsv1alpha1.Application) {
+	for _, w := range r.deprecatedValueWarnings(app) {
+		warning.AddWarning(ctx, "", w)
+	}
+}
+
+// deprecatedValueWarnings returns the warnings for the deprecated values set in app
+func (r *REST) deprecatedValueWarnings(app *appsv1alpha1.Application) []string {
+	if len(r.deprecatedValues) == 0 || app.Spec == nil || len(app.Spec.Raw) == 0 {
+		return nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(app.Spec.Raw, &values); err != nil {
+		return nil
+	}
+
+	var warnings []string
+	for _, v := range r.deprecatedValues {
+		if !hasValuesPath(values, strings.Split(v.Path, ".")) {
+			continue
+		}
+		msg := fmt.Sprintf("%s spec.%s is deprecated", r.kindName, v.Path)
+		if v.Replacement != "" {
+			msg += fmt.Sprintf(", use spec.%s instead", v.Replacement)
+		}
+		if v.Message != "" {
+			msg += ": " + v.Message
+		}
+		warnings = append(warnings, msg)
+	}
+	return warnings
+}
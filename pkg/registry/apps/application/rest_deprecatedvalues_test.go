@@ -0,0 +1,44 @@
+package application
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("deprecated values", func() {
+	r := &REST{
+		kindName: "Postgres",
+		deprecatedValues: []config.DeprecatedValue{
+			{Path: "resources.cpu", Replacement: "resourcesPreset"},
+			{Path: "external", Message: "removed in v1.0"},
+		},
+	}
+
+	warnings := func(spec string) []string {
+		return r.deprecatedValueWarnings(&appsv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       &apiextv1.JSON{Raw: []byte(spec)},
+		})
+	}
+
+	It("does not warn without deprecated values", func() {
+		Expect(warnings(`{"replicas":2,"resources":{"memory":"1Gi"}}`)).To(BeEmpty())
+	})
+
+	It("warns about nested values with a replacement", func() {
+		Expect(warnings(`{"resources":{"cpu":"1"}}`)).To(ConsistOf(
+			"Postgres spec.resources.cpu is deprecated, use spec.resourcesPreset instead",
+		))
+	})
+
+	It("includes the message", func() {
+		Expect(warnings(`{"external":false}`)).To(ConsistOf(
+			"Postgres spec.external is deprecated: removed in v1.0",
+		))
+	})
+})
@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// fieldSelectorFilter matches Applications against a field selector after
+// they are converted from HelmReleases, as the HelmRelease cache cannot
+// select by the status of the Applications.
+type fieldSelectorFilter struct {
+	selector fields.Selector
+	// matched holds the Applications of a watch that last matched selector,
+	// by namespace/name
+	matched map[string]bool
+}
+
+// newFieldSelectorFilter returns a filter for selector, or nil if selector
+// matches everything
+func newFieldSelectorFilter(selector fields.Selector) (*fieldSelectorFilter, error) {
+	if selector == nil || selector.Empty() {
+		return nil, nil
+	}
+	for _, req := range selector.Requirements() {
+		if _, _, err := appsv1alpha1.ApplicationFieldLabelConversionFunc(req.Field, req.Value); err != nil {
+			return nil, apierrors.NewBadRequest(err.Error())
+		}
+	}
+	return &fieldSelectorFilter{selector: selector, matched: map[string]bool{}}, nil
+}
+
+// matches reports whether app matches the selector
+func (f *fieldSelectorFilter) matches(app *appsv1alpha1.Application) bool {
+	return f == nil || f.selector.Matches(appsv1alpha1.ApplicationToSelectableFields(app))
+}
+
+// filterEvent returns the type of the event a watch delivers for app, and
+// false if it delivers none. As with the watch cache of the API server, an
+// Application that starts matching is added and one that stops matching is
+// deleted. Applications matching before a watch started at a resource
+// version are unknown to it, so they are added on their first change.
+func (f *fieldSelectorFilter) filterEvent(eventType watch.EventType, app *appsv1alpha1.Application) (watch.EventType, bool) {
+	if f == nil {
+		return eventType, true
+	}
+	key := app.Namespace + "/" + app.Name
+	was := f.matched[key]
+	now := f.matches(app)
+
+	switch eventType {
+	case watch.Added, watch.Modified:
+		switch {
+		case now && was:
+			return eventType, true
+		case now:
+			f.matched[key] = true
+			return watch.Added, true
+		case was:
+			delete(f.matched, key)
+			return watch.Deleted, true
+		}
+		return "", false
+	case watch.Deleted:
+		delete(f.matched, key)
+		return eventType, was || now
+	default:
+		return eventType, true
+	}
+}
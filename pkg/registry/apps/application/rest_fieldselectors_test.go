@@ -0,0 +1,69 @@
+package application
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+var _ = Describe("field selectors", func() {
+	app := func(version string, ready metav1.ConditionStatus) *appsv1alpha1.Application {
+		a := &appsv1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "tenant-root"}}
+		a.Status.Version = version
+		if ready != "" {
+			a.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: ready}}
+		}
+		return a
+	}
+	filter := func(selector string) *fieldSelectorFilter {
+		f, err := newFieldSelectorFilter(fields.ParseSelectorOrDie(selector))
+		Expect(err).NotTo(HaveOccurred())
+		return f
+	}
+
+	It("matches everything without a selector", func() {
+		f, err := newFieldSelectorFilter(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.matches(app("", ""))).To(BeTrue())
+	})
+
+	It("selects by version and readiness", func() {
+		Expect(filter("status.ready=False").matches(app("", metav1.ConditionFalse))).To(BeTrue())
+		Expect(filter("status.ready=False").matches(app("", metav1.ConditionTrue))).To(BeFalse())
+		Expect(filter("status.ready=Unknown").matches(app("", ""))).To(BeTrue())
+		Expect(filter("status.version!=1.2.0").matches(app("1.2.0", ""))).To(BeFalse())
+		Expect(filter("metadata.name=db,status.version=1.2.0").matches(app("1.2.0", ""))).To(BeTrue())
+	})
+
+	It("rejects unsupported fields", func() {
+		_, err := newFieldSelectorFilter(fields.ParseSelectorOrDie("spec.replicas=3"))
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+	})
+
+	It("adds and deletes Applications as they start and stop matching in a watch", func() {
+		f := filter("status.ready=False")
+
+		_, ok := f.filterEvent(watch.Added, app("", metav1.ConditionTrue))
+		Expect(ok).To(BeFalse())
+
+		eventType, ok := f.filterEvent(watch.Modified, app("", metav1.ConditionFalse))
+		Expect(ok).To(BeTrue())
+		Expect(eventType).To(Equal(watch.Added))
+
+		eventType, ok = f.filterEvent(watch.Modified, app("", metav1.ConditionFalse))
+		Expect(ok).To(BeTrue())
+		Expect(eventType).To(Equal(watch.Modified))
+
+		eventType, ok = f.filterEvent(watch.Modified, app("", metav1.ConditionTrue))
+		Expect(ok).To(BeTrue())
+		Expect(eventType).To(Equal(watch.Deleted))
+
+		_, ok = f.filterEvent(watch.Deleted, app("", metav1.ConditionTrue))
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("generateName", func() {
+	newREST := func(c client.Client) *REST {
+		return &REST{
+			c:             c,
+			gvr:           schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+			gvk:           schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+		}
+	}
+
+	newClient := func(funcs interceptor.Funcs) client.WithWatch {
+		scheme := runtime.NewScheme()
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(funcs).Build()
+	}
+
+	newApp := func() *appsv1alpha1.Application {
+		return &appsv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "ci-", Namespace: "tenant-root"},
+			Spec:       &apiextv1.JSON{Raw: []byte(`{}`)},
+		}
+	}
+
+	It("creates a HelmRelease with a generated name and maps it back", func() {
+		c := newClient(interceptor.Funcs{})
+		obj, err := newREST(c).Create(context.Background(), newApp(), nil, &metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		app := obj.(*appsv1alpha1.Application)
+		Expect(app.Name).To(HavePrefix("ci-"))
+		Expect(app.Name).To(HaveLen(len("ci-") + 5))
+		Expect(app.GenerateName).To(Equal("ci-"))
+
+		hr := &helmv2.HelmRelease{}
+		Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "tenant-root", Name: "test-" + app.Name}, hr)).To(Succeed())
+		Expect(hr.Labels[ApplicationNameLabel]).To(Equal(app.Name))
+		Expect(hr.GenerateName).To(Equal("test-ci-"))
+	})
+
+	It("retries when a generated name is already taken", func() {
+		attempts := 0
+		c := newClient(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				attempts++
+				if attempts == 1 {
+					return apierrors.NewAlreadyExists(schema.GroupResource{Resource: "helmreleases"}, obj.GetName())
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		})
+		obj, err := newREST(c).Create(context.Background(), newApp(), nil, &metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+		Expect(strings.HasPrefix(obj.(*appsv1alpha1.Application).Name, "ci-")).To(BeTrue())
+	})
+
+	It("requires a name or generateName", func() {
+		app := newApp()
+		app.GenerateName = ""
+		_, err := newREST(newClient(interceptor.Funcs{})).Create(context.Background(), app, nil, &metav1.CreateOptions{})
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,76 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HelmReleaseApplicationIndex indexes the cached HelmReleases by the
+// Application they back, as group/kind and as group/kind/name, from the
+// application.group, application.kind and application.name labels
+const HelmReleaseApplicationIndex = "apps.cozystack.io/application"
+
+// applicationIndexValue returns the HelmReleaseApplicationIndex value of the
+// Applications of group and kind, or of the Application name if it is set
+func applicationIndexValue(group, kind, name string) string {
+	if name == "" {
+		return group + "/" + kind
+	}
+	return group + "/" + kind + "/" + name
+}
+
+// helmReleaseApplicationIndexValues returns the HelmReleaseApplicationIndex
+// values of obj
+func helmReleaseApplicationIndexValues(obj client.Object) []string {
+	hr, ok := obj.(*helmv2.HelmRelease)
+	if !ok {
+		return nil
+	}
+	group, kind := hr.Labels[ApplicationGroupLabel], hr.Labels[ApplicationKindLabel]
+	if group == "" || kind == "" {
+		return nil
+	}
+	values := []string{applicationIndexValue(group, kind, "")}
+	if name := hr.Labels[ApplicationNameLabel]; name != "" {
+		values = append(values, applicationIndexValue(group, kind, name))
+	}
+	return values
+}
+
+// IndexHelmReleases adds HelmReleaseApplicationIndex to the HelmRelease
+// informer of indexer. It must be called before the cache is started.
+func IndexHelmReleases(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &helmv2.HelmRelease{}, HelmReleaseApplicationIndex, helmReleaseApplicationIndexValues)
+}
+
+// SetIndexed makes List look up the HelmReleases of the Applications in the
+// HelmReleaseApplicationIndex of the cache, instead of matching the labels of
+// every cached HelmRelease of the namespace
+func (r *REST) SetIndexed(indexed bool) {
+	r.indexed = indexed
+}
+
+// applicationIndexFields returns the field selector looking up the
+// HelmReleases of the Application name, or of all Applications of the kind
+// if name is empty
+func (r *REST) applicationIndexFields(name string) client.MatchingFields {
+	return client.MatchingFields{HelmReleaseApplicationIndex: applicationIndexValue(r.gvk.Group, r.kindName, name)}
+}
@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("HelmRelease index", func() {
+	release := func(name, kind, appName string) *helmv2.HelmRelease {
+		return &helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "tenant-root",
+			Labels: map[string]string{
+				ApplicationKindLabel:  kind,
+				ApplicationGroupLabel: appsv1alpha1.GroupName,
+				ApplicationNameLabel:  appName,
+			},
+		}}
+	}
+
+	newREST := func() *REST {
+		scheme := runtime.NewScheme()
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithIndex(&helmv2.HelmRelease{}, HelmReleaseApplicationIndex, helmReleaseApplicationIndexValues).
+			WithObjects(release("test-a", "Test", "a"), release("test-b", "Test", "b"), release("other-a", "Other", "a")).
+			Build()
+		r := &REST{
+			c:             c,
+			gvr:           schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+			gvk:           schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+		}
+		r.SetIndexed(true)
+		return r
+	}
+
+	names := func(obj runtime.Object) []string {
+		var out []string
+		for _, app := range obj.(*appsv1alpha1.ApplicationList).Items {
+			out = append(out, app.Name)
+		}
+		return out
+	}
+
+	It("indexes HelmReleases by kind and by name", func() {
+		Expect(helmReleaseApplicationIndexValues(release("test-a", "Test", "a"))).To(ConsistOf(
+			appsv1alpha1.GroupName+"/Test", appsv1alpha1.GroupName+"/Test/a",
+		))
+		Expect(helmReleaseApplicationIndexValues(&helmv2.HelmRelease{})).To(BeEmpty())
+	})
+
+	It("lists the Applications of the kind from the index", func() {
+		ctx := request.WithNamespace(context.Background(), "tenant-root")
+		obj, err := newREST().List(ctx, &metainternalversion.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(obj)).To(Equal([]string{"a", "b"}))
+	})
+
+	It("looks up an Application by name in the index", func() {
+		ctx := request.WithNamespace(context.Background(), "tenant-root")
+		obj, err := newREST().List(ctx, &metainternalversion.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("metadata.name", "b"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(obj)).To(Equal([]string{"b"}))
+	})
+})
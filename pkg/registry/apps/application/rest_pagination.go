@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// paginated reports whether options request a chunk of the list
+func paginated(options *metainternalversion.ListOptions) bool {
+	return options != nil && (options.Limit > 0 || options.Continue != "")
+}
+
+// listHelmReleases lists the HelmReleases matching opts. A chunked list is
+// passed through to the API server, as the cache neither limits nor
+// continues lists consistently; the continue token of the HelmRelease list
+// is the continue token of the Application list, as Application names map
+// to HelmRelease names in the same order. Chunks may hold fewer Applications
+// than the limit once the field selectors are applied, which clients handle
+// as they do for any chunked list. Other lists are served by the cache, from
+// the HelmReleaseApplicationIndex of the Application name, or of the kind if
+// name is empty, once the REST is indexed.
+func (r *REST) listHelmReleases(ctx context.Context, hrList *helmv2.HelmReleaseList, opts *client.ListOptions, options *metainternalversion.ListOptions, name string) error {
+	if !paginated(options) {
+		if r.indexed {
+			r.applicationIndexFields(name).ApplyToList(opts)
+		}
+		return r.c.List(ctx, hrList, opts)
+	}
+	opts.Limit = options.Limit
+	opts.Continue = options.Continue
+	return r.w.List(ctx, hrList, opts)
+}
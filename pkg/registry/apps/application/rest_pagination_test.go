@@ -0,0 +1,83 @@
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("pagination", func() {
+	var (
+		cached, direct client.WithWatch
+		directOpts     *client.ListOptions
+		r              *REST
+		ctx            context.Context
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		hr := &helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-db",
+			Namespace: "tenant-root",
+			Labels:    map[string]string{ApplicationKindLabel: "Test", ApplicationGroupLabel: appsv1alpha1.GroupName},
+		}}
+		cached = fake.NewClientBuilder().WithScheme(scheme).WithObjects(hr.DeepCopy()).Build()
+		directOpts = nil
+		direct = fake.NewClientBuilder().WithScheme(scheme).WithObjects(hr.DeepCopy()).
+			WithInterceptorFuncs(interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					directOpts = &client.ListOptions{}
+					directOpts.ApplyOptions(opts)
+					if err := c.List(ctx, list, opts...); err != nil {
+						return err
+					}
+					list.(*helmv2.HelmReleaseList).Continue = "next-chunk"
+					return nil
+				},
+			}).Build()
+		r = &REST{
+			c:             cached,
+			w:             direct,
+			gvr:           schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+			gvk:           schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+		}
+		ctx = request.WithNamespace(context.Background(), "tenant-root")
+	})
+
+	It("lists from the cache without a limit", func() {
+		obj, err := r.List(ctx, &metainternalversion.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(directOpts).To(BeNil())
+		Expect(obj.(*appsv1alpha1.ApplicationList).Items).To(HaveLen(1))
+		Expect(obj.(*appsv1alpha1.ApplicationList).Continue).To(BeEmpty())
+	})
+
+	It("passes limit and continue through to the API server", func() {
+		obj, err := r.List(ctx, &metainternalversion.ListOptions{Limit: 1, Continue: "this-chunk"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(directOpts).NotTo(BeNil())
+		Expect(directOpts.Limit).To(Equal(int64(1)))
+		Expect(directOpts.Continue).To(Equal("this-chunk"))
+
+		list := obj.(*appsv1alpha1.ApplicationList)
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Name).To(Equal("db"))
+		Expect(list.Continue).To(Equal("next-chunk"))
+	})
+})
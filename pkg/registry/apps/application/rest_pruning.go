@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/klog/v2"
+)
+
+// pruneUnknownFields removes spec fields that are not described by the schema.
+// It is a no-op when unknown fields are preserved or no schema is configured,
+// so the spec is carried into HelmRelease values byte-for-byte.
+func (r *REST) pruneUnknownFields(app *appsv1alpha1.Application) error {
+	if r.preserveUnknownFields || r.specSchema == nil {
+		return nil
+	}
+	if app.Spec == nil || len(app.Spec.Raw) == 0 {
+		return nil
+	}
+	// Decode numbers as json.Number so that large integers survive re-encoding
+	var m map[string]any
+	dec := json.NewDecoder(bytes.NewReader(app.Spec.Raw))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return fmt.Errorf("failed to decode spec: %w", err)
+	}
+	pruned := pruneLikeKubernetes(m, r.specSchema)
+	if len(pruned) == 0 {
+		return nil
+	}
+	klog.V(4).Infof("Pruned unknown fields from %s %s/%s: %v", r.kindName, app.Namespace, app.Name, pruned)
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode spec: %w", err)
+	}
+	app.Spec = &apiextv1.JSON{Raw: raw}
+	return nil
+}
+
+// pruneLikeKubernetes prunes the object in place the same way the kube-apiserver
+// prunes custom resources, honoring x-kubernetes-preserve-unknown-fields, and
+// returns the paths of the removed fields.
+func pruneLikeKubernetes(obj map[string]any, s *structuralschema.Structural) []string {
+	return pruning.PruneWithOptions(obj, s, false, structuralschema.UnknownFieldPathOptions{
+		TrackUnknownFieldPaths: true,
+	})
+}
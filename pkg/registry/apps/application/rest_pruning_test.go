@@ -0,0 +1,96 @@
+package application
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apischema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("unknown fields round-trip", func() {
+	const spec = `{"replicas":3,"custom":{"nested":[1,2,3]},"bigint":9007199254740993}`
+
+	newApp := func() *appsv1alpha1.Application {
+		return &appsv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "tenant-root"},
+			Spec:       &apiextv1.JSON{Raw: []byte(spec)},
+		}
+	}
+
+	roundTrip := func(r *REST, app *appsv1alpha1.Application) appsv1alpha1.Application {
+		Expect(r.pruneUnknownFields(app)).To(Succeed())
+		hr, err := r.ConvertApplicationToHelmRelease(app)
+		Expect(err).NotTo(HaveOccurred())
+		out, err := r.ConvertHelmReleaseToApplication(hr)
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	It("keeps unknown fields losslessly when preservation is enabled", func() {
+		r := &REST{
+			kindName:              "Test",
+			releaseConfig:         config.ReleaseConfig{Prefix: "test-"},
+			specSchema:            buildPruningTestSchema(),
+			preserveUnknownFields: true,
+		}
+
+		out := roundTrip(r, newApp())
+
+		Expect(out.Name).To(Equal("test"))
+		Expect(out.Spec.Raw).To(MatchJSON(spec))
+		Expect(string(out.Spec.Raw)).To(ContainSubstring("9007199254740993"))
+	})
+
+	It("keeps unknown fields when no schema is configured", func() {
+		r := &REST{
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+		}
+
+		out := roundTrip(r, newApp())
+
+		Expect(out.Spec.Raw).To(MatchJSON(spec))
+	})
+
+	It("prunes unknown fields when preservation is disabled", func() {
+		r := &REST{
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+			specSchema:    buildPruningTestSchema(),
+		}
+
+		out := roundTrip(r, newApp())
+
+		Expect(out.Spec.Raw).To(MatchJSON(`{"replicas":3}`))
+	})
+
+	It("does not touch subtrees marked with x-kubernetes-preserve-unknown-fields", func() {
+		s := buildPruningTestSchema()
+		s.Properties["custom"] = apischema.Structural{
+			Generic:    apischema.Generic{Type: "object"},
+			Extensions: apischema.Extensions{XPreserveUnknownFields: true},
+		}
+		r := &REST{
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+			specSchema:    s,
+		}
+
+		out := roundTrip(r, newApp())
+
+		Expect(out.Spec.Raw).To(MatchJSON(`{"replicas":3,"custom":{"nested":[1,2,3]}}`))
+	})
+})
+
+func buildPruningTestSchema() *apischema.Structural {
+	return &apischema.Structural{
+		Generic: apischema.Generic{Type: "object"},
+		Properties: map[string]apischema.Structural{
+			"replicas": {Generic: apischema.Generic{Type: "integer"}},
+		},
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+// requestLimiterSize is the number of namespaces a RequestLimiter tracks
+const requestLimiterSize = 4096
+
+// rateLimitRetryAfterSeconds is the delay suggested to throttled clients
+const rateLimitRetryAfterSeconds = 1
+
+// RequestLimiter limits the requests to the Applications of a kind with a
+// token bucket per namespace, so that a tenant flooding one kind with requests
+// is throttled without affecting other tenants and kinds.
+type RequestLimiter struct {
+	qps   float32
+	burst int
+
+	mu       sync.Mutex
+	limiters map[string]flowcontrol.RateLimiter
+	// order holds the keys of limiters, oldest first, for eviction
+	order []string
+}
+
+// NewRequestLimiter returns a RequestLimiter for the rate limit of a kind, or
+// nil if the kind has none
+func NewRequestLimiter(rl *config.RateLimitConfig) *RequestLimiter {
+	if rl == nil || rl.QPS <= 0 || rl.Burst < 1 {
+		return nil
+	}
+	return &RequestLimiter{
+		qps:      float32(rl.QPS),
+		burst:    rl.Burst,
+		limiters: make(map[string]flowcontrol.RateLimiter),
+	}
+}
+
+// accept takes a token for the namespace of the request and returns a
+// TooManyRequests error if there is none left
+func (l *RequestLimiter) accept(ctx context.Context, kind string) error {
+	if l == nil {
+		return nil
+	}
+	namespace := request.NamespaceValue(ctx)
+
+	l.mu.Lock()
+	limiter, ok := l.limiters[namespace]
+	if !ok {
+		if len(l.order) >= requestLimiterSize {
+			delete(l.limiters, l.order[0])
+			l.order = l.order[1:]
+		}
+		limiter = flowcontrol.NewTokenBucketRateLimiter(l.qps, l.burst)
+		l.limiters[namespace] = limiter
+		l.order = append(l.order, namespace)
+	}
+	l.mu.Unlock()
+
+	if !limiter.TryAccept() {
+		return apierrors.NewTooManyRequests(
+			fmt.Sprintf("too many requests to %s applications in namespace %s", kind, namespace),
+			rateLimitRetryAfterSeconds)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("request limiter", func() {
+	inNamespace := func(namespace string) context.Context {
+		return request.WithNamespace(context.Background(), namespace)
+	}
+
+	It("is disabled without a rate limit", func() {
+		Expect(NewRequestLimiter(nil)).To(BeNil())
+		var l *RequestLimiter
+		Expect(l.accept(inNamespace("tenant-root"), "Test")).To(Succeed())
+	})
+
+	It("throttles a namespace after its burst", func() {
+		l := NewRequestLimiter(&config.RateLimitConfig{QPS: 0.001, Burst: 2})
+		ctx := inNamespace("tenant-a")
+		Expect(l.accept(ctx, "Test")).To(Succeed())
+		Expect(l.accept(ctx, "Test")).To(Succeed())
+		err := l.accept(ctx, "Test")
+		Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+	})
+
+	It("does not throttle other namespaces", func() {
+		l := NewRequestLimiter(&config.RateLimitConfig{QPS: 0.001, Burst: 1})
+		Expect(l.accept(inNamespace("tenant-a"), "Test")).To(Succeed())
+		Expect(l.accept(inNamespace("tenant-a"), "Test")).NotTo(Succeed())
+		Expect(l.accept(inNamespace("tenant-b"), "Test")).To(Succeed())
+	})
+})
+
+var _ = Describe("request limiter of Applications", func() {
+	var r *REST
+	ctx := request.WithNamespace(context.Background(), "tenant-root")
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r = &REST{
+			c:             c,
+			w:             c,
+			gvr:           schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+			gvk:           schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+			limiter:       NewRequestLimiter(&config.RateLimitConfig{QPS: 0.001, Burst: 1}),
+		}
+	})
+
+	It("charges an update creating the Application once", func() {
+		app := &appsv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "tenant-root"},
+			Spec:       &apiextv1.JSON{Raw: []byte(`{}`)},
+		}
+		_, created, err := r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, true, &metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(BeTrue())
+
+		_, err = r.Get(ctx, "db", &metav1.GetOptions{})
+		Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+	})
+
+	It("charges subresource writes", func() {
+		Expect(r.limiter.accept(ctx, r.kindName)).To(Succeed())
+		_, err := (&ReconcileREST{app: r}).Create(ctx, "db", &appsv1alpha1.Application{}, nil, &metav1.CreateOptions{})
+		Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+		_, err = (&RollbackREST{app: r}).Create(ctx, "db", &appsv1alpha1.Application{}, nil, &metav1.CreateOptions{})
+		Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+	})
+})
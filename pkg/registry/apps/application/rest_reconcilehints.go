@@ -0,0 +1,93 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"fmt"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/pkg/apis/meta"
+)
+
+// Application annotations that request one-off Flux actions on the underlying
+// HelmRelease. The value is an opaque token, typically a timestamp: a request is
+// handled once per distinct value. The keys are qualified with the API group so
+// that they do not collide with annotations users already set on Applications.
+// Like all Application annotations, they are stored on the HelmRelease with
+// AnnotationPrefix, e.g. apps.cozystack.io-apps.cozystack.io/force-upgrade.
+const (
+	// AnnotationReconcile requests an immediate reconciliation
+	AnnotationReconcile = "apps.cozystack.io/reconcile"
+	// AnnotationForceUpgrade requests a one-off forced Helm upgrade
+	AnnotationForceUpgrade = "apps.cozystack.io/force-upgrade"
+	// AnnotationResetFailures resets the install and upgrade failure counters
+	AnnotationResetFailures = "apps.cozystack.io/reset-failures"
+)
+
+// reconcileHintAnnotations maps Application annotations to the Flux annotations they set.
+// Every hint also sets meta.ReconcileRequestAnnotation to the same token, which Flux
+// requires for force and reset requests to be handled.
+var reconcileHintAnnotations = map[string]string{
+	AnnotationReconcile:     meta.ReconcileRequestAnnotation,
+	AnnotationForceUpgrade:  helmv2.ForceRequestAnnotation,
+	AnnotationResetFailures: helmv2.ResetRequestAnnotation,
+}
+
+// reconcileHintToken returns the token of the reconcile hints set in annotations.
+// All hints set at once must carry the same token, since Flux only handles a
+// force or reset request whose token matches the reconcile request.
+func reconcileHintToken(annotations map[string]string) (string, error) {
+	var token, tokenKey string
+	for key := range reconcileHintAnnotations {
+		value, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		if value == "" {
+			return "", fmt.Errorf("annotation %q must not be empty", key)
+		}
+		if token != "" && value != token {
+			return "", fmt.Errorf("annotations %q and %q must have the same value", tokenKey, key)
+		}
+		token, tokenKey = value, key
+	}
+	return token, nil
+}
+
+// validateReconcileHints checks the reconcile hints of an Application
+func validateReconcileHints(annotations map[string]string) error {
+	_, err := reconcileHintToken(annotations)
+	return err
+}
+
+// applyReconcileHints sets the Flux annotations requested by the Application annotations on hr
+func applyReconcileHints(annotations map[string]string, hr *helmv2.HelmRelease) error {
+	token, err := reconcileHintToken(annotations)
+	if err != nil || token == "" {
+		return err
+	}
+	if hr.Annotations == nil {
+		hr.Annotations = make(map[string]string)
+	}
+	for key, fluxKey := range reconcileHintAnnotations {
+		if _, ok := annotations[key]; ok {
+			hr.Annotations[fluxKey] = token
+		}
+	}
+	hr.Annotations[meta.ReconcileRequestAnnotation] = token
+	return nil
+}
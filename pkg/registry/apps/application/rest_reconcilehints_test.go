@@ -0,0 +1,72 @@
+package application
+
+import (
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("reconcile hint annotations", func() {
+	r := &REST{
+		kindName:      "Test",
+		releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+	}
+
+	convert := func(annotations map[string]string) (*helmv2.HelmRelease, error) {
+		return r.ConvertApplicationToHelmRelease(&appsv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "tenant-root", Annotations: annotations},
+		})
+	}
+
+	It("requests a forced upgrade", func() {
+		hr, err := convert(map[string]string{AnnotationForceUpgrade: "2025-01-01T00:00:00Z"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hr.Annotations).To(HaveKeyWithValue(helmv2.ForceRequestAnnotation, "2025-01-01T00:00:00Z"))
+		Expect(hr.Annotations).To(HaveKeyWithValue(meta.ReconcileRequestAnnotation, "2025-01-01T00:00:00Z"))
+		Expect(hr.Annotations).To(HaveKeyWithValue(AnnotationPrefix+AnnotationForceUpgrade, "2025-01-01T00:00:00Z"))
+	})
+
+	It("combines hints with the same token", func() {
+		hr, err := convert(map[string]string{AnnotationForceUpgrade: "1", AnnotationResetFailures: "1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hr.Annotations).To(HaveKeyWithValue(helmv2.ForceRequestAnnotation, "1"))
+		Expect(hr.Annotations).To(HaveKeyWithValue(helmv2.ResetRequestAnnotation, "1"))
+		Expect(hr.Annotations).To(HaveKeyWithValue(meta.ReconcileRequestAnnotation, "1"))
+	})
+
+	It("does not set Flux annotations without hints", func() {
+		hr, err := convert(map[string]string{"owner": "team-a"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hr.Annotations).NotTo(HaveKey(meta.ReconcileRequestAnnotation))
+	})
+
+	It("ignores unqualified annotations of the same name", func() {
+		annotations := map[string]string{"reconcile": "", "force-upgrade": "yes"}
+		Expect(validateReconcileHints(annotations)).To(Succeed())
+		hr, err := convert(annotations)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hr.Annotations).NotTo(HaveKey(meta.ReconcileRequestAnnotation))
+		Expect(hr.Annotations).NotTo(HaveKey(helmv2.ForceRequestAnnotation))
+	})
+
+	It("rejects hints with different tokens", func() {
+		Expect(validateReconcileHints(map[string]string{AnnotationForceUpgrade: "1", AnnotationReconcile: "2"})).NotTo(Succeed())
+	})
+
+	It("rejects empty hints", func() {
+		Expect(validateReconcileHints(map[string]string{AnnotationResetFailures: ""})).NotTo(Succeed())
+	})
+
+	It("hides Flux annotations from the Application", func() {
+		hr, err := convert(map[string]string{AnnotationReconcile: "1"})
+		Expect(err).NotTo(HaveOccurred())
+		app, err := r.ConvertHelmReleaseToApplication(hr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Annotations).To(Equal(map[string]string{AnnotationReconcile: "1"}))
+	})
+})
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/pkg/apis/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+const (
+	// ConditionDriftDetected reports whether the objects of the release were
+	// changed outside of Helm. It is only set when drift detection is enabled.
+	ConditionDriftDetected = "DriftDetected"
+
+	// ReasonNoDriftDetected is the reason of a false DriftDetected condition
+	ReasonNoDriftDetected = "NoDriftDetected"
+
+	// defaultRemediationRetries retries failed installs and upgrades indefinitely
+	defaultRemediationRetries = -1
+)
+
+// applyRemediation sets the install and upgrade retries and the drift detection
+// mode of the release from the remediation rules of the application kind.
+func applyRemediation(rm *config.RemediationConfig, hr *helmv2.HelmRelease) {
+	retries := defaultRemediationRetries
+	if rm != nil && rm.Retries != nil {
+		retries = *rm.Retries
+	}
+	hr.Spec.Install = &helmv2.Install{
+		Remediation: &helmv2.InstallRemediation{Retries: retries},
+	}
+	hr.Spec.Upgrade = &helmv2.Upgrade{
+		Remediation: &helmv2.UpgradeRemediation{Retries: retries},
+	}
+	if rm != nil && rm.DriftDetection != "" {
+		hr.Spec.DriftDetection = &helmv2.DriftDetection{
+			Mode: helmv2.DriftDetectionMode(rm.DriftDetection),
+		}
+	}
+}
+
+// driftCondition derives the DriftDetected condition of an Application from its
+// HelmRelease. helm-controller reports drift it detects or fails to correct in
+// the reason or message of the Ready, Released and Reconciling conditions. The
+// condition is omitted when drift detection is disabled for the release.
+func driftCondition(hr *helmv2.HelmRelease) (metav1.Condition, bool) {
+	if !hr.GetDriftDetection().MustDetectChanges() {
+		return metav1.Condition{}, false
+	}
+
+	var ready *metav1.Condition
+	for i := range hr.Status.Conditions {
+		c := &hr.Status.Conditions[i]
+		switch c.Type {
+		case meta.ReadyCondition, helmv2.ReleasedCondition, meta.ReconcilingCondition:
+		default:
+			continue
+		}
+		if c.Type == meta.ReadyCondition {
+			ready = c
+		}
+		if mentionsDrift(c.Reason) || mentionsDrift(c.Message) {
+			return metav1.Condition{
+				Type:               ConditionDriftDetected,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: c.LastTransitionTime,
+				Reason:             c.Reason,
+				Message:            c.Message,
+			}, true
+		}
+	}
+
+	// Without a Ready condition the release has not been reconciled yet
+	if ready == nil {
+		return metav1.Condition{}, false
+	}
+	return metav1.Condition{
+		Type:               ConditionDriftDetected,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: ready.LastTransitionTime,
+		Reason:             ReasonNoDriftDetected,
+		Message:            "No drift detected in the objects of the release",
+	}, true
+}
+
+// mentionsDrift reports whether a condition reason or message refers to drift
+func mentionsDrift(s string) bool {
+	return strings.Contains(strings.ToLower(s), "drift")
+}
@@ -0,0 +1,109 @@
+package application
+
+import (
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("release remediation", func() {
+	convert := func(rm *config.RemediationConfig) *helmv2.HelmRelease {
+		r := &REST{
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-", Remediation: rm},
+		}
+		hr, err := r.ConvertApplicationToHelmRelease(&appsv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "tenant-root"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return hr
+	}
+
+	It("retries indefinitely without remediation rules", func() {
+		hr := convert(nil)
+		Expect(hr.Spec.Install.Remediation.Retries).To(Equal(-1))
+		Expect(hr.Spec.Upgrade.Remediation.Retries).To(Equal(-1))
+		Expect(hr.Spec.DriftDetection).To(BeNil())
+	})
+
+	It("passes the remediation rules of the kind through", func() {
+		retries := 3
+		hr := convert(&config.RemediationConfig{Retries: &retries, DriftDetection: "warn"})
+		Expect(hr.Spec.Install.Remediation.Retries).To(Equal(3))
+		Expect(hr.Spec.Upgrade.Remediation.Retries).To(Equal(3))
+		Expect(hr.Spec.DriftDetection.Mode).To(Equal(helmv2.DriftDetectionWarn))
+	})
+})
+
+var _ = Describe("drift condition", func() {
+	release := func(mode helmv2.DriftDetectionMode, conditions ...metav1.Condition) *helmv2.HelmRelease {
+		hr := &helmv2.HelmRelease{}
+		if mode != "" {
+			hr.Spec.DriftDetection = &helmv2.DriftDetection{Mode: mode}
+		}
+		hr.Status.Conditions = conditions
+		return hr
+	}
+
+	It("is omitted when drift detection is disabled", func() {
+		_, ok := driftCondition(release("", metav1.Condition{Type: meta.ReadyCondition, Status: metav1.ConditionTrue}))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("reports drift from the release conditions", func() {
+		c, ok := driftCondition(release(helmv2.DriftDetectionEnabled,
+			metav1.Condition{Type: meta.ReadyCondition, Status: metav1.ConditionFalse, Reason: "DriftCorrectionFailed", Message: "failed to correct drift"},
+		))
+		Expect(ok).To(BeTrue())
+		Expect(c.Type).To(Equal(ConditionDriftDetected))
+		Expect(c.Status).To(Equal(metav1.ConditionTrue))
+		Expect(c.Reason).To(Equal("DriftCorrectionFailed"))
+	})
+
+	It("reports no drift for a reconciled release", func() {
+		c, ok := driftCondition(release(helmv2.DriftDetectionWarn,
+			metav1.Condition{Type: meta.ReadyCondition, Status: metav1.ConditionTrue, Reason: "UpgradeSucceeded"},
+		))
+		Expect(ok).To(BeTrue())
+		Expect(c.Status).To(Equal(metav1.ConditionFalse))
+		Expect(c.Reason).To(Equal(ReasonNoDriftDetected))
+	})
+
+	It("is omitted before the release is reconciled", func() {
+		_, ok := driftCondition(release(helmv2.DriftDetectionEnabled))
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("release settings", func() {
+	convert := func(rc config.ReleaseConfig) *helmv2.HelmRelease {
+		r := &REST{kindName: "Test", releaseConfig: rc}
+		hr, err := r.ConvertApplicationToHelmRelease(&appsv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "tenant-root"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return hr
+	}
+
+	It("uses the defaults when the kind sets nothing", func() {
+		hr := convert(config.ReleaseConfig{Prefix: "test-"})
+		Expect(hr.Spec.Interval.Duration).To(Equal(defaultReleaseInterval))
+		Expect(hr.Spec.Timeout).To(BeNil())
+		Expect(hr.Spec.MaxHistory).To(BeNil())
+	})
+
+	It("uses the interval, timeout and history of the kind", func() {
+		maxHistory := 10
+		hr := convert(config.ReleaseConfig{Prefix: "test-", Interval: time.Minute, Timeout: 15 * time.Minute, MaxHistory: &maxHistory})
+		Expect(hr.Spec.Interval.Duration).To(Equal(time.Minute))
+		Expect(hr.Spec.Timeout.Duration).To(Equal(15 * time.Minute))
+		Expect(*hr.Spec.MaxHistory).To(Equal(10))
+	})
+})
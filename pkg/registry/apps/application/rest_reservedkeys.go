@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"encoding/json"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// validateReservedKeys rejects Applications whose spec sets any of the reserved keys
+// declared in the CozystackResourceDefinition. Platform-injected values are merged
+// under the user values by Helm, so a user value would silently override them.
+func (r *REST) validateReservedKeys(app *appsv1alpha1.Application) error {
+	if len(r.reservedKeys) == 0 || app.Spec == nil || len(app.Spec.Raw) == 0 {
+		return nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(app.Spec.Raw, &values); err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+
+	var errs field.ErrorList
+	for _, key := range r.reservedKeys {
+		path := strings.Split(key, ".")
+		if hasValuesPath(values, path) {
+			errs = append(errs, field.Forbidden(field.NewPath("spec", path...), "reserved for platform-injected configuration"))
+		}
+	}
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(r.gvk.GroupKind(), app.Name, errs)
+	}
+	return nil
+}
+
+// hasValuesPath reports whether values contain the nested key path
+func hasValuesPath(values map[string]interface{}, path []string) bool {
+	for i, key := range path {
+		v, ok := values[key]
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		if values, ok = v.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
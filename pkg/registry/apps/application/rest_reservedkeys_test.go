@@ -0,0 +1,46 @@
+package application
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+var _ = Describe("reserved keys", func() {
+	r := &REST{
+		gvk:          schema.GroupVersionKind{Group: "apps.cozystack.io", Version: "v1alpha1", Kind: "Test"},
+		reservedKeys: []string{"global.cozystack", "host"},
+	}
+
+	validate := func(spec string) error {
+		return r.validateReservedKeys(&appsv1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       &apiextv1.JSON{Raw: []byte(spec)},
+		})
+	}
+
+	It("accepts values without reserved keys", func() {
+		Expect(validate(`{"replicas":2,"global":{"storageClass":"local"}}`)).To(Succeed())
+	})
+
+	It("rejects reserved top-level keys", func() {
+		err := validate(`{"host":"example.org"}`)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.host"))
+	})
+
+	It("rejects reserved nested keys", func() {
+		err := validate(`{"global":{"cozystack":{"clusterDomain":"evil"}}}`)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.global.cozystack"))
+	})
+
+	It("ignores reserved paths through non-object values", func() {
+		Expect(validate(`{"global":"x"}`)).To(Succeed())
+	})
+})
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// Labels set by helm-controller on every object of a release, identifying its HelmRelease
+const (
+	helmReleaseNameLabel      = "helm.toolkit.fluxcd.io/name"
+	helmReleaseNamespaceLabel = "helm.toolkit.fluxcd.io/namespace"
+)
+
+// applicationResources returns the Deployments, StatefulSets and Services of
+// the release of hr, sorted by kind and name
+func (r *REST) applicationResources(ctx context.Context, hr *helmv2.HelmRelease) ([]appsv1alpha1.ApplicationResource, error) {
+	opts := []client.ListOption{
+		client.InNamespace(hr.GetReleaseNamespace()),
+		client.MatchingLabels{
+			helmReleaseNameLabel:      hr.Name,
+			helmReleaseNamespaceLabel: hr.Namespace,
+		},
+	}
+
+	var resources []appsv1alpha1.ApplicationResource
+	var deployments appsv1.DeploymentList
+	if err := r.c.List(ctx, &deployments, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list Deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		resources = append(resources, appsv1alpha1.ApplicationResource{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  d.Namespace,
+			Name:       d.Name,
+			Ready:      readyReplicas(d.Status.ReadyReplicas, d.Spec.Replicas),
+		})
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := r.c.List(ctx, &statefulSets, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list StatefulSets: %w", err)
+	}
+	for _, s := range statefulSets.Items {
+		resources = append(resources, appsv1alpha1.ApplicationResource{
+			APIVersion: "apps/v1",
+			Kind:       "StatefulSet",
+			Namespace:  s.Namespace,
+			Name:       s.Name,
+			Ready:      readyReplicas(s.Status.ReadyReplicas, s.Spec.Replicas),
+		})
+	}
+	var services corev1.ServiceList
+	if err := r.c.List(ctx, &services, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list Services: %w", err)
+	}
+	for _, s := range services.Items {
+		resources = append(resources, appsv1alpha1.ApplicationResource{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Namespace:  s.Namespace,
+			Name:       s.Name,
+		})
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Kind != resources[j].Kind {
+			return resources[i].Kind < resources[j].Kind
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	return resources, nil
+}
+
+// readyReplicas formats the ready and desired replicas of a workload
+func readyReplicas(ready int32, desired *int32) string {
+	want := int32(1)
+	if desired != nil {
+		want = *desired
+	}
+	return fmt.Sprintf("%d/%d", ready, want)
+}
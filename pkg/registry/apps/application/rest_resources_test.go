@@ -0,0 +1,48 @@
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+var _ = Describe("application resources", func() {
+	releaseLabels := map[string]string{
+		helmReleaseNameLabel:      "test-db",
+		helmReleaseNamespaceLabel: "tenant-root",
+	}
+	replicas := int32(3)
+
+	It("lists the workloads and services of the release", func() {
+		scheme := runtime.NewScheme()
+		Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "tenant-root", Labels: releaseLabels},
+				Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "db-rw", Namespace: "tenant-root", Labels: releaseLabels}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "tenant-root"}},
+		).Build()
+		r := &REST{c: c}
+
+		hr := &helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "test-db", Namespace: "tenant-root"}}
+		resources, err := r.applicationResources(context.Background(), hr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(Equal([]appsv1alpha1.ApplicationResource{
+			{APIVersion: "v1", Kind: "Service", Namespace: "tenant-root", Name: "db-rw"},
+			{APIVersion: "apps/v1", Kind: "StatefulSet", Namespace: "tenant-root", Name: "db", Ready: "2/3"},
+		}))
+	})
+})
@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// SubresourceRestorePoints lists the Backups an Application can be restored from
+const SubresourceRestorePoints = "restorepoints"
+
+var _ rest.Getter = &RestorePointsREST{}
+
+// RestorePointsREST implements the read-only restorepoints subresource. A GET
+// returns the Backups of the Application, newest first, with whether each of
+// them can be restored.
+type RestorePointsREST struct {
+	app *REST
+}
+
+// New creates a new instance of RestorePointList
+func (r *RestorePointsREST) New() runtime.Object {
+	return &appsv1alpha1.RestorePointList{}
+}
+
+// Destroy releases resources associated with RestorePointsREST
+func (r *RestorePointsREST) Destroy() {}
+
+// GroupVersionKind returns the GroupVersionKind of RestorePointList
+func (r *RestorePointsREST) GroupVersionKind(gv schema.GroupVersion) schema.GroupVersionKind {
+	return gv.WithKind("RestorePointList")
+}
+
+// Get lists the restore points of the Application name
+func (r *RestorePointsREST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	namespace, err := r.app.getNamespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.app.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+	if _, err := r.app.getHelmRelease(ctx, namespace, name); err != nil {
+		return nil, err
+	}
+
+	list := &appsv1alpha1.RestorePointList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "RestorePointList",
+		},
+		Items: []appsv1alpha1.RestorePoint{},
+	}
+	// Backups are read directly, so that the API server does not cache them and
+	// keeps working when the backups API is not installed
+	var backups backupsv1alpha1.BackupList
+	if err := r.app.w.List(ctx, &backups, client.InNamespace(namespace)); meta.IsNoMatchError(err) {
+		return list, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list Backups: %w", err)
+	}
+	list.ResourceVersion = backups.ResourceVersion
+
+	for i := range backups.Items {
+		b := &backups.Items[i]
+		ref := b.Spec.ApplicationRef
+		if ref.APIGroup == nil || *ref.APIGroup != appsv1alpha1.GroupName || ref.Kind != r.app.kindName || ref.Name != name {
+			continue
+		}
+		list.Items = append(list.Items, restorePoint(b))
+	}
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return list.Items[j].TakenAt.Before(&list.Items[i].TakenAt)
+	})
+	return list, nil
+}
+
+// restorePoint describes b as a restore point
+func restorePoint(b *backupsv1alpha1.Backup) appsv1alpha1.RestorePoint {
+	rp := appsv1alpha1.RestorePoint{
+		Backup:   b.Name,
+		TakenAt:  b.Spec.TakenAt,
+		Storage:  typedRefString(b.Spec.StorageRef),
+		Strategy: typedRefString(b.Spec.StrategyRef),
+	}
+	if b.Spec.PlanRef != nil {
+		rp.Plan = b.Spec.PlanRef.Name
+	}
+	if b.Status.Artifact != nil {
+		rp.SizeBytes = b.Status.Artifact.SizeBytes
+	}
+	for _, c := range b.Status.Copies {
+		cp := appsv1alpha1.RestorePointCopy{
+			Storage: typedRefString(c.StorageRef),
+			Phase:   string(c.Phase),
+		}
+		if c.Artifact != nil {
+			cp.SizeBytes = c.Artifact.SizeBytes
+		}
+		rp.Copies = append(rp.Copies, cp)
+	}
+	rp.Message = notRestorableReason(b)
+	rp.Restorable = rp.Message == ""
+	return rp
+}
+
+// notRestorableReason returns why a RestoreJob cannot be started from b, or an
+// empty string if it can. It mirrors the checks of the RestoreJob controller.
+func notRestorableReason(b *backupsv1alpha1.Backup) string {
+	if b.DeletionTimestamp != nil {
+		return "backup is being deleted"
+	}
+	if b.Status.Phase != backupsv1alpha1.BackupPhaseReady {
+		phase := string(b.Status.Phase)
+		if phase == "" {
+			phase = string(backupsv1alpha1.BackupPhasePending)
+		}
+		return fmt.Sprintf("backup is %s", phase)
+	}
+	if b.Spec.StrategyRef.APIGroup == nil || *b.Spec.StrategyRef.APIGroup != strategyv1alpha1.GroupVersion.Group {
+		return fmt.Sprintf("strategy %s does not support restores", typedRefString(b.Spec.StrategyRef))
+	}
+	switch b.Spec.StrategyRef.Kind {
+	case strategyv1alpha1.VeleroStrategyKind:
+		if b.Spec.DriverMetadata["velero.io/backup-name"] == "" {
+			return "backup has no velero.io/backup-name in driver metadata"
+		}
+	default:
+		return fmt.Sprintf("strategy %s does not support restores", b.Spec.StrategyRef.Kind)
+	}
+	return ""
+}
+
+// typedRefString formats ref as Kind/name
+func typedRefString(ref corev1.TypedLocalObjectReference) string {
+	return ref.Kind + "/" + ref.Name
+}
@@ -0,0 +1,66 @@
+package application
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	strategyv1alpha1 "github.com/cozystack/cozystack/api/backups/strategy/v1alpha1"
+	backupsv1alpha1 "github.com/cozystack/cozystack/api/backups/v1alpha1"
+)
+
+var _ = Describe("restorepoints subresource", func() {
+	strategyGroup := strategyv1alpha1.GroupVersion.Group
+	veleroBackup := func() *backupsv1alpha1.Backup {
+		return &backupsv1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-20250101"},
+			Spec: backupsv1alpha1.BackupSpec{
+				PlanRef:        &corev1.LocalObjectReference{Name: "nightly"},
+				StorageRef:     corev1.TypedLocalObjectReference{Kind: "Bucket", Name: "primary"},
+				StrategyRef:    corev1.TypedLocalObjectReference{APIGroup: &strategyGroup, Kind: strategyv1alpha1.VeleroStrategyKind, Name: "postgres"},
+				DriverMetadata: map[string]string{"velero.io/backup-name": "db-20250101"},
+			},
+			Status: backupsv1alpha1.BackupStatus{
+				Phase:    backupsv1alpha1.BackupPhaseReady,
+				Artifact: &backupsv1alpha1.BackupArtifact{URI: "s3://bucket/db", SizeBytes: 1024},
+				Copies: []backupsv1alpha1.BackupCopyStatus{{
+					StorageRef: corev1.TypedLocalObjectReference{Kind: "Bucket", Name: "offsite"},
+					Phase:      backupsv1alpha1.BackupPhaseReady,
+					Artifact:   &backupsv1alpha1.BackupArtifact{URI: "s3://offsite/db", SizeBytes: 1024},
+				}},
+			},
+		}
+	}
+
+	It("describes a ready Velero backup as restorable", func() {
+		rp := restorePoint(veleroBackup())
+		Expect(rp.Restorable).To(BeTrue())
+		Expect(rp.Message).To(BeEmpty())
+		Expect(rp.Plan).To(Equal("nightly"))
+		Expect(rp.Storage).To(Equal("Bucket/primary"))
+		Expect(rp.Strategy).To(Equal("Velero/postgres"))
+		Expect(rp.SizeBytes).To(BeEquivalentTo(1024))
+		Expect(rp.Copies).To(HaveLen(1))
+		Expect(rp.Copies[0].Storage).To(Equal("Bucket/offsite"))
+		Expect(rp.Copies[0].Phase).To(Equal("Ready"))
+	})
+
+	It("reports backups that are not ready", func() {
+		b := veleroBackup()
+		b.Status.Phase = backupsv1alpha1.BackupPhaseFailed
+		rp := restorePoint(b)
+		Expect(rp.Restorable).To(BeFalse())
+		Expect(rp.Message).To(Equal("backup is Failed"))
+	})
+
+	It("reports strategies without restore support", func() {
+		b := veleroBackup()
+		b.Spec.StrategyRef.Kind = "Job"
+		Expect(restorePoint(b).Restorable).To(BeFalse())
+
+		b = veleroBackup()
+		delete(b.Spec.DriverMetadata, "velero.io/backup-name")
+		Expect(restorePoint(b).Restorable).To(BeFalse())
+	})
+})
@@ -0,0 +1,260 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cozystack/cozystack/internal/template"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+// SubresourceScale reads and sets the number of replicas of an Application
+const SubresourceScale = "scale"
+
+// Ensure ScaleREST implements the necessary interfaces
+var _ rest.Patcher = &ScaleREST{}
+
+// ScaleREST implements the scale subresource of the kinds mapping it to their
+// values. The replicas of the Scale are read from and written to the values at
+// the configured replicas path; the status reports the same number, as the
+// replicas actually running are not observed.
+type ScaleREST struct {
+	app *REST
+}
+
+// New creates a new instance of Scale
+func (r *ScaleREST) New() runtime.Object {
+	return &autoscalingv1.Scale{}
+}
+
+// Destroy releases resources associated with ScaleREST
+func (r *ScaleREST) Destroy() {}
+
+// GroupVersionKind returns the GroupVersionKind of the Scale
+func (r *ScaleREST) GroupVersionKind(schema.GroupVersion) schema.GroupVersionKind {
+	return autoscalingv1.SchemeGroupVersion.WithKind("Scale")
+}
+
+// Get returns the Scale of the Application name
+func (r *ScaleREST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	namespace, err := r.app.getNamespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.app.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+	hr, err := r.app.getHelmRelease(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return r.app.scaleOf(hr)
+}
+
+// Update sets the replicas of the Application name
+func (r *ScaleREST) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	namespace, err := r.app.getNamespace(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := r.app.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, false, err
+	}
+	if err := r.app.limiter.accept(ctx, r.app.kindName); err != nil {
+		return nil, false, err
+	}
+
+	hr, err := r.app.getHelmRelease(ctx, namespace, name)
+	if err != nil {
+		return nil, false, err
+	}
+	oldScale, err := r.app.scaleOf(hr)
+	if err != nil {
+		return nil, false, err
+	}
+	obj, err := objInfo.UpdatedObject(ctx, oldScale)
+	if err != nil {
+		return nil, false, err
+	}
+	if updateValidation != nil {
+		if err := updateValidation(ctx, obj, oldScale); err != nil {
+			return nil, false, err
+		}
+	}
+	scale, ok := obj.(*autoscalingv1.Scale)
+	if !ok {
+		return nil, false, fmt.Errorf("expected *autoscalingv1.Scale object, got %T", obj)
+	}
+	if scale.Spec.Replicas < 0 {
+		return nil, false, apierrors.NewInvalid(autoscalingv1.SchemeGroupVersion.WithKind("Scale").GroupKind(), name, field.ErrorList{
+			field.Invalid(field.NewPath("spec", "replicas"), scale.Spec.Replicas, "must be greater than or equal to 0"),
+		})
+	}
+
+	if scale.ResourceVersion != "" {
+		hr.ResourceVersion = scale.ResourceVersion
+	}
+	if hr.Spec.Values, err = setValuesPath(hr.Spec.Values, config.ScalePath(r.app.scale.ReplicasPath), scale.Spec.Replicas); err != nil {
+		return nil, false, apierrors.NewBadRequest(err.Error())
+	}
+	if err := r.app.c.Update(ctx, hr, &client.UpdateOptions{Raw: &metav1.UpdateOptions{DryRun: options.DryRun}}); err != nil {
+		if apierrors.IsConflict(err) {
+			return nil, false, apierrors.NewConflict(r.app.gvr.GroupResource(), name,
+				fmt.Errorf("the %s has been modified since it was read; get the latest version and apply your changes again", r.app.kindName))
+		}
+		klog.Errorf("Failed to scale HelmRelease %s: %v", hr.Name, err)
+		if admissionErr := r.app.admissionError(err, hr.Name, name); admissionErr != nil {
+			return nil, false, admissionErr
+		}
+		return nil, false, err
+	}
+	r.app.writes.observe(hr)
+	klog.V(4).Infof("Scaled %s %s/%s to %d replicas", r.app.kindName, namespace, name, scale.Spec.Replicas)
+
+	updated, err := r.app.scaleOf(hr)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, false, nil
+}
+
+// scaleOf returns the Scale of the Application released by hr. Replicas not set
+// in the values fall back to the default of the schema.
+func (r *REST) scaleOf(hr *helmv2.HelmRelease) (*autoscalingv1.Scale, error) {
+	app, err := r.ConvertHelmReleaseToApplication(hr)
+	if err != nil {
+		return nil, fmt.Errorf("conversion error: %v", err)
+	}
+	if err := r.applySpecDefaults(&app); err != nil {
+		return nil, fmt.Errorf("failed to default values: %w", err)
+	}
+	path := config.ScalePath(r.scale.ReplicasPath)
+	replicas, err := valuesReplicas(app.Spec, path)
+	if err != nil {
+		return nil, fmt.Errorf("spec.%s: %w", strings.Join(path, "."), err)
+	}
+
+	scale := &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              app.Name,
+			Namespace:         app.Namespace,
+			UID:               app.UID,
+			ResourceVersion:   app.ResourceVersion,
+			CreationTimestamp: app.CreationTimestamp,
+		},
+		Spec:   autoscalingv1.ScaleSpec{Replicas: replicas},
+		Status: autoscalingv1.ScaleStatus{Replicas: replicas},
+	}
+	if r.scale.LabelSelector != "" {
+		sc, err := template.Template(r.scale, map[string]any{
+			"name":      app.Name,
+			"kind":      strings.ToLower(r.kindName),
+			"namespace": hr.GetReleaseNamespace(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to render scale label selector: %w", err)
+		}
+		selector, err := labels.Parse(sc.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scale label selector: %w", err)
+		}
+		scale.Status.Selector = selector.String()
+	}
+	return scale, nil
+}
+
+// valuesReplicas reads the number of replicas at path from values. A missing
+// value is 0 replicas.
+func valuesReplicas(values *apiextv1.JSON, path []string) (int32, error) {
+	if values == nil || len(values.Raw) == 0 {
+		return 0, nil
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(values.Raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return 0, err
+	}
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return 0, nil
+		}
+		if v, ok = m[key]; !ok {
+			return 0, nil
+		}
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("number of replicas is not a number")
+	}
+	replicas, err := n.Int64()
+	if err != nil || replicas < 0 || replicas > math.MaxInt32 {
+		return 0, fmt.Errorf("number of replicas %s is not a valid replica count", n)
+	}
+	return int32(replicas), nil
+}
+
+// setValuesPath returns values with the nested key path set to value, creating
+// the intermediate objects
+func setValuesPath(values *apiextv1.JSON, path []string, value any) (*apiextv1.JSON, error) {
+	m := map[string]any{}
+	if values != nil && len(values.Raw) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(values.Raw))
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("failed to decode values: %w", err)
+		}
+	}
+	parent := m
+	for i, key := range path[:len(path)-1] {
+		next, ok := parent[key].(map[string]any)
+		if !ok {
+			if _, exists := parent[key]; exists {
+				return nil, fmt.Errorf("spec.%s is not an object", strings.Join(path[:i+1], "."))
+			}
+			next = map[string]any{}
+			parent[key] = next
+		}
+		parent = next
+	}
+	parent[path[len(path)-1]] = value
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return &apiextv1.JSON{Raw: raw}, nil
+}
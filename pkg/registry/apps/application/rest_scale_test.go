@@ -0,0 +1,120 @@
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("scale subresource", func() {
+	var (
+		c     client.WithWatch
+		r     *REST
+		scale *ScaleREST
+	)
+	ctx := request.WithNamespace(context.Background(), "tenant-root")
+
+	newREST := func(values string) {
+		scheme := runtime.NewScheme()
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&helmv2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "tenant-root",
+				Name:      "test-db",
+				Labels: map[string]string{
+					ApplicationKindLabel:  "Test",
+					ApplicationGroupLabel: appsv1alpha1.GroupName,
+					ApplicationNameLabel:  "db",
+				},
+			},
+			Spec: helmv2.HelmReleaseSpec{Values: &apiextv1.JSON{Raw: []byte(values)}},
+		}).Build()
+		r = &REST{
+			c:             c,
+			w:             c,
+			gvr:           schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+			gvk:           schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+			scale:         &config.ScaleConfig{ReplicasPath: ".nodes.replicas", LabelSelector: "app.kubernetes.io/instance={{ .kind }}-{{ .name }}"},
+		}
+		scale = r.Subresources()[SubresourceScale].(*ScaleREST)
+	}
+	get := func() *autoscalingv1.Scale {
+		obj, err := scale.Get(ctx, "db", &metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return obj.(*autoscalingv1.Scale)
+	}
+	update := func(s *autoscalingv1.Scale) error {
+		_, _, err := scale.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(s), nil, nil, false, &metav1.UpdateOptions{})
+		return err
+	}
+
+	It("is only served for kinds with a scale mapping", func() {
+		newREST(`{}`)
+		r.scale = nil
+		Expect(r.Subresources()).NotTo(HaveKey(SubresourceScale))
+	})
+
+	It("reads the replicas and selector from the values", func() {
+		newREST(`{"nodes":{"replicas":3}}`)
+		s := get()
+		Expect(s.Name).To(Equal("db"))
+		Expect(s.Spec.Replicas).To(BeEquivalentTo(3))
+		Expect(s.Status.Replicas).To(BeEquivalentTo(3))
+		Expect(s.Status.Selector).To(Equal("app.kubernetes.io/instance=test-db"))
+	})
+
+	It("writes the replicas to the values", func() {
+		newREST(`{"nodes":{"replicas":3,"size":"1Gi"},"big":12345678901234567890}`)
+		s := get()
+		s.Spec.Replicas = 5
+		Expect(update(s)).To(Succeed())
+
+		hr := &helmv2.HelmRelease{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "tenant-root", Name: "test-db"}, hr)).To(Succeed())
+		Expect(hr.Spec.Values.Raw).To(MatchJSON(`{"nodes":{"replicas":5,"size":"1Gi"},"big":12345678901234567890}`))
+		Expect(get().Spec.Replicas).To(BeEquivalentTo(5))
+	})
+
+	It("creates missing objects on the replicas path", func() {
+		newREST(`{}`)
+		s := get()
+		Expect(s.Spec.Replicas).To(BeZero())
+		s.Spec.Replicas = 2
+		Expect(update(s)).To(Succeed())
+		Expect(get().Spec.Replicas).To(BeEquivalentTo(2))
+	})
+
+	It("rejects negative replicas", func() {
+		newREST(`{"nodes":{"replicas":3}}`)
+		s := get()
+		s.Spec.Replicas = -1
+		Expect(apierrors.IsInvalid(update(s))).To(BeTrue())
+	})
+
+	It("rejects updates of a stale resource version", func() {
+		newREST(`{"nodes":{"replicas":3}}`)
+		stale := get()
+		s := get()
+		s.Spec.Replicas = 4
+		Expect(update(s)).To(Succeed())
+		stale.Spec.Replicas = 5
+		Expect(apierrors.IsConflict(update(stale))).To(BeTrue())
+	})
+})
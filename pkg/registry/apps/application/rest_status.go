@@ -0,0 +1,189 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/pkg/apis/meta"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// SubresourceStatus updates the conditions of an Application without touching its spec
+const SubresourceStatus = "status"
+
+// StatusConditionsAnnotation holds on the HelmRelease the Application conditions
+// reported through the status subresource, as a JSON list
+const StatusConditionsAnnotation = "apps.cozystack.io/status-conditions"
+
+// Ensure StatusREST implements the necessary interfaces
+var _ rest.Patcher = &StatusREST{}
+
+// isPlatformCondition reports whether conditions of type t are derived from the
+// HelmRelease. They are always computed and never stored.
+func isPlatformCondition(t string) bool {
+	switch t {
+	case meta.ReadyCondition, helmv2.ReleasedCondition, helmv2.RemediatedCondition, ConditionDriftDetected:
+		return true
+	}
+	return false
+}
+
+// reportedConditions returns the conditions of conditions not derived from the HelmRelease
+func reportedConditions(conditions []metav1.Condition) []metav1.Condition {
+	var reported []metav1.Condition
+	for _, c := range conditions {
+		if !isPlatformCondition(c.Type) {
+			reported = append(reported, c)
+		}
+	}
+	return reported
+}
+
+// storedConditions returns the conditions reported through the status
+// subresource from the annotation of hr
+func storedConditions(hr *helmv2.HelmRelease) []metav1.Condition {
+	value, ok := hr.Annotations[StatusConditionsAnnotation]
+	if !ok {
+		return nil
+	}
+	var conditions []metav1.Condition
+	if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+		klog.Warningf("Ignoring malformed %s annotation of HelmRelease %s/%s: %v", StatusConditionsAnnotation, hr.Namespace, hr.Name, err)
+		return nil
+	}
+	return reportedConditions(conditions)
+}
+
+// storeConditions records the reported conditions in the annotation of hr
+func storeConditions(hr *helmv2.HelmRelease, conditions []metav1.Condition) error {
+	if len(conditions) == 0 {
+		delete(hr.Annotations, StatusConditionsAnnotation)
+		return nil
+	}
+	data, err := json.Marshal(conditions)
+	if err != nil {
+		return err
+	}
+	if hr.Annotations == nil {
+		hr.Annotations = make(map[string]string)
+	}
+	hr.Annotations[StatusConditionsAnnotation] = string(data)
+	return nil
+}
+
+// StatusREST implements the status subresource. An update of an Application
+// stores the conditions of its status other than those derived from the
+// HelmRelease, which are ignored along with the other status fields and the
+// rest of the object. The values of the HelmRelease are left untouched.
+type StatusREST struct {
+	app *REST
+}
+
+// New creates a new instance of Application
+func (r *StatusREST) New() runtime.Object {
+	return r.app.New()
+}
+
+// Destroy releases resources associated with StatusREST
+func (r *StatusREST) Destroy() {}
+
+// GroupVersionKind returns the GroupVersionKind of the parent resource
+func (r *StatusREST) GroupVersionKind(gv schema.GroupVersion) schema.GroupVersionKind {
+	return r.app.GroupVersionKind(gv)
+}
+
+// Get retrieves the Application name
+func (r *StatusREST) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	return r.app.Get(ctx, name, options)
+}
+
+// Update replaces the reported conditions of the Application name
+func (r *StatusREST) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	namespace, err := r.app.getNamespace(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := r.app.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, false, err
+	}
+	if err := r.app.limiter.accept(ctx, r.app.kindName); err != nil {
+		return nil, false, err
+	}
+
+	hr, err := r.app.getHelmRelease(ctx, namespace, name)
+	if err != nil {
+		return nil, false, err
+	}
+	oldApp, err := r.app.ConvertHelmReleaseToApplication(hr)
+	if err != nil {
+		return nil, false, fmt.Errorf("conversion error: %v", err)
+	}
+	newObj, err := objInfo.UpdatedObject(ctx, &oldApp)
+	if err != nil {
+		return nil, false, err
+	}
+	if updateValidation != nil {
+		if err := updateValidation(ctx, newObj, &oldApp); err != nil {
+			return nil, false, err
+		}
+	}
+	app, ok := newObj.(*appsv1alpha1.Application)
+	if !ok {
+		return nil, false, fmt.Errorf("expected *appsv1alpha1.Application object, got %T", newObj)
+	}
+
+	conditions := reportedConditions(app.Status.Conditions)
+	if errs := metav1validation.ValidateConditions(conditions, field.NewPath("status", "conditions")); len(errs) > 0 {
+		return nil, false, apierrors.NewInvalid(r.app.gvk.GroupKind(), name, errs)
+	}
+	if app.ResourceVersion != "" {
+		hr.ResourceVersion = app.ResourceVersion
+	}
+	if err := storeConditions(hr, conditions); err != nil {
+		return nil, false, err
+	}
+	if err := r.app.c.Update(ctx, hr, &client.UpdateOptions{Raw: &metav1.UpdateOptions{DryRun: options.DryRun}}); err != nil {
+		if apierrors.IsConflict(err) {
+			return nil, false, apierrors.NewConflict(r.app.gvr.GroupResource(), name,
+				fmt.Errorf("the %s has been modified since it was read; get the latest version and apply your changes again", r.app.kindName))
+		}
+		klog.Errorf("Failed to update status of HelmRelease %s: %v", hr.Name, err)
+		return nil, false, err
+	}
+	r.app.writes.observe(hr)
+	klog.V(4).Infof("Updated status of %s %s/%s", r.app.kindName, namespace, name)
+
+	updated, err := r.app.ConvertHelmReleaseToApplication(hr)
+	if err != nil {
+		return nil, false, fmt.Errorf("conversion error: %v", err)
+	}
+	return &updated, false, nil
+}
@@ -0,0 +1,119 @@
+package application
+
+import (
+	"context"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("status subresource", func() {
+	var (
+		c      client.WithWatch
+		r      *REST
+		status *StatusREST
+	)
+	ctx := request.WithNamespace(context.Background(), "tenant-root")
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&helmv2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "tenant-root",
+				Name:      "test-db",
+				Labels: map[string]string{
+					ApplicationKindLabel:  "Test",
+					ApplicationGroupLabel: appsv1alpha1.GroupName,
+					ApplicationNameLabel:  "db",
+				},
+			},
+			Spec: helmv2.HelmReleaseSpec{Values: &apiextv1.JSON{Raw: []byte(`{"size":"1Gi"}`)}},
+		}).Build()
+		r = &REST{
+			c:             c,
+			w:             c,
+			gvr:           schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+			gvk:           schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+			writes:        NewWriteCoordinator(3),
+		}
+		status = r.Subresources()[SubresourceStatus].(*StatusREST)
+	})
+
+	get := func() *appsv1alpha1.Application {
+		obj, err := r.Get(ctx, "db", &metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return obj.(*appsv1alpha1.Application)
+	}
+	condition := func(t string) metav1.Condition {
+		return metav1.Condition{Type: t, Status: metav1.ConditionTrue, Reason: "Checked", LastTransitionTime: metav1.Now()}
+	}
+	report := func(app *appsv1alpha1.Application, conditions ...metav1.Condition) error {
+		app.Status.Conditions = conditions
+		_, _, err := status.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+		return err
+	}
+
+	It("stores reported conditions without touching the spec", func() {
+		app := get()
+		app.Spec = &apiextv1.JSON{Raw: []byte(`{"size":"2Gi"}`)}
+		Expect(report(app, condition("BackupHealthy"), condition("Ready"))).To(Succeed())
+
+		app = get()
+		Expect(app.Spec.Raw).To(MatchJSON(`{"size":"1Gi"}`))
+		Expect(meta.FindStatusCondition(app.Status.Conditions, "BackupHealthy")).NotTo(BeNil())
+		Expect(meta.FindStatusCondition(app.Status.Conditions, "Ready")).To(BeNil())
+	})
+
+	It("keeps reported conditions across spec updates", func() {
+		Expect(report(get(), condition("BackupHealthy"))).To(Succeed())
+
+		app := get()
+		app.Spec = &apiextv1.JSON{Raw: []byte(`{"size":"2Gi"}`)}
+		app.Status.Conditions = nil
+		_, _, err := r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		app = get()
+		Expect(app.Spec.Raw).To(MatchJSON(`{"size":"2Gi"}`))
+		Expect(meta.FindStatusCondition(app.Status.Conditions, "BackupHealthy")).NotTo(BeNil())
+	})
+
+	It("applies spec updates read before a status update", func() {
+		app := get()
+		Expect(report(get(), condition("BackupHealthy"))).To(Succeed())
+
+		app.Spec = &apiextv1.JSON{Raw: []byte(`{"size":"2Gi"}`)}
+		_, _, err := r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(meta.FindStatusCondition(get().Status.Conditions, "BackupHealthy")).NotTo(BeNil())
+	})
+
+	It("rejects invalid conditions", func() {
+		err := report(get(), metav1.Condition{Type: "BackupHealthy", Status: "Maybe"})
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("status.conditions"))
+	})
+
+	It("rejects updates of a stale resource version", func() {
+		app := get()
+		Expect(report(get(), condition("BackupHealthy"))).To(Succeed())
+		Expect(apierrors.IsConflict(report(app, condition("BackupHealthy")))).To(BeTrue())
+	})
+})
@@ -0,0 +1,364 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+// Subresources of Applications. Each one is a distinct resource for RBAC, so that
+// tenants can be granted e.g. the create verb on postgreses/reconcile without
+// being allowed to update the spec of postgreses.
+const (
+	// SubresourceReconcile requests an immediate reconciliation of the Application
+	SubresourceReconcile = "reconcile"
+	// SubresourceRollback restores the values of a previous Helm release revision
+	SubresourceRollback = "rollback"
+)
+
+// AnnotationRollbackRevision selects the Helm release revision restored by the
+// rollback subresource. It is read from the request body only and never stored.
+const AnnotationRollbackRevision = "apps.cozystack.io/rollback-revision"
+
+// Ensure the subresources implement the necessary interfaces
+var (
+	_ rest.NamedCreater = &ReconcileREST{}
+	_ rest.NamedCreater = &RollbackREST{}
+)
+
+// Subresources returns the storages of the Application subresources keyed by their name
+func (r *REST) Subresources() map[string]rest.Storage {
+	subresources := map[string]rest.Storage{
+		SubresourceReconcile:     &ReconcileREST{app: r},
+		SubresourceRollback:      &RollbackREST{app: r},
+		SubresourceRestorePoints: &RestorePointsREST{app: r},
+		SubresourceBackups:       &BackupsREST{app: r},
+		SubresourceRestores:      &RestoresREST{app: r},
+		SubresourceStatus:        &StatusREST{app: r},
+	}
+	if r.scale != nil {
+		subresources[SubresourceScale] = &ScaleREST{app: r}
+	}
+	return subresources
+}
+
+// getHelmRelease returns the HelmRelease of the Application name in namespace
+func (r *REST) getHelmRelease(ctx context.Context, namespace, name string) (*helmv2.HelmRelease, error) {
+	hr := &helmv2.HelmRelease{}
+	err := r.c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: r.releaseConfig.Prefix + name}, hr)
+	if apierrors.IsNotFound(err) {
+		return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
+	} else if err != nil {
+		return nil, err
+	}
+	if !r.hasRequiredApplicationLabels(hr) {
+		return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
+	}
+	return hr, nil
+}
+
+// ReconcileREST implements the reconcile subresource. A POST of an Application
+// requests a reconciliation of its HelmRelease; the reconcile hint annotations
+// of the body select a forced upgrade or a reset of the failure counters.
+// Only the Flux annotations of the HelmRelease are changed.
+type ReconcileREST struct {
+	app *REST
+}
+
+// New creates a new instance of Application
+func (r *ReconcileREST) New() runtime.Object {
+	return r.app.New()
+}
+
+// Destroy releases resources associated with ReconcileREST
+func (r *ReconcileREST) Destroy() {}
+
+// GroupVersionKind returns the GroupVersionKind of the parent resource
+func (r *ReconcileREST) GroupVersionKind(gv schema.GroupVersion) schema.GroupVersionKind {
+	return r.app.GroupVersionKind(gv)
+}
+
+// Create requests a reconciliation of the Application name
+func (r *ReconcileREST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	namespace, err := r.app.getNamespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.app.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+	if err := r.app.limiter.accept(ctx, r.app.kindName); err != nil {
+		return nil, err
+	}
+	var annotations map[string]string
+	if app, ok := obj.(*appsv1alpha1.Application); ok {
+		annotations = app.Annotations
+	}
+	token, err := reconcileHintToken(annotations)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	if token == "" {
+		token = time.Now().UTC().Format(time.RFC3339Nano)
+		annotations = map[string]string{AnnotationReconcile: token}
+	}
+
+	hr, err := r.app.getHelmRelease(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	patch := client.MergeFrom(hr.DeepCopy())
+	if err := applyReconcileHints(annotations, hr); err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	if err := r.app.c.Patch(ctx, hr, patch, &client.PatchOptions{Raw: &metav1.PatchOptions{DryRun: options.DryRun}}); err != nil {
+		klog.Errorf("Failed to request reconciliation of HelmRelease %s: %v", hr.Name, err)
+		return nil, err
+	}
+	klog.V(4).Infof("Requested reconciliation of %s %s/%s with token %s", r.app.kindName, namespace, name, token)
+
+	app, err := r.app.ConvertHelmReleaseToApplication(hr)
+	if err != nil {
+		return nil, fmt.Errorf("conversion error: %v", err)
+	}
+	return &app, nil
+}
+
+// RollbackREST implements the rollback subresource. A POST of an Application
+// restores the values of a previous Helm release revision to its spec: the
+// revision given in the AnnotationRollbackRevision annotation of the body, or
+// the last successfully deployed revision before the current one.
+type RollbackREST struct {
+	app *REST
+}
+
+// New creates a new instance of Application
+func (r *RollbackREST) New() runtime.Object {
+	return r.app.New()
+}
+
+// Destroy releases resources associated with RollbackREST
+func (r *RollbackREST) Destroy() {}
+
+// GroupVersionKind returns the GroupVersionKind of the parent resource
+func (r *RollbackREST) GroupVersionKind(gv schema.GroupVersion) schema.GroupVersionKind {
+	return r.app.GroupVersionKind(gv)
+}
+
+// Create rolls the Application name back to a previous revision
+func (r *RollbackREST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	namespace, err := r.app.getNamespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.app.authorizeTenantNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+	if err := r.app.limiter.accept(ctx, r.app.kindName); err != nil {
+		return nil, err
+	}
+	revision := 0
+	if app, ok := obj.(*appsv1alpha1.Application); ok {
+		if value, ok := app.Annotations[AnnotationRollbackRevision]; ok {
+			if revision, err = strconv.Atoi(value); err != nil || revision < 1 {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("annotation %q must be a positive revision number", AnnotationRollbackRevision))
+			}
+		}
+	}
+
+	hr, err := r.app.getHelmRelease(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := r.app.listHelmReleaseRevisions(ctx, hr)
+	if err != nil {
+		klog.Errorf("Failed to list revisions of HelmRelease %s: %v", hr.Name, err)
+		return nil, err
+	}
+	target, err := rollbackTarget(releases, revision)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	values, err := r.app.rollbackValues(hr.Spec.Values, target.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := client.MergeFrom(hr.DeepCopy())
+	hr.Spec.Values = values
+	if err := r.app.c.Patch(ctx, hr, patch, &client.PatchOptions{Raw: &metav1.PatchOptions{DryRun: options.DryRun}}); err != nil {
+		klog.Errorf("Failed to roll back HelmRelease %s: %v", hr.Name, err)
+		return nil, err
+	}
+	klog.V(4).Infof("Rolled back %s %s/%s to revision %d", r.app.kindName, namespace, name, target.Version)
+
+	app, err := r.app.ConvertHelmReleaseToApplication(hr)
+	if err != nil {
+		return nil, fmt.Errorf("conversion error: %v", err)
+	}
+	return &app, nil
+}
+
+// helmReleaseRevision is the part of a Helm release record used for rollbacks
+type helmReleaseRevision struct {
+	Version int `json:"version"`
+	Info    struct {
+		Status string `json:"status"`
+	} `json:"info"`
+	Config map[string]interface{} `json:"config"`
+}
+
+// listHelmReleaseRevisions reads the revisions of the Helm release of hr from
+// the Helm storage secrets, newest first
+func (r *REST) listHelmReleaseRevisions(ctx context.Context, hr *helmv2.HelmRelease) ([]helmReleaseRevision, error) {
+	var secrets corev1.SecretList
+	if err := r.c.List(ctx, &secrets, client.InNamespace(hr.GetStorageNamespace()),
+		client.MatchingLabels{"owner": "helm", "name": hr.GetReleaseName()}); err != nil {
+		return nil, err
+	}
+	releases := make([]helmReleaseRevision, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		release, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Helm release %s: %w", secret.Name, err)
+		}
+		releases = append(releases, release)
+	}
+	sort.Slice(releases, func(i, j int) bool { return releases[i].Version > releases[j].Version })
+	return releases, nil
+}
+
+// decodeHelmRelease decodes a release record as stored by Helm: base64-encoded
+// JSON, usually gzip-compressed
+func decodeHelmRelease(data []byte) (helmReleaseRevision, error) {
+	var release helmReleaseRevision
+	raw, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return release, err
+	}
+	if bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return release, err
+		}
+		defer zr.Close()
+		if raw, err = io.ReadAll(zr); err != nil {
+			return release, err
+		}
+	}
+	err = json.Unmarshal(raw, &release)
+	return release, err
+}
+
+// rollbackTarget selects the revision to roll back to from releases sorted newest
+// first: revision if set, otherwise the newest deployed or superseded revision
+// before the current one
+func rollbackTarget(releases []helmReleaseRevision, revision int) (helmReleaseRevision, error) {
+	if len(releases) == 0 {
+		return helmReleaseRevision{}, fmt.Errorf("release has no revisions")
+	}
+	for _, release := range releases {
+		if revision != 0 {
+			if release.Version == revision {
+				return release, nil
+			}
+			continue
+		}
+		if release.Version == releases[0].Version {
+			continue
+		}
+		if release.Info.Status == "deployed" || release.Info.Status == "superseded" {
+			return release, nil
+		}
+	}
+	if revision != 0 {
+		return helmReleaseRevision{}, fmt.Errorf("revision %d not found", revision)
+	}
+	return helmReleaseRevision{}, fmt.Errorf("no previous successful revision to roll back to")
+}
+
+// rollbackValues returns the HelmRelease values restoring the user values of a
+// previous revision. Helm records the values merged with valuesFrom, so internal
+// keys and reserved keys are left out of config, and the internal keys of the
+// current values are kept.
+func (r *REST) rollbackValues(current *apiextv1.JSON, config map[string]interface{}) (*apiextv1.JSON, error) {
+	values := map[string]interface{}{}
+	for key, value := range config {
+		if !strings.HasPrefix(key, "_") {
+			values[key] = value
+		}
+	}
+	for _, key := range r.reservedKeys {
+		deleteValuesPath(values, strings.Split(key, "."))
+	}
+	if current != nil && len(current.Raw) > 0 {
+		var data map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(current.Raw))
+		dec.UseNumber()
+		if err := dec.Decode(&data); err != nil {
+			return nil, fmt.Errorf("failed to decode current values: %w", err)
+		}
+		for key, value := range data {
+			if strings.HasPrefix(key, "_") {
+				values[key] = value
+			}
+		}
+	}
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return &apiextv1.JSON{Raw: raw}, nil
+}
+
+// deleteValuesPath removes the nested key path from values
+func deleteValuesPath(values map[string]interface{}, path []string) {
+	for i, key := range path {
+		if i == len(path)-1 {
+			delete(values, key)
+			return
+		}
+		next, ok := values[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		values = next
+	}
+}
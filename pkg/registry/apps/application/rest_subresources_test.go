@@ -0,0 +1,68 @@
+package application
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+var _ = Describe("rollback subresource", func() {
+	revision := func(version int, status string) helmReleaseRevision {
+		r := helmReleaseRevision{Version: version}
+		r.Info.Status = status
+		return r
+	}
+
+	It("decodes gzip-compressed Helm releases", func() {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write([]byte(`{"version":3,"info":{"status":"superseded"},"config":{"replicas":2}}`))
+		Expect(zw.Close()).To(Succeed())
+
+		release, err := decodeHelmRelease([]byte(base64.StdEncoding.EncodeToString(buf.Bytes())))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(release.Version).To(Equal(3))
+		Expect(release.Info.Status).To(Equal("superseded"))
+		Expect(release.Config).To(HaveKeyWithValue("replicas", BeEquivalentTo(2)))
+	})
+
+	It("rolls back to the previous successful revision", func() {
+		releases := []helmReleaseRevision{revision(4, "deployed"), revision(3, "failed"), revision(2, "superseded")}
+		target, err := rollbackTarget(releases, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target.Version).To(Equal(2))
+	})
+
+	It("rolls back to the requested revision", func() {
+		releases := []helmReleaseRevision{revision(4, "deployed"), revision(3, "superseded"), revision(2, "superseded")}
+		target, err := rollbackTarget(releases, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(target.Version).To(Equal(2))
+
+		_, err = rollbackTarget(releases, 7)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails without a previous revision", func() {
+		_, err := rollbackTarget([]helmReleaseRevision{revision(1, "deployed")}, 0)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("restores user values only", func() {
+		r := &REST{reservedKeys: []string{"backup.s3"}}
+		values, err := r.rollbackValues(
+			&apiextv1.JSON{Raw: []byte(`{"_cluster":{"name":"new"},"replicas":3}`)},
+			map[string]interface{}{
+				"_cluster": map[string]interface{}{"name": "old"},
+				"replicas": 2,
+				"backup":   map[string]interface{}{"s3": "x", "enabled": true},
+			},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values.Raw).To(MatchJSON(`{"_cluster":{"name":"new"},"replicas":2,"backup":{"enabled":true}}`))
+	})
+})
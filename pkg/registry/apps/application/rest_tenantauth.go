@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+const (
+	tenantPrefix      = "tenant-"
+	rootTenant        = "tenant-root"
+	clusterAdminGroup = "cozystack-cluster-admin"
+)
+
+// tenantAccessLevels are the suffixes of the per-tenant groups, see
+// cozy-lib.rbac.subjectsForTenantAndAccessLevel
+var tenantAccessLevels = []string{"view", "use", "admin", "super-admin"}
+
+// authorizeTenantNamespace checks, in addition to RBAC, that namespace lies within
+// the tenant subtree of the requesting user. A tenant subtree is the tenant
+// namespace and the namespaces of all its child tenants, e.g. tenant-foo covers
+// tenant-foo and tenant-foo-bar, while tenant-root covers every tenant.
+//
+// The check only applies to users identified as tenant members, through a
+// tenant group or a tenant service account. Cluster administrators and users
+// without a tenant identity, such as system components, are left to RBAC alone.
+// An empty namespace denotes a request across all namespaces, which tenant
+// members are not allowed to make.
+func (r *REST) authorizeTenantNamespace(ctx context.Context, namespace string) error {
+	u, ok := request.UserFrom(ctx)
+	if !ok {
+		return nil
+	}
+	tenants, restricted := userTenants(u)
+	if !restricted {
+		return nil
+	}
+	for _, tenant := range tenants {
+		if namespace != "" && inTenantSubtree(tenant, namespace) {
+			return nil
+		}
+	}
+
+	verb := "access"
+	if info, ok := request.RequestInfoFrom(ctx); ok && info.Verb != "" {
+		verb = info.Verb
+	}
+	if namespace == "" {
+		return apierrors.NewForbidden(r.gvr.GroupResource(), "",
+			fmt.Errorf("tenant members cannot %s %s across all namespaces", verb, r.gvr.Resource))
+	}
+	return apierrors.NewForbidden(r.gvr.GroupResource(), "",
+		fmt.Errorf("namespace %s is outside the tenants of user %s", namespace, u.GetName()))
+}
+
+// userTenants returns the tenant namespaces the user is a member of. restricted
+// is false for users to whom the tenant boundary check does not apply.
+func userTenants(u user.Info) (tenants []string, restricted bool) {
+	for _, group := range u.GetGroups() {
+		if group == user.SystemPrivilegedGroup || group == clusterAdminGroup {
+			return nil, false
+		}
+	}
+
+	for _, group := range u.GetGroups() {
+		if !strings.HasPrefix(group, tenantPrefix) {
+			continue
+		}
+		// Tenant names cannot contain dashes, but a tenant named "super" makes
+		// "-super-admin" ambiguous, so every matching level is considered
+		for _, level := range tenantAccessLevels {
+			if tenant, ok := strings.CutSuffix(group, "-"+level); ok && strings.HasPrefix(tenant, tenantPrefix) {
+				tenants = append(tenants, tenant)
+			}
+		}
+	}
+
+	// Every tenant has a service account named after its namespace
+	if namespace, name, err := serviceAccountFromUsername(u.GetName()); err == nil &&
+		namespace == name && strings.HasPrefix(namespace, tenantPrefix) {
+		tenants = append(tenants, namespace)
+	}
+
+	return tenants, len(tenants) > 0
+}
+
+// serviceAccountFromUsername splits a service account username into namespace and name
+func serviceAccountFromUsername(username string) (namespace, name string, err error) {
+	rest, ok := strings.CutPrefix(username, "system:serviceaccount:")
+	if !ok {
+		return "", "", fmt.Errorf("%s is not a service account", username)
+	}
+	namespace, name, ok = strings.Cut(rest, ":")
+	if !ok || namespace == "" || name == "" {
+		return "", "", fmt.Errorf("%s is not a service account", username)
+	}
+	return namespace, name, nil
+}
+
+// inTenantSubtree reports whether namespace belongs to tenant or one of its descendants
+func inTenantSubtree(tenant, namespace string) bool {
+	if namespace == tenant {
+		return true
+	}
+	if tenant == rootTenant {
+		// Children of the root tenant are named tenant-<name>, not tenant-root-<name>
+		return strings.HasPrefix(namespace, tenantPrefix)
+	}
+	return strings.HasPrefix(namespace, tenant+"-")
+}
@@ -0,0 +1,53 @@
+package application
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+var _ = Describe("tenant boundary authorization", func() {
+	r := &REST{gvr: schema.GroupVersionResource{Group: "apps.cozystack.io", Version: "v1alpha1", Resource: "postgreses"}}
+
+	authorize := func(u user.Info, namespace string) error {
+		return r.authorizeTenantNamespace(request.WithUser(request.NewContext(), u), namespace)
+	}
+
+	It("allows namespaces in the tenant subtree", func() {
+		u := &user.DefaultInfo{Name: "alice", Groups: []string{"tenant-foo-admin", user.AllAuthenticated}}
+		Expect(authorize(u, "tenant-foo")).To(Succeed())
+		Expect(authorize(u, "tenant-foo-bar")).To(Succeed())
+	})
+
+	It("denies namespaces of other tenants", func() {
+		u := &user.DefaultInfo{Name: "alice", Groups: []string{"tenant-foo-use"}}
+		err := authorize(u, "tenant-foobar")
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(apierrors.IsForbidden(authorize(u, "tenant-root"))).To(BeTrue())
+	})
+
+	It("denies requests across all namespaces to tenant members", func() {
+		u := &user.DefaultInfo{Name: "alice", Groups: []string{"tenant-root-view"}}
+		Expect(apierrors.IsForbidden(authorize(u, ""))).To(BeTrue())
+	})
+
+	It("lets the root tenant access every tenant", func() {
+		u := &user.DefaultInfo{Name: "system:serviceaccount:tenant-root:tenant-root"}
+		Expect(authorize(u, "tenant-foo-bar")).To(Succeed())
+		Expect(apierrors.IsForbidden(authorize(u, "cozy-system"))).To(BeTrue())
+	})
+
+	It("recognizes tenant service accounts", func() {
+		u := &user.DefaultInfo{Name: "system:serviceaccount:tenant-foo:tenant-foo"}
+		Expect(authorize(u, "tenant-foo-bar")).To(Succeed())
+		Expect(apierrors.IsForbidden(authorize(u, "tenant-baz"))).To(BeTrue())
+	})
+
+	It("leaves users without a tenant identity to RBAC", func() {
+		Expect(authorize(&user.DefaultInfo{Name: "system:serviceaccount:cozy-dashboard:incloud-web"}, "tenant-foo")).To(Succeed())
+		Expect(authorize(&user.DefaultInfo{Name: "admin", Groups: []string{"tenant-foo-admin", "cozystack-cluster-admin"}}, "")).To(Succeed())
+	})
+})
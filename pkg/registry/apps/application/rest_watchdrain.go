@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"sync"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+)
+
+// WatchDrainer tracks the Application watches served by the API server, so that
+// they can be closed gracefully on shutdown instead of being cut with the
+// connection
+type WatchDrainer struct {
+	mu       sync.Mutex
+	draining bool
+	watches  map[*customWatcher]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewWatchDrainer returns a WatchDrainer accepting new watches
+func NewWatchDrainer() *WatchDrainer {
+	return &WatchDrainer{watches: map[*customWatcher]struct{}{}}
+}
+
+// SetWatchDrainer makes Watch register its watches with d
+func (r *REST) SetWatchDrainer(d *WatchDrainer) {
+	r.drainer = d
+}
+
+// register tracks cw until it is released. It fails once draining has started,
+// so that clients retry against another replica.
+func (d *WatchDrainer) register(cw *customWatcher) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return apierrors.NewTooManyRequests("the server is shutting down, please try again later", 1)
+	}
+	d.watches[cw] = struct{}{}
+	d.wg.Add(1)
+	return nil
+}
+
+// release stops tracking cw
+func (d *WatchDrainer) release(cw *customWatcher) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.watches[cw]; !ok {
+		return
+	}
+	delete(d.watches, cw)
+	d.wg.Done()
+}
+
+// Drain stops accepting new watches, asks the active ones to send a final
+// bookmark and close, and waits for them to finish until ctx is done.
+func (d *WatchDrainer) Drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	active := len(d.watches)
+	for cw := range d.watches {
+		cw.drain()
+	}
+	d.mu.Unlock()
+
+	klog.Infof("Draining %d Application watch(es)", active)
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		klog.Infof("Application watches drained")
+		return nil
+	case <-ctx.Done():
+		d.mu.Lock()
+		remaining := len(d.watches)
+		d.mu.Unlock()
+		klog.Warningf("Timed out draining Application watches, %d still active", remaining)
+		return nil
+	}
+}
+
+// drain asks the watch to close after the event being converted, if any
+func (cw *customWatcher) drain() {
+	cw.drainOnce.Do(func() {
+		close(cw.drainChan)
+	})
+}
+
+// bookmarkEvent returns the bookmark sent before a drained watch closes, so
+// that clients resume from the last delivered resource version instead of
+// relisting
+func bookmarkEvent(gvk schema.GroupVersionKind, resourceVersion string) watch.Event {
+	return watch.Event{
+		Type: watch.Bookmark,
+		Object: &appsv1alpha1.Application{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: gvk.GroupVersion().String(),
+				Kind:       gvk.Kind,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: resourceVersion,
+			},
+		},
+	}
+}
+
+// sendBookmark delivers a bookmark at resourceVersion if the client asked for
+// bookmarks and an event has been delivered or a starting point was requested
+func (cw *customWatcher) sendBookmark(ctx context.Context, gvk schema.GroupVersionKind, allowBookmarks bool, resourceVersion string) {
+	if !allowBookmarks || resourceVersion == "" {
+		return
+	}
+	select {
+	case cw.resultChan <- bookmarkEvent(gvk, resourceVersion):
+	case <-cw.stopChan:
+	case <-ctx.Done():
+	}
+}
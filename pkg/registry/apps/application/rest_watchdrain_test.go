@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+var _ = Describe("watch draining", func() {
+	newREST := func(drainer *WatchDrainer) *REST {
+		scheme := runtime.NewScheme()
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		w := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &REST{
+			w:        w,
+			gvk:      schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Postgres"},
+			kindName: "Postgres",
+		}
+		r.SetWatchDrainer(drainer)
+		return r
+	}
+	ctx := request.WithNamespace(context.Background(), "tenant-root")
+
+	It("closes active watches with a bookmark and rejects new ones", func() {
+		drainer := NewWatchDrainer()
+		r := newREST(drainer)
+
+		w, err := r.Watch(ctx, &metainternalversion.ListOptions{ResourceVersion: "42", AllowWatchBookmarks: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		drained := make(chan error)
+		go func() {
+			drainCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			drained <- drainer.Drain(drainCtx)
+		}()
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Bookmark))
+		Expect(event.Object.(*appsv1alpha1.Application).ResourceVersion).To(Equal("42"))
+		Eventually(w.ResultChan()).Should(BeClosed())
+		Eventually(drained).Should(Receive(BeNil()))
+
+		_, err = r.Watch(ctx, &metainternalversion.ListOptions{})
+		Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+	})
+
+	It("closes watches without bookmarks when the client did not ask for them", func() {
+		drainer := NewWatchDrainer()
+		r := newREST(drainer)
+
+		w, err := r.Watch(ctx, &metainternalversion.ListOptions{ResourceVersion: "42"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(drainer.Drain(context.Background())).To(Succeed())
+		Eventually(w.ResultChan()).Should(BeClosed())
+	})
+})
@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+)
+
+// watchRetryInterval is the delay before a closed HelmRelease watch is
+// re-established
+var watchRetryInterval = time.Second
+
+// watcher returns the HelmRelease watch the events are read from
+func (cw *customWatcher) watcher() watch.Interface {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return cw.underlying
+}
+
+// resume re-establishes the HelmRelease watch from resourceVersion once it
+// closed, retrying until the watch is stopped. It returns false if the watch
+// ends instead, after sending a 410 Gone error if resourceVersion expired, so
+// that the client relists.
+func (cw *customWatcher) resume(ctx context.Context, open func(resourceVersion string) (watch.Interface, error), resourceVersion string) bool {
+	for {
+		select {
+		case <-time.After(watchRetryInterval):
+		case <-cw.drainChan:
+			return false
+		case <-cw.stopChan:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+
+		w, err := open(resourceVersion)
+		if err != nil {
+			if isExpired(err) {
+				cw.sendError(ctx, err)
+				return false
+			}
+			klog.Warningf("Failed to re-establish HelmRelease watch at resource version %q: %v", resourceVersion, err)
+			continue
+		}
+
+		cw.mu.Lock()
+		stopped := cw.stopped
+		if !stopped {
+			cw.underlying = w
+		}
+		cw.mu.Unlock()
+		if stopped {
+			w.Stop()
+			return false
+		}
+		klog.V(4).Infof("Re-established HelmRelease watch at resource version %q", resourceVersion)
+		return true
+	}
+}
+
+// sendError delivers err to the client as an Error event
+func (cw *customWatcher) sendError(ctx context.Context, err error) {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		status = apierrors.NewInternalError(err)
+	}
+	errStatus := status.Status()
+	cw.send(ctx, watch.Event{Type: watch.Error, Object: &errStatus})
+}
+
+// send delivers event unless the watch is stopped first
+func (cw *customWatcher) send(ctx context.Context, event watch.Event) bool {
+	select {
+	case cw.resultChan <- event:
+		return true
+	case <-cw.stopChan:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isExpired reports whether err is the 410 Gone error of an expired resource
+// version
+func isExpired(err error) bool {
+	return apierrors.IsResourceExpired(err) || apierrors.IsGone(err)
+}
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("watch resumption", func() {
+	var (
+		mu       sync.Mutex
+		watchers []*watch.FakeWatcher
+		versions []string
+		expired  bool
+		r        *REST
+	)
+	ctx := request.WithNamespace(context.Background(), "tenant-root")
+
+	// upstream returns the HelmRelease watch opened at index i
+	upstream := func(i int) *watch.FakeWatcher {
+		var w *watch.FakeWatcher
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(watchers) > i {
+				w = watchers[i]
+			}
+			return len(watchers)
+		}).Should(BeNumerically(">", i))
+		return w
+	}
+	release := func(resourceVersion string) *helmv2.HelmRelease {
+		return &helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-db",
+			Namespace:       "tenant-root",
+			ResourceVersion: resourceVersion,
+			Labels:          map[string]string{ApplicationNameLabel: "db"},
+		}}
+	}
+
+	BeforeEach(func() {
+		previous := watchRetryInterval
+		watchRetryInterval = 10 * time.Millisecond
+		DeferCleanup(func() { watchRetryInterval = previous })
+
+		watchers, versions, expired = nil, nil, false
+		scheme := runtime.NewScheme()
+		Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+		existing := release("3")
+		existing.Labels[ApplicationKindLabel] = "Test"
+		existing.Labels[ApplicationGroupLabel] = appsv1alpha1.GroupName
+		w := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if err := c.List(ctx, list, opts...); err != nil {
+					return err
+				}
+				list.SetResourceVersion("7")
+				return nil
+			},
+			Watch: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+				listOpts := &client.ListOptions{}
+				listOpts.ApplyOptions(opts)
+				mu.Lock()
+				defer mu.Unlock()
+				versions = append(versions, listOpts.Raw.ResourceVersion)
+				if expired {
+					return nil, apierrors.NewResourceExpired("too old resource version")
+				}
+				fw := watch.NewFakeWithChanSize(10, false)
+				watchers = append(watchers, fw)
+				return fw, nil
+			},
+		}).Build()
+		r = &REST{
+			w:             w,
+			gvr:           schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+			gvk:           schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+			kindName:      "Test",
+			releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+		}
+	})
+
+	It("resumes a closed HelmRelease watch from the last resource version", func() {
+		w, err := r.Watch(ctx, &metainternalversion.ListOptions{ResourceVersion: "1"})
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		upstream(0).Modify(release("5"))
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Object.(*appsv1alpha1.Application).ResourceVersion).To(Equal("5"))
+
+		upstream(0).Stop()
+		upstream(1).Modify(release("6"))
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Modified))
+		Expect(event.Object.(*appsv1alpha1.Application).ResourceVersion).To(Equal("6"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(versions).To(Equal([]string{"1", "5"}))
+	})
+
+	It("propagates bookmarks and resumes from them", func() {
+		w, err := r.Watch(ctx, &metainternalversion.ListOptions{ResourceVersion: "1", AllowWatchBookmarks: true})
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		upstream(0).Action(watch.Bookmark, &helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "9"}})
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Bookmark))
+		Expect(event.Object.(*appsv1alpha1.Application).ResourceVersion).To(Equal("9"))
+
+		upstream(0).Stop()
+		upstream(1)
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(versions).To(Equal([]string{"1", "9"}))
+	})
+
+	It("resumes a watch without a resource version from the initial list", func() {
+		w, err := r.Watch(ctx, &metainternalversion.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Object.(*appsv1alpha1.Application).Name).To(Equal("db"))
+
+		// Closed before any event or bookmark, the listed HelmReleases are
+		// not replayed
+		upstream(0).Stop()
+		upstream(1)
+		Consistently(w.ResultChan(), 100*time.Millisecond).ShouldNot(Receive())
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(versions).To(Equal([]string{"7", "7"}))
+	})
+
+	It("closes a watch drained during the initial list without a bookmark", func() {
+		drainer := NewWatchDrainer()
+		r.SetWatchDrainer(drainer)
+		w, err := r.Watch(ctx, &metainternalversion.ListOptions{AllowWatchBookmarks: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		// The listed HelmRelease is not read before the drain
+		drainCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		Expect(drainer.Drain(drainCtx)).To(Succeed())
+
+		_, ok := <-w.ResultChan()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("ends the watch with 410 Gone once the resource version expired", func() {
+		w, err := r.Watch(ctx, &metainternalversion.ListOptions{ResourceVersion: "1"})
+		Expect(err).NotTo(HaveOccurred())
+
+		status := apierrors.NewResourceExpired("too old resource version").ErrStatus
+		upstream(0).Error(&status)
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Error))
+		Expect(event.Object.(*metav1.Status).Code).To(BeEquivalentTo(410))
+		Eventually(w.ResultChan()).Should(BeClosed())
+	})
+
+	It("ends the watch with 410 Gone if it cannot be resumed", func() {
+		w, err := r.Watch(ctx, &metainternalversion.ListOptions{ResourceVersion: "1"})
+		Expect(err).NotTo(HaveOccurred())
+
+		mu.Lock()
+		expired = true
+		mu.Unlock()
+		upstream(0).Stop()
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Error))
+		Expect(event.Object.(*metav1.Status).Code).To(BeEquivalentTo(410))
+		Eventually(w.ResultChan()).Should(BeClosed())
+	})
+})
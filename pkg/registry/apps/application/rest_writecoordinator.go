@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Cozystack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// writeCoordinatorSize is the number of resource versions a WriteCoordinator remembers
+const writeCoordinatorSize = 4096
+
+// WriteCoordinator helps Application writes through concurrent writes to their
+// HelmRelease, such as status updates by Flux or bulk edits from the dashboard.
+//
+// Patches that conflict are retried against the latest HelmRelease. Updates
+// (PUT) are never retried, as they carry the resource version the client read.
+//
+// It also remembers the content of the HelmReleases served at recent resource
+// versions. A write, PATCH or PUT, based on a resource version that is stale
+// only because of changes invisible in the Application, e.g. to the status, is
+// applied to the current resource version instead of failing. A write based on
+// a version whose content has changed since is still rejected with a conflict.
+type WriteCoordinator struct {
+	retries int
+
+	mu      sync.Mutex
+	digests map[string]string
+	// order holds the keys of digests, oldest first, for eviction
+	order []string
+}
+
+// NewWriteCoordinator returns a WriteCoordinator retrying conflicting patches
+// up to retries times
+func NewWriteCoordinator(retries int) *WriteCoordinator {
+	return &WriteCoordinator{
+		retries: retries,
+		digests: make(map[string]string, writeCoordinatorSize),
+	}
+}
+
+// SetWriteCoordinator makes Update coordinate writes with wc
+func (r *REST) SetWriteCoordinator(wc *WriteCoordinator) {
+	r.writes = wc
+}
+
+// observe records the content of hr at its resource version
+func (wc *WriteCoordinator) observe(hr *helmv2.HelmRelease) {
+	if wc == nil || hr.ResourceVersion == "" {
+		return
+	}
+	digest, err := contentDigest(hr)
+	if err != nil {
+		return
+	}
+	key := resourceVersionKey(hr.Namespace, hr.Name, hr.ResourceVersion)
+
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if _, ok := wc.digests[key]; ok {
+		return
+	}
+	if len(wc.order) >= writeCoordinatorSize {
+		delete(wc.digests, wc.order[0])
+		wc.order = wc.order[1:]
+	}
+	wc.digests[key] = digest
+	wc.order = append(wc.order, key)
+}
+
+// unchanged reports whether the HelmRelease name in namespace had the same
+// content at resource versions stale and current. It is false if either of
+// them was not observed.
+func (wc *WriteCoordinator) unchanged(namespace, name, stale, current string) bool {
+	if wc == nil {
+		return false
+	}
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	a, ok := wc.digests[resourceVersionKey(namespace, name, stale)]
+	if !ok {
+		return false
+	}
+	b, ok := wc.digests[resourceVersionKey(namespace, name, current)]
+	return ok && a == b
+}
+
+func resourceVersionKey(namespace, name, resourceVersion string) string {
+	return namespace + "/" + name + "@" + resourceVersion
+}
+
+// contentDigest hashes the parts of hr an Application update can change. The
+// conditions reported through the status subresource are left out, since
+// updates keep them.
+func contentDigest(hr *helmv2.HelmRelease) (string, error) {
+	annotations := make(map[string]string, len(hr.Annotations))
+	for k, v := range hr.Annotations {
+		if k != StatusConditionsAnnotation {
+			annotations[k] = v
+		}
+	}
+	data, err := json.Marshal(struct {
+		Labels      map[string]string      `json:"labels"`
+		Annotations map[string]string      `json:"annotations"`
+		Spec        helmv2.HelmReleaseSpec `json:"spec"`
+	}{hr.Labels, annotations, hr.Spec})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,205 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/config"
+)
+
+var _ = Describe("WriteCoordinator", func() {
+	helmRelease := func(resourceVersion, values string) *helmv2.HelmRelease {
+		return &helmv2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-root", Name: "test-db", ResourceVersion: resourceVersion},
+			Spec:       helmv2.HelmReleaseSpec{Values: &apiextv1.JSON{Raw: []byte(values)}},
+		}
+	}
+
+	It("maps resource versions with the same content", func() {
+		wc := NewWriteCoordinator(3)
+		wc.observe(helmRelease("1", `{"size":"1Gi"}`))
+		wc.observe(helmRelease("2", `{"size":"1Gi"}`))
+		wc.observe(helmRelease("3", `{"size":"2Gi"}`))
+		Expect(wc.unchanged("tenant-root", "test-db", "1", "2")).To(BeTrue())
+		Expect(wc.unchanged("tenant-root", "test-db", "2", "3")).To(BeFalse())
+		Expect(wc.unchanged("tenant-root", "test-db", "0", "2")).To(BeFalse())
+		Expect(wc.unchanged("tenant-root", "other", "1", "2")).To(BeFalse())
+	})
+
+	It("forgets the oldest resource versions", func() {
+		wc := NewWriteCoordinator(3)
+		for i := 0; i <= writeCoordinatorSize; i++ {
+			wc.observe(helmRelease(fmt.Sprint(i), `{}`))
+		}
+		Expect(wc.digests).To(HaveLen(writeCoordinatorSize))
+		Expect(wc.unchanged("tenant-root", "test-db", "0", "1")).To(BeFalse())
+		Expect(wc.unchanged("tenant-root", "test-db", "1", fmt.Sprint(writeCoordinatorSize))).To(BeTrue())
+	})
+
+	It("is a no-op when unset", func() {
+		var wc *WriteCoordinator
+		wc.observe(helmRelease("1", `{}`))
+		Expect(wc.unchanged("tenant-root", "test-db", "1", "1")).To(BeFalse())
+	})
+
+	Describe("Update", func() {
+		var (
+			ctx       context.Context
+			conflicts int
+			c         client.WithWatch
+		)
+
+		BeforeEach(func() {
+			ctx = request.WithNamespace(context.Background(), "tenant-root")
+			conflicts = 0
+			scheme := runtime.NewScheme()
+			Expect(helmv2.AddToScheme(scheme)).To(Succeed())
+			hr := helmRelease("", `{"size":"1Gi"}`)
+			hr.Labels = map[string]string{
+				ApplicationKindLabel:  "Test",
+				ApplicationGroupLabel: appsv1alpha1.GroupName,
+				ApplicationNameLabel:  "db",
+			}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(hr).WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if conflicts > 0 {
+						conflicts--
+						return apierrors.NewConflict(schema.GroupResource{Group: helmv2.GroupVersion.Group, Resource: "helmreleases"}, obj.GetName(), fmt.Errorf("object has been modified"))
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			}).Build()
+		})
+
+		newREST := func(wc *WriteCoordinator) *REST {
+			return &REST{
+				c:             c,
+				w:             c,
+				gvr:           schema.GroupVersionResource{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Resource: "tests"},
+				gvk:           schema.GroupVersionKind{Group: appsv1alpha1.GroupName, Version: "v1alpha1", Kind: "Test"},
+				kindName:      "Test",
+				releaseConfig: config.ReleaseConfig{Prefix: "test-"},
+				writes:        wc,
+			}
+		}
+
+		resize := func(r *REST, size string) *appsv1alpha1.Application {
+			obj, err := r.Get(ctx, "db", &metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			app := obj.(*appsv1alpha1.Application)
+			app.Spec = &apiextv1.JSON{Raw: []byte(fmt.Sprintf(`{"size":%q}`, size))}
+			return app
+		}
+
+		It("retries conflicts against the latest version", func() {
+			r := newREST(NewWriteCoordinator(3))
+			app := resize(r, "2Gi")
+			conflicts = 2
+			_, _, err := r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conflicts).To(BeZero())
+		})
+
+		It("does not retry conflicts of a PUT", func() {
+			r := newREST(NewWriteCoordinator(3))
+			app := resize(r, "2Gi")
+			conflicts = 2
+			put := request.WithRequestInfo(ctx, &request.RequestInfo{Verb: "update"})
+			_, _, err := r.Update(put, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+			Expect(conflicts).To(Equal(1))
+		})
+
+		It("retries conflicts of a PATCH", func() {
+			r := newREST(NewWriteCoordinator(3))
+			app := resize(r, "2Gi")
+			conflicts = 2
+			patch := request.WithRequestInfo(ctx, &request.RequestInfo{Verb: "patch"})
+			_, _, err := r.Update(patch, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conflicts).To(BeZero())
+		})
+
+		It("applies an update based on a stale version with unchanged content", func() {
+			r := newREST(NewWriteCoordinator(3))
+			app := resize(r, "2Gi")
+
+			// A status-only write bumps the resource version
+			hr := &helmv2.HelmRelease{}
+			Expect(c.Get(ctx, client.ObjectKey{Namespace: "tenant-root", Name: "test-db"}, hr)).To(Succeed())
+			Expect(c.Update(ctx, hr)).To(Succeed())
+			Expect(hr.ResourceVersion).NotTo(Equal(app.ResourceVersion))
+
+			obj, _, err := r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(obj.(*appsv1alpha1.Application).Spec.Raw)).To(ContainSubstring("2Gi"))
+		})
+
+		It("reports conflicts with a changed spec", func() {
+			r := newREST(NewWriteCoordinator(3))
+			app := resize(r, "2Gi")
+
+			other := resize(r, "3Gi")
+			_, _, err := r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(other), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("get the latest version"))
+		})
+
+		It("applies a PUT based on a stale version with unchanged content", func() {
+			r := newREST(NewWriteCoordinator(3))
+			app := resize(r, "2Gi")
+
+			hr := &helmv2.HelmRelease{}
+			Expect(c.Get(ctx, client.ObjectKey{Namespace: "tenant-root", Name: "test-db"}, hr)).To(Succeed())
+			Expect(c.Update(ctx, hr)).To(Succeed())
+
+			put := request.WithRequestInfo(ctx, &request.RequestInfo{Verb: "update"})
+			obj, _, err := r.Update(put, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(obj.(*appsv1alpha1.Application).Spec.Raw)).To(ContainSubstring("2Gi"))
+		})
+
+		It("reports conflicts of a PUT with a changed spec", func() {
+			r := newREST(NewWriteCoordinator(3))
+			app := resize(r, "2Gi")
+
+			other := resize(r, "3Gi")
+			_, _, err := r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(other), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			put := request.WithRequestInfo(ctx, &request.RequestInfo{Verb: "update"})
+			_, _, err = r.Update(put, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+			hr := &helmv2.HelmRelease{}
+			Expect(c.Get(ctx, client.ObjectKey{Namespace: "tenant-root", Name: "test-db"}, hr)).To(Succeed())
+			Expect(string(hr.Spec.Values.Raw)).To(ContainSubstring("3Gi"))
+		})
+
+		It("returns conflicts as is without a WriteCoordinator", func() {
+			r := newREST(nil)
+			app := resize(r, "2Gi")
+			conflicts = 1
+			_, _, err := r.Update(ctx, "db", rest.DefaultUpdatedObjectInfo(app), nil, nil, false, &metav1.UpdateOptions{})
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+		})
+	})
+})
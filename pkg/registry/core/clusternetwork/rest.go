@@ -0,0 +1,291 @@
+// SPDX-License-Identifier: Apache-2.0
+// ClusterNetwork registry: read-only singleton exposing the networking facts
+// of the cluster, read from the cozystack-values Secret of cozy-system and the
+// MetalLB address pools.
+
+package clusternetwork
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	corev1alpha1 "github.com/cozystack/cozystack/pkg/apis/core/v1alpha1"
+	fieldfilter "github.com/cozystack/cozystack/pkg/registry/fields"
+)
+
+const (
+	singularName = "clusternetwork"
+
+	// Name is the name of the ClusterNetwork singleton
+	Name = "cluster"
+
+	valuesNamespace = "cozy-system"
+	valuesSecret    = "cozystack-values"
+	valuesKey       = "values.yaml"
+
+	defaultClusterDomain = "cozy.local"
+)
+
+// ipAddressPoolList is the list kind of the MetalLB address pools
+var ipAddressPoolList = schema.GroupVersionKind{Group: "metallb.io", Version: "v1beta1", Kind: "IPAddressPoolList"}
+
+// -----------------------------------------------------------------------------
+// REST storage
+// -----------------------------------------------------------------------------
+
+var (
+	_ rest.Lister               = &REST{}
+	_ rest.Getter               = &REST{}
+	_ rest.TableConvertor       = &REST{}
+	_ rest.Scoper               = &REST{}
+	_ rest.SingularNameProvider = &REST{}
+)
+
+type REST struct {
+	// c reads the cozystack-values Secret from the cache
+	c client.Client
+	// pools reads the address pools, which are not cached
+	pools client.Reader
+	gvr   schema.GroupVersionResource
+}
+
+func NewREST(c client.Client, pools client.Reader) *REST {
+	return &REST{
+		c:     c,
+		pools: pools,
+		gvr: schema.GroupVersionResource{
+			Group:    corev1alpha1.GroupName,
+			Version:  "v1alpha1",
+			Resource: "clusternetworks",
+		},
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Basic meta
+// -----------------------------------------------------------------------------
+
+func (*REST) NamespaceScoped() bool { return false }
+func (*REST) New() runtime.Object   { return &corev1alpha1.ClusterNetwork{} }
+func (*REST) NewList() runtime.Object {
+	return &corev1alpha1.ClusterNetworkList{}
+}
+func (*REST) Kind() string { return "ClusterNetwork" }
+func (r *REST) GroupVersionKind(_ schema.GroupVersion) schema.GroupVersionKind {
+	return r.gvr.GroupVersion().WithKind("ClusterNetwork")
+}
+func (*REST) GetSingularName() string { return singularName }
+
+// -----------------------------------------------------------------------------
+// Lister / Getter
+// -----------------------------------------------------------------------------
+
+func (r *REST) List(
+	ctx context.Context,
+	opts *metainternal.ListOptions,
+) (runtime.Object, error) {
+	out := &corev1alpha1.ClusterNetworkList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ClusterNetworkList",
+		},
+	}
+	if opts != nil {
+		fieldFilter, err := fieldfilter.ParseFieldSelector(opts.FieldSelector)
+		if err != nil {
+			return nil, err
+		}
+		if !fieldFilter.MatchesName(Name) {
+			return out, nil
+		}
+	}
+
+	cn, err := r.clusterNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out.ResourceVersion = cn.ResourceVersion
+	out.Items = []corev1alpha1.ClusterNetwork{*cn}
+	return out, nil
+}
+
+func (r *REST) Get(
+	ctx context.Context,
+	name string,
+	_ *metav1.GetOptions,
+) (runtime.Object, error) {
+	if name != Name {
+		return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
+	}
+	return r.clusterNetwork(ctx)
+}
+
+// clusterNetwork builds the ClusterNetwork from the cozystack-values Secret
+// and the address pools
+func (r *REST) clusterNetwork(ctx context.Context) (*corev1alpha1.ClusterNetwork, error) {
+	secret := &corev1.Secret{}
+	if err := r.c.Get(ctx, client.ObjectKey{Namespace: valuesNamespace, Name: valuesSecret}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, apierrors.NewNotFound(r.gvr.GroupResource(), Name)
+		}
+		return nil, err
+	}
+	cn, err := fromValues(secret)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	if cn.LoadBalancerPools, err = r.loadBalancerPools(ctx); err != nil {
+		return nil, err
+	}
+	return cn, nil
+}
+
+// loadBalancerPools returns the MetalLB address pools, or none if MetalLB
+// is not installed
+func (r *REST) loadBalancerPools(ctx context.Context) ([]corev1alpha1.LoadBalancerPool, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(ipAddressPoolList)
+	if err := r.pools.List(ctx, list); err != nil {
+		if apimeta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list address pools: %w", err)
+	}
+	var pools []corev1alpha1.LoadBalancerPool
+	for _, item := range list.Items {
+		addresses, _, _ := unstructured.NestedStringSlice(item.Object, "spec", "addresses")
+		pools = append(pools, corev1alpha1.LoadBalancerPool{Name: item.GetName(), Addresses: addresses})
+	}
+	return pools, nil
+}
+
+// fromValues reads the networking facts from the _cluster values of the
+// cozystack-values Secret
+func fromValues(secret *corev1.Secret) (*corev1alpha1.ClusterNetwork, error) {
+	var values struct {
+		Cluster map[string]interface{} `json:"_cluster"`
+	}
+	if err := yaml.Unmarshal(secret.Data[valuesKey], &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s of Secret %s/%s: %w", valuesKey, secret.Namespace, secret.Name, err)
+	}
+	value := func(key string) string {
+		v, ok := values.Cluster[key]
+		if !ok || v == nil {
+			return ""
+		}
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+
+	cn := &corev1alpha1.ClusterNetwork{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ClusterNetwork",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              Name,
+			UID:               secret.UID,
+			ResourceVersion:   secret.ResourceVersion,
+			CreationTimestamp: secret.CreationTimestamp,
+		},
+		ClusterDomain:     value("cluster-domain"),
+		IngressDomain:     value("root-host"),
+		ExposeIngress:     value("expose-ingress"),
+		PodCIDR:           value("ipv4-cluster-cidr"),
+		ServiceCIDR:       value("ipv4-service-cidr"),
+		JoinCIDR:          value("ipv4-join-cidr"),
+		APIServerEndpoint: value("api-server-endpoint"),
+	}
+	if cn.ClusterDomain == "" {
+		cn.ClusterDomain = defaultClusterDomain
+	}
+	for _, ip := range strings.Split(value("expose-external-ips"), ",") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			cn.ExternalIPs = append(cn.ExternalIPs, ip)
+		}
+	}
+	return cn, nil
+}
+
+// -----------------------------------------------------------------------------
+// TableConvertor
+// -----------------------------------------------------------------------------
+
+func (r *REST) ConvertToTable(_ context.Context, obj runtime.Object, _ runtime.Object) (*metav1.Table, error) {
+	now := time.Now()
+	row := func(o *corev1alpha1.ClusterNetwork) metav1.TableRow {
+		return metav1.TableRow{
+			Cells: []interface{}{
+				o.Name,
+				o.ClusterDomain,
+				o.IngressDomain,
+				o.PodCIDR,
+				o.ServiceCIDR,
+				duration.HumanDuration(now.Sub(o.CreationTimestamp.Time)),
+			},
+			Object: runtime.RawExtension{Object: o},
+		}
+	}
+
+	tbl := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "NAME", Type: "string"},
+			{Name: "CLUSTER DOMAIN", Type: "string"},
+			{Name: "INGRESS DOMAIN", Type: "string"},
+			{Name: "POD CIDR", Type: "string"},
+			{Name: "SERVICE CIDR", Type: "string"},
+			{Name: "AGE", Type: "string"},
+		},
+	}
+
+	switch v := obj.(type) {
+	case *corev1alpha1.ClusterNetworkList:
+		for i := range v.Items {
+			tbl.Rows = append(tbl.Rows, row(&v.Items[i]))
+		}
+		tbl.ResourceVersion = v.ResourceVersion
+	case *corev1alpha1.ClusterNetwork:
+		tbl.Rows = append(tbl.Rows, row(v))
+		tbl.ResourceVersion = v.ResourceVersion
+	default:
+		return nil, notAcceptable{r.gvr.GroupResource(), fmt.Sprintf("unexpected %T", obj)}
+	}
+	return tbl, nil
+}
+
+// -----------------------------------------------------------------------------
+// Boiler-plate
+// -----------------------------------------------------------------------------
+
+func (*REST) Destroy() {}
+
+type notAcceptable struct {
+	resource schema.GroupResource
+	message  string
+}
+
+func (e notAcceptable) Error() string { return e.message }
+func (e notAcceptable) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusNotAcceptable,
+		Reason:  metav1.StatusReason("NotAcceptable"),
+		Message: e.message,
+	}
+}
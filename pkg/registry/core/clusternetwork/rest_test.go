@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package clusternetwork
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	corev1alpha1 "github.com/cozystack/cozystack/pkg/apis/core/v1alpha1"
+)
+
+func valuesSecretWith(values string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: valuesSecret, Namespace: valuesNamespace},
+		Data:       map[string][]byte{valuesKey: []byte(values)},
+	}
+}
+
+func TestFromValues(t *testing.T) {
+	cn, err := fromValues(valuesSecretWith(`_cluster:
+  cluster-domain: cluster.example
+  root-host: example.org
+  expose-ingress: tenant-root
+  expose-external-ips: "192.0.2.10, 192.0.2.11"
+  ipv4-cluster-cidr: 10.244.0.0/16
+  ipv4-service-cidr: 10.96.0.0/16
+  ipv4-join-cidr: 100.64.0.0/16
+`))
+	if err != nil {
+		t.Fatalf("fromValues returned error: %v", err)
+	}
+	if cn.Name != Name || cn.ClusterDomain != "cluster.example" || cn.IngressDomain != "example.org" ||
+		cn.ExposeIngress != "tenant-root" || cn.PodCIDR != "10.244.0.0/16" ||
+		cn.ServiceCIDR != "10.96.0.0/16" || cn.JoinCIDR != "100.64.0.0/16" {
+		t.Errorf("unexpected cluster network: %+v", cn)
+	}
+	if want := []string{"192.0.2.10", "192.0.2.11"}; !reflect.DeepEqual(cn.ExternalIPs, want) {
+		t.Errorf("external IPs = %v, want %v", cn.ExternalIPs, want)
+	}
+}
+
+func TestFromValuesDefaultsClusterDomain(t *testing.T) {
+	cn, err := fromValues(valuesSecretWith("_cluster: {}\n"))
+	if err != nil {
+		t.Fatalf("fromValues returned error: %v", err)
+	}
+	if cn.ClusterDomain != defaultClusterDomain || cn.ExternalIPs != nil {
+		t.Errorf("unexpected cluster network: %+v", cn)
+	}
+}
+
+func TestGetOnlyServesTheSingleton(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(valuesSecretWith("_cluster:\n  root-host: example.org\n")).
+		Build()
+	r := NewREST(c, c)
+
+	if _, err := r.Get(context.TODO(), "other", nil); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound for another name, got %v", err)
+	}
+	// MetalLB is not installed in the fake client, so there are no pools
+	obj, err := r.Get(context.TODO(), Name, nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	cn := obj.(*corev1alpha1.ClusterNetwork)
+	if cn.IngressDomain != "example.org" || cn.LoadBalancerPools != nil {
+		t.Errorf("unexpected cluster network: %+v", cn)
+	}
+}
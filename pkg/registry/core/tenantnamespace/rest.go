@@ -11,13 +11,17 @@ import (
 	"strings"
 	"time"
 
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/apimachinery/pkg/watch"
@@ -25,13 +29,19 @@ import (
 	"k8s.io/apiserver/pkg/registry/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
 	corev1alpha1 "github.com/cozystack/cozystack/pkg/apis/core/v1alpha1"
+	fieldfilter "github.com/cozystack/cozystack/pkg/registry/fields"
 	"github.com/cozystack/cozystack/pkg/registry/sorting"
 )
 
 const (
 	prefix       = "tenant-"
 	singularName = "tenantnamespace"
+
+	// tierLabel holds the depth of the tenant below the root tenant. It is
+	// set on tenant namespaces by the tenant namespace labeler webhook.
+	tierLabel = "tenant.cozystack.io/tier"
 )
 
 // -----------------------------------------------------------------------------
@@ -89,17 +99,22 @@ func (*REST) GetSingularName() string { return singularName }
 
 func (r *REST) List(
 	ctx context.Context,
-	_ *metainternal.ListOptions,
+	opts *metainternal.ListOptions,
 ) (runtime.Object, error) {
+	labelSelector, fieldFilter, err := parseSelectors(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	nsList := &corev1.NamespaceList{}
-	err := r.c.List(ctx, nsList)
+	err = r.c.List(ctx, nsList, client.MatchingLabelsSelector{Selector: labelSelector})
 	if err != nil {
 		return nil, err
 	}
 
 	var tenantNames []string
 	for i := range nsList.Items {
-		if strings.HasPrefix(nsList.Items[i].Name, prefix) {
+		if strings.HasPrefix(nsList.Items[i].Name, prefix) && fieldFilter.MatchesName(nsList.Items[i].Name) {
 			tenantNames = append(tenantNames, nsList.Items[i].Name)
 		}
 	}
@@ -141,11 +156,24 @@ func (r *REST) Get(
 // -----------------------------------------------------------------------------
 
 func (r *REST) Watch(ctx context.Context, opts *metainternal.ListOptions) (watch.Interface, error) {
-	nsList := &corev1.NamespaceList{}
-	nsWatch, err := r.w.Watch(ctx, nsList, &client.ListOptions{Raw: &metav1.ListOptions{
+	labelSelector, fieldFilter, err := parseSelectors(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Namespaces support both selectors natively, so let the API server
+	// filter the events; the name is still checked below.
+	raw := &metav1.ListOptions{
 		Watch:           true,
 		ResourceVersion: opts.ResourceVersion,
-	}})
+		LabelSelector:   labelSelector.String(),
+	}
+	if fieldFilter.Name != "" {
+		raw.FieldSelector = fields.OneTermEqualSelector("metadata.name", fieldFilter.Name).String()
+	}
+
+	nsList := &corev1.NamespaceList{}
+	nsWatch, err := r.w.Watch(ctx, nsList, &client.ListOptions{Raw: raw})
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +185,7 @@ func (r *REST) Watch(ctx context.Context, opts *metainternal.ListOptions) (watch
 		defer pw.Stop()
 		for ev := range nsWatch.ResultChan() {
 			ns, ok := ev.Object.(*corev1.Namespace)
-			if !ok || !strings.HasPrefix(ns.Name, prefix) {
+			if !ok || !strings.HasPrefix(ns.Name, prefix) || !fieldFilter.MatchesName(ns.Name) {
 				continue
 			}
 			out := &corev1alpha1.TenantNamespace{
@@ -185,42 +213,162 @@ func (r *REST) Watch(ctx context.Context, opts *metainternal.ListOptions) (watch
 // TableConvertor
 // -----------------------------------------------------------------------------
 
-func (r *REST) ConvertToTable(_ context.Context, obj runtime.Object, _ runtime.Object) (*metav1.Table, error) {
-	now := time.Now()
-	row := func(o *corev1alpha1.TenantNamespace) metav1.TableRow {
-		return metav1.TableRow{
-			Cells:  []interface{}{o.Name, duration.HumanDuration(now.Sub(o.CreationTimestamp.Time))},
-			Object: runtime.RawExtension{Object: o},
-		}
-	}
-
+func (r *REST) ConvertToTable(ctx context.Context, obj runtime.Object, _ runtime.Object) (*metav1.Table, error) {
+	var items []*corev1alpha1.TenantNamespace
 	tbl := &metav1.Table{
 		TypeMeta: metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
 		ColumnDefinitions: []metav1.TableColumnDefinition{
 			{Name: "NAME", Type: "string"},
+			{Name: "TIER", Type: "string", Description: "Depth of the tenant below the root tenant"},
+			{Name: "APPS", Type: "integer", Description: "Number of applications in the namespace"},
+			{Name: "CPU", Type: "string", Description: "CPU used out of the resource quota"},
+			{Name: "MEMORY", Type: "string", Description: "Memory used out of the resource quota"},
 			{Name: "AGE", Type: "string"},
 		},
 	}
 
+	// Usage is read for the single namespace or for all of them at once
+	namespace := ""
 	switch v := obj.(type) {
 	case *corev1alpha1.TenantNamespaceList:
 		for i := range v.Items {
-			tbl.Rows = append(tbl.Rows, row(&v.Items[i]))
+			items = append(items, &v.Items[i])
 		}
 		tbl.ResourceVersion = v.ResourceVersion
 	case *corev1alpha1.TenantNamespace:
-		tbl.Rows = append(tbl.Rows, row(v))
+		items = append(items, v)
+		namespace = v.Name
 		tbl.ResourceVersion = v.ResourceVersion
 	default:
 		return nil, notAcceptable{r.gvr.GroupResource(), fmt.Sprintf("unexpected %T", obj)}
 	}
+
+	usage, err := r.usage(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, o := range items {
+		tier := o.Labels[tierLabel]
+		if tier == "" {
+			tier = "<none>"
+		}
+		u := usage[o.Name]
+		if u == nil {
+			u = &namespaceUsage{}
+		}
+		tbl.Rows = append(tbl.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				o.Name,
+				tier,
+				u.apps,
+				quotaCell(u.quota, corev1.ResourceRequestsCPU, corev1.ResourceCPU, corev1.ResourceLimitsCPU),
+				quotaCell(u.quota, corev1.ResourceRequestsMemory, corev1.ResourceMemory, corev1.ResourceLimitsMemory),
+				duration.HumanDuration(now.Sub(o.CreationTimestamp.Time)),
+			},
+			Object: runtime.RawExtension{Object: o},
+		})
+	}
 	return tbl, nil
 }
 
+// namespaceUsage is the application count and resource quota of a tenant namespace
+type namespaceUsage struct {
+	apps  int
+	quota corev1.ResourceQuotaStatus
+}
+
+// usage returns the usage of the tenant namespaces by name, for the
+// namespace only unless it is empty. Applications are counted from the
+// HelmReleases backing them. Of several quotas limiting the same resource,
+// the one with the lowest limit is reported.
+func (r *REST) usage(ctx context.Context, namespace string) (map[string]*namespaceUsage, error) {
+	out := map[string]*namespaceUsage{}
+	get := func(ns string) *namespaceUsage {
+		if out[ns] == nil {
+			out[ns] = &namespaceUsage{quota: corev1.ResourceQuotaStatus{Hard: corev1.ResourceList{}, Used: corev1.ResourceList{}}}
+		}
+		return out[ns]
+	}
+
+	appSelector, err := applicationSelector()
+	if err != nil {
+		return nil, err
+	}
+	hrList := &helmv2.HelmReleaseList{}
+	if err := r.c.List(ctx, hrList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: appSelector}); err != nil {
+		return nil, fmt.Errorf("failed to list helmreleases: %w", err)
+	}
+	for i := range hrList.Items {
+		get(hrList.Items[i].Namespace).apps++
+	}
+
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.c.List(ctx, quotas, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list resourcequotas: %w", err)
+	}
+	for i := range quotas.Items {
+		q := &quotas.Items[i]
+		u := get(q.Namespace)
+		for name, hard := range q.Status.Hard {
+			if current, ok := u.quota.Hard[name]; ok && current.Cmp(hard) <= 0 {
+				continue
+			}
+			u.quota.Hard[name] = hard
+			u.quota.Used[name] = q.Status.Used[name]
+		}
+	}
+	return out, nil
+}
+
+// quotaCell formats the usage of the first of names limited by quota as
+// used/hard, or <none> if none is limited.
+func quotaCell(quota corev1.ResourceQuotaStatus, names ...corev1.ResourceName) string {
+	for _, name := range names {
+		hard, ok := quota.Hard[name]
+		if !ok {
+			continue
+		}
+		used := quota.Used[name]
+		return used.String() + "/" + hard.String()
+	}
+	return "<none>"
+}
+
 // -----------------------------------------------------------------------------
 // Helpers
 // -----------------------------------------------------------------------------
 
+// parseSelectors returns the label selector and the metadata.name field
+// filter requested in opts. Other field selectors are ignored.
+func parseSelectors(opts *metainternal.ListOptions) (labels.Selector, *fieldfilter.Filter, error) {
+	if opts == nil {
+		return labels.Everything(), &fieldfilter.Filter{}, nil
+	}
+	labelSelector := labels.Everything()
+	if opts.LabelSelector != nil {
+		labelSelector = opts.LabelSelector
+	}
+	fieldFilter, err := fieldfilter.ParseFieldSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, nil, apierrors.NewBadRequest(err.Error())
+	}
+	return labelSelector, fieldFilter, nil
+}
+
+// applicationSelector selects the HelmReleases backing Applications
+func applicationSelector() (labels.Selector, error) {
+	kindReq, err := labels.NewRequirement(appsv1alpha1.ApplicationKindLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	nameReq, err := labels.NewRequirement(appsv1alpha1.ApplicationNameLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*kindReq, *nameReq), nil
+}
+
 func (r *REST) makeList(src *corev1.NamespaceList, allowed []string) *corev1alpha1.TenantNamespaceList {
 	set := map[string]struct{}{}
 	for _, n := range allowed {
@@ -264,6 +412,16 @@ func (r *REST) makeList(src *corev1.NamespaceList, allowed []string) *corev1alph
 func (r *REST) filterAccessible(
 	ctx context.Context,
 	names []string,
+) ([]string, error) {
+	return FilterAccessible(ctx, r.c, names)
+}
+
+// FilterAccessible returns the namespaces among names in which the requesting
+// user is bound to any role. Cluster administrators can access all of them.
+func FilterAccessible(
+	ctx context.Context,
+	c client.Client,
+	names []string,
 ) ([]string, error) {
 	u, ok := request.UserFrom(ctx)
 	if !ok {
@@ -284,7 +442,7 @@ func (r *REST) filterAccessible(
 		nameSet[name] = struct{}{}
 	}
 	rbs := &rbacv1.RoleBindingList{}
-	err := r.c.List(ctx, rbs)
+	err := c.List(ctx, rbs)
 	if err != nil {
 		return []string{}, fmt.Errorf("failed to list rolebindings: %w", err)
 	}
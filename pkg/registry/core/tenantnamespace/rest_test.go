@@ -3,10 +3,22 @@
 package tenantnamespace
 
 import (
+	"context"
 	"testing"
 
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	corev1alpha1 "github.com/cozystack/cozystack/pkg/apis/core/v1alpha1"
 )
 
 func TestMakeListSortsAlphabetically(t *testing.T) {
@@ -38,3 +50,83 @@ func TestMakeListSortsAlphabetically(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSelectors(t *testing.T) {
+	opts := &metainternal.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"tenant.cozystack.io/parent": "tenant-root"}),
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", "tenant-foo"),
+	}
+
+	labelSelector, fieldFilter, err := parseSelectors(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := labelSelector.String(); got != "tenant.cozystack.io/parent=tenant-root" {
+		t.Errorf("expected label selector to be passed through, got %q", got)
+	}
+	if !fieldFilter.MatchesName("tenant-foo") || fieldFilter.MatchesName("tenant-bar") {
+		t.Errorf("expected field filter to match only tenant-foo, got %+v", fieldFilter)
+	}
+
+	labelSelector, fieldFilter, err = parseSelectors(&metainternal.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !labelSelector.Empty() || !fieldFilter.MatchesName("tenant-bar") {
+		t.Errorf("expected empty options to match everything")
+	}
+}
+
+func TestConvertToTableShowsUsage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := helmv2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	app := func(namespace, name string) *helmv2.HelmRelease {
+		return &helmv2.HelmRelease{ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels: map[string]string{
+				appsv1alpha1.ApplicationKindLabel: "Postgres",
+				appsv1alpha1.ApplicationNameLabel: name,
+			},
+		}}
+	}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-foo", Name: "tenant-quota"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4")},
+			Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1500m")},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(app("tenant-foo", "db1"), app("tenant-foo", "db2"), app("tenant-bar", "db3"), quota).
+		Build()
+	r := &REST{c: c}
+
+	list := &corev1alpha1.TenantNamespaceList{Items: []corev1alpha1.TenantNamespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "tenant-foo", Labels: map[string]string{tierLabel: "1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "tenant-baz"}},
+	}}
+	tbl, err := r.ConvertToTable(context.Background(), list, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tbl.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(tbl.Rows))
+	}
+	expected := [][]interface{}{
+		{"tenant-foo", "1", 2, "1500m/4", "<none>"},
+		{"tenant-baz", "<none>", 0, "<none>", "<none>"},
+	}
+	for i, cells := range expected {
+		for j, cell := range cells {
+			if got := tbl.Rows[i].Cells[j]; got != cell {
+				t.Errorf("row %d, column %s: expected %v, got %v", i, tbl.ColumnDefinitions[j].Name, cell, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: Apache-2.0
+// WorkloadSummary registry: read-only, cluster-wide overview of Applications
+// of all kinds, served from a single list of labelled HelmReleases.
+
+package workloadsummary
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+	corev1alpha1 "github.com/cozystack/cozystack/pkg/apis/core/v1alpha1"
+	"github.com/cozystack/cozystack/pkg/registry/core/tenantnamespace"
+	"github.com/cozystack/cozystack/pkg/registry/sorting"
+)
+
+const (
+	singularName = "workloadsummary"
+)
+
+// -----------------------------------------------------------------------------
+// REST storage
+// -----------------------------------------------------------------------------
+
+var (
+	_ rest.Lister               = &REST{}
+	_ rest.Getter               = &REST{}
+	_ rest.TableConvertor       = &REST{}
+	_ rest.Scoper               = &REST{}
+	_ rest.SingularNameProvider = &REST{}
+)
+
+type REST struct {
+	c   client.Client
+	gvr schema.GroupVersionResource
+}
+
+func NewREST(c client.Client) *REST {
+	return &REST{
+		c: c,
+		gvr: schema.GroupVersionResource{
+			Group:    corev1alpha1.GroupName,
+			Version:  "v1alpha1",
+			Resource: "workloadsummaries",
+		},
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Basic meta
+// -----------------------------------------------------------------------------
+
+func (*REST) NamespaceScoped() bool { return false }
+func (*REST) New() runtime.Object   { return &corev1alpha1.WorkloadSummary{} }
+func (*REST) NewList() runtime.Object {
+	return &corev1alpha1.WorkloadSummaryList{}
+}
+func (*REST) Kind() string { return "WorkloadSummary" }
+func (r *REST) GroupVersionKind(_ schema.GroupVersion) schema.GroupVersionKind {
+	return r.gvr.GroupVersion().WithKind("WorkloadSummary")
+}
+func (*REST) GetSingularName() string { return singularName }
+
+// -----------------------------------------------------------------------------
+// Lister / Getter
+// -----------------------------------------------------------------------------
+
+func (r *REST) List(
+	ctx context.Context,
+	opts *metainternal.ListOptions,
+) (runtime.Object, error) {
+	selector, err := applicationSelector()
+	if err != nil {
+		return nil, err
+	}
+	// Label selectors apply to the application labels, which summaries share with their HelmReleases
+	if opts != nil && opts.LabelSelector != nil {
+		reqs, _ := opts.LabelSelector.Requirements()
+		selector = selector.Add(reqs...)
+	}
+
+	hrList := &helmv2.HelmReleaseList{}
+	if err := r.c.List(ctx, hrList, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	var namespaces []string
+	for i := range hrList.Items {
+		ns := hrList.Items[i].Namespace
+		if _, ok := seen[ns]; !ok {
+			seen[ns] = struct{}{}
+			namespaces = append(namespaces, ns)
+		}
+	}
+	allowed, err := tenantnamespace.FilterAccessible(ctx, r.c, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.makeList(hrList, allowed), nil
+}
+
+func (r *REST) Get(
+	ctx context.Context,
+	name string,
+	_ *metav1.GetOptions,
+) (runtime.Object, error) {
+	namespace, kind, appName, ok := parseName(name)
+	if !ok {
+		return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
+	}
+
+	allowed, err := tenantnamespace.FilterAccessible(ctx, r.c, []string{namespace})
+	if err != nil {
+		return nil, err
+	}
+	if len(allowed) == 0 {
+		return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
+	}
+
+	hrList := &helmv2.HelmReleaseList{}
+	err = r.c.List(ctx, hrList,
+		client.InNamespace(namespace),
+		client.MatchingLabels{appsv1alpha1.ApplicationNameLabel: appName},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hrList.Items {
+		hr := &hrList.Items[i]
+		if strings.ToLower(hr.Labels[appsv1alpha1.ApplicationKindLabel]) == kind {
+			summary := summarize(hr)
+			return &summary, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(r.gvr.GroupResource(), name)
+}
+
+// -----------------------------------------------------------------------------
+// TableConvertor
+// -----------------------------------------------------------------------------
+
+func (r *REST) ConvertToTable(_ context.Context, obj runtime.Object, _ runtime.Object) (*metav1.Table, error) {
+	now := time.Now()
+	row := func(o *corev1alpha1.WorkloadSummary) metav1.TableRow {
+		version := o.Version
+		if version == "" {
+			version = "<unknown>"
+		}
+		return metav1.TableRow{
+			Cells: []interface{}{
+				o.ApplicationNamespace,
+				o.ApplicationKind,
+				o.ApplicationName,
+				string(o.Ready),
+				version,
+				duration.HumanDuration(now.Sub(o.CreationTimestamp.Time)),
+			},
+			Object: runtime.RawExtension{Object: o},
+		}
+	}
+
+	tbl := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "NAMESPACE", Type: "string"},
+			{Name: "KIND", Type: "string"},
+			{Name: "NAME", Type: "string"},
+			{Name: "READY", Type: "string"},
+			{Name: "VERSION", Type: "string"},
+			{Name: "AGE", Type: "string"},
+		},
+	}
+
+	switch v := obj.(type) {
+	case *corev1alpha1.WorkloadSummaryList:
+		for i := range v.Items {
+			tbl.Rows = append(tbl.Rows, row(&v.Items[i]))
+		}
+		tbl.ResourceVersion = v.ResourceVersion
+	case *corev1alpha1.WorkloadSummary:
+		tbl.Rows = append(tbl.Rows, row(v))
+		tbl.ResourceVersion = v.ResourceVersion
+	default:
+		return nil, notAcceptable{r.gvr.GroupResource(), fmt.Sprintf("unexpected %T", obj)}
+	}
+	return tbl, nil
+}
+
+// -----------------------------------------------------------------------------
+// Helpers
+// -----------------------------------------------------------------------------
+
+// applicationSelector selects the HelmReleases backing Applications
+func applicationSelector() (labels.Selector, error) {
+	kindReq, err := labels.NewRequirement(appsv1alpha1.ApplicationKindLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	nameReq, err := labels.NewRequirement(appsv1alpha1.ApplicationNameLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*kindReq, *nameReq), nil
+}
+
+func (r *REST) makeList(src *helmv2.HelmReleaseList, allowed []string) *corev1alpha1.WorkloadSummaryList {
+	set := map[string]struct{}{}
+	for _, n := range allowed {
+		set[n] = struct{}{}
+	}
+
+	out := &corev1alpha1.WorkloadSummaryList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "WorkloadSummaryList",
+		},
+		ListMeta: metav1.ListMeta{ResourceVersion: src.ResourceVersion},
+	}
+
+	for i := range src.Items {
+		hr := &src.Items[i]
+		if _, ok := set[hr.Namespace]; !ok {
+			continue
+		}
+		out.Items = append(out.Items, summarize(hr))
+	}
+
+	sorting.ByName[corev1alpha1.WorkloadSummary, *corev1alpha1.WorkloadSummary](out.Items)
+
+	return out
+}
+
+// summarize converts the HelmRelease of an Application into a WorkloadSummary
+func summarize(hr *helmv2.HelmRelease) corev1alpha1.WorkloadSummary {
+	kind := hr.Labels[appsv1alpha1.ApplicationKindLabel]
+	appName := hr.Labels[appsv1alpha1.ApplicationNameLabel]
+
+	ready := metav1.ConditionUnknown
+	if cond := apimeta.FindStatusCondition(hr.Status.Conditions, "Ready"); cond != nil {
+		ready = cond.Status
+	}
+
+	return corev1alpha1.WorkloadSummary{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "WorkloadSummary",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              summaryName(hr.Namespace, kind, appName),
+			UID:               hr.UID,
+			ResourceVersion:   hr.ResourceVersion,
+			CreationTimestamp: hr.CreationTimestamp,
+			Labels: map[string]string{
+				appsv1alpha1.ApplicationKindLabel:  kind,
+				appsv1alpha1.ApplicationGroupLabel: hr.Labels[appsv1alpha1.ApplicationGroupLabel],
+				appsv1alpha1.ApplicationNameLabel:  appName,
+			},
+		},
+		ApplicationKind:      kind,
+		ApplicationName:      appName,
+		ApplicationNamespace: hr.Namespace,
+		Ready:                ready,
+		Version:              hr.Status.LastAttemptedRevision,
+	}
+}
+
+// summaryName returns the name of a WorkloadSummary: "<namespace>.<kind>.<name>".
+// Namespaces and kinds cannot contain dots, so the name can be split back unambiguously.
+func summaryName(namespace, kind, appName string) string {
+	return namespace + "." + strings.ToLower(kind) + "." + appName
+}
+
+// parseName splits the name of a WorkloadSummary into its parts
+func parseName(name string) (namespace, kind, appName string, ok bool) {
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// -----------------------------------------------------------------------------
+// Boiler-plate
+// -----------------------------------------------------------------------------
+
+func (*REST) Destroy() {}
+
+type notAcceptable struct {
+	resource schema.GroupResource
+	message  string
+}
+
+func (e notAcceptable) Error() string { return e.message }
+func (e notAcceptable) Status() metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusNotAcceptable,
+		Reason:  metav1.StatusReason("NotAcceptable"),
+		Message: e.message,
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package workloadsummary
+
+import (
+	"testing"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/cozystack/cozystack/pkg/apis/apps/v1alpha1"
+)
+
+func helmRelease(namespace, kind, name string) helmv2.HelmRelease {
+	return helmv2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kind + "-" + name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				appsv1alpha1.ApplicationKindLabel: kind,
+				appsv1alpha1.ApplicationNameLabel: name,
+			},
+		},
+	}
+}
+
+func TestMakeListFiltersAndSorts(t *testing.T) {
+	r := &REST{}
+
+	ready := helmRelease("tenant-b", "Postgres", "db.v2")
+	ready.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}}
+	ready.Status.LastAttemptedRevision = "0.1.0"
+
+	src := &helmv2.HelmReleaseList{
+		Items: []helmv2.HelmRelease{
+			ready,
+			helmRelease("tenant-a", "Redis", "cache"),
+			helmRelease("tenant-hidden", "Redis", "cache"),
+		},
+	}
+
+	result := r.makeList(src, []string{"tenant-a", "tenant-b"})
+
+	if len(result.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(result.Items))
+	}
+	if got := result.Items[0].Name; got != "tenant-a.redis.cache" {
+		t.Errorf("item 0: expected %q, got %q", "tenant-a.redis.cache", got)
+	}
+	if got := result.Items[0].Ready; got != metav1.ConditionUnknown {
+		t.Errorf("item 0: expected ready %q, got %q", metav1.ConditionUnknown, got)
+	}
+	db := result.Items[1]
+	if db.Name != "tenant-b.postgres.db.v2" || db.ApplicationKind != "Postgres" || db.ApplicationName != "db.v2" ||
+		db.ApplicationNamespace != "tenant-b" || db.Ready != metav1.ConditionTrue || db.Version != "0.1.0" {
+		t.Errorf("unexpected summary: %+v", db)
+	}
+}
+
+func TestParseName(t *testing.T) {
+	namespace, kind, appName, ok := parseName("tenant-b.postgres.db.v2")
+	if !ok || namespace != "tenant-b" || kind != "postgres" || appName != "db.v2" {
+		t.Errorf("unexpected result: %q %q %q %v", namespace, kind, appName, ok)
+	}
+	for _, name := range []string{"tenant-b", "tenant-b.postgres", "tenant-b..db", ".postgres.db"} {
+		if _, _, _, ok := parseName(name); ok {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}